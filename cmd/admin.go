@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dipress/crmifc/internal/kit/mail"
+	"github.com/dipress/crmifc/internal/kit/password"
+	"github.com/dipress/crmifc/internal/role"
+	"github.com/dipress/crmifc/internal/storage/postgres"
+	"github.com/dipress/crmifc/internal/storage/postgres/schema"
+	"github.com/dipress/crmifc/internal/user"
+	"github.com/dipress/crmifc/internal/validation"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// ErrPasswordMismatch is returned when the repeated password differs.
+var ErrPasswordMismatch = errors.New("passwords don't match")
+
+// createAdmin asks the credentials of the administrator and creates it.
+func createAdmin(args []string) {
+	fs := flag.NewFlagSet("create-admin", flag.ExitOnError)
+	var (
+		dsn        = fs.String("dsn", "", "postgres database DSN")
+		roleName   = fs.String("role", "Admin", "role of the administrator, created with all permissions unless it exists")
+		bcryptCost = fs.Int("bcrypt-cost", bcrypt.DefaultCost, "bcrypt cost of the password hash")
+	)
+	fs.Parse(args)
+
+	db, err := sql.Open("postgres", *dsn)
+	if err != nil {
+		log.Fatalf("failed to connect db: %v\n", err)
+	}
+	defer db.Close()
+
+	if err := schema.Check(db); err != nil {
+		log.Fatalf("failed to check schema, run migrate up: %v", err)
+	}
+
+	p := prompter{
+		r:        bufio.NewReader(os.Stdin),
+		w:        os.Stdout,
+		terminal: -1,
+	}
+	if fd := int(os.Stdin.Fd()); terminal.IsTerminal(fd) {
+		p.terminal = fd
+	}
+
+	f, err := p.admin()
+	if err != nil {
+		log.Fatalf("failed to read administrator: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	u, err := bootstrapAdmin(ctx, db, f, *roleName, *bcryptCost)
+	if err != nil {
+		log.Fatalf("failed to create administrator: %v", err)
+	}
+
+	fmt.Fprintf(os.Stdout, "created administrator %s with id %d\n", u.Username, u.ID)
+}
+
+// bootstrapAdmin creates the user of the role with the given name. The
+// role is created with all permissions when it doesn't exist yet.
+func bootstrapAdmin(ctx context.Context, db *sql.DB, f *user.Form, roleName string, cost int) (*user.User, error) {
+	roleRepo := postgres.NewRoleRepository(db)
+	transactor := postgres.NewTransactor(db)
+
+	userService := user.NewService(
+		postgres.NewUserRepository(db),
+		&validation.User{Policy: password.DefaultPolicy},
+		postgres.NewPasswordResetRepository(db),
+		mail.NewMemoryMailer(),
+	)
+	userService.Cost = cost
+	userService.Transactor = transactor
+
+	var u user.User
+	err := transactor.WithinTx(ctx, func(ctx context.Context) error {
+		rl, err := roleRepo.FindByName(ctx, roleName)
+		switch {
+		case err == role.ErrNotFound:
+			var created role.Role
+			if err := roleRepo.Create(ctx, &role.NewRole{Name: roleName}, &created); err != nil {
+				return errors.Wrap(err, "create role")
+			}
+
+			for _, p := range role.Permissions {
+				if err := roleRepo.Grant(ctx, created.ID, p); err != nil {
+					return errors.Wrap(err, "grant permission")
+				}
+			}
+			rl = &created
+		case err != nil:
+			return errors.Wrap(err, "find role")
+		}
+
+		f.RoleID = rl.ID
+		if err := userService.Create(ctx, f, &u); err != nil {
+			return errors.Wrap(err, "create user")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &u, nil
+}
+
+// prompter asks the values line by line.
+type prompter struct {
+	r *bufio.Reader
+	w io.Writer
+	// terminal is the descriptor of the terminal which hides the
+	// typed passwords, it's -1 when the input isn't a terminal.
+	terminal int
+}
+
+// admin asks the username, the email and the repeated password.
+func (p *prompter) admin() (*user.Form, error) {
+	username, err := p.ask("Username")
+	if err != nil {
+		return nil, err
+	}
+
+	email, err := p.ask("Email")
+	if err != nil {
+		return nil, err
+	}
+
+	pw, err := p.askPassword("Password")
+	if err != nil {
+		return nil, err
+	}
+
+	repeated, err := p.askPassword("Repeat password")
+	if err != nil {
+		return nil, err
+	}
+
+	if pw != repeated {
+		return nil, ErrPasswordMismatch
+	}
+
+	f := user.Form{
+		Username: strings.TrimSpace(username),
+		Email:    strings.TrimSpace(email),
+		Password: pw,
+	}
+
+	return &f, nil
+}
+
+// ask reads the line without the line break.
+func (p *prompter) ask(label string) (string, error) {
+	fmt.Fprintf(p.w, "%s: ", label)
+
+	line, err := p.r.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", errors.Wrapf(err, "read %s", strings.ToLower(label))
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// askPassword reads the line without echo when the input is a terminal.
+func (p *prompter) askPassword(label string) (string, error) {
+	if p.terminal < 0 {
+		return p.ask(label)
+	}
+
+	fmt.Fprintf(p.w, "%s: ", label)
+	b, err := terminal.ReadPassword(p.terminal)
+	fmt.Fprintln(p.w)
+	if err != nil {
+		return "", errors.Wrapf(err, "read %s", strings.ToLower(label))
+	}
+
+	return string(b), nil
+}
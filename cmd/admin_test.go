@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/dipress/crmifc/internal/role"
+	"github.com/dipress/crmifc/internal/storage/postgres"
+	"github.com/dipress/crmifc/internal/user"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestPrompterAdmin(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		expect *user.Form
+		err    error
+	}{
+		{
+			name:  "ok",
+			input: " username49 \r\nusername49@example.com\ncorrect horse battery\ncorrect horse battery",
+			expect: &user.Form{
+				Username: "username49",
+				Email:    "username49@example.com",
+				Password: "correct horse battery",
+			},
+		},
+		{
+			name:  "password mismatch",
+			input: "username49\nusername49@example.com\ncorrect horse battery\ncorrect horse\n",
+			err:   ErrPasswordMismatch,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := prompter{
+				r:        bufio.NewReader(strings.NewReader(tc.input)),
+				w:        ioutil.Discard,
+				terminal: -1,
+			}
+
+			f, err := p.admin()
+			if errors.Cause(err) != tc.err {
+				t.Fatalf("unexpected error: %v expected: %v", err, tc.err)
+			}
+
+			if tc.expect != nil && *f != *tc.expect {
+				t.Errorf("unexpected form: %+v expected: %+v", f, tc.expect)
+			}
+		})
+	}
+}
+
+func TestBootstrapAdmin(t *testing.T) {
+	t.Log("with prepared database")
+	{
+		db, teardown := postgresDB(t)
+		defer teardown()
+
+		ctx, cancel := context.WithTimeout(context.Background(), caseTimeout)
+		defer cancel()
+
+		roleRepo := postgres.NewRoleRepository(db)
+
+		t.Log("\ttest:0\tshould create the role with all permissions and the user.")
+		{
+			f := user.Form{
+				Username: "username49",
+				Email:    "username49@example.com",
+				Password: "correct horse battery",
+			}
+
+			u, err := bootstrapAdmin(ctx, db, &f, "Root", bcrypt.MinCost)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			rl, err := roleRepo.FindByName(ctx, "Root")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if u.Role.ID != rl.ID || len(rl.Permissions) != len(role.Permissions) {
+				t.Errorf("unexpected user: %+v role: %+v", u, rl)
+			}
+		}
+
+		t.Log("\ttest:1\tshould add the user to the existing role.")
+		{
+			f := user.Form{
+				Username: "username50",
+				Email:    "username50@example.com",
+				Password: "correct horse battery",
+			}
+
+			u, err := bootstrapAdmin(ctx, db, &f, "Root", bcrypt.MinCost)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			rl, err := roleRepo.FindByName(ctx, "Root")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if u.Role.ID != rl.ID {
+				t.Errorf("unexpected user: %+v role: %+v", u, rl)
+			}
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/dipress/crmifc/internal/apikey"
+	"github.com/dipress/crmifc/internal/kit/mail"
+	"github.com/dipress/crmifc/internal/role"
+	"github.com/dipress/crmifc/internal/storage/postgres"
+	"github.com/dipress/crmifc/internal/user"
+)
+
+func TestAPIKeys(t *testing.T) {
+	t.Log("with prepared server")
+	{
+		db, teardown := postgresDB(t)
+		defer teardown()
+
+		ctx, cancel := context.WithTimeout(context.Background(), caseTimeout)
+		defer cancel()
+
+		userRepo := postgres.NewUserRepository(db)
+		roleRepo := postgres.NewRoleRepository(db)
+
+		nr := role.NewRole{
+			Name: "Integrator",
+		}
+
+		var rl role.Role
+		if err := roleRepo.Create(ctx, &nr, &rl); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		for _, p := range []role.Permission{role.ArticlesView, role.CategoriesView} {
+			if err := roleRepo.Grant(ctx, rl.ID, p); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}
+
+		nu := user.NewUser{
+			RoleID:       rl.ID,
+			Username:     "username37",
+			Email:        "username37@example.com",
+			PasswordHash: "$2y$12$e4.VBLqKAanAZs10dRL65O8.b0kHBC34pcGCN1HdJIchCi9im40Ei",
+		}
+
+		var u user.User
+		if err := userRepo.Create(ctx, &nu, &u); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		claims := newClaims(ctx, t, userRepo, u.ID)
+		authenticator := authenticatorSetup(db)
+
+		token, err := authenticator.GenerateToken(ctx, claims)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			log.Fatalf("failed to listen: %v", err)
+		}
+
+		services := setupServices(db, authenticator, mail.NewMemoryMailer())
+
+		s := setupServer(lis.Addr().String(), services, authenticator)
+		go s.Serve(lis)
+		defer s.Close()
+
+		do := func(method, path, body, header, value string, v interface{}) int {
+			req, err := http.NewRequest(method, fmt.Sprintf("http://%s%s", s.Addr, path), strings.NewReader(body))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Add(header, value)
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if v != nil && resp.StatusCode == http.StatusOK {
+				if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			return resp.StatusCode
+		}
+
+		bearer := "Bearer " + token
+
+		var created apikey.Created
+		t.Log("\ttest:0\tshould create the api key with granted scopes only.")
+		{
+			body := `{"name": "ci", "scopes": ["users:manage"], "expires_in": 30}`
+			if code := do(http.MethodPost, "/me/tokens", body, "Authorization", bearer, nil); code != http.StatusUnprocessableEntity {
+				t.Errorf("unexpected status code: %d expected: %d", code, http.StatusUnprocessableEntity)
+			}
+
+			body = `{"name": "ci", "scopes": ["articles:view"], "expires_in": 30}`
+			if code := do(http.MethodPost, "/me/tokens", body, "Authorization", bearer, &created); code != http.StatusOK {
+				t.Fatalf("unexpected status code: %d expected: %d", code, http.StatusOK)
+			}
+
+			if !strings.HasPrefix(created.Key, apikey.KeyPrefix) || !strings.HasPrefix(created.Key, created.Prefix) {
+				t.Errorf("unexpected api key: %+v", created)
+			}
+		}
+
+		t.Log("\ttest:1\tshould list the api keys without the key.")
+		{
+			var keys apikey.APIKeys
+			if code := do(http.MethodGet, "/me/tokens", "", "Authorization", bearer, &keys); code != http.StatusOK {
+				t.Errorf("unexpected status code: %d expected: %d", code, http.StatusOK)
+			}
+
+			if len(keys.APIKeys) != 1 || keys.APIKeys[0].ID != created.ID {
+				t.Errorf("unexpected api keys: %+v", keys)
+			}
+		}
+
+		t.Log("\ttest:2\tshould authorize the api key within its scopes.")
+		{
+			if code := do(http.MethodGet, "/articles", "", "X-API-Key", created.Key, nil); code != http.StatusOK {
+				t.Errorf("unexpected status code: %d expected: %d", code, http.StatusOK)
+			}
+
+			if code := do(http.MethodGet, "/articles", "", "Authorization", "Bearer "+created.Key, nil); code != http.StatusOK {
+				t.Errorf("unexpected status code: %d expected: %d", code, http.StatusOK)
+			}
+
+			if code := do(http.MethodGet, "/categories", "", "X-API-Key", created.Key, nil); code != http.StatusForbidden {
+				t.Errorf("unexpected status code: %d expected: %d", code, http.StatusForbidden)
+			}
+		}
+
+		t.Log("\ttest:3\tshould not manage the api keys with the api key.")
+		{
+			body := `{"name": "other", "scopes": ["articles:view"], "expires_in": 30}`
+			if code := do(http.MethodPost, "/me/tokens", body, "X-API-Key", created.Key, nil); code != http.StatusForbidden {
+				t.Errorf("unexpected status code: %d expected: %d", code, http.StatusForbidden)
+			}
+		}
+
+		t.Log("\ttest:4\tshould not manage the account with the api key.")
+		{
+			requests := []struct {
+				method string
+				path   string
+				body   string
+			}{
+				{method: http.MethodPatch, path: "/me", body: `{"username": "renamed"}`},
+				{method: http.MethodPost, path: "/me/password", body: `{"old_password": "password", "new_password": "new-password"}`},
+				{method: http.MethodPost, path: "/mfa/enroll"},
+				{method: http.MethodPost, path: "/mfa/verify", body: `{"code": "123456"}`},
+				{method: http.MethodDelete, path: "/mfa", body: `{"code": "123456"}`},
+				{method: http.MethodGet, path: "/me/sessions"},
+				{method: http.MethodDelete, path: "/me/sessions/1"},
+				{method: http.MethodPost, path: "/signout"},
+			}
+
+			for _, req := range requests {
+				if code := do(req.method, req.path, req.body, "X-API-Key", created.Key, nil); code != http.StatusForbidden {
+					t.Errorf("%s %s: unexpected status code: %d expected: %d", req.method, req.path, code, http.StatusForbidden)
+				}
+			}
+		}
+
+		t.Log("\ttest:5\tshould revoke the api key.")
+		{
+			path := fmt.Sprintf("/me/tokens/%d", created.ID)
+			if code := do(http.MethodDelete, path, "", "Authorization", bearer, nil); code != http.StatusNoContent {
+				t.Errorf("unexpected status code: %d expected: %d", code, http.StatusNoContent)
+			}
+
+			if code := do(http.MethodGet, "/articles", "", "X-API-Key", created.Key, nil); code != http.StatusUnauthorized {
+				t.Errorf("unexpected status code: %d expected: %d", code, http.StatusUnauthorized)
+			}
+		}
+	}
+}
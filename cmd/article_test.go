@@ -2,16 +2,16 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"net"
 	"net/http"
 	"strings"
 	"testing"
-	"time"
 
 	"github.com/dipress/crmifc/internal/article"
-	"github.com/dipress/crmifc/internal/kit/auth"
+	"github.com/dipress/crmifc/internal/kit/mail"
 	"github.com/dipress/crmifc/internal/role"
 	"github.com/dipress/crmifc/internal/storage/postgres"
 	"github.com/dipress/crmifc/internal/user"
@@ -28,6 +28,7 @@ func TestArticleCreate(t *testing.T) {
 
 		userRepo := postgres.NewUserRepository(db)
 		roleRepo := postgres.NewRoleRepository(db)
+		categoryRepo := postgres.NewCategoryRepository(db)
 
 		nr := role.NewRole{
 			Name: "Admin",
@@ -51,11 +52,13 @@ func TestArticleCreate(t *testing.T) {
 			t.Errorf("unexpected error: %v", err)
 		}
 
-		claims := auth.NewClaims(u.Email, time.Now(), time.Hour)
+		grantPermissions(ctx, t, roleRepo, rol.ID)
+
+		claims := newClaims(ctx, t, userRepo, u.ID)
 
 		authenticator := authenticatorSetup(db)
 
-		token, err := authenticator.GenerateToken(ctx, claims.StandardClaims)
+		token, err := authenticator.GenerateToken(ctx, claims)
 		if err != nil {
 			t.Errorf("unexpected error: %v", err)
 		}
@@ -67,15 +70,17 @@ func TestArticleCreate(t *testing.T) {
 			log.Fatalf("failed to listen: %v", err)
 		}
 
-		services := setupServices(db, authenticator)
+		services := setupServices(db, authenticator, mail.NewMemoryMailer())
 
 		s := setupServer(lis.Addr().String(), services, authenticator)
 		go s.Serve(lis)
 		defer s.Close()
 
+		categoryID := newCategory(ctx, t, categoryRepo, "Router")
+
 		t.Log("\ttest:0\tshould create a article.")
 		{
-			articleStr := `{"category_id":1,"title":"my title","body":"my body"}`
+			articleStr := fmt.Sprintf(`{"category_id":%d,"title":"my title","body":"my body"}`, categoryID)
 			req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("http://%s/articles", s.Addr), strings.NewReader(articleStr))
 			req.Header.Set("Content-Type", "application/json")
 			req.Header.Add("Authorization", token)
@@ -93,6 +98,38 @@ func TestArticleCreate(t *testing.T) {
 				t.Errorf("unexpected status code: %d expected: %d", resp.StatusCode, http.StatusOK)
 			}
 		}
+
+		t.Log("\ttest:1\tshould refuse an article of unknown category.")
+		{
+			articleStr := fmt.Sprintf(`{"category_id":%d,"title":"my title","body":"my body"}`, categoryID+100)
+			req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("http://%s/articles", s.Addr), strings.NewReader(articleStr))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Add("Authorization", token)
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusUnprocessableEntity {
+				t.Errorf("unexpected status code: %d expected: %d", resp.StatusCode, http.StatusUnprocessableEntity)
+			}
+
+			var body struct {
+				Errors map[string]string `json:"errors"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if body.Errors["category_id"] != "does not exist" {
+				t.Errorf("unexpected errors: %+v", body.Errors)
+			}
+		}
 	}
 }
 
@@ -108,6 +145,7 @@ func TestFindArticle(t *testing.T) {
 		userRepo := postgres.NewUserRepository(db)
 		roleRepo := postgres.NewRoleRepository(db)
 		articleRepo := postgres.NewArticleRepository(db)
+		categoryRepo := postgres.NewCategoryRepository(db)
 
 		nr := role.NewRole{
 			Name: "Admin",
@@ -131,13 +169,15 @@ func TestFindArticle(t *testing.T) {
 			t.Errorf("unexpected error: %v", err)
 		}
 
-		claims := auth.NewClaims(u.Email, time.Now(), time.Hour)
+		grantPermissions(ctx, t, roleRepo, rol.ID)
+
+		claims := newClaims(ctx, t, userRepo, u.ID)
 
 		authenticator := authenticatorSetup(db)
 
 		na := article.NewArticle{
-			UserID:     u.ID,
-			CategoryID: 10,
+			AuthorID:   u.ID,
+			CategoryID: newCategory(ctx, t, categoryRepo, "Router"),
 			Title:      "my title",
 			Body:       "my body",
 		}
@@ -160,7 +200,7 @@ func TestFindArticle(t *testing.T) {
 			log.Fatalf("failed to listen: %v", err)
 		}
 
-		services := setupServices(db, authenticator)
+		services := setupServices(db, authenticator, mail.NewMemoryMailer())
 
 		s := setupServer(lis.Addr().String(), services, authenticator)
 		go s.Serve(lis)
@@ -200,6 +240,7 @@ func TestUpdateArticle(t *testing.T) {
 		userRepo := postgres.NewUserRepository(db)
 		roleRepo := postgres.NewRoleRepository(db)
 		articleRepo := postgres.NewArticleRepository(db)
+		categoryRepo := postgres.NewCategoryRepository(db)
 
 		nr := role.NewRole{
 			Name: "Admin",
@@ -223,13 +264,15 @@ func TestUpdateArticle(t *testing.T) {
 			t.Errorf("unexpected error: %v", err)
 		}
 
-		claims := auth.NewClaims(u.Email, time.Now(), time.Hour)
+		grantPermissions(ctx, t, roleRepo, rol.ID)
+
+		claims := newClaims(ctx, t, userRepo, u.ID)
 
 		authenticator := authenticatorSetup(db)
 
 		na := article.NewArticle{
-			UserID:     u.ID,
-			CategoryID: 10,
+			AuthorID:   u.ID,
+			CategoryID: newCategory(ctx, t, categoryRepo, "Router"),
 			Title:      "my title",
 			Body:       "my body",
 		}
@@ -252,7 +295,7 @@ func TestUpdateArticle(t *testing.T) {
 			log.Fatalf("failed to listen: %v", err)
 		}
 
-		services := setupServices(db, authenticator)
+		services := setupServices(db, authenticator, mail.NewMemoryMailer())
 
 		s := setupServer(lis.Addr().String(), services, authenticator)
 		go s.Serve(lis)
@@ -260,10 +303,11 @@ func TestUpdateArticle(t *testing.T) {
 
 		t.Log("\ttest:0\tshould update a post.")
 		{
-			articleStr := `{"category_id":22, "title":"my awesome title", "body":"my awesome body"}`
+			articleStr := fmt.Sprintf(`{"category_id":%d, "title":"my awesome title", "body":"my awesome body"}`, art.CategoryID)
 			req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("http://%s/articles/%d", s.Addr, art.ID), strings.NewReader(articleStr))
 			req.Header.Set("Content-Type", "application/json")
 			req.Header.Add("Authorization", token)
+			req.Header.Set("If-Match", `"1"`)
 
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
@@ -281,6 +325,214 @@ func TestUpdateArticle(t *testing.T) {
 	}
 }
 
+func TestUpdateArticleOfAnotherUser(t *testing.T) {
+	t.Log("with prepared server")
+	{
+		db, teardown := postgresDB(t)
+		defer teardown()
+
+		ctx, cancel := context.WithTimeout(context.Background(), caseTimeout)
+		defer cancel()
+
+		userRepo := postgres.NewUserRepository(db)
+		roleRepo := postgres.NewRoleRepository(db)
+		articleRepo := postgres.NewArticleRepository(db)
+		categoryRepo := postgres.NewCategoryRepository(db)
+
+		nr := role.NewRole{
+			Name: "Manager",
+		}
+
+		var rol role.Role
+		err := roleRepo.Create(ctx, &nr, &rol)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		nu := user.NewUser{
+			Username:     "username28",
+			Email:        "username28@example.com",
+			PasswordHash: "$2y$12$e4.VBLqKAanAZs10dRL65O8.b0kHBC34pcGCN1HdJIchCi9im40Ei",
+			RoleID:       rol.ID,
+		}
+
+		var u user.User
+		if err := userRepo.Create(ctx, &nu, &u); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		for _, p := range []role.Permission{role.ArticlesView, role.ArticlesUpdateOwn} {
+			if err := roleRepo.Grant(ctx, rol.ID, p); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}
+
+		claims := newClaims(ctx, t, userRepo, u.ID)
+
+		authenticator := authenticatorSetup(db)
+
+		na := article.NewArticle{
+			AuthorID:   u.ID + 1,
+			CategoryID: newCategory(ctx, t, categoryRepo, "Router"),
+			Title:      "my title",
+			Body:       "my body",
+		}
+
+		var art article.Article
+		err = articleRepo.Create(ctx, &na, &art)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		token, err := authenticator.GenerateToken(ctx, claims)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		token = "Bearer " + token
+
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			log.Fatalf("failed to listen: %v", err)
+		}
+
+		services := setupServices(db, authenticator, mail.NewMemoryMailer())
+
+		s := setupServer(lis.Addr().String(), services, authenticator)
+		go s.Serve(lis)
+		defer s.Close()
+
+		t.Log("\ttest:0\tshould forbid to update an article of another user.")
+		{
+			articleStr := fmt.Sprintf(`{"category_id":%d, "title":"my awesome title", "body":"my awesome body"}`, art.CategoryID)
+			req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("http://%s/articles/%d", s.Addr, art.ID), strings.NewReader(articleStr))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Add("Authorization", token)
+			req.Header.Set("If-Match", `"1"`)
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if resp.StatusCode != http.StatusForbidden {
+				t.Errorf("unexpected status code: %d expected: %d", resp.StatusCode, http.StatusForbidden)
+			}
+		}
+	}
+}
+
+func TestArticleRestore(t *testing.T) {
+	t.Log("with prepared server")
+	{
+		db, teardown := postgresDB(t)
+		defer teardown()
+
+		ctx, cancel := context.WithTimeout(context.Background(), caseTimeout)
+		defer cancel()
+
+		userRepo := postgres.NewUserRepository(db)
+		roleRepo := postgres.NewRoleRepository(db)
+		articleRepo := postgres.NewArticleRepository(db)
+		categoryRepo := postgres.NewCategoryRepository(db)
+
+		nr := role.NewRole{
+			Name: "Admin",
+		}
+
+		var rol role.Role
+		err := roleRepo.Create(ctx, &nr, &rol)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		nu := user.NewUser{
+			Username:     "username27",
+			Email:        "username27@example.com",
+			PasswordHash: "$2y$12$e4.VBLqKAanAZs10dRL65O8.b0kHBC34pcGCN1HdJIchCi9im40Ei",
+			RoleID:       rol.ID,
+		}
+
+		var u user.User
+		if err := userRepo.Create(ctx, &nu, &u); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		grantPermissions(ctx, t, roleRepo, rol.ID)
+
+		claims := newClaims(ctx, t, userRepo, u.ID)
+
+		authenticator := authenticatorSetup(db)
+
+		na := article.NewArticle{
+			AuthorID:   u.ID,
+			CategoryID: newCategory(ctx, t, categoryRepo, "Router"),
+			Title:      "my title",
+			Body:       "my body",
+		}
+
+		var art article.Article
+		err = articleRepo.Create(ctx, &na, &art)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		art.Title = "my changed title"
+		if err := articleRepo.Update(ctx, art.ID, &art); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		token, err := authenticator.GenerateToken(ctx, claims)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		token = "Bearer " + token
+
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			log.Fatalf("failed to listen: %v", err)
+		}
+
+		services := setupServices(db, authenticator, mail.NewMemoryMailer())
+
+		s := setupServer(lis.Addr().String(), services, authenticator)
+		go s.Serve(lis)
+		defer s.Close()
+
+		t.Log("\ttest:0\tshould restore the first revision of the article.")
+		{
+			req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("http://%s/articles/%d/revisions/1/restore", s.Addr, art.ID), nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			req.Header.Add("Authorization", token)
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("unexpected status code: %d expected: %d", resp.StatusCode, http.StatusOK)
+			}
+
+			restored, err := articleRepo.Find(ctx, art.ID)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if restored.Title != "my title" {
+				t.Errorf("unexpected title: %q", restored.Title)
+			}
+		}
+	}
+}
+
 func TestDeleteArticle(t *testing.T) {
 	t.Log("with prepared server")
 	{
@@ -293,6 +545,7 @@ func TestDeleteArticle(t *testing.T) {
 		userRepo := postgres.NewUserRepository(db)
 		roleRepo := postgres.NewRoleRepository(db)
 		articleRepo := postgres.NewArticleRepository(db)
+		categoryRepo := postgres.NewCategoryRepository(db)
 
 		nr := role.NewRole{
 			Name: "Admin",
@@ -316,13 +569,15 @@ func TestDeleteArticle(t *testing.T) {
 			t.Errorf("unexpected error: %v", err)
 		}
 
-		claims := auth.NewClaims(u.Email, time.Now(), time.Hour)
+		grantPermissions(ctx, t, roleRepo, rol.ID)
+
+		claims := newClaims(ctx, t, userRepo, u.ID)
 
 		authenticator := authenticatorSetup(db)
 
 		na := article.NewArticle{
-			UserID:     u.ID,
-			CategoryID: 10,
+			AuthorID:   u.ID,
+			CategoryID: newCategory(ctx, t, categoryRepo, "Router"),
 			Title:      "my title",
 			Body:       "my body",
 		}
@@ -345,7 +600,7 @@ func TestDeleteArticle(t *testing.T) {
 			log.Fatalf("failed to listen: %v", err)
 		}
 
-		services := setupServices(db, authenticator)
+		services := setupServices(db, authenticator, mail.NewMemoryMailer())
 
 		s := setupServer(lis.Addr().String(), services, authenticator)
 		go s.Serve(lis)
@@ -356,6 +611,7 @@ func TestDeleteArticle(t *testing.T) {
 			req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("http://%s/articles/%d", s.Addr, art.ID), nil)
 			req.Header.Set("Content-Type", "application/json")
 			req.Header.Add("Authorization", token)
+			req.Header.Set("If-Match", `"1"`)
 
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
@@ -385,6 +641,7 @@ func TestArticleList(t *testing.T) {
 		userRepo := postgres.NewUserRepository(db)
 		roleRepo := postgres.NewRoleRepository(db)
 		articleRepo := postgres.NewArticleRepository(db)
+		categoryRepo := postgres.NewCategoryRepository(db)
 
 		nr := role.NewRole{
 			Name: "Admin",
@@ -408,13 +665,15 @@ func TestArticleList(t *testing.T) {
 			t.Errorf("unexpected error: %v", err)
 		}
 
-		claims := auth.NewClaims(u.Email, time.Now(), time.Hour)
+		grantPermissions(ctx, t, roleRepo, rol.ID)
+
+		claims := newClaims(ctx, t, userRepo, u.ID)
 
 		authenticator := authenticatorSetup(db)
 
 		na := article.NewArticle{
-			UserID:     u.ID,
-			CategoryID: 10,
+			AuthorID:   u.ID,
+			CategoryID: newCategory(ctx, t, categoryRepo, "Router"),
 			Title:      "my title",
 			Body:       "my body",
 		}
@@ -437,7 +696,7 @@ func TestArticleList(t *testing.T) {
 			log.Fatalf("failed to listen: %v", err)
 		}
 
-		services := setupServices(db, authenticator)
+		services := setupServices(db, authenticator, mail.NewMemoryMailer())
 
 		s := setupServer(lis.Addr().String(), services, authenticator)
 		go s.Serve(lis)
@@ -464,3 +723,98 @@ func TestArticleList(t *testing.T) {
 		}
 	}
 }
+
+func TestArticleSearch(t *testing.T) {
+	t.Log("with prepared server")
+	{
+		db, teardown := postgresDB(t)
+		defer teardown()
+
+		ctx, cancel := context.WithTimeout(context.Background(), caseTimeout)
+		defer cancel()
+
+		userRepo := postgres.NewUserRepository(db)
+		roleRepo := postgres.NewRoleRepository(db)
+		articleRepo := postgres.NewArticleRepository(db)
+		categoryRepo := postgres.NewCategoryRepository(db)
+
+		nr := role.NewRole{
+			Name: "Admin",
+		}
+
+		var rol role.Role
+		err := roleRepo.Create(ctx, &nr, &rol)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		nu := user.NewUser{
+			Username:     "username30",
+			Email:        "username30@example.com",
+			PasswordHash: "$2y$12$e4.VBLqKAanAZs10dRL65O8.b0kHBC34pcGCN1HdJIchCi9im40Ei",
+			RoleID:       rol.ID,
+		}
+
+		var u user.User
+		if err := userRepo.Create(ctx, &nu, &u); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		grantPermissions(ctx, t, roleRepo, rol.ID)
+
+		claims := newClaims(ctx, t, userRepo, u.ID)
+
+		authenticator := authenticatorSetup(db)
+
+		na := article.NewArticle{
+			AuthorID:   u.ID,
+			CategoryID: newCategory(ctx, t, categoryRepo, "Router"),
+			Title:      "my title",
+			Body:       "my body",
+		}
+
+		var art article.Article
+		err = articleRepo.Create(ctx, &na, &art)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		token, err := authenticator.GenerateToken(ctx, claims)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		token = "Bearer " + token
+
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			log.Fatalf("failed to listen: %v", err)
+		}
+
+		services := setupServices(db, authenticator, mail.NewMemoryMailer())
+
+		s := setupServer(lis.Addr().String(), services, authenticator)
+		go s.Serve(lis)
+		defer s.Close()
+
+		t.Log("\ttest:0\tshould search articles.")
+		{
+			req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("http://%s/articles/search?q=title", s.Addr), nil)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Add("Authorization", token)
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("unexpected status code: %d expected: %d", resp.StatusCode, http.StatusOK)
+			}
+		}
+	}
+}
@@ -2,16 +2,16 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"net"
 	"net/http"
 	"strings"
 	"testing"
-	"time"
 
 	"github.com/dipress/crmifc/internal/category"
-	"github.com/dipress/crmifc/internal/kit/auth"
+	"github.com/dipress/crmifc/internal/kit/mail"
 	"github.com/dipress/crmifc/internal/role"
 	"github.com/dipress/crmifc/internal/storage/postgres"
 	"github.com/dipress/crmifc/internal/user"
@@ -50,7 +50,9 @@ func TestCategoryCreate(t *testing.T) {
 			t.Errorf("unexpected error: %v", err)
 		}
 
-		claims := auth.NewClaims(u.Email, time.Now(), time.Hour)
+		grantPermissions(ctx, t, roleRepo, rl.ID)
+
+		claims := newClaims(ctx, t, userRepo, u.ID)
 		authenticator := authenticatorSetup(db)
 
 		token, err := authenticator.GenerateToken(ctx, claims)
@@ -65,7 +67,7 @@ func TestCategoryCreate(t *testing.T) {
 			log.Fatalf("failed to listen: %v", err)
 		}
 
-		services := setupServices(db, authenticator)
+		services := setupServices(db, authenticator, mail.NewMemoryMailer())
 
 		s := setupServer(lis.Addr().String(), services, authenticator)
 		go s.Serve(lis)
@@ -142,7 +144,9 @@ func TestFindCategory(t *testing.T) {
 			t.Errorf("unexpected error: %v", err)
 		}
 
-		claims := auth.NewClaims(u.Email, time.Now(), time.Hour)
+		grantPermissions(ctx, t, roleRepo, rl.ID)
+
+		claims := newClaims(ctx, t, userRepo, u.ID)
 		authenticator := authenticatorSetup(db)
 
 		token, err := authenticator.GenerateToken(ctx, claims)
@@ -157,7 +161,7 @@ func TestFindCategory(t *testing.T) {
 			log.Fatalf("failed to listen: %v", err)
 		}
 
-		services := setupServices(db, authenticator)
+		services := setupServices(db, authenticator, mail.NewMemoryMailer())
 
 		s := setupServer(lis.Addr().String(), services, authenticator)
 		go s.Serve(lis)
@@ -227,7 +231,9 @@ func TestUpdateCategory(t *testing.T) {
 			t.Errorf("unexpected error: %v", err)
 		}
 
-		claims := auth.NewClaims(u.Email, time.Now(), time.Hour)
+		grantPermissions(ctx, t, roleRepo, rl.ID)
+
+		claims := newClaims(ctx, t, userRepo, u.ID)
 		authenticator := authenticatorSetup(db)
 
 		token, err := authenticator.GenerateToken(ctx, claims)
@@ -242,7 +248,7 @@ func TestUpdateCategory(t *testing.T) {
 			log.Fatalf("failed to listen: %v", err)
 		}
 
-		services := setupServices(db, authenticator)
+		services := setupServices(db, authenticator, mail.NewMemoryMailer())
 
 		s := setupServer(lis.Addr().String(), services, authenticator)
 		go s.Serve(lis)
@@ -257,6 +263,7 @@ func TestUpdateCategory(t *testing.T) {
 			)
 			req.Header.Set("Content-Type", "application/json")
 			req.Header.Add("Authorization", token)
+			req.Header.Set("If-Match", `"1"`)
 
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
@@ -270,6 +277,63 @@ func TestUpdateCategory(t *testing.T) {
 			if resp.StatusCode != http.StatusOK {
 				t.Errorf("unexpected status code: %d expected: %d", resp.StatusCode, http.StatusOK)
 			}
+
+			if etag := resp.Header.Get("ETag"); etag != `"2"` {
+				t.Errorf("unexpected etag: %s expected: %s", etag, `"2"`)
+			}
+		}
+
+		t.Log("\ttest:1\tshould refuse to update the stale category.")
+		{
+			categoryStr := `{"name": "Vendors"}`
+			req, err := http.NewRequest(http.MethodPut,
+				fmt.Sprintf("http://%s/categories/%d", s.Addr, c.ID),
+				strings.NewReader(categoryStr),
+			)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Add("Authorization", token)
+			req.Header.Set("If-Match", `"1"`)
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusPreconditionFailed {
+				t.Errorf("unexpected status code: %d expected: %d", resp.StatusCode, http.StatusPreconditionFailed)
+			}
+
+			var cat category.Category
+			if err := json.NewDecoder(resp.Body).Decode(&cat); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if cat.Name != "Partners" || resp.Header.Get("ETag") != `"2"` {
+				t.Errorf("unexpected category: %+v etag: %s", cat, resp.Header.Get("ETag"))
+			}
+		}
+
+		t.Log("\ttest:2\tshould require the version of the category.")
+		{
+			req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("http://%s/categories/%d", s.Addr, c.ID), nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			req.Header.Add("Authorization", token)
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusPreconditionRequired {
+				t.Errorf("unexpected status code: %d expected: %d", resp.StatusCode, http.StatusPreconditionRequired)
+			}
 		}
 	}
 }
@@ -317,7 +381,9 @@ func TestDeleteCategory(t *testing.T) {
 			t.Errorf("unexpected error: %v", err)
 		}
 
-		claims := auth.NewClaims(u.Email, time.Now(), time.Hour)
+		grantPermissions(ctx, t, roleRepo, rl.ID)
+
+		claims := newClaims(ctx, t, userRepo, u.ID)
 		authenticator := authenticatorSetup(db)
 
 		token, err := authenticator.GenerateToken(ctx, claims)
@@ -332,7 +398,7 @@ func TestDeleteCategory(t *testing.T) {
 			log.Fatalf("failed to listen: %v", err)
 		}
 
-		services := setupServices(db, authenticator)
+		services := setupServices(db, authenticator, mail.NewMemoryMailer())
 
 		s := setupServer(lis.Addr().String(), services, authenticator)
 		go s.Serve(lis)
@@ -343,6 +409,7 @@ func TestDeleteCategory(t *testing.T) {
 			req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("http://%s/categories/%d", s.Addr, c.ID), nil)
 			req.Header.Set("Content-Type", "application/json")
 			req.Header.Add("Authorization", token)
+			req.Header.Set("If-Match", `"1"`)
 
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
@@ -403,7 +470,9 @@ func TestListCategory(t *testing.T) {
 			t.Errorf("unexpected error: %v", err)
 		}
 
-		claims := auth.NewClaims(u.Email, time.Now(), time.Hour)
+		grantPermissions(ctx, t, roleRepo, rl.ID)
+
+		claims := newClaims(ctx, t, userRepo, u.ID)
 		authenticator := authenticatorSetup(db)
 
 		token, err := authenticator.GenerateToken(ctx, claims)
@@ -418,7 +487,7 @@ func TestListCategory(t *testing.T) {
 			log.Fatalf("failed to listen: %v", err)
 		}
 
-		services := setupServices(db, authenticator)
+		services := setupServices(db, authenticator, mail.NewMemoryMailer())
 
 		s := setupServer(lis.Addr().String(), services, authenticator)
 		go s.Serve(lis)
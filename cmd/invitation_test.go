@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/dipress/crmifc/internal/invitation"
+	"github.com/dipress/crmifc/internal/kit/mail"
+	"github.com/dipress/crmifc/internal/role"
+	"github.com/dipress/crmifc/internal/storage/postgres"
+	"github.com/dipress/crmifc/internal/user"
+)
+
+func TestInvitations(t *testing.T) {
+	t.Log("with prepared server")
+	{
+		db, teardown := postgresDB(t)
+		defer teardown()
+
+		ctx, cancel := context.WithTimeout(context.Background(), caseTimeout)
+		defer cancel()
+
+		userRepo := postgres.NewUserRepository(db)
+		roleRepo := postgres.NewRoleRepository(db)
+
+		nr := role.NewRole{
+			Name: "Recruiter",
+		}
+
+		var rl role.Role
+		if err := roleRepo.Create(ctx, &nr, &rl); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		grantPermissions(ctx, t, roleRepo, rl.ID)
+
+		nu := user.NewUser{
+			RoleID:       rl.ID,
+			Username:     "username40",
+			Email:        "username40@example.com",
+			PasswordHash: "$2y$12$e4.VBLqKAanAZs10dRL65O8.b0kHBC34pcGCN1HdJIchCi9im40Ei",
+		}
+
+		var u user.User
+		if err := userRepo.Create(ctx, &nu, &u); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		claims := newClaims(ctx, t, userRepo, u.ID)
+		authenticator := authenticatorSetup(db)
+
+		token, err := authenticator.GenerateToken(ctx, claims)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			log.Fatalf("failed to listen: %v", err)
+		}
+
+		mailer := mail.NewMemoryMailer()
+		services := setupServices(db, authenticator, mailer)
+
+		s := setupServer(lis.Addr().String(), services, authenticator)
+		go s.Serve(lis)
+		defer s.Close()
+
+		do := func(method, path, body string, authorized bool, v interface{}) int {
+			req, err := http.NewRequest(method, fmt.Sprintf("http://%s%s", s.Addr, path), strings.NewReader(body))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			req.Header.Set("Content-Type", "application/json")
+			if authorized {
+				req.Header.Set("Authorization", "Bearer "+token)
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if v != nil && resp.StatusCode == http.StatusOK {
+				if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			return resp.StatusCode
+		}
+
+		// lastToken returns the token of the link in the last email.
+		lastToken := func() string {
+			messages := mailer.Messages()
+			if len(messages) == 0 {
+				t.Fatalf("no messages are sent")
+			}
+
+			body := messages[len(messages)-1].Body
+			start := strings.Index(body, "?token=") + len("?token=")
+			token, err := url.QueryUnescape(body[start : start+strings.IndexAny(body[start:], "\n")])
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			return token
+		}
+
+		invite := fmt.Sprintf(`{"email": "username41@example.com", "role_id": %d}`, rl.ID)
+
+		var inv invitation.Invitation
+		t.Log("\ttest:0\tshould invite the email once.")
+		{
+			if code := do(http.MethodPost, "/invitations", invite, false, nil); code != http.StatusUnauthorized {
+				t.Errorf("unexpected status code: %d expected: %d", code, http.StatusUnauthorized)
+			}
+
+			if code := do(http.MethodPost, "/invitations", invite, true, &inv); code != http.StatusOK {
+				t.Fatalf("unexpected status code: %d expected: %d", code, http.StatusOK)
+			}
+
+			if inv.Email != "username41@example.com" || inv.RoleID != rl.ID || inv.InvitedBy != u.ID {
+				t.Errorf("unexpected invitation: %+v", inv)
+			}
+
+			if code := do(http.MethodPost, "/invitations", invite, true, nil); code != http.StatusUnprocessableEntity {
+				t.Errorf("unexpected status code: %d expected: %d", code, http.StatusUnprocessableEntity)
+			}
+		}
+
+		t.Log("\ttest:1\tshould list the pending invitation.")
+		{
+			var invs invitation.Invitations
+			if code := do(http.MethodGet, "/invitations", "", true, &invs); code != http.StatusOK {
+				t.Errorf("unexpected status code: %d expected: %d", code, http.StatusOK)
+			}
+
+			if len(invs.Invitations) != 1 || invs.Invitations[0].ID != inv.ID {
+				t.Errorf("unexpected invitations: %+v", invs)
+			}
+		}
+
+		t.Log("\ttest:2\tshould resend the invitation with a new link.")
+		{
+			sent := lastToken()
+
+			path := fmt.Sprintf("/invitations/%d/resend", inv.ID)
+			if code := do(http.MethodPost, path, "", true, nil); code != http.StatusOK {
+				t.Fatalf("unexpected status code: %d expected: %d", code, http.StatusOK)
+			}
+
+			accept := `{"username": "username41", "password": "password123"}`
+			if code := do(http.MethodPost, "/invitations/"+sent+"/accept", accept, false, nil); code != http.StatusNotFound {
+				t.Errorf("unexpected status code: %d expected: %d", code, http.StatusNotFound)
+			}
+		}
+
+		t.Log("\ttest:3\tshould accept the invitation once.")
+		{
+			resent := lastToken()
+			accept := `{"username": "username41", "password": "password123"}`
+
+			var created user.User
+			if code := do(http.MethodPost, "/invitations/"+resent+"/accept", accept, false, &created); code != http.StatusOK {
+				t.Fatalf("unexpected status code: %d expected: %d", code, http.StatusOK)
+			}
+
+			if created.Email != "username41@example.com" || created.Role.ID != rl.ID {
+				t.Errorf("unexpected user: %+v", created)
+			}
+
+			if code := do(http.MethodPost, "/invitations/"+resent+"/accept", accept, false, nil); code != http.StatusNotFound {
+				t.Errorf("unexpected status code: %d expected: %d", code, http.StatusNotFound)
+			}
+
+			signIn := `{"email": "username41@example.com", "password": "password123"}`
+			if code := do(http.MethodPost, "/signin", signIn, false, nil); code != http.StatusOK {
+				t.Errorf("unexpected status code: %d expected: %d", code, http.StatusOK)
+			}
+		}
+
+		t.Log("\ttest:4\tshould revoke the pending invitation.")
+		{
+			other := fmt.Sprintf(`{"email": "username42@example.com", "role_id": %d}`, rl.ID)
+
+			var revoked invitation.Invitation
+			if code := do(http.MethodPost, "/invitations", other, true, &revoked); code != http.StatusOK {
+				t.Fatalf("unexpected status code: %d expected: %d", code, http.StatusOK)
+			}
+
+			path := fmt.Sprintf("/invitations/%d", revoked.ID)
+			if code := do(http.MethodDelete, path, "", true, nil); code != http.StatusNoContent {
+				t.Errorf("unexpected status code: %d expected: %d", code, http.StatusNoContent)
+			}
+
+			accept := `{"username": "username42", "password": "password123"}`
+			if code := do(http.MethodPost, "/invitations/"+lastToken()+"/accept", accept, false, nil); code != http.StatusNotFound {
+				t.Errorf("unexpected status code: %d expected: %d", code, http.StatusNotFound)
+			}
+		}
+	}
+}
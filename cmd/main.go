@@ -1,41 +1,126 @@
 package main
 
 import (
-	"crypto/rsa"
+	"context"
 	"database/sql"
 	"flag"
+	"fmt"
 	"io/ioutil"
 	"log"
 	"net/http"
+	"os"
+	"strings"
 	"time"
 
-	"github.com/dgrijalva/jwt-go"
+	"github.com/dipress/crmifc/internal/apikey"
 	"github.com/dipress/crmifc/internal/article"
 	authSrv "github.com/dipress/crmifc/internal/auth"
 	httpBroker "github.com/dipress/crmifc/internal/broker/http"
 	"github.com/dipress/crmifc/internal/category"
+	"github.com/dipress/crmifc/internal/invitation"
 	"github.com/dipress/crmifc/internal/kit/auth"
+	"github.com/dipress/crmifc/internal/kit/mail"
+	"github.com/dipress/crmifc/internal/kit/oidc"
+	"github.com/dipress/crmifc/internal/kit/password"
 	"github.com/dipress/crmifc/internal/role"
+	"github.com/dipress/crmifc/internal/session"
 	"github.com/dipress/crmifc/internal/storage/postgres"
 	"github.com/dipress/crmifc/internal/storage/postgres/schema"
 	"github.com/dipress/crmifc/internal/user"
 	"github.com/dipress/crmifc/internal/validation"
-	"github.com/mattes/migrate"
 	"github.com/pkg/errors"
+	"golang.org/x/crypto/bcrypt"
 )
 
 const (
-	alg = "RS256"
+	defaultAlgorithm = "RS256"
 )
 
+const usage = `Usage: crmifc <command> [flags]
+
+Commands:
+  serve          run the http server
+  migrate        manage the schema: up, down [N], goto V, status, force V
+  seed           insert the development users, never use it in production
+  create-admin   create an administrator interactively
+
+Run crmifc <command> -h to see the flags of the command.
+`
+
 func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	args := os.Args[2:]
+	switch os.Args[1] {
+	case "serve":
+		serve(args)
+	case "migrate":
+		migrateSchema(args)
+	case "seed":
+		seed(args)
+	case "create-admin":
+		createAdmin(args)
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+// serve runs the http server, the schema must be migrated to the latest version.
+func serve(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
 	var (
-		addr           = flag.String("addr", ":8080", "address of http server")
-		dsn            = flag.String("dsn", "", "postgres database DSN")
-		privateKeyFile = flag.String("key", "", "private key file path")
-		keyID          = flag.String("id", "123456", "private key id")
+		addr           = fs.String("addr", ":8080", "address of http server")
+		dsn            = fs.String("dsn", "", "postgres database DSN")
+		privateKeyFile = fs.String("key", "", "private key file path")
+		keysDir        = fs.String("keys", "", "directory of <kid>.pem key files, overrides -key")
+		keyID          = fs.String("id", "123456", "active private key id")
+		retiredKeys    = fs.String("retired", "", "comma separated ids of retired keys")
+		algorithm      = fs.String("alg", defaultAlgorithm, "signing algorithm: RS256, ES256, EdDSA, etc.")
+
+		lockoutAccount = fs.Int("lockout-account", authSrv.DefaultLockout.AccountThreshold, "failed sign in attempts per account before lockout")
+		lockoutIP      = fs.Int("lockout-ip", authSrv.DefaultLockout.IPThreshold, "failed sign in attempts per client ip before lockout")
+		lockoutBase    = fs.Duration("lockout-base", authSrv.DefaultLockout.BaseDelay, "first lockout duration, doubled on each next failure")
+		lockoutMax     = fs.Duration("lockout-max", authSrv.DefaultLockout.MaxDelay, "max lockout duration")
+		lockoutWindow  = fs.Duration("lockout-window", authSrv.DefaultLockout.Window, "time after which failed attempts are forgotten")
+
+		resetURL     = fs.String("reset-url", user.DefaultResetURL, "page which resets the password by the token")
+		inviteURL    = fs.String("invite-url", invitation.DefaultAcceptURL, "page which accepts the invitation by the token")
+		inviteExpire = fs.Duration("invite-expire", invitation.DefaultExpireAfter, "lifetime of the invitation link")
+		mailFrom     = fs.String("mail-from", "noreply@localhost", "sender of emails")
+		mailDir      = fs.String("mail-dir", "mail", "directory to drop emails into when smtp isn't set")
+		smtpAddr     = fs.String("smtp-addr", "", "smtp server address host:port")
+		smtpUsername = fs.String("smtp-username", "", "smtp username")
+		smtpPassword = fs.String("smtp-password", "", "smtp password")
+
+		mfaIssuer = fs.String("mfa-issuer", authSrv.DefaultMFAIssuer, "service name shown in authenticator apps")
+		mfaExpire = fs.Duration("mfa-expire", authSrv.DefaultMFAExpireAfter, "time to pass the second step of the sign in")
+
+		oidcIssuer       = fs.String("oidc-issuer", "", "openid connect provider issuer url, enables the single sign-on")
+		oidcClientID     = fs.String("oidc-client-id", "", "openid connect client id")
+		oidcClientSecret = fs.String("oidc-client-secret", "", "openid connect client secret")
+		oidcRedirectURL  = fs.String("oidc-redirect-url", "", "callback url registered at the provider, e.g. https://crm.example.com/auth/oidc/callback")
+		oidcScopes       = fs.String("oidc-scopes", "email,profile", "comma separated scopes requested in addition to openid")
+		oidcProvision    = fs.Bool("oidc-provision", false, "create users who sign in with the provider for the first time")
+		oidcDefaultRole  = fs.String("oidc-default-role", "", "role of provisioned users whose groups don't match any role")
+
+		passwordMinLength = fs.Int("password-min-length", password.DefaultPolicy.MinLength, "min length of new passwords")
+		passwordLower     = fs.Bool("password-lower", false, "require a lowercase letter in new passwords")
+		passwordUpper     = fs.Bool("password-upper", false, "require an uppercase letter in new passwords")
+		passwordDigit     = fs.Bool("password-digit", false, "require a digit in new passwords")
+		passwordSymbol    = fs.Bool("password-symbol", false, "require a symbol in new passwords")
+		passwordPersonal  = fs.Bool("password-personal", password.DefaultPolicy.Personal, "forbid the username and the email in new passwords")
+		passwordCommon    = fs.Bool("password-common", password.DefaultPolicy.Common, "forbid the passwords of the bundled common passwords list")
+		bcryptCost        = fs.Int("bcrypt-cost", bcrypt.DefaultCost, "bcrypt cost of password hashes, lower hashes are upgraded on sign in")
 	)
-	flag.Parse()
+	fs.Parse(args)
+
+	if *bcryptCost < bcrypt.MinCost || *bcryptCost > bcrypt.MaxCost {
+		log.Fatalf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+	}
 
 	// Setup database connection.
 	db, err := sql.Open("postgres", *dsn)
@@ -44,39 +129,78 @@ func main() {
 	}
 	defer db.Close()
 
-	// Migrate schema.
-	if err := schema.Migrate(db); err != nil {
-		if errors.Cause(err) != migrate.ErrNoChange {
-			log.Fatalf("failed to migrate schema: %v", err)
-		}
-	}
-
-	// Seed data.
-	if err := schema.Seed(db); err != nil {
-		log.Fatalf("failed to seeds data: %v", err)
+	// Check schema.
+	if err := schema.Check(db); err != nil {
+		log.Fatalf("failed to check schema, run migrate up: %v", err)
 	}
 
 	// Reppositories.
 	userRepo := postgres.NewUserRepository(db)
+	tokenRepo := postgres.NewTokenRepository(db)
 
 	// Authentication setup.
-	keyContents, err := ioutil.ReadFile(*privateKeyFile)
+	keys, err := setupKeys(*keysDir, *privateKeyFile, *keyID, *retiredKeys)
 	if err != nil {
-		log.Fatalf("reading auth private key: %v", err)
+		log.Fatalf("loading auth keys: %v", err)
 	}
-
-	key, err := jwt.ParseRSAPrivateKeyFromPEM(keyContents)
+	authenticator, err := auth.NewAuthenticator(keys, *algorithm, userRepo, tokenRepo)
 	if err != nil {
-		log.Fatalf("parsing auth private key: %v", err)
+		log.Fatalf("constructing authenticator: %v", err)
 	}
-	publicKeyLookup := auth.NewSingleKeyFunc(*keyID, key.Public().(*rsa.PublicKey))
-	authenticator, err := auth.NewAuthenticator(key, *keyID, alg, publicKeyLookup, userRepo)
+
+	mailer, err := setupMailer(*smtpAddr, *smtpUsername, *smtpPassword, *mailFrom, *mailDir)
 	if err != nil {
-		log.Fatalf("constructing authenticator: %v", err)
+		log.Fatalf("constructing mailer: %v", err)
 	}
 
 	// Services
-	services := setupServices(db, authenticator)
+	services := setupServices(db, authenticator, mailer)
+	services.Auth.Lockout = authSrv.Lockout{
+		AccountThreshold: *lockoutAccount,
+		IPThreshold:      *lockoutIP,
+		BaseDelay:        *lockoutBase,
+		MaxDelay:         *lockoutMax,
+		Window:           *lockoutWindow,
+	}
+	services.Auth.MFAIssuer = *mfaIssuer
+	services.Auth.MFAExpireAfter = *mfaExpire
+	services.Auth.Cost = *bcryptCost
+
+	policy := password.Policy{
+		MinLength:     *passwordMinLength,
+		RequireLower:  *passwordLower,
+		RequireUpper:  *passwordUpper,
+		RequireDigit:  *passwordDigit,
+		RequireSymbol: *passwordSymbol,
+		Personal:      *passwordPersonal,
+		Common:        *passwordCommon,
+	}
+	services.User.Validater = &validation.User{Policy: policy}
+	services.User.Cost = *bcryptCost
+	services.Invitation.Validater = &validation.Invitation{Policy: policy}
+	services.Invitation.Cost = *bcryptCost
+
+	if *oidcIssuer != "" {
+		cfg := oidc.Config{
+			Issuer:       *oidcIssuer,
+			ClientID:     *oidcClientID,
+			ClientSecret: *oidcClientSecret,
+			RedirectURL:  *oidcRedirectURL,
+			Scopes:       strings.Split(*oidcScopes, ","),
+		}
+
+		sso, err := setupOIDC(db, cfg)
+		if err != nil {
+			log.Fatalf("constructing oidc: %v", err)
+		}
+		sso.Provision = *oidcProvision
+		sso.DefaultRole = *oidcDefaultRole
+		services.Auth.OIDC = sso
+	}
+
+	services.User.ResetURL = *resetURL
+	services.Invitation.AcceptURL = *inviteURL
+	services.Invitation.ExpireAfter = *inviteExpire
 
 	// Setup server.
 	srv := setupServer(*addr, services, authenticator)
@@ -85,30 +209,103 @@ func main() {
 	}
 }
 
+// setupKeys loads the key set from the directory when it's given,
+// otherwise uses the single private key file.
+func setupKeys(dir, file, kid, retired string) (*auth.KeySet, error) {
+	if dir != "" {
+		var ids []string
+		if retired != "" {
+			ids = strings.Split(retired, ",")
+		}
+		return auth.LoadKeySet(dir, kid, ids...)
+	}
+
+	keyContents, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading auth private key")
+	}
+
+	key, err := auth.ParsePrivateKey(keyContents)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing auth private key")
+	}
+
+	return auth.NewKeySet(kid, key)
+}
+
+// setupMailer sends emails through the smtp server when its address
+// is given, otherwise drops them into the directory.
+func setupMailer(addr, username, password, from, dir string) (mail.Mailer, error) {
+	if addr == "" {
+		return mail.NewFileMailer(dir, from), nil
+	}
+
+	return mail.NewSMTPMailer(addr, from, username, password)
+}
+
+// setupOIDC discovers the provider and prepares the single sign-on.
+func setupOIDC(db *sql.DB, cfg oidc.Config) (*authSrv.OIDC, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	provider, err := oidc.NewProvider(ctx, cfg, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "new provider")
+	}
+
+	sso := authSrv.NewOIDC(
+		provider,
+		postgres.NewOIDCStateRepository(db),
+		postgres.NewUserRepository(db),
+		postgres.NewRoleRepository(db),
+	)
+
+	return sso, nil
+}
+
 func setupServer(addr string, services *httpBroker.Services, authenticator *auth.Authenticator) *http.Server {
 	return httpBroker.NewServer(addr, services, authenticator)
 }
 
-func setupServices(db *sql.DB, authenticator *auth.Authenticator) *httpBroker.Services {
+func setupServices(db *sql.DB, authenticator *auth.Authenticator, mailer mail.Mailer) *httpBroker.Services {
 	// Repositorires
 	userRepo := postgres.NewUserRepository(db)
 	articleRepo := postgres.NewArticleRepository(db)
 	categoryRepo := postgres.NewCategoryRepository(db)
 	roleRepo := postgres.NewRoleRepository(db)
+	tokenRepo := postgres.NewTokenRepository(db)
+	attemptRepo := postgres.NewAttemptRepository(db)
+	resetRepo := postgres.NewPasswordResetRepository(db)
+	mfaRepo := postgres.NewMFARepository(db)
+	apiKeyRepo := postgres.NewAPIKeyRepository(db)
+	invitationRepo := postgres.NewInvitationRepository(db)
+	sessionRepo := postgres.NewSessionRepository(db)
+	transactor := postgres.NewTransactor(db)
 
 	// Services
-	authenticateService := authSrv.NewService(userRepo, authenticator, time.Hour*24)
+	authenticateService := authSrv.NewService(userRepo, tokenRepo, attemptRepo, mfaRepo, sessionRepo, authenticator, time.Minute*15, time.Hour*24*30)
+	apiKeyService := apikey.NewService(apiKeyRepo, userRepo, &validation.APIKey{})
 	articleService := article.NewService(articleRepo, &validation.Article{})
 	categoryService := category.NewService(categoryRepo, &validation.Category{})
 	roleService := role.NewService(roleRepo, &validation.Role{})
-	userService := user.NewService(userRepo, &validation.User{})
+	userService := user.NewService(userRepo, &validation.User{}, resetRepo, mailer)
+	invitationService := invitation.NewService(invitationRepo, userRepo, roleRepo, &validation.Invitation{}, mailer)
+	sessionService := session.NewService(sessionRepo)
+
+	articleService.Transactor = transactor
+	categoryService.Transactor = transactor
+	roleService.Transactor = transactor
+	userService.Transactor = transactor
 
 	services := httpBroker.Services{
-		Auth:     authenticateService,
-		Article:  articleService,
-		Category: categoryService,
-		Role:     roleService,
-		User:     userService,
+		Auth:       authenticateService,
+		APIKey:     apiKeyService,
+		Article:    articleService,
+		Category:   categoryService,
+		Invitation: invitationService,
+		Role:       roleService,
+		Session:    sessionService,
+		User:       userService,
 	}
 
 	return &services
@@ -1,7 +1,7 @@
 package main
 
 import (
-	"crypto/rsa"
+	"context"
 	"database/sql"
 	"flag"
 	"fmt"
@@ -12,8 +12,10 @@ import (
 
 	"github.com/DATA-DOG/go-txdb"
 	"github.com/dgrijalva/jwt-go"
+	"github.com/dipress/crmifc/internal/category"
 	"github.com/dipress/crmifc/internal/kit/auth"
 	"github.com/dipress/crmifc/internal/kit/docker"
+	"github.com/dipress/crmifc/internal/role"
 	"github.com/dipress/crmifc/internal/storage/postgres"
 	"github.com/dipress/crmifc/internal/storage/postgres/schema"
 	"github.com/ory/dockertest"
@@ -77,16 +79,49 @@ func postgresDB(t *testing.T) (db *sql.DB, teardown func() error) {
 	return db, db.Close
 }
 
+// grantPermissions grants all known permissions to the role.
+func grantPermissions(ctx context.Context, t *testing.T, repo *postgres.RoleRepository, id int) {
+	for _, p := range role.Permissions {
+		if err := repo.Grant(ctx, id, p); err != nil {
+			t.Fatalf("grant permission: %v", err)
+		}
+	}
+}
+
+// newCategory creates a category the articles of the test refer to.
+func newCategory(ctx context.Context, t *testing.T, repo *postgres.CategoryRepository, name string) int {
+	var cat category.Category
+	if err := repo.Create(ctx, &category.NewCategory{Name: name}, &cat); err != nil {
+		t.Fatalf("create category: %v", err)
+	}
+
+	return cat.ID
+}
+
+// newClaims returns claims of the user with its role and permissions.
+func newClaims(ctx context.Context, t *testing.T, repo *postgres.UserRepository, id int) auth.Claims {
+	u, err := repo.Find(ctx, id)
+	if err != nil {
+		t.Fatalf("find user: %v", err)
+	}
+
+	return auth.NewClaims(u, time.Now(), time.Hour)
+}
+
 func authenticatorSetup(db *sql.DB) *auth.Authenticator {
 	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(jwtKey))
 	if err != nil {
 		log.Fatalf("parsing auth private key: %v", err)
 	}
 
-	publicKeyLookup := auth.NewSingleKeyFunc("12345", key.Public().(*rsa.PublicKey))
+	keys, err := auth.NewKeySet("12345", key)
+	if err != nil {
+		log.Fatalf("constructing key set: %v", err)
+	}
 
 	userRepo := postgres.NewUserRepository(db)
-	ac, err := auth.NewAuthenticator(key, "12345", alg, publicKeyLookup, userRepo)
+	tokenRepo := postgres.NewTokenRepository(db)
+	ac, err := auth.NewAuthenticator(keys, defaultAlgorithm, userRepo, tokenRepo)
 	if err != nil {
 		log.Fatalf("constructing authenticator: %v", err)
 	}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	authSrv "github.com/dipress/crmifc/internal/auth"
+	"github.com/dipress/crmifc/internal/kit/mail"
+	"github.com/dipress/crmifc/internal/kit/totp"
+	"github.com/dipress/crmifc/internal/role"
+	"github.com/dipress/crmifc/internal/storage/postgres"
+	"github.com/dipress/crmifc/internal/user"
+)
+
+func TestSignInMFA(t *testing.T) {
+	t.Log("with prepared server")
+	{
+		db, teardown := postgresDB(t)
+		defer teardown()
+
+		ctx, cancel := context.WithTimeout(context.Background(), caseTimeout)
+		defer cancel()
+
+		userRepo := postgres.NewUserRepository(db)
+		roleRepo := postgres.NewRoleRepository(db)
+
+		nr := role.NewRole{
+			Name:        "Accountant",
+			MFARequired: true,
+		}
+
+		var rl role.Role
+		if err := roleRepo.Create(ctx, &nr, &rl); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		nu := user.NewUser{
+			RoleID:       rl.ID,
+			Username:     "username36",
+			Email:        "username36@example.com",
+			PasswordHash: "$2y$12$e4.VBLqKAanAZs10dRL65O8.b0kHBC34pcGCN1HdJIchCi9im40Ei",
+		}
+
+		var u user.User
+		if err := userRepo.Create(ctx, &nu, &u); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			log.Fatalf("failed to listen: %v", err)
+		}
+
+		authenticator := authenticatorSetup(db)
+
+		services := setupServices(db, authenticator, mail.NewMemoryMailer())
+
+		s := setupServer(lis.Addr().String(), services, authenticator)
+		go s.Serve(lis)
+		defer s.Close()
+
+		post := func(path, body string, v interface{}) int {
+			req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("http://%s%s", s.Addr, path), strings.NewReader(body))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if v != nil && resp.StatusCode == http.StatusOK {
+				if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			return resp.StatusCode
+		}
+
+		signIn := `{"email": "username36@example.com", "password": "password123"}`
+
+		var pending authSrv.Token
+		t.Log("\ttest:0\tshould require the enrollment of the role user.")
+		{
+			if code := post("/signin", signIn, &pending); code != http.StatusOK {
+				t.Fatalf("unexpected status code: %d expected: %d", code, http.StatusOK)
+			}
+
+			if pending.Token != "" || !pending.MFARequired || !pending.MFAEnroll || pending.MFAToken == "" {
+				t.Errorf("unexpected token: %+v", pending)
+			}
+		}
+
+		var enrollment authSrv.Enrollment
+		t.Log("\ttest:1\tshould enroll the secret with the pending token.")
+		{
+			body := fmt.Sprintf(`{"mfa_token": %q}`, pending.MFAToken)
+			if code := post("/signin/mfa/enroll", body, &enrollment); code != http.StatusOK {
+				t.Fatalf("unexpected status code: %d expected: %d", code, http.StatusOK)
+			}
+
+			if enrollment.Secret == "" || len(enrollment.QRCode) == 0 {
+				t.Errorf("unexpected enrollment: %+v", enrollment)
+			}
+		}
+
+		t.Log("\ttest:2\tshould not sign in with the wrong code.")
+		{
+			body := fmt.Sprintf(`{"mfa_token": %q, "code": "000000"}`, pending.MFAToken)
+			if code, _ := totp.Code(enrollment.Secret, time.Now()); code == "000000" {
+				body = fmt.Sprintf(`{"mfa_token": %q, "code": "111111"}`, pending.MFAToken)
+			}
+
+			if code := post("/signin/mfa", body, nil); code != http.StatusUnauthorized {
+				t.Errorf("unexpected status code: %d expected: %d", code, http.StatusUnauthorized)
+			}
+		}
+
+		t.Log("\ttest:3\tshould sign in with the code and return recovery codes.")
+		{
+			code, err := totp.Code(enrollment.Secret, time.Now())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var tkn authSrv.Token
+			body := fmt.Sprintf(`{"mfa_token": %q, "code": %q}`, pending.MFAToken, code)
+			if status := post("/signin/mfa", body, &tkn); status != http.StatusOK {
+				t.Fatalf("unexpected status code: %d expected: %d", status, http.StatusOK)
+			}
+
+			if tkn.Token == "" || len(tkn.RecoveryCodes) == 0 {
+				t.Errorf("unexpected token: %+v", tkn)
+			}
+
+			if status := post("/signin/mfa", body, nil); status != http.StatusUnauthorized {
+				t.Errorf("unexpected status code: %d expected: %d", status, http.StatusUnauthorized)
+			}
+		}
+
+		t.Log("\ttest:4\tshould require the code on the next sign in.")
+		{
+			var tkn authSrv.Token
+			if code := post("/signin", signIn, &tkn); code != http.StatusOK {
+				t.Fatalf("unexpected status code: %d expected: %d", code, http.StatusOK)
+			}
+
+			if tkn.Token != "" || !tkn.MFARequired || tkn.MFAEnroll {
+				t.Errorf("unexpected token: %+v", tkn)
+			}
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/dipress/crmifc/internal/storage/postgres/schema"
+	"github.com/mattes/migrate"
+	"github.com/pkg/errors"
+)
+
+// ErrUnknownMigrateCommand is returned when the migrate command isn't known.
+var ErrUnknownMigrateCommand = errors.New("unknown migrate command")
+
+// migrateSchema manages the schema of the database.
+func migrateSchema(args []string) {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	dsn := fs.String("dsn", "", "postgres database DSN")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: crmifc migrate [flags] up | down [N] | goto V | status | force V")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	db, err := sql.Open("postgres", *dsn)
+	if err != nil {
+		log.Fatalf("failed to connect db: %v\n", err)
+	}
+	defer db.Close()
+
+	if err := runMigrate(os.Stdout, db, fs.Args()); err != nil {
+		log.Fatalf("failed to migrate schema: %v", err)
+	}
+}
+
+// runMigrate runs the migrate command, down rolls back
+// the last migration unless the number of steps is given.
+func runMigrate(w io.Writer, db *sql.DB, args []string) error {
+	var err error
+	switch args[0] {
+	case "up":
+		err = schema.Migrate(db)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return errors.Errorf("invalid number of steps: %s", args[1])
+			}
+		}
+		err = schema.Rollback(db, steps)
+	case "goto":
+		if len(args) < 2 {
+			return errors.New("version is required")
+		}
+		version, perr := strconv.ParseUint(args[1], 10, 64)
+		if perr != nil {
+			return errors.Wrap(perr, "parse version")
+		}
+		err = schema.Goto(db, uint(version))
+	case "force":
+		if len(args) < 2 {
+			return errors.New("version is required")
+		}
+		version, perr := strconv.Atoi(args[1])
+		if perr != nil {
+			return errors.Wrap(perr, "parse version")
+		}
+		err = schema.Force(db, version)
+	case "status":
+		return migrateStatus(w, db)
+	default:
+		return ErrUnknownMigrateCommand
+	}
+
+	if err != nil && errors.Cause(err) != migrate.ErrNoChange {
+		return err
+	}
+
+	return migrateStatus(w, db)
+}
+
+// migrateStatus prints the current and the latest versions of the schema.
+func migrateStatus(w io.Writer, db *sql.DB) error {
+	latest, err := schema.Latest()
+	if err != nil {
+		return errors.Wrap(err, "latest version")
+	}
+
+	version, dirty, err := schema.Status(db)
+	if err != nil {
+		return errors.Wrap(err, "status")
+	}
+
+	fmt.Fprintf(w, "version: %d\nlatest: %d\ndirty: %t\n", version, latest, dirty)
+	return nil
+}
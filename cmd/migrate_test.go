@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/dipress/crmifc/internal/storage/postgres/schema"
+)
+
+func TestRunMigrateUnknown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := runMigrate(&buf, nil, []string{"sideways"}); err != ErrUnknownMigrateCommand {
+		t.Errorf("unexpected error: %v expected: %v", err, ErrUnknownMigrateCommand)
+	}
+}
+
+func TestRunMigrate(t *testing.T) {
+	t.Log("with prepared database")
+	{
+		db, teardown := postgresDB(t)
+		defer teardown()
+
+		latest, err := schema.Latest()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		t.Log("\ttest:0\tshould show the migrated schema.")
+		{
+			var buf bytes.Buffer
+			if err := runMigrate(&buf, db, []string{"status"}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			expect := fmt.Sprintf("version: %d\nlatest: %d\ndirty: false\n", latest, latest)
+			if buf.String() != expect {
+				t.Errorf("unexpected status: %q expected: %q", buf.String(), expect)
+			}
+		}
+
+		t.Log("\ttest:1\tshould skip the migrated schema.")
+		{
+			var buf bytes.Buffer
+			if err := runMigrate(&buf, db, []string{"up"}); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/http/cookiejar"
+	"testing"
+
+	authSrv "github.com/dipress/crmifc/internal/auth"
+	"github.com/dipress/crmifc/internal/kit/mail"
+	"github.com/dipress/crmifc/internal/kit/oidc"
+	"github.com/dipress/crmifc/internal/kit/oidc/oidctest"
+	"github.com/dipress/crmifc/internal/role"
+	"github.com/dipress/crmifc/internal/storage/postgres"
+	"github.com/dipress/crmifc/internal/user"
+)
+
+func TestOIDCSignIn(t *testing.T) {
+	t.Log("with prepared server and provider")
+	{
+		db, teardown := postgresDB(t)
+		defer teardown()
+
+		ctx, cancel := context.WithTimeout(context.Background(), caseTimeout)
+		defer cancel()
+
+		userRepo := postgres.NewUserRepository(db)
+		roleRepo := postgres.NewRoleRepository(db)
+
+		roles := make(map[string]role.Role)
+		for _, name := range []string{"Auditor", "Support"} {
+			var rl role.Role
+			if err := roleRepo.Create(ctx, &role.NewRole{Name: name}, &rl); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			roles[name] = rl
+		}
+
+		nu := user.NewUser{
+			RoleID:       roles["Auditor"].ID,
+			Username:     "username38",
+			Email:        "username38@example.com",
+			PasswordHash: "$2y$12$e4.VBLqKAanAZs10dRL65O8.b0kHBC34pcGCN1HdJIchCi9im40Ei",
+		}
+
+		var u user.User
+		if err := userRepo.Create(ctx, &nu, &u); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		idp, err := oidctest.NewServer("crm", "secret")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer idp.Close()
+
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			log.Fatalf("failed to listen: %v", err)
+		}
+
+		authenticator := authenticatorSetup(db)
+
+		services := setupServices(db, authenticator, mail.NewMemoryMailer())
+
+		sso, err := setupOIDC(db, oidc.Config{
+			Issuer:       idp.Issuer(),
+			ClientID:     "crm",
+			ClientSecret: "secret",
+			RedirectURL:  fmt.Sprintf("http://%s/auth/oidc/callback", lis.Addr()),
+			Scopes:       []string{"email", "profile"},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		services.Auth.OIDC = sso
+
+		s := setupServer(lis.Addr().String(), services, authenticator)
+		go s.Serve(lis)
+		defer s.Close()
+
+		// signIn follows the redirects from the login through
+		// the provider to the callback with the state cookie.
+		signIn := func(v interface{}) int {
+			jar, err := cookiejar.New(nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			client := http.Client{Jar: jar}
+
+			resp, err := client.Get(fmt.Sprintf("http://%s/auth/oidc/login", s.Addr))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if v != nil && resp.StatusCode == http.StatusOK {
+				if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			return resp.StatusCode
+		}
+
+		t.Log("\ttest:0\tshould sign in the user and sync the role of the group.")
+		{
+			idp.SignIn(map[string]interface{}{
+				"email":          "username38@example.com",
+				"email_verified": true,
+				"groups":         []string{"Support"},
+			})
+
+			var tkn authSrv.Token
+			if code := signIn(&tkn); code != http.StatusOK {
+				t.Fatalf("unexpected status code: %d expected: %d", code, http.StatusOK)
+			}
+
+			if tkn.Token == "" || tkn.RefreshToken == "" {
+				t.Errorf("unexpected token: %+v", tkn)
+			}
+
+			found, err := userRepo.Find(ctx, u.ID)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if found.Role.ID != roles["Support"].ID {
+				t.Errorf("unexpected role: %d expected: %d", found.Role.ID, roles["Support"].ID)
+			}
+		}
+
+		t.Log("\ttest:1\tshould not sign in the unknown user without provisioning.")
+		{
+			idp.SignIn(map[string]interface{}{
+				"email":          "username39@example.com",
+				"email_verified": true,
+				"groups":         []string{"Support"},
+			})
+
+			if code := signIn(nil); code != http.StatusForbidden {
+				t.Errorf("unexpected status code: %d expected: %d", code, http.StatusForbidden)
+			}
+		}
+
+		t.Log("\ttest:2\tshould provision the unknown user with the default role.")
+		{
+			sso.Provision = true
+			sso.DefaultRole = "Auditor"
+
+			idp.SignIn(map[string]interface{}{
+				"email":              "username39@example.com",
+				"email_verified":     true,
+				"preferred_username": "username39",
+			})
+
+			var tkn authSrv.Token
+			if code := signIn(&tkn); code != http.StatusOK {
+				t.Fatalf("unexpected status code: %d expected: %d", code, http.StatusOK)
+			}
+
+			found, err := userRepo.FindByEmail(ctx, "username39@example.com")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if found.Username != "username39" || found.Role.ID != roles["Auditor"].ID {
+				t.Errorf("unexpected user: %+v", found)
+			}
+		}
+
+		t.Log("\ttest:3\tshould not sign in without the state cookie.")
+		{
+			resp, err := http.Get(fmt.Sprintf("http://%s/auth/oidc/callback?state=state&code=code", s.Addr))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != http.StatusUnauthorized {
+				t.Errorf("unexpected status code: %d expected: %d", resp.StatusCode, http.StatusUnauthorized)
+			}
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/dipress/crmifc/internal/kit/mail"
+	"github.com/dipress/crmifc/internal/role"
+	"github.com/dipress/crmifc/internal/storage/postgres"
+	"github.com/dipress/crmifc/internal/user"
+)
+
+func TestPasswordReset(t *testing.T) {
+	t.Log("with prepared server")
+	{
+		db, teardown := postgresDB(t)
+		defer teardown()
+
+		ctx, cancel := context.WithTimeout(context.Background(), caseTimeout)
+		defer cancel()
+
+		userRepo := postgres.NewUserRepository(db)
+		roleRepo := postgres.NewRoleRepository(db)
+
+		nr := role.NewRole{
+			Name: "Manager",
+		}
+
+		var rl role.Role
+		if err := roleRepo.Create(ctx, &nr, &rl); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		nu := user.NewUser{
+			RoleID:       rl.ID,
+			Username:     "username33",
+			Email:        "username33@example.com",
+			PasswordHash: "$2y$12$e4.VBLqKAanAZs10dRL65O8.b0kHBC34pcGCN1HdJIchCi9im40Ei",
+		}
+
+		var u user.User
+		if err := userRepo.Create(ctx, &nu, &u); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			log.Fatalf("failed to listen: %v", err)
+		}
+
+		authenticator := authenticatorSetup(db)
+
+		mailer := mail.NewMemoryMailer()
+		services := setupServices(db, authenticator, mailer)
+
+		s := setupServer(lis.Addr().String(), services, authenticator)
+		go s.Serve(lis)
+		defer s.Close()
+
+		post := func(path, body string) int {
+			req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("http://%s%s", s.Addr, path), strings.NewReader(body))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			resp.Body.Close()
+			return resp.StatusCode
+		}
+
+		var token string
+		t.Log("\ttest:0\tshould send the reset link.")
+		{
+			if code := post("/password/forgot", `{"email": "username33@example.com"}`); code != http.StatusNoContent {
+				t.Errorf("unexpected status code: %d expected: %d", code, http.StatusNoContent)
+			}
+
+			messages := mailer.Messages()
+			if len(messages) != 1 {
+				t.Fatalf("unexpected messages: %+v", messages)
+			}
+
+			body := messages[0].Body
+			start := strings.Index(body, "?token=") + len("?token=")
+			token = body[start : start+strings.IndexAny(body[start:], "\n")]
+		}
+
+		t.Log("\ttest:1\tshould not tell that email is unknown.")
+		{
+			if code := post("/password/forgot", `{"email": "unknown@example.com"}`); code != http.StatusNoContent {
+				t.Errorf("unexpected status code: %d expected: %d", code, http.StatusNoContent)
+			}
+
+			if messages := mailer.Messages(); len(messages) != 1 {
+				t.Errorf("unexpected messages: %+v", messages)
+			}
+		}
+
+		t.Log("\ttest:2\tshould reset the password once.")
+		{
+			reset := fmt.Sprintf(`{"token": %q, "password": "newpassword"}`, token)
+			if code := post("/password/reset", reset); code != http.StatusNoContent {
+				t.Errorf("unexpected status code: %d expected: %d", code, http.StatusNoContent)
+			}
+
+			if code := post("/password/reset", reset); code != http.StatusUnprocessableEntity {
+				t.Errorf("unexpected status code: %d expected: %d", code, http.StatusUnprocessableEntity)
+			}
+		}
+
+		t.Log("\ttest:3\tshould sign in with the new password only.")
+		{
+			if code := post("/signin", `{"email": "username33@example.com", "password": "password123"}`); code != http.StatusUnauthorized {
+				t.Errorf("unexpected status code: %d expected: %d", code, http.StatusUnauthorized)
+			}
+
+			if code := post("/signin", `{"email": "username33@example.com", "password": "newpassword"}`); code != http.StatusOK {
+				t.Errorf("unexpected status code: %d expected: %d", code, http.StatusOK)
+			}
+		}
+	}
+}
@@ -8,9 +8,8 @@ import (
 	"net/http"
 	"strings"
 	"testing"
-	"time"
 
-	"github.com/dipress/crmifc/internal/kit/auth"
+	"github.com/dipress/crmifc/internal/kit/mail"
 	"github.com/dipress/crmifc/internal/role"
 	"github.com/dipress/crmifc/internal/storage/postgres"
 	"github.com/dipress/crmifc/internal/user"
@@ -49,7 +48,9 @@ func TestRoleCreate(t *testing.T) {
 			t.Errorf("unexpected error: %v", err)
 		}
 
-		claims := auth.NewClaims(u.Email, time.Now(), time.Hour)
+		grantPermissions(ctx, t, roleRepo, rl.ID)
+
+		claims := newClaims(ctx, t, userRepo, u.ID)
 		authenticator := authenticatorSetup(db)
 
 		token, err := authenticator.GenerateToken(ctx, claims)
@@ -64,7 +65,7 @@ func TestRoleCreate(t *testing.T) {
 			log.Fatalf("failed to listen: %v", err)
 		}
 
-		services := setupServices(db, authenticator)
+		services := setupServices(db, authenticator, mail.NewMemoryMailer())
 
 		s := setupServer(lis.Addr().String(), services, authenticator)
 		go s.Serve(lis)
@@ -126,7 +127,9 @@ func TestFindRole(t *testing.T) {
 			t.Errorf("unexpected error: %v", err)
 		}
 
-		claims := auth.NewClaims(u.Email, time.Now(), time.Hour)
+		grantPermissions(ctx, t, roleRepo, rl.ID)
+
+		claims := newClaims(ctx, t, userRepo, u.ID)
 		authenticator := authenticatorSetup(db)
 
 		token, err := authenticator.GenerateToken(ctx, claims)
@@ -141,7 +144,7 @@ func TestFindRole(t *testing.T) {
 			log.Fatalf("failed to listen: %v", err)
 		}
 
-		services := setupServices(db, authenticator)
+		services := setupServices(db, authenticator, mail.NewMemoryMailer())
 
 		s := setupServer(lis.Addr().String(), services, authenticator)
 		go s.Serve(lis)
@@ -201,7 +204,9 @@ func TestUpdateRole(t *testing.T) {
 			t.Errorf("unexpected error: %v", err)
 		}
 
-		claims := auth.NewClaims(u.Email, time.Now(), time.Hour)
+		grantPermissions(ctx, t, roleRepo, rl.ID)
+
+		claims := newClaims(ctx, t, userRepo, u.ID)
 		authenticator := authenticatorSetup(db)
 
 		token, err := authenticator.GenerateToken(ctx, claims)
@@ -216,7 +221,7 @@ func TestUpdateRole(t *testing.T) {
 			log.Fatalf("failed to listen: %v", err)
 		}
 
-		services := setupServices(db, authenticator)
+		services := setupServices(db, authenticator, mail.NewMemoryMailer())
 
 		s := setupServer(lis.Addr().String(), services, authenticator)
 		go s.Serve(lis)
@@ -228,6 +233,7 @@ func TestUpdateRole(t *testing.T) {
 			req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("http://%s/roles/%d", s.Addr, rl.ID), strings.NewReader(roleStr))
 			req.Header.Set("Content-Type", "application/json")
 			req.Header.Add("Authorization", token)
+			req.Header.Set("If-Match", `"1"`)
 
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
@@ -278,7 +284,9 @@ func TestDeleteRole(t *testing.T) {
 			t.Errorf("unexpected error: %v", err)
 		}
 
-		claims := auth.NewClaims(u.Email, time.Now(), time.Hour)
+		grantPermissions(ctx, t, roleRepo, rl.ID)
+
+		claims := newClaims(ctx, t, userRepo, u.ID)
 		authenticator := authenticatorSetup(db)
 
 		token, err := authenticator.GenerateToken(ctx, claims)
@@ -293,29 +301,46 @@ func TestDeleteRole(t *testing.T) {
 			log.Fatalf("failed to listen: %v", err)
 		}
 
-		services := setupServices(db, authenticator)
+		services := setupServices(db, authenticator, mail.NewMemoryMailer())
 
 		s := setupServer(lis.Addr().String(), services, authenticator)
 		go s.Serve(lis)
 		defer s.Close()
 
-		t.Log("\ttest:0\tshould delete a role.")
-		{
-			req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("http://%s/roles/%d", s.Addr, rl.ID), nil)
+		deleteRole := func(id int) int {
+			req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("http://%s/roles/%d", s.Addr, id), nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
 			req.Header.Set("Content-Type", "application/json")
 			req.Header.Add("Authorization", token)
+			req.Header.Set("If-Match", `"1"`)
 
+			resp, err := http.DefaultClient.Do(req)
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
+			defer resp.Body.Close()
 
-			resp, err := http.DefaultClient.Do(req)
-			if err != nil {
-				t.Errorf("unexpected error: %v", err)
+			return resp.StatusCode
+		}
+
+		t.Log("\ttest:0\tshould delete a role.")
+		{
+			var unused role.Role
+			if err := roleRepo.Create(ctx, &role.NewRole{Name: "Guest"}, &unused); err != nil {
+				t.Fatalf("unexpected error: %v", err)
 			}
 
-			if resp.StatusCode != http.StatusOK {
-				t.Errorf("unexpected status code: %d expected: %d", resp.StatusCode, http.StatusOK)
+			if code := deleteRole(unused.ID); code != http.StatusOK {
+				t.Errorf("unexpected status code: %d expected: %d", code, http.StatusOK)
+			}
+		}
+
+		t.Log("\ttest:1\tshould not delete the role of users.")
+		{
+			if code := deleteRole(rl.ID); code != http.StatusConflict {
+				t.Errorf("unexpected status code: %d expected: %d", code, http.StatusConflict)
 			}
 		}
 	}
@@ -354,7 +379,9 @@ func TestListRole(t *testing.T) {
 			t.Errorf("unexpected error: %v", err)
 		}
 
-		claims := auth.NewClaims(u.Email, time.Now(), time.Hour)
+		grantPermissions(ctx, t, roleRepo, rl.ID)
+
+		claims := newClaims(ctx, t, userRepo, u.ID)
 		authenticator := authenticatorSetup(db)
 
 		token, err := authenticator.GenerateToken(ctx, claims)
@@ -369,7 +396,7 @@ func TestListRole(t *testing.T) {
 			log.Fatalf("failed to listen: %v", err)
 		}
 
-		services := setupServices(db, authenticator)
+		services := setupServices(db, authenticator, mail.NewMemoryMailer())
 
 		s := setupServer(lis.Addr().String(), services, authenticator)
 		go s.Serve(lis)
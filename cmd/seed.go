@@ -0,0 +1,45 @@
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"log"
+
+	"github.com/dipress/crmifc/internal/storage/postgres/schema"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// seed inserts the Admin and the Manager users for the development.
+func seed(args []string) {
+	fs := flag.NewFlagSet("seed", flag.ExitOnError)
+	var (
+		dsn        = fs.String("dsn", "", "postgres database DSN")
+		dev        = fs.Bool("dev", false, "confirm the database is a development one")
+		password   = fs.String("password", "password123", "password of the seeded users")
+		bcryptCost = fs.Int("bcrypt-cost", bcrypt.DefaultCost, "bcrypt cost of the password hash")
+	)
+	fs.Parse(args)
+
+	if !*dev {
+		log.Fatal("seed inserts the users with the known password, pass -dev to confirm the database is a development one")
+	}
+
+	db, err := sql.Open("postgres", *dsn)
+	if err != nil {
+		log.Fatalf("failed to connect db: %v\n", err)
+	}
+	defer db.Close()
+
+	if err := schema.Check(db); err != nil {
+		log.Fatalf("failed to check schema, run migrate up: %v", err)
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(*password), *bcryptCost)
+	if err != nil {
+		log.Fatalf("failed to hash password: %v", err)
+	}
+
+	if err := schema.Seed(db, string(hash)); err != nil {
+		log.Fatalf("failed to seeds data: %v", err)
+	}
+}
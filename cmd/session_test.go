@@ -0,0 +1,203 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	authSrv "github.com/dipress/crmifc/internal/auth"
+	"github.com/dipress/crmifc/internal/kit/mail"
+	"github.com/dipress/crmifc/internal/role"
+	"github.com/dipress/crmifc/internal/session"
+	"github.com/dipress/crmifc/internal/storage/postgres"
+	"github.com/dipress/crmifc/internal/user"
+)
+
+func TestSessions(t *testing.T) {
+	t.Log("with prepared server")
+	{
+		db, teardown := postgresDB(t)
+		defer teardown()
+
+		ctx, cancel := context.WithTimeout(context.Background(), caseTimeout)
+		defer cancel()
+
+		userRepo := postgres.NewUserRepository(db)
+		roleRepo := postgres.NewRoleRepository(db)
+
+		nr := role.NewRole{
+			Name: "Watcher",
+		}
+
+		var rl role.Role
+		if err := roleRepo.Create(ctx, &nr, &rl); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		grantPermissions(ctx, t, roleRepo, rl.ID)
+
+		var admin, member user.User
+		for i, u := range []*user.User{&admin, &member} {
+			nu := user.NewUser{
+				RoleID:       rl.ID,
+				Username:     fmt.Sprintf("username%d", 45+i),
+				Email:        fmt.Sprintf("username%d@example.com", 45+i),
+				PasswordHash: "$2y$12$e4.VBLqKAanAZs10dRL65O8.b0kHBC34pcGCN1HdJIchCi9im40Ei",
+			}
+
+			if err := userRepo.Create(ctx, &nu, u); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+
+		authenticator := authenticatorSetup(db)
+
+		token, err := authenticator.GenerateToken(ctx, newClaims(ctx, t, userRepo, admin.ID))
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			log.Fatalf("failed to listen: %v", err)
+		}
+
+		services := setupServices(db, authenticator, mail.NewMemoryMailer())
+
+		s := setupServer(lis.Addr().String(), services, authenticator)
+		go s.Serve(lis)
+		defer s.Close()
+
+		do := func(method, path, body, token string, v interface{}) int {
+			req, err := http.NewRequest(method, fmt.Sprintf("http://%s%s", s.Addr, path), strings.NewReader(body))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("User-Agent", "sessions-test")
+			if token != "" {
+				req.Header.Set("Authorization", "Bearer "+token)
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if v != nil && resp.StatusCode == http.StatusOK {
+				if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			return resp.StatusCode
+		}
+
+		t.Log("\ttest:0\tshould record the sign in attempts.")
+		{
+			if code := do(http.MethodPost, "/signin", `{"email": "username46@example.com", "password": "wrong"}`, "", nil); code != http.StatusUnauthorized {
+				t.Errorf("unexpected status code: %d expected: %d", code, http.StatusUnauthorized)
+			}
+
+			var events session.LoginEvents
+			path := fmt.Sprintf("/login-events?filter[user_id]=%d", member.ID)
+			if code := do(http.MethodGet, path, "", token, &events); code != http.StatusOK {
+				t.Fatalf("unexpected status code: %d expected: %d", code, http.StatusOK)
+			}
+
+			if len(events.LoginEvents) != 1 || events.LoginEvents[0].Success || events.LoginEvents[0].Reason != "wrong password" {
+				t.Errorf("unexpected login events: %+v", events)
+			}
+
+			if events.LoginEvents[0].UserAgent != "sessions-test" || events.LoginEvents[0].IP != "127.0.0.1" {
+				t.Errorf("unexpected client of login event: %+v", events.LoginEvents[0])
+			}
+		}
+
+		var signed authSrv.Token
+		if code := do(http.MethodPost, "/signin", `{"email": "username46@example.com", "password": "password123"}`, "", &signed); code != http.StatusOK {
+			t.Fatalf("unexpected status code: %d expected: %d", code, http.StatusOK)
+		}
+
+		var current session.Session
+		t.Log("\ttest:1\tshould list the sessions of the current user.")
+		{
+			var sessions session.Sessions
+			if code := do(http.MethodGet, "/me/sessions", "", signed.Token, &sessions); code != http.StatusOK {
+				t.Fatalf("unexpected status code: %d expected: %d", code, http.StatusOK)
+			}
+
+			if len(sessions.Sessions) != 1 || !sessions.Sessions[0].Current {
+				t.Fatalf("unexpected sessions: %+v", sessions)
+			}
+			current = sessions.Sessions[0]
+		}
+
+		t.Log("\ttest:2\tshould list the sessions of all users.")
+		{
+			var sessions session.Sessions
+			path := fmt.Sprintf("/sessions?filter[user_id]=%d", member.ID)
+			if code := do(http.MethodGet, path, "", token, &sessions); code != http.StatusOK {
+				t.Fatalf("unexpected status code: %d expected: %d", code, http.StatusOK)
+			}
+
+			if len(sessions.Sessions) != 1 || sessions.Sessions[0].Username != "username46" {
+				t.Errorf("unexpected sessions: %+v", sessions)
+			}
+		}
+
+		t.Log("\ttest:3\tshould reject the filter which doesn't fit the column.")
+		{
+			if code := do(http.MethodGet, "/sessions?filter[user_id]=abc", "", token, nil); code != http.StatusBadRequest {
+				t.Errorf("unexpected status code: %d expected: %d", code, http.StatusBadRequest)
+			}
+
+			if code := do(http.MethodGet, "/login-events?filter[success]=abc", "", token, nil); code != http.StatusBadRequest {
+				t.Errorf("unexpected status code: %d expected: %d", code, http.StatusBadRequest)
+			}
+		}
+
+		t.Log("\ttest:4\tshould revoke the session and reject its tokens.")
+		{
+			path := fmt.Sprintf("/me/sessions/%d", current.ID)
+			if code := do(http.MethodDelete, path, "", signed.Token, nil); code != http.StatusNoContent {
+				t.Fatalf("unexpected status code: %d expected: %d", code, http.StatusNoContent)
+			}
+
+			if code := do(http.MethodGet, "/me/sessions", "", signed.Token, nil); code != http.StatusUnauthorized {
+				t.Errorf("unexpected status code: %d expected: %d", code, http.StatusUnauthorized)
+			}
+
+			body := fmt.Sprintf(`{"refresh_token": %q}`, signed.RefreshToken)
+			if code := do(http.MethodPost, "/token/refresh", body, "", nil); code != http.StatusUnauthorized {
+				t.Errorf("unexpected status code: %d expected: %d", code, http.StatusUnauthorized)
+			}
+		}
+
+		t.Log("\ttest:5\tshould not revoke the session of another user.")
+		{
+			if code := do(http.MethodPost, "/signin", `{"email": "username46@example.com", "password": "password123"}`, "", &signed); code != http.StatusOK {
+				t.Fatalf("unexpected status code: %d expected: %d", code, http.StatusOK)
+			}
+
+			var sessions session.Sessions
+			if code := do(http.MethodGet, "/me/sessions", "", signed.Token, &sessions); code != http.StatusOK || len(sessions.Sessions) != 1 {
+				t.Fatalf("unexpected sessions: %+v code: %d", sessions, code)
+			}
+
+			path := fmt.Sprintf("/me/sessions/%d", sessions.Sessions[0].ID)
+			if code := do(http.MethodDelete, path, "", token, nil); code != http.StatusNotFound {
+				t.Errorf("unexpected status code: %d expected: %d", code, http.StatusNotFound)
+			}
+
+			path = fmt.Sprintf("/sessions/%d", sessions.Sessions[0].ID)
+			if code := do(http.MethodDelete, path, "", token, nil); code != http.StatusNoContent {
+				t.Errorf("unexpected status code: %d expected: %d", code, http.StatusNoContent)
+			}
+		}
+	}
+}
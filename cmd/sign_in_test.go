@@ -3,12 +3,17 @@ package main
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
 	"log"
 	"net"
 	"net/http"
 	"strings"
 	"testing"
+	"time"
 
+	authSrv "github.com/dipress/crmifc/internal/auth"
+	"github.com/dipress/crmifc/internal/kit/auth"
+	"github.com/dipress/crmifc/internal/kit/mail"
 	"github.com/dipress/crmifc/internal/role"
 	"github.com/dipress/crmifc/internal/storage/postgres"
 	"github.com/dipress/crmifc/internal/user"
@@ -54,7 +59,7 @@ func TestSignIn(t *testing.T) {
 
 		authenticator := authenticatorSetup(db)
 
-		services := setupServices(db, authenticator)
+		services := setupServices(db, authenticator, mail.NewMemoryMailer())
 
 		s := setupServer(lis.Addr().String(), services, authenticator)
 		go s.Serve(lis)
@@ -81,3 +86,305 @@ func TestSignIn(t *testing.T) {
 		}
 	}
 }
+
+func TestRefreshAndSignOut(t *testing.T) {
+	t.Log("with prepared server")
+	{
+		db, teardown := postgresDB(t)
+		defer teardown()
+
+		ctx, cancel := context.WithTimeout(context.Background(), caseTimeout)
+		defer cancel()
+
+		userRepo := postgres.NewUserRepository(db)
+		roleRepo := postgres.NewRoleRepository(db)
+
+		nr := role.NewRole{
+			Name: "Manager",
+		}
+
+		var rl role.Role
+		if err := roleRepo.Create(ctx, &nr, &rl); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		nu := user.NewUser{
+			RoleID:       rl.ID,
+			Username:     "username31",
+			Email:        "username31@example.com",
+			PasswordHash: "$2y$12$e4.VBLqKAanAZs10dRL65O8.b0kHBC34pcGCN1HdJIchCi9im40Ei",
+		}
+
+		var u user.User
+		if err := userRepo.Create(ctx, &nu, &u); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			log.Fatalf("failed to listen: %v", err)
+		}
+
+		authenticator := authenticatorSetup(db)
+
+		services := setupServices(db, authenticator, mail.NewMemoryMailer())
+
+		s := setupServer(lis.Addr().String(), services, authenticator)
+		go s.Serve(lis)
+		defer s.Close()
+
+		post := func(path, token, body string) (*http.Response, authSrv.Token) {
+			req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("http://%s%s", s.Addr, path), strings.NewReader(body))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			req.Header.Set("Content-Type", "application/json")
+			if token != "" {
+				req.Header.Set("Authorization", "Bearer "+token)
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer resp.Body.Close()
+
+			var tkn authSrv.Token
+			if resp.StatusCode == http.StatusOK {
+				data, err := ioutil.ReadAll(resp.Body)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if err := tkn.UnmarshalJSON(data); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			return resp, tkn
+		}
+
+		_, signed := post("/signin", "", `{"email": "username31@example.com", "password": "password123"}`)
+
+		var refreshed authSrv.Token
+		t.Log("\ttest:0\tshould exchange the refresh token for new tokens.")
+		{
+			var resp *http.Response
+			resp, refreshed = post("/token/refresh", "", fmt.Sprintf(`{"refresh_token": %q}`, signed.RefreshToken))
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("unexpected status code: %d expected: %d", resp.StatusCode, http.StatusOK)
+			}
+
+			if refreshed.RefreshToken == "" || refreshed.RefreshToken == signed.RefreshToken {
+				t.Errorf("expected a new refresh token, got %q", refreshed.RefreshToken)
+			}
+		}
+
+		t.Log("\ttest:1\tshould reject the used refresh token.")
+		{
+			resp, _ := post("/token/refresh", "", fmt.Sprintf(`{"refresh_token": %q}`, signed.RefreshToken))
+			if resp.StatusCode != http.StatusUnauthorized {
+				t.Errorf("unexpected status code: %d expected: %d", resp.StatusCode, http.StatusUnauthorized)
+			}
+		}
+
+		_, signed = post("/signin", "", `{"email": "username31@example.com", "password": "password123"}`)
+
+		t.Log("\ttest:2\tshould sign out and revoke the tokens.")
+		{
+			resp, _ := post("/signout", signed.Token, fmt.Sprintf(`{"refresh_token": %q}`, signed.RefreshToken))
+			if resp.StatusCode != http.StatusNoContent {
+				t.Errorf("unexpected status code: %d expected: %d", resp.StatusCode, http.StatusNoContent)
+			}
+
+			resp, _ = post("/signout", signed.Token, "")
+			if resp.StatusCode != http.StatusUnauthorized {
+				t.Errorf("unexpected status code: %d expected: %d", resp.StatusCode, http.StatusUnauthorized)
+			}
+
+			resp, _ = post("/token/refresh", "", fmt.Sprintf(`{"refresh_token": %q}`, signed.RefreshToken))
+			if resp.StatusCode != http.StatusUnauthorized {
+				t.Errorf("unexpected status code: %d expected: %d", resp.StatusCode, http.StatusUnauthorized)
+			}
+		}
+	}
+}
+
+func TestJWKS(t *testing.T) {
+	t.Log("with prepared server")
+	{
+		db, teardown := postgresDB(t)
+		defer teardown()
+
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			log.Fatalf("failed to listen: %v", err)
+		}
+
+		authenticator := authenticatorSetup(db)
+
+		services := setupServices(db, authenticator, mail.NewMemoryMailer())
+
+		s := setupServer(lis.Addr().String(), services, authenticator)
+		go s.Serve(lis)
+		defer s.Close()
+
+		t.Log("\ttest:0\tshould publish the public keys.")
+		{
+			resp, err := http.Get(fmt.Sprintf("http://%s/.well-known/jwks.json", s.Addr))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("unexpected status code: %d expected: %d", resp.StatusCode, http.StatusOK)
+			}
+
+			data, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var set auth.JWKS
+			if err := set.UnmarshalJSON(data); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(set.Keys) != 1 || set.Keys[0].Kid != "12345" {
+				t.Errorf("unexpected keys: %+v", set.Keys)
+			}
+		}
+	}
+}
+
+func TestSignInLockout(t *testing.T) {
+	t.Log("with prepared server")
+	{
+		db, teardown := postgresDB(t)
+		defer teardown()
+
+		ctx, cancel := context.WithTimeout(context.Background(), caseTimeout)
+		defer cancel()
+
+		userRepo := postgres.NewUserRepository(db)
+		roleRepo := postgres.NewRoleRepository(db)
+
+		nr := role.NewRole{
+			Name: "Admin",
+		}
+
+		var rl role.Role
+		if err := roleRepo.Create(ctx, &nr, &rl); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		nu := user.NewUser{
+			RoleID:       rl.ID,
+			Username:     "username32",
+			Email:        "username32@example.com",
+			PasswordHash: "$2y$12$e4.VBLqKAanAZs10dRL65O8.b0kHBC34pcGCN1HdJIchCi9im40Ei",
+		}
+
+		var u user.User
+		if err := userRepo.Create(ctx, &nu, &u); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		grantPermissions(ctx, t, roleRepo, rl.ID)
+
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			log.Fatalf("failed to listen: %v", err)
+		}
+
+		authenticator := authenticatorSetup(db)
+
+		services := setupServices(db, authenticator, mail.NewMemoryMailer())
+		services.Auth.Lockout = authSrv.Lockout{
+			AccountThreshold: 3,
+			IPThreshold:      100,
+			BaseDelay:        time.Minute,
+			MaxDelay:         time.Hour,
+			Window:           time.Hour,
+		}
+
+		s := setupServer(lis.Addr().String(), services, authenticator)
+		go s.Serve(lis)
+		defer s.Close()
+
+		signInAs := func(email, password string) *http.Response {
+			authStr := fmt.Sprintf(`{"email": %q, "password": %q}`, email, password)
+			req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("http://%s/signin", s.Addr), strings.NewReader(authStr))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			resp.Body.Close()
+			return resp
+		}
+
+		signIn := func(password string) *http.Response {
+			return signInAs("username32@example.com", password)
+		}
+
+		t.Log("\ttest:0\tshould lock the account after failed attempts.")
+		{
+			for i := 0; i < 3; i++ {
+				if resp := signIn("wrong"); resp.StatusCode != http.StatusUnauthorized {
+					t.Errorf("unexpected status code: %d expected: %d", resp.StatusCode, http.StatusUnauthorized)
+				}
+			}
+
+			resp := signIn("password123")
+			if resp.StatusCode != http.StatusTooManyRequests {
+				t.Errorf("unexpected status code: %d expected: %d", resp.StatusCode, http.StatusTooManyRequests)
+			}
+
+			if resp.Header.Get("Retry-After") == "" {
+				t.Error("expected Retry-After header")
+			}
+		}
+
+		t.Log("\ttest:1\tshould unlock the account.")
+		{
+			claims := newClaims(ctx, t, userRepo, u.ID)
+
+			token, err := authenticator.GenerateToken(ctx, claims)
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("http://%s/users/%d/unlock", s.Addr, u.ID), nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			req.Header.Set("Authorization", "Bearer "+token)
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != http.StatusNoContent {
+				t.Errorf("unexpected status code: %d expected: %d", resp.StatusCode, http.StatusNoContent)
+			}
+
+			if resp := signIn("password123"); resp.StatusCode != http.StatusOK {
+				t.Errorf("unexpected status code: %d expected: %d", resp.StatusCode, http.StatusOK)
+			}
+		}
+
+		t.Log("\ttest:2\tshould count the attempt of the oversized email.")
+		{
+			email := strings.Repeat("a", 400) + "@example.com"
+			if resp := signInAs(email, "wrong"); resp.StatusCode != http.StatusUnauthorized {
+				t.Errorf("unexpected status code: %d expected: %d", resp.StatusCode, http.StatusUnauthorized)
+			}
+		}
+	}
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/dipress/crmifc/internal/article"
+	"github.com/dipress/crmifc/internal/category"
+	"github.com/dipress/crmifc/internal/kit/mail"
+	"github.com/dipress/crmifc/internal/role"
+	"github.com/dipress/crmifc/internal/storage/postgres"
+	"github.com/dipress/crmifc/internal/user"
+)
+
+func TestTrash(t *testing.T) {
+	t.Log("with prepared server")
+	{
+		db, teardown := postgresDB(t)
+		defer teardown()
+
+		ctx, cancel := context.WithTimeout(context.Background(), caseTimeout)
+		defer cancel()
+
+		userRepo := postgres.NewUserRepository(db)
+		roleRepo := postgres.NewRoleRepository(db)
+		articleRepo := postgres.NewArticleRepository(db)
+		categoryRepo := postgres.NewCategoryRepository(db)
+
+		nr := role.NewRole{
+			Name: "Janitor",
+		}
+
+		var rl role.Role
+		if err := roleRepo.Create(ctx, &nr, &rl); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		grantPermissions(ctx, t, roleRepo, rl.ID)
+
+		var admin, member user.User
+		for i, u := range []*user.User{&admin, &member} {
+			nu := user.NewUser{
+				RoleID:       rl.ID,
+				Username:     fmt.Sprintf("username%d", 43+i),
+				Email:        fmt.Sprintf("username%d@example.com", 43+i),
+				PasswordHash: "$2y$12$e4.VBLqKAanAZs10dRL65O8.b0kHBC34pcGCN1HdJIchCi9im40Ei",
+			}
+
+			if err := userRepo.Create(ctx, &nu, u); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+
+		authenticator := authenticatorSetup(db)
+
+		token, err := authenticator.GenerateToken(ctx, newClaims(ctx, t, userRepo, admin.ID))
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			log.Fatalf("failed to listen: %v", err)
+		}
+
+		services := setupServices(db, authenticator, mail.NewMemoryMailer())
+
+		s := setupServer(lis.Addr().String(), services, authenticator)
+		go s.Serve(lis)
+		defer s.Close()
+
+		do := func(method, path, body, token string, v interface{}) int {
+			req, err := http.NewRequest(method, fmt.Sprintf("http://%s%s", s.Addr, path), strings.NewReader(body))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			req.Header.Set("Content-Type", "application/json")
+			if token != "" {
+				req.Header.Set("Authorization", "Bearer "+token)
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if v != nil && resp.StatusCode == http.StatusOK {
+				if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			return resp.StatusCode
+		}
+
+		remove := func(path string) int {
+			req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("http://%s%s", s.Addr, path), nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			req.Header.Set("Authorization", "Bearer "+token)
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			resp.Body.Close()
+
+			req, err = http.NewRequest(http.MethodDelete, fmt.Sprintf("http://%s%s", s.Addr, path), nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			req.Header.Set("Authorization", "Bearer "+token)
+			req.Header.Set("If-Match", resp.Header.Get("ETag"))
+
+			resp, err = http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer resp.Body.Close()
+
+			return resp.StatusCode
+		}
+
+		signIn := `{"email": "username44@example.com", "password": "password123"}`
+
+		t.Log("\ttest:0\tshould refuse the deactivated user.")
+		{
+			if code := do(http.MethodPost, fmt.Sprintf("/users/%d/deactivate", member.ID), "", token, nil); code != http.StatusNoContent {
+				t.Fatalf("unexpected status code: %d expected: %d", code, http.StatusNoContent)
+			}
+
+			if code := do(http.MethodPost, "/signin", signIn, "", nil); code != http.StatusForbidden {
+				t.Errorf("unexpected status code: %d expected: %d", code, http.StatusForbidden)
+			}
+
+			memberToken, err := authenticator.GenerateToken(ctx, newClaims(ctx, t, userRepo, member.ID))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if code := do(http.MethodGet, "/me", "", memberToken, nil); code != http.StatusUnauthorized {
+				t.Errorf("unexpected status code: %d expected: %d", code, http.StatusUnauthorized)
+			}
+		}
+
+		t.Log("\ttest:1\tshould sign in the activated user.")
+		{
+			if code := do(http.MethodPost, fmt.Sprintf("/users/%d/activate", member.ID), "", token, nil); code != http.StatusNoContent {
+				t.Fatalf("unexpected status code: %d expected: %d", code, http.StatusNoContent)
+			}
+
+			if code := do(http.MethodPost, "/signin", signIn, "", nil); code != http.StatusOK {
+				t.Errorf("unexpected status code: %d expected: %d", code, http.StatusOK)
+			}
+		}
+
+		t.Log("\ttest:2\tshould move the deleted user to the trash and restore it.")
+		{
+			path := fmt.Sprintf("/users/%d", member.ID)
+			if code := remove(path); code != http.StatusOK {
+				t.Fatalf("unexpected status code: %d expected: %d", code, http.StatusOK)
+			}
+
+			if code := do(http.MethodGet, path, "", token, nil); code != http.StatusNotFound {
+				t.Errorf("unexpected status code: %d expected: %d", code, http.StatusNotFound)
+			}
+
+			if code := do(http.MethodPost, "/signin", signIn, "", nil); code != http.StatusUnauthorized {
+				t.Errorf("unexpected status code: %d expected: %d", code, http.StatusUnauthorized)
+			}
+
+			var trash user.Users
+			if code := do(http.MethodGet, "/trash/users", "", token, &trash); code != http.StatusOK {
+				t.Fatalf("unexpected status code: %d expected: %d", code, http.StatusOK)
+			}
+
+			if len(trash.Users) != 1 || trash.Users[0].ID != member.ID {
+				t.Errorf("unexpected trash: %+v", trash)
+			}
+
+			if code := do(http.MethodPost, fmt.Sprintf("/trash/users/%d/restore", member.ID), "", token, nil); code != http.StatusNoContent {
+				t.Fatalf("unexpected status code: %d expected: %d", code, http.StatusNoContent)
+			}
+
+			if code := do(http.MethodGet, path, "", token, nil); code != http.StatusOK {
+				t.Errorf("unexpected status code: %d expected: %d", code, http.StatusOK)
+			}
+		}
+
+		categoryID := newCategory(ctx, t, categoryRepo, "Router")
+
+		t.Log("\ttest:3\tshould restore the deleted article.")
+		{
+			var art article.Article
+			if err := articleRepo.Create(ctx, &article.NewArticle{
+				AuthorID:   member.ID,
+				CategoryID: categoryID,
+				Title:      "my title",
+				Body:       "my body",
+			}, &art); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			path := fmt.Sprintf("/articles/%d", art.ID)
+			if code := remove(path); code != http.StatusOK {
+				t.Fatalf("unexpected status code: %d expected: %d", code, http.StatusOK)
+			}
+
+			if code := do(http.MethodGet, path, "", token, nil); code != http.StatusNotFound {
+				t.Errorf("unexpected status code: %d expected: %d", code, http.StatusNotFound)
+			}
+
+			if code := do(http.MethodPost, fmt.Sprintf("/trash/articles/%d/restore", art.ID), "", token, nil); code != http.StatusNoContent {
+				t.Fatalf("unexpected status code: %d expected: %d", code, http.StatusNoContent)
+			}
+
+			var found article.Article
+			if code := do(http.MethodGet, path, "", token, &found); code != http.StatusOK {
+				t.Errorf("unexpected status code: %d expected: %d", code, http.StatusOK)
+			}
+
+			if found.AuthorUsername != member.Username {
+				t.Errorf("unexpected article: %+v", found)
+			}
+		}
+
+		t.Log("\ttest:4\tshould purge the deleted category.")
+		{
+			var cat category.Category
+			if err := categoryRepo.Create(ctx, &category.NewCategory{Name: "Archive"}, &cat); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			trashPath := fmt.Sprintf("/trash/categories/%d", cat.ID)
+			if code := do(http.MethodDelete, trashPath, "", token, nil); code != http.StatusNotFound {
+				t.Errorf("unexpected status code: %d expected: %d", code, http.StatusNotFound)
+			}
+
+			if code := remove(fmt.Sprintf("/categories/%d", cat.ID)); code != http.StatusOK {
+				t.Fatalf("unexpected status code: %d expected: %d", code, http.StatusOK)
+			}
+
+			var trash category.Categories
+			if code := do(http.MethodGet, "/trash/categories", "", token, &trash); code != http.StatusOK {
+				t.Fatalf("unexpected status code: %d expected: %d", code, http.StatusOK)
+			}
+
+			if len(trash.Categories) != 1 || trash.Categories[0].ID != cat.ID {
+				t.Errorf("unexpected trash: %+v", trash)
+			}
+
+			if code := do(http.MethodDelete, trashPath, "", token, nil); code != http.StatusNoContent {
+				t.Errorf("unexpected status code: %d expected: %d", code, http.StatusNoContent)
+			}
+
+			if code := do(http.MethodPost, trashPath+"/restore", "", token, nil); code != http.StatusNotFound {
+				t.Errorf("unexpected status code: %d expected: %d", code, http.StatusNotFound)
+			}
+		}
+
+		t.Log("\ttest:5\tshould not purge the category of articles.")
+		{
+			if code := remove(fmt.Sprintf("/categories/%d", categoryID)); code != http.StatusOK {
+				t.Fatalf("unexpected status code: %d expected: %d", code, http.StatusOK)
+			}
+
+			if code := do(http.MethodDelete, fmt.Sprintf("/trash/categories/%d", categoryID), "", token, nil); code != http.StatusConflict {
+				t.Errorf("unexpected status code: %d expected: %d", code, http.StatusConflict)
+			}
+		}
+	}
+}
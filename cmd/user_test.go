@@ -2,15 +2,16 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"net"
 	"net/http"
 	"strings"
 	"testing"
-	"time"
 
-	"github.com/dipress/crmifc/internal/kit/auth"
+	authSrv "github.com/dipress/crmifc/internal/auth"
+	"github.com/dipress/crmifc/internal/kit/mail"
 	"github.com/dipress/crmifc/internal/role"
 	"github.com/dipress/crmifc/internal/storage/postgres"
 	"github.com/dipress/crmifc/internal/user"
@@ -49,7 +50,9 @@ func TestUserCreate(t *testing.T) {
 			t.Errorf("unexpected error: %v", err)
 		}
 
-		claims := auth.NewClaims(u.Email, time.Now(), time.Hour)
+		grantPermissions(ctx, t, roleRepo, rl.ID)
+
+		claims := newClaims(ctx, t, userRepo, u.ID)
 		authenticator := authenticatorSetup(db)
 
 		token, err := authenticator.GenerateToken(ctx, claims)
@@ -64,7 +67,7 @@ func TestUserCreate(t *testing.T) {
 			log.Fatalf("failed to listen: %v", err)
 		}
 
-		services := setupServices(db, authenticator)
+		services := setupServices(db, authenticator, mail.NewMemoryMailer())
 
 		s := setupServer(lis.Addr().String(), services, authenticator)
 		go s.Serve(lis)
@@ -72,12 +75,12 @@ func TestUserCreate(t *testing.T) {
 
 		t.Log("\ttest:0\tshould create a user.")
 		{
-			userStr := `{
+			userStr := fmt.Sprintf(`{
 				"username": "dmitry",
 				"email": "dmitry@example.com",
 				"password": "password123",
-				"role_id": 1
-			}`
+				"role_id": %d
+			}`, rl.ID)
 			req, err := http.NewRequest(
 				http.MethodPost,
 				fmt.Sprintf("http://%s/users", s.Addr),
@@ -99,6 +102,32 @@ func TestUserCreate(t *testing.T) {
 				t.Errorf("unexpected status code: %d expected: %d", resp.StatusCode, http.StatusOK)
 			}
 		}
+
+		t.Log("\ttest:1\tshould refuse a user of unknown role.")
+		{
+			userStr := fmt.Sprintf(`{
+				"username": "ivan",
+				"email": "ivan@example.com",
+				"password": "password123",
+				"role_id": %d
+			}`, rl.ID+100)
+			req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("http://%s/users", s.Addr), strings.NewReader(userStr))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Add("Authorization", token)
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusUnprocessableEntity {
+				t.Errorf("unexpected status code: %d expected: %d", resp.StatusCode, http.StatusUnprocessableEntity)
+			}
+		}
 	}
 }
 
@@ -135,7 +164,9 @@ func TestFindUser(t *testing.T) {
 			t.Errorf("unexpected error: %v", err)
 		}
 
-		claims := auth.NewClaims(u.Email, time.Now(), time.Hour)
+		grantPermissions(ctx, t, roleRepo, rl.ID)
+
+		claims := newClaims(ctx, t, userRepo, u.ID)
 		authenticator := authenticatorSetup(db)
 
 		token, err := authenticator.GenerateToken(ctx, claims)
@@ -150,7 +181,7 @@ func TestFindUser(t *testing.T) {
 			log.Fatalf("failed to listen: %v", err)
 		}
 
-		services := setupServices(db, authenticator)
+		services := setupServices(db, authenticator, mail.NewMemoryMailer())
 
 		s := setupServer(lis.Addr().String(), services, authenticator)
 		go s.Serve(lis)
@@ -211,7 +242,9 @@ func TestUpdateUser(t *testing.T) {
 			t.Errorf("unexpected error: %v", err)
 		}
 
-		claims := auth.NewClaims(u.Email, time.Now(), time.Hour)
+		grantPermissions(ctx, t, roleRepo, rl.ID)
+
+		claims := newClaims(ctx, t, userRepo, u.ID)
 		authenticator := authenticatorSetup(db)
 
 		token, err := authenticator.GenerateToken(ctx, claims)
@@ -226,7 +259,7 @@ func TestUpdateUser(t *testing.T) {
 			log.Fatalf("failed to listen: %v", err)
 		}
 
-		services := setupServices(db, authenticator)
+		services := setupServices(db, authenticator, mail.NewMemoryMailer())
 
 		s := setupServer(lis.Addr().String(), services, authenticator)
 		go s.Serve(lis)
@@ -234,15 +267,16 @@ func TestUpdateUser(t *testing.T) {
 
 		t.Log("\ttest:0\tshould update a user.")
 		{
-			userStr := `{
+			userStr := fmt.Sprintf(`{
 				"username": "roman",
 				"email": "roman@example.com",
 				"password": "password1234",
-				"role_id": 1
-			}`
+				"role_id": %d
+			}`, rl.ID)
 			req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("http://%s/users/%d", s.Addr, u.ID), strings.NewReader(userStr))
 			req.Header.Set("Content-Type", "application/json")
 			req.Header.Add("Authorization", token)
+			req.Header.Set("If-Match", `"1"`)
 
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
@@ -293,7 +327,9 @@ func TestDeleteUser(t *testing.T) {
 			t.Errorf("unexpected error: %v", err)
 		}
 
-		claims := auth.NewClaims(u.Email, time.Now(), time.Hour)
+		grantPermissions(ctx, t, roleRepo, rl.ID)
+
+		claims := newClaims(ctx, t, userRepo, u.ID)
 		authenticator := authenticatorSetup(db)
 
 		token, err := authenticator.GenerateToken(ctx, claims)
@@ -308,7 +344,7 @@ func TestDeleteUser(t *testing.T) {
 			log.Fatalf("failed to listen: %v", err)
 		}
 
-		services := setupServices(db, authenticator)
+		services := setupServices(db, authenticator, mail.NewMemoryMailer())
 
 		s := setupServer(lis.Addr().String(), services, authenticator)
 		go s.Serve(lis)
@@ -319,6 +355,7 @@ func TestDeleteUser(t *testing.T) {
 			req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("http://%s/users/%d", s.Addr, u.ID), nil)
 			req.Header.Set("Content-Type", "application/json")
 			req.Header.Add("Authorization", token)
+			req.Header.Set("If-Match", `"1"`)
 
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
@@ -369,7 +406,9 @@ func TestListUser(t *testing.T) {
 			t.Errorf("unexpected error: %v", err)
 		}
 
-		claims := auth.NewClaims(u.Email, time.Now(), time.Hour)
+		grantPermissions(ctx, t, roleRepo, rl.ID)
+
+		claims := newClaims(ctx, t, userRepo, u.ID)
 		authenticator := authenticatorSetup(db)
 
 		token, err := authenticator.GenerateToken(ctx, claims)
@@ -384,7 +423,7 @@ func TestListUser(t *testing.T) {
 			log.Fatalf("failed to listen: %v", err)
 		}
 
-		services := setupServices(db, authenticator)
+		services := setupServices(db, authenticator, mail.NewMemoryMailer())
 
 		s := setupServer(lis.Addr().String(), services, authenticator)
 		go s.Serve(lis)
@@ -411,3 +450,260 @@ func TestListUser(t *testing.T) {
 		}
 	}
 }
+
+func TestMe(t *testing.T) {
+	t.Log("with prepared server")
+	{
+		db, teardown := postgresDB(t)
+		defer teardown()
+
+		ctx, cancel := context.WithTimeout(context.Background(), caseTimeout)
+		defer cancel()
+
+		userRepo := postgres.NewUserRepository(db)
+		roleRepo := postgres.NewRoleRepository(db)
+
+		nr := role.NewRole{
+			Name: "Guest",
+		}
+
+		var rl role.Role
+		if err := roleRepo.Create(ctx, &nr, &rl); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		nu := user.NewUser{
+			RoleID:       rl.ID,
+			Username:     "username34",
+			Email:        "username34@example.com",
+			PasswordHash: "$2y$12$e4.VBLqKAanAZs10dRL65O8.b0kHBC34pcGCN1HdJIchCi9im40Ei",
+		}
+
+		var u user.User
+		if err := userRepo.Create(ctx, &nu, &u); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		claims := newClaims(ctx, t, userRepo, u.ID)
+		authenticator := authenticatorSetup(db)
+
+		token, err := authenticator.GenerateToken(ctx, claims)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		token = "Bearer " + token
+
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			log.Fatalf("failed to listen: %v", err)
+		}
+
+		services := setupServices(db, authenticator, mail.NewMemoryMailer())
+
+		s := setupServer(lis.Addr().String(), services, authenticator)
+		go s.Serve(lis)
+		defer s.Close()
+
+		do := func(method, path, body string) int {
+			req, err := http.NewRequest(method, fmt.Sprintf("http://%s%s", s.Addr, path), strings.NewReader(body))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Add("Authorization", token)
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			resp.Body.Close()
+			return resp.StatusCode
+		}
+
+		t.Log("\ttest:0\tshould show the user without permissions.")
+		{
+			if code := do(http.MethodGet, "/me", ""); code != http.StatusOK {
+				t.Errorf("unexpected status code: %d expected: %d", code, http.StatusOK)
+			}
+		}
+
+		t.Log("\ttest:1\tshould update the user but not the role.")
+		{
+			body := `{"username": "username35", "email": "username35@example.com", "role_id": 1}`
+			if code := do(http.MethodPatch, "/me", body); code != http.StatusOK {
+				t.Errorf("unexpected status code: %d expected: %d", code, http.StatusOK)
+			}
+
+			updated, err := userRepo.Find(ctx, u.ID)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if updated.Username != "username35" || updated.Role.ID != rl.ID {
+				t.Errorf("unexpected user: %+v", updated)
+			}
+		}
+
+		t.Log("\ttest:2\tshould not change the password with the wrong one.")
+		{
+			body := `{"old_password": "wrong", "new_password": "newpassword"}`
+			if code := do(http.MethodPost, "/me/password", body); code != http.StatusUnprocessableEntity {
+				t.Errorf("unexpected status code: %d expected: %d", code, http.StatusUnprocessableEntity)
+			}
+		}
+
+		t.Log("\ttest:3\tshould change the password and revoke the refresh tokens.")
+		{
+			resp, err := http.Post(fmt.Sprintf("http://%s/signin", s.Addr), "application/json",
+				strings.NewReader(`{"email": "username35@example.com", "password": "password123"}`))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer resp.Body.Close()
+
+			var signed authSrv.Token
+			if err := json.NewDecoder(resp.Body).Decode(&signed); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			body := `{"old_password": "password123", "new_password": "newpassword"}`
+			if code := do(http.MethodPost, "/me/password", body); code != http.StatusNoContent {
+				t.Errorf("unexpected status code: %d expected: %d", code, http.StatusNoContent)
+			}
+
+			refresh := fmt.Sprintf(`{"refresh_token": %q}`, signed.RefreshToken)
+			if code := do(http.MethodPost, "/token/refresh", refresh); code != http.StatusUnauthorized {
+				t.Errorf("unexpected status code: %d expected: %d", code, http.StatusUnauthorized)
+			}
+		}
+	}
+}
+
+func TestUserPatch(t *testing.T) {
+	t.Log("with prepared server")
+	{
+		db, teardown := postgresDB(t)
+		defer teardown()
+
+		ctx, cancel := context.WithTimeout(context.Background(), caseTimeout)
+		defer cancel()
+
+		userRepo := postgres.NewUserRepository(db)
+		roleRepo := postgres.NewRoleRepository(db)
+
+		nr := role.NewRole{
+			Name: "Editor",
+		}
+
+		var rl role.Role
+		if err := roleRepo.Create(ctx, &nr, &rl); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		grantPermissions(ctx, t, roleRepo, rl.ID)
+
+		var admin, member user.User
+		for i, u := range []*user.User{&admin, &member} {
+			nu := user.NewUser{
+				RoleID:       rl.ID,
+				Username:     fmt.Sprintf("username%d", 47+i),
+				Email:        fmt.Sprintf("username%d@example.com", 47+i),
+				PasswordHash: "$2y$12$e4.VBLqKAanAZs10dRL65O8.b0kHBC34pcGCN1HdJIchCi9im40Ei",
+			}
+
+			if err := userRepo.Create(ctx, &nu, u); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+
+		authenticator := authenticatorSetup(db)
+
+		token, err := authenticator.GenerateToken(ctx, newClaims(ctx, t, userRepo, admin.ID))
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			log.Fatalf("failed to listen: %v", err)
+		}
+
+		services := setupServices(db, authenticator, mail.NewMemoryMailer())
+
+		s := setupServer(lis.Addr().String(), services, authenticator)
+		go s.Serve(lis)
+		defer s.Close()
+
+		etag := `"1"`
+		do := func(method, path, body, token string) int {
+			req, err := http.NewRequest(method, fmt.Sprintf("http://%s%s", s.Addr, path), strings.NewReader(body))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			req.Header.Set("Content-Type", "application/merge-patch+json")
+			req.Header.Set("If-Match", etag)
+			if token != "" {
+				req.Header.Set("Authorization", "Bearer "+token)
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if e := resp.Header.Get("ETag"); e != "" && resp.StatusCode == http.StatusOK {
+				etag = e
+			}
+			return resp.StatusCode
+		}
+
+		path := fmt.Sprintf("/users/%d", member.ID)
+
+		t.Log("\ttest:0\tshould change the email and keep the password.")
+		{
+			if code := do(http.MethodPatch, path, `{"email": "username48@example.org"}`, token); code != http.StatusOK {
+				t.Fatalf("unexpected status code: %d expected: %d", code, http.StatusOK)
+			}
+
+			u, err := userRepo.Find(ctx, member.ID)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if u.Email != "username48@example.org" || u.Username != "username48" || u.Role.ID != rl.ID {
+				t.Errorf("unexpected user: %+v", u)
+			}
+
+			if code := do(http.MethodPost, "/signin", `{"email": "username48@example.org", "password": "password123"}`, ""); code != http.StatusOK {
+				t.Errorf("unexpected status code: %d expected: %d", code, http.StatusOK)
+			}
+		}
+
+		t.Log("\ttest:1\tshould change the supplied password.")
+		{
+			if code := do(http.MethodPatch, path, `{"password": "correct horse battery"}`, token); code != http.StatusOK {
+				t.Fatalf("unexpected status code: %d expected: %d", code, http.StatusOK)
+			}
+
+			if code := do(http.MethodPost, "/signin", `{"email": "username48@example.org", "password": "correct horse battery"}`, ""); code != http.StatusOK {
+				t.Errorf("unexpected status code: %d expected: %d", code, http.StatusOK)
+			}
+		}
+
+		t.Log("\ttest:2\tshould refuse the invalid merged user.")
+		{
+			if code := do(http.MethodPatch, path, `{"username": null}`, token); code != http.StatusUnprocessableEntity {
+				t.Errorf("unexpected status code: %d expected: %d", code, http.StatusUnprocessableEntity)
+			}
+
+			if code := do(http.MethodPatch, path, `["email"]`, token); code != http.StatusBadRequest {
+				t.Errorf("unexpected status code: %d expected: %d", code, http.StatusBadRequest)
+			}
+
+			if code := do(http.MethodPatch, fmt.Sprintf("/users/%d", member.ID+100), `{}`, token); code != http.StatusNotFound {
+				t.Errorf("unexpected status code: %d expected: %d", code, http.StatusNotFound)
+			}
+		}
+	}
+}
@@ -1,19 +1,29 @@
 package abillity
 
-import "github.com/dipress/crmifc/internal/user"
-
-const (
-	// ADMIN is a role name.
-	ADMIN = "Admin"
+import (
+	"github.com/dipress/crmifc/internal/role"
+	"github.com/dipress/crmifc/internal/user"
 )
 
-// UserAbillity allows checking ability to view by user role.
+// UserAbillity allows checking ability to act by user role.
 type UserAbillity struct{}
 
-// CanAdmin checks that user have a admin role.
-func (a UserAbillity) CanAdmin(u *user.User) bool {
-	if u.Role.Name == ADMIN {
-		return true
+// Can checks that user role is granted with any of given permissions.
+func (a UserAbillity) Can(u *user.User, permissions ...role.Permission) bool {
+	for _, p := range permissions {
+		if u.Role.Can(p) {
+			return true
+		}
 	}
 	return false
 }
+
+// CanChange checks that user is allowed to change the item created
+// by author, either with the permission for all items or with
+// the permission for own items.
+func (a UserAbillity) CanChange(u *user.User, authorID int, all, own role.Permission) bool {
+	if u.Role.Can(all) {
+		return true
+	}
+	return u.Role.Can(own) && u.ID == authorID
+}
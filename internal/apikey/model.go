@@ -0,0 +1,67 @@
+package apikey
+
+import (
+	"errors"
+	"time"
+
+	"github.com/dipress/crmifc/internal/role"
+)
+
+// easyjson -all model.go
+
+var (
+	// ErrNotFound raises when api key isn't found in the database.
+	ErrNotFound = errors.New("api key not found")
+	// ErrInvalidKey returns when the api key is unknown,
+	// expired or was revoked.
+	ErrInvalidKey = errors.New("invalid api key")
+	// ErrScopeNotGranted returns when the scope of the new
+	// key isn't granted to the role of the user.
+	ErrScopeNotGranted = errors.New("scope is not granted")
+	// ErrKeyAuthenticated returns when api keys are managed by
+	// the request which is authenticated with an api key.
+	ErrKeyAuthenticated = errors.New("authenticated with api key")
+)
+
+// APIKey contains all api key fields. Only the hash of
+// the key is stored, the prefix tells keys apart.
+type APIKey struct {
+	ID         int               `json:"id"`
+	UserID     int               `json:"-"`
+	Name       string            `json:"name"`
+	Prefix     string            `json:"prefix"`
+	Scopes     []role.Permission `json:"scopes"`
+	ExpiresAt  time.Time         `json:"expires_at"`
+	LastUsedAt *time.Time        `json:"last_used_at"`
+	CreatedAt  time.Time         `json:"created_at"`
+}
+
+// NewAPIKey contains the information which needs to create a new APIKey.
+type NewAPIKey struct {
+	UserID    int
+	Name      string
+	Prefix    string
+	Hash      string
+	Scopes    []role.Permission
+	ExpiresAt time.Time
+}
+
+// Form is an api key form.
+type Form struct {
+	Name   string            `json:"name"`
+	Scopes []role.Permission `json:"scopes"`
+	// ExpiresIn is the lifetime of the key in days.
+	ExpiresIn int `json:"expires_in"`
+}
+
+// Created holds the new api key. The key
+// itself is shown only once.
+type Created struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+// APIKeys contains slice of api keys.
+type APIKeys struct {
+	APIKeys []APIKey `json:"api_keys"`
+}
@@ -0,0 +1,198 @@
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/dipress/crmifc/internal/kit/auth"
+	"github.com/dipress/crmifc/internal/role"
+	"github.com/dipress/crmifc/internal/user"
+	"github.com/pkg/errors"
+)
+
+// go:generate mockgen -source=service.go -package=apikey -destination=service.mock.go
+
+const (
+	// KeyPrefix starts each api key, so the keys are told
+	// apart from the tokens in the Authorization header.
+	KeyPrefix = "crm_"
+
+	// prefixLength is the length of the stored key prefix
+	// which is shown in the key list.
+	prefixLength = 12
+)
+
+// Repository allows to work with the database.
+type Repository interface {
+	Create(ctx context.Context, nk *NewAPIKey, k *APIKey) error
+	List(ctx context.Context, userID int, keys *APIKeys) error
+	Delete(ctx context.Context, userID, id int) error
+	Use(ctx context.Context, hash string) (*APIKey, error)
+}
+
+// UserRepository finds the owners of the api keys.
+type UserRepository interface {
+	Find(ctx context.Context, id int) (*user.User, error)
+}
+
+// Validater validates api key fields.
+type Validater interface {
+	Validate(ctx context.Context, form *Form) error
+}
+
+// Service is a use case for api keys of the users.
+type Service struct {
+	Repository
+	UserRepository
+	Validater
+}
+
+// NewService factory prepares service for all futher operations.
+func NewService(r Repository, ur UserRepository, v Validater) *Service {
+	s := Service{
+		Repository:     r,
+		UserRepository: ur,
+		Validater:      v,
+	}
+
+	return &s
+}
+
+// Create creates the api key of the user with the claims. The scopes
+// have to be granted to the role of the user. Keys can't be created
+// with another key, so a key never widens its own scopes.
+func (s *Service) Create(ctx context.Context, c *auth.Claims, f *Form) (*Created, error) {
+	if c.APIKeyID != 0 {
+		return nil, ErrKeyAuthenticated
+	}
+
+	if err := s.Validater.Validate(ctx, f); err != nil {
+		return nil, errors.Wrap(err, "validater validate")
+	}
+
+	for _, p := range f.Scopes {
+		if !c.User.Role.Can(p) {
+			return nil, ErrScopeNotGranted
+		}
+	}
+
+	key, err := newKey()
+	if err != nil {
+		return nil, errors.Wrap(err, "new key")
+	}
+
+	nk := NewAPIKey{
+		UserID:    c.User.ID,
+		Name:      f.Name,
+		Prefix:    key[:prefixLength],
+		Hash:      hashKey(key),
+		Scopes:    f.Scopes,
+		ExpiresAt: time.Now().AddDate(0, 0, f.ExpiresIn),
+	}
+
+	var created Created
+	if err := s.Repository.Create(ctx, &nk, &created.APIKey); err != nil {
+		return nil, errors.Wrap(err, "repository create api key")
+	}
+	created.Key = key
+
+	return &created, nil
+}
+
+// List lists the api keys of the user with the claims.
+func (s *Service) List(ctx context.Context, c *auth.Claims) (*APIKeys, error) {
+	var keys APIKeys
+	if err := s.Repository.List(ctx, c.User.ID, &keys); err != nil {
+		return nil, errors.Wrap(err, "repository list api keys")
+	}
+	return &keys, nil
+}
+
+// Delete revokes the api key of the user with the claims.
+func (s *Service) Delete(ctx context.Context, c *auth.Claims, id int) error {
+	if c.APIKeyID != 0 {
+		return ErrKeyAuthenticated
+	}
+
+	if err := s.Repository.Delete(ctx, c.User.ID, id); err != nil {
+		return errors.Wrap(err, "repository delete api key")
+	}
+	return nil
+}
+
+// ParseKey recreates the claims of the api key owner. The permissions
+// are the scopes of the key which are still granted to the role.
+// Keys of the deactivated users don't work.
+func (s *Service) ParseKey(ctx context.Context, key string) (auth.Claims, error) {
+	if !strings.HasPrefix(key, KeyPrefix) {
+		return auth.Claims{}, ErrInvalidKey
+	}
+
+	k, err := s.Repository.Use(ctx, hashKey(key))
+	if err != nil {
+		if errors.Cause(err) == ErrNotFound {
+			return auth.Claims{}, ErrInvalidKey
+		}
+		return auth.Claims{}, errors.Wrap(err, "use api key")
+	}
+
+	u, err := s.UserRepository.Find(ctx, k.UserID)
+	if err != nil {
+		if errors.Cause(err) == user.ErrNotFound {
+			return auth.Claims{}, ErrInvalidKey
+		}
+		return auth.Claims{}, errors.Wrap(err, "find user")
+	}
+
+	if u.DisabledAt != nil {
+		return auth.Claims{}, ErrInvalidKey
+	}
+
+	permissions := make([]role.Permission, 0, len(k.Scopes))
+	for _, p := range k.Scopes {
+		if u.Role.Can(p) {
+			permissions = append(permissions, p)
+		}
+	}
+	u.Role.Permissions = permissions
+
+	c := auth.Claims{
+		StandardClaims: jwt.StandardClaims{
+			Subject:   u.Email,
+			ExpiresAt: k.ExpiresAt.Unix(),
+		},
+		UserID:       u.ID,
+		Username:     u.Username,
+		RoleID:       u.Role.ID,
+		RoleName:     u.Role.Name,
+		Permissions:  permissions,
+		TokenVersion: u.TokenVersion,
+		User:         *u,
+		APIKeyID:     k.ID,
+	}
+
+	return c, nil
+}
+
+// newKey returns random api key with the key prefix.
+func newKey() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Wrap(err, "read random bytes")
+	}
+	return KeyPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashKey returns the hash of the api key which is stored instead
+// of the key itself. The key is random and long enough, so a fast
+// hash is sufficient.
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,167 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: service.go
+
+// Package apikey is a generated GoMock package.
+package apikey
+
+import (
+	context "context"
+	user "github.com/dipress/crmifc/internal/user"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockRepository is a mock of Repository interface
+type MockRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepositoryMockRecorder
+}
+
+// MockRepositoryMockRecorder is the mock recorder for MockRepository
+type MockRepositoryMockRecorder struct {
+	mock *MockRepository
+}
+
+// NewMockRepository creates a new mock instance
+func NewMockRepository(ctrl *gomock.Controller) *MockRepository {
+	mock := &MockRepository{ctrl: ctrl}
+	mock.recorder = &MockRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method
+func (m *MockRepository) Create(ctx context.Context, nk *NewAPIKey, k *APIKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, nk, k)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create
+func (mr *MockRepositoryMockRecorder) Create(ctx, nk, k interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRepository)(nil).Create), ctx, nk, k)
+}
+
+// List mocks base method
+func (m *MockRepository) List(ctx context.Context, userID int, keys *APIKeys) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, userID, keys)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// List indicates an expected call of List
+func (mr *MockRepositoryMockRecorder) List(ctx, userID, keys interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockRepository)(nil).List), ctx, userID, keys)
+}
+
+// Delete mocks base method
+func (m *MockRepository) Delete(ctx context.Context, userID, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, userID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockRepositoryMockRecorder) Delete(ctx, userID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockRepository)(nil).Delete), ctx, userID, id)
+}
+
+// Use mocks base method
+func (m *MockRepository) Use(ctx context.Context, hash string) (*APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Use", ctx, hash)
+	ret0, _ := ret[0].(*APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Use indicates an expected call of Use
+func (mr *MockRepositoryMockRecorder) Use(ctx, hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Use", reflect.TypeOf((*MockRepository)(nil).Use), ctx, hash)
+}
+
+// MockUserRepository is a mock of UserRepository interface
+type MockUserRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserRepositoryMockRecorder
+}
+
+// MockUserRepositoryMockRecorder is the mock recorder for MockUserRepository
+type MockUserRepositoryMockRecorder struct {
+	mock *MockUserRepository
+}
+
+// NewMockUserRepository creates a new mock instance
+func NewMockUserRepository(ctrl *gomock.Controller) *MockUserRepository {
+	mock := &MockUserRepository{ctrl: ctrl}
+	mock.recorder = &MockUserRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockUserRepository) EXPECT() *MockUserRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Find mocks base method
+func (m *MockUserRepository) Find(ctx context.Context, id int) (*user.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Find", ctx, id)
+	ret0, _ := ret[0].(*user.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Find indicates an expected call of Find
+func (mr *MockUserRepositoryMockRecorder) Find(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockUserRepository)(nil).Find), ctx, id)
+}
+
+// MockValidater is a mock of Validater interface
+type MockValidater struct {
+	ctrl     *gomock.Controller
+	recorder *MockValidaterMockRecorder
+}
+
+// MockValidaterMockRecorder is the mock recorder for MockValidater
+type MockValidaterMockRecorder struct {
+	mock *MockValidater
+}
+
+// NewMockValidater creates a new mock instance
+func NewMockValidater(ctrl *gomock.Controller) *MockValidater {
+	mock := &MockValidater{ctrl: ctrl}
+	mock.recorder = &MockValidaterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockValidater) EXPECT() *MockValidaterMockRecorder {
+	return m.recorder
+}
+
+// Validate mocks base method
+func (m *MockValidater) Validate(ctx context.Context, form *Form) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Validate", ctx, form)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Validate indicates an expected call of Validate
+func (mr *MockValidaterMockRecorder) Validate(ctx, form interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Validate", reflect.TypeOf((*MockValidater)(nil).Validate), ctx, form)
+}
@@ -0,0 +1,209 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dipress/crmifc/internal/kit/auth"
+	"github.com/dipress/crmifc/internal/role"
+	"github.com/dipress/crmifc/internal/user"
+	gomock "github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Create_Service(t *testing.T) {
+	tests := []struct {
+		name           string
+		claims         auth.Claims
+		scopes         []role.Permission
+		repositoryFunc func(mock *MockRepository)
+		validaterFunc  func(mock *MockValidater)
+		wantErr        error
+	}{
+		{
+			name:   "ok",
+			scopes: []role.Permission{role.ArticlesView},
+			repositoryFunc: func(m *MockRepository) {
+				m.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, nk *NewAPIKey, k *APIKey) error {
+						if nk.UserID != 1 || len(nk.Hash) != 64 || !strings.HasPrefix(nk.Prefix, KeyPrefix) {
+							t.Errorf("unexpected new key: %+v", nk)
+						}
+						return nil
+					})
+			},
+			validaterFunc: func(m *MockValidater) {
+				m.EXPECT().Validate(gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:   "scope is not granted",
+			scopes: []role.Permission{role.UsersManage},
+			validaterFunc: func(m *MockValidater) {
+				m.EXPECT().Validate(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			repositoryFunc: func(m *MockRepository) {},
+			wantErr:        ErrScopeNotGranted,
+		},
+		{
+			name:           "authenticated with api key",
+			claims:         auth.Claims{APIKeyID: 1},
+			scopes:         []role.Permission{role.ArticlesView},
+			validaterFunc:  func(m *MockValidater) {},
+			repositoryFunc: func(m *MockRepository) {},
+			wantErr:        ErrKeyAuthenticated,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := NewMockRepository(ctrl)
+			validater := NewMockValidater(ctrl)
+			s := NewService(repo, NewMockUserRepository(ctrl), validater)
+
+			tc.repositoryFunc(repo)
+			tc.validaterFunc(validater)
+
+			claims := tc.claims
+			claims.User = user.User{
+				ID: 1,
+				Role: role.Role{
+					Permissions: []role.Permission{role.ArticlesView},
+				},
+			}
+
+			created, err := s.Create(context.Background(), &claims, &Form{Name: "ci", Scopes: tc.scopes, ExpiresIn: 30})
+			if tc.wantErr != nil {
+				assert.Equal(t, tc.wantErr, err)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.True(t, strings.HasPrefix(created.Key, KeyPrefix))
+		})
+	}
+}
+
+func Test_ParseKey_Service(t *testing.T) {
+	key := KeyPrefix + "key"
+
+	tests := []struct {
+		name           string
+		key            string
+		repositoryFunc func(mock *MockRepository)
+		userRepoFunc   func(mock *MockUserRepository)
+		permissions    []role.Permission
+		wantErr        error
+	}{
+		{
+			name: "ok",
+			key:  key,
+			repositoryFunc: func(m *MockRepository) {
+				m.EXPECT().Use(gomock.Any(), hashKey(key)).Return(&APIKey{
+					ID:     2,
+					UserID: 1,
+					Scopes: []role.Permission{role.ArticlesView, role.UsersManage},
+				}, nil)
+			},
+			userRepoFunc: func(m *MockUserRepository) {
+				m.EXPECT().Find(gomock.Any(), 1).Return(&user.User{
+					ID: 1,
+					Role: role.Role{
+						Permissions: []role.Permission{role.ArticlesView, role.ArticlesCreate},
+					},
+				}, nil)
+			},
+			permissions: []role.Permission{role.ArticlesView},
+		},
+		{
+			name:           "without prefix",
+			key:            "token",
+			repositoryFunc: func(m *MockRepository) {},
+			userRepoFunc:   func(m *MockUserRepository) {},
+			wantErr:        ErrInvalidKey,
+		},
+		{
+			name: "unknown key",
+			key:  key,
+			repositoryFunc: func(m *MockRepository) {
+				m.EXPECT().Use(gomock.Any(), gomock.Any()).Return(nil, ErrNotFound)
+			},
+			userRepoFunc: func(m *MockUserRepository) {},
+			wantErr:      ErrInvalidKey,
+		},
+		{
+			name: "user not found",
+			key:  key,
+			repositoryFunc: func(m *MockRepository) {
+				m.EXPECT().Use(gomock.Any(), gomock.Any()).Return(&APIKey{UserID: 1}, nil)
+			},
+			userRepoFunc: func(m *MockUserRepository) {
+				m.EXPECT().Find(gomock.Any(), 1).Return(nil, user.ErrNotFound)
+			},
+			wantErr: ErrInvalidKey,
+		},
+		{
+			name: "user deactivated",
+			key:  key,
+			repositoryFunc: func(m *MockRepository) {
+				m.EXPECT().Use(gomock.Any(), gomock.Any()).Return(&APIKey{UserID: 1}, nil)
+			},
+			userRepoFunc: func(m *MockUserRepository) {
+				disabledAt := time.Now()
+				m.EXPECT().Find(gomock.Any(), 1).Return(&user.User{ID: 1, DisabledAt: &disabledAt}, nil)
+			},
+			wantErr: ErrInvalidKey,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := NewMockRepository(ctrl)
+			userRepo := NewMockUserRepository(ctrl)
+			s := NewService(repo, userRepo, NewMockValidater(ctrl))
+
+			tc.repositoryFunc(repo)
+			tc.userRepoFunc(userRepo)
+
+			claims, err := s.ParseKey(context.Background(), tc.key)
+			if tc.wantErr != nil {
+				assert.Equal(t, tc.wantErr, err)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, 2, claims.APIKeyID)
+			assert.Equal(t, tc.permissions, claims.Permissions)
+			assert.Equal(t, tc.permissions, claims.User.Role.Permissions)
+		})
+	}
+}
+
+func Test_Delete_Service(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := NewMockRepository(ctrl)
+	s := NewService(repo, NewMockUserRepository(ctrl), NewMockValidater(ctrl))
+
+	repo.EXPECT().Delete(gomock.Any(), 1, 2).Return(errors.New("mock error"))
+
+	claims := auth.Claims{User: user.User{ID: 1}}
+	assert.NotNil(t, s.Delete(context.Background(), &claims, 2))
+
+	claims.APIKeyID = 3
+	assert.Equal(t, ErrKeyAuthenticated, s.Delete(context.Background(), &claims, 2))
+}
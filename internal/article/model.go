@@ -3,6 +3,8 @@ package article
 import (
 	"errors"
 	"time"
+
+	"github.com/dipress/crmifc/internal/kit/diff"
 )
 
 // easyjson -all model.go
@@ -10,22 +12,40 @@ import (
 var (
 	// ErrNotFound raises when article not found in the database.
 	ErrNotFound = errors.New("article not found")
+	// ErrEmptySearch raises when search query is blank.
+	ErrEmptySearch = errors.New("search query is blank")
+	// ErrForbidden raises when user isn't allowed to change the article.
+	ErrForbidden = errors.New("article change is forbidden")
+	// ErrRevisionNotFound raises when article revision not found in the database.
+	ErrRevisionNotFound = errors.New("article revision not found")
+	// ErrCategoryNotFound raises when the category of the article doesn't exist.
+	ErrCategoryNotFound = errors.New("article category not found")
+	// ErrVersionMismatch raises when the article was changed
+	// since the version the request is based on.
+	ErrVersionMismatch = errors.New("article version mismatch")
 )
 
 // Article contains all article field.
 type Article struct {
-	ID         int       `json:"id"`
-	UserID     int       `json:"user_id"`
-	CategoryID int       `json:"categort_id"`
-	Title      string    `json:"title"`
-	Body       string    `json:"body"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
+	ID                int        `json:"id"`
+	AuthorID          int        `json:"author_id"`
+	AuthorUsername    string     `json:"author_username"`
+	UpdatedByID       int        `json:"updated_by_id"`
+	UpdatedByUsername string     `json:"updated_by_username"`
+	CategoryID        int        `json:"categort_id"`
+	Title             string     `json:"title"`
+	Body              string     `json:"body"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+	DeletedAt         *time.Time `json:"deleted_at,omitempty"`
+	// Version is incremented by every update of the article,
+	// unlike the version of the revision.
+	Version int `json:"version"`
 }
 
 // NewArticle contains the information which needs to create a new Article.
 type NewArticle struct {
-	UserID     int
+	AuthorID   int
 	CategoryID int
 	Title      string
 	Body       string
@@ -41,5 +61,56 @@ type Form struct {
 
 // Articles contains slice of posts.
 type Articles struct {
-	Articles []Article `json:"articles"`
+	Articles   []Article `json:"articles"`
+	NextCursor string    `json:"next_cursor"`
+}
+
+// SearchQuery contains the information which needs to search articles.
+type SearchQuery struct {
+	Query       string
+	CategoryIDs []int
+	Limit       int
+	Offset      int
+}
+
+// SearchResult contains found article with its rank
+// and highlighted fragments.
+type SearchResult struct {
+	Article Article `json:"article"`
+	Rank    float64 `json:"rank"`
+	Title   string  `json:"title_highlight"`
+	Snippet string  `json:"snippet"`
+}
+
+// SearchResults contains slice of found articles.
+type SearchResults struct {
+	Results []SearchResult `json:"results"`
+	Total   int            `json:"total"`
+}
+
+// Revision is a saved version of the article.
+type Revision struct {
+	ID         int       `json:"id"`
+	ArticleID  int       `json:"article_id"`
+	Version    int       `json:"version"`
+	UserID     int       `json:"user_id"`
+	CategoryID int       `json:"category_id"`
+	Title      string    `json:"title"`
+	Body       string    `json:"body"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Revisions contains slice of article revisions.
+type Revisions struct {
+	Revisions []Revision `json:"revisions"`
+}
+
+// Diff contains line-level changes between two revisions.
+type Diff struct {
+	ArticleID       int         `json:"article_id"`
+	From            int         `json:"from"`
+	To              int         `json:"to"`
+	CategoryChanged bool        `json:"category_changed"`
+	Title           []diff.Line `json:"title"`
+	Body            []diff.Line `json:"body"`
 }
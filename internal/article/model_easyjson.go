@@ -4,9 +4,11 @@ package article
 
 import (
 	json "encoding/json"
+	diff "github.com/dipress/crmifc/internal/kit/diff"
 	easyjson "github.com/mailru/easyjson"
 	jlexer "github.com/mailru/easyjson/jlexer"
 	jwriter "github.com/mailru/easyjson/jwriter"
+	time "time"
 )
 
 // suppress unused package warning
@@ -17,7 +19,7 @@ var (
 	_ easyjson.Marshaler
 )
 
-func easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalArticle(in *jlexer.Lexer, out *NewArticle) {
+func easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalArticle(in *jlexer.Lexer, out *SearchResults) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -36,13 +38,741 @@ func easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalArticle(in *jlexer.Lexe
 			continue
 		}
 		switch key {
-		case "UserID":
+		case "results":
+			if in.IsNull() {
+				in.Skip()
+				out.Results = nil
+			} else {
+				in.Delim('[')
+				if out.Results == nil {
+					if !in.IsDelim(']') {
+						out.Results = make([]SearchResult, 0, 1)
+					} else {
+						out.Results = []SearchResult{}
+					}
+				} else {
+					out.Results = (out.Results)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v1 SearchResult
+					(v1).UnmarshalEasyJSON(in)
+					out.Results = append(out.Results, v1)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "total":
+			out.Total = int(in.Int())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalArticle(out *jwriter.Writer, in SearchResults) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"results\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		if in.Results == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v2, v3 := range in.Results {
+				if v2 > 0 {
+					out.RawByte(',')
+				}
+				(v3).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	{
+		const prefix string = ",\"total\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int(int(in.Total))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v SearchResults) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalArticle(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v SearchResults) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalArticle(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *SearchResults) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalArticle(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *SearchResults) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalArticle(l, v)
+}
+func easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalArticle1(in *jlexer.Lexer, out *SearchResult) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "article":
+			(out.Article).UnmarshalEasyJSON(in)
+		case "rank":
+			out.Rank = float64(in.Float64())
+		case "title_highlight":
+			out.Title = string(in.String())
+		case "snippet":
+			out.Snippet = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalArticle1(out *jwriter.Writer, in SearchResult) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"article\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.Article).MarshalEasyJSON(out)
+	}
+	{
+		const prefix string = ",\"rank\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Float64(float64(in.Rank))
+	}
+	{
+		const prefix string = ",\"title_highlight\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Title))
+	}
+	{
+		const prefix string = ",\"snippet\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Snippet))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v SearchResult) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalArticle1(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v SearchResult) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalArticle1(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *SearchResult) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalArticle1(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *SearchResult) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalArticle1(l, v)
+}
+func easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalArticle2(in *jlexer.Lexer, out *SearchQuery) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "Query":
+			out.Query = string(in.String())
+		case "CategoryIDs":
+			if in.IsNull() {
+				in.Skip()
+				out.CategoryIDs = nil
+			} else {
+				in.Delim('[')
+				if out.CategoryIDs == nil {
+					if !in.IsDelim(']') {
+						out.CategoryIDs = make([]int, 0, 8)
+					} else {
+						out.CategoryIDs = []int{}
+					}
+				} else {
+					out.CategoryIDs = (out.CategoryIDs)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v4 int
+					v4 = int(in.Int())
+					out.CategoryIDs = append(out.CategoryIDs, v4)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "Limit":
+			out.Limit = int(in.Int())
+		case "Offset":
+			out.Offset = int(in.Int())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalArticle2(out *jwriter.Writer, in SearchQuery) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"Query\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Query))
+	}
+	{
+		const prefix string = ",\"CategoryIDs\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		if in.CategoryIDs == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v5, v6 := range in.CategoryIDs {
+				if v5 > 0 {
+					out.RawByte(',')
+				}
+				out.Int(int(v6))
+			}
+			out.RawByte(']')
+		}
+	}
+	{
+		const prefix string = ",\"Limit\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int(int(in.Limit))
+	}
+	{
+		const prefix string = ",\"Offset\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int(int(in.Offset))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v SearchQuery) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalArticle2(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v SearchQuery) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalArticle2(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *SearchQuery) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalArticle2(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *SearchQuery) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalArticle2(l, v)
+}
+func easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalArticle3(in *jlexer.Lexer, out *Revisions) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "revisions":
+			if in.IsNull() {
+				in.Skip()
+				out.Revisions = nil
+			} else {
+				in.Delim('[')
+				if out.Revisions == nil {
+					if !in.IsDelim(']') {
+						out.Revisions = make([]Revision, 0, 1)
+					} else {
+						out.Revisions = []Revision{}
+					}
+				} else {
+					out.Revisions = (out.Revisions)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v7 Revision
+					(v7).UnmarshalEasyJSON(in)
+					out.Revisions = append(out.Revisions, v7)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalArticle3(out *jwriter.Writer, in Revisions) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"revisions\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		if in.Revisions == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v8, v9 := range in.Revisions {
+				if v8 > 0 {
+					out.RawByte(',')
+				}
+				(v9).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Revisions) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalArticle3(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Revisions) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalArticle3(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Revisions) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalArticle3(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Revisions) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalArticle3(l, v)
+}
+func easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalArticle4(in *jlexer.Lexer, out *Revision) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "id":
+			out.ID = int(in.Int())
+		case "article_id":
+			out.ArticleID = int(in.Int())
+		case "version":
+			out.Version = int(in.Int())
+		case "user_id":
+			out.UserID = int(in.Int())
+		case "category_id":
+			out.CategoryID = int(in.Int())
+		case "title":
+			out.Title = string(in.String())
+		case "body":
+			out.Body = string(in.String())
+		case "created_at":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.CreatedAt).UnmarshalJSON(data))
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalArticle4(out *jwriter.Writer, in Revision) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"id\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int(int(in.ID))
+	}
+	{
+		const prefix string = ",\"article_id\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int(int(in.ArticleID))
+	}
+	{
+		const prefix string = ",\"version\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int(int(in.Version))
+	}
+	{
+		const prefix string = ",\"user_id\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int(int(in.UserID))
+	}
+	{
+		const prefix string = ",\"category_id\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int(int(in.CategoryID))
+	}
+	{
+		const prefix string = ",\"title\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Title))
+	}
+	{
+		const prefix string = ",\"body\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Body))
+	}
+	{
+		const prefix string = ",\"created_at\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Raw((in.CreatedAt).MarshalJSON())
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Revision) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalArticle4(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Revision) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalArticle4(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Revision) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalArticle4(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Revision) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalArticle4(l, v)
+}
+func easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalArticle5(in *jlexer.Lexer, out *NewArticle) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "AuthorID":
+			out.AuthorID = int(in.Int())
+		case "CategoryID":
+			out.CategoryID = int(in.Int())
+		case "Title":
+			out.Title = string(in.String())
+		case "Body":
+			out.Body = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalArticle5(out *jwriter.Writer, in NewArticle) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"AuthorID\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int(int(in.AuthorID))
+	}
+	{
+		const prefix string = ",\"CategoryID\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int(int(in.CategoryID))
+	}
+	{
+		const prefix string = ",\"Title\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Title))
+	}
+	{
+		const prefix string = ",\"Body\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Body))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v NewArticle) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalArticle5(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v NewArticle) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalArticle5(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *NewArticle) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalArticle5(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *NewArticle) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalArticle5(l, v)
+}
+func easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalArticle6(in *jlexer.Lexer, out *Form) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "user":
 			out.UserID = int(in.Int())
-		case "CategoryID":
+		case "category_id":
 			out.CategoryID = int(in.Int())
-		case "Title":
+		case "title":
 			out.Title = string(in.String())
-		case "Body":
+		case "body":
 			out.Body = string(in.String())
 		default:
 			in.SkipRecursive()
@@ -54,12 +784,12 @@ func easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalArticle(in *jlexer.Lexe
 		in.Consumed()
 	}
 }
-func easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalArticle(out *jwriter.Writer, in NewArticle) {
+func easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalArticle6(out *jwriter.Writer, in Form) {
 	out.RawByte('{')
 	first := true
 	_ = first
 	{
-		const prefix string = ",\"UserID\":"
+		const prefix string = ",\"user\":"
 		if first {
 			first = false
 			out.RawString(prefix[1:])
@@ -69,7 +799,7 @@ func easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalArticle(out *jwriter.Wr
 		out.Int(int(in.UserID))
 	}
 	{
-		const prefix string = ",\"CategoryID\":"
+		const prefix string = ",\"category_id\":"
 		if first {
 			first = false
 			out.RawString(prefix[1:])
@@ -79,7 +809,7 @@ func easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalArticle(out *jwriter.Wr
 		out.Int(int(in.CategoryID))
 	}
 	{
-		const prefix string = ",\"Title\":"
+		const prefix string = ",\"title\":"
 		if first {
 			first = false
 			out.RawString(prefix[1:])
@@ -89,7 +819,7 @@ func easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalArticle(out *jwriter.Wr
 		out.String(string(in.Title))
 	}
 	{
-		const prefix string = ",\"Body\":"
+		const prefix string = ",\"body\":"
 		if first {
 			first = false
 			out.RawString(prefix[1:])
@@ -102,29 +832,29 @@ func easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalArticle(out *jwriter.Wr
 }
 
 // MarshalJSON supports json.Marshaler interface
-func (v NewArticle) MarshalJSON() ([]byte, error) {
+func (v Form) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalArticle(&w, v)
+	easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalArticle6(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
-func (v NewArticle) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalArticle(w, v)
+func (v Form) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalArticle6(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
-func (v *NewArticle) UnmarshalJSON(data []byte) error {
+func (v *Form) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalArticle(&r, v)
+	easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalArticle6(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
-func (v *NewArticle) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalArticle(l, v)
+func (v *Form) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalArticle6(l, v)
 }
-func easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalArticle1(in *jlexer.Lexer, out *Form) {
+func easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalArticle7(in *jlexer.Lexer, out *Diff) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -143,14 +873,60 @@ func easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalArticle1(in *jlexer.Lex
 			continue
 		}
 		switch key {
-		case "user":
-			out.UserID = int(in.Int())
-		case "category_id":
-			out.CategoryID = int(in.Int())
+		case "article_id":
+			out.ArticleID = int(in.Int())
+		case "from":
+			out.From = int(in.Int())
+		case "to":
+			out.To = int(in.Int())
+		case "category_changed":
+			out.CategoryChanged = bool(in.Bool())
 		case "title":
-			out.Title = string(in.String())
+			if in.IsNull() {
+				in.Skip()
+				out.Title = nil
+			} else {
+				in.Delim('[')
+				if out.Title == nil {
+					if !in.IsDelim(']') {
+						out.Title = make([]diff.Line, 0, 2)
+					} else {
+						out.Title = []diff.Line{}
+					}
+				} else {
+					out.Title = (out.Title)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v10 diff.Line
+					easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalKitDiff(in, &v10)
+					out.Title = append(out.Title, v10)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
 		case "body":
-			out.Body = string(in.String())
+			if in.IsNull() {
+				in.Skip()
+				out.Body = nil
+			} else {
+				in.Delim('[')
+				if out.Body == nil {
+					if !in.IsDelim(']') {
+						out.Body = make([]diff.Line, 0, 2)
+					} else {
+						out.Body = []diff.Line{}
+					}
+				} else {
+					out.Body = (out.Body)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v11 diff.Line
+					easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalKitDiff(in, &v11)
+					out.Body = append(out.Body, v11)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
 		default:
 			in.SkipRecursive()
 		}
@@ -161,29 +937,49 @@ func easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalArticle1(in *jlexer.Lex
 		in.Consumed()
 	}
 }
-func easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalArticle1(out *jwriter.Writer, in Form) {
+func easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalArticle7(out *jwriter.Writer, in Diff) {
 	out.RawByte('{')
 	first := true
 	_ = first
 	{
-		const prefix string = ",\"user\":"
+		const prefix string = ",\"article_id\":"
 		if first {
 			first = false
 			out.RawString(prefix[1:])
 		} else {
 			out.RawString(prefix)
 		}
-		out.Int(int(in.UserID))
+		out.Int(int(in.ArticleID))
 	}
 	{
-		const prefix string = ",\"category_id\":"
+		const prefix string = ",\"from\":"
 		if first {
 			first = false
 			out.RawString(prefix[1:])
 		} else {
 			out.RawString(prefix)
 		}
-		out.Int(int(in.CategoryID))
+		out.Int(int(in.From))
+	}
+	{
+		const prefix string = ",\"to\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int(int(in.To))
+	}
+	{
+		const prefix string = ",\"category_changed\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Bool(bool(in.CategoryChanged))
 	}
 	{
 		const prefix string = ",\"title\":"
@@ -193,7 +989,18 @@ func easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalArticle1(out *jwriter.W
 		} else {
 			out.RawString(prefix)
 		}
-		out.String(string(in.Title))
+		if in.Title == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v12, v13 := range in.Title {
+				if v12 > 0 {
+					out.RawByte(',')
+				}
+				easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalKitDiff(out, v13)
+			}
+			out.RawByte(']')
+		}
 	}
 	{
 		const prefix string = ",\"body\":"
@@ -203,35 +1010,105 @@ func easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalArticle1(out *jwriter.W
 		} else {
 			out.RawString(prefix)
 		}
-		out.String(string(in.Body))
+		if in.Body == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v14, v15 := range in.Body {
+				if v14 > 0 {
+					out.RawByte(',')
+				}
+				easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalKitDiff(out, v15)
+			}
+			out.RawByte(']')
+		}
 	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
-func (v Form) MarshalJSON() ([]byte, error) {
+func (v Diff) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalArticle1(&w, v)
+	easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalArticle7(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
-func (v Form) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalArticle1(w, v)
+func (v Diff) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalArticle7(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
-func (v *Form) UnmarshalJSON(data []byte) error {
+func (v *Diff) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalArticle1(&r, v)
+	easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalArticle7(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
-func (v *Form) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalArticle1(l, v)
+func (v *Diff) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalArticle7(l, v)
+}
+func easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalKitDiff(in *jlexer.Lexer, out *diff.Line) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "op":
+			out.Op = diff.Op(in.String())
+		case "text":
+			out.Text = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalKitDiff(out *jwriter.Writer, in diff.Line) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"op\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Op))
+	}
+	{
+		const prefix string = ",\"text\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Text))
+	}
+	out.RawByte('}')
 }
-func easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalArticle2(in *jlexer.Lexer, out *Articles) {
+func easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalArticle8(in *jlexer.Lexer, out *Articles) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -266,13 +1143,15 @@ func easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalArticle2(in *jlexer.Lex
 					out.Articles = (out.Articles)[:0]
 				}
 				for !in.IsDelim(']') {
-					var v1 Article
-					(v1).UnmarshalEasyJSON(in)
-					out.Articles = append(out.Articles, v1)
+					var v16 Article
+					(v16).UnmarshalEasyJSON(in)
+					out.Articles = append(out.Articles, v16)
 					in.WantComma()
 				}
 				in.Delim(']')
 			}
+		case "next_cursor":
+			out.NextCursor = string(in.String())
 		default:
 			in.SkipRecursive()
 		}
@@ -283,7 +1162,7 @@ func easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalArticle2(in *jlexer.Lex
 		in.Consumed()
 	}
 }
-func easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalArticle2(out *jwriter.Writer, in Articles) {
+func easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalArticle8(out *jwriter.Writer, in Articles) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -299,42 +1178,52 @@ func easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalArticle2(out *jwriter.W
 			out.RawString("null")
 		} else {
 			out.RawByte('[')
-			for v2, v3 := range in.Articles {
-				if v2 > 0 {
+			for v17, v18 := range in.Articles {
+				if v17 > 0 {
 					out.RawByte(',')
 				}
-				(v3).MarshalEasyJSON(out)
+				(v18).MarshalEasyJSON(out)
 			}
 			out.RawByte(']')
 		}
 	}
+	{
+		const prefix string = ",\"next_cursor\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.NextCursor))
+	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
 func (v Articles) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalArticle2(&w, v)
+	easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalArticle8(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v Articles) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalArticle2(w, v)
+	easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalArticle8(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *Articles) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalArticle2(&r, v)
+	easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalArticle8(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *Articles) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalArticle2(l, v)
+	easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalArticle8(l, v)
 }
-func easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalArticle3(in *jlexer.Lexer, out *Article) {
+func easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalArticle9(in *jlexer.Lexer, out *Article) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -355,8 +1244,14 @@ func easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalArticle3(in *jlexer.Lex
 		switch key {
 		case "id":
 			out.ID = int(in.Int())
-		case "user_id":
-			out.UserID = int(in.Int())
+		case "author_id":
+			out.AuthorID = int(in.Int())
+		case "author_username":
+			out.AuthorUsername = string(in.String())
+		case "updated_by_id":
+			out.UpdatedByID = int(in.Int())
+		case "updated_by_username":
+			out.UpdatedByUsername = string(in.String())
 		case "categort_id":
 			out.CategoryID = int(in.Int())
 		case "title":
@@ -371,6 +1266,20 @@ func easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalArticle3(in *jlexer.Lex
 			if data := in.Raw(); in.Ok() {
 				in.AddError((out.UpdatedAt).UnmarshalJSON(data))
 			}
+		case "deleted_at":
+			if in.IsNull() {
+				in.Skip()
+				out.DeletedAt = nil
+			} else {
+				if out.DeletedAt == nil {
+					out.DeletedAt = new(time.Time)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.DeletedAt).UnmarshalJSON(data))
+				}
+			}
+		case "version":
+			out.Version = int(in.Int())
 		default:
 			in.SkipRecursive()
 		}
@@ -381,7 +1290,7 @@ func easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalArticle3(in *jlexer.Lex
 		in.Consumed()
 	}
 }
-func easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalArticle3(out *jwriter.Writer, in Article) {
+func easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalArticle9(out *jwriter.Writer, in Article) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -396,14 +1305,44 @@ func easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalArticle3(out *jwriter.W
 		out.Int(int(in.ID))
 	}
 	{
-		const prefix string = ",\"user_id\":"
+		const prefix string = ",\"author_id\":"
 		if first {
 			first = false
 			out.RawString(prefix[1:])
 		} else {
 			out.RawString(prefix)
 		}
-		out.Int(int(in.UserID))
+		out.Int(int(in.AuthorID))
+	}
+	{
+		const prefix string = ",\"author_username\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.AuthorUsername))
+	}
+	{
+		const prefix string = ",\"updated_by_id\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int(int(in.UpdatedByID))
+	}
+	{
+		const prefix string = ",\"updated_by_username\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.UpdatedByUsername))
 	}
 	{
 		const prefix string = ",\"categort_id\":"
@@ -455,29 +1394,49 @@ func easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalArticle3(out *jwriter.W
 		}
 		out.Raw((in.UpdatedAt).MarshalJSON())
 	}
+	if in.DeletedAt != nil {
+		const prefix string = ",\"deleted_at\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Raw((*in.DeletedAt).MarshalJSON())
+	}
+	{
+		const prefix string = ",\"version\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int(int(in.Version))
+	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
 func (v Article) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalArticle3(&w, v)
+	easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalArticle9(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v Article) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalArticle3(w, v)
+	easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalArticle9(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *Article) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalArticle3(&r, v)
+	easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalArticle9(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *Article) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalArticle3(l, v)
+	easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalArticle9(l, v)
 }
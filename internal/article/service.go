@@ -2,8 +2,15 @@ package article
 
 import (
 	"context"
+	"strings"
 
+	"github.com/dipress/crmifc/internal/abillity"
 	"github.com/dipress/crmifc/internal/kit/auth"
+	"github.com/dipress/crmifc/internal/kit/diff"
+	"github.com/dipress/crmifc/internal/kit/patch"
+	"github.com/dipress/crmifc/internal/kit/query"
+	"github.com/dipress/crmifc/internal/kit/tx"
+	"github.com/dipress/crmifc/internal/role"
 	"github.com/pkg/errors"
 )
 
@@ -15,7 +22,13 @@ type Repository interface {
 	Find(ctx context.Context, id int) (*Article, error)
 	Update(ctx context.Context, id int, a *Article) error
 	Delete(ctx context.Context, id int) error
-	List(ctx context.Context, articles *Articles) error
+	List(ctx context.Context, q *query.Query, articles *Articles) error
+	ListDeleted(ctx context.Context, q *query.Query, articles *Articles) error
+	Undelete(ctx context.Context, id int) error
+	Purge(ctx context.Context, id int) error
+	Search(ctx context.Context, sq *SearchQuery, results *SearchResults) error
+	Revisions(ctx context.Context, articleID int, revisions *Revisions) error
+	FindRevision(ctx context.Context, articleID, version int) (*Revision, error)
 }
 
 // Validater validates article fields.
@@ -27,6 +40,9 @@ type Validater interface {
 type Service struct {
 	Repository
 	Validater
+	// Transactor runs the actions of several
+	// repository calls in a single transaction.
+	Transactor tx.Transactor
 }
 
 // NewService factory prepares service for all futher operations.
@@ -34,6 +50,7 @@ func NewService(r Repository, v Validater) *Service {
 	s := Service{
 		Repository: r,
 		Validater:  v,
+		Transactor: tx.Nop{},
 	}
 
 	return &s
@@ -51,7 +68,7 @@ func (s *Service) Create(ctx context.Context, f *Form) (*Article, error) {
 		CategoryID: f.CategoryID,
 		Title:      f.Title,
 		Body:       f.Body,
-		UserID:     claims.User.ID,
+		AuthorID:   claims.User.ID,
 	}
 
 	var a Article
@@ -70,8 +87,8 @@ func (s *Service) Find(ctx context.Context, id int) (*Article, error) {
 	return a, nil
 }
 
-// Update updates a article.
-func (s *Service) Update(ctx context.Context, id int, f *Form) (*Article, error) {
+// Update updates a article of the given version.
+func (s *Service) Update(ctx context.Context, id, version int, f *Form) (*Article, error) {
 	if err := s.Validater.Validate(ctx, f); err != nil {
 		return nil, errors.Wrap(err, "validater validate")
 	}
@@ -83,7 +100,16 @@ func (s *Service) Update(ctx context.Context, id int, f *Form) (*Article, error)
 		return nil, errors.Wrap(err, "find article")
 	}
 
-	a.UserID = claims.User.ID
+	if !canChange(ctx, a, role.ArticlesUpdate, role.ArticlesUpdateOwn) {
+		return nil, ErrForbidden
+	}
+
+	if a.Version != version {
+		return nil, ErrVersionMismatch
+	}
+
+	a.UpdatedByID = claims.User.ID
+	a.UpdatedByUsername = claims.User.Username
 	a.CategoryID = f.CategoryID
 	a.Title = f.Title
 	a.Body = f.Body
@@ -95,26 +121,214 @@ func (s *Service) Update(ctx context.Context, id int, f *Form) (*Article, error)
 	return a, nil
 }
 
-// Delete deletes a article.
-func (s *Service) Delete(ctx context.Context, id int) error {
-	art, err := s.Repository.Find(ctx, id)
+// Patch updates only the fields of the article of the
+// given version which are given by the JSON merge patch.
+func (s *Service) Patch(ctx context.Context, id, version int, p patch.Patch) (*Article, error) {
+	a, err := s.Repository.Find(ctx, id)
 	if err != nil {
-		return errors.Wrap(err, "find article")
+		return nil, errors.Wrap(err, "find article")
 	}
 
-	if err := s.Repository.Delete(ctx, art.ID); err != nil {
-		return errors.Wrap(err, "delete category")
+	f := Form{
+		CategoryID: a.CategoryID,
+		Title:      a.Title,
+		Body:       a.Body,
 	}
 
-	return nil
+	if err := p.Apply(&f); err != nil {
+		return nil, errors.Wrap(err, "apply patch")
+	}
+
+	return s.Update(ctx, id, version, &f)
+}
+
+// Delete moves a article of the given version to the trash.
+func (s *Service) Delete(ctx context.Context, id, version int) error {
+	return s.Transactor.WithinTx(ctx, func(ctx context.Context) error {
+		art, err := s.Repository.Find(ctx, id)
+		if err != nil {
+			return errors.Wrap(err, "find article")
+		}
+
+		if !canChange(ctx, art, role.ArticlesDelete, role.ArticlesDeleteOwn) {
+			return ErrForbidden
+		}
+
+		if art.Version != version {
+			return ErrVersionMismatch
+		}
+
+		if err := s.Repository.Delete(ctx, art.ID); err != nil {
+			return errors.Wrap(err, "delete category")
+		}
+
+		return nil
+	})
 }
 
-// List shows all articles.
-func (s *Service) List(ctx context.Context) (*Articles, error) {
+// List shows articles page by given query.
+func (s *Service) List(ctx context.Context, q *query.Query) (*Articles, error) {
 	var articles Articles
-	if err := s.Repository.List(ctx, &articles); err != nil {
+	if err := s.Repository.List(ctx, q, &articles); err != nil {
 		return nil, errors.Wrap(err, "list of articles")
 	}
 
 	return &articles, nil
 }
+
+// Trash shows page of the deleted articles by given query.
+func (s *Service) Trash(ctx context.Context, q *query.Query) (*Articles, error) {
+	var articles Articles
+	if err := s.Repository.ListDeleted(ctx, q, &articles); err != nil {
+		return nil, errors.Wrap(err, "list of deleted articles")
+	}
+
+	return &articles, nil
+}
+
+// Undelete restores the deleted article.
+func (s *Service) Undelete(ctx context.Context, id int) error {
+	if err := s.Repository.Undelete(ctx, id); err != nil {
+		return errors.Wrap(err, "undelete article")
+	}
+	return nil
+}
+
+// Purge deletes the deleted article permanently.
+func (s *Service) Purge(ctx context.Context, id int) error {
+	if err := s.Repository.Purge(ctx, id); err != nil {
+		return errors.Wrap(err, "purge article")
+	}
+	return nil
+}
+
+// Search finds articles by full-text query.
+func (s *Service) Search(ctx context.Context, sq *SearchQuery) (*SearchResults, error) {
+	sq.Query = strings.TrimSpace(sq.Query)
+	if sq.Query == "" {
+		return nil, ErrEmptySearch
+	}
+
+	if sq.Limit < 1 || sq.Limit > query.MaxLimit {
+		sq.Limit = query.DefaultLimit
+	}
+
+	if sq.Offset < 0 {
+		sq.Offset = 0
+	}
+
+	var results SearchResults
+	if err := s.Repository.Search(ctx, sq, &results); err != nil {
+		return nil, errors.Wrap(err, "search articles")
+	}
+
+	return &results, nil
+}
+
+// Revisions shows all saved versions of the article.
+func (s *Service) Revisions(ctx context.Context, id int) (*Revisions, error) {
+	if _, err := s.Repository.Find(ctx, id); err != nil {
+		return nil, errors.Wrap(err, "find article")
+	}
+
+	var revisions Revisions
+	if err := s.Repository.Revisions(ctx, id, &revisions); err != nil {
+		return nil, errors.Wrap(err, "list of revisions")
+	}
+
+	return &revisions, nil
+}
+
+// FindRevision finds a version of the article.
+func (s *Service) FindRevision(ctx context.Context, id, version int) (*Revision, error) {
+	if _, err := s.Repository.Find(ctx, id); err != nil {
+		return nil, errors.Wrap(err, "find article")
+	}
+
+	rev, err := s.Repository.FindRevision(ctx, id, version)
+	if err != nil {
+		return nil, errors.Wrap(err, "find revision")
+	}
+	return rev, nil
+}
+
+// Diff compares two versions of the article line by line.
+func (s *Service) Diff(ctx context.Context, id, from, to int) (*Diff, error) {
+	if _, err := s.Repository.Find(ctx, id); err != nil {
+		return nil, errors.Wrap(err, "find article")
+	}
+
+	a, err := s.Repository.FindRevision(ctx, id, from)
+	if err != nil {
+		return nil, errors.Wrap(err, "find from revision")
+	}
+
+	b, err := s.Repository.FindRevision(ctx, id, to)
+	if err != nil {
+		return nil, errors.Wrap(err, "find to revision")
+	}
+
+	title, err := diff.Lines(a.Title, b.Title)
+	if err != nil {
+		return nil, errors.Wrap(err, "diff title")
+	}
+
+	body, err := diff.Lines(a.Body, b.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "diff body")
+	}
+
+	d := Diff{
+		ArticleID:       id,
+		From:            from,
+		To:              to,
+		CategoryChanged: a.CategoryID != b.CategoryID,
+		Title:           title,
+		Body:            body,
+	}
+
+	return &d, nil
+}
+
+// Restore brings the article back to the given version.
+// Restoring saves a new revision, so the history is kept.
+func (s *Service) Restore(ctx context.Context, id, version int) (*Article, error) {
+	a, err := s.Repository.Find(ctx, id)
+	if err != nil {
+		return nil, errors.Wrap(err, "find article")
+	}
+
+	if !canChange(ctx, a, role.ArticlesUpdate, role.ArticlesUpdateOwn) {
+		return nil, ErrForbidden
+	}
+
+	rev, err := s.Repository.FindRevision(ctx, id, version)
+	if err != nil {
+		return nil, errors.Wrap(err, "find revision")
+	}
+
+	claims, _ := auth.FromContext(ctx)
+
+	a.UpdatedByID = claims.User.ID
+	a.UpdatedByUsername = claims.User.Username
+	a.CategoryID = rev.CategoryID
+	a.Title = rev.Title
+	a.Body = rev.Body
+
+	if err := s.Repository.Update(ctx, id, a); err != nil {
+		return nil, errors.Wrap(err, "update article")
+	}
+
+	return a, nil
+}
+
+// canChange checks that the user of the request is allowed to change
+// the article with the permission for all or own articles.
+func canChange(ctx context.Context, a *Article, all, own role.Permission) bool {
+	claims, ok := auth.FromContext(ctx)
+	if !ok {
+		return false
+	}
+
+	return abillity.UserAbillity{}.CanChange(&claims.User, a.AuthorID, all, own)
+}
@@ -6,6 +6,7 @@ package article
 
 import (
 	context "context"
+	query "github.com/dipress/crmifc/internal/kit/query"
 	gomock "github.com/golang/mock/gomock"
 	reflect "reflect"
 )
@@ -91,17 +92,102 @@ func (mr *MockRepositoryMockRecorder) Delete(ctx, id interface{}) *gomock.Call {
 }
 
 // List mocks base method
-func (m *MockRepository) List(ctx context.Context, articles *Articles) error {
+func (m *MockRepository) List(ctx context.Context, q *query.Query, articles *Articles) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "List", ctx, articles)
+	ret := m.ctrl.Call(m, "List", ctx, q, articles)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // List indicates an expected call of List
-func (mr *MockRepositoryMockRecorder) List(ctx, articles interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) List(ctx, q, articles interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockRepository)(nil).List), ctx, articles)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockRepository)(nil).List), ctx, q, articles)
+}
+
+// ListDeleted mocks base method
+func (m *MockRepository) ListDeleted(ctx context.Context, q *query.Query, articles *Articles) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeleted", ctx, q, articles)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ListDeleted indicates an expected call of ListDeleted
+func (mr *MockRepositoryMockRecorder) ListDeleted(ctx, q, articles interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeleted", reflect.TypeOf((*MockRepository)(nil).ListDeleted), ctx, q, articles)
+}
+
+// Undelete mocks base method
+func (m *MockRepository) Undelete(ctx context.Context, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Undelete", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Undelete indicates an expected call of Undelete
+func (mr *MockRepositoryMockRecorder) Undelete(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Undelete", reflect.TypeOf((*MockRepository)(nil).Undelete), ctx, id)
+}
+
+// Purge mocks base method
+func (m *MockRepository) Purge(ctx context.Context, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Purge indicates an expected call of Purge
+func (mr *MockRepositoryMockRecorder) Purge(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockRepository)(nil).Purge), ctx, id)
+}
+
+// Search mocks base method
+func (m *MockRepository) Search(ctx context.Context, sq *SearchQuery, results *SearchResults) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", ctx, sq, results)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Search indicates an expected call of Search
+func (mr *MockRepositoryMockRecorder) Search(ctx, sq, results interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockRepository)(nil).Search), ctx, sq, results)
+}
+
+// Revisions mocks base method
+func (m *MockRepository) Revisions(ctx context.Context, articleID int, revisions *Revisions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revisions", ctx, articleID, revisions)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Revisions indicates an expected call of Revisions
+func (mr *MockRepositoryMockRecorder) Revisions(ctx, articleID, revisions interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revisions", reflect.TypeOf((*MockRepository)(nil).Revisions), ctx, articleID, revisions)
+}
+
+// FindRevision mocks base method
+func (m *MockRepository) FindRevision(ctx context.Context, articleID, version int) (*Revision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindRevision", ctx, articleID, version)
+	ret0, _ := ret[0].(*Revision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindRevision indicates an expected call of FindRevision
+func (mr *MockRepositoryMockRecorder) FindRevision(ctx, articleID, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindRevision", reflect.TypeOf((*MockRepository)(nil).FindRevision), ctx, articleID, version)
 }
 
 // MockValidater is a mock of Validater interface
@@ -2,11 +2,14 @@ package article
 
 import (
 	"context"
-	"errors"
 	"testing"
 
 	"github.com/dipress/crmifc/internal/kit/auth"
+	"github.com/dipress/crmifc/internal/kit/patch"
+	"github.com/dipress/crmifc/internal/kit/query"
+	"github.com/dipress/crmifc/internal/role"
 	gomock "github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -139,6 +142,7 @@ func Test_Service_Update(t *testing.T) {
 		name           string
 		validaterFunc  func(mock *MockValidater)
 		repositoryFunc func(mock *MockRepository)
+		permissions    []role.Permission
 		wantErr        bool
 	}{
 		{
@@ -147,9 +151,32 @@ func Test_Service_Update(t *testing.T) {
 				m.EXPECT().Validate(gomock.Any(), gomock.Any()).Return(nil)
 			},
 			repositoryFunc: func(m *MockRepository) {
-				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&Article{}, nil)
+				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&Article{Version: 1}, nil)
+				m.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+			},
+			permissions: []role.Permission{role.ArticlesUpdate},
+		},
+		{
+			name: "own article",
+			validaterFunc: func(m *MockValidater) {
+				m.EXPECT().Validate(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			repositoryFunc: func(m *MockRepository) {
+				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&Article{Version: 1, AuthorID: 1}, nil)
 				m.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
 			},
+			permissions: []role.Permission{role.ArticlesUpdateOwn},
+		},
+		{
+			name: "article of another user",
+			validaterFunc: func(m *MockValidater) {
+				m.EXPECT().Validate(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			repositoryFunc: func(m *MockRepository) {
+				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&Article{Version: 1, AuthorID: 2}, nil)
+			},
+			permissions: []role.Permission{role.ArticlesUpdateOwn},
+			wantErr:     true,
 		},
 		{
 			name: "validation error",
@@ -165,7 +192,7 @@ func Test_Service_Update(t *testing.T) {
 				m.EXPECT().Validate(gomock.Any(), gomock.Any()).Return(nil)
 			},
 			repositoryFunc: func(m *MockRepository) {
-				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&Article{}, errors.New("mock error"))
+				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&Article{Version: 1}, errors.New("mock error"))
 			},
 			wantErr: true,
 		},
@@ -175,10 +202,11 @@ func Test_Service_Update(t *testing.T) {
 				m.EXPECT().Validate(gomock.Any(), gomock.Any()).Return(nil)
 			},
 			repositoryFunc: func(m *MockRepository) {
-				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&Article{}, nil)
+				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&Article{Version: 1}, nil)
 				m.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("mock error"))
 			},
-			wantErr: true,
+			permissions: []role.Permission{role.ArticlesUpdate},
+			wantErr:     true,
 		},
 	}
 
@@ -202,6 +230,8 @@ func Test_Service_Update(t *testing.T) {
 			defer cancel()
 
 			claims := auth.Claims{}
+			claims.User.ID = 1
+			claims.User.Role.Permissions = tc.permissions
 			newCtx := auth.ToContext(ctx, &claims)
 
 			form := Form{
@@ -210,7 +240,7 @@ func Test_Service_Update(t *testing.T) {
 				Body:       "update my awesome body",
 			}
 
-			_, err := s.Update(newCtx, 1, &form)
+			_, err := s.Update(newCtx, 1, 1, &form)
 
 			if tc.wantErr {
 				assert.Error(t, err)
@@ -225,29 +255,40 @@ func Test_Service_Delete(t *testing.T) {
 	tests := []struct {
 		name           string
 		repositoryFunc func(mock *MockRepository)
+		permissions    []role.Permission
 		wantErr        bool
 	}{
 		{
 			name: "ok",
 			repositoryFunc: func(m *MockRepository) {
-				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&Article{}, nil)
+				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&Article{Version: 1}, nil)
 				m.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(nil)
 			},
+			permissions: []role.Permission{role.ArticlesDelete},
+		},
+		{
+			name: "article of another user",
+			repositoryFunc: func(m *MockRepository) {
+				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&Article{Version: 1, AuthorID: 2}, nil)
+			},
+			permissions: []role.Permission{role.ArticlesDeleteOwn},
+			wantErr:     true,
 		},
 		{
 			name: "find article error",
 			repositoryFunc: func(m *MockRepository) {
-				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&Article{}, errors.New("mock error"))
+				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&Article{Version: 1}, errors.New("mock error"))
 			},
 			wantErr: true,
 		},
 		{
 			name: "delete article error",
 			repositoryFunc: func(m *MockRepository) {
-				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&Article{}, nil)
+				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&Article{Version: 1}, nil)
 				m.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(errors.New("mock errror"))
 			},
-			wantErr: true,
+			permissions: []role.Permission{role.ArticlesDelete},
+			wantErr:     true,
 		},
 	}
 
@@ -267,7 +308,12 @@ func Test_Service_Delete(t *testing.T) {
 			ctx, cancel := context.WithCancel(context.Background())
 			defer cancel()
 
-			err := s.Delete(ctx, 1)
+			claims := auth.Claims{}
+			claims.User.ID = 1
+			claims.User.Role.Permissions = tc.permissions
+			newCtx := auth.ToContext(ctx, &claims)
+
+			err := s.Delete(newCtx, 1, 1)
 
 			if tc.wantErr {
 				assert.Error(t, err)
@@ -287,13 +333,337 @@ func Test_List_Service(t *testing.T) {
 		{
 			name: "ok",
 			repositoryFunc: func(m *MockRepository) {
-				m.EXPECT().List(gomock.Any(), gomock.Any()).Return(nil)
+				m.EXPECT().List(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name: "internal error",
+			repositoryFunc: func(m *MockRepository) {
+				m.EXPECT().List(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("mock error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := NewMockRepository(ctrl)
+
+			tc.repositoryFunc(repo)
+
+			s := NewService(repo, nil)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			_, err := s.List(ctx, query.New())
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.Nil(t, err)
+		})
+	}
+}
+
+func Test_Service_Search(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		repositoryFunc func(mock *MockRepository)
+		wantErr        bool
+	}{
+		{
+			name:  "ok",
+			query: "vpn setup",
+			repositoryFunc: func(m *MockRepository) {
+				m.EXPECT().Search(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:           "blank query",
+			query:          "  ",
+			repositoryFunc: func(m *MockRepository) {},
+			wantErr:        true,
+		},
+		{
+			name:  "internal error",
+			query: "vpn setup",
+			repositoryFunc: func(m *MockRepository) {
+				m.EXPECT().Search(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("mock error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := NewMockRepository(ctrl)
+			tc.repositoryFunc(repo)
+
+			s := NewService(repo, nil)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			_, err := s.Search(ctx, &SearchQuery{Query: tc.query})
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.Nil(t, err)
+		})
+	}
+}
+
+func Test_Service_Revisions(t *testing.T) {
+	tests := []struct {
+		name           string
+		repositoryFunc func(mock *MockRepository)
+		wantErr        bool
+	}{
+		{
+			name: "ok",
+			repositoryFunc: func(m *MockRepository) {
+				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&Article{}, nil)
+				m.EXPECT().Revisions(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name: "find article error",
+			repositoryFunc: func(m *MockRepository) {
+				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(nil, ErrNotFound)
+			},
+			wantErr: true,
+		},
+		{
+			name: "internal error",
+			repositoryFunc: func(m *MockRepository) {
+				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&Article{}, nil)
+				m.EXPECT().Revisions(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("mock error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := NewMockRepository(ctrl)
+			tc.repositoryFunc(repo)
+
+			s := NewService(repo, nil)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			_, err := s.Revisions(ctx, 1)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.Nil(t, err)
+		})
+	}
+}
+
+func Test_Service_Diff(t *testing.T) {
+	from := Revision{Version: 1, CategoryID: 1, Title: "title", Body: "one\ntwo"}
+	to := Revision{Version: 2, CategoryID: 2, Title: "title", Body: "one\n2"}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := NewMockRepository(ctrl)
+	repo.EXPECT().Find(gomock.Any(), 1).Return(&Article{}, nil)
+	repo.EXPECT().FindRevision(gomock.Any(), 1, 1).Return(&from, nil)
+	repo.EXPECT().FindRevision(gomock.Any(), 1, 2).Return(&to, nil)
+
+	s := NewService(repo, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	d, err := s.Diff(ctx, 1, 1, 2)
+	assert.Nil(t, err)
+	assert.True(t, d.CategoryChanged)
+	assert.Len(t, d.Title, 1)
+	assert.Len(t, d.Body, 3)
+}
+
+func Test_Service_TrashedRevisions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// The trashed article isn't found, so its revisions aren't shown.
+	repo := NewMockRepository(ctrl)
+	repo.EXPECT().Find(gomock.Any(), 1).Return(nil, ErrNotFound).Times(2)
+
+	s := NewService(repo, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, err := s.FindRevision(ctx, 1, 1)
+	assert.Equal(t, ErrNotFound, errors.Cause(err))
+
+	_, err = s.Diff(ctx, 1, 1, 2)
+	assert.Equal(t, ErrNotFound, errors.Cause(err))
+}
+
+func Test_Service_Restore(t *testing.T) {
+	tests := []struct {
+		name           string
+		repositoryFunc func(mock *MockRepository)
+		wantErr        bool
+	}{
+		{
+			name: "ok",
+			repositoryFunc: func(m *MockRepository) {
+				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&Article{}, nil)
+				m.EXPECT().FindRevision(gomock.Any(), gomock.Any(), gomock.Any()).Return(&Revision{}, nil)
+				m.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name: "find article error",
+			repositoryFunc: func(m *MockRepository) {
+				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(nil, ErrNotFound)
+			},
+			wantErr: true,
+		},
+		{
+			name: "find revision error",
+			repositoryFunc: func(m *MockRepository) {
+				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&Article{}, nil)
+				m.EXPECT().FindRevision(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, ErrRevisionNotFound)
+			},
+			wantErr: true,
+		},
+		{
+			name: "update article error",
+			repositoryFunc: func(m *MockRepository) {
+				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&Article{}, nil)
+				m.EXPECT().FindRevision(gomock.Any(), gomock.Any(), gomock.Any()).Return(&Revision{}, nil)
+				m.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("mock error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := NewMockRepository(ctrl)
+			tc.repositoryFunc(repo)
+
+			s := NewService(repo, nil)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			claims := auth.Claims{}
+			claims.User.Role.Permissions = []role.Permission{role.ArticlesUpdate}
+			newCtx := auth.ToContext(ctx, &claims)
+
+			_, err := s.Restore(newCtx, 1, 1)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.Nil(t, err)
+		})
+	}
+}
+
+func Test_Service_Update_KeepsAuthor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := NewMockRepository(ctrl)
+	validater := NewMockValidater(ctrl)
+
+	validater.EXPECT().Validate(gomock.Any(), gomock.Any()).Return(nil)
+	repo.EXPECT().Find(gomock.Any(), 1).Return(&Article{Version: 1, ID: 1, AuthorID: 2, UpdatedByID: 2}, nil)
+	repo.EXPECT().Update(gomock.Any(), 1, gomock.Any()).Return(nil)
+
+	s := NewService(repo, validater)
+
+	claims := auth.Claims{}
+	claims.User.ID = 3
+	claims.User.Username = "editor"
+	claims.User.Role.Permissions = []role.Permission{role.ArticlesUpdate}
+	ctx := auth.ToContext(context.Background(), &claims)
+
+	a, err := s.Update(ctx, 1, 1, &Form{Title: "title", Body: "body"})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, a.AuthorID)
+	assert.Equal(t, 3, a.UpdatedByID)
+	assert.Equal(t, "editor", a.UpdatedByUsername)
+}
+
+func Test_Service_Patch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := NewMockRepository(ctrl)
+	validater := NewMockValidater(ctrl)
+
+	validater.EXPECT().Validate(gomock.Any(), &Form{CategoryID: 4, Title: "new title", Body: "body"}).Return(nil)
+	repo.EXPECT().Find(gomock.Any(), 1).Return(&Article{Version: 1, ID: 1, AuthorID: 3, CategoryID: 4, Title: "title", Body: "body"}, nil).Times(2)
+	repo.EXPECT().Update(gomock.Any(), 1, gomock.Any()).Return(nil)
+
+	s := NewService(repo, validater)
+
+	claims := auth.Claims{}
+	claims.User.ID = 3
+	claims.User.Role.Permissions = []role.Permission{role.ArticlesUpdateOwn}
+	ctx := auth.ToContext(context.Background(), &claims)
+
+	a, err := s.Patch(ctx, 1, 1, patch.Patch(`{"title":"new title"}`))
+	assert.Nil(t, err)
+	assert.Equal(t, "new title", a.Title)
+	assert.Equal(t, "body", a.Body)
+}
+
+func Test_Service_Trash(t *testing.T) {
+	tests := []struct {
+		name           string
+		repositoryFunc func(mock *MockRepository)
+		wantErr        bool
+	}{
+		{
+			name: "ok",
+			repositoryFunc: func(m *MockRepository) {
+				m.EXPECT().ListDeleted(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
 			},
 		},
 		{
 			name: "internal error",
 			repositoryFunc: func(m *MockRepository) {
-				m.EXPECT().List(gomock.Any(), gomock.Any()).Return(errors.New("mock error"))
+				m.EXPECT().ListDeleted(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("mock error"))
 			},
 			wantErr: true,
 		},
@@ -316,7 +686,7 @@ func Test_List_Service(t *testing.T) {
 			ctx, cancel := context.WithCancel(context.Background())
 			defer cancel()
 
-			_, err := s.List(ctx)
+			_, err := s.Trash(ctx, query.New())
 			if tc.wantErr {
 				assert.Error(t, err)
 				return
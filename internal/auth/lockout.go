@@ -0,0 +1,151 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultLockout is the brute-force protection which is used by default.
+var DefaultLockout = Lockout{
+	AccountThreshold: 5,
+	IPThreshold:      20,
+	BaseDelay:        30 * time.Second,
+	MaxDelay:         time.Hour,
+	Window:           time.Hour,
+}
+
+// Lockout configures the brute-force protection of the sign in.
+// After the threshold of failed attempts the account or the client IP
+// is locked, each next failure doubles the lock up to the max delay.
+type Lockout struct {
+	AccountThreshold int
+	IPThreshold      int
+	BaseDelay        time.Duration
+	MaxDelay         time.Duration
+	// Window is the time after the last failure
+	// when the failures are forgotten.
+	Window time.Duration
+}
+
+// Delay returns how long to lock after given number of failures.
+func (l Lockout) Delay(failures, threshold int) time.Duration {
+	if threshold <= 0 || failures < threshold {
+		return 0
+	}
+
+	delay := l.BaseDelay
+	for i := threshold; i < failures && delay < l.MaxDelay; i++ {
+		delay *= 2
+	}
+
+	if delay > l.MaxDelay {
+		delay = l.MaxDelay
+	}
+	return delay
+}
+
+// LockedError returns when the account or the client
+// IP is locked after too many failed attempts.
+type LockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("locked, retry after %s", e.RetryAfter)
+}
+
+// Attempts holds failed sign in attempts of the account or the client IP.
+type Attempts struct {
+	Key         string
+	Failures    int
+	LockedUntil *time.Time
+}
+
+// AttemptRepository stores failed sign in attempts.
+type AttemptRepository interface {
+	FindAttempts(ctx context.Context, key string) (*Attempts, error)
+	AddFailure(ctx context.Context, key string, window time.Duration) (int, error)
+	Lock(ctx context.Context, key string, until time.Time) error
+	ResetAttempts(ctx context.Context, key string) error
+}
+
+// AccountKey returns the attempts key of the account. The email
+// isn't validated on the sign in, so its hash keeps the key short.
+func AccountKey(email string) string {
+	return "email:" + hashToken(strings.ToLower(email))
+}
+
+// IPKey returns the attempts key of the client IP.
+func IPKey(ip string) string {
+	return "ip:" + ip
+}
+
+// checkLock returns LockedError when any of keys is locked.
+func (s *Service) checkLock(ctx context.Context, now time.Time, keys ...string) error {
+	var retryAfter time.Duration
+	for _, key := range keys {
+		a, err := s.AttemptRepository.FindAttempts(ctx, key)
+		if err != nil {
+			return errors.Wrap(err, "find attempts")
+		}
+
+		if a.LockedUntil != nil && a.LockedUntil.After(now) {
+			if d := a.LockedUntil.Sub(now); d > retryAfter {
+				retryAfter = d
+			}
+		}
+	}
+
+	if retryAfter > 0 {
+		return &LockedError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// failAttempt records the failed attempt of the account and the client ip.
+func (s *Service) failAttempt(ctx context.Context, now time.Time, accountKey, ipKey string) error {
+	if err := s.fail(ctx, now, accountKey, s.Lockout.AccountThreshold); err != nil {
+		return errors.Wrap(err, "account")
+	}
+
+	if err := s.fail(ctx, now, ipKey, s.Lockout.IPThreshold); err != nil {
+		return errors.Wrap(err, "ip")
+	}
+
+	return nil
+}
+
+// fail records the failed attempt and locks the
+// key when the failures reach the threshold.
+func (s *Service) fail(ctx context.Context, now time.Time, key string, threshold int) error {
+	failures, err := s.AttemptRepository.AddFailure(ctx, key, s.Lockout.Window)
+	if err != nil {
+		return errors.Wrap(err, "add failure")
+	}
+
+	if delay := s.Lockout.Delay(failures, threshold); delay > 0 {
+		if err := s.AttemptRepository.Lock(ctx, key, now.Add(delay)); err != nil {
+			return errors.Wrap(err, "lock")
+		}
+	}
+
+	return nil
+}
+
+// Unlock resets failed attempts of the user account.
+func (s *Service) Unlock(ctx context.Context, userID int) error {
+	u, err := s.UserRepository.Find(ctx, userID)
+	if err != nil {
+		return errors.Wrap(err, "find user")
+	}
+
+	if err := s.AttemptRepository.ResetAttempts(ctx, AccountKey(u.Email)); err != nil {
+		return errors.Wrap(err, "reset attempts")
+	}
+
+	return nil
+}
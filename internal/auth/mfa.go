@@ -0,0 +1,393 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"strings"
+	"time"
+
+	"github.com/dipress/crmifc/internal/kit/auth"
+	"github.com/dipress/crmifc/internal/kit/totp"
+	"github.com/dipress/crmifc/internal/user"
+	"github.com/pkg/errors"
+	"rsc.io/qr"
+)
+
+// easyjson mfa.go
+
+var (
+	// ErrInvalidMFAToken returns when the mfa pending
+	// token is invalid or expired.
+	ErrInvalidMFAToken = errors.New("invalid mfa token")
+	// ErrInvalidCode returns when the totp code or the
+	// recovery code is wrong or was used already.
+	ErrInvalidCode = errors.New("invalid code")
+	// ErrMFAEnabled returns on the enrollment when
+	// mfa of the user is enabled already.
+	ErrMFAEnabled = errors.New("mfa is enabled already")
+	// ErrMFANotFound returns when the user didn't enroll mfa.
+	ErrMFANotFound = errors.New("mfa not found")
+)
+
+const (
+	// DefaultMFAIssuer names the service in authenticator apps.
+	DefaultMFAIssuer = "crmifc"
+	// DefaultMFAExpireAfter is the lifetime of the mfa pending token.
+	DefaultMFAExpireAfter = 5 * time.Minute
+
+	recoveryCodesCount = 10
+	qrScale            = 4
+)
+
+// MFA holds the totp secret of the user. The secret
+// is enabled after the first code is verified.
+type MFA struct {
+	UserID  int
+	Secret  string
+	Enabled bool
+	// LastCounter is the time step of the last accepted
+	// code, so each code works only once.
+	LastCounter int64
+}
+
+// MFARepository stores totp secrets and recovery codes.
+type MFARepository interface {
+	FindMFA(ctx context.Context, userID int) (*MFA, error)
+	SaveMFASecret(ctx context.Context, userID int, secret string) error
+	EnableMFA(ctx context.Context, userID int, counter int64, codeHashes []string) error
+	UseMFACounter(ctx context.Context, userID int, counter int64) error
+	UseRecoveryCode(ctx context.Context, userID int, hash string) error
+	DisableMFA(ctx context.Context, userID int) error
+}
+
+// MFAForm is the second step form of the sign in.
+//easyjson:json
+type MFAForm struct {
+	MFAToken string `json:"mfa_token"`
+	Code     string `json:"code"`
+}
+
+// CodeForm holds the totp code or the recovery code.
+//easyjson:json
+type CodeForm struct {
+	Code string `json:"code"`
+}
+
+// Enrollment holds the new secret of the user. Authenticator
+// apps scan the QR code of the otpauth URI.
+//easyjson:json
+type Enrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+	QRCode []byte `json:"qr_png"`
+}
+
+// RecoveryCodes holds the one-time codes which are used
+// instead of the totp codes when the device is lost.
+//easyjson:json
+type RecoveryCodes struct {
+	Codes []string `json:"recovery_codes"`
+}
+
+// VerifyMFA passes the second step of the sign in by the mfa pending
+// token and the code. When the user enrolls mfa on the sign in, the
+// secret is enabled and the recovery codes are set to t. Each
+// attempt is recorded into the sign in history.
+func (s *Service) VerifyMFA(ctx context.Context, f *MFAForm, c Client, t *Token) error {
+	claims, err := s.TokenGenerator.ParseMFAClaims(ctx, f.MFAToken)
+	if err != nil {
+		s.loginEvent(ctx, 0, "", c, t, ErrInvalidMFAToken)
+		return ErrInvalidMFAToken
+	}
+
+	err = s.verifyMFA(ctx, &claims, f, c, t)
+	s.loginEvent(ctx, claims.UserID, claims.Subject, c, t, err)
+	return err
+}
+
+// verifyMFA passes the second step of the sign in of the claims user.
+func (s *Service) verifyMFA(ctx context.Context, claims *auth.Claims, f *MFAForm, c Client, t *Token) error {
+	err := s.checkCode(ctx, claims.Subject, c, func(now time.Time) error {
+		m, err := s.MFARepository.FindMFA(ctx, claims.UserID)
+		if err != nil {
+			return errors.Wrap(err, "find mfa")
+		}
+
+		if m.Enabled {
+			return s.verifyCode(ctx, m, f.Code, now)
+		}
+
+		t.RecoveryCodes, err = s.enable(ctx, m, f.Code, now)
+		return err
+	})
+	if err != nil {
+		return errors.Wrap(err, "check code")
+	}
+
+	// The pending token works only once.
+	if err := s.TokenRepository.RevokeToken(ctx, claims.Id, time.Unix(claims.ExpiresAt, 0)); err != nil {
+		return errors.Wrap(err, "revoke mfa token")
+	}
+
+	usr, err := s.UserRepository.Find(ctx, claims.UserID)
+	if err != nil {
+		return errors.Wrap(err, "find user")
+	}
+
+	if err := s.signIn(ctx, usr, c, t); err != nil {
+		return errors.Wrap(err, "sign in")
+	}
+
+	return nil
+}
+
+// EnrollMFA generates a new secret of the signed in user.
+// It is enabled by ConfirmMFA with the first code.
+func (s *Service) EnrollMFA(ctx context.Context) (*Enrollment, error) {
+	claims, ok := auth.FromContext(ctx)
+	if !ok {
+		return nil, errors.New("claims missing from context")
+	}
+
+	e, err := s.enroll(ctx, claims.User.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "enroll")
+	}
+
+	return e, nil
+}
+
+// EnrollPendingMFA generates a new secret of the user whose role
+// requires mfa on the sign in. It is enabled by VerifyMFA.
+func (s *Service) EnrollPendingMFA(ctx context.Context, mfaToken string) (*Enrollment, error) {
+	claims, err := s.TokenGenerator.ParseMFAClaims(ctx, mfaToken)
+	if err != nil {
+		return nil, ErrInvalidMFAToken
+	}
+
+	e, err := s.enroll(ctx, claims.UserID)
+	if err != nil {
+		return nil, errors.Wrap(err, "enroll")
+	}
+
+	return e, nil
+}
+
+// ConfirmMFA enables the secret of the signed in user by
+// the first code and returns the recovery codes. Wrong codes
+// are counted the same way as on the sign in.
+func (s *Service) ConfirmMFA(ctx context.Context, code string, c Client) (*RecoveryCodes, error) {
+	claims, ok := auth.FromContext(ctx)
+	if !ok {
+		return nil, errors.New("claims missing from context")
+	}
+
+	m, err := s.MFARepository.FindMFA(ctx, claims.User.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "find mfa")
+	}
+
+	if m.Enabled {
+		return nil, ErrMFAEnabled
+	}
+
+	var codes []string
+	err = s.checkCode(ctx, claims.User.Email, c, func(now time.Time) error {
+		codes, err = s.enable(ctx, m, code, now)
+		return err
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "enable")
+	}
+
+	return &RecoveryCodes{Codes: codes}, nil
+}
+
+// DisableMFA removes the secret and the recovery codes of the
+// signed in user. It requires a valid code, so a stolen access
+// token isn't enough to turn mfa off. Wrong codes are counted
+// the same way as on the sign in.
+func (s *Service) DisableMFA(ctx context.Context, code string, c Client) error {
+	claims, ok := auth.FromContext(ctx)
+	if !ok {
+		return errors.New("claims missing from context")
+	}
+
+	m, err := s.MFARepository.FindMFA(ctx, claims.User.ID)
+	if err != nil {
+		return errors.Wrap(err, "find mfa")
+	}
+
+	if !m.Enabled {
+		return ErrMFANotFound
+	}
+
+	err = s.checkCode(ctx, claims.User.Email, c, func(now time.Time) error {
+		return s.verifyCode(ctx, m, code, now)
+	})
+	if err != nil {
+		return errors.Wrap(err, "verify code")
+	}
+
+	if err := s.MFARepository.DisableMFA(ctx, m.UserID); err != nil {
+		return errors.Wrap(err, "disable mfa")
+	}
+
+	return nil
+}
+
+// challenge sets the mfa pending token instead of the access token.
+func (s *Service) challenge(ctx context.Context, u *user.User, m *MFA, t *Token) error {
+	claims := auth.NewMFAClaims(u, time.Now(), s.MFAExpireAfter)
+
+	tknStr, err := s.GenerateToken(ctx, claims)
+	if err != nil {
+		return errors.Wrap(err, "generate token")
+	}
+
+	t.MFARequired = true
+	t.MFAEnroll = m == nil || !m.Enabled
+	t.MFAToken = tknStr
+
+	return nil
+}
+
+// findMFA returns the mfa of the user or nil if it isn't enrolled.
+func (s *Service) findMFA(ctx context.Context, userID int) (*MFA, error) {
+	m, err := s.MFARepository.FindMFA(ctx, userID)
+	if err != nil {
+		if errors.Cause(err) == ErrMFANotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return m, nil
+}
+
+func (s *Service) enroll(ctx context.Context, userID int) (*Enrollment, error) {
+	m, err := s.findMFA(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "find mfa")
+	}
+
+	if m != nil && m.Enabled {
+		return nil, ErrMFAEnabled
+	}
+
+	u, err := s.UserRepository.Find(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "find user")
+	}
+
+	secret, err := totp.NewSecret()
+	if err != nil {
+		return nil, errors.Wrap(err, "new secret")
+	}
+
+	if err := s.MFARepository.SaveMFASecret(ctx, userID, secret); err != nil {
+		return nil, errors.Wrap(err, "save mfa secret")
+	}
+
+	uri := totp.URI(s.MFAIssuer, u.Email, secret)
+
+	code, err := qr.Encode(uri, qr.M)
+	if err != nil {
+		return nil, errors.Wrap(err, "encode qr code")
+	}
+	code.Scale = qrScale
+
+	e := Enrollment{
+		Secret: secret,
+		URI:    uri,
+		QRCode: code.PNG(),
+	}
+
+	return &e, nil
+}
+
+// enable enables the secret by its first code and
+// returns the new recovery codes.
+func (s *Service) enable(ctx context.Context, m *MFA, code string, now time.Time) ([]string, error) {
+	counter, ok := totp.Match(m.Secret, code, now)
+	if !ok {
+		return nil, ErrInvalidCode
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, errors.Wrap(err, "new recovery codes")
+	}
+
+	if err := s.MFARepository.EnableMFA(ctx, m.UserID, counter, hashes); err != nil {
+		return nil, errors.Wrap(err, "enable mfa")
+	}
+
+	return codes, nil
+}
+
+// checkCode runs verify under the lockout of the account and the
+// client ip. The failure is counted when the code is invalid, the
+// failures of the account are reset when it is accepted.
+func (s *Service) checkCode(ctx context.Context, email string, c Client, verify func(now time.Time) error) error {
+	now := time.Now()
+	accountKey, ipKey := AccountKey(email), IPKey(c.IP)
+
+	if err := s.checkLock(ctx, now, accountKey, ipKey); err != nil {
+		return err
+	}
+
+	if err := verify(now); err != nil {
+		if errors.Cause(err) == ErrInvalidCode {
+			if err := s.failAttempt(ctx, now, accountKey, ipKey); err != nil {
+				return errors.Wrap(err, "fail attempt")
+			}
+		}
+		return err
+	}
+
+	if err := s.AttemptRepository.ResetAttempts(ctx, accountKey); err != nil {
+		return errors.Wrap(err, "reset attempts")
+	}
+
+	return nil
+}
+
+// verifyCode accepts the totp code which wasn't used yet
+// or any of the unused recovery codes.
+func (s *Service) verifyCode(ctx context.Context, m *MFA, code string, now time.Time) error {
+	if counter, ok := totp.Match(m.Secret, code, now); ok {
+		if counter <= m.LastCounter {
+			return ErrInvalidCode
+		}
+		return s.MFARepository.UseMFACounter(ctx, m.UserID, counter)
+	}
+
+	return s.MFARepository.UseRecoveryCode(ctx, m.UserID, hashToken(normalizeRecoveryCode(code)))
+}
+
+// newRecoveryCodes returns the recovery codes and their hashes.
+func newRecoveryCodes() ([]string, []string, error) {
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+
+	codes := make([]string, recoveryCodesCount)
+	hashes := make([]string, recoveryCodesCount)
+	for i := range codes {
+		b := make([]byte, 5)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, errors.Wrap(err, "read random bytes")
+		}
+
+		code := strings.ToLower(encoding.EncodeToString(b))
+		codes[i] = code[:4] + "-" + code[4:]
+		hashes[i] = hashToken(normalizeRecoveryCode(codes[i]))
+	}
+
+	return codes, hashes, nil
+}
+
+// normalizeRecoveryCode allows to type the code
+// in any case with or without the dash.
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
@@ -0,0 +1,376 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package auth
+
+import (
+	json "encoding/json"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjsonFc00c7ecDecodeGithubComDipressCrmifcInternalAuth(in *jlexer.Lexer, out *RecoveryCodes) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "recovery_codes":
+			if in.IsNull() {
+				in.Skip()
+				out.Codes = nil
+			} else {
+				in.Delim('[')
+				if out.Codes == nil {
+					if !in.IsDelim(']') {
+						out.Codes = make([]string, 0, 4)
+					} else {
+						out.Codes = []string{}
+					}
+				} else {
+					out.Codes = (out.Codes)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v1 string
+					v1 = string(in.String())
+					out.Codes = append(out.Codes, v1)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonFc00c7ecEncodeGithubComDipressCrmifcInternalAuth(out *jwriter.Writer, in RecoveryCodes) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"recovery_codes\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		if in.Codes == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v2, v3 := range in.Codes {
+				if v2 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v3))
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v RecoveryCodes) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonFc00c7ecEncodeGithubComDipressCrmifcInternalAuth(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v RecoveryCodes) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonFc00c7ecEncodeGithubComDipressCrmifcInternalAuth(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *RecoveryCodes) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonFc00c7ecDecodeGithubComDipressCrmifcInternalAuth(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *RecoveryCodes) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonFc00c7ecDecodeGithubComDipressCrmifcInternalAuth(l, v)
+}
+func easyjsonFc00c7ecDecodeGithubComDipressCrmifcInternalAuth1(in *jlexer.Lexer, out *MFAForm) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "mfa_token":
+			out.MFAToken = string(in.String())
+		case "code":
+			out.Code = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonFc00c7ecEncodeGithubComDipressCrmifcInternalAuth1(out *jwriter.Writer, in MFAForm) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"mfa_token\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.MFAToken))
+	}
+	{
+		const prefix string = ",\"code\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Code))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v MFAForm) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonFc00c7ecEncodeGithubComDipressCrmifcInternalAuth1(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v MFAForm) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonFc00c7ecEncodeGithubComDipressCrmifcInternalAuth1(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *MFAForm) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonFc00c7ecDecodeGithubComDipressCrmifcInternalAuth1(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *MFAForm) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonFc00c7ecDecodeGithubComDipressCrmifcInternalAuth1(l, v)
+}
+func easyjsonFc00c7ecDecodeGithubComDipressCrmifcInternalAuth2(in *jlexer.Lexer, out *Enrollment) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "secret":
+			out.Secret = string(in.String())
+		case "uri":
+			out.URI = string(in.String())
+		case "qr_png":
+			if in.IsNull() {
+				in.Skip()
+				out.QRCode = nil
+			} else {
+				out.QRCode = in.Bytes()
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonFc00c7ecEncodeGithubComDipressCrmifcInternalAuth2(out *jwriter.Writer, in Enrollment) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"secret\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Secret))
+	}
+	{
+		const prefix string = ",\"uri\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.URI))
+	}
+	{
+		const prefix string = ",\"qr_png\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Base64Bytes(in.QRCode)
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Enrollment) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonFc00c7ecEncodeGithubComDipressCrmifcInternalAuth2(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Enrollment) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonFc00c7ecEncodeGithubComDipressCrmifcInternalAuth2(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Enrollment) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonFc00c7ecDecodeGithubComDipressCrmifcInternalAuth2(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Enrollment) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonFc00c7ecDecodeGithubComDipressCrmifcInternalAuth2(l, v)
+}
+func easyjsonFc00c7ecDecodeGithubComDipressCrmifcInternalAuth3(in *jlexer.Lexer, out *CodeForm) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "code":
+			out.Code = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonFc00c7ecEncodeGithubComDipressCrmifcInternalAuth3(out *jwriter.Writer, in CodeForm) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"code\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Code))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v CodeForm) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonFc00c7ecEncodeGithubComDipressCrmifcInternalAuth3(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v CodeForm) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonFc00c7ecEncodeGithubComDipressCrmifcInternalAuth3(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *CodeForm) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonFc00c7ecDecodeGithubComDipressCrmifcInternalAuth3(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *CodeForm) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonFc00c7ecDecodeGithubComDipressCrmifcInternalAuth3(l, v)
+}
@@ -0,0 +1,316 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/dipress/crmifc/internal/kit/auth"
+	"github.com/dipress/crmifc/internal/kit/totp"
+	"github.com/dipress/crmifc/internal/role"
+	"github.com/dipress/crmifc/internal/session"
+	"github.com/dipress/crmifc/internal/user"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func Test_Service_MFA(t *testing.T) {
+	pw, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("failed to generate password: %v", err)
+	}
+
+	repo := repositoryFunc(func(ctx context.Context, email string) (*user.User, error) {
+		u := user.User{
+			ID:           1,
+			Email:        "username@example.com",
+			PasswordHash: string(pw),
+			Role:         role.Role{ID: 1, MFARequired: true},
+		}
+		return &u, nil
+	})
+	tokens := newTokenRepository()
+	attempts := newAttemptRepository()
+	mfa := newMFARepository()
+	sessions := newSessionRepository()
+
+	s := NewService(repo, tokens, attempts, mfa, sessions, newMFATokens(), time.Hour, 24*time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	signIn := func() Token {
+		var got Token
+		if err := s.Authenticate(ctx, "username@example.com", "password123", Client{IP: "127.0.0.1"}, &got); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		assert.True(t, got.MFARequired)
+		assert.Empty(t, got.Token)
+		return got
+	}
+
+	// The role requires mfa, so the user enrolls it on the sign in.
+	pending := signIn()
+	assert.True(t, pending.MFAEnroll)
+
+	e, err := s.EnrollPendingMFA(ctx, pending.MFAToken)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert.True(t, strings.HasPrefix(e.URI, "otpauth://totp/crmifc:username@example.com?"))
+	assert.NotEmpty(t, e.QRCode)
+
+	var got Token
+	err = s.VerifyMFA(ctx, &MFAForm{MFAToken: pending.MFAToken, Code: "000000"}, Client{IP: "127.0.0.1"}, &got)
+	assert.Equal(t, ErrInvalidCode, errors.Cause(err))
+	assert.Equal(t, 1, attempts.attempts[AccountKey("username@example.com")].Failures)
+
+	code, err := totp.Code(e.Secret, time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assert.Nil(t, s.VerifyMFA(ctx, &MFAForm{MFAToken: pending.MFAToken, Code: code}, Client{IP: "127.0.0.1"}, &got))
+	assert.Equal(t, "token", got.Token)
+	assert.Len(t, got.RecoveryCodes, recoveryCodesCount)
+	assert.NotContains(t, attempts.attempts, AccountKey("username@example.com"))
+	assert.Len(t, tokens.revoked, 1)
+
+	// The enrolled user passes the second step with the code once.
+	pending = signIn()
+	assert.False(t, pending.MFAEnroll)
+
+	err = s.VerifyMFA(ctx, &MFAForm{MFAToken: pending.MFAToken, Code: code}, Client{IP: "127.0.0.1"}, &Token{})
+	assert.Equal(t, ErrInvalidCode, errors.Cause(err))
+
+	recovery := strings.ToUpper(got.RecoveryCodes[0])
+	assert.Nil(t, s.VerifyMFA(ctx, &MFAForm{MFAToken: pending.MFAToken, Code: recovery}, Client{IP: "127.0.0.1"}, &Token{}))
+
+	err = s.VerifyMFA(ctx, &MFAForm{MFAToken: pending.MFAToken, Code: recovery}, Client{IP: "127.0.0.1"}, &Token{})
+	assert.Equal(t, ErrInvalidCode, errors.Cause(err))
+
+	err = s.VerifyMFA(ctx, &MFAForm{MFAToken: "unknown", Code: code}, Client{IP: "127.0.0.1"}, &Token{})
+	assert.Equal(t, ErrInvalidMFAToken, err)
+
+	// The password step isn't successful until the second one is passed.
+	var reasons []string
+	for _, e := range sessions.events {
+		if e.Success {
+			reasons = append(reasons, "success")
+			continue
+		}
+		reasons = append(reasons, e.Reason)
+	}
+	assert.Equal(t, []string{
+		"mfa required", "wrong code", "success",
+		"mfa required", "wrong code", "success", "wrong code",
+		"invalid mfa token",
+	}, reasons)
+	assert.Equal(t, session.NewLoginEvent{UserID: 1, Email: "username@example.com", IP: "127.0.0.1", Success: true}, sessions.events[2])
+}
+
+func Test_Service_ConfirmMFA(t *testing.T) {
+	repo := repositoryFunc(func(ctx context.Context, email string) (*user.User, error) {
+		return &user.User{ID: 1, Email: "username@example.com"}, nil
+	})
+	mfa := newMFARepository()
+
+	s := NewService(repo, newTokenRepository(), newAttemptRepository(), mfa, newSessionRepository(), newMFATokens(), time.Hour, 24*time.Hour)
+
+	claims := auth.NewClaims(&user.User{ID: 1, Email: "username@example.com"}, time.Now(), time.Hour)
+	claims.User = user.User{ID: 1, Email: "username@example.com"}
+	ctx := auth.ToContext(context.Background(), &claims)
+	c := Client{IP: "127.0.0.1"}
+
+	e, err := s.EnrollMFA(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err = s.ConfirmMFA(ctx, "000000", c)
+	assert.Equal(t, ErrInvalidCode, errors.Cause(err))
+
+	code, err := totp.Code(e.Secret, time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	codes, err := s.ConfirmMFA(ctx, code, c)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert.Len(t, codes.Codes, recoveryCodesCount)
+
+	_, err = s.ConfirmMFA(ctx, code, c)
+	assert.Equal(t, ErrMFAEnabled, errors.Cause(err))
+
+	_, err = s.EnrollMFA(ctx)
+	assert.Equal(t, ErrMFAEnabled, errors.Cause(err))
+
+	assert.Nil(t, s.DisableMFA(ctx, codes.Codes[0], c))
+
+	_, err = mfa.FindMFA(ctx, 1)
+	assert.Equal(t, ErrMFANotFound, err)
+}
+
+func Test_Service_MFACodeLockout(t *testing.T) {
+	repo := repositoryFunc(func(ctx context.Context, email string) (*user.User, error) {
+		return &user.User{ID: 1, Email: "username@example.com"}, nil
+	})
+	attempts := newAttemptRepository()
+
+	s := NewService(repo, newTokenRepository(), attempts, newMFARepository(), newSessionRepository(), newMFATokens(), time.Hour, 24*time.Hour)
+	s.Lockout = Lockout{AccountThreshold: 3, IPThreshold: 100, BaseDelay: time.Minute, MaxDelay: time.Hour, Window: time.Hour}
+
+	claims := auth.NewClaims(&user.User{ID: 1, Email: "username@example.com"}, time.Now(), time.Hour)
+	claims.User = user.User{ID: 1, Email: "username@example.com"}
+	ctx := auth.ToContext(context.Background(), &claims)
+	c := Client{IP: "127.0.0.1"}
+
+	e, err := s.EnrollMFA(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	code, err := totp.Code(e.Secret, time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	codes, err := s.ConfirmMFA(ctx, code, c)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The wrong codes of the signed in user are counted
+	// the same way as on the sign in.
+	for i := 0; i < 3; i++ {
+		err := s.DisableMFA(ctx, "000000", c)
+		assert.Equal(t, ErrInvalidCode, errors.Cause(err))
+	}
+	assert.Equal(t, 3, attempts.attempts[AccountKey("username@example.com")].Failures)
+
+	err = s.DisableMFA(ctx, codes.Codes[0], c)
+	_, locked := errors.Cause(err).(*LockedError)
+	assert.True(t, locked)
+}
+
+// mfaTokens keeps claims of the generated tokens in memory.
+type mfaTokens struct {
+	mu     sync.Mutex
+	claims map[string]auth.Claims
+}
+
+func newMFATokens() *mfaTokens {
+	t := mfaTokens{
+		claims: make(map[string]auth.Claims),
+	}
+	return &t
+}
+
+func (t *mfaTokens) GenerateToken(ctx context.Context, claims jwt.Claims) (string, error) {
+	c := claims.(auth.Claims)
+	if c.Audience != auth.MFAAudience {
+		return "token", nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	tknStr := fmt.Sprintf("mfa.%s", c.Id)
+	t.claims[tknStr] = c
+	return tknStr, nil
+}
+
+func (t *mfaTokens) ParseMFAClaims(ctx context.Context, tknStr string) (auth.Claims, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	c, ok := t.claims[tknStr]
+	if !ok {
+		return auth.Claims{}, errors.New("invalid token")
+	}
+	return c, nil
+}
+
+// mfaRepository keeps secrets and recovery codes in memory.
+type mfaRepository struct {
+	mu    sync.Mutex
+	mfa   map[int]*MFA
+	codes map[int]map[string]bool
+}
+
+func newMFARepository() *mfaRepository {
+	r := mfaRepository{
+		mfa:   make(map[int]*MFA),
+		codes: make(map[int]map[string]bool),
+	}
+	return &r
+}
+
+func (r *mfaRepository) FindMFA(ctx context.Context, userID int) (*MFA, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m, ok := r.mfa[userID]
+	if !ok {
+		return nil, ErrMFANotFound
+	}
+	found := *m
+	return &found, nil
+}
+
+func (r *mfaRepository) SaveMFASecret(ctx context.Context, userID int, secret string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if m, ok := r.mfa[userID]; ok && m.Enabled {
+		return ErrMFAEnabled
+	}
+	r.mfa[userID] = &MFA{UserID: userID, Secret: secret}
+	return nil
+}
+
+func (r *mfaRepository) EnableMFA(ctx context.Context, userID int, counter int64, codeHashes []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m := r.mfa[userID]
+	m.Enabled = true
+	m.LastCounter = counter
+	r.codes[userID] = make(map[string]bool)
+	for _, hash := range codeHashes {
+		r.codes[userID][hash] = false
+	}
+	return nil
+}
+
+func (r *mfaRepository) UseMFACounter(ctx context.Context, userID int, counter int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m := r.mfa[userID]
+	if counter <= m.LastCounter {
+		return ErrInvalidCode
+	}
+	m.LastCounter = counter
+	return nil
+}
+
+func (r *mfaRepository) UseRecoveryCode(ctx context.Context, userID int, hash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	used, ok := r.codes[userID][hash]
+	if !ok || used {
+		return ErrInvalidCode
+	}
+	r.codes[userID][hash] = true
+	return nil
+}
+
+func (r *mfaRepository) DisableMFA(ctx context.Context, userID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.mfa, userID)
+	delete(r.codes, userID)
+	return nil
+}
@@ -0,0 +1,294 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/dipress/crmifc/internal/kit/oidc"
+	"github.com/dipress/crmifc/internal/role"
+	"github.com/dipress/crmifc/internal/user"
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrOIDCDisabled returns when the single sign-on isn't configured.
+	ErrOIDCDisabled = errors.New("oidc is disabled")
+	// ErrInvalidOIDCState returns when the state of the callback
+	// is unknown, expired or was used already.
+	ErrInvalidOIDCState = errors.New("invalid oidc state")
+	// ErrInvalidIDToken returns when the provider refuses the code
+	// or the ID token doesn't hold the verified email.
+	ErrInvalidIDToken = errors.New("invalid id token")
+	// ErrOIDCUserNotFound returns when the user of the ID token
+	// isn't found and can't be provisioned.
+	ErrOIDCUserNotFound = errors.New("oidc user not found")
+)
+
+const (
+	// DefaultOIDCStateExpireAfter is the time given
+	// to sign in at the provider.
+	DefaultOIDCStateExpireAfter = 10 * time.Minute
+
+	// ssoPasswordHash is set to the provisioned users. It isn't
+	// a bcrypt hash, so the password sign in always fails until
+	// the password is reset.
+	ssoPasswordHash = "!"
+)
+
+// OIDCProvider is the OpenID Connect identity provider.
+type OIDCProvider interface {
+	AuthCodeURL(state, nonce, challenge string) string
+	Exchange(ctx context.Context, code, verifier, nonce string) (*oidc.Claims, error)
+}
+
+// OIDCState holds the sign in which waits for the callback
+// of the provider. Only the hash of the state is stored.
+type OIDCState struct {
+	Nonce     string
+	Verifier  string
+	ExpiresAt time.Time
+}
+
+// OIDCRepository stores the sign ins which wait for the callback.
+type OIDCRepository interface {
+	SaveOIDCState(ctx context.Context, hash string, s *OIDCState) error
+	UseOIDCState(ctx context.Context, hash string) (*OIDCState, error)
+}
+
+// OIDCUserRepository provisions the users and syncs their roles.
+type OIDCUserRepository interface {
+	Create(ctx context.Context, f *user.NewUser, usr *user.User) error
+	UniqueUsername(ctx context.Context, username string) error
+	UpdateRole(ctx context.Context, id, roleID int) error
+}
+
+// RoleRepository finds the roles of the provider groups.
+type RoleRepository interface {
+	FindByName(ctx context.Context, name string) (*role.Role, error)
+}
+
+// OIDC holds the single sign-on settings. Users are matched by
+// the email, the groups are matched with the roles by name.
+type OIDC struct {
+	OIDCProvider
+	OIDCRepository
+	Users OIDCUserRepository
+	Roles RoleRepository
+	// Provision creates the users who sign in for the first time.
+	Provision bool
+	// DefaultRole names the role of the provisioned users
+	// whose groups don't match any role.
+	DefaultRole      string
+	StateExpireAfter time.Duration
+}
+
+// NewOIDC factory prepares the single sign-on to work.
+func NewOIDC(p OIDCProvider, r OIDCRepository, ur OIDCUserRepository, rr RoleRepository) *OIDC {
+	o := OIDC{
+		OIDCProvider:     p,
+		OIDCRepository:   r,
+		Users:            ur,
+		Roles:            rr,
+		StateExpireAfter: DefaultOIDCStateExpireAfter,
+	}
+
+	return &o
+}
+
+// OIDCLogin holds the provider URL which the user is redirected
+// to and the state which the callback has to bring back.
+type OIDCLogin struct {
+	URL   string
+	State string
+}
+
+// OIDCLogin starts the sign in at the provider with
+// the authorization code flow and PKCE.
+func (s *Service) OIDCLogin(ctx context.Context) (*OIDCLogin, error) {
+	if s.OIDC == nil {
+		return nil, ErrOIDCDisabled
+	}
+
+	var values [3]string
+	for i := range values {
+		v, err := oidc.NewRandom()
+		if err != nil {
+			return nil, errors.Wrap(err, "new random")
+		}
+		values[i] = v
+	}
+	state, nonce, verifier := values[0], values[1], values[2]
+
+	st := OIDCState{
+		Nonce:     nonce,
+		Verifier:  verifier,
+		ExpiresAt: time.Now().Add(s.OIDC.StateExpireAfter),
+	}
+
+	if err := s.OIDC.SaveOIDCState(ctx, hashToken(state), &st); err != nil {
+		return nil, errors.Wrap(err, "save oidc state")
+	}
+
+	l := OIDCLogin{
+		URL:   s.OIDC.AuthCodeURL(state, nonce, oidc.Challenge(verifier)),
+		State: state,
+	}
+
+	return &l, nil
+}
+
+// OIDCCallback finishes the sign in at the provider. The code is
+// exchanged for the ID token, the user is found by its email or
+// provisioned and then signed in the same way as by the password.
+// Each attempt is recorded into the sign in history.
+func (s *Service) OIDCCallback(ctx context.Context, state, code string, c Client, t *Token) error {
+	if s.OIDC == nil {
+		return ErrOIDCDisabled
+	}
+
+	userID, email, err := s.oidcCallback(ctx, state, code, c, t)
+	s.loginEvent(ctx, userID, email, c, t, err)
+	return err
+}
+
+// oidcCallback signs in the user of the ID token and returns its id
+// and email, they are empty until the ID token is verified.
+func (s *Service) oidcCallback(ctx context.Context, state, code string, c Client, t *Token) (int, string, error) {
+	st, err := s.OIDC.UseOIDCState(ctx, hashToken(state))
+	if err != nil {
+		return 0, "", errors.Wrap(err, "use oidc state")
+	}
+
+	if time.Now().After(st.ExpiresAt) {
+		return 0, "", ErrInvalidOIDCState
+	}
+
+	claims, err := s.OIDC.Exchange(ctx, code, st.Verifier, st.Nonce)
+	if err != nil {
+		return 0, "", errors.Wrapf(ErrInvalidIDToken, "exchange: %v", err)
+	}
+
+	if claims.Email == "" || claims.EmailVerified == nil || !*claims.EmailVerified {
+		return 0, claims.Email, errors.Wrap(ErrInvalidIDToken, "email isn't verified")
+	}
+
+	rl, err := s.groupRole(ctx, claims.Groups)
+	if err != nil {
+		return 0, claims.Email, errors.Wrap(err, "group role")
+	}
+
+	usr, err := s.oidcUser(ctx, claims, rl)
+	if err != nil {
+		return 0, claims.Email, errors.Wrap(err, "oidc user")
+	}
+
+	m, err := s.findMFA(ctx, usr.ID)
+	if err != nil {
+		return usr.ID, claims.Email, errors.Wrap(err, "find mfa")
+	}
+
+	if (m != nil && m.Enabled) || usr.Role.MFARequired {
+		if err := s.challenge(ctx, usr, m, t); err != nil {
+			return usr.ID, claims.Email, errors.Wrap(err, "mfa challenge")
+		}
+		return usr.ID, claims.Email, nil
+	}
+
+	if err := s.signIn(ctx, usr, c, t); err != nil {
+		return usr.ID, claims.Email, errors.Wrap(err, "sign in")
+	}
+
+	return usr.ID, claims.Email, nil
+}
+
+// oidcUser finds the user by the email of the claims. The role of
+// the user is replaced by the role of the groups when it is found.
+// Unknown users are provisioned when it is enabled.
+func (s *Service) oidcUser(ctx context.Context, claims *oidc.Claims, rl *role.Role) (*user.User, error) {
+	found, err := s.UserRepository.FindByEmail(ctx, claims.Email)
+	switch {
+	case err == nil:
+		if rl == nil || rl.ID == found.Role.ID {
+			return found, nil
+		}
+
+		if err := s.OIDC.Users.UpdateRole(ctx, found.ID, rl.ID); err != nil {
+			return nil, errors.Wrap(err, "update role")
+		}
+	case errors.Cause(err) == ErrEmailNotFound:
+		if !s.OIDC.Provision {
+			return nil, ErrOIDCUserNotFound
+		}
+
+		if rl == nil && s.OIDC.DefaultRole != "" {
+			rl, err = s.OIDC.Roles.FindByName(ctx, s.OIDC.DefaultRole)
+			if err != nil {
+				return nil, errors.Wrap(err, "find default role")
+			}
+		}
+
+		if rl == nil {
+			return nil, ErrOIDCUserNotFound
+		}
+
+		found, err = s.provision(ctx, claims, rl)
+		if err != nil {
+			return nil, errors.Wrap(err, "provision")
+		}
+	default:
+		return nil, errors.Wrap(err, "find user by email")
+	}
+
+	// The user is found again to load the actual role.
+	usr, err := s.UserRepository.Find(ctx, found.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "find user")
+	}
+	return usr, nil
+}
+
+// provision creates the user of the claims with the role. The preferred
+// username is used when it isn't taken, otherwise the email is.
+func (s *Service) provision(ctx context.Context, claims *oidc.Claims, rl *role.Role) (*user.User, error) {
+	username := claims.Email
+	if claims.PreferredUsername != "" {
+		err := s.OIDC.Users.UniqueUsername(ctx, claims.PreferredUsername)
+		switch errors.Cause(err) {
+		case nil:
+			username = claims.PreferredUsername
+		case user.ErrUsernameExists:
+		default:
+			return nil, errors.Wrap(err, "unique username")
+		}
+	}
+
+	nu := user.NewUser{
+		Username:     username,
+		Email:        claims.Email,
+		PasswordHash: ssoPasswordHash,
+		RoleID:       rl.ID,
+	}
+
+	var usr user.User
+	if err := s.OIDC.Users.Create(ctx, &nu, &usr); err != nil {
+		return nil, errors.Wrap(err, "create user")
+	}
+
+	return &usr, nil
+}
+
+// groupRole returns the role of the first group which
+// matches a role by name, nil when none of them does.
+func (s *Service) groupRole(ctx context.Context, groups []string) (*role.Role, error) {
+	for _, g := range groups {
+		rl, err := s.OIDC.Roles.FindByName(ctx, g)
+		switch errors.Cause(err) {
+		case nil:
+			return rl, nil
+		case role.ErrNotFound:
+		default:
+			return nil, errors.Wrap(err, "find role by name")
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,338 @@
+package auth
+
+import (
+	"context"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dipress/crmifc/internal/kit/oidc"
+	"github.com/dipress/crmifc/internal/role"
+	"github.com/dipress/crmifc/internal/user"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Service_OIDCLogin(t *testing.T) {
+	s := NewService(newOIDCUsers(), newTokenRepository(), newAttemptRepository(), newMFARepository(), newSessionRepository(), newMFATokens(), time.Hour, 24*time.Hour)
+
+	_, err := s.OIDCLogin(context.Background())
+	assert.Equal(t, ErrOIDCDisabled, err)
+
+	states := newOIDCStates()
+	s.OIDC = NewOIDC(&oidcProvider{}, states, newOIDCUsers(), oidcRoles{})
+
+	l, err := s.OIDCLogin(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	u, err := url.Parse(l.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	st, ok := states.states[hashToken(l.State)]
+	if !ok {
+		t.Fatalf("state isn't saved")
+	}
+	assert.Equal(t, l.State, u.Query().Get("state"))
+	assert.Equal(t, st.Nonce, u.Query().Get("nonce"))
+	assert.Equal(t, oidc.Challenge(st.Verifier), u.Query().Get("code_challenge"))
+}
+
+func Test_Service_OIDCCallback(t *testing.T) {
+	verified, unverified := true, false
+
+	tests := []struct {
+		name        string
+		claims      *oidc.Claims
+		expired     bool
+		state       string
+		provision   bool
+		defaultRole string
+		wantErr     error
+		reason      string
+		wantRole    int
+		wantUser    string
+	}{
+		{
+			name:     "ok",
+			claims:   &oidc.Claims{Email: "username@example.com", EmailVerified: &verified},
+			wantRole: 1,
+		},
+		{
+			name:     "group role is synced",
+			claims:   &oidc.Claims{Email: "username@example.com", EmailVerified: &verified, Groups: []string{"Unknown", "Manager"}},
+			wantRole: 2,
+		},
+		{
+			name:    "unknown state",
+			claims:  &oidc.Claims{Email: "username@example.com", EmailVerified: &verified},
+			state:   "unknown",
+			wantErr: ErrInvalidOIDCState,
+			reason:  "invalid state",
+		},
+		{
+			name:    "expired state",
+			claims:  &oidc.Claims{Email: "username@example.com", EmailVerified: &verified},
+			expired: true,
+			wantErr: ErrInvalidOIDCState,
+			reason:  "invalid state",
+		},
+		{
+			name:    "unverified email",
+			claims:  &oidc.Claims{Email: "username@example.com", EmailVerified: &unverified},
+			wantErr: ErrInvalidIDToken,
+			reason:  "invalid id token",
+		},
+		{
+			name:    "email verification is absent",
+			claims:  &oidc.Claims{Email: "username@example.com"},
+			wantErr: ErrInvalidIDToken,
+			reason:  "invalid id token",
+		},
+		{
+			name:    "exchange failed",
+			wantErr: ErrInvalidIDToken,
+			reason:  "invalid id token",
+		},
+		{
+			name:    "provisioning is disabled",
+			claims:  &oidc.Claims{Email: "new@example.com", EmailVerified: &verified, Groups: []string{"Manager"}},
+			wantErr: ErrOIDCUserNotFound,
+			reason:  "unknown email",
+		},
+		{
+			name:      "provisioning without role",
+			claims:    &oidc.Claims{Email: "new@example.com", EmailVerified: &verified},
+			provision: true,
+			wantErr:   ErrOIDCUserNotFound,
+			reason:    "unknown email",
+		},
+		{
+			name:      "provisioned with group role",
+			claims:    &oidc.Claims{Email: "new@example.com", EmailVerified: &verified, PreferredUsername: "newcomer", Groups: []string{"Manager"}},
+			provision: true,
+			wantRole:  2,
+			wantUser:  "newcomer",
+		},
+		{
+			name:        "provisioned with default role",
+			claims:      &oidc.Claims{Email: "new@example.com", EmailVerified: &verified, PreferredUsername: "username"},
+			provision:   true,
+			defaultRole: "Guest",
+			wantRole:    3,
+			wantUser:    "new@example.com",
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			users := newOIDCUsers()
+			states := newOIDCStates()
+			sessions := newSessionRepository()
+			p := oidcProvider{claims: tc.claims}
+
+			s := NewService(users, newTokenRepository(), newAttemptRepository(), newMFARepository(), sessions, newMFATokens(), time.Hour, 24*time.Hour)
+			s.OIDC = NewOIDC(&p, states, users, oidcRoles{})
+			s.OIDC.Provision = tc.provision
+			s.OIDC.DefaultRole = tc.defaultRole
+
+			ctx := context.Background()
+			l, err := s.OIDCLogin(ctx)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tc.expired {
+				states.states[hashToken(l.State)].ExpiresAt = time.Now().Add(-time.Minute)
+			}
+
+			state := l.State
+			if tc.state != "" {
+				state = tc.state
+			}
+
+			var got Token
+			err = s.OIDCCallback(ctx, state, "code", Client{}, &got)
+
+			if assert.Len(t, sessions.events, 1) {
+				e := sessions.events[0]
+				assert.Equal(t, tc.wantErr == nil, e.Success)
+				assert.Equal(t, tc.reason, e.Reason)
+			}
+
+			if tc.wantErr != nil {
+				assert.Equal(t, tc.wantErr, errors.Cause(err))
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, "token", got.Token)
+			assert.NotEmpty(t, got.RefreshToken)
+
+			usr, err := users.FindByEmail(ctx, tc.claims.Email)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			assert.Equal(t, tc.wantRole, usr.Role.ID)
+			if tc.wantUser != "" {
+				assert.Equal(t, tc.wantUser, usr.Username)
+				assert.Equal(t, ssoPasswordHash, usr.PasswordHash)
+			}
+
+			// The state is used once.
+			err = s.OIDCCallback(ctx, l.State, "code", Client{}, &got)
+			assert.Equal(t, ErrInvalidOIDCState, errors.Cause(err))
+		})
+	}
+}
+
+// oidcProvider returns the claims on the exchange of any code.
+type oidcProvider struct {
+	claims *oidc.Claims
+}
+
+func (p *oidcProvider) AuthCodeURL(state, nonce, challenge string) string {
+	v := url.Values{
+		"state":          {state},
+		"nonce":          {nonce},
+		"code_challenge": {challenge},
+	}
+	return "http://example.com/authorize?" + v.Encode()
+}
+
+func (p *oidcProvider) Exchange(ctx context.Context, code, verifier, nonce string) (*oidc.Claims, error) {
+	if p.claims == nil {
+		return nil, oidc.ErrExchange
+	}
+	c := *p.claims
+	c.Nonce = nonce
+	return &c, nil
+}
+
+// oidcStates keeps the states in memory.
+type oidcStates struct {
+	mu     sync.Mutex
+	states map[string]*OIDCState
+}
+
+func newOIDCStates() *oidcStates {
+	r := oidcStates{
+		states: make(map[string]*OIDCState),
+	}
+	return &r
+}
+
+func (r *oidcStates) SaveOIDCState(ctx context.Context, hash string, s *OIDCState) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.states[hash] = s
+	return nil
+}
+
+func (r *oidcStates) UseOIDCState(ctx context.Context, hash string) (*OIDCState, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.states[hash]
+	if !ok {
+		return nil, ErrInvalidOIDCState
+	}
+	delete(r.states, hash)
+	return s, nil
+}
+
+// oidcRoles finds the roles Member, Manager and Guest.
+type oidcRoles struct{}
+
+func (oidcRoles) FindByName(ctx context.Context, name string) (*role.Role, error) {
+	for id, n := range []string{"Member", "Manager", "Guest"} {
+		if n == name {
+			return &role.Role{ID: id + 1, Name: n}, nil
+		}
+	}
+	return nil, role.ErrNotFound
+}
+
+// oidcUsers keeps the users in memory. The user with
+// the username@example.com email exists with the Member role.
+type oidcUsers struct {
+	mu    sync.Mutex
+	users []user.User
+}
+
+func newOIDCUsers() *oidcUsers {
+	r := oidcUsers{
+		users: []user.User{
+			{ID: 1, Username: "username", Email: "username@example.com", Role: role.Role{ID: 1}},
+		},
+	}
+	return &r
+}
+
+func (r *oidcUsers) FindByEmail(ctx context.Context, email string) (*user.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, u := range r.users {
+		if u.Email == email {
+			return &u, nil
+		}
+	}
+	return nil, ErrEmailNotFound
+}
+
+func (r *oidcUsers) Find(ctx context.Context, id int) (*user.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, u := range r.users {
+		if u.ID == id {
+			return &u, nil
+		}
+	}
+	return nil, user.ErrNotFound
+}
+
+func (r *oidcUsers) UpgradePasswordHash(ctx context.Context, id int, oldHash, newHash string) error {
+	return nil
+}
+
+func (r *oidcUsers) Create(ctx context.Context, f *user.NewUser, usr *user.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	*usr = user.User{
+		ID:           len(r.users) + 1,
+		Username:     f.Username,
+		Email:        f.Email,
+		PasswordHash: f.PasswordHash,
+		Role:         role.Role{ID: f.RoleID},
+	}
+	r.users = append(r.users, *usr)
+	return nil
+}
+
+func (r *oidcUsers) UniqueUsername(ctx context.Context, username string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, u := range r.users {
+		if u.Username == username {
+			return user.ErrUsernameExists
+		}
+	}
+	return nil
+}
+
+func (r *oidcUsers) UpdateRole(ctx context.Context, id, roleID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.users {
+		if r.users[i].ID == id {
+			r.users[i].Role = role.Role{ID: roleID}
+		}
+	}
+	return nil
+}
@@ -2,11 +2,17 @@ package auth
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"log"
 	"time"
 
 	"github.com/dipress/crmifc/internal/kit/auth"
 
 	"github.com/dgrijalva/jwt-go"
+	"github.com/dipress/crmifc/internal/session"
 	"github.com/dipress/crmifc/internal/user"
 	"github.com/pkg/errors"
 	"golang.org/x/crypto/bcrypt"
@@ -21,24 +27,70 @@ var (
 	// ErrWrongPassword returns when given password
 	// isn't equal to to its hash in the database.
 	ErrWrongPassword = errors.New("wrong password")
+	// ErrInvalidRefreshToken returns when given refresh token
+	// is unknown, expired or revoked.
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	// ErrTokenNotFound returns when refresh token isn't
+	// found in database.
+	ErrTokenNotFound = errors.New("refresh token not found")
+	// ErrTokenRevoked returns when refresh token was
+	// revoked already.
+	ErrTokenRevoked = errors.New("refresh token revoked")
+	// ErrDeactivated returns when the user is deactivated
+	// and isn't allowed to sign in.
+	ErrDeactivated = errors.New("user is deactivated")
 )
 
 // UserRepository allows working with a database.
 type UserRepository interface {
 	FindByEmail(ctx context.Context, email string) (*user.User, error)
+	Find(ctx context.Context, id int) (*user.User, error)
+	UpgradePasswordHash(ctx context.Context, id int, oldHash, newHash string) error
+}
+
+// TokenRepository stores refresh tokens and
+// the revocation list of access tokens.
+type TokenRepository interface {
+	CreateRefreshToken(ctx context.Context, userID, sessionID int, hash string, expiresAt time.Time) error
+	FindRefreshToken(ctx context.Context, hash string) (*RefreshToken, error)
+	RevokeRefreshToken(ctx context.Context, id int) error
+	RevokeUserRefreshTokens(ctx context.Context, userID int) error
+	RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error
+}
+
+// SessionRepository stores the sessions and the sign in history.
+type SessionRepository interface {
+	CreateSession(ctx context.Context, ns *session.NewSession, sess *session.Session) error
+	TouchSession(ctx context.Context, id int, expiresAt time.Time) error
+	RevokeSession(ctx context.Context, id int) error
+	CreateLoginEvent(ctx context.Context, ne *session.NewLoginEvent) error
 }
 
-// TokenGenerator generates token for authenticated user.
+// TokenGenerator generates token for authenticated user
+// and parses the mfa pending tokens.
 type TokenGenerator interface {
 	GenerateToken(ctx context.Context, claims jwt.Claims) (string, error)
+	ParseMFAClaims(ctx context.Context, tknStr string) (auth.Claims, error)
 }
 
 // Service holds required data for user
 // authentication.
 type Service struct {
 	UserRepository
+	TokenRepository
+	AttemptRepository
+	MFARepository
+	SessionRepository
 	TokenGenerator
-	ExpireAfter time.Duration
+	ExpireAfter        time.Duration
+	RefreshExpireAfter time.Duration
+	Lockout            Lockout
+	MFAIssuer          string
+	MFAExpireAfter     time.Duration
+	// OIDC enables the single sign-on when it is set.
+	OIDC *OIDC
+	// Cost is the bcrypt cost the password hashes are upgraded to.
+	Cost int
 }
 
 // Form is a user auth form.
@@ -48,47 +100,400 @@ type Form struct {
 	Password string `json:"password"`
 }
 
-// Token holds token data.
+// Token holds token data. When mfa is required only
+// the mfa pending token is set.
 //easyjson:json
 type Token struct {
-	Token string `json:"token"`
+	Token         string   `json:"token,omitempty"`
+	RefreshToken  string   `json:"refresh_token,omitempty"`
+	ExpiresIn     int      `json:"expires_in,omitempty"`
+	MFARequired   bool     `json:"mfa_required,omitempty"`
+	MFAEnroll     bool     `json:"mfa_enroll,omitempty"`
+	MFAToken      string   `json:"mfa_token,omitempty"`
+	RecoveryCodes []string `json:"recovery_codes,omitempty"`
+}
+
+// Client describes where the sign in comes from.
+type Client struct {
+	IP        string
+	UserAgent string
+}
+
+// RefreshForm is a refresh token form.
+//easyjson:json
+type RefreshForm struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// RefreshToken holds stored refresh token data.
+// Only the hash of the token is stored.
+type RefreshToken struct {
+	ID        int
+	UserID    int
+	SessionID int
+	Hash      string
+	ExpiresAt time.Time
+	RevokedAt *time.Time
+	CreatedAt time.Time
 }
 
 // NewService factory takes in required arguments
 // and returns a pointer to the Service instance.
-func NewService(r UserRepository, t TokenGenerator, exp time.Duration) *Service {
+func NewService(r UserRepository, tr TokenRepository, ar AttemptRepository, mr MFARepository, sr SessionRepository, t TokenGenerator, exp, refreshExp time.Duration) *Service {
 	s := Service{
-		UserRepository: r,
-		TokenGenerator: t,
-		ExpireAfter:    exp,
+		UserRepository:     r,
+		TokenRepository:    tr,
+		AttemptRepository:  ar,
+		MFARepository:      mr,
+		SessionRepository:  sr,
+		TokenGenerator:     t,
+		ExpireAfter:        exp,
+		RefreshExpireAfter: refreshExp,
+		Lockout:            DefaultLockout,
+		MFAIssuer:          DefaultMFAIssuer,
+		MFAExpireAfter:     DefaultMFAExpireAfter,
+		Cost:               bcrypt.DefaultCost,
 	}
 
 	return &s
 }
 
 // Authenticate allows authenticating user by given email and password
-// and set t Token value as generated token.
-func (s *Service) Authenticate(ctx context.Context, email, password string, t *Token) error {
+// and set t Token value as generated token. Failed attempts are counted
+// per account and per client ip, when any of them is locked the
+// LockedError is returned. When the user enrolled mfa or the role
+// requires it, only the mfa pending token is set, it is exchanged
+// for the tokens by VerifyMFA. Each attempt is recorded into
+// the sign in history.
+func (s *Service) Authenticate(ctx context.Context, email, password string, c Client, t *Token) error {
+	userID, err := s.authenticate(ctx, email, password, c, t)
+	s.loginEvent(ctx, userID, email, c, t, err)
+	return err
+}
+
+// loginEvent records the outcome of the sign in step into the
+// sign in history. The step which leads to the mfa challenge
+// isn't successful yet. The history doesn't affect the sign
+// in, so the failure to record it is only logged.
+func (s *Service) loginEvent(ctx context.Context, userID int, email string, c Client, t *Token, err error) {
+	ne := session.NewLoginEvent{
+		UserID:    userID,
+		Email:     email,
+		IP:        c.IP,
+		UserAgent: c.UserAgent,
+		Success:   err == nil && !t.MFARequired,
+		Reason:    loginReason(err, t),
+	}
+
+	if err := s.SessionRepository.CreateLoginEvent(ctx, &ne); err != nil {
+		log.Printf("create login event: %+v\n", err)
+	}
+}
+
+// authenticate signs in the user and returns its id,
+// the id is zero when the user isn't found.
+func (s *Service) authenticate(ctx context.Context, email, password string, c Client, t *Token) (int, error) {
+	now := time.Now()
+	accountKey, ipKey := AccountKey(email), IPKey(c.IP)
+
+	if err := s.checkLock(ctx, now, accountKey, ipKey); err != nil {
+		return 0, err
+	}
+
 	user, err := s.UserRepository.FindByEmail(ctx, email)
 	if err != nil {
-		return errors.Wrap(err, "find user by email")
+		if errors.Cause(err) == ErrEmailNotFound {
+			if err := s.failAttempt(ctx, now, accountKey, ipKey); err != nil {
+				return 0, errors.Wrap(err, "fail attempt")
+			}
+		}
+		return 0, errors.Wrap(err, "find user by email")
 	}
 
 	// Compare the provided password with the saved hash. Use the bcrypt
 	// comparison function so it is cryptographically secure.
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
-		return ErrWrongPassword
+		if err := s.failAttempt(ctx, now, accountKey, ipKey); err != nil {
+			return user.ID, errors.Wrap(err, "fail attempt")
+		}
+		return user.ID, ErrWrongPassword
+	}
+
+	// The deactivation is told only to the ones who know the password.
+	if user.DisabledAt != nil {
+		return user.ID, ErrDeactivated
+	}
+
+	// The password is already verified, a failed upgrade
+	// is retried on the next sign in.
+	if err := s.upgradeHash(ctx, user.ID, user.PasswordHash, password); err != nil {
+		log.Printf("upgrade password hash: %+v\n", err)
+	}
+
+	m, err := s.findMFA(ctx, user.ID)
+	if err != nil {
+		return user.ID, errors.Wrap(err, "find mfa")
+	}
+
+	// The account failures are kept until the second step is passed.
+	if (m != nil && m.Enabled) || user.Role.MFARequired {
+		if err := s.challenge(ctx, user, m, t); err != nil {
+			return user.ID, errors.Wrap(err, "mfa challenge")
+		}
+		return user.ID, nil
+	}
+
+	// The failures of the client ip aren't reset, so a valid
+	// account doesn't allow guessing passwords of others.
+	if err := s.AttemptRepository.ResetAttempts(ctx, accountKey); err != nil {
+		return user.ID, errors.Wrap(err, "reset attempts")
 	}
 
 	// If we are this far the request is valid.
-	// Now we need to create the token for the user.
-	claims := auth.NewClaims(user.Email, time.Now(), s.ExpireAfter)
+	// Now we need to create the tokens for the user.
+	if err := s.signIn(ctx, user, c, t); err != nil {
+		return user.ID, errors.Wrap(err, "sign in")
+	}
+
+	return user.ID, nil
+}
+
+// Refresh exchanges the refresh token for a new pair of tokens.
+// The used refresh token is revoked, so each of them works only once.
+// Reuse of a revoked refresh token means it was stolen, then all
+// refresh tokens of the user are revoked. The tokens stay in the
+// session of the refresh token, it doesn't work after the session
+// is revoked.
+func (s *Service) Refresh(ctx context.Context, refreshToken string, t *Token) error {
+	rt, err := s.TokenRepository.FindRefreshToken(ctx, hashToken(refreshToken))
+	if err != nil {
+		if errors.Cause(err) == ErrTokenNotFound {
+			return ErrInvalidRefreshToken
+		}
+		return errors.Wrap(err, "find refresh token")
+	}
+
+	if rt.RevokedAt != nil {
+		if err := s.TokenRepository.RevokeUserRefreshTokens(ctx, rt.UserID); err != nil {
+			return errors.Wrap(err, "revoke user refresh tokens")
+		}
+		return ErrInvalidRefreshToken
+	}
+
+	if time.Now().After(rt.ExpiresAt) {
+		return ErrInvalidRefreshToken
+	}
+
+	if err := s.TokenRepository.RevokeRefreshToken(ctx, rt.ID); err != nil {
+		if errors.Cause(err) == ErrTokenRevoked {
+			return ErrInvalidRefreshToken
+		}
+		return errors.Wrap(err, "revoke refresh token")
+	}
+
+	usr, err := s.UserRepository.Find(ctx, rt.UserID)
+	if err != nil {
+		if errors.Cause(err) == user.ErrNotFound {
+			return ErrInvalidRefreshToken
+		}
+		return errors.Wrap(err, "find user")
+	}
+
+	// Refresh tokens issued before sessions were
+	// added start a new session.
+	if rt.SessionID == 0 {
+		if err := s.signIn(ctx, usr, Client{}, t); err != nil {
+			return errors.Wrap(err, "sign in")
+		}
+		return nil
+	}
+
+	if err := s.SessionRepository.TouchSession(ctx, rt.SessionID, time.Now().Add(s.RefreshExpireAfter)); err != nil {
+		if errors.Cause(err) == session.ErrNotFound {
+			return ErrInvalidRefreshToken
+		}
+		return errors.Wrap(err, "touch session")
+	}
+
+	if err := s.issue(ctx, usr, rt.SessionID, t); err != nil {
+		return errors.Wrap(err, "issue tokens")
+	}
+
+	return nil
+}
+
+// SignOut revokes the access token and the session of the request
+// and given refresh token when it belongs to the same user.
+func (s *Service) SignOut(ctx context.Context, refreshToken string) error {
+	claims, ok := auth.FromContext(ctx)
+	if !ok {
+		return errors.New("claims missing from context")
+	}
+
+	if claims.Id != "" {
+		if err := s.TokenRepository.RevokeToken(ctx, claims.Id, time.Unix(claims.ExpiresAt, 0)); err != nil {
+			return errors.Wrap(err, "revoke access token")
+		}
+	}
+
+	if claims.SessionID != 0 {
+		if err := s.SessionRepository.RevokeSession(ctx, claims.SessionID); err != nil && errors.Cause(err) != session.ErrNotFound {
+			return errors.Wrap(err, "revoke session")
+		}
+	}
+
+	if refreshToken == "" {
+		return nil
+	}
+
+	rt, err := s.TokenRepository.FindRefreshToken(ctx, hashToken(refreshToken))
+	if err != nil {
+		if errors.Cause(err) == ErrTokenNotFound {
+			return nil
+		}
+		return errors.Wrap(err, "find refresh token")
+	}
+
+	if rt.UserID != claims.User.ID || rt.RevokedAt != nil {
+		return nil
+	}
+
+	if err := s.TokenRepository.RevokeRefreshToken(ctx, rt.ID); err != nil && errors.Cause(err) != ErrTokenRevoked {
+		return errors.Wrap(err, "revoke refresh token")
+	}
+
+	return nil
+}
 
-	tknStr, err := s.GenerateToken(ctx, claims.StandardClaims)
+// signIn starts a new session of the user on the client
+// and issues the tokens of the session.
+func (s *Service) signIn(ctx context.Context, u *user.User, c Client, t *Token) error {
+	if u.DisabledAt != nil {
+		return ErrDeactivated
+	}
+
+	ns := session.NewSession{
+		UserID:    u.ID,
+		IP:        c.IP,
+		UserAgent: c.UserAgent,
+		ExpiresAt: time.Now().Add(s.RefreshExpireAfter),
+	}
+
+	var sess session.Session
+	if err := s.SessionRepository.CreateSession(ctx, &ns, &sess); err != nil {
+		return errors.Wrap(err, "create session")
+	}
+
+	if err := s.issue(ctx, u, sess.ID, t); err != nil {
+		return errors.Wrap(err, "issue tokens")
+	}
+
+	return nil
+}
+
+// issue generates the access token and the refresh token of the session
+// for the user. The tokens aren't issued for the deactivated user.
+func (s *Service) issue(ctx context.Context, u *user.User, sessionID int, t *Token) error {
+	if u.DisabledAt != nil {
+		return ErrDeactivated
+	}
+
+	claims := auth.NewClaims(u, time.Now(), s.ExpireAfter)
+	claims.SessionID = sessionID
+
+	tknStr, err := s.GenerateToken(ctx, claims)
 	if err != nil {
 		return errors.Wrap(err, "generate token")
 	}
+
+	refreshToken, err := newRefreshToken()
+	if err != nil {
+		return errors.Wrap(err, "new refresh token")
+	}
+
+	expiresAt := time.Now().Add(s.RefreshExpireAfter)
+	if err := s.TokenRepository.CreateRefreshToken(ctx, u.ID, sessionID, hashToken(refreshToken), expiresAt); err != nil {
+		return errors.Wrap(err, "create refresh token")
+	}
+
 	t.Token = tknStr
+	t.RefreshToken = refreshToken
+	t.ExpiresIn = int(s.ExpireAfter.Seconds())
+
+	return nil
+}
+
+// loginReason tells why the sign in failed or isn't finished yet.
+func loginReason(err error, t *Token) string {
+	if err == nil {
+		if t.MFARequired {
+			return "mfa required"
+		}
+		return ""
+	}
+
+	if _, ok := errors.Cause(err).(*LockedError); ok {
+		return "locked"
+	}
+
+	switch errors.Cause(err) {
+	case ErrEmailNotFound, ErrOIDCUserNotFound:
+		return "unknown email"
+	case ErrWrongPassword:
+		return "wrong password"
+	case ErrDeactivated:
+		return "deactivated"
+	case ErrInvalidMFAToken:
+		return "invalid mfa token"
+	case ErrInvalidCode:
+		return "wrong code"
+	case ErrInvalidOIDCState:
+		return "invalid state"
+	case ErrInvalidIDToken:
+		return "invalid id token"
+	default:
+		return "error"
+	}
+}
+
+// upgradeHash rehashes the password with the configured cost when
+// the stored hash has a lower one. The password is known only while
+// the user signs in, so it's the only moment to upgrade the hash.
+func (s *Service) upgradeHash(ctx context.Context, id int, hash, password string) error {
+	cost, err := bcrypt.Cost([]byte(hash))
+	if err != nil {
+		return errors.Wrap(err, "hash cost")
+	}
+
+	if cost >= s.Cost {
+		return nil
+	}
+
+	upgraded, err := bcrypt.GenerateFromPassword([]byte(password), s.Cost)
+	if err != nil {
+		return errors.Wrap(err, "generating password hash")
+	}
+
+	if err := s.UserRepository.UpgradePasswordHash(ctx, id, hash, string(upgraded)); err != nil {
+		return errors.Wrap(err, "repository upgrade password hash")
+	}
 
 	return nil
 }
+
+// newRefreshToken returns random opaque refresh token.
+func newRefreshToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Wrap(err, "read random bytes")
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken returns the hash of the refresh token which is stored
+// instead of the token itself. The token is random and long enough,
+// so a fast hash is sufficient.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
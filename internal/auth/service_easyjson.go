@@ -38,6 +38,39 @@ func easyjsonCd93bc43DecodeGithubComDipressCrmifcInternalAuth(in *jlexer.Lexer,
 		switch key {
 		case "token":
 			out.Token = string(in.String())
+		case "refresh_token":
+			out.RefreshToken = string(in.String())
+		case "expires_in":
+			out.ExpiresIn = int(in.Int())
+		case "mfa_required":
+			out.MFARequired = bool(in.Bool())
+		case "mfa_enroll":
+			out.MFAEnroll = bool(in.Bool())
+		case "mfa_token":
+			out.MFAToken = string(in.String())
+		case "recovery_codes":
+			if in.IsNull() {
+				in.Skip()
+				out.RecoveryCodes = nil
+			} else {
+				in.Delim('[')
+				if out.RecoveryCodes == nil {
+					if !in.IsDelim(']') {
+						out.RecoveryCodes = make([]string, 0, 4)
+					} else {
+						out.RecoveryCodes = []string{}
+					}
+				} else {
+					out.RecoveryCodes = (out.RecoveryCodes)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v1 string
+					v1 = string(in.String())
+					out.RecoveryCodes = append(out.RecoveryCodes, v1)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
 		default:
 			in.SkipRecursive()
 		}
@@ -52,7 +85,7 @@ func easyjsonCd93bc43EncodeGithubComDipressCrmifcInternalAuth(out *jwriter.Write
 	out.RawByte('{')
 	first := true
 	_ = first
-	{
+	if in.Token != "" {
 		const prefix string = ",\"token\":"
 		if first {
 			first = false
@@ -62,6 +95,75 @@ func easyjsonCd93bc43EncodeGithubComDipressCrmifcInternalAuth(out *jwriter.Write
 		}
 		out.String(string(in.Token))
 	}
+	if in.RefreshToken != "" {
+		const prefix string = ",\"refresh_token\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.RefreshToken))
+	}
+	if in.ExpiresIn != 0 {
+		const prefix string = ",\"expires_in\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int(int(in.ExpiresIn))
+	}
+	if in.MFARequired {
+		const prefix string = ",\"mfa_required\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Bool(bool(in.MFARequired))
+	}
+	if in.MFAEnroll {
+		const prefix string = ",\"mfa_enroll\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Bool(bool(in.MFAEnroll))
+	}
+	if in.MFAToken != "" {
+		const prefix string = ",\"mfa_token\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.MFAToken))
+	}
+	if len(in.RecoveryCodes) != 0 {
+		const prefix string = ",\"recovery_codes\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		{
+			out.RawByte('[')
+			for v2, v3 := range in.RecoveryCodes {
+				if v2 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v3))
+			}
+			out.RawByte(']')
+		}
+	}
 	out.RawByte('}')
 }
 
@@ -88,7 +190,78 @@ func (v *Token) UnmarshalJSON(data []byte) error {
 func (v *Token) UnmarshalEasyJSON(l *jlexer.Lexer) {
 	easyjsonCd93bc43DecodeGithubComDipressCrmifcInternalAuth(l, v)
 }
-func easyjsonCd93bc43DecodeGithubComDipressCrmifcInternalAuth1(in *jlexer.Lexer, out *Form) {
+func easyjsonCd93bc43DecodeGithubComDipressCrmifcInternalAuth1(in *jlexer.Lexer, out *RefreshForm) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "refresh_token":
+			out.RefreshToken = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonCd93bc43EncodeGithubComDipressCrmifcInternalAuth1(out *jwriter.Writer, in RefreshForm) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"refresh_token\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.RefreshToken))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v RefreshForm) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonCd93bc43EncodeGithubComDipressCrmifcInternalAuth1(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v RefreshForm) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonCd93bc43EncodeGithubComDipressCrmifcInternalAuth1(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *RefreshForm) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonCd93bc43DecodeGithubComDipressCrmifcInternalAuth1(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *RefreshForm) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonCd93bc43DecodeGithubComDipressCrmifcInternalAuth1(l, v)
+}
+func easyjsonCd93bc43DecodeGithubComDipressCrmifcInternalAuth2(in *jlexer.Lexer, out *Form) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -121,7 +294,7 @@ func easyjsonCd93bc43DecodeGithubComDipressCrmifcInternalAuth1(in *jlexer.Lexer,
 		in.Consumed()
 	}
 }
-func easyjsonCd93bc43EncodeGithubComDipressCrmifcInternalAuth1(out *jwriter.Writer, in Form) {
+func easyjsonCd93bc43EncodeGithubComDipressCrmifcInternalAuth2(out *jwriter.Writer, in Form) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -151,23 +324,23 @@ func easyjsonCd93bc43EncodeGithubComDipressCrmifcInternalAuth1(out *jwriter.Writ
 // MarshalJSON supports json.Marshaler interface
 func (v Form) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonCd93bc43EncodeGithubComDipressCrmifcInternalAuth1(&w, v)
+	easyjsonCd93bc43EncodeGithubComDipressCrmifcInternalAuth2(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v Form) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonCd93bc43EncodeGithubComDipressCrmifcInternalAuth1(w, v)
+	easyjsonCd93bc43EncodeGithubComDipressCrmifcInternalAuth2(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *Form) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonCd93bc43DecodeGithubComDipressCrmifcInternalAuth1(&r, v)
+	easyjsonCd93bc43DecodeGithubComDipressCrmifcInternalAuth2(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *Form) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonCd93bc43DecodeGithubComDipressCrmifcInternalAuth1(l, v)
+	easyjsonCd93bc43DecodeGithubComDipressCrmifcInternalAuth2(l, v)
 }
@@ -2,10 +2,15 @@ package auth
 
 import (
 	"context"
+	"crypto/sha256"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/dgrijalva/jwt-go"
+	"github.com/dipress/crmifc/internal/kit/auth"
+	"github.com/dipress/crmifc/internal/session"
 	"github.com/dipress/crmifc/internal/user"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
@@ -23,6 +28,7 @@ func Test_Service(t *testing.T) {
 		repositoryFunc     func(ctx context.Context, email string) (*user.User, error)
 		tokenGeneratorFunc func(ctx context.Context, claims jwt.Claims) (string, error)
 		wantErr            bool
+		reason             string
 		expect             Token
 	}{
 		{
@@ -34,7 +40,8 @@ func Test_Service(t *testing.T) {
 				return "token", nil
 			},
 			expect: Token{
-				Token: "token",
+				Token:     "token",
+				ExpiresIn: 3600,
 			},
 		},
 		{
@@ -43,6 +50,7 @@ func Test_Service(t *testing.T) {
 				return &user.User{}, ErrEmailNotFound
 			},
 			wantErr: true,
+			reason:  "unknown email",
 		},
 		{
 			name: "wrong password",
@@ -50,6 +58,16 @@ func Test_Service(t *testing.T) {
 				return &user.User{}, nil
 			},
 			wantErr: true,
+			reason:  "wrong password",
+		},
+		{
+			name: "deactivated",
+			repositoryFunc: func(ctx context.Context, email string) (*user.User, error) {
+				disabledAt := time.Now()
+				return &user.User{PasswordHash: string(pw), DisabledAt: &disabledAt}, nil
+			},
+			wantErr: true,
+			reason:  "deactivated",
 		},
 		{
 			name: "token generate",
@@ -60,6 +78,7 @@ func Test_Service(t *testing.T) {
 				return "", errors.New("mock error")
 			},
 			wantErr: true,
+			reason:  "error",
 		},
 	}
 
@@ -67,7 +86,8 @@ func Test_Service(t *testing.T) {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
-			s := NewService(repositoryFunc(tt.repositoryFunc), tokenGeneratorFunc(tt.tokenGeneratorFunc), time.Hour)
+			sessions := newSessionRepository()
+			s := NewService(repositoryFunc(tt.repositoryFunc), newTokenRepository(), newAttemptRepository(), newMFARepository(), sessions, tokenGeneratorFunc(tt.tokenGeneratorFunc), time.Hour, 24*time.Hour)
 
 			ctx, cancel := context.WithCancel(context.Background())
 			defer cancel()
@@ -75,7 +95,12 @@ func Test_Service(t *testing.T) {
 			var got Token
 			email := "username@example.com"
 			password := "password123"
-			err := s.Authenticate(ctx, email, password, &got)
+			err := s.Authenticate(ctx, email, password, Client{IP: "127.0.0.1", UserAgent: "agent/1.0"}, &got)
+
+			if assert.Len(t, sessions.events, 1) {
+				e := sessions.events[0]
+				assert.Equal(t, session.NewLoginEvent{Email: email, IP: "127.0.0.1", UserAgent: "agent/1.0", Success: !tt.wantErr, Reason: tt.reason}, e)
+			}
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -83,19 +108,527 @@ func Test_Service(t *testing.T) {
 			}
 
 			assert.Nil(t, err)
+			assert.Len(t, sessions.sessions, 1)
+			assert.NotEmpty(t, got.RefreshToken)
+			got.RefreshToken = ""
 			assert.Equal(t, got, tt.expect)
 		})
 	}
 }
 
+func Test_Service_Lockout(t *testing.T) {
+	pw, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("failed to generate password: %v", err)
+	}
+
+	repo := repositoryFunc(func(ctx context.Context, email string) (*user.User, error) {
+		if email != "username@example.com" {
+			return nil, ErrEmailNotFound
+		}
+		return &user.User{ID: 1, Email: email, PasswordHash: string(pw)}, nil
+	})
+	generator := tokenGeneratorFunc(func(ctx context.Context, claims jwt.Claims) (string, error) {
+		return "token", nil
+	})
+	attempts := newAttemptRepository()
+
+	s := NewService(repo, newTokenRepository(), attempts, newMFARepository(), newSessionRepository(), generator, time.Hour, 24*time.Hour)
+	s.Lockout = Lockout{
+		AccountThreshold: 2,
+		IPThreshold:      3,
+		BaseDelay:        time.Minute,
+		MaxDelay:         time.Hour,
+		Window:           time.Hour,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var got Token
+
+	t.Log("\ttest:0\tshould lock the account after failed attempts")
+	{
+		assert.Equal(t, ErrWrongPassword, s.Authenticate(ctx, "username@example.com", "wrong", Client{IP: "10.0.0.1"}, &got))
+		assert.Equal(t, ErrWrongPassword, s.Authenticate(ctx, "username@example.com", "wrong", Client{IP: "10.0.0.2"}, &got))
+
+		err := s.Authenticate(ctx, "username@example.com", "password123", Client{IP: "10.0.0.3"}, &got)
+		locked, ok := err.(*LockedError)
+		if assert.True(t, ok, "unexpected error: %v", err) {
+			assert.True(t, locked.RetryAfter > 0 && locked.RetryAfter <= time.Minute)
+		}
+	}
+
+	t.Log("\ttest:1\tshould sign in after unlock")
+	{
+		assert.Nil(t, attempts.ResetAttempts(ctx, AccountKey("username@example.com")))
+		assert.Nil(t, s.Authenticate(ctx, "username@example.com", "password123", Client{IP: "10.0.0.3"}, &got))
+	}
+
+	t.Log("\ttest:2\tshould lock the client ip after failed attempts")
+	{
+		for _, email := range []string{"one@example.com", "two@example.com", "three@example.com"} {
+			assert.Error(t, s.Authenticate(ctx, email, "wrong", Client{IP: "10.0.0.4"}, &got))
+		}
+
+		err := s.Authenticate(ctx, "username@example.com", "password123", Client{IP: "10.0.0.4"}, &got)
+		_, ok := err.(*LockedError)
+		assert.True(t, ok, "unexpected error: %v", err)
+	}
+}
+
+func Test_Service_UpgradeHash(t *testing.T) {
+	pw, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("failed to generate password: %v", err)
+	}
+
+	repo := upgradeRepository{
+		repositoryFunc: func(ctx context.Context, email string) (*user.User, error) {
+			return &user.User{ID: 1, Email: email, PasswordHash: string(pw)}, nil
+		},
+	}
+	generator := tokenGeneratorFunc(func(ctx context.Context, claims jwt.Claims) (string, error) {
+		return "token", nil
+	})
+
+	s := NewService(&repo, newTokenRepository(), newAttemptRepository(), newMFARepository(), newSessionRepository(), generator, time.Hour, 24*time.Hour)
+	s.Cost = bcrypt.MinCost + 1
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var got Token
+
+	t.Log("\ttest:0\tshould rehash the password with the higher cost")
+	{
+		assert.Nil(t, s.Authenticate(ctx, "username@example.com", "password123", Client{IP: "127.0.0.1"}, &got))
+
+		cost, err := bcrypt.Cost([]byte(repo.hash))
+		assert.Nil(t, err)
+		assert.Equal(t, s.Cost, cost)
+		assert.Nil(t, bcrypt.CompareHashAndPassword([]byte(repo.hash), []byte("password123")))
+	}
+
+	t.Log("\ttest:1\tshould keep the hash with the same cost")
+	{
+		repo.hash = ""
+		s.Cost = bcrypt.MinCost
+
+		assert.Nil(t, s.Authenticate(ctx, "username@example.com", "password123", Client{IP: "127.0.0.1"}, &got))
+		assert.Empty(t, repo.hash)
+	}
+
+	t.Log("\ttest:2\tshould sign in when the upgrade fails")
+	{
+		repo.err = errors.New("mock error")
+		s.Cost = bcrypt.MinCost + 1
+
+		got = Token{}
+		assert.Nil(t, s.Authenticate(ctx, "username@example.com", "password123", Client{IP: "127.0.0.1"}, &got))
+		assert.Equal(t, "token", got.Token)
+		assert.Empty(t, repo.hash)
+	}
+}
+
+func Test_Service_LoginEventFailed(t *testing.T) {
+	pw, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("failed to generate password: %v", err)
+	}
+
+	repo := repositoryFunc(func(ctx context.Context, email string) (*user.User, error) {
+		return &user.User{ID: 1, Email: email, PasswordHash: string(pw)}, nil
+	})
+	generator := tokenGeneratorFunc(func(ctx context.Context, claims jwt.Claims) (string, error) {
+		return "token", nil
+	})
+	sessions := newSessionRepository()
+	sessions.eventErr = errors.New("mock error")
+
+	s := NewService(repo, newTokenRepository(), newAttemptRepository(), newMFARepository(), sessions, generator, time.Hour, 24*time.Hour)
+	s.Cost = bcrypt.MinCost
+
+	var got Token
+	assert.Nil(t, s.Authenticate(context.Background(), "username@example.com", "password123", Client{IP: "127.0.0.1"}, &got))
+	assert.Equal(t, "token", got.Token)
+
+	err = s.Authenticate(context.Background(), "username@example.com", "wrong", Client{IP: "127.0.0.1"}, &Token{})
+	assert.Equal(t, ErrWrongPassword, errors.Cause(err))
+}
+
+func TestLockoutDelay(t *testing.T) {
+	l := Lockout{
+		BaseDelay: time.Minute,
+		MaxDelay:  5 * time.Minute,
+	}
+
+	assert.Equal(t, time.Duration(0), l.Delay(2, 3))
+	assert.Equal(t, time.Minute, l.Delay(3, 3))
+	assert.Equal(t, 2*time.Minute, l.Delay(4, 3))
+	assert.Equal(t, 4*time.Minute, l.Delay(5, 3))
+	assert.Equal(t, 5*time.Minute, l.Delay(6, 3))
+	assert.Equal(t, time.Duration(0), l.Delay(10, 0))
+}
+
+func TestAccountKey(t *testing.T) {
+	assert.Equal(t, AccountKey("username@example.com"), AccountKey("UserName@Example.com"))
+	assert.NotEqual(t, AccountKey("username@example.com"), AccountKey("other@example.com"))
+
+	long := strings.Repeat("a", 1000) + "@example.com"
+	assert.Len(t, AccountKey(long), len("email:")+sha256.Size*2)
+}
+
+func Test_Service_Refresh(t *testing.T) {
+	tests := []struct {
+		name    string
+		prepare func(r *tokenRepository, sr *sessionRepository) string
+		wantErr error
+		revoked bool
+	}{
+		{
+			name: "ok",
+			prepare: func(r *tokenRepository, sr *sessionRepository) string {
+				return r.add("refresh", 1, time.Now().Add(time.Hour), false)
+			},
+		},
+		{
+			name: "unknown",
+			prepare: func(r *tokenRepository, sr *sessionRepository) string {
+				return "unknown"
+			},
+			wantErr: ErrInvalidRefreshToken,
+		},
+		{
+			name: "expired",
+			prepare: func(r *tokenRepository, sr *sessionRepository) string {
+				return r.add("refresh", 1, time.Now().Add(-time.Hour), false)
+			},
+			wantErr: ErrInvalidRefreshToken,
+		},
+		{
+			name: "reused",
+			prepare: func(r *tokenRepository, sr *sessionRepository) string {
+				r.add("another", 1, time.Now().Add(time.Hour), false)
+				return r.add("refresh", 1, time.Now().Add(time.Hour), true)
+			},
+			wantErr: ErrInvalidRefreshToken,
+			revoked: true,
+		},
+		{
+			name: "session",
+			prepare: func(r *tokenRepository, sr *sessionRepository) string {
+				id := sr.add(1)
+				_ = r.CreateRefreshToken(context.Background(), 1, id, hashToken("refresh"), time.Now().Add(time.Hour))
+				return "refresh"
+			},
+		},
+		{
+			name: "revoked session",
+			prepare: func(r *tokenRepository, sr *sessionRepository) string {
+				id := sr.add(1)
+				_ = r.CreateRefreshToken(context.Background(), 1, id, hashToken("refresh"), time.Now().Add(time.Hour))
+				_ = sr.RevokeSession(context.Background(), id)
+				return "refresh"
+			},
+			wantErr: ErrInvalidRefreshToken,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			repo := repositoryFunc(func(ctx context.Context, email string) (*user.User, error) {
+				return &user.User{ID: 1, Email: "username@example.com"}, nil
+			})
+			generator := tokenGeneratorFunc(func(ctx context.Context, claims jwt.Claims) (string, error) {
+				return "token", nil
+			})
+			tokens := newTokenRepository()
+			sessions := newSessionRepository()
+			refreshToken := tt.prepare(tokens, sessions)
+
+			s := NewService(repo, tokens, newAttemptRepository(), newMFARepository(), sessions, generator, time.Hour, 24*time.Hour)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			var got Token
+			err := s.Refresh(ctx, refreshToken, &got)
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				if tt.revoked {
+					for _, rt := range tokens.tokens {
+						assert.NotNil(t, rt.RevokedAt)
+					}
+				}
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, "token", got.Token)
+			assert.NotEqual(t, refreshToken, got.RefreshToken)
+			assert.Len(t, sessions.sessions, 1)
+
+			err = s.Refresh(ctx, refreshToken, &got)
+			assert.Equal(t, ErrInvalidRefreshToken, err)
+		})
+	}
+}
+
+func Test_Service_SignOut(t *testing.T) {
+	tokens := newTokenRepository()
+	refreshToken := tokens.add("refresh", 1, time.Now().Add(time.Hour), false)
+	foreign := tokens.add("foreign", 2, time.Now().Add(time.Hour), false)
+
+	sessions := newSessionRepository()
+
+	s := NewService(repositoryFunc(nil), tokens, newAttemptRepository(), newMFARepository(), sessions, tokenGeneratorFunc(nil), time.Hour, 24*time.Hour)
+
+	claims := auth.NewClaims(&user.User{ID: 1, Email: "username@example.com"}, time.Now(), time.Hour)
+	claims.User = user.User{ID: 1}
+	claims.SessionID = sessions.add(1)
+	ctx := auth.ToContext(context.Background(), &claims)
+
+	assert.Nil(t, s.SignOut(ctx, refreshToken))
+	assert.Nil(t, s.SignOut(ctx, foreign))
+
+	assert.Contains(t, tokens.revoked, claims.Id)
+	assert.NotNil(t, tokens.tokens[hashToken(refreshToken)].RevokedAt)
+	assert.Nil(t, tokens.tokens[hashToken(foreign)].RevokedAt)
+	assert.NotNil(t, sessions.sessions[claims.SessionID].RevokedAt)
+}
+
 type repositoryFunc func(ctx context.Context, email string) (*user.User, error)
 
 func (r repositoryFunc) FindByEmail(ctx context.Context, email string) (*user.User, error) {
 	return r(ctx, email)
 }
 
+func (r repositoryFunc) Find(ctx context.Context, id int) (*user.User, error) {
+	return r(ctx, "")
+}
+
+func (r repositoryFunc) UpgradePasswordHash(ctx context.Context, id int, oldHash, newHash string) error {
+	return nil
+}
+
+// upgradeRepository keeps the upgraded password hash.
+type upgradeRepository struct {
+	repositoryFunc
+	hash string
+	err  error
+}
+
+func (r *upgradeRepository) UpgradePasswordHash(ctx context.Context, id int, oldHash, newHash string) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.hash = newHash
+	return nil
+}
+
 type tokenGeneratorFunc func(ctx context.Context, claims jwt.Claims) (string, error)
 
 func (t tokenGeneratorFunc) GenerateToken(ctx context.Context, claims jwt.Claims) (string, error) {
 	return t(ctx, claims)
 }
+
+func (t tokenGeneratorFunc) ParseMFAClaims(ctx context.Context, tknStr string) (auth.Claims, error) {
+	return auth.Claims{}, errors.New("mfa tokens aren't supported")
+}
+
+// tokenRepository keeps tokens in memory.
+type tokenRepository struct {
+	mu      sync.Mutex
+	nextID  int
+	tokens  map[string]*RefreshToken
+	revoked map[string]time.Time
+}
+
+func newTokenRepository() *tokenRepository {
+	r := tokenRepository{
+		tokens:  make(map[string]*RefreshToken),
+		revoked: make(map[string]time.Time),
+	}
+	return &r
+}
+
+func (r *tokenRepository) add(token string, userID int, expiresAt time.Time, revoked bool) string {
+	_ = r.CreateRefreshToken(context.Background(), userID, 0, hashToken(token), expiresAt)
+	if revoked {
+		now := time.Now()
+		r.tokens[hashToken(token)].RevokedAt = &now
+	}
+	return token
+}
+
+func (r *tokenRepository) CreateRefreshToken(ctx context.Context, userID, sessionID int, hash string, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	r.tokens[hash] = &RefreshToken{ID: r.nextID, UserID: userID, SessionID: sessionID, Hash: hash, ExpiresAt: expiresAt}
+	return nil
+}
+
+func (r *tokenRepository) FindRefreshToken(ctx context.Context, hash string) (*RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rt, ok := r.tokens[hash]
+	if !ok {
+		return nil, ErrTokenNotFound
+	}
+	found := *rt
+	return &found, nil
+}
+
+func (r *tokenRepository) RevokeRefreshToken(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, rt := range r.tokens {
+		if rt.ID == id {
+			if rt.RevokedAt != nil {
+				return ErrTokenRevoked
+			}
+			now := time.Now()
+			rt.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+func (r *tokenRepository) RevokeUserRefreshTokens(ctx context.Context, userID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	for _, rt := range r.tokens {
+		if rt.UserID == userID && rt.RevokedAt == nil {
+			rt.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+func (r *tokenRepository) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.revoked[jti] = expiresAt
+	return nil
+}
+
+// sessionRepository keeps sessions and sign in history in memory.
+type sessionRepository struct {
+	mu       sync.Mutex
+	nextID   int
+	sessions map[int]*session.Session
+	events   []session.NewLoginEvent
+	eventErr error
+}
+
+func newSessionRepository() *sessionRepository {
+	r := sessionRepository{
+		sessions: make(map[int]*session.Session),
+	}
+	return &r
+}
+
+func (r *sessionRepository) add(userID int) int {
+	var sess session.Session
+	_ = r.CreateSession(context.Background(), &session.NewSession{UserID: userID, ExpiresAt: time.Now().Add(time.Hour)}, &sess)
+	return sess.ID
+}
+
+func (r *sessionRepository) CreateSession(ctx context.Context, ns *session.NewSession, sess *session.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	*sess = session.Session{ID: r.nextID, UserID: ns.UserID, IP: ns.IP, UserAgent: ns.UserAgent, ExpiresAt: ns.ExpiresAt}
+	created := *sess
+	r.sessions[sess.ID] = &created
+	return nil
+}
+
+func (r *sessionRepository) TouchSession(ctx context.Context, id int, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sess, ok := r.sessions[id]
+	if !ok || sess.RevokedAt != nil || sess.ExpiresAt.Before(time.Now()) {
+		return session.ErrNotFound
+	}
+	sess.ExpiresAt = expiresAt
+	return nil
+}
+
+func (r *sessionRepository) RevokeSession(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sess, ok := r.sessions[id]
+	if !ok || sess.RevokedAt != nil {
+		return session.ErrNotFound
+	}
+	now := time.Now()
+	sess.RevokedAt = &now
+	return nil
+}
+
+func (r *sessionRepository) CreateLoginEvent(ctx context.Context, ne *session.NewLoginEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.eventErr != nil {
+		return r.eventErr
+	}
+	r.events = append(r.events, *ne)
+	return nil
+}
+
+// attemptRepository keeps sign in attempts in memory.
+type attemptRepository struct {
+	mu       sync.Mutex
+	attempts map[string]*Attempts
+}
+
+func newAttemptRepository() *attemptRepository {
+	r := attemptRepository{
+		attempts: make(map[string]*Attempts),
+	}
+	return &r
+}
+
+func (r *attemptRepository) FindAttempts(ctx context.Context, key string) (*Attempts, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	a, ok := r.attempts[key]
+	if !ok {
+		return &Attempts{Key: key}, nil
+	}
+	found := *a
+	return &found, nil
+}
+
+func (r *attemptRepository) AddFailure(ctx context.Context, key string, window time.Duration) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	a, ok := r.attempts[key]
+	if !ok {
+		a = &Attempts{Key: key}
+		r.attempts[key] = a
+	}
+	a.Failures++
+	return a.Failures, nil
+}
+
+func (r *attemptRepository) Lock(ctx context.Context, key string, until time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.attempts[key].LockedUntil = &until
+	return nil
+}
+
+func (r *attemptRepository) ResetAttempts(ctx context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.attempts, key)
+	return nil
+}
@@ -0,0 +1,158 @@
+package apikey
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/dipress/crmifc/internal/apikey"
+	"github.com/dipress/crmifc/internal/broker/http/handler"
+	"github.com/dipress/crmifc/internal/broker/http/response"
+	"github.com/dipress/crmifc/internal/kit/auth"
+	"github.com/dipress/crmifc/internal/validation"
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+)
+
+// go:generate mockgen -source=handler.go -package=apikey -destination=handler.mock.go Service
+
+// Handler allows to handle requests.
+type Handler interface {
+	Handle(w http.ResponseWriter, r *http.Request) error
+}
+
+// Service contains all services.
+type Service interface {
+	Create(ctx context.Context, c *auth.Claims, f *apikey.Form) (*apikey.Created, error)
+	List(ctx context.Context, c *auth.Claims) (*apikey.APIKeys, error)
+	Delete(ctx context.Context, c *auth.Claims, id int) error
+}
+
+// CreateHandler for api key create requests.
+type CreateHandler struct {
+	Service
+}
+
+// Handle implements Handler interface.
+func (h *CreateHandler) Handle(w http.ResponseWriter, r *http.Request) error {
+	claims, ok := auth.FromContext(r.Context())
+	if !ok {
+		return errors.Wrap(response.UnauthorizedResponse(w), "claims from context")
+	}
+
+	var f apikey.Form
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return errors.Wrap(response.BadRequestResponse(w), "read body")
+	}
+
+	if err := f.UnmarshalJSON(data); err != nil {
+		return errors.Wrap(response.BadRequestResponse(w), "unmarshal json")
+	}
+
+	created, err := h.Create(r.Context(), claims, &f)
+	if err != nil {
+		switch v := errors.Cause(err).(type) {
+		case validation.Errors:
+			return errors.Wrap(response.UnprocessabeEntityResponse(w, v), "validation response")
+		}
+
+		switch errors.Cause(err) {
+		case apikey.ErrScopeNotGranted:
+			ves := validation.Errors{"scopes": "is not granted"}
+			return errors.Wrap(response.UnprocessabeEntityResponse(w, ves), "create api key")
+		case apikey.ErrKeyAuthenticated:
+			return errors.Wrap(response.ForbiddenResponse(w), "create api key")
+		default:
+			return errors.Wrap(response.InternalServerErrorResponse(w), "create api key")
+		}
+	}
+
+	data, err = created.MarshalJSON()
+	if err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "marshal json")
+	}
+
+	// The response holds the key.
+	w.Header().Set("Cache-Control", "no-store")
+	if _, err := w.Write(data); err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "write response")
+	}
+
+	return nil
+}
+
+// ListHandler for api key list requests.
+type ListHandler struct {
+	Service
+}
+
+// Handle implements Handler interface.
+func (h *ListHandler) Handle(w http.ResponseWriter, r *http.Request) error {
+	claims, ok := auth.FromContext(r.Context())
+	if !ok {
+		return errors.Wrap(response.UnauthorizedResponse(w), "claims from context")
+	}
+
+	keys, err := h.List(r.Context(), claims)
+	if err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "list api keys")
+	}
+
+	data, err := keys.MarshalJSON()
+	if err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "marshal json")
+	}
+
+	if _, err := w.Write(data); err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "write response")
+	}
+
+	return nil
+}
+
+// DeleteHandler for api key revoke requests.
+type DeleteHandler struct {
+	Service
+}
+
+// Handle implements Handler interface.
+func (h *DeleteHandler) Handle(w http.ResponseWriter, r *http.Request) error {
+	claims, ok := auth.FromContext(r.Context())
+	if !ok {
+		return errors.Wrap(response.UnauthorizedResponse(w), "claims from context")
+	}
+
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		return errors.Wrapf(response.BadRequestResponse(w), "convert id query param to int: %v", err)
+	}
+
+	if err := h.Delete(r.Context(), claims, id); err != nil {
+		switch errors.Cause(err) {
+		case apikey.ErrNotFound:
+			return errors.Wrap(response.NotFoundResponse(w), "delete api key")
+		case apikey.ErrKeyAuthenticated:
+			return errors.Wrap(response.ForbiddenResponse(w), "delete api key")
+		default:
+			return errors.Wrap(response.InternalServerErrorResponse(w), "delete api key")
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// Prepare prepares routes of the api keys of the current
+// user, which are available for any authorized user.
+func Prepare(subrouter *mux.Router, service Service, middleware func(handler.Handler) http.Handler) {
+	create := CreateHandler{service}
+	list := ListHandler{service}
+	delete := DeleteHandler{service}
+
+	subrouter.Handle("", middleware(&create)).Methods(http.MethodPost)
+	subrouter.Handle("", middleware(&list)).Methods(http.MethodGet)
+	subrouter.Handle("/{id}", middleware(&delete)).Methods(http.MethodDelete)
+}
@@ -0,0 +1,148 @@
+package apikey
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dipress/crmifc/internal/apikey"
+	"github.com/dipress/crmifc/internal/kit/auth"
+	"github.com/dipress/crmifc/internal/user"
+	"github.com/dipress/crmifc/internal/validation"
+	gomock "github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+)
+
+func TestCreateHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		claims      bool
+		serviceFunc func(mock *MockService)
+		code        int
+	}{
+		{
+			name:   "ok",
+			claims: true,
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Return(&apikey.Created{Key: "crm_key"}, nil)
+			},
+			code: http.StatusOK,
+		},
+		{
+			name:        "without claims",
+			serviceFunc: func(m *MockService) {},
+			code:        http.StatusUnauthorized,
+		},
+		{
+			name:   "validation error",
+			claims: true,
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, validation.Errors{"name": "cannot be blank"})
+			},
+			code: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "scope is not granted",
+			claims: true,
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, apikey.ErrScopeNotGranted)
+			},
+			code: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "authenticated with api key",
+			claims: true,
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, apikey.ErrKeyAuthenticated)
+			},
+			code: http.StatusForbidden,
+		},
+		{
+			name:   "internal error",
+			claims: true,
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("mock error"))
+			},
+			code: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := NewMockService(ctrl)
+			tc.serviceFunc(service)
+
+			h := CreateHandler{service}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "http://example.com", strings.NewReader(`{"name":"ci","scopes":["articles:view"],"expires_in":30}`))
+			if tc.claims {
+				r = r.WithContext(auth.ToContext(r.Context(), &auth.Claims{User: user.User{ID: 1}}))
+			}
+
+			err := h.Handle(w, r)
+			if w.Code != tc.code {
+				t.Errorf("unexpected code: %d expected %d error: %v", w.Code, tc.code, err)
+			}
+		})
+	}
+}
+
+func TestDeleteHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		serviceFunc func(mock *MockService)
+		code        int
+	}{
+		{
+			name: "ok",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Delete(gomock.Any(), gomock.Any(), 2).Return(nil)
+			},
+			code: http.StatusNoContent,
+		},
+		{
+			name: "not found",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Delete(gomock.Any(), gomock.Any(), 2).Return(apikey.ErrNotFound)
+			},
+			code: http.StatusNotFound,
+		},
+		{
+			name: "internal error",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Delete(gomock.Any(), gomock.Any(), 2).Return(errors.New("mock error"))
+			},
+			code: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := NewMockService(ctrl)
+			tc.serviceFunc(service)
+
+			h := DeleteHandler{service}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("DELETE", "http://example.com", nil)
+			r = mux.SetURLVars(r, map[string]string{"id": "2"})
+			r = r.WithContext(auth.ToContext(r.Context(), &auth.Claims{User: user.User{ID: 1}}))
+
+			err := h.Handle(w, r)
+			if w.Code != tc.code {
+				t.Errorf("unexpected code: %d expected %d error: %v", w.Code, tc.code, err)
+			}
+		})
+	}
+}
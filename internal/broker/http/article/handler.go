@@ -11,7 +11,12 @@ import (
 
 	"github.com/dipress/crmifc/internal/article"
 	"github.com/dipress/crmifc/internal/broker/http/handler"
+	"github.com/dipress/crmifc/internal/broker/http/request"
 	"github.com/dipress/crmifc/internal/broker/http/response"
+	"github.com/dipress/crmifc/internal/kit/diff"
+	"github.com/dipress/crmifc/internal/kit/patch"
+	"github.com/dipress/crmifc/internal/kit/query"
+	"github.com/dipress/crmifc/internal/role"
 	"github.com/dipress/crmifc/internal/validation"
 )
 
@@ -26,9 +31,18 @@ type Handler interface {
 type Service interface {
 	Create(ctx context.Context, f *article.Form) (*article.Article, error)
 	Find(ctx context.Context, id int) (*article.Article, error)
-	Update(ctx context.Context, id int, f *article.Form) (*article.Article, error)
-	Delete(ctx context.Context, id int) error
-	List(ctx context.Context) (*article.Articles, error)
+	Update(ctx context.Context, id, version int, f *article.Form) (*article.Article, error)
+	Patch(ctx context.Context, id, version int, p patch.Patch) (*article.Article, error)
+	Delete(ctx context.Context, id, version int) error
+	List(ctx context.Context, q *query.Query) (*article.Articles, error)
+	Trash(ctx context.Context, q *query.Query) (*article.Articles, error)
+	Undelete(ctx context.Context, id int) error
+	Purge(ctx context.Context, id int) error
+	Search(ctx context.Context, sq *article.SearchQuery) (*article.SearchResults, error)
+	Revisions(ctx context.Context, id int) (*article.Revisions, error)
+	FindRevision(ctx context.Context, id, version int) (*article.Revision, error)
+	Diff(ctx context.Context, id, from, to int) (*article.Diff, error)
+	Restore(ctx context.Context, id, version int) (*article.Article, error)
 }
 
 // CreateHandler for create requests.
@@ -49,21 +63,29 @@ func (h *CreateHandler) Handle(w http.ResponseWriter, r *http.Request) error {
 		return errors.Wrap(response.BadRequestResponse(w), "unmarshal json")
 	}
 
-	article, err := h.Create(r.Context(), &f)
+	art, err := h.Create(r.Context(), &f)
 	if err != nil {
 		switch v := errors.Cause(err).(type) {
 		case validation.Errors:
 			return errors.Wrap(response.UnprocessabeEntityResponse(w, v), "validation response")
+		}
+
+		switch errors.Cause(err) {
+		case article.ErrCategoryNotFound:
+			ves := validation.Errors{"category_id": "does not exist"}
+			return errors.Wrap(response.UnprocessabeEntityResponse(w, ves), "create article")
 		default:
 			return errors.Wrap(response.InternalServerErrorResponse(w), "create article")
 		}
 	}
 
-	data, err = article.MarshalJSON()
+	data, err = art.MarshalJSON()
 	if err != nil {
 		return errors.Wrap(response.InternalServerErrorResponse(w), "marshal json")
 	}
 
+	response.ETag(w, art.Version)
+
 	if _, err := w.Write(data); err != nil {
 		return errors.Wrap(response.InternalServerErrorResponse(w), "write response")
 	}
@@ -100,6 +122,8 @@ func (f FindHandler) Handle(w http.ResponseWriter, r *http.Request) error {
 		return errors.Wrap(response.InternalServerErrorResponse(w), "marshal json")
 	}
 
+	response.ETag(w, a.Version)
+
 	if _, err := w.Write(data); err != nil {
 		return errors.Wrap(response.InternalServerErrorResponse(w), "write response")
 	}
@@ -120,6 +144,11 @@ func (h UpdateHandler) Handle(w http.ResponseWriter, r *http.Request) error {
 		return errors.Wrapf(response.BadRequestResponse(w), "convert id query param to int: %v", err)
 	}
 
+	version, err := request.IfMatch(r)
+	if err != nil {
+		return errors.Wrap(response.PreconditionRequiredResponse(w), "if match")
+	}
+
 	data, err := ioutil.ReadAll(r.Body)
 	if err != nil {
 		return errors.Wrap(response.BadRequestResponse(w), "read body")
@@ -130,11 +159,25 @@ func (h UpdateHandler) Handle(w http.ResponseWriter, r *http.Request) error {
 		return errors.Wrap(response.BadRequestResponse(w), "unmarshal json")
 	}
 
-	art, err := h.Update(r.Context(), id, &f)
+	art, err := h.Update(r.Context(), id, version, &f)
 	if err != nil {
+		if errors.Cause(err) == article.ErrForbidden {
+			return errors.Wrap(response.ForbiddenResponse(w), "update")
+		}
+
 		switch v := errors.Cause(err).(type) {
 		case validation.Errors:
 			return errors.Wrap(response.UnprocessabeEntityResponse(w, v), "validation response")
+		}
+
+		switch errors.Cause(err) {
+		case article.ErrNotFound:
+			return errors.Wrap(response.NotFoundResponse(w), "update article")
+		case article.ErrCategoryNotFound:
+			ves := validation.Errors{"category_id": "does not exist"}
+			return errors.Wrap(response.UnprocessabeEntityResponse(w, ves), "update article")
+		case article.ErrVersionMismatch:
+			return errors.Wrap(preconditionFailed(r.Context(), w, h.Service, id), "update article")
 		default:
 			return errors.Wrap(response.InternalServerErrorResponse(w), "update article")
 		}
@@ -145,6 +188,69 @@ func (h UpdateHandler) Handle(w http.ResponseWriter, r *http.Request) error {
 		return errors.Wrap(response.InternalServerErrorResponse(w), "marshal json")
 	}
 
+	response.ETag(w, art.Version)
+
+	if _, err := w.Write(data); err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "write response")
+	}
+
+	return nil
+}
+
+// PatchHandler for article partial update requests.
+type PatchHandler struct {
+	Service
+}
+
+// Handle implements Handler interface.
+func (h PatchHandler) Handle(w http.ResponseWriter, r *http.Request) error {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		return errors.Wrapf(response.BadRequestResponse(w), "convert id query param to int: %v", err)
+	}
+
+	version, err := request.IfMatch(r)
+	if err != nil {
+		return errors.Wrap(response.PreconditionRequiredResponse(w), "if match")
+	}
+
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return errors.Wrap(response.BadRequestResponse(w), "read body")
+	}
+
+	art, err := h.Patch(r.Context(), id, version, patch.Patch(data))
+	if err != nil {
+		switch v := errors.Cause(err).(type) {
+		case validation.Errors:
+			return errors.Wrap(response.UnprocessabeEntityResponse(w, v), "validation response")
+		}
+
+		switch errors.Cause(err) {
+		case patch.ErrInvalid:
+			return errors.Wrap(response.BadRequestResponse(w), "apply patch")
+		case article.ErrForbidden:
+			return errors.Wrap(response.ForbiddenResponse(w), "patch article")
+		case article.ErrCategoryNotFound:
+			ves := validation.Errors{"category_id": "does not exist"}
+			return errors.Wrap(response.UnprocessabeEntityResponse(w, ves), "patch article")
+		case article.ErrNotFound:
+			return errors.Wrap(response.NotFoundResponse(w), "patch article")
+		case article.ErrVersionMismatch:
+			return errors.Wrap(preconditionFailed(r.Context(), w, h.Service, id), "patch article")
+		default:
+			return errors.Wrap(response.InternalServerErrorResponse(w), "patch article")
+		}
+	}
+
+	data, err = art.MarshalJSON()
+	if err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "marshal json")
+	}
+
+	response.ETag(w, art.Version)
+
 	if _, err := w.Write(data); err != nil {
 		return errors.Wrap(response.InternalServerErrorResponse(w), "write response")
 	}
@@ -166,8 +272,22 @@ func (h DeleteHandler) Handle(w http.ResponseWriter, r *http.Request) error {
 		return errors.Wrapf(response.BadRequestResponse(w), "convert id query param to int: %v", err)
 	}
 
-	if err := h.Delete(r.Context(), id); err != nil {
-		return errors.Wrap(response.InternalServerErrorResponse(w), "delete article")
+	version, err := request.IfMatch(r)
+	if err != nil {
+		return errors.Wrap(response.PreconditionRequiredResponse(w), "if match")
+	}
+
+	if err := h.Delete(r.Context(), id, version); err != nil {
+		switch errors.Cause(err) {
+		case article.ErrNotFound:
+			return errors.Wrap(response.NotFoundResponse(w), "delete")
+		case article.ErrForbidden:
+			return errors.Wrap(response.ForbiddenResponse(w), "delete")
+		case article.ErrVersionMismatch:
+			return errors.Wrap(preconditionFailed(r.Context(), w, h.Service, id), "delete article")
+		default:
+			return errors.Wrap(response.InternalServerErrorResponse(w), "delete article")
+		}
 	}
 
 	return nil
@@ -180,8 +300,16 @@ type ListHandler struct {
 
 // Handle implements Handler interface.
 func (h ListHandler) Handle(w http.ResponseWriter, r *http.Request) error {
-	articles, err := h.List(r.Context())
+	q, err := request.ParseQuery(r)
 	if err != nil {
+		return errors.Wrapf(response.BadRequestResponse(w), "parse query: %v", err)
+	}
+
+	articles, err := h.List(r.Context(), q)
+	if err != nil {
+		if query.IsInvalid(errors.Cause(err)) {
+			return errors.Wrapf(response.BadRequestResponse(w), "list query: %v", err)
+		}
 		return errors.Wrap(response.InternalServerErrorResponse(w), "list of articles")
 	}
 
@@ -197,17 +325,384 @@ func (h ListHandler) Handle(w http.ResponseWriter, r *http.Request) error {
 	return nil
 }
 
+// SearchHandler for article search request.
+type SearchHandler struct {
+	Service
+}
+
+// Handle implements Handler interface.
+func (h SearchHandler) Handle(w http.ResponseWriter, r *http.Request) error {
+	values := r.URL.Query()
+
+	sq := article.SearchQuery{
+		Query: values.Get("q"),
+		Limit: query.DefaultLimit,
+	}
+
+	if v := values.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			return errors.Wrapf(response.BadRequestResponse(w), "convert limit query param to int: %v", err)
+		}
+		if limit < 1 || limit > query.MaxLimit {
+			return errors.Wrap(response.BadRequestResponse(w), "limit out of range")
+		}
+		sq.Limit = limit
+	}
+
+	if v := values.Get("offset"); v != "" {
+		offset, err := strconv.Atoi(v)
+		if err != nil {
+			return errors.Wrapf(response.BadRequestResponse(w), "convert offset query param to int: %v", err)
+		}
+		if offset < 0 {
+			return errors.Wrap(response.BadRequestResponse(w), "negative offset")
+		}
+		sq.Offset = offset
+	}
+
+	for _, v := range values["category_id"] {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			return errors.Wrapf(response.BadRequestResponse(w), "convert category_id query param to int: %v", err)
+		}
+		sq.CategoryIDs = append(sq.CategoryIDs, id)
+	}
+
+	results, err := h.Search(r.Context(), &sq)
+	if err != nil {
+		switch errors.Cause(err) {
+		case article.ErrEmptySearch:
+			return errors.Wrap(response.BadRequestResponse(w), "search")
+		default:
+			return errors.Wrap(response.InternalServerErrorResponse(w), "search articles")
+		}
+	}
+
+	data, err := results.MarshalJSON()
+	if err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "marshal json")
+	}
+
+	if _, err := w.Write(data); err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "write response")
+	}
+
+	return nil
+}
+
+// RevisionsHandler for article revisions list request.
+type RevisionsHandler struct {
+	Service
+}
+
+// Handle implements Handler interface.
+func (h RevisionsHandler) Handle(w http.ResponseWriter, r *http.Request) error {
+	vars := mux.Vars(r)
+
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		return errors.Wrapf(response.BadRequestResponse(w), "convert id query param to int: %v", err)
+	}
+
+	revisions, err := h.Revisions(r.Context(), id)
+	if err != nil {
+		switch errors.Cause(err) {
+		case article.ErrNotFound:
+			return errors.Wrap(response.NotFoundResponse(w), "revisions")
+		default:
+			return errors.Wrap(response.InternalServerErrorResponse(w), "list of revisions")
+		}
+	}
+
+	data, err := revisions.MarshalJSON()
+	if err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "marshal json")
+	}
+
+	if _, err := w.Write(data); err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "write response")
+	}
+
+	return nil
+}
+
+// RevisionHandler for article revision find request.
+type RevisionHandler struct {
+	Service
+}
+
+// Handle implements Handler interface.
+func (h RevisionHandler) Handle(w http.ResponseWriter, r *http.Request) error {
+	vars := mux.Vars(r)
+
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		return errors.Wrapf(response.BadRequestResponse(w), "convert id query param to int: %v", err)
+	}
+
+	version, err := strconv.Atoi(vars["version"])
+	if err != nil {
+		return errors.Wrapf(response.BadRequestResponse(w), "convert version query param to int: %v", err)
+	}
+
+	rev, err := h.FindRevision(r.Context(), id, version)
+	if err != nil {
+		switch errors.Cause(err) {
+		case article.ErrNotFound, article.ErrRevisionNotFound:
+			return errors.Wrap(response.NotFoundResponse(w), "find revision")
+		default:
+			return errors.Wrap(response.InternalServerErrorResponse(w), "find revision")
+		}
+	}
+
+	data, err := rev.MarshalJSON()
+	if err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "marshal json")
+	}
+
+	if _, err := w.Write(data); err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "write response")
+	}
+
+	return nil
+}
+
+// DiffHandler for article revisions diff request.
+type DiffHandler struct {
+	Service
+}
+
+// Handle implements Handler interface.
+func (h DiffHandler) Handle(w http.ResponseWriter, r *http.Request) error {
+	vars := mux.Vars(r)
+
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		return errors.Wrapf(response.BadRequestResponse(w), "convert id query param to int: %v", err)
+	}
+
+	values := r.URL.Query()
+
+	from, err := strconv.Atoi(values.Get("from"))
+	if err != nil {
+		return errors.Wrapf(response.BadRequestResponse(w), "convert from query param to int: %v", err)
+	}
+
+	to, err := strconv.Atoi(values.Get("to"))
+	if err != nil {
+		return errors.Wrapf(response.BadRequestResponse(w), "convert to query param to int: %v", err)
+	}
+
+	d, err := h.Diff(r.Context(), id, from, to)
+	if err != nil {
+		switch errors.Cause(err) {
+		case article.ErrNotFound, article.ErrRevisionNotFound:
+			return errors.Wrap(response.NotFoundResponse(w), "diff")
+		case diff.ErrTooLarge:
+			ves := validation.Errors{"body": diff.ErrTooLarge.Error()}
+			return errors.Wrap(response.UnprocessabeEntityResponse(w, ves), "diff")
+		default:
+			return errors.Wrap(response.InternalServerErrorResponse(w), "diff revisions")
+		}
+	}
+
+	data, err := d.MarshalJSON()
+	if err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "marshal json")
+	}
+
+	if _, err := w.Write(data); err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "write response")
+	}
+
+	return nil
+}
+
+// RestoreHandler for article restore request.
+type RestoreHandler struct {
+	Service
+}
+
+// Handle implements Handler interface.
+func (h RestoreHandler) Handle(w http.ResponseWriter, r *http.Request) error {
+	vars := mux.Vars(r)
+
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		return errors.Wrapf(response.BadRequestResponse(w), "convert id query param to int: %v", err)
+	}
+
+	version, err := strconv.Atoi(vars["version"])
+	if err != nil {
+		return errors.Wrapf(response.BadRequestResponse(w), "convert version query param to int: %v", err)
+	}
+
+	art, err := h.Restore(r.Context(), id, version)
+	if err != nil {
+		switch errors.Cause(err) {
+		case article.ErrNotFound, article.ErrRevisionNotFound:
+			return errors.Wrap(response.NotFoundResponse(w), "restore")
+		case article.ErrForbidden:
+			return errors.Wrap(response.ForbiddenResponse(w), "restore")
+		case article.ErrCategoryNotFound:
+			return errors.Wrap(response.ConflictResponse(w, article.ErrCategoryNotFound), "restore")
+		default:
+			return errors.Wrap(response.InternalServerErrorResponse(w), "restore article")
+		}
+	}
+
+	data, err := art.MarshalJSON()
+	if err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "marshal json")
+	}
+
+	response.ETag(w, art.Version)
+
+	if _, err := w.Write(data); err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "write response")
+	}
+
+	return nil
+}
+
+// TrashHandler for deleted article list requests.
+type TrashHandler struct {
+	Service
+}
+
+// Handle implements Handler interface.
+func (h TrashHandler) Handle(w http.ResponseWriter, r *http.Request) error {
+	q, err := request.ParseQuery(r)
+	if err != nil {
+		return errors.Wrapf(response.BadRequestResponse(w), "parse query: %v", err)
+	}
+
+	articles, err := h.Trash(r.Context(), q)
+	if err != nil {
+		if query.IsInvalid(errors.Cause(err)) {
+			return errors.Wrapf(response.BadRequestResponse(w), "list query: %v", err)
+		}
+		return errors.Wrap(response.InternalServerErrorResponse(w), "list of deleted articles")
+	}
+
+	data, err := articles.MarshalJSON()
+	if err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "marshal json")
+	}
+
+	if _, err := w.Write(data); err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "write response")
+	}
+
+	return nil
+}
+
+// UndeleteHandler for deleted article restore requests.
+type UndeleteHandler struct {
+	Service
+}
+
+// Handle implements Handler interface.
+func (h UndeleteHandler) Handle(w http.ResponseWriter, r *http.Request) error {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		return errors.Wrapf(response.BadRequestResponse(w), "convert id query param to int: %v", err)
+	}
+
+	if err := h.Undelete(r.Context(), id); err != nil {
+		switch errors.Cause(err) {
+		case article.ErrNotFound:
+			return errors.Wrap(response.NotFoundResponse(w), "undelete article")
+		default:
+			return errors.Wrap(response.InternalServerErrorResponse(w), "undelete article")
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// PurgeHandler for deleted article purge requests.
+type PurgeHandler struct {
+	Service
+}
+
+// Handle implements Handler interface.
+func (h PurgeHandler) Handle(w http.ResponseWriter, r *http.Request) error {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		return errors.Wrapf(response.BadRequestResponse(w), "convert id query param to int: %v", err)
+	}
+
+	if err := h.Purge(r.Context(), id); err != nil {
+		switch errors.Cause(err) {
+		case article.ErrNotFound:
+			return errors.Wrap(response.NotFoundResponse(w), "purge article")
+		default:
+			return errors.Wrap(response.InternalServerErrorResponse(w), "purge article")
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// preconditionFailed responds with the current article
+// which the version of the request doesn't match.
+func preconditionFailed(ctx context.Context, w http.ResponseWriter, s Service, id int) error {
+	art, err := s.Find(ctx, id)
+	if err != nil {
+		switch errors.Cause(err) {
+		case article.ErrNotFound:
+			return errors.Wrap(response.NotFoundResponse(w), "find article")
+		default:
+			return errors.Wrap(response.InternalServerErrorResponse(w), "find article")
+		}
+	}
+
+	data, err := art.MarshalJSON()
+	if err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "marshal json")
+	}
+
+	return errors.Wrap(response.PreconditionFailedResponse(w, art.Version, data), "precondition failed")
+}
+
 // Prepare prepares routes to use.
-func Prepare(subrouter *mux.Router, service Service, middleware func(handler.Handler) http.Handler) {
+func Prepare(subrouter *mux.Router, service Service, middleware func(permissions ...role.Permission) func(handler.Handler) http.Handler) {
 	create := CreateHandler{service}
 	find := FindHandler{service}
 	update := UpdateHandler{service}
+	patch := PatchHandler{service}
 	delete := DeleteHandler{service}
 	list := ListHandler{service}
+	search := SearchHandler{service}
+	revisions := RevisionsHandler{service}
+	revision := RevisionHandler{service}
+	diff := DiffHandler{service}
+	restore := RestoreHandler{service}
+
+	subrouter.Handle("", middleware(role.ArticlesCreate)(&create)).Methods(http.MethodPost)
+	subrouter.Handle("/search", middleware(role.ArticlesView)(&search)).Methods(http.MethodGet)
+	subrouter.Handle("/{id}", middleware(role.ArticlesView)(&find)).Methods(http.MethodGet)
+	subrouter.Handle("/{id}", middleware(role.ArticlesUpdate, role.ArticlesUpdateOwn)(&update)).Methods(http.MethodPut)
+	subrouter.Handle("/{id}", middleware(role.ArticlesUpdate, role.ArticlesUpdateOwn)(&patch)).Methods(http.MethodPatch)
+	subrouter.Handle("/{id}", middleware(role.ArticlesDelete, role.ArticlesDeleteOwn)(&delete)).Methods(http.MethodDelete)
+	subrouter.Handle("/{id}/revisions", middleware(role.ArticlesView)(&revisions)).Methods(http.MethodGet)
+	subrouter.Handle("/{id}/revisions/{version}", middleware(role.ArticlesView)(&revision)).Methods(http.MethodGet)
+	subrouter.Handle("/{id}/revisions/{version}/restore", middleware(role.ArticlesUpdate, role.ArticlesUpdateOwn)(&restore)).Methods(http.MethodPost)
+	subrouter.Handle("/{id}/diff", middleware(role.ArticlesView)(&diff)).Methods(http.MethodGet)
+	subrouter.Handle("", middleware(role.ArticlesView)(&list)).Methods(http.MethodGet)
+}
+
+// PrepareTrash prepares routes of the deleted articles.
+func PrepareTrash(subrouter *mux.Router, service Service, middleware func(permissions ...role.Permission) func(handler.Handler) http.Handler) {
+	trash := TrashHandler{service}
+	undelete := UndeleteHandler{service}
+	purge := PurgeHandler{service}
 
-	subrouter.Handle("", middleware(&create)).Methods(http.MethodPost)
-	subrouter.Handle("/{id}", middleware(&find)).Methods(http.MethodGet)
-	subrouter.Handle("/{id}", middleware(&update)).Methods(http.MethodPut)
-	subrouter.Handle("/{id}", middleware(&delete)).Methods(http.MethodDelete)
-	subrouter.Handle("", middleware(&list)).Methods(http.MethodGet)
+	subrouter.Handle("", middleware(role.ArticlesDelete)(&trash)).Methods(http.MethodGet)
+	subrouter.Handle("/{id:[0-9]+}/restore", middleware(role.ArticlesDelete)(&undelete)).Methods(http.MethodPost)
+	subrouter.Handle("/{id:[0-9]+}", middleware(role.ArticlesDelete)(&purge)).Methods(http.MethodDelete)
 }
@@ -7,6 +7,8 @@ package article
 import (
 	context "context"
 	article "github.com/dipress/crmifc/internal/article"
+	patch "github.com/dipress/crmifc/internal/kit/patch"
+	query "github.com/dipress/crmifc/internal/kit/query"
 	gomock "github.com/golang/mock/gomock"
 	http "net/http"
 	reflect "reflect"
@@ -103,45 +105,178 @@ func (mr *MockServiceMockRecorder) Find(ctx, id interface{}) *gomock.Call {
 }
 
 // Update mocks base method
-func (m *MockService) Update(ctx context.Context, id int, f *article.Form) (*article.Article, error) {
+func (m *MockService) Update(ctx context.Context, id, version int, f *article.Form) (*article.Article, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Update", ctx, id, f)
+	ret := m.ctrl.Call(m, "Update", ctx, id, version, f)
 	ret0, _ := ret[0].(*article.Article)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Update indicates an expected call of Update
-func (mr *MockServiceMockRecorder) Update(ctx, id, f interface{}) *gomock.Call {
+func (mr *MockServiceMockRecorder) Update(ctx, id, version, f interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockService)(nil).Update), ctx, id, f)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockService)(nil).Update), ctx, id, version, f)
+}
+
+// Patch mocks base method
+func (m *MockService) Patch(ctx context.Context, id, version int, p patch.Patch) (*article.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Patch", ctx, id, version, p)
+	ret0, _ := ret[0].(*article.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Patch indicates an expected call of Patch
+func (mr *MockServiceMockRecorder) Patch(ctx, id, version, p interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Patch", reflect.TypeOf((*MockService)(nil).Patch), ctx, id, version, p)
 }
 
 // Delete mocks base method
-func (m *MockService) Delete(ctx context.Context, id int) error {
+func (m *MockService) Delete(ctx context.Context, id, version int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret := m.ctrl.Call(m, "Delete", ctx, id, version)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete
-func (mr *MockServiceMockRecorder) Delete(ctx, id interface{}) *gomock.Call {
+func (mr *MockServiceMockRecorder) Delete(ctx, id, version interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockService)(nil).Delete), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockService)(nil).Delete), ctx, id, version)
 }
 
 // List mocks base method
-func (m *MockService) List(ctx context.Context) (*article.Articles, error) {
+func (m *MockService) List(ctx context.Context, q *query.Query) (*article.Articles, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "List", ctx)
+	ret := m.ctrl.Call(m, "List", ctx, q)
 	ret0, _ := ret[0].(*article.Articles)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // List indicates an expected call of List
-func (mr *MockServiceMockRecorder) List(ctx interface{}) *gomock.Call {
+func (mr *MockServiceMockRecorder) List(ctx, q interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockService)(nil).List), ctx, q)
+}
+
+// Trash mocks base method
+func (m *MockService) Trash(ctx context.Context, q *query.Query) (*article.Articles, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Trash", ctx, q)
+	ret0, _ := ret[0].(*article.Articles)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Trash indicates an expected call of Trash
+func (mr *MockServiceMockRecorder) Trash(ctx, q interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Trash", reflect.TypeOf((*MockService)(nil).Trash), ctx, q)
+}
+
+// Undelete mocks base method
+func (m *MockService) Undelete(ctx context.Context, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Undelete", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Undelete indicates an expected call of Undelete
+func (mr *MockServiceMockRecorder) Undelete(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Undelete", reflect.TypeOf((*MockService)(nil).Undelete), ctx, id)
+}
+
+// Purge mocks base method
+func (m *MockService) Purge(ctx context.Context, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Purge indicates an expected call of Purge
+func (mr *MockServiceMockRecorder) Purge(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockService)(nil).Purge), ctx, id)
+}
+
+// Search mocks base method
+func (m *MockService) Search(ctx context.Context, sq *article.SearchQuery) (*article.SearchResults, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", ctx, sq)
+	ret0, _ := ret[0].(*article.SearchResults)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search
+func (mr *MockServiceMockRecorder) Search(ctx, sq interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockService)(nil).Search), ctx, sq)
+}
+
+// Revisions mocks base method
+func (m *MockService) Revisions(ctx context.Context, id int) (*article.Revisions, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revisions", ctx, id)
+	ret0, _ := ret[0].(*article.Revisions)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Revisions indicates an expected call of Revisions
+func (mr *MockServiceMockRecorder) Revisions(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revisions", reflect.TypeOf((*MockService)(nil).Revisions), ctx, id)
+}
+
+// FindRevision mocks base method
+func (m *MockService) FindRevision(ctx context.Context, id, version int) (*article.Revision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindRevision", ctx, id, version)
+	ret0, _ := ret[0].(*article.Revision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindRevision indicates an expected call of FindRevision
+func (mr *MockServiceMockRecorder) FindRevision(ctx, id, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindRevision", reflect.TypeOf((*MockService)(nil).FindRevision), ctx, id, version)
+}
+
+// Diff mocks base method
+func (m *MockService) Diff(ctx context.Context, id, from, to int) (*article.Diff, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Diff", ctx, id, from, to)
+	ret0, _ := ret[0].(*article.Diff)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Diff indicates an expected call of Diff
+func (mr *MockServiceMockRecorder) Diff(ctx, id, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Diff", reflect.TypeOf((*MockService)(nil).Diff), ctx, id, from, to)
+}
+
+// Restore mocks base method
+func (m *MockService) Restore(ctx context.Context, id, version int) (*article.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, id, version)
+	ret0, _ := ret[0].(*article.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Restore indicates an expected call of Restore
+func (mr *MockServiceMockRecorder) Restore(ctx, id, version interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockService)(nil).List), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockService)(nil).Restore), ctx, id, version)
 }
@@ -11,6 +11,8 @@ import (
 	"github.com/gorilla/mux"
 
 	"github.com/dipress/crmifc/internal/article"
+	"github.com/dipress/crmifc/internal/kit/diff"
+	"github.com/dipress/crmifc/internal/kit/query"
 	"github.com/dipress/crmifc/internal/validation"
 )
 
@@ -34,6 +36,13 @@ func TestCreateHandler(t *testing.T) {
 			},
 			code: http.StatusUnprocessableEntity,
 		},
+		{
+			name: "category not found",
+			serviceFunc: func(mock *MockService) {
+				mock.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil, article.ErrCategoryNotFound)
+			},
+			code: http.StatusUnprocessableEntity,
+		},
 		{
 			name: "internal error",
 			serviceFunc: func(mock *MockService) {
@@ -120,21 +129,43 @@ func TestUpdateHandler(t *testing.T) {
 		{
 			name: "ok",
 			serviceFunc: func(mock *MockService) {
-				mock.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any()).Return(&article.Article{}, nil)
+				mock.EXPECT().Update(gomock.Any(), gomock.Any(), 1, gomock.Any()).Return(&article.Article{}, nil)
 			},
 			code: http.StatusOK,
 		},
 		{
 			name: "validation error",
 			serviceFunc: func(mock *MockService) {
-				mock.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any()).Return(&article.Article{}, make(validation.Errors))
+				mock.EXPECT().Update(gomock.Any(), gomock.Any(), 1, gomock.Any()).Return(&article.Article{}, make(validation.Errors))
 			},
 			code: http.StatusUnprocessableEntity,
 		},
+		{
+			name: "forbidden",
+			serviceFunc: func(mock *MockService) {
+				mock.EXPECT().Update(gomock.Any(), gomock.Any(), 1, gomock.Any()).Return(nil, article.ErrForbidden)
+			},
+			code: http.StatusForbidden,
+		},
+		{
+			name: "not found",
+			serviceFunc: func(mock *MockService) {
+				mock.EXPECT().Update(gomock.Any(), gomock.Any(), 1, gomock.Any()).Return(nil, article.ErrNotFound)
+			},
+			code: http.StatusNotFound,
+		},
+		{
+			name: "version mismatch",
+			serviceFunc: func(mock *MockService) {
+				mock.EXPECT().Update(gomock.Any(), gomock.Any(), 1, gomock.Any()).Return(nil, article.ErrVersionMismatch)
+				mock.EXPECT().Find(gomock.Any(), 1).Return(&article.Article{Version: 2}, nil)
+			},
+			code: http.StatusPreconditionFailed,
+		},
 		{
 			name: "internal error",
 			serviceFunc: func(mock *MockService) {
-				mock.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any()).Return(&article.Article{}, errors.New("mock error"))
+				mock.EXPECT().Update(gomock.Any(), gomock.Any(), 1, gomock.Any()).Return(&article.Article{}, errors.New("mock error"))
 			},
 			code: http.StatusInternalServerError,
 		},
@@ -154,6 +185,7 @@ func TestUpdateHandler(t *testing.T) {
 			w := httptest.NewRecorder()
 			r := httptest.NewRequest(http.MethodPut, "http://example.com", strings.NewReader("{}"))
 			r = mux.SetURLVars(r, map[string]string{"id": "1"})
+			r.Header.Set("If-Match", `"1"`)
 
 			err := h.Handle(w, r)
 			if w.Code != tc.code {
@@ -172,17 +204,32 @@ func TestDeleteHandler(t *testing.T) {
 		{
 			name: "ok",
 			serviceFunc: func(mock *MockService) {
-				mock.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(nil)
+				mock.EXPECT().Delete(gomock.Any(), gomock.Any(), 1).Return(nil)
 			},
 			code: http.StatusOK,
 		},
+		{
+			name: "forbidden",
+			serviceFunc: func(mock *MockService) {
+				mock.EXPECT().Delete(gomock.Any(), gomock.Any(), 1).Return(article.ErrForbidden)
+			},
+			code: http.StatusForbidden,
+		},
 		{
 			name: "repository error",
 			serviceFunc: func(mock *MockService) {
-				mock.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(errors.New("mock error"))
+				mock.EXPECT().Delete(gomock.Any(), gomock.Any(), 1).Return(errors.New("mock error"))
 			},
 			code: http.StatusInternalServerError,
 		},
+		{
+			name: "version mismatch",
+			serviceFunc: func(mock *MockService) {
+				mock.EXPECT().Delete(gomock.Any(), gomock.Any(), 1).Return(article.ErrVersionMismatch)
+				mock.EXPECT().Find(gomock.Any(), 1).Return(&article.Article{Version: 2}, nil)
+			},
+			code: http.StatusPreconditionFailed,
+		},
 	}
 
 	for _, tc := range tests {
@@ -199,6 +246,7 @@ func TestDeleteHandler(t *testing.T) {
 			w := httptest.NewRecorder()
 			r := httptest.NewRequest(http.MethodDelete, "http://example.com", strings.NewReader("{}"))
 			r = mux.SetURLVars(r, map[string]string{"id": "1"})
+			r.Header.Set("If-Match", `"1"`)
 
 			err := h.Handle(w, r)
 			if w.Code != tc.code {
@@ -211,23 +259,45 @@ func TestDeleteHandler(t *testing.T) {
 func TestListHandler(t *testing.T) {
 	tests := []struct {
 		name        string
+		query       string
 		serviceFunc func(mock *MockService)
 		code        int
 	}{
 		{
 			name: "ok",
 			serviceFunc: func(m *MockService) {
-				m.EXPECT().List(gomock.Any()).Return(&article.Articles{}, nil)
+				m.EXPECT().List(gomock.Any(), gomock.Any()).Return(&article.Articles{}, nil)
 			},
 			code: http.StatusOK,
 		},
 		{
 			name: "repository error",
 			serviceFunc: func(m *MockService) {
-				m.EXPECT().List(gomock.Any()).Return(&article.Articles{}, errors.New("mock error"))
+				m.EXPECT().List(gomock.Any(), gomock.Any()).Return(&article.Articles{}, errors.New("mock error"))
 			},
 			code: http.StatusInternalServerError,
 		},
+		{
+			name:        "invalid query",
+			query:       "?limit=-1",
+			serviceFunc: func(m *MockService) {},
+			code:        http.StatusBadRequest,
+		},
+		{
+			name: "invalid sort",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().List(gomock.Any(), gomock.Any()).Return(&article.Articles{}, query.ErrInvalidSort)
+			},
+			code: http.StatusBadRequest,
+		},
+		{
+			name:  "not numeric filter",
+			query: "?filter[category_id]=abc",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().List(gomock.Any(), gomock.Any()).Return(nil, query.ErrInvalidFilter)
+			},
+			code: http.StatusBadRequest,
+		},
 	}
 
 	for _, tc := range tests {
@@ -242,7 +312,130 @@ func TestListHandler(t *testing.T) {
 
 			h := ListHandler{service}
 			w := httptest.NewRecorder()
-			r := httptest.NewRequest(http.MethodGet, "http://example.com", strings.NewReader("{}"))
+			r := httptest.NewRequest(http.MethodGet, "http://example.com"+tc.query, strings.NewReader("{}"))
+
+			err := h.Handle(w, r)
+			if w.Code != tc.code {
+				t.Errorf("unexpected code: %d expected %d error: %v", w.Code, tc.code, err)
+			}
+		})
+	}
+}
+
+func TestSearchHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		serviceFunc func(mock *MockService)
+		code        int
+	}{
+		{
+			name:  "ok",
+			query: "?q=vpn&category_id=1&category_id=2&limit=5&offset=5",
+			serviceFunc: func(mock *MockService) {
+				mock.EXPECT().Search(gomock.Any(), &article.SearchQuery{
+					Query:       "vpn",
+					CategoryIDs: []int{1, 2},
+					Limit:       5,
+					Offset:      5,
+				}).Return(&article.SearchResults{}, nil)
+			},
+			code: http.StatusOK,
+		},
+		{
+			name:        "wrong category",
+			query:       "?q=vpn&category_id=one",
+			serviceFunc: func(mock *MockService) {},
+			code:        http.StatusBadRequest,
+		},
+		{
+			name:        "wrong limit",
+			query:       "?q=vpn&limit=0",
+			serviceFunc: func(mock *MockService) {},
+			code:        http.StatusBadRequest,
+		},
+		{
+			name: "blank query",
+			serviceFunc: func(mock *MockService) {
+				mock.EXPECT().Search(gomock.Any(), gomock.Any()).Return(nil, article.ErrEmptySearch)
+			},
+			code: http.StatusBadRequest,
+		},
+		{
+			name:  "internal error",
+			query: "?q=vpn",
+			serviceFunc: func(mock *MockService) {
+				mock.EXPECT().Search(gomock.Any(), gomock.Any()).Return(nil, errors.New("mock error"))
+			},
+			code: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := NewMockService(ctrl)
+			tc.serviceFunc(service)
+
+			h := SearchHandler{service}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "http://example.com/articles/search"+tc.query, nil)
+
+			err := h.Handle(w, r)
+			if w.Code != tc.code {
+				t.Errorf("unexpected code: %d expected %d error: %v", w.Code, tc.code, err)
+			}
+		})
+	}
+}
+
+func TestRevisionsHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		serviceFunc func(m *MockService)
+		code        int
+	}{
+		{
+			name: "ok",
+			serviceFunc: func(mock *MockService) {
+				mock.EXPECT().Revisions(gomock.Any(), gomock.Any()).Return(&article.Revisions{}, nil)
+			},
+			code: http.StatusOK,
+		},
+		{
+			name: "not found",
+			serviceFunc: func(mock *MockService) {
+				mock.EXPECT().Revisions(gomock.Any(), gomock.Any()).Return(nil, article.ErrNotFound)
+			},
+			code: http.StatusNotFound,
+		},
+		{
+			name: "internal error",
+			serviceFunc: func(mock *MockService) {
+				mock.EXPECT().Revisions(gomock.Any(), gomock.Any()).Return(nil, errors.New("mock error"))
+			},
+			code: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := NewMockService(ctrl)
+			tc.serviceFunc(service)
+
+			h := RevisionsHandler{service}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+			r = mux.SetURLVars(r, map[string]string{"id": "1"})
 
 			err := h.Handle(w, r)
 			if w.Code != tc.code {
@@ -251,3 +444,268 @@ func TestListHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestRevisionHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		version     string
+		serviceFunc func(m *MockService)
+		code        int
+	}{
+		{
+			name:    "ok",
+			version: "1",
+			serviceFunc: func(mock *MockService) {
+				mock.EXPECT().FindRevision(gomock.Any(), 1, 1).Return(&article.Revision{}, nil)
+			},
+			code: http.StatusOK,
+		},
+		{
+			name:        "invalid version",
+			version:     "first",
+			serviceFunc: func(mock *MockService) {},
+			code:        http.StatusBadRequest,
+		},
+		{
+			name:    "not found",
+			version: "2",
+			serviceFunc: func(mock *MockService) {
+				mock.EXPECT().FindRevision(gomock.Any(), 1, 2).Return(nil, article.ErrRevisionNotFound)
+			},
+			code: http.StatusNotFound,
+		},
+		{
+			name:    "trashed article",
+			version: "1",
+			serviceFunc: func(mock *MockService) {
+				mock.EXPECT().FindRevision(gomock.Any(), 1, 1).Return(nil, article.ErrNotFound)
+			},
+			code: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := NewMockService(ctrl)
+			tc.serviceFunc(service)
+
+			h := RevisionHandler{service}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+			r = mux.SetURLVars(r, map[string]string{"id": "1", "version": tc.version})
+
+			err := h.Handle(w, r)
+			if w.Code != tc.code {
+				t.Errorf("unexpected code: %d expected %d error: %v", w.Code, tc.code, err)
+			}
+		})
+	}
+}
+
+func TestDiffHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		serviceFunc func(m *MockService)
+		code        int
+	}{
+		{
+			name:  "ok",
+			query: "?from=1&to=2",
+			serviceFunc: func(mock *MockService) {
+				mock.EXPECT().Diff(gomock.Any(), 1, 1, 2).Return(&article.Diff{}, nil)
+			},
+			code: http.StatusOK,
+		},
+		{
+			name:        "missing to",
+			query:       "?from=1",
+			serviceFunc: func(mock *MockService) {},
+			code:        http.StatusBadRequest,
+		},
+		{
+			name:  "not found",
+			query: "?from=1&to=5",
+			serviceFunc: func(mock *MockService) {
+				mock.EXPECT().Diff(gomock.Any(), 1, 1, 5).Return(nil, article.ErrRevisionNotFound)
+			},
+			code: http.StatusNotFound,
+		},
+		{
+			name:  "trashed article",
+			query: "?from=1&to=2",
+			serviceFunc: func(mock *MockService) {
+				mock.EXPECT().Diff(gomock.Any(), 1, 1, 2).Return(nil, article.ErrNotFound)
+			},
+			code: http.StatusNotFound,
+		},
+		{
+			name:  "too large",
+			query: "?from=1&to=2",
+			serviceFunc: func(mock *MockService) {
+				mock.EXPECT().Diff(gomock.Any(), 1, 1, 2).Return(nil, diff.ErrTooLarge)
+			},
+			code: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := NewMockService(ctrl)
+			tc.serviceFunc(service)
+
+			h := DiffHandler{service}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "http://example.com"+tc.query, nil)
+			r = mux.SetURLVars(r, map[string]string{"id": "1"})
+
+			err := h.Handle(w, r)
+			if w.Code != tc.code {
+				t.Errorf("unexpected code: %d expected %d error: %v", w.Code, tc.code, err)
+			}
+		})
+	}
+}
+
+func TestRestoreHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		serviceFunc func(m *MockService)
+		code        int
+	}{
+		{
+			name: "ok",
+			serviceFunc: func(mock *MockService) {
+				mock.EXPECT().Restore(gomock.Any(), 1, 1).Return(&article.Article{}, nil)
+			},
+			code: http.StatusOK,
+		},
+		{
+			name: "not found",
+			serviceFunc: func(mock *MockService) {
+				mock.EXPECT().Restore(gomock.Any(), 1, 1).Return(nil, article.ErrRevisionNotFound)
+			},
+			code: http.StatusNotFound,
+		},
+		{
+			name: "internal error",
+			serviceFunc: func(mock *MockService) {
+				mock.EXPECT().Restore(gomock.Any(), 1, 1).Return(nil, errors.New("mock error"))
+			},
+			code: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := NewMockService(ctrl)
+			tc.serviceFunc(service)
+
+			h := RestoreHandler{service}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "http://example.com", nil)
+			r = mux.SetURLVars(r, map[string]string{"id": "1", "version": "1"})
+
+			err := h.Handle(w, r)
+			if w.Code != tc.code {
+				t.Errorf("unexpected code: %d expected %d error: %v", w.Code, tc.code, err)
+			}
+		})
+	}
+}
+
+func TestPurgeHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		serviceFunc func(mock *MockService)
+		code        int
+	}{
+		{
+			name: "ok",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Purge(gomock.Any(), 1).Return(nil)
+			},
+			code: http.StatusNoContent,
+		},
+		{
+			name: "not found",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Purge(gomock.Any(), 1).Return(article.ErrNotFound)
+			},
+			code: http.StatusNotFound,
+		},
+		{
+			name: "internal error",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Purge(gomock.Any(), 1).Return(errors.New("mock error"))
+			},
+			code: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := NewMockService(ctrl)
+			tc.serviceFunc(service)
+
+			h := PurgeHandler{service}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodDelete, "http://example.com", nil)
+			r = mux.SetURLVars(r, map[string]string{"id": "1"})
+
+			err := h.Handle(w, r)
+			if w.Code != tc.code {
+				t.Errorf("unexpected code: %d expected %d error: %v", w.Code, tc.code, err)
+			}
+		})
+	}
+}
+
+func TestIfMatchRequired(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler Handler
+		method  string
+	}{
+		{name: "update", handler: &UpdateHandler{}, method: http.MethodPut},
+		{name: "patch", handler: &PatchHandler{}, method: http.MethodPatch},
+		{name: "delete", handler: &DeleteHandler{}, method: http.MethodDelete},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(tc.method, "http://example.com", strings.NewReader("{}"))
+			r = mux.SetURLVars(r, map[string]string{"id": "1"})
+
+			err := tc.handler.Handle(w, r)
+			if w.Code != http.StatusPreconditionRequired {
+				t.Errorf("unexpected code: %d expected %d error: %v", w.Code, http.StatusPreconditionRequired, err)
+			}
+		})
+	}
+}
@@ -4,9 +4,15 @@ import (
 	"context"
 	"io/ioutil"
 	"net/http"
+	"strconv"
 
 	"github.com/dipress/crmifc/internal/auth"
+	"github.com/dipress/crmifc/internal/broker/http/request"
 	"github.com/dipress/crmifc/internal/broker/http/response"
+	authEng "github.com/dipress/crmifc/internal/kit/auth"
+	"github.com/dipress/crmifc/internal/user"
+	"github.com/dipress/crmifc/internal/validation"
+	"github.com/gorilla/mux"
 	"github.com/pkg/errors"
 )
 
@@ -15,9 +21,17 @@ type Handler interface {
 	Handle(w http.ResponseWriter, r *http.Request) error
 }
 
+// client describes the client of the request.
+func client(r *http.Request) auth.Client {
+	return auth.Client{
+		IP:        request.ClientIP(r),
+		UserAgent: r.UserAgent(),
+	}
+}
+
 // Authenticater abstraction for authenticate service.
 type Authenticater interface {
-	Authenticate(ctx context.Context, email, password string, t *auth.Token) error
+	Authenticate(ctx context.Context, email, password string, c auth.Client, t *auth.Token) error
 }
 
 // AuthenticaterHandler for authenticate request.
@@ -39,10 +53,16 @@ func (a AuthenticaterHandler) Handle(w http.ResponseWriter, r *http.Request) err
 		return errors.Wrap(response.BadRequestResponse(w), "unmarshal json")
 	}
 
-	if err := a.Authenticater.Authenticate(r.Context(), f.Email, f.Password, &t); err != nil {
+	if err := a.Authenticater.Authenticate(r.Context(), f.Email, f.Password, client(r), &t); err != nil {
+		if locked, ok := errors.Cause(err).(*auth.LockedError); ok {
+			return errors.Wrap(response.TooManyRequestsResponse(w, locked.RetryAfter), "locked")
+		}
+
 		switch err := errors.Cause(err); err {
 		case auth.ErrEmailNotFound, auth.ErrWrongPassword:
 			return errors.Wrap(response.UnauthorizedResponse(w), "find user")
+		case auth.ErrDeactivated:
+			return errors.Wrap(response.ForbiddenResponse(w), "user deactivated")
 		default:
 			return errors.Wrap(response.InternalServerErrorResponse(w), "authenticate user")
 		}
@@ -59,3 +79,461 @@ func (a AuthenticaterHandler) Handle(w http.ResponseWriter, r *http.Request) err
 
 	return nil
 }
+
+// Refresher abstraction for refresh token service.
+type Refresher interface {
+	Refresh(ctx context.Context, refreshToken string, t *auth.Token) error
+}
+
+// RefreshHandler for refresh token request.
+type RefreshHandler struct {
+	Refresher
+}
+
+// Handle implements Handler interface.
+func (h RefreshHandler) Handle(w http.ResponseWriter, r *http.Request) error {
+	var f auth.RefreshForm
+	var t auth.Token
+
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return errors.Wrap(response.BadRequestResponse(w), "read body")
+	}
+
+	if err := f.UnmarshalJSON(data); err != nil {
+		return errors.Wrap(response.BadRequestResponse(w), "unmarshal json")
+	}
+
+	if err := h.Refresher.Refresh(r.Context(), f.RefreshToken, &t); err != nil {
+		switch errors.Cause(err) {
+		case auth.ErrInvalidRefreshToken:
+			return errors.Wrap(response.UnauthorizedResponse(w), "refresh token")
+		case auth.ErrDeactivated:
+			return errors.Wrap(response.ForbiddenResponse(w), "refresh token")
+		default:
+			return errors.Wrap(response.InternalServerErrorResponse(w), "refresh token")
+		}
+	}
+
+	data, err = t.MarshalJSON()
+	if err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "marshal json")
+	}
+
+	if _, err := w.Write(data); err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "write response")
+	}
+
+	return nil
+}
+
+// SignOuter abstraction for sign out service.
+type SignOuter interface {
+	SignOut(ctx context.Context, refreshToken string) error
+}
+
+// SignOutHandler for sign out request.
+type SignOutHandler struct {
+	SignOuter
+}
+
+// Handle implements Handler interface. The body with
+// the refresh token is optional.
+func (h SignOutHandler) Handle(w http.ResponseWriter, r *http.Request) error {
+	var f auth.RefreshForm
+
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return errors.Wrap(response.BadRequestResponse(w), "read body")
+	}
+
+	if len(data) > 0 {
+		if err := f.UnmarshalJSON(data); err != nil {
+			return errors.Wrap(response.BadRequestResponse(w), "unmarshal json")
+		}
+	}
+
+	if err := h.SignOuter.SignOut(r.Context(), f.RefreshToken); err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "sign out")
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// KeyPublisher abstraction for public keys of the authenticator.
+type KeyPublisher interface {
+	JWKS() authEng.JWKS
+}
+
+// JWKSHandler for public keys request.
+type JWKSHandler struct {
+	KeyPublisher
+}
+
+// Handle implements Handler interface.
+func (h JWKSHandler) Handle(w http.ResponseWriter, r *http.Request) error {
+	set := h.KeyPublisher.JWKS()
+
+	data, err := set.MarshalJSON()
+	if err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "marshal json")
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	if _, err := w.Write(data); err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "write response")
+	}
+
+	return nil
+}
+
+// Unlocker abstraction for unlock account service.
+type Unlocker interface {
+	Unlock(ctx context.Context, userID int) error
+}
+
+// UnlockHandler for unlock account request.
+type UnlockHandler struct {
+	Unlocker
+}
+
+// Handle implements Handler interface.
+func (h UnlockHandler) Handle(w http.ResponseWriter, r *http.Request) error {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		return errors.Wrapf(response.BadRequestResponse(w), "convert id query param to int: %v", err)
+	}
+
+	if err := h.Unlocker.Unlock(r.Context(), id); err != nil {
+		switch errors.Cause(err) {
+		case user.ErrNotFound:
+			return errors.Wrap(response.NotFoundResponse(w), "find user")
+		default:
+			return errors.Wrap(response.InternalServerErrorResponse(w), "unlock")
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// MFAVerifier abstraction for the second step of the sign in.
+type MFAVerifier interface {
+	VerifyMFA(ctx context.Context, f *auth.MFAForm, c auth.Client, t *auth.Token) error
+}
+
+// MFAHandler for the second step of the sign in request.
+type MFAHandler struct {
+	MFAVerifier
+}
+
+// Handle implements Handler interface.
+func (h MFAHandler) Handle(w http.ResponseWriter, r *http.Request) error {
+	var f auth.MFAForm
+	var t auth.Token
+
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return errors.Wrap(response.BadRequestResponse(w), "read body")
+	}
+
+	if err := f.UnmarshalJSON(data); err != nil {
+		return errors.Wrap(response.BadRequestResponse(w), "unmarshal json")
+	}
+
+	if err := h.MFAVerifier.VerifyMFA(r.Context(), &f, client(r), &t); err != nil {
+		if locked, ok := errors.Cause(err).(*auth.LockedError); ok {
+			return errors.Wrap(response.TooManyRequestsResponse(w, locked.RetryAfter), "locked")
+		}
+
+		switch errors.Cause(err) {
+		case auth.ErrInvalidMFAToken, auth.ErrInvalidCode:
+			return errors.Wrap(response.UnauthorizedResponse(w), "verify mfa")
+		case auth.ErrDeactivated:
+			return errors.Wrap(response.ForbiddenResponse(w), "verify mfa")
+		case auth.ErrMFANotFound:
+			ves := validation.Errors{"mfa": "is not enrolled"}
+			return errors.Wrap(response.UnprocessabeEntityResponse(w, ves), "verify mfa")
+		default:
+			return errors.Wrap(response.InternalServerErrorResponse(w), "verify mfa")
+		}
+	}
+
+	data, err = t.MarshalJSON()
+	if err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "marshal json")
+	}
+
+	if _, err := w.Write(data); err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "write response")
+	}
+
+	return nil
+}
+
+// PendingEnroller abstraction for mfa enrollment on the sign in.
+type PendingEnroller interface {
+	EnrollPendingMFA(ctx context.Context, mfaToken string) (*auth.Enrollment, error)
+}
+
+// PendingEnrollHandler for mfa enrollment request with the mfa pending token.
+type PendingEnrollHandler struct {
+	PendingEnroller
+}
+
+// Handle implements Handler interface.
+func (h PendingEnrollHandler) Handle(w http.ResponseWriter, r *http.Request) error {
+	var f auth.MFAForm
+
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return errors.Wrap(response.BadRequestResponse(w), "read body")
+	}
+
+	if err := f.UnmarshalJSON(data); err != nil {
+		return errors.Wrap(response.BadRequestResponse(w), "unmarshal json")
+	}
+
+	e, err := h.PendingEnroller.EnrollPendingMFA(r.Context(), f.MFAToken)
+	if err != nil {
+		return errors.Wrap(enrollErrorResponse(w, err), "enroll mfa")
+	}
+
+	return errors.Wrap(writeEnrollment(w, e), "write enrollment")
+}
+
+// Enroller abstraction for mfa enrollment of the signed in user.
+type Enroller interface {
+	EnrollMFA(ctx context.Context) (*auth.Enrollment, error)
+}
+
+// EnrollHandler for mfa enrollment request.
+type EnrollHandler struct {
+	Enroller
+}
+
+// Handle implements Handler interface.
+func (h EnrollHandler) Handle(w http.ResponseWriter, r *http.Request) error {
+	e, err := h.Enroller.EnrollMFA(r.Context())
+	if err != nil {
+		return errors.Wrap(enrollErrorResponse(w, err), "enroll mfa")
+	}
+
+	return errors.Wrap(writeEnrollment(w, e), "write enrollment")
+}
+
+func enrollErrorResponse(w http.ResponseWriter, err error) error {
+	switch errors.Cause(err) {
+	case auth.ErrInvalidMFAToken:
+		return response.UnauthorizedResponse(w)
+	case auth.ErrMFAEnabled:
+		return response.UnprocessabeEntityResponse(w, validation.Errors{"mfa": "is enabled already"})
+	default:
+		return response.InternalServerErrorResponse(w)
+	}
+}
+
+func writeEnrollment(w http.ResponseWriter, e *auth.Enrollment) error {
+	data, err := e.MarshalJSON()
+	if err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "marshal json")
+	}
+
+	// The response holds the secret.
+	w.Header().Set("Cache-Control", "no-store")
+	if _, err := w.Write(data); err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "write response")
+	}
+
+	return nil
+}
+
+// Confirmer abstraction for mfa confirmation of the signed in user.
+type Confirmer interface {
+	ConfirmMFA(ctx context.Context, code string, c auth.Client) (*auth.RecoveryCodes, error)
+}
+
+// ConfirmHandler for mfa confirmation request.
+type ConfirmHandler struct {
+	Confirmer
+}
+
+// Handle implements Handler interface.
+func (h ConfirmHandler) Handle(w http.ResponseWriter, r *http.Request) error {
+	var f auth.CodeForm
+
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return errors.Wrap(response.BadRequestResponse(w), "read body")
+	}
+
+	if err := f.UnmarshalJSON(data); err != nil {
+		return errors.Wrap(response.BadRequestResponse(w), "unmarshal json")
+	}
+
+	codes, err := h.Confirmer.ConfirmMFA(r.Context(), f.Code, client(r))
+	if err != nil {
+		return errors.Wrap(codeErrorResponse(w, err), "confirm mfa")
+	}
+
+	data, err = codes.MarshalJSON()
+	if err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "marshal json")
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	if _, err := w.Write(data); err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "write response")
+	}
+
+	return nil
+}
+
+// MFADisabler abstraction for turning mfa of the signed in user off.
+type MFADisabler interface {
+	DisableMFA(ctx context.Context, code string, c auth.Client) error
+}
+
+// DisableMFAHandler for turning mfa off request.
+type DisableMFAHandler struct {
+	MFADisabler
+}
+
+// Handle implements Handler interface.
+func (h DisableMFAHandler) Handle(w http.ResponseWriter, r *http.Request) error {
+	var f auth.CodeForm
+
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return errors.Wrap(response.BadRequestResponse(w), "read body")
+	}
+
+	if err := f.UnmarshalJSON(data); err != nil {
+		return errors.Wrap(response.BadRequestResponse(w), "unmarshal json")
+	}
+
+	if err := h.MFADisabler.DisableMFA(r.Context(), f.Code, client(r)); err != nil {
+		return errors.Wrap(codeErrorResponse(w, err), "disable mfa")
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func codeErrorResponse(w http.ResponseWriter, err error) error {
+	if locked, ok := errors.Cause(err).(*auth.LockedError); ok {
+		return response.TooManyRequestsResponse(w, locked.RetryAfter)
+	}
+
+	switch errors.Cause(err) {
+	case auth.ErrInvalidCode:
+		return response.UnprocessabeEntityResponse(w, validation.Errors{"code": "is invalid"})
+	case auth.ErrMFAEnabled:
+		return response.UnprocessabeEntityResponse(w, validation.Errors{"mfa": "is enabled already"})
+	case auth.ErrMFANotFound:
+		return response.UnprocessabeEntityResponse(w, validation.Errors{"mfa": "is not enrolled"})
+	default:
+		return response.InternalServerErrorResponse(w)
+	}
+}
+
+// oidcStateCookie binds the single sign-on callback to
+// the browser which started the sign in.
+const oidcStateCookie = "oidc_state"
+
+// OIDCLoginer abstraction for single sign-on start.
+type OIDCLoginer interface {
+	OIDCLogin(ctx context.Context) (*auth.OIDCLogin, error)
+}
+
+// OIDCLoginHandler for single sign-on start request.
+type OIDCLoginHandler struct {
+	OIDCLoginer
+}
+
+// Handle implements Handler interface.
+func (h OIDCLoginHandler) Handle(w http.ResponseWriter, r *http.Request) error {
+	l, err := h.OIDCLoginer.OIDCLogin(r.Context())
+	if err != nil {
+		switch errors.Cause(err) {
+		case auth.ErrOIDCDisabled:
+			return errors.Wrap(response.NotFoundResponse(w), "oidc login")
+		default:
+			return errors.Wrap(response.InternalServerErrorResponse(w), "oidc login")
+		}
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    l.State,
+		Path:     "/auth/oidc",
+		MaxAge:   int(auth.DefaultOIDCStateExpireAfter.Seconds()),
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	http.Redirect(w, r, l.URL, http.StatusFound)
+	return nil
+}
+
+// OIDCCallbacker abstraction for single sign-on finish.
+type OIDCCallbacker interface {
+	OIDCCallback(ctx context.Context, state, code string, c auth.Client, t *auth.Token) error
+}
+
+// OIDCCallbackHandler for single sign-on callback request.
+type OIDCCallbackHandler struct {
+	OIDCCallbacker
+}
+
+// Handle implements Handler interface.
+func (h OIDCCallbackHandler) Handle(w http.ResponseWriter, r *http.Request) error {
+	q := r.URL.Query()
+	state := q.Get("state")
+
+	// The provider reports the refused sign in by the error.
+	if e := q.Get("error"); e != "" {
+		return errors.Wrapf(response.UnauthorizedResponse(w), "oidc provider: %s", e)
+	}
+
+	cookie, err := r.Cookie(oidcStateCookie)
+	if err != nil || state == "" || cookie.Value != state {
+		return errors.Wrap(response.UnauthorizedResponse(w), "oidc state cookie")
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Path:     "/auth/oidc",
+		MaxAge:   -1,
+		HttpOnly: true,
+	})
+
+	var t auth.Token
+	if err := h.OIDCCallbacker.OIDCCallback(r.Context(), state, q.Get("code"), client(r), &t); err != nil {
+		switch errors.Cause(err) {
+		case auth.ErrOIDCDisabled:
+			return errors.Wrap(response.NotFoundResponse(w), "oidc callback")
+		case auth.ErrInvalidOIDCState, auth.ErrInvalidIDToken:
+			return errors.Wrap(response.UnauthorizedResponse(w), "oidc callback")
+		case auth.ErrOIDCUserNotFound, auth.ErrDeactivated:
+			return errors.Wrap(response.ForbiddenResponse(w), "oidc callback")
+		default:
+			return errors.Wrap(response.InternalServerErrorResponse(w), "oidc callback")
+		}
+	}
+
+	data, err := t.MarshalJSON()
+	if err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "marshal json")
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	if _, err := w.Write(data); err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "write response")
+	}
+
+	return nil
+}
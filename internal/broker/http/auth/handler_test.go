@@ -3,44 +3,73 @@ package auth
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/dipress/crmifc/internal/auth"
+	authEng "github.com/dipress/crmifc/internal/kit/auth"
+	"github.com/dipress/crmifc/internal/user"
+	"github.com/gorilla/mux"
 )
 
 func TestAuthHandler(t *testing.T) {
 	tests := []struct {
 		name     string
-		authFunc func(ctx context.Context, email, password string, t *auth.Token) error
+		authFunc func(ctx context.Context, email, password string, c auth.Client, t *auth.Token) error
 		code     int
 	}{
 		{
 			name: "ok",
-			authFunc: func(ctx context.Context, email, password string, t *auth.Token) error {
+			authFunc: func(ctx context.Context, email, password string, c auth.Client, t *auth.Token) error {
+				return nil
+			},
+			code: http.StatusOK,
+		},
+		{
+			name: "client",
+			authFunc: func(ctx context.Context, email, password string, c auth.Client, t *auth.Token) error {
+				if c.IP != "192.0.2.1" || c.UserAgent != "agent/1.0" {
+					return fmt.Errorf("unexpected client: %+v", c)
+				}
 				return nil
 			},
 			code: http.StatusOK,
 		},
 		{
 			name: "email error",
-			authFunc: func(ctx context.Context, email, password string, t *auth.Token) error {
+			authFunc: func(ctx context.Context, email, password string, c auth.Client, t *auth.Token) error {
 				return auth.ErrEmailNotFound
 			},
 			code: http.StatusUnauthorized,
 		},
 		{
 			name: "password error",
-			authFunc: func(ctx context.Context, email, password string, t *auth.Token) error {
+			authFunc: func(ctx context.Context, email, password string, c auth.Client, t *auth.Token) error {
 				return auth.ErrWrongPassword
 			},
 			code: http.StatusUnauthorized,
 		},
+		{
+			name: "deactivated",
+			authFunc: func(ctx context.Context, email, password string, c auth.Client, t *auth.Token) error {
+				return auth.ErrDeactivated
+			},
+			code: http.StatusForbidden,
+		},
+		{
+			name: "locked",
+			authFunc: func(ctx context.Context, email, password string, c auth.Client, t *auth.Token) error {
+				return &auth.LockedError{RetryAfter: time.Minute}
+			},
+			code: http.StatusTooManyRequests,
+		},
 		{
 			name: "internal error",
-			authFunc: func(ctx context.Context, email, password string, t *auth.Token) error {
+			authFunc: func(ctx context.Context, email, password string, c auth.Client, t *auth.Token) error {
 				return errors.New("mock error")
 			},
 			code: http.StatusInternalServerError,
@@ -54,6 +83,173 @@ func TestAuthHandler(t *testing.T) {
 			h := AuthenticaterHandler{authFunc(tc.authFunc)}
 			w := httptest.NewRecorder()
 			r := httptest.NewRequest("POST", "http://example.com", strings.NewReader("{}"))
+			r.Header.Set("User-Agent", "agent/1.0")
+
+			err := h.Handle(w, r)
+			if w.Code != tc.code {
+				t.Errorf("unexpected code: %d expected %d error: %v", w.Code, tc.code, err)
+			}
+
+			if tc.code == http.StatusTooManyRequests && w.Header().Get("Retry-After") != "60" {
+				t.Errorf("unexpected retry after: %q", w.Header().Get("Retry-After"))
+			}
+		})
+	}
+}
+
+type authFunc func(ctx context.Context, email, password string, c auth.Client, t *auth.Token) error
+
+func (a authFunc) Authenticate(ctx context.Context, email, password string, c auth.Client, t *auth.Token) error {
+	return a(ctx, email, password, c, t)
+}
+
+func TestUnlockHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		unlockFunc func(ctx context.Context, userID int) error
+		id         string
+		code       int
+	}{
+		{
+			name: "ok",
+			unlockFunc: func(ctx context.Context, userID int) error {
+				return nil
+			},
+			id:   "1",
+			code: http.StatusNoContent,
+		},
+		{
+			name: "not found",
+			unlockFunc: func(ctx context.Context, userID int) error {
+				return user.ErrNotFound
+			},
+			id:   "1",
+			code: http.StatusNotFound,
+		},
+		{
+			name: "bad id",
+			id:   "one",
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "internal error",
+			unlockFunc: func(ctx context.Context, userID int) error {
+				return errors.New("mock error")
+			},
+			id:   "1",
+			code: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			h := UnlockHandler{unlockFunc(tc.unlockFunc)}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "http://example.com", nil)
+			r = mux.SetURLVars(r, map[string]string{"id": tc.id})
+
+			err := h.Handle(w, r)
+			if w.Code != tc.code {
+				t.Errorf("unexpected code: %d expected %d error: %v", w.Code, tc.code, err)
+			}
+		})
+	}
+}
+
+func TestRefreshHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		refreshFunc func(ctx context.Context, refreshToken string, t *auth.Token) error
+		body        string
+		code        int
+	}{
+		{
+			name: "ok",
+			refreshFunc: func(ctx context.Context, refreshToken string, t *auth.Token) error {
+				return nil
+			},
+			body: `{"refresh_token":"token"}`,
+			code: http.StatusOK,
+		},
+		{
+			name: "invalid token",
+			refreshFunc: func(ctx context.Context, refreshToken string, t *auth.Token) error {
+				return auth.ErrInvalidRefreshToken
+			},
+			body: `{"refresh_token":"token"}`,
+			code: http.StatusUnauthorized,
+		},
+		{
+			name: "bad request",
+			body: `{`,
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "internal error",
+			refreshFunc: func(ctx context.Context, refreshToken string, t *auth.Token) error {
+				return errors.New("mock error")
+			},
+			body: `{"refresh_token":"token"}`,
+			code: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			h := RefreshHandler{refreshFunc(tc.refreshFunc)}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "http://example.com", strings.NewReader(tc.body))
+
+			err := h.Handle(w, r)
+			if w.Code != tc.code {
+				t.Errorf("unexpected code: %d expected %d error: %v", w.Code, tc.code, err)
+			}
+		})
+	}
+}
+
+func TestSignOutHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		signOutFunc func(ctx context.Context, refreshToken string) error
+		body        string
+		code        int
+	}{
+		{
+			name: "ok",
+			signOutFunc: func(ctx context.Context, refreshToken string) error {
+				return nil
+			},
+			body: `{"refresh_token":"token"}`,
+			code: http.StatusNoContent,
+		},
+		{
+			name: "without body",
+			signOutFunc: func(ctx context.Context, refreshToken string) error {
+				return nil
+			},
+			code: http.StatusNoContent,
+		},
+		{
+			name: "internal error",
+			signOutFunc: func(ctx context.Context, refreshToken string) error {
+				return errors.New("mock error")
+			},
+			code: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			h := SignOutHandler{signOutFunc(tc.signOutFunc)}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "http://example.com", strings.NewReader(tc.body))
 
 			err := h.Handle(w, r)
 			if w.Code != tc.code {
@@ -63,8 +259,332 @@ func TestAuthHandler(t *testing.T) {
 	}
 }
 
-type authFunc func(ctx context.Context, email, password string, t *auth.Token) error
+func TestJWKSHandler(t *testing.T) {
+	h := JWKSHandler{jwksFunc(func() authEng.JWKS {
+		return authEng.JWKS{Keys: []authEng.JWK{{Kty: "RSA", Kid: "12345"}}}
+	})}
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "http://example.com", nil)
+
+	if err := h.Handle(w, r); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if w.Code != http.StatusOK {
+		t.Errorf("unexpected code: %d expected %d", w.Code, http.StatusOK)
+	}
+
+	if !strings.Contains(w.Body.String(), `"kid":"12345"`) {
+		t.Errorf("unexpected body: %s", w.Body.String())
+	}
+}
+
+type unlockFunc func(ctx context.Context, userID int) error
+
+func (f unlockFunc) Unlock(ctx context.Context, userID int) error {
+	return f(ctx, userID)
+}
+
+type refreshFunc func(ctx context.Context, refreshToken string, t *auth.Token) error
+
+func (f refreshFunc) Refresh(ctx context.Context, refreshToken string, t *auth.Token) error {
+	return f(ctx, refreshToken, t)
+}
+
+type signOutFunc func(ctx context.Context, refreshToken string) error
+
+func (f signOutFunc) SignOut(ctx context.Context, refreshToken string) error {
+	return f(ctx, refreshToken)
+}
+
+type jwksFunc func() authEng.JWKS
+
+func (f jwksFunc) JWKS() authEng.JWKS {
+	return f()
+}
+
+func TestMFAHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		verifyFunc func(ctx context.Context, f *auth.MFAForm, c auth.Client, t *auth.Token) error
+		code       int
+	}{
+		{
+			name: "ok",
+			verifyFunc: func(ctx context.Context, f *auth.MFAForm, c auth.Client, t *auth.Token) error {
+				return nil
+			},
+			code: http.StatusOK,
+		},
+		{
+			name: "invalid code",
+			verifyFunc: func(ctx context.Context, f *auth.MFAForm, c auth.Client, t *auth.Token) error {
+				return auth.ErrInvalidCode
+			},
+			code: http.StatusUnauthorized,
+		},
+		{
+			name: "invalid token",
+			verifyFunc: func(ctx context.Context, f *auth.MFAForm, c auth.Client, t *auth.Token) error {
+				return auth.ErrInvalidMFAToken
+			},
+			code: http.StatusUnauthorized,
+		},
+		{
+			name: "not enrolled",
+			verifyFunc: func(ctx context.Context, f *auth.MFAForm, c auth.Client, t *auth.Token) error {
+				return auth.ErrMFANotFound
+			},
+			code: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "locked",
+			verifyFunc: func(ctx context.Context, f *auth.MFAForm, c auth.Client, t *auth.Token) error {
+				return &auth.LockedError{RetryAfter: time.Minute}
+			},
+			code: http.StatusTooManyRequests,
+		},
+		{
+			name: "internal error",
+			verifyFunc: func(ctx context.Context, f *auth.MFAForm, c auth.Client, t *auth.Token) error {
+				return errors.New("mock error")
+			},
+			code: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			h := MFAHandler{verifyFunc(tc.verifyFunc)}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "http://example.com", strings.NewReader(`{"mfa_token":"token","code":"123456"}`))
+
+			err := h.Handle(w, r)
+			if w.Code != tc.code {
+				t.Errorf("unexpected code: %d expected %d error: %v", w.Code, tc.code, err)
+			}
+		})
+	}
+}
+
+func TestConfirmHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		confirmFunc func(ctx context.Context, code string, c auth.Client) (*auth.RecoveryCodes, error)
+		code        int
+	}{
+		{
+			name: "ok",
+			confirmFunc: func(ctx context.Context, code string, c auth.Client) (*auth.RecoveryCodes, error) {
+				return &auth.RecoveryCodes{Codes: []string{"abcd-efgh"}}, nil
+			},
+			code: http.StatusOK,
+		},
+		{
+			name: "invalid code",
+			confirmFunc: func(ctx context.Context, code string, c auth.Client) (*auth.RecoveryCodes, error) {
+				return nil, auth.ErrInvalidCode
+			},
+			code: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "locked",
+			confirmFunc: func(ctx context.Context, code string, c auth.Client) (*auth.RecoveryCodes, error) {
+				return nil, &auth.LockedError{RetryAfter: time.Minute}
+			},
+			code: http.StatusTooManyRequests,
+		},
+		{
+			name: "enabled already",
+			confirmFunc: func(ctx context.Context, code string, c auth.Client) (*auth.RecoveryCodes, error) {
+				return nil, auth.ErrMFAEnabled
+			},
+			code: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "internal error",
+			confirmFunc: func(ctx context.Context, code string, c auth.Client) (*auth.RecoveryCodes, error) {
+				return nil, errors.New("mock error")
+			},
+			code: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			h := ConfirmHandler{confirmFunc(tc.confirmFunc)}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "http://example.com", strings.NewReader(`{"code":"123456"}`))
+
+			err := h.Handle(w, r)
+			if w.Code != tc.code {
+				t.Errorf("unexpected code: %d expected %d error: %v", w.Code, tc.code, err)
+			}
+		})
+	}
+}
+
+type verifyFunc func(ctx context.Context, f *auth.MFAForm, c auth.Client, t *auth.Token) error
+
+func (v verifyFunc) VerifyMFA(ctx context.Context, f *auth.MFAForm, c auth.Client, t *auth.Token) error {
+	return v(ctx, f, c, t)
+}
+
+type confirmFunc func(ctx context.Context, code string, c auth.Client) (*auth.RecoveryCodes, error)
+
+func (f confirmFunc) ConfirmMFA(ctx context.Context, code string, c auth.Client) (*auth.RecoveryCodes, error) {
+	return f(ctx, code, c)
+}
+
+func TestOIDCLoginHandler(t *testing.T) {
+	tests := []struct {
+		name      string
+		loginFunc func(ctx context.Context) (*auth.OIDCLogin, error)
+		code      int
+	}{
+		{
+			name: "ok",
+			loginFunc: func(ctx context.Context) (*auth.OIDCLogin, error) {
+				return &auth.OIDCLogin{URL: "http://idp.example.com/authorize", State: "state"}, nil
+			},
+			code: http.StatusFound,
+		},
+		{
+			name: "disabled",
+			loginFunc: func(ctx context.Context) (*auth.OIDCLogin, error) {
+				return nil, auth.ErrOIDCDisabled
+			},
+			code: http.StatusNotFound,
+		},
+		{
+			name: "internal error",
+			loginFunc: func(ctx context.Context) (*auth.OIDCLogin, error) {
+				return nil, errors.New("mock error")
+			},
+			code: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			h := OIDCLoginHandler{oidcLoginFunc(tc.loginFunc)}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "http://example.com/auth/oidc/login", nil)
+
+			err := h.Handle(w, r)
+			if w.Code != tc.code {
+				t.Errorf("unexpected code: %d expected %d error: %v", w.Code, tc.code, err)
+			}
+
+			if tc.code == http.StatusFound {
+				if l := w.Header().Get("Location"); l != "http://idp.example.com/authorize" {
+					t.Errorf("unexpected location: %s", l)
+				}
+				if c := w.Header().Get("Set-Cookie"); !strings.HasPrefix(c, "oidc_state=state;") {
+					t.Errorf("unexpected cookie: %s", c)
+				}
+			}
+		})
+	}
+}
+
+func TestOIDCCallbackHandler(t *testing.T) {
+	tests := []struct {
+		name         string
+		query        string
+		cookie       string
+		callbackFunc func(ctx context.Context, state, code string, c auth.Client, t *auth.Token) error
+		code         int
+	}{
+		{
+			name:   "ok",
+			query:  "?state=state&code=code",
+			cookie: "state",
+			callbackFunc: func(ctx context.Context, state, code string, c auth.Client, t *auth.Token) error {
+				t.Token = "token"
+				return nil
+			},
+			code: http.StatusOK,
+		},
+		{
+			name:   "provider error",
+			query:  "?state=state&error=access_denied",
+			cookie: "state",
+			code:   http.StatusUnauthorized,
+		},
+		{
+			name:  "without cookie",
+			query: "?state=state&code=code",
+			code:  http.StatusUnauthorized,
+		},
+		{
+			name:   "cookie mismatch",
+			query:  "?state=state&code=code",
+			cookie: "other",
+			code:   http.StatusUnauthorized,
+		},
+		{
+			name:   "invalid state",
+			query:  "?state=state&code=code",
+			cookie: "state",
+			callbackFunc: func(ctx context.Context, state, code string, c auth.Client, t *auth.Token) error {
+				return auth.ErrInvalidOIDCState
+			},
+			code: http.StatusUnauthorized,
+		},
+		{
+			name:   "user not found",
+			query:  "?state=state&code=code",
+			cookie: "state",
+			callbackFunc: func(ctx context.Context, state, code string, c auth.Client, t *auth.Token) error {
+				return auth.ErrOIDCUserNotFound
+			},
+			code: http.StatusForbidden,
+		},
+		{
+			name:   "internal error",
+			query:  "?state=state&code=code",
+			cookie: "state",
+			callbackFunc: func(ctx context.Context, state, code string, c auth.Client, t *auth.Token) error {
+				return errors.New("mock error")
+			},
+			code: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			h := OIDCCallbackHandler{oidcCallbackFunc(tc.callbackFunc)}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "http://example.com/auth/oidc/callback"+tc.query, nil)
+			if tc.cookie != "" {
+				r.AddCookie(&http.Cookie{Name: "oidc_state", Value: tc.cookie})
+			}
+
+			err := h.Handle(w, r)
+			if w.Code != tc.code {
+				t.Errorf("unexpected code: %d expected %d error: %v", w.Code, tc.code, err)
+			}
+		})
+	}
+}
+
+type oidcLoginFunc func(ctx context.Context) (*auth.OIDCLogin, error)
+
+func (f oidcLoginFunc) OIDCLogin(ctx context.Context) (*auth.OIDCLogin, error) {
+	return f(ctx)
+}
+
+type oidcCallbackFunc func(ctx context.Context, state, code string, c auth.Client, t *auth.Token) error
 
-func (a authFunc) Authenticate(ctx context.Context, email, password string, t *auth.Token) error {
-	return a(ctx, email, password, t)
+func (f oidcCallbackFunc) OIDCCallback(ctx context.Context, state, code string, c auth.Client, t *auth.Token) error {
+	return f(ctx, state, code, c, t)
 }
@@ -7,8 +7,12 @@ import (
 	"strconv"
 
 	"github.com/dipress/crmifc/internal/broker/http/handler"
+	"github.com/dipress/crmifc/internal/broker/http/request"
 	"github.com/dipress/crmifc/internal/broker/http/response"
 	"github.com/dipress/crmifc/internal/category"
+	"github.com/dipress/crmifc/internal/kit/patch"
+	"github.com/dipress/crmifc/internal/kit/query"
+	"github.com/dipress/crmifc/internal/role"
 	"github.com/dipress/crmifc/internal/validation"
 	"github.com/gorilla/mux"
 	"github.com/pkg/errors"
@@ -25,9 +29,13 @@ type Handler interface {
 type Service interface {
 	Create(ctx context.Context, f *category.Form) (*category.Category, error)
 	Find(ctx context.Context, id int) (*category.Category, error)
-	Update(ctx context.Context, id int, f *category.Form) (*category.Category, error)
-	Delete(ctx context.Context, id int) error
-	List(ctx context.Context) (*category.Categories, error)
+	Update(ctx context.Context, id, version int, f *category.Form) (*category.Category, error)
+	Patch(ctx context.Context, id, version int, p patch.Patch) (*category.Category, error)
+	Delete(ctx context.Context, id, version int) error
+	List(ctx context.Context, q *query.Query) (*category.Categories, error)
+	Trash(ctx context.Context, q *query.Query) (*category.Categories, error)
+	Undelete(ctx context.Context, id int) error
+	Purge(ctx context.Context, id int) error
 }
 
 // CreateHandler for create requests.
@@ -48,21 +56,29 @@ func (h *CreateHandler) Handle(w http.ResponseWriter, r *http.Request) error {
 		return errors.Wrap(response.BadRequestResponse(w), "unmarshal json")
 	}
 
-	category, err := h.Create(r.Context(), &f)
+	cat, err := h.Create(r.Context(), &f)
 	if err != nil {
 		switch v := errors.Cause(err).(type) {
 		case validation.Errors:
 			return errors.Wrap(response.UnprocessabeEntityResponse(w, v), "validation response")
+		}
+
+		switch errors.Cause(err) {
+		case category.ErrNameExists:
+			ves := validation.Errors{"name": "already exists"}
+			return errors.Wrap(response.UnprocessabeEntityResponse(w, ves), "create category")
 		default:
 			return errors.Wrap(response.InternalServerErrorResponse(w), "create category")
 		}
 	}
 
-	data, err = category.MarshalJSON()
+	data, err = cat.MarshalJSON()
 	if err != nil {
 		return errors.Wrap(response.InternalServerErrorResponse(w), "marshal json")
 	}
 
+	response.ETag(w, cat.Version)
+
 	if _, err := w.Write(data); err != nil {
 		return errors.Wrap(response.InternalServerErrorResponse(w), "write response")
 	}
@@ -99,6 +115,8 @@ func (h *FindHandler) Handle(w http.ResponseWriter, r *http.Request) error {
 		return errors.Wrap(response.InternalServerErrorResponse(w), "marshal json")
 	}
 
+	response.ETag(w, cat.Version)
+
 	if _, err := w.Write(data); err != nil {
 		return errors.Wrap(response.InternalServerErrorResponse(w), "write response")
 	}
@@ -121,6 +139,11 @@ func (h *UpdateHandler) Handle(w http.ResponseWriter, r *http.Request) error {
 		return errors.Wrapf(response.BadRequestResponse(w), "convert id query param to int: %v", err)
 	}
 
+	version, err := request.IfMatch(r)
+	if err != nil {
+		return errors.Wrap(response.PreconditionRequiredResponse(w), "if match")
+	}
+
 	data, err := ioutil.ReadAll(r.Body)
 	if err != nil {
 		return errors.Wrap(response.BadRequestResponse(w), "read body")
@@ -130,11 +153,21 @@ func (h *UpdateHandler) Handle(w http.ResponseWriter, r *http.Request) error {
 		return errors.Wrap(response.BadRequestResponse(w), "unmarshal json")
 	}
 
-	cat, err := h.Update(r.Context(), id, &f)
+	cat, err := h.Update(r.Context(), id, version, &f)
 	if err != nil {
 		switch v := errors.Cause(err).(type) {
 		case validation.Errors:
 			return errors.Wrap(response.UnprocessabeEntityResponse(w, v), "validation response")
+		}
+
+		switch errors.Cause(err) {
+		case category.ErrNameExists:
+			ves := validation.Errors{"name": "already exists"}
+			return errors.Wrap(response.UnprocessabeEntityResponse(w, ves), "update category")
+		case category.ErrNotFound:
+			return errors.Wrap(response.NotFoundResponse(w), "update category")
+		case category.ErrVersionMismatch:
+			return errors.Wrap(preconditionFailed(r.Context(), w, h.Service, id), "update category")
 		default:
 			return errors.Wrap(response.InternalServerErrorResponse(w), "update category")
 		}
@@ -145,9 +178,70 @@ func (h *UpdateHandler) Handle(w http.ResponseWriter, r *http.Request) error {
 		return errors.Wrap(response.InternalServerErrorResponse(w), "marshal json")
 	}
 
+	response.ETag(w, cat.Version)
+
+	if _, err := w.Write(data); err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "write response")
+	}
+	return nil
+}
+
+// PatchHandler for category partial update requests.
+type PatchHandler struct {
+	Service
+}
+
+// Handle implements Handler interface.
+func (h PatchHandler) Handle(w http.ResponseWriter, r *http.Request) error {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		return errors.Wrapf(response.BadRequestResponse(w), "convert id query param to int: %v", err)
+	}
+
+	version, err := request.IfMatch(r)
+	if err != nil {
+		return errors.Wrap(response.PreconditionRequiredResponse(w), "if match")
+	}
+
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return errors.Wrap(response.BadRequestResponse(w), "read body")
+	}
+
+	cat, err := h.Patch(r.Context(), id, version, patch.Patch(data))
+	if err != nil {
+		switch v := errors.Cause(err).(type) {
+		case validation.Errors:
+			return errors.Wrap(response.UnprocessabeEntityResponse(w, v), "validation response")
+		}
+
+		switch errors.Cause(err) {
+		case patch.ErrInvalid:
+			return errors.Wrap(response.BadRequestResponse(w), "apply patch")
+		case category.ErrNameExists:
+			ves := validation.Errors{"name": "already exists"}
+			return errors.Wrap(response.UnprocessabeEntityResponse(w, ves), "patch category")
+		case category.ErrNotFound:
+			return errors.Wrap(response.NotFoundResponse(w), "patch category")
+		case category.ErrVersionMismatch:
+			return errors.Wrap(preconditionFailed(r.Context(), w, h.Service, id), "patch category")
+		default:
+			return errors.Wrap(response.InternalServerErrorResponse(w), "patch category")
+		}
+	}
+
+	data, err = cat.MarshalJSON()
+	if err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "marshal json")
+	}
+
+	response.ETag(w, cat.Version)
+
 	if _, err := w.Write(data); err != nil {
 		return errors.Wrap(response.InternalServerErrorResponse(w), "write response")
 	}
+
 	return nil
 }
 
@@ -165,8 +259,20 @@ func (h *DeleteHandler) Handle(w http.ResponseWriter, r *http.Request) error {
 		return errors.Wrapf(response.BadRequestResponse(w), "convert id query param to int: %v", err)
 	}
 
-	if err := h.Delete(r.Context(), id); err != nil {
-		return errors.Wrap(response.InternalServerErrorResponse(w), "delete category")
+	version, err := request.IfMatch(r)
+	if err != nil {
+		return errors.Wrap(response.PreconditionRequiredResponse(w), "if match")
+	}
+
+	if err := h.Delete(r.Context(), id, version); err != nil {
+		switch errors.Cause(err) {
+		case category.ErrNotFound:
+			return errors.Wrap(response.NotFoundResponse(w), "delete category")
+		case category.ErrVersionMismatch:
+			return errors.Wrap(preconditionFailed(r.Context(), w, h.Service, id), "delete category")
+		default:
+			return errors.Wrap(response.InternalServerErrorResponse(w), "delete category")
+		}
 	}
 
 	return nil
@@ -179,8 +285,16 @@ type ListHandler struct {
 
 // Handle implements Handler interface.
 func (h *ListHandler) Handle(w http.ResponseWriter, r *http.Request) error {
-	categories, err := h.List(r.Context())
+	q, err := request.ParseQuery(r)
 	if err != nil {
+		return errors.Wrapf(response.BadRequestResponse(w), "parse query: %v", err)
+	}
+
+	categories, err := h.List(r.Context(), q)
+	if err != nil {
+		if query.IsInvalid(errors.Cause(err)) {
+			return errors.Wrapf(response.BadRequestResponse(w), "list query: %v", err)
+		}
 		return errors.Wrap(response.InternalServerErrorResponse(w), "list of categories")
 	}
 
@@ -196,17 +310,135 @@ func (h *ListHandler) Handle(w http.ResponseWriter, r *http.Request) error {
 	return nil
 }
 
+// TrashHandler for deleted category list requests.
+type TrashHandler struct {
+	Service
+}
+
+// Handle implements Handler interface.
+func (h TrashHandler) Handle(w http.ResponseWriter, r *http.Request) error {
+	q, err := request.ParseQuery(r)
+	if err != nil {
+		return errors.Wrapf(response.BadRequestResponse(w), "parse query: %v", err)
+	}
+
+	categories, err := h.Trash(r.Context(), q)
+	if err != nil {
+		if query.IsInvalid(errors.Cause(err)) {
+			return errors.Wrapf(response.BadRequestResponse(w), "list query: %v", err)
+		}
+		return errors.Wrap(response.InternalServerErrorResponse(w), "list of deleted categories")
+	}
+
+	data, err := categories.MarshalJSON()
+	if err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "marshal json")
+	}
+
+	if _, err := w.Write(data); err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "write response")
+	}
+
+	return nil
+}
+
+// UndeleteHandler for deleted category restore requests.
+type UndeleteHandler struct {
+	Service
+}
+
+// Handle implements Handler interface.
+func (h UndeleteHandler) Handle(w http.ResponseWriter, r *http.Request) error {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		return errors.Wrapf(response.BadRequestResponse(w), "convert id query param to int: %v", err)
+	}
+
+	if err := h.Undelete(r.Context(), id); err != nil {
+		switch errors.Cause(err) {
+		case category.ErrNotFound:
+			return errors.Wrap(response.NotFoundResponse(w), "undelete category")
+		default:
+			return errors.Wrap(response.InternalServerErrorResponse(w), "undelete category")
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// PurgeHandler for deleted category purge requests.
+type PurgeHandler struct {
+	Service
+}
+
+// Handle implements Handler interface.
+func (h PurgeHandler) Handle(w http.ResponseWriter, r *http.Request) error {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		return errors.Wrapf(response.BadRequestResponse(w), "convert id query param to int: %v", err)
+	}
+
+	if err := h.Purge(r.Context(), id); err != nil {
+		switch errors.Cause(err) {
+		case category.ErrNotFound:
+			return errors.Wrap(response.NotFoundResponse(w), "purge category")
+		case category.ErrInUse:
+			return errors.Wrap(response.ConflictResponse(w, category.ErrInUse), "purge category")
+		default:
+			return errors.Wrap(response.InternalServerErrorResponse(w), "purge category")
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// preconditionFailed responds with the current category
+// which the version of the request doesn't match.
+func preconditionFailed(ctx context.Context, w http.ResponseWriter, s Service, id int) error {
+	cat, err := s.Find(ctx, id)
+	if err != nil {
+		switch errors.Cause(err) {
+		case category.ErrNotFound:
+			return errors.Wrap(response.NotFoundResponse(w), "find category")
+		default:
+			return errors.Wrap(response.InternalServerErrorResponse(w), "find category")
+		}
+	}
+
+	data, err := cat.MarshalJSON()
+	if err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "marshal json")
+	}
+
+	return errors.Wrap(response.PreconditionFailedResponse(w, cat.Version, data), "precondition failed")
+}
+
 // Prepare prepares routes to use.
-func Prepare(subrouter *mux.Router, service Service, middleware func(handler.Handler) http.Handler) {
+func Prepare(subrouter *mux.Router, service Service, middleware func(permissions ...role.Permission) func(handler.Handler) http.Handler) {
 	create := CreateHandler{service}
 	find := FindHandler{service}
 	update := UpdateHandler{service}
+	patch := PatchHandler{service}
 	delete := DeleteHandler{service}
 	list := ListHandler{service}
 
-	subrouter.Handle("", middleware(&create)).Methods(http.MethodPost)
-	subrouter.Handle("/{id}", middleware(&find)).Methods(http.MethodGet)
-	subrouter.Handle("/{id}", middleware(&update)).Methods(http.MethodPut)
-	subrouter.Handle("/{id}", middleware(&delete)).Methods(http.MethodDelete)
-	subrouter.Handle("", middleware(&list)).Methods(http.MethodGet)
+	subrouter.Handle("", middleware(role.CategoriesCreate)(&create)).Methods(http.MethodPost)
+	subrouter.Handle("/{id}", middleware(role.CategoriesView)(&find)).Methods(http.MethodGet)
+	subrouter.Handle("/{id}", middleware(role.CategoriesUpdate)(&update)).Methods(http.MethodPut)
+	subrouter.Handle("/{id}", middleware(role.CategoriesUpdate)(&patch)).Methods(http.MethodPatch)
+	subrouter.Handle("/{id}", middleware(role.CategoriesDelete)(&delete)).Methods(http.MethodDelete)
+	subrouter.Handle("", middleware(role.CategoriesView)(&list)).Methods(http.MethodGet)
+}
+
+// PrepareTrash prepares routes of the deleted categories.
+func PrepareTrash(subrouter *mux.Router, service Service, middleware func(permissions ...role.Permission) func(handler.Handler) http.Handler) {
+	trash := TrashHandler{service}
+	undelete := UndeleteHandler{service}
+	purge := PurgeHandler{service}
+
+	subrouter.Handle("", middleware(role.CategoriesDelete)(&trash)).Methods(http.MethodGet)
+	subrouter.Handle("/{id:[0-9]+}/restore", middleware(role.CategoriesDelete)(&undelete)).Methods(http.MethodPost)
+	subrouter.Handle("/{id:[0-9]+}", middleware(role.CategoriesDelete)(&purge)).Methods(http.MethodDelete)
 }
@@ -8,6 +8,8 @@ import (
 	"testing"
 
 	"github.com/dipress/crmifc/internal/category"
+	"github.com/dipress/crmifc/internal/kit/patch"
+	"github.com/dipress/crmifc/internal/kit/query"
 	"github.com/dipress/crmifc/internal/validation"
 	gomock "github.com/golang/mock/gomock"
 	"github.com/gorilla/mux"
@@ -33,6 +35,13 @@ func TestCreateHandler(t *testing.T) {
 			},
 			code: http.StatusUnprocessableEntity,
 		},
+		{
+			name: "name exists",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil, category.ErrNameExists)
+			},
+			code: http.StatusUnprocessableEntity,
+		},
 		{
 			name: "internl error",
 			serviceFunc: func(m *MockService) {
@@ -121,21 +130,29 @@ func TestUpdateHandler(t *testing.T) {
 		{
 			name: "ok",
 			serviceFunc: func(m *MockService) {
-				m.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any()).Return(&category.Category{}, nil)
+				m.EXPECT().Update(gomock.Any(), gomock.Any(), 1, gomock.Any()).Return(&category.Category{}, nil)
 			},
 			code: http.StatusOK,
 		},
 		{
 			name: "validation error",
 			serviceFunc: func(m *MockService) {
-				m.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any()).Return(&category.Category{}, make(validation.Errors))
+				m.EXPECT().Update(gomock.Any(), gomock.Any(), 1, gomock.Any()).Return(&category.Category{}, make(validation.Errors))
 			},
 			code: http.StatusUnprocessableEntity,
 		},
+		{
+			name: "version mismatch",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Update(gomock.Any(), gomock.Any(), 1, gomock.Any()).Return(nil, category.ErrVersionMismatch)
+				m.EXPECT().Find(gomock.Any(), 1).Return(&category.Category{Version: 2}, nil)
+			},
+			code: http.StatusPreconditionFailed,
+		},
 		{
 			name: "internal error",
 			serviceFunc: func(m *MockService) {
-				m.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any()).Return(&category.Category{}, errors.New("mock error"))
+				m.EXPECT().Update(gomock.Any(), gomock.Any(), 1, gomock.Any()).Return(&category.Category{}, errors.New("mock error"))
 			},
 			code: http.StatusInternalServerError,
 		},
@@ -155,6 +172,74 @@ func TestUpdateHandler(t *testing.T) {
 			w := httptest.NewRecorder()
 			r := httptest.NewRequest(http.MethodPut, "http://example.com", strings.NewReader("{}"))
 			r = mux.SetURLVars(r, map[string]string{"id": "1"})
+			r.Header.Set("If-Match", `"1"`)
+
+			err := h.Handle(w, r)
+			if w.Code != tc.code {
+				t.Errorf("unexpected code: %d expected %d error: %v", w.Code, tc.code, err)
+			}
+		})
+	}
+}
+
+func TestPatchHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		serviceFunc func(mock *MockService)
+		code        int
+	}{
+		{
+			name: "ok",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Patch(gomock.Any(), 1, 1, patch.Patch(`{"name":"Contacts"}`)).Return(&category.Category{}, nil)
+			},
+			code: http.StatusOK,
+		},
+		{
+			name: "invalid patch",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Patch(gomock.Any(), 1, 1, gomock.Any()).Return(nil, patch.ErrInvalid)
+			},
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "validation error",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Patch(gomock.Any(), 1, 1, gomock.Any()).Return(nil, make(validation.Errors))
+			},
+			code: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "not found",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Patch(gomock.Any(), 1, 1, gomock.Any()).Return(nil, category.ErrNotFound)
+			},
+			code: http.StatusNotFound,
+		},
+		{
+			name: "internal error",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Patch(gomock.Any(), 1, 1, gomock.Any()).Return(nil, errors.New("mock error"))
+			},
+			code: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := NewMockService(ctrl)
+			tc.serviceFunc(service)
+
+			h := PatchHandler{service}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPatch, "http://example.com", strings.NewReader(`{"name":"Contacts"}`))
+			r = mux.SetURLVars(r, map[string]string{"id": "1"})
+			r.Header.Set("If-Match", `"1"`)
 
 			err := h.Handle(w, r)
 			if w.Code != tc.code {
@@ -173,17 +258,32 @@ func TestDeleteHandler(t *testing.T) {
 		{
 			name: "ok",
 			serviceFunc: func(m *MockService) {
-				m.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(nil)
+				m.EXPECT().Delete(gomock.Any(), gomock.Any(), 1).Return(nil)
 			},
 			code: http.StatusOK,
 		},
+		{
+			name: "not found",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Delete(gomock.Any(), gomock.Any(), 1).Return(category.ErrNotFound)
+			},
+			code: http.StatusNotFound,
+		},
 		{
 			name: "repository error",
 			serviceFunc: func(m *MockService) {
-				m.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(errors.New("mock error"))
+				m.EXPECT().Delete(gomock.Any(), gomock.Any(), 1).Return(errors.New("mock error"))
 			},
 			code: http.StatusInternalServerError,
 		},
+		{
+			name: "version mismatch",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Delete(gomock.Any(), gomock.Any(), 1).Return(category.ErrVersionMismatch)
+				m.EXPECT().Find(gomock.Any(), 1).Return(&category.Category{Version: 2}, nil)
+			},
+			code: http.StatusPreconditionFailed,
+		},
 	}
 
 	for _, tc := range tests {
@@ -201,6 +301,7 @@ func TestDeleteHandler(t *testing.T) {
 			w := httptest.NewRecorder()
 			r := httptest.NewRequest(http.MethodDelete, "http://example.com", strings.NewReader("{}"))
 			r = mux.SetURLVars(r, map[string]string{"id": "1"})
+			r.Header.Set("If-Match", `"1"`)
 
 			err := h.Handle(w, r)
 			if w.Code != tc.code {
@@ -213,23 +314,37 @@ func TestDeleteHandler(t *testing.T) {
 func TestListHandler(t *testing.T) {
 	tests := []struct {
 		name        string
+		query       string
 		serviceFunc func(mock *MockService)
 		code        int
 	}{
 		{
 			name: "ok",
 			serviceFunc: func(m *MockService) {
-				m.EXPECT().List(gomock.Any()).Return(&category.Categories{}, nil)
+				m.EXPECT().List(gomock.Any(), gomock.Any()).Return(&category.Categories{}, nil)
 			},
 			code: http.StatusOK,
 		},
 		{
 			name: "repository error",
 			serviceFunc: func(m *MockService) {
-				m.EXPECT().List(gomock.Any()).Return(&category.Categories{}, errors.New("mock error"))
+				m.EXPECT().List(gomock.Any(), gomock.Any()).Return(&category.Categories{}, errors.New("mock error"))
 			},
 			code: http.StatusInternalServerError,
 		},
+		{
+			name:        "invalid query",
+			query:       "?limit=-1",
+			serviceFunc: func(m *MockService) {},
+			code:        http.StatusBadRequest,
+		},
+		{
+			name: "invalid sort",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().List(gomock.Any(), gomock.Any()).Return(&category.Categories{}, query.ErrInvalidSort)
+			},
+			code: http.StatusBadRequest,
+		},
 	}
 
 	for _, tc := range tests {
@@ -245,7 +360,7 @@ func TestListHandler(t *testing.T) {
 
 			h := ListHandler{service}
 			w := httptest.NewRecorder()
-			r := httptest.NewRequest(http.MethodGet, "http://example.com", strings.NewReader("{}"))
+			r := httptest.NewRequest(http.MethodGet, "http://example.com"+tc.query, strings.NewReader("{}"))
 
 			err := h.Handle(w, r)
 			if w.Code != tc.code {
@@ -254,3 +369,84 @@ func TestListHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestUndeleteHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		serviceFunc func(mock *MockService)
+		code        int
+	}{
+		{
+			name: "ok",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Undelete(gomock.Any(), 1).Return(nil)
+			},
+			code: http.StatusNoContent,
+		},
+		{
+			name: "not found",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Undelete(gomock.Any(), 1).Return(category.ErrNotFound)
+			},
+			code: http.StatusNotFound,
+		},
+		{
+			name: "internal error",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Undelete(gomock.Any(), 1).Return(errors.New("mock error"))
+			},
+			code: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := NewMockService(ctrl)
+			tc.serviceFunc(service)
+
+			h := UndeleteHandler{service}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "http://example.com", nil)
+			r = mux.SetURLVars(r, map[string]string{"id": "1"})
+
+			err := h.Handle(w, r)
+			if w.Code != tc.code {
+				t.Errorf("unexpected code: %d expected %d error: %v", w.Code, tc.code, err)
+			}
+		})
+	}
+}
+
+func TestIfMatchRequired(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler Handler
+		method  string
+	}{
+		{name: "update", handler: &UpdateHandler{}, method: http.MethodPut},
+		{name: "patch", handler: &PatchHandler{}, method: http.MethodPatch},
+		{name: "delete", handler: &DeleteHandler{}, method: http.MethodDelete},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(tc.method, "http://example.com", strings.NewReader("{}"))
+			r = mux.SetURLVars(r, map[string]string{"id": "1"})
+
+			err := tc.handler.Handle(w, r)
+			if w.Code != http.StatusPreconditionRequired {
+				t.Errorf("unexpected code: %d expected %d error: %v", w.Code, http.StatusPreconditionRequired, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,242 @@
+package invitation
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/dipress/crmifc/internal/broker/http/handler"
+	"github.com/dipress/crmifc/internal/broker/http/response"
+	"github.com/dipress/crmifc/internal/invitation"
+	"github.com/dipress/crmifc/internal/kit/auth"
+	"github.com/dipress/crmifc/internal/role"
+	"github.com/dipress/crmifc/internal/user"
+	"github.com/dipress/crmifc/internal/validation"
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+)
+
+// go:generate mockgen -source=handler.go -package=invitation -destination=handler.mock.go Service
+
+// Handler allows to handle requests.
+type Handler interface {
+	Handle(w http.ResponseWriter, r *http.Request) error
+}
+
+// Service contains all services.
+type Service interface {
+	Invite(ctx context.Context, c *auth.Claims, f *invitation.Form) (*invitation.Invitation, error)
+	List(ctx context.Context) (*invitation.Invitations, error)
+	Resend(ctx context.Context, id int) (*invitation.Invitation, error)
+	Revoke(ctx context.Context, id int) error
+	Accept(ctx context.Context, token string, f *invitation.AcceptForm) (*user.User, error)
+}
+
+// InviteHandler for invitation create requests.
+type InviteHandler struct {
+	Service
+}
+
+// Handle implements Handler interface.
+func (h *InviteHandler) Handle(w http.ResponseWriter, r *http.Request) error {
+	claims, ok := auth.FromContext(r.Context())
+	if !ok {
+		return errors.Wrap(response.UnauthorizedResponse(w), "claims from context")
+	}
+
+	var f invitation.Form
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return errors.Wrap(response.BadRequestResponse(w), "read body")
+	}
+
+	if err := f.UnmarshalJSON(data); err != nil {
+		return errors.Wrap(response.BadRequestResponse(w), "unmarshal json")
+	}
+
+	inv, err := h.Invite(r.Context(), claims, &f)
+	if err != nil {
+		switch v := errors.Cause(err).(type) {
+		case validation.Errors:
+			return errors.Wrap(response.UnprocessabeEntityResponse(w, v), "validation response")
+		}
+
+		switch errors.Cause(err) {
+		case user.ErrEmailExists:
+			ves := validation.Errors{"email": "already exists"}
+			return errors.Wrap(response.UnprocessabeEntityResponse(w, ves), "invite")
+		case invitation.ErrExists:
+			ves := validation.Errors{"email": "is invited already"}
+			return errors.Wrap(response.UnprocessabeEntityResponse(w, ves), "invite")
+		case role.ErrNotFound:
+			ves := validation.Errors{"role_id": "is not found"}
+			return errors.Wrap(response.UnprocessabeEntityResponse(w, ves), "invite")
+		default:
+			return errors.Wrap(response.InternalServerErrorResponse(w), "invite")
+		}
+	}
+
+	data, err = inv.MarshalJSON()
+	if err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "marshal json")
+	}
+
+	if _, err := w.Write(data); err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "write response")
+	}
+
+	return nil
+}
+
+// ListHandler for pending invitation list requests.
+type ListHandler struct {
+	Service
+}
+
+// Handle implements Handler interface.
+func (h *ListHandler) Handle(w http.ResponseWriter, r *http.Request) error {
+	invs, err := h.List(r.Context())
+	if err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "list invitations")
+	}
+
+	data, err := invs.MarshalJSON()
+	if err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "marshal json")
+	}
+
+	if _, err := w.Write(data); err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "write response")
+	}
+
+	return nil
+}
+
+// ResendHandler for invitation resend requests.
+type ResendHandler struct {
+	Service
+}
+
+// Handle implements Handler interface.
+func (h *ResendHandler) Handle(w http.ResponseWriter, r *http.Request) error {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		return errors.Wrapf(response.BadRequestResponse(w), "convert id query param to int: %v", err)
+	}
+
+	inv, err := h.Resend(r.Context(), id)
+	if err != nil {
+		switch errors.Cause(err) {
+		case invitation.ErrNotFound:
+			return errors.Wrap(response.NotFoundResponse(w), "resend invitation")
+		default:
+			return errors.Wrap(response.InternalServerErrorResponse(w), "resend invitation")
+		}
+	}
+
+	data, err := inv.MarshalJSON()
+	if err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "marshal json")
+	}
+
+	if _, err := w.Write(data); err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "write response")
+	}
+
+	return nil
+}
+
+// RevokeHandler for invitation revoke requests.
+type RevokeHandler struct {
+	Service
+}
+
+// Handle implements Handler interface.
+func (h *RevokeHandler) Handle(w http.ResponseWriter, r *http.Request) error {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		return errors.Wrapf(response.BadRequestResponse(w), "convert id query param to int: %v", err)
+	}
+
+	if err := h.Revoke(r.Context(), id); err != nil {
+		switch errors.Cause(err) {
+		case invitation.ErrNotFound:
+			return errors.Wrap(response.NotFoundResponse(w), "revoke invitation")
+		default:
+			return errors.Wrap(response.InternalServerErrorResponse(w), "revoke invitation")
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// AcceptHandler for invitation accept requests.
+type AcceptHandler struct {
+	Service
+}
+
+// Handle implements Handler interface.
+func (h *AcceptHandler) Handle(w http.ResponseWriter, r *http.Request) error {
+	var f invitation.AcceptForm
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return errors.Wrap(response.BadRequestResponse(w), "read body")
+	}
+
+	if err := f.UnmarshalJSON(data); err != nil {
+		return errors.Wrap(response.BadRequestResponse(w), "unmarshal json")
+	}
+
+	u, err := h.Accept(r.Context(), mux.Vars(r)["token"], &f)
+	if err != nil {
+		switch v := errors.Cause(err).(type) {
+		case validation.Errors:
+			return errors.Wrap(response.UnprocessabeEntityResponse(w, v), "validation response")
+		}
+
+		switch errors.Cause(err) {
+		case invitation.ErrInvalidToken:
+			return errors.Wrap(response.NotFoundResponse(w), "accept invitation")
+		case user.ErrUsernameExists:
+			ves := validation.Errors{"username": "already exists"}
+			return errors.Wrap(response.UnprocessabeEntityResponse(w, ves), "accept invitation")
+		case user.ErrEmailExists:
+			ves := validation.Errors{"email": "already exists"}
+			return errors.Wrap(response.UnprocessabeEntityResponse(w, ves), "accept invitation")
+		default:
+			return errors.Wrap(response.InternalServerErrorResponse(w), "accept invitation")
+		}
+	}
+
+	data, err = u.MarshalJSON()
+	if err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "marshal json")
+	}
+
+	if _, err := w.Write(data); err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "write response")
+	}
+
+	return nil
+}
+
+// Prepare prepares routes of the invitations. Invitations are managed
+// by the users who manage users, the invitee accepts it by the token
+// without authorization.
+func Prepare(subrouter *mux.Router, service Service, middleware func(permissions ...role.Permission) func(handler.Handler) http.Handler, public func(handler.Handler) http.Handler) {
+	invite := InviteHandler{service}
+	list := ListHandler{service}
+	resend := ResendHandler{service}
+	revoke := RevokeHandler{service}
+	accept := AcceptHandler{service}
+
+	subrouter.Handle("", middleware(role.UsersManage)(&invite)).Methods(http.MethodPost)
+	subrouter.Handle("", middleware(role.UsersManage)(&list)).Methods(http.MethodGet)
+	subrouter.Handle("/{id:[0-9]+}/resend", middleware(role.UsersManage)(&resend)).Methods(http.MethodPost)
+	subrouter.Handle("/{id:[0-9]+}", middleware(role.UsersManage)(&revoke)).Methods(http.MethodDelete)
+	subrouter.Handle("/{token}/accept", public(&accept)).Methods(http.MethodPost)
+}
@@ -0,0 +1,267 @@
+package invitation
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dipress/crmifc/internal/invitation"
+	"github.com/dipress/crmifc/internal/kit/auth"
+	"github.com/dipress/crmifc/internal/role"
+	"github.com/dipress/crmifc/internal/user"
+	"github.com/dipress/crmifc/internal/validation"
+	gomock "github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+)
+
+func TestInviteHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		claims      bool
+		serviceFunc func(mock *MockService)
+		code        int
+	}{
+		{
+			name:   "ok",
+			claims: true,
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Invite(gomock.Any(), gomock.Any(), gomock.Any()).Return(&invitation.Invitation{ID: 1}, nil)
+			},
+			code: http.StatusOK,
+		},
+		{
+			name:        "without claims",
+			serviceFunc: func(m *MockService) {},
+			code:        http.StatusUnauthorized,
+		},
+		{
+			name:   "validation error",
+			claims: true,
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Invite(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, validation.Errors{"email": "cannot be blank"})
+			},
+			code: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "email exists",
+			claims: true,
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Invite(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, user.ErrEmailExists)
+			},
+			code: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "invited already",
+			claims: true,
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Invite(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, invitation.ErrExists)
+			},
+			code: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "role not found",
+			claims: true,
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Invite(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, role.ErrNotFound)
+			},
+			code: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "internal error",
+			claims: true,
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Invite(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("mock error"))
+			},
+			code: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := NewMockService(ctrl)
+			tc.serviceFunc(service)
+
+			h := InviteHandler{service}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "http://example.com", strings.NewReader(`{"email":"username@example.com","role_id":2}`))
+			if tc.claims {
+				r = r.WithContext(auth.ToContext(r.Context(), &auth.Claims{User: user.User{ID: 1}}))
+			}
+
+			err := h.Handle(w, r)
+			if w.Code != tc.code {
+				t.Errorf("unexpected code: %d expected %d error: %v", w.Code, tc.code, err)
+			}
+		})
+	}
+}
+
+func TestResendHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		serviceFunc func(mock *MockService)
+		code        int
+	}{
+		{
+			name: "ok",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Resend(gomock.Any(), 2).Return(&invitation.Invitation{ID: 2}, nil)
+			},
+			code: http.StatusOK,
+		},
+		{
+			name: "not found",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Resend(gomock.Any(), 2).Return(nil, invitation.ErrNotFound)
+			},
+			code: http.StatusNotFound,
+		},
+		{
+			name: "internal error",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Resend(gomock.Any(), 2).Return(nil, errors.New("mock error"))
+			},
+			code: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := NewMockService(ctrl)
+			tc.serviceFunc(service)
+
+			h := ResendHandler{service}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "http://example.com", nil)
+			r = mux.SetURLVars(r, map[string]string{"id": "2"})
+
+			err := h.Handle(w, r)
+			if w.Code != tc.code {
+				t.Errorf("unexpected code: %d expected %d error: %v", w.Code, tc.code, err)
+			}
+		})
+	}
+}
+
+func TestRevokeHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		serviceFunc func(mock *MockService)
+		code        int
+	}{
+		{
+			name: "ok",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Revoke(gomock.Any(), 2).Return(nil)
+			},
+			code: http.StatusNoContent,
+		},
+		{
+			name: "not found",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Revoke(gomock.Any(), 2).Return(invitation.ErrNotFound)
+			},
+			code: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := NewMockService(ctrl)
+			tc.serviceFunc(service)
+
+			h := RevokeHandler{service}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("DELETE", "http://example.com", nil)
+			r = mux.SetURLVars(r, map[string]string{"id": "2"})
+
+			err := h.Handle(w, r)
+			if w.Code != tc.code {
+				t.Errorf("unexpected code: %d expected %d error: %v", w.Code, tc.code, err)
+			}
+		})
+	}
+}
+
+func TestAcceptHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		serviceFunc func(mock *MockService)
+		code        int
+	}{
+		{
+			name: "ok",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Accept(gomock.Any(), "token", gomock.Any()).Return(&user.User{ID: 1}, nil)
+			},
+			code: http.StatusOK,
+		},
+		{
+			name: "validation error",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Accept(gomock.Any(), "token", gomock.Any()).Return(nil, validation.Errors{"password": "cannot be blank"})
+			},
+			code: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "invalid token",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Accept(gomock.Any(), "token", gomock.Any()).Return(nil, invitation.ErrInvalidToken)
+			},
+			code: http.StatusNotFound,
+		},
+		{
+			name: "username exists",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Accept(gomock.Any(), "token", gomock.Any()).Return(nil, user.ErrUsernameExists)
+			},
+			code: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "internal error",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Accept(gomock.Any(), "token", gomock.Any()).Return(nil, errors.New("mock error"))
+			},
+			code: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := NewMockService(ctrl)
+			tc.serviceFunc(service)
+
+			h := AcceptHandler{service}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "http://example.com", strings.NewReader(`{"username":"username","password":"password123"}`))
+			r = mux.SetURLVars(r, map[string]string{"token": "token"})
+
+			err := h.Handle(w, r)
+			if w.Code != tc.code {
+				t.Errorf("unexpected code: %d expected %d error: %v", w.Code, tc.code, err)
+			}
+		})
+	}
+}
@@ -6,9 +6,11 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/dipress/crmifc/internal/apikey"
 	"github.com/dipress/crmifc/internal/broker/http/handler"
 	"github.com/dipress/crmifc/internal/broker/http/response"
 	"github.com/dipress/crmifc/internal/kit/auth"
+	"github.com/dipress/crmifc/internal/role"
 	"github.com/dipress/crmifc/internal/user"
 )
 
@@ -18,27 +20,24 @@ type Authenticator interface {
 	ParseClaims(ctx context.Context, tknStr string) (auth.Claims, error)
 }
 
-// authMiddleware represents middleware with authentication.
-func authMiddleware(a Authenticator) handler.Middleware {
+// KeyAuthenticator is used to authenticate clients by api keys.
+// It recreates the claims of the key owner.
+type KeyAuthenticator interface {
+	ParseKey(ctx context.Context, key string) (auth.Claims, error)
+}
+
+// authMiddleware represents middleware with authentication. The
+// api key is accepted from the X-API-Key header or instead of the
+// token in the Authorization header.
+func authMiddleware(a Authenticator, k KeyAuthenticator) handler.Middleware {
 	m := func(next handler.Handler) handler.Handler {
 		h := handler.Func(func(w http.ResponseWriter, r *http.Request) error {
-			authHdr := r.Header.Get("Authorization")
-			if authHdr == "" {
-				return response.UnauthorizedResponse(w)
-			}
-
-			tknStr, err := parseAuthHeader(authHdr)
+			cl, err := parseRequest(r, a, k)
 			if err != nil {
 				return response.UnauthorizedResponse(w)
 			}
 
-			c := r.Context()
-			cl, err := a.ParseClaims(c, tknStr)
-			if err != nil {
-				return response.UnauthorizedResponse(w)
-			}
-
-			ctx := auth.ToContext(c, &cl)
+			ctx := auth.ToContext(r.Context(), &cl)
 			r = r.WithContext(ctx)
 
 			return next.Handle(w, r)
@@ -50,6 +49,44 @@ func authMiddleware(a Authenticator) handler.Middleware {
 	return m
 }
 
+// interactiveOnly refuses the requests authenticated by an api key.
+// Such keys are meant for integrations, so they must not manage the
+// account, its second factor or its sessions.
+func interactiveOnly(next handler.Handler) handler.Handler {
+	h := handler.Func(func(w http.ResponseWriter, r *http.Request) error {
+		claims, ok := auth.FromContext(r.Context())
+		if !ok {
+			return response.UnauthorizedResponse(w)
+		}
+
+		if claims.APIKeyID != 0 {
+			return response.ForbiddenResponse(w)
+		}
+		return next.Handle(w, r)
+	})
+
+	return h
+}
+
+// parseRequest recreates the claims by the api key
+// or by the token of the request.
+func parseRequest(r *http.Request, a Authenticator, k KeyAuthenticator) (auth.Claims, error) {
+	ctx := r.Context()
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return k.ParseKey(ctx, key)
+	}
+
+	tknStr, err := parseAuthHeader(r.Header.Get("Authorization"))
+	if err != nil {
+		return auth.Claims{}, err
+	}
+
+	if strings.HasPrefix(tknStr, apikey.KeyPrefix) {
+		return k.ParseKey(ctx, tknStr)
+	}
+	return a.ParseClaims(ctx, tknStr)
+}
+
 // contentTypeMiddleware sets content type header.
 func contentTypeMiddleware(next handler.Handler) handler.Handler {
 	h := handler.Func(func(w http.ResponseWriter, r *http.Request) error {
@@ -60,21 +97,25 @@ func contentTypeMiddleware(next handler.Handler) handler.Handler {
 	return h
 }
 
-// Abillity checks permissions to view.
+// Abillity checks permissions of the user.
 type Abillity interface {
-	CanAdmin(u *user.User) bool
+	Can(u *user.User, permissions ...role.Permission) bool
 }
 
-func adminMiddleware(a Abillity) handler.Middleware {
+// permissionMiddleware allows the request only for users
+// granted with any of given permissions.
+func permissionMiddleware(a Abillity, permissions ...role.Permission) handler.Middleware {
 	m := func(next handler.Handler) handler.Handler {
 		h := handler.Func(func(w http.ResponseWriter, r *http.Request) error {
 			ctx := r.Context()
-			claims, _ := auth.FromContext(ctx)
-
-			ok := a.CanAdmin(&claims.User)
+			claims, ok := auth.FromContext(ctx)
 			if !ok {
 				return response.UnauthorizedResponse(w)
 			}
+
+			if !a.Can(&claims.User, permissions...) {
+				return response.ForbiddenResponse(w)
+			}
 			return next.Handle(w, r)
 		})
 
@@ -7,6 +7,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/dipress/crmifc/internal/abillity"
 	"github.com/dipress/crmifc/internal/broker/http/handler"
 	"github.com/dipress/crmifc/internal/kit/auth"
 	"github.com/dipress/crmifc/internal/role"
@@ -18,6 +19,7 @@ func Test_authMiddleware(t *testing.T) {
 		name      string
 		header    map[string]string
 		parseFunc func(ctx context.Context, tknStr string) (auth.Claims, error)
+		keyFunc   func(ctx context.Context, key string) (auth.Claims, error)
 		code      int
 	}{
 		{
@@ -58,6 +60,36 @@ func Test_authMiddleware(t *testing.T) {
 			},
 			code: http.StatusUnauthorized,
 		},
+		{
+			name: "api key header",
+			header: map[string]string{
+				"X-API-Key": "crm_key",
+			},
+			keyFunc: func(ctx context.Context, key string) (auth.Claims, error) {
+				return auth.Claims{}, nil
+			},
+			code: http.StatusOK,
+		},
+		{
+			name: "api key bearer",
+			header: map[string]string{
+				"Authorization": "Bearer crm_key",
+			},
+			keyFunc: func(ctx context.Context, key string) (auth.Claims, error) {
+				return auth.Claims{}, nil
+			},
+			code: http.StatusOK,
+		},
+		{
+			name: "wrong api key",
+			header: map[string]string{
+				"X-API-Key": "crm_wrong",
+			},
+			keyFunc: func(ctx context.Context, key string) (auth.Claims, error) {
+				return auth.Claims{}, errors.New("mock error")
+			},
+			code: http.StatusUnauthorized,
+		},
 	}
 
 	for _, tc := range tests {
@@ -76,7 +108,7 @@ func Test_authMiddleware(t *testing.T) {
 				r.Header.Set(k, v)
 			}
 
-			authMiddleware(parseFunc(tc.parseFunc))(next).Handle(w, r)
+			authMiddleware(parseFunc(tc.parseFunc), keyFunc(tc.keyFunc))(next).Handle(w, r)
 
 			if w.Code != tc.code {
 				t.Errorf("unexpected code: %d expected: %d", w.Code, tc.code)
@@ -91,36 +123,55 @@ func (p parseFunc) ParseClaims(ctx context.Context, tknStr string) (auth.Claims,
 	return p(ctx, tknStr)
 }
 
-func Test_adminMiddleware(t *testing.T) {
+type keyFunc func(ctx context.Context, key string) (auth.Claims, error)
+
+func (k keyFunc) ParseKey(ctx context.Context, key string) (auth.Claims, error) {
+	return k(ctx, key)
+}
+
+func Test_permissionMiddleware(t *testing.T) {
 	tests := []struct {
-		name      string
-		user      user.User
-		adminFunc func(u *user.User) bool
-		code      int
+		name        string
+		user        user.User
+		noClaims    bool
+		permissions []role.Permission
+		code        int
 	}{
 		{
 			name: "ok",
 			user: user.User{
 				Role: role.Role{
-					Name: "Admin",
+					Permissions: []role.Permission{role.ArticlesView},
 				},
 			},
-			adminFunc: func(u *user.User) bool {
-				return true
-			},
-			code: http.StatusOK,
+			permissions: []role.Permission{role.ArticlesView},
+			code:        http.StatusOK,
 		},
 		{
-			name: "not admin error",
+			name: "any of permissions",
 			user: user.User{
 				Role: role.Role{
-					Name: "Manager",
+					Permissions: []role.Permission{role.ArticlesUpdateOwn},
 				},
 			},
-			adminFunc: func(u *user.User) bool {
-				return false
+			permissions: []role.Permission{role.ArticlesUpdate, role.ArticlesUpdateOwn},
+			code:        http.StatusOK,
+		},
+		{
+			name: "permission is not granted",
+			user: user.User{
+				Role: role.Role{
+					Permissions: []role.Permission{role.ArticlesView},
+				},
 			},
-			code: http.StatusUnauthorized,
+			permissions: []role.Permission{role.UsersManage},
+			code:        http.StatusForbidden,
+		},
+		{
+			name:        "no claims",
+			noClaims:    true,
+			permissions: []role.Permission{role.ArticlesView},
+			code:        http.StatusUnauthorized,
 		},
 	}
 
@@ -136,15 +187,17 @@ func Test_adminMiddleware(t *testing.T) {
 			w := httptest.NewRecorder()
 			r := httptest.NewRequest(http.MethodGet, "http://exapmle.com", nil)
 
-			claims := auth.Claims{
-				User: tc.user,
-			}
+			if !tc.noClaims {
+				claims := auth.Claims{
+					User: tc.user,
+				}
 
-			c := r.Context()
-			ctx := auth.ToContext(c, &claims)
-			r = r.WithContext(ctx)
+				c := r.Context()
+				ctx := auth.ToContext(c, &claims)
+				r = r.WithContext(ctx)
+			}
 
-			adminMiddleware(adminFunc(tc.adminFunc))(next).Handle(w, r)
+			permissionMiddleware(abillity.UserAbillity{}, tc.permissions...)(next).Handle(w, r)
 
 			if w.Code != tc.code {
 				t.Errorf("unexpected code: %d expected: %d", w.Code, tc.code)
@@ -153,10 +206,55 @@ func Test_adminMiddleware(t *testing.T) {
 	}
 }
 
-type adminFunc func(u *user.User) bool
+func Test_interactiveOnly(t *testing.T) {
+	tests := []struct {
+		name     string
+		claims   auth.Claims
+		noClaims bool
+		code     int
+	}{
+		{
+			name: "ok",
+			code: http.StatusOK,
+		},
+		{
+			name: "api key",
+			claims: auth.Claims{
+				APIKeyID: 1,
+			},
+			code: http.StatusForbidden,
+		},
+		{
+			name:     "no claims",
+			noClaims: true,
+			code:     http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			next := handler.Func(func(w http.ResponseWriter, r *http.Request) error {
+				return nil
+			})
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "http://exapmle.com", nil)
 
-func (a adminFunc) CanAdmin(u *user.User) bool {
-	return a(u)
+			if !tc.noClaims {
+				ctx := auth.ToContext(r.Context(), &tc.claims)
+				r = r.WithContext(ctx)
+			}
+
+			interactiveOnly(next).Handle(w, r)
+
+			if w.Code != tc.code {
+				t.Errorf("unexpected code: %d expected: %d", w.Code, tc.code)
+			}
+		})
+	}
 }
 
 func Test_contentTypeMiddleware(t *testing.T) {
@@ -0,0 +1,32 @@
+package request
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ErrPreconditionRequired raises when the request which changes the
+// resource doesn't tell its version by If-Match header.
+var ErrPreconditionRequired = errors.New("precondition required")
+
+// IfMatch returns the version of the resource from the
+// entity tag of If-Match header:
+//
+//	If-Match: "3"
+//
+// Weak and multiple entity tags aren't accepted.
+func IfMatch(r *http.Request) (int, error) {
+	tag := r.Header.Get("If-Match")
+	if len(tag) < 2 || !strings.HasPrefix(tag, `"`) || !strings.HasSuffix(tag, `"`) {
+		return 0, ErrPreconditionRequired
+	}
+
+	version, err := strconv.Atoi(tag[1 : len(tag)-1])
+	if err != nil || version < 1 {
+		return 0, ErrPreconditionRequired
+	}
+
+	return version, nil
+}
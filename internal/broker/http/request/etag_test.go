@@ -0,0 +1,73 @@
+package request
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIfMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		ifMatch string
+		wantErr bool
+		expect  int
+	}{
+		{
+			name:    "ok",
+			ifMatch: `"3"`,
+			expect:  3,
+		},
+		{
+			name:    "missing",
+			wantErr: true,
+		},
+		{
+			name:    "unquoted",
+			ifMatch: "3",
+			wantErr: true,
+		},
+		{
+			name:    "weak",
+			ifMatch: `W/"3"`,
+			wantErr: true,
+		},
+		{
+			name:    "any",
+			ifMatch: "*",
+			wantErr: true,
+		},
+		{
+			name:    "not a version",
+			ifMatch: `"abc"`,
+			wantErr: true,
+		},
+		{
+			name:    "zero",
+			ifMatch: `"0"`,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := httptest.NewRequest(http.MethodPut, "http://example.com", nil)
+			if tc.ifMatch != "" {
+				r.Header.Set("If-Match", tc.ifMatch)
+			}
+
+			version, err := IfMatch(r)
+			if tc.wantErr {
+				assert.Equal(t, ErrPreconditionRequired, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expect, version)
+		})
+	}
+}
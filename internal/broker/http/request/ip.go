@@ -0,0 +1,16 @@
+package request
+
+import (
+	"net"
+	"net/http"
+)
+
+// ClientIP returns the IP address of the client which sent r.
+// Forwarded headers aren't trusted, since they are set by the client.
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
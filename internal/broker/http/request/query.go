@@ -0,0 +1,63 @@
+package request
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/dipress/crmifc/internal/kit/query"
+)
+
+const (
+	limitParam  = "limit"
+	cursorParam = "cursor"
+	sortParam   = "sort"
+	orderParam  = "order"
+
+	filterPrefix = "filter["
+	filterSuffix = "]"
+)
+
+// ParseQuery parses list query from the query-string params:
+//
+//	?limit=20&cursor=<next_cursor>&sort=created_at&order=desc&filter[user_id]=1
+func ParseQuery(r *http.Request) (*query.Query, error) {
+	q := query.New()
+	values := r.URL.Query()
+
+	if v := values.Get(limitParam); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, query.ErrInvalidLimit
+		}
+		q.Limit = limit
+	}
+
+	if v := values.Get(sortParam); v != "" {
+		q.Sort = v
+	}
+
+	if v := values.Get(orderParam); v != "" {
+		q.Direction = query.Direction(strings.ToLower(v))
+	}
+
+	q.Cursor = values.Get(cursorParam)
+
+	for k, v := range values {
+		if !strings.HasPrefix(k, filterPrefix) || !strings.HasSuffix(k, filterSuffix) {
+			continue
+		}
+
+		field := strings.TrimSuffix(strings.TrimPrefix(k, filterPrefix), filterSuffix)
+		if field == "" || len(v) == 0 {
+			return nil, query.ErrInvalidFilter
+		}
+		q.Filters[field] = v[0]
+	}
+
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+
+	return q, nil
+}
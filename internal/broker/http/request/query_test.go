@@ -0,0 +1,76 @@
+package request
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dipress/crmifc/internal/kit/query"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		target  string
+		wantErr bool
+		expect  *query.Query
+	}{
+		{
+			name:   "defaults",
+			target: "http://example.com",
+			expect: query.New(),
+		},
+		{
+			name:   "ok",
+			target: "http://example.com?limit=5&cursor=abc&sort=created_at&order=DESC&filter[user_id]=1",
+			expect: &query.Query{
+				Limit:     5,
+				Cursor:    "abc",
+				Sort:      "created_at",
+				Direction: query.Desc,
+				Filters: map[string]string{
+					"user_id": "1",
+				},
+			},
+		},
+		{
+			name:    "wrong limit",
+			target:  "http://example.com?limit=ten",
+			wantErr: true,
+		},
+		{
+			name:    "large limit",
+			target:  "http://example.com?limit=1000",
+			wantErr: true,
+		},
+		{
+			name:    "wrong order",
+			target:  "http://example.com?order=up",
+			wantErr: true,
+		},
+		{
+			name:    "blank filter",
+			target:  "http://example.com?filter[]=1",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := httptest.NewRequest(http.MethodGet, tc.target, nil)
+
+			got, err := ParseQuery(r)
+			if tc.wantErr {
+				assert.True(t, query.IsInvalid(err))
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expect, got)
+		})
+	}
+}
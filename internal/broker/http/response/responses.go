@@ -1,7 +1,10 @@
 package response
 
 import (
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/dipress/crmifc/internal/validation"
 	"github.com/pkg/errors"
@@ -25,6 +28,18 @@ var (
 	unauthorizedBody = messageResponse{
 		Message: "unauthorized",
 	}
+
+	forbiddenBody = messageResponse{
+		Message: "forbidden",
+	}
+
+	tooManyRequestsBody = messageResponse{
+		Message: "too many requests",
+	}
+
+	preconditionRequiredBody = messageResponse{
+		Message: "precondition required",
+	}
 )
 
 type messageResponse struct {
@@ -91,6 +106,88 @@ func UnauthorizedResponse(w http.ResponseWriter) error {
 	return nil
 }
 
+// ForbiddenResponse returns forbidden response.
+func ForbiddenResponse(w http.ResponseWriter) error {
+	w.WriteHeader(http.StatusForbidden)
+
+	data, err := forbiddenBody.MarshalJSON()
+	if err != nil {
+		return errors.Wrap(err, "marshal json")
+	}
+	if _, err := w.Write(data); err != nil {
+		return errors.Wrap(err, "write response")
+	}
+	return nil
+}
+
+// TooManyRequestsResponse returns too many requests response
+// which tells the client when to retry.
+func TooManyRequestsResponse(w http.ResponseWriter, retryAfter time.Duration) error {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	w.WriteHeader(http.StatusTooManyRequests)
+
+	data, err := tooManyRequestsBody.MarshalJSON()
+	if err != nil {
+		return errors.Wrap(err, "marshal json")
+	}
+	if _, err := w.Write(data); err != nil {
+		return errors.Wrap(err, "write response")
+	}
+	return nil
+}
+
+// ConflictResponse returns conflict response
+// which tells the client what the conflict is.
+func ConflictResponse(w http.ResponseWriter, reason error) error {
+	w.WriteHeader(http.StatusConflict)
+
+	body := messageResponse{
+		Message: reason.Error(),
+	}
+
+	data, err := body.MarshalJSON()
+	if err != nil {
+		return errors.Wrap(err, "marshal json")
+	}
+	if _, err := w.Write(data); err != nil {
+		return errors.Wrap(err, "write response")
+	}
+	return nil
+}
+
+// ETag tells the version of the representation by its entity tag.
+func ETag(w http.ResponseWriter, version int) {
+	w.Header().Set("ETag", strconv.Quote(strconv.Itoa(version)))
+}
+
+// PreconditionRequiredResponse returns precondition required response,
+// the request has to tell the version it changes by If-Match header.
+func PreconditionRequiredResponse(w http.ResponseWriter) error {
+	w.WriteHeader(http.StatusPreconditionRequired)
+
+	data, err := preconditionRequiredBody.MarshalJSON()
+	if err != nil {
+		return errors.Wrap(err, "marshal json")
+	}
+	if _, err := w.Write(data); err != nil {
+		return errors.Wrap(err, "write response")
+	}
+	return nil
+}
+
+// PreconditionFailedResponse returns precondition failed response
+// with the current representation of the changed resource.
+func PreconditionFailedResponse(w http.ResponseWriter, version int, data []byte) error {
+	ETag(w, version)
+	w.WriteHeader(http.StatusPreconditionFailed)
+
+	if _, err := w.Write(data); err != nil {
+		return errors.Wrap(err, "write response")
+	}
+	return nil
+}
+
 type validationResponse struct {
 	Message string            `json:"message"`
 	Errors  validation.Errors `json:"errors"`
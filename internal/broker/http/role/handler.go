@@ -7,7 +7,10 @@ import (
 	"strconv"
 
 	"github.com/dipress/crmifc/internal/broker/http/handler"
+	"github.com/dipress/crmifc/internal/broker/http/request"
 	"github.com/dipress/crmifc/internal/broker/http/response"
+	"github.com/dipress/crmifc/internal/kit/patch"
+	"github.com/dipress/crmifc/internal/kit/query"
 	"github.com/dipress/crmifc/internal/role"
 	"github.com/dipress/crmifc/internal/validation"
 	"github.com/gorilla/mux"
@@ -25,9 +28,13 @@ type Handler interface {
 type Service interface {
 	Create(ctx context.Context, f *role.Form) (*role.Role, error)
 	Find(ctx context.Context, id int) (*role.Role, error)
-	Update(ctx context.Context, id int, f *role.Form) (*role.Role, error)
-	Delete(ctx context.Context, id int) error
-	List(ctx context.Context) (*role.Roles, error)
+	Update(ctx context.Context, id, version int, f *role.Form) (*role.Role, error)
+	Patch(ctx context.Context, id, version int, p patch.Patch) (*role.Role, error)
+	Delete(ctx context.Context, id, version int) error
+	List(ctx context.Context, q *query.Query) (*role.Roles, error)
+	Permissions(ctx context.Context) *role.PermissionList
+	Grant(ctx context.Context, id int, p role.Permission) (*role.Role, error)
+	Revoke(ctx context.Context, id int, p role.Permission) (*role.Role, error)
 }
 
 // CreateHandler for create requests.
@@ -48,21 +55,29 @@ func (h *CreateHandler) Handle(w http.ResponseWriter, r *http.Request) error {
 		return errors.Wrap(response.BadRequestResponse(w), "unmarshal json")
 	}
 
-	role, err := h.Create(r.Context(), &f)
+	rl, err := h.Create(r.Context(), &f)
 	if err != nil {
 		switch v := errors.Cause(err).(type) {
 		case validation.Errors:
 			return errors.Wrap(response.UnprocessabeEntityResponse(w, v), "validation response")
+		}
+
+		switch errors.Cause(err) {
+		case role.ErrNameExists:
+			ves := validation.Errors{"name": "already exists"}
+			return errors.Wrap(response.UnprocessabeEntityResponse(w, ves), "create role")
 		default:
 			return errors.Wrap(response.InternalServerErrorResponse(w), "create role")
 		}
 	}
 
-	data, err = role.MarshalJSON()
+	data, err = rl.MarshalJSON()
 	if err != nil {
 		return errors.Wrap(response.InternalServerErrorResponse(w), "marshal json")
 	}
 
+	response.ETag(w, rl.Version)
+
 	if _, err := w.Write(data); err != nil {
 		return errors.Wrap(response.InternalServerErrorResponse(w), "write response")
 	}
@@ -98,6 +113,8 @@ func (rol *FindHandler) Handle(w http.ResponseWriter, r *http.Request) error {
 		return errors.Wrap(response.InternalServerErrorResponse(w), "marshal json")
 	}
 
+	response.ETag(w, rl.Version)
+
 	if _, err := w.Write(data); err != nil {
 		return errors.Wrap(response.InternalServerErrorResponse(w), "write response")
 	}
@@ -120,6 +137,11 @@ func (rol *UpdateHandler) Handle(w http.ResponseWriter, r *http.Request) error {
 		return errors.Wrapf(response.BadRequestResponse(w), "convert id query param to int: %v", err)
 	}
 
+	version, err := request.IfMatch(r)
+	if err != nil {
+		return errors.Wrap(response.PreconditionRequiredResponse(w), "if match")
+	}
+
 	data, err := ioutil.ReadAll(r.Body)
 	if err != nil {
 		return errors.Wrap(response.BadRequestResponse(w), "read body")
@@ -129,11 +151,21 @@ func (rol *UpdateHandler) Handle(w http.ResponseWriter, r *http.Request) error {
 		return errors.Wrap(response.BadRequestResponse(w), "unmarshal json")
 	}
 
-	rl, err := rol.Update(r.Context(), id, &f)
+	rl, err := rol.Update(r.Context(), id, version, &f)
 	if err != nil {
 		switch v := errors.Cause(err).(type) {
 		case validation.Errors:
 			return errors.Wrap(response.UnprocessabeEntityResponse(w, v), "validation response")
+		}
+
+		switch errors.Cause(err) {
+		case role.ErrNameExists:
+			ves := validation.Errors{"name": "already exists"}
+			return errors.Wrap(response.UnprocessabeEntityResponse(w, ves), "update role")
+		case role.ErrNotFound:
+			return errors.Wrap(response.NotFoundResponse(w), "update role")
+		case role.ErrVersionMismatch:
+			return errors.Wrap(preconditionFailed(r.Context(), w, rol.Service, id), "update role")
 		default:
 			return errors.Wrap(response.InternalServerErrorResponse(w), "update role")
 		}
@@ -144,6 +176,67 @@ func (rol *UpdateHandler) Handle(w http.ResponseWriter, r *http.Request) error {
 		return errors.Wrap(response.InternalServerErrorResponse(w), "marshal json")
 	}
 
+	response.ETag(w, rl.Version)
+
+	if _, err := w.Write(data); err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "write response")
+	}
+
+	return nil
+}
+
+// PatchHandler for role partial update requests.
+type PatchHandler struct {
+	Service
+}
+
+// Handle implements Handler interface.
+func (h PatchHandler) Handle(w http.ResponseWriter, r *http.Request) error {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		return errors.Wrapf(response.BadRequestResponse(w), "convert id query param to int: %v", err)
+	}
+
+	version, err := request.IfMatch(r)
+	if err != nil {
+		return errors.Wrap(response.PreconditionRequiredResponse(w), "if match")
+	}
+
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return errors.Wrap(response.BadRequestResponse(w), "read body")
+	}
+
+	rl, err := h.Patch(r.Context(), id, version, patch.Patch(data))
+	if err != nil {
+		switch v := errors.Cause(err).(type) {
+		case validation.Errors:
+			return errors.Wrap(response.UnprocessabeEntityResponse(w, v), "validation response")
+		}
+
+		switch errors.Cause(err) {
+		case patch.ErrInvalid:
+			return errors.Wrap(response.BadRequestResponse(w), "apply patch")
+		case role.ErrNameExists:
+			ves := validation.Errors{"name": "already exists"}
+			return errors.Wrap(response.UnprocessabeEntityResponse(w, ves), "patch role")
+		case role.ErrNotFound:
+			return errors.Wrap(response.NotFoundResponse(w), "patch role")
+		case role.ErrVersionMismatch:
+			return errors.Wrap(preconditionFailed(r.Context(), w, h.Service, id), "patch role")
+		default:
+			return errors.Wrap(response.InternalServerErrorResponse(w), "patch role")
+		}
+	}
+
+	data, err = rl.MarshalJSON()
+	if err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "marshal json")
+	}
+
+	response.ETag(w, rl.Version)
+
 	if _, err := w.Write(data); err != nil {
 		return errors.Wrap(response.InternalServerErrorResponse(w), "write response")
 	}
@@ -165,8 +258,22 @@ func (rol *DeleteHandler) Handle(w http.ResponseWriter, r *http.Request) error {
 		return errors.Wrapf(response.BadRequestResponse(w), "convert id query param to int: %v", err)
 	}
 
-	if err := rol.Delete(r.Context(), id); err != nil {
-		return errors.Wrap(response.InternalServerErrorResponse(w), "delete role")
+	version, err := request.IfMatch(r)
+	if err != nil {
+		return errors.Wrap(response.PreconditionRequiredResponse(w), "if match")
+	}
+
+	if err := rol.Delete(r.Context(), id, version); err != nil {
+		switch errors.Cause(err) {
+		case role.ErrNotFound:
+			return errors.Wrap(response.NotFoundResponse(w), "delete role")
+		case role.ErrInUse:
+			return errors.Wrap(response.ConflictResponse(w, role.ErrInUse), "delete role")
+		case role.ErrVersionMismatch:
+			return errors.Wrap(preconditionFailed(r.Context(), w, rol.Service, id), "delete role")
+		default:
+			return errors.Wrap(response.InternalServerErrorResponse(w), "delete role")
+		}
 	}
 
 	return nil
@@ -179,8 +286,16 @@ type ListHandler struct {
 
 // Handle implements Handler interface.
 func (rol *ListHandler) Handle(w http.ResponseWriter, r *http.Request) error {
-	roles, err := rol.List(r.Context())
+	q, err := request.ParseQuery(r)
 	if err != nil {
+		return errors.Wrapf(response.BadRequestResponse(w), "parse query: %v", err)
+	}
+
+	roles, err := rol.List(r.Context(), q)
+	if err != nil {
+		if query.IsInvalid(errors.Cause(err)) {
+			return errors.Wrapf(response.BadRequestResponse(w), "list query: %v", err)
+		}
 		return errors.Wrap(response.InternalServerErrorResponse(w), "list of roles")
 	}
 
@@ -195,17 +310,141 @@ func (rol *ListHandler) Handle(w http.ResponseWriter, r *http.Request) error {
 	return nil
 }
 
+// PermissionsHandler for known permissions requests.
+type PermissionsHandler struct {
+	Service
+}
+
+// Handle implements Handler interface.
+func (rol *PermissionsHandler) Handle(w http.ResponseWriter, r *http.Request) error {
+	data, err := rol.Permissions(r.Context()).MarshalJSON()
+	if err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "marshal json")
+	}
+
+	if _, err := w.Write(data); err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "write response")
+	}
+	return nil
+}
+
+// GrantHandler for grant permission requests.
+type GrantHandler struct {
+	Service
+}
+
+// Handle implements Handler interface.
+func (rol *GrantHandler) Handle(w http.ResponseWriter, r *http.Request) error {
+	vars := mux.Vars(r)
+
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		return errors.Wrapf(response.BadRequestResponse(w), "convert id query param to int: %v", err)
+	}
+
+	rl, err := rol.Grant(r.Context(), id, role.Permission(vars["permission"]))
+	if err != nil {
+		return errors.Wrap(permissionErrorResponse(w, err), "grant permission")
+	}
+
+	data, err := rl.MarshalJSON()
+	if err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "marshal json")
+	}
+
+	response.ETag(w, rl.Version)
+
+	if _, err := w.Write(data); err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "write response")
+	}
+	return nil
+}
+
+// RevokeHandler for revoke permission requests.
+type RevokeHandler struct {
+	Service
+}
+
+// Handle implements Handler interface.
+func (rol *RevokeHandler) Handle(w http.ResponseWriter, r *http.Request) error {
+	vars := mux.Vars(r)
+
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		return errors.Wrapf(response.BadRequestResponse(w), "convert id query param to int: %v", err)
+	}
+
+	rl, err := rol.Revoke(r.Context(), id, role.Permission(vars["permission"]))
+	if err != nil {
+		return errors.Wrap(permissionErrorResponse(w, err), "revoke permission")
+	}
+
+	data, err := rl.MarshalJSON()
+	if err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "marshal json")
+	}
+
+	response.ETag(w, rl.Version)
+
+	if _, err := w.Write(data); err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "write response")
+	}
+	return nil
+}
+
+// permissionErrorResponse responds with the error
+// of granting or revoking the permission.
+func permissionErrorResponse(w http.ResponseWriter, err error) error {
+	switch errors.Cause(err) {
+	case role.ErrUnknownPermission:
+		return response.UnprocessabeEntityResponse(w, validation.Errors{"permission": role.ErrUnknownPermission.Error()})
+	case role.ErrNotFound:
+		return response.NotFoundResponse(w)
+	default:
+		return response.InternalServerErrorResponse(w)
+	}
+}
+
+// preconditionFailed responds with the current role
+// which the version of the request doesn't match.
+func preconditionFailed(ctx context.Context, w http.ResponseWriter, s Service, id int) error {
+	rl, err := s.Find(ctx, id)
+	if err != nil {
+		switch errors.Cause(err) {
+		case role.ErrNotFound:
+			return errors.Wrap(response.NotFoundResponse(w), "find role")
+		default:
+			return errors.Wrap(response.InternalServerErrorResponse(w), "find role")
+		}
+	}
+
+	data, err := rl.MarshalJSON()
+	if err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "marshal json")
+	}
+
+	return errors.Wrap(response.PreconditionFailedResponse(w, rl.Version, data), "precondition failed")
+}
+
 // Prepare prepares routes to use.
-func Prepare(subrouter *mux.Router, service Service, middleware func(handler.Handler) http.Handler) {
+func Prepare(subrouter *mux.Router, service Service, middleware func(permissions ...role.Permission) func(handler.Handler) http.Handler) {
 	create := CreateHandler{service}
 	find := FindHandler{service}
 	update := UpdateHandler{service}
+	patch := PatchHandler{service}
 	delete := DeleteHandler{service}
 	list := ListHandler{service}
-
-	subrouter.Handle("", middleware(&create)).Methods(http.MethodPost)
-	subrouter.Handle("/{id}", middleware(&find)).Methods(http.MethodGet)
-	subrouter.Handle("/{id}", middleware(&update)).Methods(http.MethodPut)
-	subrouter.Handle("/{id}", middleware(&delete)).Methods(http.MethodDelete)
-	subrouter.Handle("", middleware(&list)).Methods(http.MethodGet)
+	permissions := PermissionsHandler{service}
+	grant := GrantHandler{service}
+	revoke := RevokeHandler{service}
+
+	subrouter.Handle("", middleware(role.RolesManage)(&create)).Methods(http.MethodPost)
+	subrouter.Handle("/permissions", middleware(role.RolesView)(&permissions)).Methods(http.MethodGet)
+	subrouter.Handle("/{id}", middleware(role.RolesView)(&find)).Methods(http.MethodGet)
+	subrouter.Handle("/{id}", middleware(role.RolesManage)(&update)).Methods(http.MethodPut)
+	subrouter.Handle("/{id}", middleware(role.RolesManage)(&patch)).Methods(http.MethodPatch)
+	subrouter.Handle("/{id}", middleware(role.RolesManage)(&delete)).Methods(http.MethodDelete)
+	subrouter.Handle("/{id}/permissions/{permission}", middleware(role.RolesManage)(&grant)).Methods(http.MethodPut)
+	subrouter.Handle("/{id}/permissions/{permission}", middleware(role.RolesManage)(&revoke)).Methods(http.MethodDelete)
+	subrouter.Handle("", middleware(role.RolesView)(&list)).Methods(http.MethodGet)
 }
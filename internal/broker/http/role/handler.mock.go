@@ -6,6 +6,8 @@ package role
 
 import (
 	context "context"
+	patch "github.com/dipress/crmifc/internal/kit/patch"
+	query "github.com/dipress/crmifc/internal/kit/query"
 	role "github.com/dipress/crmifc/internal/role"
 	gomock "github.com/golang/mock/gomock"
 	http "net/http"
@@ -103,45 +105,104 @@ func (mr *MockServiceMockRecorder) Find(ctx, id interface{}) *gomock.Call {
 }
 
 // Update mocks base method
-func (m *MockService) Update(ctx context.Context, id int, f *role.Form) (*role.Role, error) {
+func (m *MockService) Update(ctx context.Context, id, version int, f *role.Form) (*role.Role, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Update", ctx, id, f)
+	ret := m.ctrl.Call(m, "Update", ctx, id, version, f)
 	ret0, _ := ret[0].(*role.Role)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Update indicates an expected call of Update
-func (mr *MockServiceMockRecorder) Update(ctx, id, f interface{}) *gomock.Call {
+func (mr *MockServiceMockRecorder) Update(ctx, id, version, f interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockService)(nil).Update), ctx, id, f)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockService)(nil).Update), ctx, id, version, f)
+}
+
+// Patch mocks base method
+func (m *MockService) Patch(ctx context.Context, id, version int, p patch.Patch) (*role.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Patch", ctx, id, version, p)
+	ret0, _ := ret[0].(*role.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Patch indicates an expected call of Patch
+func (mr *MockServiceMockRecorder) Patch(ctx, id, version, p interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Patch", reflect.TypeOf((*MockService)(nil).Patch), ctx, id, version, p)
 }
 
 // Delete mocks base method
-func (m *MockService) Delete(ctx context.Context, id int) error {
+func (m *MockService) Delete(ctx context.Context, id, version int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret := m.ctrl.Call(m, "Delete", ctx, id, version)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete
-func (mr *MockServiceMockRecorder) Delete(ctx, id interface{}) *gomock.Call {
+func (mr *MockServiceMockRecorder) Delete(ctx, id, version interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockService)(nil).Delete), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockService)(nil).Delete), ctx, id, version)
 }
 
 // List mocks base method
-func (m *MockService) List(ctx context.Context) (*role.Roles, error) {
+func (m *MockService) List(ctx context.Context, q *query.Query) (*role.Roles, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "List", ctx)
+	ret := m.ctrl.Call(m, "List", ctx, q)
 	ret0, _ := ret[0].(*role.Roles)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // List indicates an expected call of List
-func (mr *MockServiceMockRecorder) List(ctx interface{}) *gomock.Call {
+func (mr *MockServiceMockRecorder) List(ctx, q interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockService)(nil).List), ctx, q)
+}
+
+// Permissions mocks base method
+func (m *MockService) Permissions(ctx context.Context) *role.PermissionList {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Permissions", ctx)
+	ret0, _ := ret[0].(*role.PermissionList)
+	return ret0
+}
+
+// Permissions indicates an expected call of Permissions
+func (mr *MockServiceMockRecorder) Permissions(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Permissions", reflect.TypeOf((*MockService)(nil).Permissions), ctx)
+}
+
+// Grant mocks base method
+func (m *MockService) Grant(ctx context.Context, id int, p role.Permission) (*role.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Grant", ctx, id, p)
+	ret0, _ := ret[0].(*role.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Grant indicates an expected call of Grant
+func (mr *MockServiceMockRecorder) Grant(ctx, id, p interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Grant", reflect.TypeOf((*MockService)(nil).Grant), ctx, id, p)
+}
+
+// Revoke mocks base method
+func (m *MockService) Revoke(ctx context.Context, id int, p role.Permission) (*role.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", ctx, id, p)
+	ret0, _ := ret[0].(*role.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Revoke indicates an expected call of Revoke
+func (mr *MockServiceMockRecorder) Revoke(ctx, id, p interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockService)(nil).List), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockService)(nil).Revoke), ctx, id, p)
 }
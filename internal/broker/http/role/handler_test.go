@@ -7,7 +7,7 @@ import (
 	"strings"
 	"testing"
 
-	"github.com/dipress/crmifc/internal/category"
+	"github.com/dipress/crmifc/internal/kit/query"
 	"github.com/dipress/crmifc/internal/role"
 	"github.com/dipress/crmifc/internal/validation"
 	gomock "github.com/golang/mock/gomock"
@@ -37,7 +37,7 @@ func TestCreateHandler(t *testing.T) {
 		{
 			name: "internl error",
 			serviceFunc: func(m *MockService) {
-				m.EXPECT().Create(gomock.Any(), gomock.Any()).Return(&category.Category{}, errors.New("mock error"))
+				m.EXPECT().Create(gomock.Any(), gomock.Any()).Return(&role.Role{}, errors.New("mock error"))
 			},
 			code: http.StatusInternalServerError,
 		},
@@ -122,21 +122,29 @@ func TestUpdateHandler(t *testing.T) {
 		{
 			name: "ok",
 			serviceFunc: func(m *MockService) {
-				m.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any()).Return(&role.Role{}, nil)
+				m.EXPECT().Update(gomock.Any(), gomock.Any(), 1, gomock.Any()).Return(&role.Role{}, nil)
 			},
 			code: http.StatusOK,
 		},
 		{
 			name: "validation error",
 			serviceFunc: func(m *MockService) {
-				m.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any()).Return(&role.Role{}, make(validation.Errors))
+				m.EXPECT().Update(gomock.Any(), gomock.Any(), 1, gomock.Any()).Return(&role.Role{}, make(validation.Errors))
 			},
 			code: http.StatusUnprocessableEntity,
 		},
+		{
+			name: "version mismatch",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Update(gomock.Any(), gomock.Any(), 1, gomock.Any()).Return(nil, role.ErrVersionMismatch)
+				m.EXPECT().Find(gomock.Any(), 1).Return(&role.Role{Version: 2}, nil)
+			},
+			code: http.StatusPreconditionFailed,
+		},
 		{
 			name: "internal error",
 			serviceFunc: func(m *MockService) {
-				m.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any()).Return(&role.Role{}, errors.New("mock error"))
+				m.EXPECT().Update(gomock.Any(), gomock.Any(), 1, gomock.Any()).Return(&role.Role{}, errors.New("mock error"))
 			},
 			code: http.StatusInternalServerError,
 		},
@@ -156,6 +164,7 @@ func TestUpdateHandler(t *testing.T) {
 			w := httptest.NewRecorder()
 			r := httptest.NewRequest(http.MethodPut, "http://example.com", strings.NewReader("{}"))
 			r = mux.SetURLVars(r, map[string]string{"id": "1"})
+			r.Header.Set("If-Match", `"1"`)
 
 			err := h.Handle(w, r)
 			if w.Code != tc.code {
@@ -174,17 +183,39 @@ func TestDeleteHandler(t *testing.T) {
 		{
 			name: "ok",
 			serviceFunc: func(m *MockService) {
-				m.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(nil)
+				m.EXPECT().Delete(gomock.Any(), gomock.Any(), 1).Return(nil)
 			},
 			code: http.StatusOK,
 		},
+		{
+			name: "not found",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Delete(gomock.Any(), gomock.Any(), 1).Return(role.ErrNotFound)
+			},
+			code: http.StatusNotFound,
+		},
+		{
+			name: "in use",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Delete(gomock.Any(), gomock.Any(), 1).Return(role.ErrInUse)
+			},
+			code: http.StatusConflict,
+		},
 		{
 			name: "repository error",
 			serviceFunc: func(m *MockService) {
-				m.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(errors.New("mock error"))
+				m.EXPECT().Delete(gomock.Any(), gomock.Any(), 1).Return(errors.New("mock error"))
 			},
 			code: http.StatusInternalServerError,
 		},
+		{
+			name: "version mismatch",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Delete(gomock.Any(), gomock.Any(), 1).Return(role.ErrVersionMismatch)
+				m.EXPECT().Find(gomock.Any(), 1).Return(&role.Role{Version: 2}, nil)
+			},
+			code: http.StatusPreconditionFailed,
+		},
 	}
 
 	for _, tc := range tests {
@@ -202,6 +233,7 @@ func TestDeleteHandler(t *testing.T) {
 			w := httptest.NewRecorder()
 			r := httptest.NewRequest(http.MethodDelete, "http://example.com", strings.NewReader("{}"))
 			r = mux.SetURLVars(r, map[string]string{"id": "1"})
+			r.Header.Set("If-Match", `"1"`)
 
 			err := h.Handle(w, r)
 			if w.Code != tc.code {
@@ -214,23 +246,37 @@ func TestDeleteHandler(t *testing.T) {
 func TestListHandler(t *testing.T) {
 	tests := []struct {
 		name        string
+		query       string
 		serviceFunc func(mock *MockService)
 		code        int
 	}{
 		{
 			name: "ok",
 			serviceFunc: func(m *MockService) {
-				m.EXPECT().List(gomock.Any()).Return(&role.Roles{}, nil)
+				m.EXPECT().List(gomock.Any(), gomock.Any()).Return(&role.Roles{}, nil)
 			},
 			code: http.StatusOK,
 		},
 		{
 			name: "repository error",
 			serviceFunc: func(m *MockService) {
-				m.EXPECT().List(gomock.Any()).Return(&role.Roles{}, errors.New("mock error"))
+				m.EXPECT().List(gomock.Any(), gomock.Any()).Return(&role.Roles{}, errors.New("mock error"))
 			},
 			code: http.StatusInternalServerError,
 		},
+		{
+			name:        "invalid query",
+			query:       "?limit=-1",
+			serviceFunc: func(m *MockService) {},
+			code:        http.StatusBadRequest,
+		},
+		{
+			name: "invalid sort",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().List(gomock.Any(), gomock.Any()).Return(&role.Roles{}, query.ErrInvalidSort)
+			},
+			code: http.StatusBadRequest,
+		},
 	}
 
 	for _, tc := range tests {
@@ -246,7 +292,7 @@ func TestListHandler(t *testing.T) {
 
 			h := ListHandler{service}
 			w := httptest.NewRecorder()
-			r := httptest.NewRequest(http.MethodGet, "http://example.com", strings.NewReader("{}"))
+			r := httptest.NewRequest(http.MethodGet, "http://example.com"+tc.query, strings.NewReader("{}"))
 
 			err := h.Handle(w, r)
 			if w.Code != tc.code {
@@ -255,3 +301,137 @@ func TestListHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestGrantHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		serviceFunc func(mock *MockService)
+		code        int
+	}{
+		{
+			name: "ok",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Grant(gomock.Any(), 1, role.ArticlesCreate).Return(&role.Role{}, nil)
+			},
+			code: http.StatusOK,
+		},
+		{
+			name: "unknown permission",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Grant(gomock.Any(), 1, role.ArticlesCreate).Return(nil, role.ErrUnknownPermission)
+			},
+			code: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "not found",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Grant(gomock.Any(), 1, role.ArticlesCreate).Return(nil, role.ErrNotFound)
+			},
+			code: http.StatusNotFound,
+		},
+		{
+			name: "internal error",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Grant(gomock.Any(), 1, role.ArticlesCreate).Return(nil, errors.New("mock error"))
+			},
+			code: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := NewMockService(ctrl)
+			tc.serviceFunc(service)
+
+			h := GrantHandler{service}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPut, "http://example.com", nil)
+			r = mux.SetURLVars(r, map[string]string{"id": "1", "permission": string(role.ArticlesCreate)})
+
+			err := h.Handle(w, r)
+			if w.Code != tc.code {
+				t.Errorf("unexpected code: %d expected %d error: %v", w.Code, tc.code, err)
+			}
+		})
+	}
+}
+
+func TestRevokeHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		serviceFunc func(mock *MockService)
+		code        int
+	}{
+		{
+			name: "ok",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Revoke(gomock.Any(), 1, role.ArticlesDelete).Return(&role.Role{}, nil)
+			},
+			code: http.StatusOK,
+		},
+		{
+			name: "not found",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Revoke(gomock.Any(), 1, role.ArticlesDelete).Return(nil, role.ErrNotFound)
+			},
+			code: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := NewMockService(ctrl)
+			tc.serviceFunc(service)
+
+			h := RevokeHandler{service}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodDelete, "http://example.com", nil)
+			r = mux.SetURLVars(r, map[string]string{"id": "1", "permission": string(role.ArticlesDelete)})
+
+			err := h.Handle(w, r)
+			if w.Code != tc.code {
+				t.Errorf("unexpected code: %d expected %d error: %v", w.Code, tc.code, err)
+			}
+		})
+	}
+}
+
+func TestIfMatchRequired(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler Handler
+		method  string
+	}{
+		{name: "update", handler: &UpdateHandler{}, method: http.MethodPut},
+		{name: "patch", handler: &PatchHandler{}, method: http.MethodPatch},
+		{name: "delete", handler: &DeleteHandler{}, method: http.MethodDelete},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(tc.method, "http://example.com", strings.NewReader("{}"))
+			r = mux.SetURLVars(r, map[string]string{"id": "1"})
+
+			err := tc.handler.Handle(w, r)
+			if w.Code != http.StatusPreconditionRequired {
+				t.Errorf("unexpected code: %d expected %d error: %v", w.Code, http.StatusPreconditionRequired, err)
+			}
+		})
+	}
+}
@@ -8,17 +8,23 @@ import (
 	"github.com/gorilla/mux"
 
 	"github.com/dipress/crmifc/internal/abillity"
+	"github.com/dipress/crmifc/internal/apikey"
 	"github.com/dipress/crmifc/internal/article"
 	"github.com/dipress/crmifc/internal/auth"
+	apikeyHandlers "github.com/dipress/crmifc/internal/broker/http/apikey"
 	articleHandlers "github.com/dipress/crmifc/internal/broker/http/article"
 	authHandlers "github.com/dipress/crmifc/internal/broker/http/auth"
 	categoryHandlers "github.com/dipress/crmifc/internal/broker/http/category"
 	"github.com/dipress/crmifc/internal/broker/http/handler"
+	invitationHandlers "github.com/dipress/crmifc/internal/broker/http/invitation"
 	roleHandlers "github.com/dipress/crmifc/internal/broker/http/role"
+	sessionHandlers "github.com/dipress/crmifc/internal/broker/http/session"
 	userHandlers "github.com/dipress/crmifc/internal/broker/http/user"
 	"github.com/dipress/crmifc/internal/category"
+	"github.com/dipress/crmifc/internal/invitation"
 	authEng "github.com/dipress/crmifc/internal/kit/auth"
 	"github.com/dipress/crmifc/internal/role"
+	"github.com/dipress/crmifc/internal/session"
 	"github.com/dipress/crmifc/internal/user"
 )
 
@@ -28,11 +34,14 @@ const (
 
 // Services contains all the services.
 type Services struct {
-	Auth     *auth.Service
-	Article  *article.Service
-	Category *category.Service
-	Role     *role.Service
-	User     *user.Service
+	Auth       *auth.Service
+	APIKey     *apikey.Service
+	Article    *article.Service
+	Category   *category.Service
+	Invitation *invitation.Service
+	Role       *role.Service
+	Session    *session.Service
+	User       *user.Service
 }
 
 // NewServer prepare http server to work.
@@ -44,26 +53,116 @@ func NewServer(addr string, services *Services, authenticator *authEng.Authentic
 		Authenticater: services.Auth,
 	}
 
+	refreshHandler := authHandlers.RefreshHandler{
+		Refresher: services.Auth,
+	}
+
+	signOutHandler := authHandlers.SignOutHandler{
+		SignOuter: services.Auth,
+	}
+
+	unlockHandler := authHandlers.UnlockHandler{
+		Unlocker: services.Auth,
+	}
+
+	mfaHandler := authHandlers.MFAHandler{
+		MFAVerifier: services.Auth,
+	}
+
+	pendingEnrollHandler := authHandlers.PendingEnrollHandler{
+		PendingEnroller: services.Auth,
+	}
+
+	enrollHandler := authHandlers.EnrollHandler{
+		Enroller: services.Auth,
+	}
+
+	confirmHandler := authHandlers.ConfirmHandler{
+		Confirmer: services.Auth,
+	}
+
+	disableMFAHandler := authHandlers.DisableMFAHandler{
+		MFADisabler: services.Auth,
+	}
+
+	oidcLoginHandler := authHandlers.OIDCLoginHandler{
+		OIDCLoginer: services.Auth,
+	}
+
+	oidcCallbackHandler := authHandlers.OIDCCallbackHandler{
+		OIDCCallbacker: services.Auth,
+	}
+
+	jwksHandler := authHandlers.JWKSHandler{
+		KeyPublisher: authenticator,
+	}
+
 	base := handler.NewChain(contentTypeMiddleware)
+	authorized := base.Append(authMiddleware(authenticator, services.APIKey))
+	interactive := authorized.Append(interactiveOnly)
 
-	// Auth route.
+	// Auth routes.
 	mux.Handle("/signin", finalizeMiddleware(base)(&authenticateHandler)).Methods(http.MethodPost)
-
-	authorized := base.Append(authMiddleware(authenticator))
+	mux.Handle("/token/refresh", finalizeMiddleware(base)(&refreshHandler)).Methods(http.MethodPost)
+	mux.Handle("/signout", finalizeMiddleware(interactive)(&signOutHandler)).Methods(http.MethodPost)
+	mux.Handle("/signin/mfa", finalizeMiddleware(base)(&mfaHandler)).Methods(http.MethodPost)
+	mux.Handle("/signin/mfa/enroll", finalizeMiddleware(base)(&pendingEnrollHandler)).Methods(http.MethodPost)
+	mux.Handle("/mfa/enroll", finalizeMiddleware(interactive)(&enrollHandler)).Methods(http.MethodPost)
+	mux.Handle("/mfa/verify", finalizeMiddleware(interactive)(&confirmHandler)).Methods(http.MethodPost)
+	mux.Handle("/mfa", finalizeMiddleware(interactive)(&disableMFAHandler)).Methods(http.MethodDelete)
+	mux.Handle("/auth/oidc/login", finalizeMiddleware(base)(&oidcLoginHandler)).Methods(http.MethodGet)
+	mux.Handle("/auth/oidc/callback", finalizeMiddleware(base)(&oidcCallbackHandler)).Methods(http.MethodGet)
+	mux.Handle("/.well-known/jwks.json", finalizeMiddleware(base)(&jwksHandler)).Methods(http.MethodGet)
+
+	can := permissions(authorized, abillity.UserAbillity{})
 
 	articles := mux.PathPrefix("/articles").Subrouter()
-	articleHandlers.Prepare(articles, services.Article, finalizeMiddleware(authorized))
+	articleHandlers.Prepare(articles, services.Article, can)
 
 	categories := mux.PathPrefix("/categories").Subrouter()
-	categoryHandlers.Prepare(categories, services.Category, finalizeMiddleware(authorized))
-
-	admin := authorized.Append(adminMiddleware(abillity.UserAbillity{}))
+	categoryHandlers.Prepare(categories, services.Category, can)
 
 	roles := mux.PathPrefix("/roles").Subrouter()
-	roleHandlers.Prepare(roles, services.Role, finalizeMiddleware(admin))
+	roleHandlers.Prepare(roles, services.Role, can)
+
+	// Registered before the users subrouter which
+	// would take all the paths under its prefix.
+	mux.Handle("/users/{id}/unlock", can(role.UsersManage)(&unlockHandler)).Methods(http.MethodPost)
 
 	users := mux.PathPrefix("/users").Subrouter()
-	userHandlers.Prepare(users, services.User, finalizeMiddleware(admin))
+	userHandlers.Prepare(users, services.User, can)
+
+	trashUsers := mux.PathPrefix("/trash/users").Subrouter()
+	userHandlers.PrepareTrash(trashUsers, services.User, can)
+
+	trashArticles := mux.PathPrefix("/trash/articles").Subrouter()
+	articleHandlers.PrepareTrash(trashArticles, services.Article, can)
+
+	trashCategories := mux.PathPrefix("/trash/categories").Subrouter()
+	categoryHandlers.PrepareTrash(trashCategories, services.Category, can)
+
+	invitations := mux.PathPrefix("/invitations").Subrouter()
+	invitationHandlers.Prepare(invitations, services.Invitation, can, finalizeMiddleware(base))
+
+	sessions := mux.PathPrefix("/sessions").Subrouter()
+	sessionHandlers.PrepareAdmin(sessions, services.Session, can)
+
+	loginEvents := mux.PathPrefix("/login-events").Subrouter()
+	sessionHandlers.PrepareEvents(loginEvents, services.Session, can)
+
+	// Registered before the me subrouter which
+	// would take all the paths under its prefix.
+	tokens := mux.PathPrefix("/me/tokens").Subrouter()
+	apikeyHandlers.Prepare(tokens, services.APIKey, finalizeMiddleware(authorized))
+
+	mySessions := mux.PathPrefix("/me/sessions").Subrouter()
+	sessionHandlers.Prepare(mySessions, services.Session, finalizeMiddleware(interactive))
+
+	me := mux.PathPrefix("/me").Subrouter()
+	userHandlers.PrepareMe(me, services.User, finalizeMiddleware(authorized), finalizeMiddleware(interactive))
+
+	password := mux.PathPrefix("/password").Subrouter()
+	userHandlers.PreparePassword(password, services.User, finalizeMiddleware(base))
 
 	s := http.Server{
 		Addr:         addr,
@@ -75,6 +174,16 @@ func NewServer(addr string, services *Services, authenticator *authEng.Authentic
 	return &s
 }
 
+// permissions returns the middleware factory which allows requests
+// only for authorized users granted with any of given permissions.
+func permissions(authorized handler.Chain, a Abillity) func(...role.Permission) func(handler.Handler) http.Handler {
+	f := func(permissions ...role.Permission) func(handler.Handler) http.Handler {
+		return finalizeMiddleware(authorized.Append(permissionMiddleware(a, permissions...)))
+	}
+
+	return f
+}
+
 func finalizeMiddleware(middleware handler.Chain) func(handler.Handler) http.Handler {
 	f := func(handler handler.Handler) http.Handler {
 		wrapped := middleware.Then(handler)
@@ -0,0 +1,209 @@
+package session
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/dipress/crmifc/internal/broker/http/handler"
+	"github.com/dipress/crmifc/internal/broker/http/request"
+	"github.com/dipress/crmifc/internal/broker/http/response"
+	"github.com/dipress/crmifc/internal/kit/auth"
+	"github.com/dipress/crmifc/internal/kit/query"
+	"github.com/dipress/crmifc/internal/role"
+	"github.com/dipress/crmifc/internal/session"
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+)
+
+// go:generate mockgen -source=handler.go -package=session -destination=handler.mock.go Service
+
+// Handler allows to handle requests.
+type Handler interface {
+	Handle(w http.ResponseWriter, r *http.Request) error
+}
+
+// Service contains all services.
+type Service interface {
+	List(ctx context.Context, c *auth.Claims) (*session.Sessions, error)
+	Delete(ctx context.Context, c *auth.Claims, id int) error
+	ListAll(ctx context.Context, q *query.Query) (*session.Sessions, error)
+	Revoke(ctx context.Context, id int) error
+	LoginEvents(ctx context.Context, q *query.Query) (*session.LoginEvents, error)
+}
+
+// ListHandler for the current user sessions requests.
+type ListHandler struct {
+	Service
+}
+
+// Handle implements Handler interface.
+func (h *ListHandler) Handle(w http.ResponseWriter, r *http.Request) error {
+	claims, ok := auth.FromContext(r.Context())
+	if !ok {
+		return errors.Wrap(response.UnauthorizedResponse(w), "claims from context")
+	}
+
+	sessions, err := h.List(r.Context(), claims)
+	if err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "list sessions")
+	}
+
+	data, err := sessions.MarshalJSON()
+	if err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "marshal json")
+	}
+
+	if _, err := w.Write(data); err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "write response")
+	}
+
+	return nil
+}
+
+// DeleteHandler for the current user session revoke requests.
+type DeleteHandler struct {
+	Service
+}
+
+// Handle implements Handler interface.
+func (h *DeleteHandler) Handle(w http.ResponseWriter, r *http.Request) error {
+	claims, ok := auth.FromContext(r.Context())
+	if !ok {
+		return errors.Wrap(response.UnauthorizedResponse(w), "claims from context")
+	}
+
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		return errors.Wrapf(response.BadRequestResponse(w), "convert id query param to int: %v", err)
+	}
+
+	if err := h.Delete(r.Context(), claims, id); err != nil {
+		switch errors.Cause(err) {
+		case session.ErrNotFound:
+			return errors.Wrap(response.NotFoundResponse(w), "delete session")
+		default:
+			return errors.Wrap(response.InternalServerErrorResponse(w), "delete session")
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// AllHandler for the sessions of all users requests.
+type AllHandler struct {
+	Service
+}
+
+// Handle implements Handler interface.
+func (h *AllHandler) Handle(w http.ResponseWriter, r *http.Request) error {
+	q, err := request.ParseQuery(r)
+	if err != nil {
+		return errors.Wrapf(response.BadRequestResponse(w), "parse query: %v", err)
+	}
+
+	sessions, err := h.ListAll(r.Context(), q)
+	if err != nil {
+		if query.IsInvalid(errors.Cause(err)) {
+			return errors.Wrapf(response.BadRequestResponse(w), "list query: %v", err)
+		}
+		return errors.Wrap(response.InternalServerErrorResponse(w), "list of sessions")
+	}
+
+	data, err := sessions.MarshalJSON()
+	if err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "marshal json")
+	}
+
+	if _, err := w.Write(data); err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "write response")
+	}
+
+	return nil
+}
+
+// RevokeHandler for any user session revoke requests.
+type RevokeHandler struct {
+	Service
+}
+
+// Handle implements Handler interface.
+func (h *RevokeHandler) Handle(w http.ResponseWriter, r *http.Request) error {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		return errors.Wrapf(response.BadRequestResponse(w), "convert id query param to int: %v", err)
+	}
+
+	if err := h.Revoke(r.Context(), id); err != nil {
+		switch errors.Cause(err) {
+		case session.ErrNotFound:
+			return errors.Wrap(response.NotFoundResponse(w), "revoke session")
+		default:
+			return errors.Wrap(response.InternalServerErrorResponse(w), "revoke session")
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// EventsHandler for sign in history requests.
+type EventsHandler struct {
+	Service
+}
+
+// Handle implements Handler interface.
+func (h *EventsHandler) Handle(w http.ResponseWriter, r *http.Request) error {
+	q, err := request.ParseQuery(r)
+	if err != nil {
+		return errors.Wrapf(response.BadRequestResponse(w), "parse query: %v", err)
+	}
+
+	events, err := h.LoginEvents(r.Context(), q)
+	if err != nil {
+		if query.IsInvalid(errors.Cause(err)) {
+			return errors.Wrapf(response.BadRequestResponse(w), "list query: %v", err)
+		}
+		return errors.Wrap(response.InternalServerErrorResponse(w), "list of login events")
+	}
+
+	data, err := events.MarshalJSON()
+	if err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "marshal json")
+	}
+
+	if _, err := w.Write(data); err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "write response")
+	}
+
+	return nil
+}
+
+// Prepare prepares routes of the sessions of the current
+// user, which are available for any authorized user.
+func Prepare(subrouter *mux.Router, service Service, middleware func(handler.Handler) http.Handler) {
+	list := ListHandler{service}
+	delete := DeleteHandler{service}
+
+	subrouter.Handle("", middleware(&list)).Methods(http.MethodGet)
+	subrouter.Handle("/{id:[0-9]+}", middleware(&delete)).Methods(http.MethodDelete)
+}
+
+// PrepareAdmin prepares routes of the sessions of all users.
+func PrepareAdmin(subrouter *mux.Router, service Service, middleware func(permissions ...role.Permission) func(handler.Handler) http.Handler) {
+	all := AllHandler{service}
+	revoke := RevokeHandler{service}
+
+	subrouter.Handle("", middleware(role.UsersManage)(&all)).Methods(http.MethodGet)
+	subrouter.Handle("/{id:[0-9]+}", middleware(role.UsersManage)(&revoke)).Methods(http.MethodDelete)
+}
+
+// PrepareEvents prepares routes of the sign in history.
+func PrepareEvents(subrouter *mux.Router, service Service, middleware func(permissions ...role.Permission) func(handler.Handler) http.Handler) {
+	events := EventsHandler{service}
+
+	subrouter.Handle("", middleware(role.UsersManage)(&events)).Methods(http.MethodGet)
+}
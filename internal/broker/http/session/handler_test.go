@@ -0,0 +1,184 @@
+package session
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dipress/crmifc/internal/kit/auth"
+	"github.com/dipress/crmifc/internal/kit/query"
+	"github.com/dipress/crmifc/internal/session"
+	"github.com/dipress/crmifc/internal/user"
+	gomock "github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+)
+
+func TestListHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		claims      bool
+		serviceFunc func(mock *MockService)
+		code        int
+	}{
+		{
+			name:   "ok",
+			claims: true,
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().List(gomock.Any(), gomock.Any()).Return(&session.Sessions{}, nil)
+			},
+			code: http.StatusOK,
+		},
+		{
+			name:        "without claims",
+			serviceFunc: func(m *MockService) {},
+			code:        http.StatusUnauthorized,
+		},
+		{
+			name:   "internal error",
+			claims: true,
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().List(gomock.Any(), gomock.Any()).Return(nil, errors.New("mock error"))
+			},
+			code: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := NewMockService(ctrl)
+			tc.serviceFunc(service)
+
+			h := ListHandler{service}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "http://example.com", nil)
+			if tc.claims {
+				r = r.WithContext(auth.ToContext(r.Context(), &auth.Claims{User: user.User{ID: 1}}))
+			}
+
+			err := h.Handle(w, r)
+			if w.Code != tc.code {
+				t.Errorf("unexpected code: %d expected %d error: %v", w.Code, tc.code, err)
+			}
+		})
+	}
+}
+
+func TestDeleteHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		serviceFunc func(mock *MockService)
+		code        int
+	}{
+		{
+			name: "ok",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Delete(gomock.Any(), gomock.Any(), 2).Return(nil)
+			},
+			code: http.StatusNoContent,
+		},
+		{
+			name: "not found",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Delete(gomock.Any(), gomock.Any(), 2).Return(session.ErrNotFound)
+			},
+			code: http.StatusNotFound,
+		},
+		{
+			name: "internal error",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Delete(gomock.Any(), gomock.Any(), 2).Return(errors.New("mock error"))
+			},
+			code: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := NewMockService(ctrl)
+			tc.serviceFunc(service)
+
+			h := DeleteHandler{service}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("DELETE", "http://example.com", nil)
+			r = mux.SetURLVars(r, map[string]string{"id": "2"})
+			r = r.WithContext(auth.ToContext(r.Context(), &auth.Claims{User: user.User{ID: 1}}))
+
+			err := h.Handle(w, r)
+			if w.Code != tc.code {
+				t.Errorf("unexpected code: %d expected %d error: %v", w.Code, tc.code, err)
+			}
+		})
+	}
+}
+
+func TestEventsHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		url         string
+		serviceFunc func(mock *MockService)
+		code        int
+	}{
+		{
+			name: "ok",
+			url:  "http://example.com?filter[email]=username@example.com",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().LoginEvents(gomock.Any(), gomock.Any()).Return(&session.LoginEvents{}, nil)
+			},
+			code: http.StatusOK,
+		},
+		{
+			name:        "bad query",
+			url:         "http://example.com?limit=abc",
+			serviceFunc: func(m *MockService) {},
+			code:        http.StatusBadRequest,
+		},
+		{
+			name: "invalid filter",
+			url:  "http://example.com?filter[user_agent]=agent",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().LoginEvents(gomock.Any(), gomock.Any()).Return(nil, query.ErrInvalidFilter)
+			},
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "internal error",
+			url:  "http://example.com",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().LoginEvents(gomock.Any(), gomock.Any()).Return(nil, errors.New("mock error"))
+			},
+			code: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := NewMockService(ctrl)
+			tc.serviceFunc(service)
+
+			h := EventsHandler{service}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", tc.url, nil)
+
+			err := h.Handle(w, r)
+			if w.Code != tc.code {
+				t.Errorf("unexpected code: %d expected %d error: %v", w.Code, tc.code, err)
+			}
+		})
+	}
+}
@@ -7,7 +7,12 @@ import (
 	"strconv"
 
 	"github.com/dipress/crmifc/internal/broker/http/handler"
+	"github.com/dipress/crmifc/internal/broker/http/request"
 	"github.com/dipress/crmifc/internal/broker/http/response"
+	"github.com/dipress/crmifc/internal/kit/auth"
+	"github.com/dipress/crmifc/internal/kit/patch"
+	"github.com/dipress/crmifc/internal/kit/query"
+	"github.com/dipress/crmifc/internal/role"
 	"github.com/dipress/crmifc/internal/user"
 	"github.com/dipress/crmifc/internal/validation"
 	"github.com/gorilla/mux"
@@ -25,9 +30,19 @@ type Handler interface {
 type Service interface {
 	Create(ctx context.Context, f *user.Form, u *user.User) error
 	Find(ctx context.Context, id int) (*user.User, error)
-	Update(ctx context.Context, id int, f *user.Form) (*user.User, error)
-	Delete(ctx context.Context, id int) error
-	List(ctx context.Context) (*user.Users, error)
+	Update(ctx context.Context, id, version int, f *user.Form) (*user.User, error)
+	Patch(ctx context.Context, id, version int, p patch.Patch) (*user.User, error)
+	Delete(ctx context.Context, id, version int) error
+	List(ctx context.Context, q *query.Query) (*user.Users, error)
+	ForgotPassword(ctx context.Context, f *user.ForgotForm) error
+	ResetPassword(ctx context.Context, f *user.ResetForm) error
+	UpdateProfile(ctx context.Context, id int, f *user.ProfileForm) (*user.User, error)
+	ChangePassword(ctx context.Context, id, sessionID int, f *user.PasswordForm) error
+	Deactivate(ctx context.Context, id int) error
+	Activate(ctx context.Context, id int) error
+	Trash(ctx context.Context, q *query.Query) (*user.Users, error)
+	Undelete(ctx context.Context, id int) error
+	Purge(ctx context.Context, id int) error
 }
 
 // CreateHandler for  user create requests.
@@ -53,6 +68,18 @@ func (h *CreateHandler) Handle(w http.ResponseWriter, r *http.Request) error {
 		switch v := errors.Cause(err).(type) {
 		case validation.Errors:
 			return errors.Wrap(response.UnprocessabeEntityResponse(w, v), "validation response")
+		}
+
+		switch errors.Cause(err) {
+		case user.ErrUsernameExists:
+			ves := validation.Errors{"username": "already exists"}
+			return errors.Wrap(response.UnprocessabeEntityResponse(w, ves), "create user")
+		case user.ErrEmailExists:
+			ves := validation.Errors{"email": "already exists"}
+			return errors.Wrap(response.UnprocessabeEntityResponse(w, ves), "create user")
+		case user.ErrRoleNotFound:
+			ves := validation.Errors{"role_id": "does not exist"}
+			return errors.Wrap(response.UnprocessabeEntityResponse(w, ves), "create user")
 		default:
 			return errors.Wrap(response.InternalServerErrorResponse(w), "create user")
 		}
@@ -63,6 +90,8 @@ func (h *CreateHandler) Handle(w http.ResponseWriter, r *http.Request) error {
 		return errors.Wrap(response.InternalServerErrorResponse(w), "marshal json")
 	}
 
+	response.ETag(w, u.Version)
+
 	if _, err := w.Write(data); err != nil {
 		return errors.Wrap(response.InternalServerErrorResponse(w), "write response")
 	}
@@ -98,6 +127,8 @@ func (h FindHandler) Handle(w http.ResponseWriter, r *http.Request) error {
 		return errors.Wrap(response.InternalServerErrorResponse(w), "marshal json")
 	}
 
+	response.ETag(w, u.Version)
+
 	if _, err := w.Write(data); err != nil {
 		return errors.Wrap(response.InternalServerErrorResponse(w), "write response")
 	}
@@ -118,6 +149,11 @@ func (h UpdateHandler) Handle(w http.ResponseWriter, r *http.Request) error {
 		return errors.Wrapf(response.BadRequestResponse(w), "convert id query param to int: %v", err)
 	}
 
+	version, err := request.IfMatch(r)
+	if err != nil {
+		return errors.Wrap(response.PreconditionRequiredResponse(w), "if match")
+	}
+
 	var f user.Form
 	data, err := ioutil.ReadAll(r.Body)
 	if err != nil {
@@ -128,11 +164,25 @@ func (h UpdateHandler) Handle(w http.ResponseWriter, r *http.Request) error {
 		return errors.Wrap(response.BadRequestResponse(w), "unmarshal json")
 	}
 
-	u, err := h.Update(r.Context(), id, &f)
+	u, err := h.Update(r.Context(), id, version, &f)
 	if err != nil {
 		switch v := errors.Cause(err).(type) {
 		case validation.Errors:
 			return errors.Wrap(response.UnprocessabeEntityResponse(w, v), "validation response")
+		}
+
+		switch errors.Cause(err) {
+		case user.ErrUsernameExists:
+			ves := validation.Errors{"username": "already exists"}
+			return errors.Wrap(response.UnprocessabeEntityResponse(w, ves), "update user")
+		case user.ErrEmailExists:
+			ves := validation.Errors{"email": "already exists"}
+			return errors.Wrap(response.UnprocessabeEntityResponse(w, ves), "update user")
+		case user.ErrRoleNotFound:
+			ves := validation.Errors{"role_id": "does not exist"}
+			return errors.Wrap(response.UnprocessabeEntityResponse(w, ves), "update user")
+		case user.ErrVersionMismatch:
+			return errors.Wrap(preconditionFailed(r.Context(), w, h.Service, id), "update user")
 		default:
 			return errors.Wrap(response.InternalServerErrorResponse(w), "update user")
 		}
@@ -143,6 +193,73 @@ func (h UpdateHandler) Handle(w http.ResponseWriter, r *http.Request) error {
 		return errors.Wrap(response.InternalServerErrorResponse(w), "marshal json")
 	}
 
+	response.ETag(w, u.Version)
+
+	if _, err := w.Write(data); err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "write response")
+	}
+
+	return nil
+}
+
+// PatchHandler for user partial update requests.
+type PatchHandler struct {
+	Service
+}
+
+// Handle implements Handler interface.
+func (h PatchHandler) Handle(w http.ResponseWriter, r *http.Request) error {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		return errors.Wrapf(response.BadRequestResponse(w), "convert id query param to int: %v", err)
+	}
+
+	version, err := request.IfMatch(r)
+	if err != nil {
+		return errors.Wrap(response.PreconditionRequiredResponse(w), "if match")
+	}
+
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return errors.Wrap(response.BadRequestResponse(w), "read body")
+	}
+
+	u, err := h.Patch(r.Context(), id, version, patch.Patch(data))
+	if err != nil {
+		switch v := errors.Cause(err).(type) {
+		case validation.Errors:
+			return errors.Wrap(response.UnprocessabeEntityResponse(w, v), "validation response")
+		}
+
+		switch errors.Cause(err) {
+		case patch.ErrInvalid:
+			return errors.Wrap(response.BadRequestResponse(w), "apply patch")
+		case user.ErrUsernameExists:
+			ves := validation.Errors{"username": "already exists"}
+			return errors.Wrap(response.UnprocessabeEntityResponse(w, ves), "patch user")
+		case user.ErrEmailExists:
+			ves := validation.Errors{"email": "already exists"}
+			return errors.Wrap(response.UnprocessabeEntityResponse(w, ves), "patch user")
+		case user.ErrRoleNotFound:
+			ves := validation.Errors{"role_id": "does not exist"}
+			return errors.Wrap(response.UnprocessabeEntityResponse(w, ves), "patch user")
+		case user.ErrNotFound:
+			return errors.Wrap(response.NotFoundResponse(w), "patch user")
+		case user.ErrVersionMismatch:
+			return errors.Wrap(preconditionFailed(r.Context(), w, h.Service, id), "patch user")
+		default:
+			return errors.Wrap(response.InternalServerErrorResponse(w), "patch user")
+		}
+	}
+
+	data, err = u.MarshalJSON()
+	if err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "marshal json")
+	}
+
+	response.ETag(w, u.Version)
+
 	if _, err := w.Write(data); err != nil {
 		return errors.Wrap(response.InternalServerErrorResponse(w), "write response")
 	}
@@ -164,8 +281,20 @@ func (u *DeleteHandler) Handle(w http.ResponseWriter, r *http.Request) error {
 		return errors.Wrapf(response.BadRequestResponse(w), "convert id query param to int: %v", err)
 	}
 
-	if err := u.Delete(r.Context(), id); err != nil {
-		return errors.Wrap(response.InternalServerErrorResponse(w), "delete user")
+	version, err := request.IfMatch(r)
+	if err != nil {
+		return errors.Wrap(response.PreconditionRequiredResponse(w), "if match")
+	}
+
+	if err := u.Delete(r.Context(), id, version); err != nil {
+		switch errors.Cause(err) {
+		case user.ErrNotFound:
+			return errors.Wrap(response.NotFoundResponse(w), "delete user")
+		case user.ErrVersionMismatch:
+			return errors.Wrap(preconditionFailed(r.Context(), w, u.Service, id), "delete user")
+		default:
+			return errors.Wrap(response.InternalServerErrorResponse(w), "delete user")
+		}
 	}
 
 	return nil
@@ -178,8 +307,16 @@ type ListHandler struct {
 
 // Handle implements Handler interface.
 func (h *ListHandler) Handle(w http.ResponseWriter, r *http.Request) error {
-	users, err := h.List(r.Context())
+	q, err := request.ParseQuery(r)
 	if err != nil {
+		return errors.Wrapf(response.BadRequestResponse(w), "parse query: %v", err)
+	}
+
+	users, err := h.List(r.Context(), q)
+	if err != nil {
+		if query.IsInvalid(errors.Cause(err)) {
+			return errors.Wrapf(response.BadRequestResponse(w), "list query: %v", err)
+		}
 		return errors.Wrap(response.InternalServerErrorResponse(w), "list of users")
 	}
 
@@ -195,17 +332,414 @@ func (h *ListHandler) Handle(w http.ResponseWriter, r *http.Request) error {
 	return nil
 }
 
+// ForgotPasswordHandler for forgot password requests.
+type ForgotPasswordHandler struct {
+	Service
+}
+
+// Handle implements Handler interface.
+func (h ForgotPasswordHandler) Handle(w http.ResponseWriter, r *http.Request) error {
+	var f user.ForgotForm
+
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return errors.Wrap(response.BadRequestResponse(w), "read body")
+	}
+
+	if err := f.UnmarshalJSON(data); err != nil {
+		return errors.Wrap(response.BadRequestResponse(w), "unmarshal json")
+	}
+
+	if err := h.ForgotPassword(r.Context(), &f); err != nil {
+		switch v := errors.Cause(err).(type) {
+		case validation.Errors:
+			return errors.Wrap(response.UnprocessabeEntityResponse(w, v), "validation response")
+		default:
+			return errors.Wrap(response.InternalServerErrorResponse(w), "forgot password")
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// ResetPasswordHandler for reset password requests.
+type ResetPasswordHandler struct {
+	Service
+}
+
+// Handle implements Handler interface.
+func (h ResetPasswordHandler) Handle(w http.ResponseWriter, r *http.Request) error {
+	var f user.ResetForm
+
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return errors.Wrap(response.BadRequestResponse(w), "read body")
+	}
+
+	if err := f.UnmarshalJSON(data); err != nil {
+		return errors.Wrap(response.BadRequestResponse(w), "unmarshal json")
+	}
+
+	if err := h.ResetPassword(r.Context(), &f); err != nil {
+		switch v := errors.Cause(err).(type) {
+		case validation.Errors:
+			return errors.Wrap(response.UnprocessabeEntityResponse(w, v), "validation response")
+		}
+
+		switch errors.Cause(err) {
+		case user.ErrInvalidResetToken:
+			ves := validation.Errors{"token": "is invalid or expired"}
+			return errors.Wrap(response.UnprocessabeEntityResponse(w, ves), "reset password")
+		default:
+			return errors.Wrap(response.InternalServerErrorResponse(w), "reset password")
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// MeHandler for current user requests.
+type MeHandler struct {
+	Service
+}
+
+// Handle implements Handler interface.
+func (h MeHandler) Handle(w http.ResponseWriter, r *http.Request) error {
+	claims, ok := auth.FromContext(r.Context())
+	if !ok {
+		return errors.Wrap(response.UnauthorizedResponse(w), "claims from context")
+	}
+
+	u, err := h.Find(r.Context(), claims.User.ID)
+	if err != nil {
+		switch errors.Cause(err) {
+		case user.ErrNotFound:
+			return errors.Wrap(response.NotFoundResponse(w), "find user")
+		default:
+			return errors.Wrap(response.InternalServerErrorResponse(w), "find user")
+		}
+	}
+
+	data, err := u.MarshalJSON()
+	if err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "marshal json")
+	}
+
+	response.ETag(w, u.Version)
+
+	if _, err := w.Write(data); err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "write response")
+	}
+
+	return nil
+}
+
+// UpdateMeHandler for current user update requests.
+type UpdateMeHandler struct {
+	Service
+}
+
+// Handle implements Handler interface.
+func (h UpdateMeHandler) Handle(w http.ResponseWriter, r *http.Request) error {
+	claims, ok := auth.FromContext(r.Context())
+	if !ok {
+		return errors.Wrap(response.UnauthorizedResponse(w), "claims from context")
+	}
+
+	var f user.ProfileForm
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return errors.Wrap(response.BadRequestResponse(w), "read body")
+	}
+
+	if err := f.UnmarshalJSON(data); err != nil {
+		return errors.Wrap(response.BadRequestResponse(w), "unmarshal json")
+	}
+
+	u, err := h.UpdateProfile(r.Context(), claims.User.ID, &f)
+	if err != nil {
+		switch v := errors.Cause(err).(type) {
+		case validation.Errors:
+			return errors.Wrap(response.UnprocessabeEntityResponse(w, v), "validation response")
+		}
+
+		switch errors.Cause(err) {
+		case user.ErrUsernameExists:
+			ves := validation.Errors{"username": "already exists"}
+			return errors.Wrap(response.UnprocessabeEntityResponse(w, ves), "update profile")
+		case user.ErrEmailExists:
+			ves := validation.Errors{"email": "already exists"}
+			return errors.Wrap(response.UnprocessabeEntityResponse(w, ves), "update profile")
+		case user.ErrNotFound:
+			return errors.Wrap(response.NotFoundResponse(w), "update profile")
+		default:
+			return errors.Wrap(response.InternalServerErrorResponse(w), "update profile")
+		}
+	}
+
+	data, err = u.MarshalJSON()
+	if err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "marshal json")
+	}
+
+	if _, err := w.Write(data); err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "write response")
+	}
+
+	return nil
+}
+
+// ChangePasswordHandler for current user password change requests.
+type ChangePasswordHandler struct {
+	Service
+}
+
+// Handle implements Handler interface.
+func (h ChangePasswordHandler) Handle(w http.ResponseWriter, r *http.Request) error {
+	claims, ok := auth.FromContext(r.Context())
+	if !ok {
+		return errors.Wrap(response.UnauthorizedResponse(w), "claims from context")
+	}
+
+	var f user.PasswordForm
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return errors.Wrap(response.BadRequestResponse(w), "read body")
+	}
+
+	if err := f.UnmarshalJSON(data); err != nil {
+		return errors.Wrap(response.BadRequestResponse(w), "unmarshal json")
+	}
+
+	if err := h.ChangePassword(r.Context(), claims.User.ID, claims.SessionID, &f); err != nil {
+		switch v := errors.Cause(err).(type) {
+		case validation.Errors:
+			return errors.Wrap(response.UnprocessabeEntityResponse(w, v), "validation response")
+		}
+
+		switch errors.Cause(err) {
+		case user.ErrWrongPassword:
+			ves := validation.Errors{"old_password": "is wrong"}
+			return errors.Wrap(response.UnprocessabeEntityResponse(w, ves), "change password")
+		case user.ErrNotFound:
+			return errors.Wrap(response.NotFoundResponse(w), "change password")
+		default:
+			return errors.Wrap(response.InternalServerErrorResponse(w), "change password")
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// DeactivateHandler for user deactivate requests.
+type DeactivateHandler struct {
+	Service
+}
+
+// Handle implements Handler interface.
+func (h DeactivateHandler) Handle(w http.ResponseWriter, r *http.Request) error {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		return errors.Wrapf(response.BadRequestResponse(w), "convert id query param to int: %v", err)
+	}
+
+	if err := h.Deactivate(r.Context(), id); err != nil {
+		switch errors.Cause(err) {
+		case user.ErrNotFound:
+			return errors.Wrap(response.NotFoundResponse(w), "deactivate user")
+		default:
+			return errors.Wrap(response.InternalServerErrorResponse(w), "deactivate user")
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// ActivateHandler for user activate requests.
+type ActivateHandler struct {
+	Service
+}
+
+// Handle implements Handler interface.
+func (h ActivateHandler) Handle(w http.ResponseWriter, r *http.Request) error {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		return errors.Wrapf(response.BadRequestResponse(w), "convert id query param to int: %v", err)
+	}
+
+	if err := h.Activate(r.Context(), id); err != nil {
+		switch errors.Cause(err) {
+		case user.ErrNotFound:
+			return errors.Wrap(response.NotFoundResponse(w), "activate user")
+		default:
+			return errors.Wrap(response.InternalServerErrorResponse(w), "activate user")
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// TrashHandler for deleted user list requests.
+type TrashHandler struct {
+	Service
+}
+
+// Handle implements Handler interface.
+func (h TrashHandler) Handle(w http.ResponseWriter, r *http.Request) error {
+	q, err := request.ParseQuery(r)
+	if err != nil {
+		return errors.Wrapf(response.BadRequestResponse(w), "parse query: %v", err)
+	}
+
+	users, err := h.Trash(r.Context(), q)
+	if err != nil {
+		if query.IsInvalid(errors.Cause(err)) {
+			return errors.Wrapf(response.BadRequestResponse(w), "list query: %v", err)
+		}
+		return errors.Wrap(response.InternalServerErrorResponse(w), "list of deleted users")
+	}
+
+	data, err := users.MarshalJSON()
+	if err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "marshal json")
+	}
+
+	if _, err := w.Write(data); err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "write response")
+	}
+
+	return nil
+}
+
+// UndeleteHandler for deleted user restore requests.
+type UndeleteHandler struct {
+	Service
+}
+
+// Handle implements Handler interface.
+func (h UndeleteHandler) Handle(w http.ResponseWriter, r *http.Request) error {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		return errors.Wrapf(response.BadRequestResponse(w), "convert id query param to int: %v", err)
+	}
+
+	if err := h.Undelete(r.Context(), id); err != nil {
+		switch errors.Cause(err) {
+		case user.ErrNotFound:
+			return errors.Wrap(response.NotFoundResponse(w), "undelete user")
+		default:
+			return errors.Wrap(response.InternalServerErrorResponse(w), "undelete user")
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// PurgeHandler for deleted user purge requests.
+type PurgeHandler struct {
+	Service
+}
+
+// Handle implements Handler interface.
+func (h PurgeHandler) Handle(w http.ResponseWriter, r *http.Request) error {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		return errors.Wrapf(response.BadRequestResponse(w), "convert id query param to int: %v", err)
+	}
+
+	if err := h.Purge(r.Context(), id); err != nil {
+		switch errors.Cause(err) {
+		case user.ErrNotFound:
+			return errors.Wrap(response.NotFoundResponse(w), "purge user")
+		case user.ErrInUse:
+			return errors.Wrap(response.ConflictResponse(w, user.ErrInUse), "purge user")
+		default:
+			return errors.Wrap(response.InternalServerErrorResponse(w), "purge user")
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// preconditionFailed responds with the current user
+// which the version of the request doesn't match.
+func preconditionFailed(ctx context.Context, w http.ResponseWriter, s Service, id int) error {
+	u, err := s.Find(ctx, id)
+	if err != nil {
+		switch errors.Cause(err) {
+		case user.ErrNotFound:
+			return errors.Wrap(response.NotFoundResponse(w), "find user")
+		default:
+			return errors.Wrap(response.InternalServerErrorResponse(w), "find user")
+		}
+	}
+
+	data, err := u.MarshalJSON()
+	if err != nil {
+		return errors.Wrap(response.InternalServerErrorResponse(w), "marshal json")
+	}
+
+	return errors.Wrap(response.PreconditionFailedResponse(w, u.Version, data), "precondition failed")
+}
+
 // Prepare prepares routes to use.
-func Prepare(subrouter *mux.Router, service Service, middleware func(handler.Handler) http.Handler) {
+func Prepare(subrouter *mux.Router, service Service, middleware func(permissions ...role.Permission) func(handler.Handler) http.Handler) {
 	create := CreateHandler{service}
 	find := FindHandler{service}
 	update := UpdateHandler{service}
+	patch := PatchHandler{service}
 	delete := DeleteHandler{service}
 	list := ListHandler{service}
+	deactivate := DeactivateHandler{service}
+	activate := ActivateHandler{service}
+
+	subrouter.Handle("", middleware(role.UsersManage)(&create)).Methods(http.MethodPost)
+	subrouter.Handle("/{id}", middleware(role.UsersView)(&find)).Methods(http.MethodGet)
+	subrouter.Handle("/{id}", middleware(role.UsersManage)(&update)).Methods(http.MethodPut)
+	subrouter.Handle("/{id}", middleware(role.UsersManage)(&patch)).Methods(http.MethodPatch)
+	subrouter.Handle("/{id}", middleware(role.UsersManage)(&delete)).Methods(http.MethodDelete)
+	subrouter.Handle("/{id}/deactivate", middleware(role.UsersManage)(&deactivate)).Methods(http.MethodPost)
+	subrouter.Handle("/{id}/activate", middleware(role.UsersManage)(&activate)).Methods(http.MethodPost)
+	subrouter.Handle("", middleware(role.UsersView)(&list)).Methods(http.MethodGet)
+}
+
+// PrepareTrash prepares routes of the deleted users.
+func PrepareTrash(subrouter *mux.Router, service Service, middleware func(permissions ...role.Permission) func(handler.Handler) http.Handler) {
+	trash := TrashHandler{service}
+	undelete := UndeleteHandler{service}
+	purge := PurgeHandler{service}
+
+	subrouter.Handle("", middleware(role.UsersManage)(&trash)).Methods(http.MethodGet)
+	subrouter.Handle("/{id:[0-9]+}/restore", middleware(role.UsersManage)(&undelete)).Methods(http.MethodPost)
+	subrouter.Handle("/{id:[0-9]+}", middleware(role.UsersManage)(&purge)).Methods(http.MethodDelete)
+}
+
+// PreparePassword prepares password recovery routes,
+// which are available without authorization.
+func PreparePassword(subrouter *mux.Router, service Service, middleware func(handler.Handler) http.Handler) {
+	forgot := ForgotPasswordHandler{service}
+	reset := ResetPasswordHandler{service}
+
+	subrouter.Handle("/forgot", middleware(&forgot)).Methods(http.MethodPost)
+	subrouter.Handle("/reset", middleware(&reset)).Methods(http.MethodPost)
+}
 
-	subrouter.Handle("", middleware(&create)).Methods(http.MethodPost)
-	subrouter.Handle("/{id}", middleware(&find)).Methods(http.MethodGet)
-	subrouter.Handle("/{id}", middleware(&update)).Methods(http.MethodPut)
-	subrouter.Handle("/{id}", middleware(&delete)).Methods(http.MethodDelete)
-	subrouter.Handle("", middleware(&list)).Methods(http.MethodGet)
+// PrepareMe prepares routes of the current user,
+// which are available for any authorized user. The
+// account is changed only with the interactive middleware.
+func PrepareMe(subrouter *mux.Router, service Service, middleware, interactive func(handler.Handler) http.Handler) {
+	me := MeHandler{service}
+	update := UpdateMeHandler{service}
+	password := ChangePasswordHandler{service}
+
+	subrouter.Handle("", middleware(&me)).Methods(http.MethodGet)
+	subrouter.Handle("", interactive(&update)).Methods(http.MethodPatch)
+	subrouter.Handle("/password", interactive(&password)).Methods(http.MethodPost)
 }
@@ -6,6 +6,8 @@ package user
 
 import (
 	context "context"
+	patch "github.com/dipress/crmifc/internal/kit/patch"
+	query "github.com/dipress/crmifc/internal/kit/query"
 	user "github.com/dipress/crmifc/internal/user"
 	gomock "github.com/golang/mock/gomock"
 	http "net/http"
@@ -102,45 +104,188 @@ func (mr *MockServiceMockRecorder) Find(ctx, id interface{}) *gomock.Call {
 }
 
 // Update mocks base method
-func (m *MockService) Update(ctx context.Context, id int, f *user.Form) (*user.User, error) {
+func (m *MockService) Update(ctx context.Context, id, version int, f *user.Form) (*user.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Update", ctx, id, f)
+	ret := m.ctrl.Call(m, "Update", ctx, id, version, f)
 	ret0, _ := ret[0].(*user.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Update indicates an expected call of Update
-func (mr *MockServiceMockRecorder) Update(ctx, id, f interface{}) *gomock.Call {
+func (mr *MockServiceMockRecorder) Update(ctx, id, version, f interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockService)(nil).Update), ctx, id, f)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockService)(nil).Update), ctx, id, version, f)
+}
+
+// Patch mocks base method
+func (m *MockService) Patch(ctx context.Context, id, version int, p patch.Patch) (*user.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Patch", ctx, id, version, p)
+	ret0, _ := ret[0].(*user.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Patch indicates an expected call of Patch
+func (mr *MockServiceMockRecorder) Patch(ctx, id, version, p interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Patch", reflect.TypeOf((*MockService)(nil).Patch), ctx, id, version, p)
 }
 
 // Delete mocks base method
-func (m *MockService) Delete(ctx context.Context, id int) error {
+func (m *MockService) Delete(ctx context.Context, id, version int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret := m.ctrl.Call(m, "Delete", ctx, id, version)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete
-func (mr *MockServiceMockRecorder) Delete(ctx, id interface{}) *gomock.Call {
+func (mr *MockServiceMockRecorder) Delete(ctx, id, version interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockService)(nil).Delete), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockService)(nil).Delete), ctx, id, version)
 }
 
 // List mocks base method
-func (m *MockService) List(ctx context.Context) (*user.Users, error) {
+func (m *MockService) List(ctx context.Context, q *query.Query) (*user.Users, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "List", ctx)
+	ret := m.ctrl.Call(m, "List", ctx, q)
 	ret0, _ := ret[0].(*user.Users)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // List indicates an expected call of List
-func (mr *MockServiceMockRecorder) List(ctx interface{}) *gomock.Call {
+func (mr *MockServiceMockRecorder) List(ctx, q interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockService)(nil).List), ctx, q)
+}
+
+// ForgotPassword mocks base method
+func (m *MockService) ForgotPassword(ctx context.Context, f *user.ForgotForm) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForgotPassword", ctx, f)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ForgotPassword indicates an expected call of ForgotPassword
+func (mr *MockServiceMockRecorder) ForgotPassword(ctx, f interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForgotPassword", reflect.TypeOf((*MockService)(nil).ForgotPassword), ctx, f)
+}
+
+// ResetPassword mocks base method
+func (m *MockService) ResetPassword(ctx context.Context, f *user.ResetForm) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPassword", ctx, f)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetPassword indicates an expected call of ResetPassword
+func (mr *MockServiceMockRecorder) ResetPassword(ctx, f interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockService)(nil).ResetPassword), ctx, f)
+}
+
+// UpdateProfile mocks base method
+func (m *MockService) UpdateProfile(ctx context.Context, id int, f *user.ProfileForm) (*user.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProfile", ctx, id, f)
+	ret0, _ := ret[0].(*user.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateProfile indicates an expected call of UpdateProfile
+func (mr *MockServiceMockRecorder) UpdateProfile(ctx, id, f interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProfile", reflect.TypeOf((*MockService)(nil).UpdateProfile), ctx, id, f)
+}
+
+// ChangePassword mocks base method
+func (m *MockService) ChangePassword(ctx context.Context, id, sessionID int, f *user.PasswordForm) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangePassword", ctx, id, sessionID, f)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangePassword indicates an expected call of ChangePassword
+func (mr *MockServiceMockRecorder) ChangePassword(ctx, id, sessionID, f interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePassword", reflect.TypeOf((*MockService)(nil).ChangePassword), ctx, id, sessionID, f)
+}
+
+// Deactivate mocks base method
+func (m *MockService) Deactivate(ctx context.Context, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Deactivate", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Deactivate indicates an expected call of Deactivate
+func (mr *MockServiceMockRecorder) Deactivate(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deactivate", reflect.TypeOf((*MockService)(nil).Deactivate), ctx, id)
+}
+
+// Activate mocks base method
+func (m *MockService) Activate(ctx context.Context, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Activate", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Activate indicates an expected call of Activate
+func (mr *MockServiceMockRecorder) Activate(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Activate", reflect.TypeOf((*MockService)(nil).Activate), ctx, id)
+}
+
+// Trash mocks base method
+func (m *MockService) Trash(ctx context.Context, q *query.Query) (*user.Users, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Trash", ctx, q)
+	ret0, _ := ret[0].(*user.Users)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Trash indicates an expected call of Trash
+func (mr *MockServiceMockRecorder) Trash(ctx, q interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Trash", reflect.TypeOf((*MockService)(nil).Trash), ctx, q)
+}
+
+// Undelete mocks base method
+func (m *MockService) Undelete(ctx context.Context, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Undelete", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Undelete indicates an expected call of Undelete
+func (mr *MockServiceMockRecorder) Undelete(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Undelete", reflect.TypeOf((*MockService)(nil).Undelete), ctx, id)
+}
+
+// Purge mocks base method
+func (m *MockService) Purge(ctx context.Context, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Purge indicates an expected call of Purge
+func (mr *MockServiceMockRecorder) Purge(ctx, id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockService)(nil).List), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockService)(nil).Purge), ctx, id)
 }
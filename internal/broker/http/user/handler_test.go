@@ -7,6 +7,9 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/dipress/crmifc/internal/kit/auth"
+	"github.com/dipress/crmifc/internal/kit/patch"
+	"github.com/dipress/crmifc/internal/kit/query"
 	"github.com/dipress/crmifc/internal/user"
 	"github.com/dipress/crmifc/internal/validation"
 	"github.com/golang/mock/gomock"
@@ -33,6 +36,20 @@ func TestCreateHandler(t *testing.T) {
 			},
 			code: http.StatusUnprocessableEntity,
 		},
+		{
+			name: "username exists",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Return(user.ErrUsernameExists)
+			},
+			code: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "role not found",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Return(user.ErrRoleNotFound)
+			},
+			code: http.StatusUnprocessableEntity,
+		},
 		{
 			name: "internal error",
 			serviceFunc: func(m *MockService) {
@@ -120,21 +137,36 @@ func TestUpdateHandler(t *testing.T) {
 		{
 			name: "ok",
 			serviceFunc: func(m *MockService) {
-				m.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any()).Return(&user.User{}, nil)
+				m.EXPECT().Update(gomock.Any(), gomock.Any(), 1, gomock.Any()).Return(&user.User{}, nil)
 			},
 			code: http.StatusOK,
 		},
 		{
 			name: "validation error",
 			serviceFunc: func(m *MockService) {
-				m.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any()).Return(&user.User{}, make(validation.Errors))
+				m.EXPECT().Update(gomock.Any(), gomock.Any(), 1, gomock.Any()).Return(&user.User{}, make(validation.Errors))
+			},
+			code: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "email exists",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Update(gomock.Any(), gomock.Any(), 1, gomock.Any()).Return(nil, user.ErrEmailExists)
 			},
 			code: http.StatusUnprocessableEntity,
 		},
+		{
+			name: "version mismatch",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Update(gomock.Any(), gomock.Any(), 1, gomock.Any()).Return(nil, user.ErrVersionMismatch)
+				m.EXPECT().Find(gomock.Any(), 1).Return(&user.User{Version: 2}, nil)
+			},
+			code: http.StatusPreconditionFailed,
+		},
 		{
 			name: "internal error",
 			serviceFunc: func(m *MockService) {
-				m.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any()).Return(&user.User{}, errors.New("mock error"))
+				m.EXPECT().Update(gomock.Any(), gomock.Any(), 1, gomock.Any()).Return(&user.User{}, errors.New("mock error"))
 			},
 			code: http.StatusInternalServerError,
 		},
@@ -155,6 +187,81 @@ func TestUpdateHandler(t *testing.T) {
 			w := httptest.NewRecorder()
 			r := httptest.NewRequest(http.MethodPut, "http://example.com", strings.NewReader("{}"))
 			r = mux.SetURLVars(r, map[string]string{"id": "1"})
+			r.Header.Set("If-Match", `"1"`)
+
+			err := h.Handle(w, r)
+			if w.Code != tc.code {
+				t.Errorf("unexpected code: %d expected %d error: %v", w.Code, tc.code, err)
+			}
+		})
+	}
+}
+
+func TestPatchHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		serviceFunc func(mock *MockService)
+		code        int
+	}{
+		{
+			name: "ok",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Patch(gomock.Any(), 1, 1, patch.Patch(`{"email":"username@example.com"}`)).Return(&user.User{}, nil)
+			},
+			code: http.StatusOK,
+		},
+		{
+			name: "invalid patch",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Patch(gomock.Any(), 1, 1, gomock.Any()).Return(nil, patch.ErrInvalid)
+			},
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "validation error",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Patch(gomock.Any(), 1, 1, gomock.Any()).Return(nil, make(validation.Errors))
+			},
+			code: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "email exists",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Patch(gomock.Any(), 1, 1, gomock.Any()).Return(nil, user.ErrEmailExists)
+			},
+			code: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "not found",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Patch(gomock.Any(), 1, 1, gomock.Any()).Return(nil, user.ErrNotFound)
+			},
+			code: http.StatusNotFound,
+		},
+		{
+			name: "internal error",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Patch(gomock.Any(), 1, 1, gomock.Any()).Return(nil, errors.New("mock error"))
+			},
+			code: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := NewMockService(ctrl)
+			tc.serviceFunc(service)
+
+			h := PatchHandler{service}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPatch, "http://example.com", strings.NewReader(`{"email":"username@example.com"}`))
+			r = mux.SetURLVars(r, map[string]string{"id": "1"})
+			r.Header.Set("If-Match", `"1"`)
 
 			err := h.Handle(w, r)
 			if w.Code != tc.code {
@@ -173,17 +280,32 @@ func TestDeleteHandler(t *testing.T) {
 		{
 			name: "ok",
 			serviceFunc: func(m *MockService) {
-				m.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(nil)
+				m.EXPECT().Delete(gomock.Any(), gomock.Any(), 1).Return(nil)
 			},
 			code: http.StatusOK,
 		},
+		{
+			name: "not found",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Delete(gomock.Any(), gomock.Any(), 1).Return(user.ErrNotFound)
+			},
+			code: http.StatusNotFound,
+		},
 		{
 			name: "repository error",
 			serviceFunc: func(m *MockService) {
-				m.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(errors.New("mock error"))
+				m.EXPECT().Delete(gomock.Any(), gomock.Any(), 1).Return(errors.New("mock error"))
 			},
 			code: http.StatusInternalServerError,
 		},
+		{
+			name: "version mismatch",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Delete(gomock.Any(), gomock.Any(), 1).Return(user.ErrVersionMismatch)
+				m.EXPECT().Find(gomock.Any(), 1).Return(&user.User{Version: 2}, nil)
+			},
+			code: http.StatusPreconditionFailed,
+		},
 	}
 
 	for _, tc := range tests {
@@ -201,6 +323,7 @@ func TestDeleteHandler(t *testing.T) {
 			w := httptest.NewRecorder()
 			r := httptest.NewRequest("PUT", "http://example.com", strings.NewReader("{}"))
 			r = mux.SetURLVars(r, map[string]string{"id": "1"})
+			r.Header.Set("If-Match", `"1"`)
 
 			err := h.Handle(w, r)
 			if w.Code != tc.code {
@@ -213,23 +336,37 @@ func TestDeleteHandler(t *testing.T) {
 func TestListHandler(t *testing.T) {
 	tests := []struct {
 		name        string
+		query       string
 		serviceFunc func(mock *MockService)
 		code        int
 	}{
 		{
 			name: "ok",
 			serviceFunc: func(m *MockService) {
-				m.EXPECT().List(gomock.Any()).Return(&user.Users{}, nil)
+				m.EXPECT().List(gomock.Any(), gomock.Any()).Return(&user.Users{}, nil)
 			},
 			code: http.StatusOK,
 		},
 		{
 			name: "repository error",
 			serviceFunc: func(m *MockService) {
-				m.EXPECT().List(gomock.Any()).Return(&user.Users{}, errors.New("mock error"))
+				m.EXPECT().List(gomock.Any(), gomock.Any()).Return(&user.Users{}, errors.New("mock error"))
 			},
 			code: http.StatusInternalServerError,
 		},
+		{
+			name:        "invalid query",
+			query:       "?limit=-1",
+			serviceFunc: func(m *MockService) {},
+			code:        http.StatusBadRequest,
+		},
+		{
+			name: "invalid sort",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().List(gomock.Any(), gomock.Any()).Return(&user.Users{}, query.ErrInvalidSort)
+			},
+			code: http.StatusBadRequest,
+		},
 	}
 
 	for _, tc := range tests {
@@ -244,7 +381,7 @@ func TestListHandler(t *testing.T) {
 
 			h := ListHandler{service}
 			w := httptest.NewRecorder()
-			r := httptest.NewRequest("GET", "http://example.com", strings.NewReader("{}"))
+			r := httptest.NewRequest("GET", "http://example.com"+tc.query, strings.NewReader("{}"))
 
 			err := h.Handle(w, r)
 			if w.Code != tc.code {
@@ -253,3 +390,326 @@ func TestListHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestForgotPasswordHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		serviceFunc func(mock *MockService)
+		code        int
+	}{
+		{
+			name: "ok",
+			body: `{"email":"username@example.com"}`,
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().ForgotPassword(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			code: http.StatusNoContent,
+		},
+		{
+			name:        "bad request",
+			body:        `{`,
+			serviceFunc: func(m *MockService) {},
+			code:        http.StatusBadRequest,
+		},
+		{
+			name: "validation error",
+			body: `{}`,
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().ForgotPassword(gomock.Any(), gomock.Any()).Return(validation.Errors{"email": "cannot be blank"})
+			},
+			code: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "internal error",
+			body: `{"email":"username@example.com"}`,
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().ForgotPassword(gomock.Any(), gomock.Any()).Return(errors.New("mock error"))
+			},
+			code: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := NewMockService(ctrl)
+			tc.serviceFunc(service)
+
+			h := ForgotPasswordHandler{service}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "http://example.com", strings.NewReader(tc.body))
+
+			err := h.Handle(w, r)
+			if w.Code != tc.code {
+				t.Errorf("unexpected code: %d expected %d error: %v", w.Code, tc.code, err)
+			}
+		})
+	}
+}
+
+func TestResetPasswordHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		serviceFunc func(mock *MockService)
+		code        int
+	}{
+		{
+			name: "ok",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().ResetPassword(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			code: http.StatusNoContent,
+		},
+		{
+			name: "invalid token",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().ResetPassword(gomock.Any(), gomock.Any()).Return(user.ErrInvalidResetToken)
+			},
+			code: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "validation error",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().ResetPassword(gomock.Any(), gomock.Any()).Return(validation.Errors{"password": "cannot be blank"})
+			},
+			code: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "internal error",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().ResetPassword(gomock.Any(), gomock.Any()).Return(errors.New("mock error"))
+			},
+			code: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := NewMockService(ctrl)
+			tc.serviceFunc(service)
+
+			h := ResetPasswordHandler{service}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "http://example.com", strings.NewReader(`{"token":"token","password":"password123"}`))
+
+			err := h.Handle(w, r)
+			if w.Code != tc.code {
+				t.Errorf("unexpected code: %d expected %d error: %v", w.Code, tc.code, err)
+			}
+		})
+	}
+}
+
+func TestUpdateMeHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		claims      bool
+		serviceFunc func(mock *MockService)
+		code        int
+	}{
+		{
+			name:   "ok",
+			claims: true,
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().UpdateProfile(gomock.Any(), 1, gomock.Any()).Return(&user.User{ID: 1}, nil)
+			},
+			code: http.StatusOK,
+		},
+		{
+			name:        "without claims",
+			serviceFunc: func(m *MockService) {},
+			code:        http.StatusUnauthorized,
+		},
+		{
+			name:   "username exists",
+			claims: true,
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().UpdateProfile(gomock.Any(), 1, gomock.Any()).Return(nil, user.ErrUsernameExists)
+			},
+			code: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "validation error",
+			claims: true,
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().UpdateProfile(gomock.Any(), 1, gomock.Any()).Return(nil, validation.Errors{"email": "cannot be blank"})
+			},
+			code: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "internal error",
+			claims: true,
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().UpdateProfile(gomock.Any(), 1, gomock.Any()).Return(nil, errors.New("mock error"))
+			},
+			code: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := NewMockService(ctrl)
+			tc.serviceFunc(service)
+
+			h := UpdateMeHandler{service}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("PATCH", "http://example.com", strings.NewReader(`{"username":"username","email":"username@example.com"}`))
+			if tc.claims {
+				r = r.WithContext(auth.ToContext(r.Context(), &auth.Claims{User: user.User{ID: 1}}))
+			}
+
+			err := h.Handle(w, r)
+			if w.Code != tc.code {
+				t.Errorf("unexpected code: %d expected %d error: %v", w.Code, tc.code, err)
+			}
+		})
+	}
+}
+
+func TestChangePasswordHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		serviceFunc func(mock *MockService)
+		code        int
+	}{
+		{
+			name: "ok",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().ChangePassword(gomock.Any(), 1, 3, gomock.Any()).Return(nil)
+			},
+			code: http.StatusNoContent,
+		},
+		{
+			name: "wrong password",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().ChangePassword(gomock.Any(), 1, 3, gomock.Any()).Return(user.ErrWrongPassword)
+			},
+			code: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "internal error",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().ChangePassword(gomock.Any(), 1, 3, gomock.Any()).Return(errors.New("mock error"))
+			},
+			code: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := NewMockService(ctrl)
+			tc.serviceFunc(service)
+
+			h := ChangePasswordHandler{service}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "http://example.com", strings.NewReader(`{"old_password":"password123","new_password":"newpassword"}`))
+			r = r.WithContext(auth.ToContext(r.Context(), &auth.Claims{User: user.User{ID: 1}, SessionID: 3}))
+
+			err := h.Handle(w, r)
+			if w.Code != tc.code {
+				t.Errorf("unexpected code: %d expected %d error: %v", w.Code, tc.code, err)
+			}
+		})
+	}
+}
+
+func TestDeactivateHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		serviceFunc func(mock *MockService)
+		code        int
+	}{
+		{
+			name: "ok",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Deactivate(gomock.Any(), 1).Return(nil)
+			},
+			code: http.StatusNoContent,
+		},
+		{
+			name: "not found",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Deactivate(gomock.Any(), 1).Return(user.ErrNotFound)
+			},
+			code: http.StatusNotFound,
+		},
+		{
+			name: "internal error",
+			serviceFunc: func(m *MockService) {
+				m.EXPECT().Deactivate(gomock.Any(), 1).Return(errors.New("mock error"))
+			},
+			code: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := NewMockService(ctrl)
+			tc.serviceFunc(service)
+
+			h := DeactivateHandler{service}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "http://example.com", nil)
+			r = mux.SetURLVars(r, map[string]string{"id": "1"})
+
+			err := h.Handle(w, r)
+			if w.Code != tc.code {
+				t.Errorf("unexpected code: %d expected %d error: %v", w.Code, tc.code, err)
+			}
+		})
+	}
+}
+
+func TestIfMatchRequired(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler Handler
+		method  string
+	}{
+		{name: "update", handler: &UpdateHandler{}, method: http.MethodPut},
+		{name: "patch", handler: &PatchHandler{}, method: http.MethodPatch},
+		{name: "delete", handler: &DeleteHandler{}, method: http.MethodDelete},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(tc.method, "http://example.com", strings.NewReader("{}"))
+			r = mux.SetURLVars(r, map[string]string{"id": "1"})
+
+			err := tc.handler.Handle(w, r)
+			if w.Code != http.StatusPreconditionRequired {
+				t.Errorf("unexpected code: %d expected %d error: %v", w.Code, http.StatusPreconditionRequired, err)
+			}
+		})
+	}
+}
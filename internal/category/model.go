@@ -13,14 +13,22 @@ var (
 	// ErrNameExists returns when given name is already
 	// present in database.
 	ErrNameExists = errors.New("name already exists")
+	// ErrInUse returns when category can't be purged
+	// because articles refer to it.
+	ErrInUse = errors.New("category is in use")
+	// ErrVersionMismatch returns when the category was changed
+	// since the version the request is based on.
+	ErrVersionMismatch = errors.New("category version mismatch")
 )
 
 // Category contains all user field.
 type Category struct {
-	ID        int       `json:"id"`
-	Name      string    `json:"name"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        int        `json:"id"`
+	Name      string     `json:"name"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	Version   int        `json:"version"`
 }
 
 // Form is a category form.
@@ -36,4 +44,5 @@ type NewCategory struct {
 // Categories contains slice of categories.
 type Categories struct {
 	Categories []Category `json:"categories"`
+	NextCursor string     `json:"next_cursor"`
 }
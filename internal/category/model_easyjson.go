@@ -7,6 +7,7 @@ import (
 	easyjson "github.com/mailru/easyjson"
 	jlexer "github.com/mailru/easyjson/jlexer"
 	jwriter "github.com/mailru/easyjson/jwriter"
+	time "time"
 )
 
 // suppress unused package warning
@@ -190,6 +191,20 @@ func easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalCategory2(in *jlexer.Le
 			if data := in.Raw(); in.Ok() {
 				in.AddError((out.UpdatedAt).UnmarshalJSON(data))
 			}
+		case "deleted_at":
+			if in.IsNull() {
+				in.Skip()
+				out.DeletedAt = nil
+			} else {
+				if out.DeletedAt == nil {
+					out.DeletedAt = new(time.Time)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.DeletedAt).UnmarshalJSON(data))
+				}
+			}
+		case "version":
+			out.Version = int(in.Int())
 		default:
 			in.SkipRecursive()
 		}
@@ -244,6 +259,26 @@ func easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalCategory2(out *jwriter.
 		}
 		out.Raw((in.UpdatedAt).MarshalJSON())
 	}
+	if in.DeletedAt != nil {
+		const prefix string = ",\"deleted_at\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Raw((*in.DeletedAt).MarshalJSON())
+	}
+	{
+		const prefix string = ",\"version\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int(int(in.Version))
+	}
 	out.RawByte('}')
 }
 
@@ -312,6 +347,8 @@ func easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalCategory3(in *jlexer.Le
 				}
 				in.Delim(']')
 			}
+		case "next_cursor":
+			out.NextCursor = string(in.String())
 		default:
 			in.SkipRecursive()
 		}
@@ -347,6 +384,16 @@ func easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalCategory3(out *jwriter.
 			out.RawByte(']')
 		}
 	}
+	{
+		const prefix string = ",\"next_cursor\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.NextCursor))
+	}
 	out.RawByte('}')
 }
 
@@ -3,6 +3,9 @@ package category
 import (
 	"context"
 
+	"github.com/dipress/crmifc/internal/kit/patch"
+	"github.com/dipress/crmifc/internal/kit/query"
+	"github.com/dipress/crmifc/internal/kit/tx"
 	"github.com/pkg/errors"
 )
 
@@ -14,7 +17,10 @@ type Repository interface {
 	Find(ctx context.Context, id int) (*Category, error)
 	Update(ctx context.Context, id int, cat *Category) error
 	Delete(ctx context.Context, id int) error
-	List(ctx context.Context, cat *Categories) error
+	List(ctx context.Context, q *query.Query, cat *Categories) error
+	ListDeleted(ctx context.Context, q *query.Query, cat *Categories) error
+	Undelete(ctx context.Context, id int) error
+	Purge(ctx context.Context, id int) error
 }
 
 // Validater validates role fields.
@@ -26,6 +32,9 @@ type Validater interface {
 type Service struct {
 	Repository
 	Validater
+	// Transactor runs the actions of several
+	// repository calls in a single transaction.
+	Transactor tx.Transactor
 }
 
 // NewService factory prepares service for all futher operations.
@@ -33,6 +42,7 @@ func NewService(r Repository, v Validater) *Service {
 	s := Service{
 		Repository: r,
 		Validater:  v,
+		Transactor: tx.Nop{},
 	}
 	return &s
 }
@@ -64,8 +74,8 @@ func (s *Service) Find(ctx context.Context, id int) (*Category, error) {
 	return c, nil
 }
 
-// Update updates a category.
-func (s *Service) Update(ctx context.Context, id int, f *Form) (*Category, error) {
+// Update updates a category of the given version.
+func (s *Service) Update(ctx context.Context, id, version int, f *Form) (*Category, error) {
 	if err := s.Validater.Validate(ctx, f); err != nil {
 		return nil, errors.Wrap(err, "validater validate")
 	}
@@ -75,6 +85,10 @@ func (s *Service) Update(ctx context.Context, id int, f *Form) (*Category, error
 		return nil, errors.Wrap(err, "repository find category")
 	}
 
+	if cat.Version != version {
+		return nil, ErrVersionMismatch
+	}
+
 	cat.Name = f.Name
 
 	if err := s.Repository.Update(ctx, id, cat); err != nil {
@@ -84,26 +98,77 @@ func (s *Service) Update(ctx context.Context, id int, f *Form) (*Category, error
 	return cat, nil
 }
 
-// Delete deletes a category.
-func (s *Service) Delete(ctx context.Context, id int) error {
+// Patch updates only the fields of the category of the
+// given version which are given by the JSON merge patch.
+func (s *Service) Patch(ctx context.Context, id, version int, p patch.Patch) (*Category, error) {
 	cat, err := s.Repository.Find(ctx, id)
 	if err != nil {
-		return errors.Wrap(err, "find category")
+		return nil, errors.Wrap(err, "repository find category")
 	}
 
-	if err := s.Repository.Delete(ctx, cat.ID); err != nil {
-		return errors.Wrap(err, "delete category")
+	f := Form{
+		Name: cat.Name,
 	}
 
-	return nil
+	if err := p.Apply(&f); err != nil {
+		return nil, errors.Wrap(err, "apply patch")
+	}
+
+	return s.Update(ctx, id, version, &f)
 }
 
-// List shows all categories.
-func (s *Service) List(ctx context.Context) (*Categories, error) {
+// Delete moves a category of the given version to the trash.
+func (s *Service) Delete(ctx context.Context, id, version int) error {
+	return s.Transactor.WithinTx(ctx, func(ctx context.Context) error {
+		cat, err := s.Repository.Find(ctx, id)
+		if err != nil {
+			return errors.Wrap(err, "find category")
+		}
+
+		if cat.Version != version {
+			return ErrVersionMismatch
+		}
+
+		if err := s.Repository.Delete(ctx, cat.ID); err != nil {
+			return errors.Wrap(err, "delete category")
+		}
+
+		return nil
+	})
+}
+
+// List shows categories page by given query.
+func (s *Service) List(ctx context.Context, q *query.Query) (*Categories, error) {
 	var categories Categories
-	if err := s.Repository.List(ctx, &categories); err != nil {
+	if err := s.Repository.List(ctx, q, &categories); err != nil {
 		return nil, errors.Wrap(err, "list of categories")
 	}
 
 	return &categories, nil
 }
+
+// Trash shows page of the deleted categories by given query.
+func (s *Service) Trash(ctx context.Context, q *query.Query) (*Categories, error) {
+	var categories Categories
+	if err := s.Repository.ListDeleted(ctx, q, &categories); err != nil {
+		return nil, errors.Wrap(err, "list of deleted categories")
+	}
+
+	return &categories, nil
+}
+
+// Undelete restores the deleted category.
+func (s *Service) Undelete(ctx context.Context, id int) error {
+	if err := s.Repository.Undelete(ctx, id); err != nil {
+		return errors.Wrap(err, "undelete category")
+	}
+	return nil
+}
+
+// Purge deletes the deleted category permanently.
+func (s *Service) Purge(ctx context.Context, id int) error {
+	if err := s.Repository.Purge(ctx, id); err != nil {
+		return errors.Wrap(err, "purge category")
+	}
+	return nil
+}
@@ -6,6 +6,7 @@ package category
 
 import (
 	context "context"
+	query "github.com/dipress/crmifc/internal/kit/query"
 	gomock "github.com/golang/mock/gomock"
 	reflect "reflect"
 )
@@ -91,17 +92,59 @@ func (mr *MockRepositoryMockRecorder) Delete(ctx, id interface{}) *gomock.Call {
 }
 
 // List mocks base method
-func (m *MockRepository) List(ctx context.Context, cat *Categories) error {
+func (m *MockRepository) List(ctx context.Context, q *query.Query, cat *Categories) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "List", ctx, cat)
+	ret := m.ctrl.Call(m, "List", ctx, q, cat)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // List indicates an expected call of List
-func (mr *MockRepositoryMockRecorder) List(ctx, cat interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) List(ctx, q, cat interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockRepository)(nil).List), ctx, cat)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockRepository)(nil).List), ctx, q, cat)
+}
+
+// ListDeleted mocks base method
+func (m *MockRepository) ListDeleted(ctx context.Context, q *query.Query, cat *Categories) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeleted", ctx, q, cat)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ListDeleted indicates an expected call of ListDeleted
+func (mr *MockRepositoryMockRecorder) ListDeleted(ctx, q, cat interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeleted", reflect.TypeOf((*MockRepository)(nil).ListDeleted), ctx, q, cat)
+}
+
+// Undelete mocks base method
+func (m *MockRepository) Undelete(ctx context.Context, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Undelete", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Undelete indicates an expected call of Undelete
+func (mr *MockRepositoryMockRecorder) Undelete(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Undelete", reflect.TypeOf((*MockRepository)(nil).Undelete), ctx, id)
+}
+
+// Purge mocks base method
+func (m *MockRepository) Purge(ctx context.Context, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Purge indicates an expected call of Purge
+func (mr *MockRepositoryMockRecorder) Purge(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockRepository)(nil).Purge), ctx, id)
 }
 
 // MockValidater is a mock of Validater interface
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/dipress/crmifc/internal/kit/auth"
+	"github.com/dipress/crmifc/internal/kit/query"
 	gomock "github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 )
@@ -143,7 +144,7 @@ func Test_Service_Update(t *testing.T) {
 		{
 			name: "ok",
 			repositoryFunc: func(m *MockRepository) {
-				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&Category{}, nil)
+				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&Category{Version: 1}, nil)
 				m.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
 			},
 			validaterFunc: func(m *MockValidater) {
@@ -161,7 +162,7 @@ func Test_Service_Update(t *testing.T) {
 		{
 			name: "find category error",
 			repositoryFunc: func(m *MockRepository) {
-				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&Category{}, errors.New("mock error"))
+				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&Category{Version: 1}, errors.New("mock error"))
 			},
 			validaterFunc: func(m *MockValidater) {
 				m.EXPECT().Validate(gomock.Any(), gomock.Any()).Return(nil)
@@ -171,7 +172,7 @@ func Test_Service_Update(t *testing.T) {
 		{
 			name: "update category error",
 			repositoryFunc: func(m *MockRepository) {
-				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&Category{}, nil)
+				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&Category{Version: 1}, nil)
 				m.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("mock error"))
 			},
 			validaterFunc: func(m *MockValidater) {
@@ -207,7 +208,7 @@ func Test_Service_Update(t *testing.T) {
 				Name: "Contacts",
 			}
 
-			_, err := s.Update(newCtx, 1, &form)
+			_, err := s.Update(newCtx, 1, 1, &form)
 			if tc.wantErr {
 				assert.Error(t, err)
 				return
@@ -226,21 +227,21 @@ func Test_Service_Delete(t *testing.T) {
 		{
 			name: "ok",
 			repositoryFunc: func(m *MockRepository) {
-				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&Category{}, nil)
+				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&Category{Version: 1}, nil)
 				m.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(nil)
 			},
 		},
 		{
 			name: "find category error",
 			repositoryFunc: func(m *MockRepository) {
-				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&Category{}, errors.New("mock error"))
+				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&Category{Version: 1}, errors.New("mock error"))
 			},
 			wantErr: true,
 		},
 		{
 			name: "delete category error",
 			repositoryFunc: func(m *MockRepository) {
-				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&Category{}, nil)
+				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&Category{Version: 1}, nil)
 				m.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(errors.New("mock error"))
 			},
 			wantErr: true,
@@ -267,7 +268,7 @@ func Test_Service_Delete(t *testing.T) {
 			claims := auth.Claims{}
 			newCtx := auth.ToContext(ctx, &claims)
 
-			err := s.Delete(newCtx, 1)
+			err := s.Delete(newCtx, 1, 1)
 			if tc.wantErr {
 				assert.Error(t, err)
 				return
@@ -286,13 +287,13 @@ func Test_List_Service(t *testing.T) {
 		{
 			name: "ok",
 			repositoryFunc: func(m *MockRepository) {
-				m.EXPECT().List(gomock.Any(), gomock.Any()).Return(nil)
+				m.EXPECT().List(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
 			},
 		},
 		{
 			name: "internal error",
 			repositoryFunc: func(m *MockRepository) {
-				m.EXPECT().List(gomock.Any(), gomock.Any()).Return(errors.New("mock error"))
+				m.EXPECT().List(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("mock error"))
 			},
 			wantErr: true,
 		},
@@ -318,7 +319,52 @@ func Test_List_Service(t *testing.T) {
 			claims := auth.Claims{}
 			newCtx := auth.ToContext(ctx, &claims)
 
-			_, err := s.List(newCtx)
+			_, err := s.List(newCtx, query.New())
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.Nil(t, err)
+		})
+	}
+}
+
+func Test_Service_Purge(t *testing.T) {
+	tests := []struct {
+		name           string
+		repositoryFunc func(mock *MockRepository)
+		wantErr        bool
+	}{
+		{
+			name: "ok",
+			repositoryFunc: func(m *MockRepository) {
+				m.EXPECT().Purge(gomock.Any(), 1).Return(nil)
+			},
+		},
+		{
+			name: "not in the trash",
+			repositoryFunc: func(m *MockRepository) {
+				m.EXPECT().Purge(gomock.Any(), 1).Return(ErrNotFound)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := NewMockRepository(ctrl)
+
+			tc.repositoryFunc(repo)
+
+			s := NewService(repo, nil)
+
+			err := s.Purge(context.Background(), 1)
 			if tc.wantErr {
 				assert.Error(t, err)
 				return
@@ -0,0 +1,59 @@
+package invitation
+
+import (
+	"errors"
+	"time"
+)
+
+// easyjson -all model.go
+
+var (
+	// ErrNotFound raises when pending invitation isn't found in the database.
+	ErrNotFound = errors.New("invitation not found")
+	// ErrExists returns when the email is invited already
+	// and the invitation isn't accepted yet.
+	ErrExists = errors.New("invitation already exists")
+	// ErrInvalidToken returns when the invitation token
+	// is unknown, expired, revoked or used already.
+	ErrInvalidToken = errors.New("invalid invitation token")
+)
+
+// Invitation contains all invitation fields. Only the hash
+// of the token is stored, the token is sent by email.
+type Invitation struct {
+	ID        int       `json:"id"`
+	Email     string    `json:"email"`
+	RoleID    int       `json:"role_id"`
+	InvitedBy int       `json:"invited_by"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewInvitation contains the information which needs to create a new Invitation.
+type NewInvitation struct {
+	Email     string
+	RoleID    int
+	InvitedBy int
+	Hash      string
+	ExpiresAt time.Time
+}
+
+// Form is an invitation form.
+type Form struct {
+	Email  string `json:"email"`
+	RoleID int    `json:"role_id"`
+}
+
+// AcceptForm is a form to accept the invitation.
+type AcceptForm struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	// Email is taken from the invitation, so the password
+	// policy can check it.
+	Email string `json:"-"`
+}
+
+// Invitations contains slice of invitations.
+type Invitations struct {
+	Invitations []Invitation `json:"invitations"`
+}
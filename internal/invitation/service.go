@@ -0,0 +1,234 @@
+package invitation
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/dipress/crmifc/internal/kit/auth"
+	"github.com/dipress/crmifc/internal/kit/mail"
+	"github.com/dipress/crmifc/internal/role"
+	"github.com/dipress/crmifc/internal/user"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// go:generate mockgen -source=service.go -package=invitation -destination=service.mock.go
+
+const (
+	// DefaultAcceptURL is the page which accepts the
+	// invitation by the token from the query string.
+	DefaultAcceptURL = "http://localhost:3000/invitations/accept"
+	// DefaultExpireAfter is the lifetime of the invitation token.
+	DefaultExpireAfter = 7 * 24 * time.Hour
+)
+
+// Repository allows to work with the database.
+type Repository interface {
+	Create(ctx context.Context, ni *NewInvitation, inv *Invitation) error
+	List(ctx context.Context, invs *Invitations) error
+	FindByToken(ctx context.Context, hash string) (*Invitation, error)
+	Renew(ctx context.Context, id int, hash string, expiresAt time.Time, inv *Invitation) error
+	Delete(ctx context.Context, id int) error
+	Accept(ctx context.Context, id int, nu *user.NewUser, usr *user.User) error
+}
+
+// UserRepository checks that the invited user doesn't exist.
+type UserRepository interface {
+	UniqueUsername(ctx context.Context, username string) error
+	UniqueEmail(ctx context.Context, email string) error
+}
+
+// RoleRepository finds the roles of the invitations.
+type RoleRepository interface {
+	Find(ctx context.Context, id int) (*role.Role, error)
+}
+
+// Validater validates invitation fields.
+type Validater interface {
+	Validate(ctx context.Context, form *Form) error
+	ValidateAccept(ctx context.Context, form *AcceptForm) error
+}
+
+// Service is a use case for user invitations.
+type Service struct {
+	Repository
+	UserRepository
+	RoleRepository
+	Validater
+	Mailer      mail.Mailer
+	AcceptURL   string
+	ExpireAfter time.Duration
+	// Cost is the bcrypt cost of the password hashes.
+	Cost int
+}
+
+// NewService factory prepares service for all futher operations.
+func NewService(r Repository, ur UserRepository, rr RoleRepository, v Validater, m mail.Mailer) *Service {
+	s := Service{
+		Repository:     r,
+		UserRepository: ur,
+		RoleRepository: rr,
+		Validater:      v,
+		Mailer:         m,
+		AcceptURL:      DefaultAcceptURL,
+		ExpireAfter:    DefaultExpireAfter,
+		Cost:           bcrypt.DefaultCost,
+	}
+
+	return &s
+}
+
+// Invite invites the email with the role on behalf of the user
+// with the claims. The link with the token is sent to the email.
+func (s *Service) Invite(ctx context.Context, c *auth.Claims, f *Form) (*Invitation, error) {
+	if err := s.Validater.Validate(ctx, f); err != nil {
+		return nil, errors.Wrap(err, "validater validate")
+	}
+
+	if err := s.UserRepository.UniqueEmail(ctx, f.Email); err != nil {
+		return nil, errors.Wrap(err, "unique email")
+	}
+
+	if _, err := s.RoleRepository.Find(ctx, f.RoleID); err != nil {
+		return nil, errors.Wrap(err, "find role")
+	}
+
+	token, err := newToken()
+	if err != nil {
+		return nil, errors.Wrap(err, "new token")
+	}
+
+	ni := NewInvitation{
+		Email:     f.Email,
+		RoleID:    f.RoleID,
+		InvitedBy: c.User.ID,
+		Hash:      hashToken(token),
+		ExpiresAt: time.Now().Add(s.ExpireAfter),
+	}
+
+	var inv Invitation
+	if err := s.Repository.Create(ctx, &ni, &inv); err != nil {
+		return nil, errors.Wrap(err, "repository create invitation")
+	}
+
+	if err := s.send(ctx, &inv, token); err != nil {
+		return nil, errors.Wrap(err, "send invitation")
+	}
+
+	return &inv, nil
+}
+
+// List lists the pending invitations.
+func (s *Service) List(ctx context.Context) (*Invitations, error) {
+	var invs Invitations
+	if err := s.Repository.List(ctx, &invs); err != nil {
+		return nil, errors.Wrap(err, "repository list invitations")
+	}
+	return &invs, nil
+}
+
+// Resend sends the pending invitation again with a new token.
+// The link which was sent before stops working.
+func (s *Service) Resend(ctx context.Context, id int) (*Invitation, error) {
+	token, err := newToken()
+	if err != nil {
+		return nil, errors.Wrap(err, "new token")
+	}
+
+	var inv Invitation
+	if err := s.Repository.Renew(ctx, id, hashToken(token), time.Now().Add(s.ExpireAfter), &inv); err != nil {
+		return nil, errors.Wrap(err, "repository renew invitation")
+	}
+
+	if err := s.send(ctx, &inv, token); err != nil {
+		return nil, errors.Wrap(err, "send invitation")
+	}
+
+	return &inv, nil
+}
+
+// Revoke deletes the pending invitation.
+func (s *Service) Revoke(ctx context.Context, id int) error {
+	if err := s.Repository.Delete(ctx, id); err != nil {
+		return errors.Wrap(err, "repository delete invitation")
+	}
+	return nil
+}
+
+// Accept creates the user with the email and the role of the
+// invitation. The invitee chooses the username and the password.
+func (s *Service) Accept(ctx context.Context, token string, f *AcceptForm) (*user.User, error) {
+	inv, err := s.Repository.FindByToken(ctx, hashToken(token))
+	if err != nil {
+		return nil, errors.Wrap(err, "find invitation")
+	}
+
+	f.Email = inv.Email
+	if err := s.Validater.ValidateAccept(ctx, f); err != nil {
+		return nil, errors.Wrap(err, "validater validate accept")
+	}
+
+	if err := s.UserRepository.UniqueUsername(ctx, f.Username); err != nil {
+		return nil, errors.Wrap(err, "unique username")
+	}
+
+	if err := s.UserRepository.UniqueEmail(ctx, inv.Email); err != nil {
+		return nil, errors.Wrap(err, "unique email")
+	}
+
+	pw, err := bcrypt.GenerateFromPassword([]byte(f.Password), s.Cost)
+	if err != nil {
+		return nil, errors.Wrap(err, "generating password hash")
+	}
+
+	nu := user.NewUser{
+		Username:     f.Username,
+		Email:        inv.Email,
+		PasswordHash: string(pw),
+		RoleID:       inv.RoleID,
+	}
+
+	var u user.User
+	if err := s.Repository.Accept(ctx, inv.ID, &nu, &u); err != nil {
+		return nil, errors.Wrap(err, "repository accept invitation")
+	}
+
+	return &u, nil
+}
+
+// send sends the link with the token to the invited email.
+func (s *Service) send(ctx context.Context, inv *Invitation, token string) error {
+	m := mail.Message{
+		To:      inv.Email,
+		Subject: "Invitation",
+		Body: fmt.Sprintf("You are invited to join. Follow the link to choose your username and password:\n\n%s?token=%s\n\nThe link expires in %s.\n",
+			s.AcceptURL, url.QueryEscape(token), s.ExpireAfter),
+	}
+
+	if err := s.Mailer.Send(ctx, &m); err != nil {
+		return errors.Wrap(err, "send mail")
+	}
+	return nil
+}
+
+// newToken returns random invitation token.
+func newToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Wrap(err, "read random bytes")
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken returns the hash of the invitation token
+// which is stored instead of the token itself.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,262 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: service.go
+
+// Package invitation is a generated GoMock package.
+package invitation
+
+import (
+	context "context"
+	role "github.com/dipress/crmifc/internal/role"
+	user "github.com/dipress/crmifc/internal/user"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+	time "time"
+)
+
+// MockRepository is a mock of Repository interface
+type MockRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepositoryMockRecorder
+}
+
+// MockRepositoryMockRecorder is the mock recorder for MockRepository
+type MockRepositoryMockRecorder struct {
+	mock *MockRepository
+}
+
+// NewMockRepository creates a new mock instance
+func NewMockRepository(ctrl *gomock.Controller) *MockRepository {
+	mock := &MockRepository{ctrl: ctrl}
+	mock.recorder = &MockRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method
+func (m *MockRepository) Create(ctx context.Context, ni *NewInvitation, inv *Invitation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, ni, inv)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create
+func (mr *MockRepositoryMockRecorder) Create(ctx, ni, inv interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRepository)(nil).Create), ctx, ni, inv)
+}
+
+// List mocks base method
+func (m *MockRepository) List(ctx context.Context, invs *Invitations) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, invs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// List indicates an expected call of List
+func (mr *MockRepositoryMockRecorder) List(ctx, invs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockRepository)(nil).List), ctx, invs)
+}
+
+// FindByToken mocks base method
+func (m *MockRepository) FindByToken(ctx context.Context, hash string) (*Invitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByToken", ctx, hash)
+	ret0, _ := ret[0].(*Invitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByToken indicates an expected call of FindByToken
+func (mr *MockRepositoryMockRecorder) FindByToken(ctx, hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByToken", reflect.TypeOf((*MockRepository)(nil).FindByToken), ctx, hash)
+}
+
+// Renew mocks base method
+func (m *MockRepository) Renew(ctx context.Context, id int, hash string, expiresAt time.Time, inv *Invitation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Renew", ctx, id, hash, expiresAt, inv)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Renew indicates an expected call of Renew
+func (mr *MockRepositoryMockRecorder) Renew(ctx, id, hash, expiresAt, inv interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Renew", reflect.TypeOf((*MockRepository)(nil).Renew), ctx, id, hash, expiresAt, inv)
+}
+
+// Delete mocks base method
+func (m *MockRepository) Delete(ctx context.Context, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockRepositoryMockRecorder) Delete(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockRepository)(nil).Delete), ctx, id)
+}
+
+// Accept mocks base method
+func (m *MockRepository) Accept(ctx context.Context, id int, nu *user.NewUser, usr *user.User) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Accept", ctx, id, nu, usr)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Accept indicates an expected call of Accept
+func (mr *MockRepositoryMockRecorder) Accept(ctx, id, nu, usr interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Accept", reflect.TypeOf((*MockRepository)(nil).Accept), ctx, id, nu, usr)
+}
+
+// MockUserRepository is a mock of UserRepository interface
+type MockUserRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserRepositoryMockRecorder
+}
+
+// MockUserRepositoryMockRecorder is the mock recorder for MockUserRepository
+type MockUserRepositoryMockRecorder struct {
+	mock *MockUserRepository
+}
+
+// NewMockUserRepository creates a new mock instance
+func NewMockUserRepository(ctrl *gomock.Controller) *MockUserRepository {
+	mock := &MockUserRepository{ctrl: ctrl}
+	mock.recorder = &MockUserRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockUserRepository) EXPECT() *MockUserRepositoryMockRecorder {
+	return m.recorder
+}
+
+// UniqueUsername mocks base method
+func (m *MockUserRepository) UniqueUsername(ctx context.Context, username string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UniqueUsername", ctx, username)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UniqueUsername indicates an expected call of UniqueUsername
+func (mr *MockUserRepositoryMockRecorder) UniqueUsername(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UniqueUsername", reflect.TypeOf((*MockUserRepository)(nil).UniqueUsername), ctx, username)
+}
+
+// UniqueEmail mocks base method
+func (m *MockUserRepository) UniqueEmail(ctx context.Context, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UniqueEmail", ctx, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UniqueEmail indicates an expected call of UniqueEmail
+func (mr *MockUserRepositoryMockRecorder) UniqueEmail(ctx, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UniqueEmail", reflect.TypeOf((*MockUserRepository)(nil).UniqueEmail), ctx, email)
+}
+
+// MockRoleRepository is a mock of RoleRepository interface
+type MockRoleRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRoleRepositoryMockRecorder
+}
+
+// MockRoleRepositoryMockRecorder is the mock recorder for MockRoleRepository
+type MockRoleRepositoryMockRecorder struct {
+	mock *MockRoleRepository
+}
+
+// NewMockRoleRepository creates a new mock instance
+func NewMockRoleRepository(ctrl *gomock.Controller) *MockRoleRepository {
+	mock := &MockRoleRepository{ctrl: ctrl}
+	mock.recorder = &MockRoleRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockRoleRepository) EXPECT() *MockRoleRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Find mocks base method
+func (m *MockRoleRepository) Find(ctx context.Context, id int) (*role.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Find", ctx, id)
+	ret0, _ := ret[0].(*role.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Find indicates an expected call of Find
+func (mr *MockRoleRepositoryMockRecorder) Find(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockRoleRepository)(nil).Find), ctx, id)
+}
+
+// MockValidater is a mock of Validater interface
+type MockValidater struct {
+	ctrl     *gomock.Controller
+	recorder *MockValidaterMockRecorder
+}
+
+// MockValidaterMockRecorder is the mock recorder for MockValidater
+type MockValidaterMockRecorder struct {
+	mock *MockValidater
+}
+
+// NewMockValidater creates a new mock instance
+func NewMockValidater(ctrl *gomock.Controller) *MockValidater {
+	mock := &MockValidater{ctrl: ctrl}
+	mock.recorder = &MockValidaterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockValidater) EXPECT() *MockValidaterMockRecorder {
+	return m.recorder
+}
+
+// Validate mocks base method
+func (m *MockValidater) Validate(ctx context.Context, form *Form) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Validate", ctx, form)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Validate indicates an expected call of Validate
+func (mr *MockValidaterMockRecorder) Validate(ctx, form interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Validate", reflect.TypeOf((*MockValidater)(nil).Validate), ctx, form)
+}
+
+// ValidateAccept mocks base method
+func (m *MockValidater) ValidateAccept(ctx context.Context, form *AcceptForm) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ValidateAccept", ctx, form)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ValidateAccept indicates an expected call of ValidateAccept
+func (mr *MockValidaterMockRecorder) ValidateAccept(ctx, form interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateAccept", reflect.TypeOf((*MockValidater)(nil).ValidateAccept), ctx, form)
+}
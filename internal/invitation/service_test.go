@@ -0,0 +1,260 @@
+package invitation
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dipress/crmifc/internal/kit/auth"
+	"github.com/dipress/crmifc/internal/kit/mail"
+	"github.com/dipress/crmifc/internal/role"
+	"github.com/dipress/crmifc/internal/user"
+	gomock "github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func Test_Invite_Service(t *testing.T) {
+	tests := []struct {
+		name           string
+		repositoryFunc func(mock *MockRepository)
+		userRepoFunc   func(mock *MockUserRepository)
+		roleRepoFunc   func(mock *MockRoleRepository)
+		validaterFunc  func(mock *MockValidater)
+		wantErr        error
+	}{
+		{
+			name: "ok",
+			repositoryFunc: func(m *MockRepository) {
+				m.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, ni *NewInvitation, inv *Invitation) error {
+						if ni.Email != "username@example.com" || ni.RoleID != 2 || ni.InvitedBy != 1 || len(ni.Hash) != 64 {
+							t.Errorf("unexpected new invitation: %+v", ni)
+						}
+						inv.Email = ni.Email
+						return nil
+					})
+			},
+			userRepoFunc: func(m *MockUserRepository) {
+				m.EXPECT().UniqueEmail(gomock.Any(), "username@example.com").Return(nil)
+			},
+			roleRepoFunc: func(m *MockRoleRepository) {
+				m.EXPECT().Find(gomock.Any(), 2).Return(&role.Role{ID: 2}, nil)
+			},
+			validaterFunc: func(m *MockValidater) {
+				m.EXPECT().Validate(gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:           "email exists",
+			repositoryFunc: func(m *MockRepository) {},
+			userRepoFunc: func(m *MockUserRepository) {
+				m.EXPECT().UniqueEmail(gomock.Any(), gomock.Any()).Return(user.ErrEmailExists)
+			},
+			roleRepoFunc: func(m *MockRoleRepository) {},
+			validaterFunc: func(m *MockValidater) {
+				m.EXPECT().Validate(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			wantErr: user.ErrEmailExists,
+		},
+		{
+			name:           "role not found",
+			repositoryFunc: func(m *MockRepository) {},
+			userRepoFunc: func(m *MockUserRepository) {
+				m.EXPECT().UniqueEmail(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			roleRepoFunc: func(m *MockRoleRepository) {
+				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(nil, role.ErrNotFound)
+			},
+			validaterFunc: func(m *MockValidater) {
+				m.EXPECT().Validate(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			wantErr: role.ErrNotFound,
+		},
+		{
+			name: "invited already",
+			repositoryFunc: func(m *MockRepository) {
+				m.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Return(ErrExists)
+			},
+			userRepoFunc: func(m *MockUserRepository) {
+				m.EXPECT().UniqueEmail(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			roleRepoFunc: func(m *MockRoleRepository) {
+				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&role.Role{ID: 2}, nil)
+			},
+			validaterFunc: func(m *MockValidater) {
+				m.EXPECT().Validate(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			wantErr: ErrExists,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := NewMockRepository(ctrl)
+			userRepo := NewMockUserRepository(ctrl)
+			roleRepo := NewMockRoleRepository(ctrl)
+			validater := NewMockValidater(ctrl)
+			mailer := mail.NewMemoryMailer()
+			s := NewService(repo, userRepo, roleRepo, validater, mailer)
+
+			tc.repositoryFunc(repo)
+			tc.userRepoFunc(userRepo)
+			tc.roleRepoFunc(roleRepo)
+			tc.validaterFunc(validater)
+
+			claims := auth.Claims{User: user.User{ID: 1}}
+			_, err := s.Invite(context.Background(), &claims, &Form{Email: "username@example.com", RoleID: 2})
+			if tc.wantErr != nil {
+				assert.Equal(t, tc.wantErr, errors.Cause(err))
+				assert.Empty(t, mailer.Messages())
+				return
+			}
+
+			assert.Nil(t, err)
+			messages := mailer.Messages()
+			if assert.Len(t, messages, 1) {
+				assert.Equal(t, "username@example.com", messages[0].To)
+				assert.Contains(t, messages[0].Body, DefaultAcceptURL+"?token=")
+			}
+		})
+	}
+}
+
+func Test_Resend_Service(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := NewMockRepository(ctrl)
+	mailer := mail.NewMemoryMailer()
+	s := NewService(repo, NewMockUserRepository(ctrl), NewMockRoleRepository(ctrl), NewMockValidater(ctrl), mailer)
+
+	var hash string
+	repo.EXPECT().Renew(gomock.Any(), 1, gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, id int, h string, expiresAt time.Time, inv *Invitation) error {
+			hash = h
+			inv.Email = "username@example.com"
+			return nil
+		})
+	repo.EXPECT().Renew(gomock.Any(), 2, gomock.Any(), gomock.Any(), gomock.Any()).Return(ErrNotFound)
+
+	if _, err := s.Resend(context.Background(), 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	messages := mailer.Messages()
+	if assert.Len(t, messages, 1) {
+		token := tokenOf(t, messages[0].Body)
+		assert.Equal(t, hashToken(token), hash)
+	}
+
+	_, err := s.Resend(context.Background(), 2)
+	assert.NotNil(t, err)
+	assert.Len(t, mailer.Messages(), 1)
+}
+
+func Test_Accept_Service(t *testing.T) {
+	token := "token"
+
+	tests := []struct {
+		name           string
+		repositoryFunc func(mock *MockRepository)
+		userRepoFunc   func(mock *MockUserRepository)
+		wantErr        error
+	}{
+		{
+			name: "ok",
+			repositoryFunc: func(m *MockRepository) {
+				m.EXPECT().FindByToken(gomock.Any(), hashToken(token)).Return(&Invitation{ID: 1, Email: "username@example.com", RoleID: 2}, nil)
+				m.EXPECT().Accept(gomock.Any(), 1, gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, id int, nu *user.NewUser, u *user.User) error {
+						if nu.Email != "username@example.com" || nu.RoleID != 2 || nu.Username != "username" {
+							t.Errorf("unexpected new user: %+v", nu)
+						}
+						if err := bcrypt.CompareHashAndPassword([]byte(nu.PasswordHash), []byte("password123")); err != nil {
+							t.Errorf("unexpected password hash: %v", err)
+						}
+						return nil
+					})
+			},
+			userRepoFunc: func(m *MockUserRepository) {
+				m.EXPECT().UniqueUsername(gomock.Any(), "username").Return(nil)
+				m.EXPECT().UniqueEmail(gomock.Any(), "username@example.com").Return(nil)
+			},
+		},
+		{
+			name: "invalid token",
+			repositoryFunc: func(m *MockRepository) {
+				m.EXPECT().FindByToken(gomock.Any(), gomock.Any()).Return(nil, ErrInvalidToken)
+			},
+			userRepoFunc: func(m *MockUserRepository) {},
+			wantErr:      ErrInvalidToken,
+		},
+		{
+			name: "username exists",
+			repositoryFunc: func(m *MockRepository) {
+				m.EXPECT().FindByToken(gomock.Any(), gomock.Any()).Return(&Invitation{ID: 1, Email: "username@example.com"}, nil)
+			},
+			userRepoFunc: func(m *MockUserRepository) {
+				m.EXPECT().UniqueUsername(gomock.Any(), gomock.Any()).Return(user.ErrUsernameExists)
+			},
+			wantErr: user.ErrUsernameExists,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := NewMockRepository(ctrl)
+			userRepo := NewMockUserRepository(ctrl)
+			validater := NewMockValidater(ctrl)
+			s := NewService(repo, userRepo, NewMockRoleRepository(ctrl), validater, mail.NewMemoryMailer())
+
+			tc.repositoryFunc(repo)
+			tc.userRepoFunc(userRepo)
+			if tc.wantErr != ErrInvalidToken {
+				validater.EXPECT().ValidateAccept(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, f *AcceptForm) error {
+						if f.Email == "" {
+							t.Errorf("the email of the invitation isn't set: %+v", f)
+						}
+						return nil
+					})
+			}
+
+			_, err := s.Accept(context.Background(), token, &AcceptForm{Username: "username", Password: "password123"})
+			if tc.wantErr != nil {
+				assert.Equal(t, tc.wantErr, errors.Cause(err))
+				return
+			}
+			assert.Nil(t, err)
+		})
+	}
+}
+
+// tokenOf returns the token of the link in the body.
+func tokenOf(t *testing.T, body string) string {
+	i := strings.Index(body, "?token=")
+	if i < 0 {
+		t.Fatalf("link isn't found in %q", body)
+	}
+
+	raw := strings.Fields(body[i+len("?token="):])[0]
+	token, err := url.QueryUnescape(raw)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return token
+}
@@ -2,40 +2,97 @@ package auth
 
 import (
 	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
 	"crypto/rsa"
-	"fmt"
+	"encoding/hex"
 	"time"
 
 	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/dipress/crmifc/internal/role"
 	"github.com/dipress/crmifc/internal/user"
 	"github.com/pkg/errors"
 )
 
 var (
 	contextKeyClaims = contextKey("claims")
+
+	// ErrRevoked returns when the token was revoked before it expired.
+	ErrRevoked = errors.New("token is revoked")
+	// ErrOutdated returns when the token version is older than the
+	// version of the user, e.g. after the role or password change.
+	ErrOutdated = errors.New("token is outdated")
+	// ErrMFAPending returns when the token of the unfinished
+	// two-factor sign in is used as the access token.
+	ErrMFAPending = errors.New("token is mfa pending")
+	// ErrDeactivated returns when the user of the token is deactivated.
+	ErrDeactivated = errors.New("user is deactivated")
 )
 
+// MFAAudience is the audience of the tokens which are issued after the
+// password check of the two-factor sign in. They only allow to pass
+// the second step and are refused as access tokens.
+const MFAAudience = "mfa"
+
 // Claims represents the authorization claims transmitted via a JWT.
+// The user data is signed into the token, so it isn't loaded on each
+// request. The token version tells whether the data is still actual.
 type Claims struct {
 	jwt.StandardClaims
-	User user.User
+	UserID       int               `json:"uid"`
+	Username     string            `json:"username"`
+	RoleID       int               `json:"role_id"`
+	RoleName     string            `json:"role"`
+	Permissions  []role.Permission `json:"permissions"`
+	TokenVersion int               `json:"ver"`
+	SessionID    int               `json:"sid,omitempty"`
+	User         user.User         `json:"-"`
+	// APIKeyID is set when the request is authenticated
+	// by the api key instead of the token.
+	APIKeyID int `json:"-"`
 }
 
 // NewClaims constructs a Claims value for the identified user. The Claims
 // expire within a specified duration of the provided time. Additional fields
 // of the Claims can be set after calling NewClaims is desired.
-func NewClaims(email string, now time.Time, expires time.Duration) Claims {
+func NewClaims(u *user.User, now time.Time, expires time.Duration) Claims {
 	c := Claims{
 		StandardClaims: jwt.StandardClaims{
-			Subject:   email,
+			Id:        NewTokenID(),
+			Subject:   u.Email,
 			IssuedAt:  now.Unix(),
 			ExpiresAt: now.Add(expires).Unix(),
 		},
+		UserID:       u.ID,
+		Username:     u.Username,
+		RoleID:       u.Role.ID,
+		RoleName:     u.Role.Name,
+		Permissions:  u.Role.Permissions,
+		TokenVersion: u.TokenVersion,
 	}
 
 	return c
 }
 
+// NewMFAClaims constructs the claims of the mfa pending token of the user.
+func NewMFAClaims(u *user.User, now time.Time, expires time.Duration) Claims {
+	c := NewClaims(u, now, expires)
+	c.Audience = MFAAudience
+	return c
+}
+
+// NewTokenID returns a random identifier of the token
+// which is used to revoke it.
+func NewTokenID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(errors.Wrap(err, "read random bytes"))
+	}
+	return hex.EncodeToString(b)
+}
+
 // Valid is called during the parsing of a token.
 func (c Claims) Valid() error {
 	if err := c.StandardClaims.Valid(); err != nil {
@@ -44,63 +101,47 @@ func (c Claims) Valid() error {
 	return nil
 }
 
-// KeyFunc is used to map a JWT key id (kid) to the corresponding public key.
-// It is a requirement for creating an Authenticator.
-//
-// * Private keys should be rotated. During the transition period, tokens
-// signed with the old and new keys can coexist by looking up the correct
-// public key by key id (kid).
-//
-// * Key-id-to-public-key resolution is usually accomplished via a public JWKS
-// endpoint. See https://auth0.com/docs/jwks for more details.
-type KeyFunc func(keyID string) (*rsa.PublicKey, error)
-
-// NewSingleKeyFunc is a simple implementation of KeyFunc that only ever
-// supports one key. This is easy for development but in production should be
-// replaced with a caching layer that calls a JWKS endpoint.
-func NewSingleKeyFunc(id string, key *rsa.PublicKey) KeyFunc {
-	return func(kid string) (*rsa.PublicKey, error) {
-		if id != kid {
-			return nil, fmt.Errorf("unrecognized kid %q", kid)
-		}
-		return key, nil
-	}
+// Repository holds the current token versions of users.
+type Repository interface {
+	TokenVersion(ctx context.Context, userID int) (int, error)
 }
 
-// Repository holds find user by email action.
-type Repository interface {
-	FindByEmail(ctx context.Context, email string) (*user.User, error)
+// RevocationList holds identifiers of the tokens and the sessions
+// which were revoked before they expired.
+type RevocationList interface {
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+	IsSessionRevoked(ctx context.Context, id int) (bool, error)
 }
 
 // Authenticator is used to authenticate clients. It can generate a token for a
 // set of user claims and recreate the claims by parsing the token.
 type Authenticator struct {
-	privateKey *rsa.PrivateKey
-	keyID      string
-	algorithm  string
-	kf         KeyFunc
-	parser     *jwt.Parser
+	keys      *KeySet
+	algorithm string
+	parser    *jwt.Parser
+	versions  *versionCache
 	Repository
+	RevocationList
 }
 
 // NewAuthenticator creates an *Authenticator for use. It will error if:
-// - The private key is nil.
-// - The public key func is nil.
-// - The key ID is blank.
+// - The key set is nil.
 // - The specified algorithm is unsupported.
-func NewAuthenticator(key *rsa.PrivateKey, keyID, algorithm string, publicKeyFunc KeyFunc, r Repository) (*Authenticator, error) {
-	if key == nil {
-		return nil, errors.New("private key cannot be nil")
-	}
-	if publicKeyFunc == nil {
-		return nil, errors.New("public key function cannot be nil")
-	}
-	if keyID == "" {
-		return nil, errors.New("keyID cannot be blank")
+// - The active key doesn't match the algorithm.
+// - The revocation list is nil.
+func NewAuthenticator(keys *KeySet, algorithm string, r Repository, rl RevocationList) (*Authenticator, error) {
+	if keys == nil {
+		return nil, errors.New("key set cannot be nil")
 	}
 	if jwt.GetSigningMethod(algorithm) == nil {
 		return nil, errors.Errorf("unknown algorithm %v", algorithm)
 	}
+	if _, key := keys.SigningKey(); !matchKey(algorithm, key) {
+		return nil, errors.Errorf("active key %T doesn't match algorithm %v", key, algorithm)
+	}
+	if rl == nil {
+		return nil, errors.New("revocation list cannot be nil")
+	}
 
 	// Create the token parser to use. The algorithm used to sign the JWT must be
 	// validated to avoid a critical vulnerability:
@@ -110,25 +151,46 @@ func NewAuthenticator(key *rsa.PrivateKey, keyID, algorithm string, publicKeyFun
 	}
 
 	a := Authenticator{
-		privateKey: key,
-		keyID:      keyID,
-		algorithm:  algorithm,
-		kf:         publicKeyFunc,
-		parser:     &parser,
-		Repository: r,
+		keys:           keys,
+		algorithm:      algorithm,
+		parser:         &parser,
+		versions:       newVersionCache(versionTTL),
+		Repository:     r,
+		RevocationList: rl,
 	}
 
 	return &a, nil
 }
 
+// matchKey checks that the private key can sign tokens with the algorithm.
+// Symmetric algorithms are never matched, so the public keys can't be
+// used as secrets to forge the tokens.
+func matchKey(algorithm string, key crypto.Signer) bool {
+	switch method := jwt.GetSigningMethod(algorithm).(type) {
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+		_, ok := key.(*rsa.PrivateKey)
+		return ok
+	case *jwt.SigningMethodECDSA:
+		k, ok := key.(*ecdsa.PrivateKey)
+		return ok && k.Curve.Params().BitSize == method.CurveBits
+	case *signingMethodEdDSA:
+		_, ok := key.(ed25519.PrivateKey)
+		return ok
+	default:
+		return false
+	}
+}
+
 // GenerateToken generates a signed JWT token string representing the user Claims.
 func (a *Authenticator) GenerateToken(ctx context.Context, claims jwt.Claims) (string, error) {
 	method := jwt.GetSigningMethod(a.algorithm)
 
+	kid, key := a.keys.SigningKey()
+
 	tkn := jwt.NewWithClaims(method, claims)
-	tkn.Header["kid"] = a.keyID
+	tkn.Header["kid"] = kid
 
-	str, err := tkn.SignedString(a.privateKey)
+	str, err := tkn.SignedString(key)
 	if err != nil {
 		return "", errors.Wrap(err, "signing token")
 	}
@@ -137,12 +199,42 @@ func (a *Authenticator) GenerateToken(ctx context.Context, claims jwt.Claims) (s
 }
 
 // ParseClaims recreates the Claims that were used to generate a token. It
-// verifies that the token was signed using our key.
+// verifies that the token was signed using any of our keys which are
+// not retired, that the token version is still actual and the user
+// isn't deactivated.
 func (a *Authenticator) ParseClaims(ctx context.Context, tknStr string) (Claims, error) {
+	claims, err := a.parse(ctx, tknStr)
+	if err != nil {
+		return Claims{}, err
+	}
+
+	if claims.Audience == MFAAudience {
+		return Claims{}, ErrMFAPending
+	}
+
+	return claims, nil
+}
+
+// ParseMFAClaims recreates the Claims of the mfa pending token.
+// Access tokens are refused.
+func (a *Authenticator) ParseMFAClaims(ctx context.Context, tknStr string) (Claims, error) {
+	claims, err := a.parse(ctx, tknStr)
+	if err != nil {
+		return Claims{}, err
+	}
+
+	if claims.Audience != MFAAudience {
+		return Claims{}, errors.New("not an mfa token")
+	}
+
+	return claims, nil
+}
+
+func (a *Authenticator) parse(ctx context.Context, tknStr string) (Claims, error) {
 
 	// f is a function that returns the public key for validating a token. We use
 	// the parsed (but unverified) token to find the key id. That ID is passed to
-	// our KeyFunc to find the public key to use for verification.
+	// our key set to find the public key to use for verification.
 	f := func(t *jwt.Token) (interface{}, error) {
 		kid, ok := t.Header["kid"]
 		if !ok {
@@ -153,11 +245,11 @@ func (a *Authenticator) ParseClaims(ctx context.Context, tknStr string) (Claims,
 			return nil, errors.New("token key id (kid) must be string")
 		}
 
-		return a.kf(kidStr)
+		return a.keys.PublicKey(kidStr)
 	}
 
 	var claims Claims
-	tkn, err := a.parser.ParseWithClaims(tknStr, &claims.StandardClaims, f)
+	tkn, err := a.parser.ParseWithClaims(tknStr, &claims, f)
 	if err != nil {
 		return Claims{}, errors.Wrap(err, "parsing token")
 	}
@@ -166,19 +258,70 @@ func (a *Authenticator) ParseClaims(ctx context.Context, tknStr string) (Claims,
 		return Claims{}, errors.New("invalid token")
 	}
 
-	user, err := a.Repository.FindByEmail(ctx, claims.Subject)
+	// Tokens issued before the user data was signed into them
+	// have to be issued again.
+	if claims.UserID == 0 {
+		return Claims{}, errors.New("missing user claims")
+	}
+
+	// Tokens issued before identifiers were added can't be revoked
+	// and stay valid until they expire.
+	if claims.Id != "" {
+		revoked, err := a.RevocationList.IsRevoked(ctx, claims.Id)
+		if err != nil {
+			return Claims{}, errors.Wrap(err, "check revocation list")
+		}
+		if revoked {
+			return Claims{}, ErrRevoked
+		}
+	}
+
+	// Tokens issued before sessions were added don't belong to any.
+	if claims.SessionID != 0 {
+		revoked, err := a.RevocationList.IsSessionRevoked(ctx, claims.SessionID)
+		if err != nil {
+			return Claims{}, errors.Wrap(err, "check session")
+		}
+		if revoked {
+			return Claims{}, ErrRevoked
+		}
+	}
+
+	// Deactivation of the user increments the token version too,
+	// so the cached version doesn't keep the tokens working longer
+	// than the version ttl.
+	version, err := a.versions.get(ctx, claims.UserID, claims.TokenVersion, a.Repository.TokenVersion)
 	if err != nil {
-		return Claims{}, errors.Wrap(err, "find user by email")
+		if errors.Cause(err) == user.ErrDeactivated {
+			return Claims{}, ErrDeactivated
+		}
+		return Claims{}, errors.Wrap(err, "token version")
 	}
 
-	claims.User.ID = user.ID
-	claims.User.Role.ID = user.Role.ID
-	claims.User.Role.Name = user.Role.Name
-	claims.User.Username = user.Username
+	if claims.TokenVersion != version {
+		return Claims{}, ErrOutdated
+	}
+
+	claims.User = user.User{
+		ID:       claims.UserID,
+		Username: claims.Username,
+		Email:    claims.Subject,
+		Role: role.Role{
+			ID:          claims.RoleID,
+			Name:        claims.RoleName,
+			Permissions: claims.Permissions,
+		},
+		TokenVersion: claims.TokenVersion,
+	}
 
 	return claims, nil
 }
 
+// JWKS returns the public keys which verify tokens.
+func (a *Authenticator) JWKS() JWKS {
+	return a.keys.JWKS(a.algorithm)
+}
+
 type contextKey string
 
 func (c contextKey) String() string {
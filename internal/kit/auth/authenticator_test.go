@@ -0,0 +1,127 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dipress/crmifc/internal/user"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMFAClaims(t *testing.T) {
+	ks, err := NewKeySet("12345", newKey(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	a, err := NewAuthenticator(ks, "RS256", versionFunc(func(ctx context.Context, userID int) (int, error) {
+		return 1, nil
+	}), revocationListFunc(func(ctx context.Context, jti string) (bool, error) {
+		return false, nil
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx := context.Background()
+	u := user.User{ID: 1, Email: "username@example.com", TokenVersion: 1}
+
+	access, err := a.GenerateToken(ctx, NewClaims(&u, time.Now(), time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	pending, err := a.GenerateToken(ctx, NewMFAClaims(&u, time.Now(), time.Minute))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err = a.ParseClaims(ctx, pending)
+	assert.Equal(t, ErrMFAPending, err)
+
+	_, err = a.ParseMFAClaims(ctx, access)
+	assert.Error(t, err)
+
+	claims, err := a.ParseMFAClaims(ctx, pending)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, claims.UserID)
+}
+
+func TestParseClaimsDeactivated(t *testing.T) {
+	ks, err := NewKeySet("12345", newKey(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	a, err := NewAuthenticator(ks, "RS256", versionFunc(func(ctx context.Context, userID int) (int, error) {
+		return 0, user.ErrDeactivated
+	}), revocationListFunc(func(ctx context.Context, jti string) (bool, error) {
+		return false, nil
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx := context.Background()
+	u := user.User{ID: 1, Email: "username@example.com", TokenVersion: 1}
+
+	tkn, err := a.GenerateToken(ctx, NewClaims(&u, time.Now(), time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err = a.ParseClaims(ctx, tkn)
+	assert.Equal(t, ErrDeactivated, err)
+}
+
+func TestParseClaimsSessionRevoked(t *testing.T) {
+	ks, err := NewKeySet("12345", newKey(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	a, err := NewAuthenticator(ks, "RS256", versionFunc(func(ctx context.Context, userID int) (int, error) {
+		return 1, nil
+	}), revokedSessions{2: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx := context.Background()
+	u := user.User{ID: 1, Email: "username@example.com", TokenVersion: 1}
+
+	for _, tc := range []struct {
+		session int
+		expect  error
+	}{
+		{session: 0},
+		{session: 1},
+		{session: 2, expect: ErrRevoked},
+	} {
+		claims := NewClaims(&u, time.Now(), time.Hour)
+		claims.SessionID = tc.session
+
+		tkn, err := a.GenerateToken(ctx, claims)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		got, err := a.ParseClaims(ctx, tkn)
+		assert.Equal(t, tc.expect, err)
+		if tc.expect == nil {
+			assert.Equal(t, tc.session, got.SessionID)
+		}
+	}
+}
+
+// revokedSessions holds the revoked session ids.
+type revokedSessions map[int]bool
+
+func (r revokedSessions) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	return false, nil
+}
+
+func (r revokedSessions) IsSessionRevoked(ctx context.Context, id int) (bool, error) {
+	return r[id], nil
+}
@@ -0,0 +1,52 @@
+package auth
+
+import (
+	"crypto/ed25519"
+
+	jwt "github.com/dgrijalva/jwt-go"
+)
+
+// SigningMethodEdDSA signs tokens with Ed25519 keys,
+// jwt-go doesn't implement it.
+var SigningMethodEdDSA = &signingMethodEdDSA{}
+
+func init() {
+	jwt.RegisterSigningMethod(SigningMethodEdDSA.Alg(), func() jwt.SigningMethod {
+		return SigningMethodEdDSA
+	})
+}
+
+type signingMethodEdDSA struct{}
+
+// Alg implements jwt.SigningMethod interface.
+func (m *signingMethodEdDSA) Alg() string {
+	return "EdDSA"
+}
+
+// Verify implements jwt.SigningMethod interface.
+func (m *signingMethodEdDSA) Verify(signingString, signature string, key interface{}) error {
+	k, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return jwt.ErrInvalidKeyType
+	}
+
+	sig, err := jwt.DecodeSegment(signature)
+	if err != nil {
+		return err
+	}
+
+	if !ed25519.Verify(k, []byte(signingString), sig) {
+		return jwt.ErrSignatureInvalid
+	}
+	return nil
+}
+
+// Sign implements jwt.SigningMethod interface.
+func (m *signingMethodEdDSA) Sign(signingString string, key interface{}) (string, error) {
+	k, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return "", jwt.ErrInvalidKeyType
+	}
+
+	return jwt.EncodeSegment(ed25519.Sign(k, []byte(signingString))), nil
+}
@@ -0,0 +1,330 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// easyjson keyset.go
+
+// KeySet holds the keys used to sign and verify tokens. RSA, ECDSA
+// and Ed25519 keys are supported.
+//
+// * Private keys should be rotated. During the transition period, tokens
+// signed with the old and new keys can coexist by looking up the correct
+// public key by key id (kid). The active key signs new tokens, the others
+// only verify tokens issued before the rotation until they are retired.
+//
+// * Public keys are published as JWKS, so other services can verify tokens
+// as well. See https://auth0.com/docs/jwks for more details.
+type KeySet struct {
+	active     string
+	privateKey crypto.Signer
+	publicKeys map[string]crypto.PublicKey
+}
+
+// NewKeySet factory prepares the key set with a single key,
+// which is used both to sign and verify tokens.
+func NewKeySet(kid string, key crypto.Signer) (*KeySet, error) {
+	if kid == "" {
+		return nil, errors.New("key id cannot be blank")
+	}
+	if key == nil {
+		return nil, errors.New("private key cannot be nil")
+	}
+
+	ks := KeySet{
+		active:     kid,
+		privateKey: key,
+		publicKeys: map[string]crypto.PublicKey{
+			kid: key.Public(),
+		},
+	}
+
+	return &ks, nil
+}
+
+// LoadKeySet loads PEM keys from the files of dir named "<kid>.pem".
+// The files may hold either private or public keys, only the active
+// key must be private. Keys with retired ids are skipped, so tokens
+// signed with them are no longer accepted.
+func LoadKeySet(dir, active string, retired ...string) (*KeySet, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, errors.Wrap(err, "glob key files")
+	}
+
+	skip := make(map[string]bool, len(retired))
+	for _, kid := range retired {
+		skip[kid] = true
+	}
+
+	if skip[active] {
+		return nil, errors.Errorf("active key %q is retired", active)
+	}
+
+	ks := KeySet{
+		active:     active,
+		publicKeys: make(map[string]crypto.PublicKey),
+	}
+
+	for _, file := range files {
+		kid := strings.TrimSuffix(filepath.Base(file), ".pem")
+		if skip[kid] {
+			continue
+		}
+
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, errors.Wrapf(err, "read key %q", kid)
+		}
+
+		if key, err := ParsePrivateKey(data); err == nil {
+			ks.publicKeys[kid] = key.Public()
+			if kid == active {
+				ks.privateKey = key
+			}
+			continue
+		}
+
+		key, err := ParsePublicKey(data)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parse key %q", kid)
+		}
+		ks.publicKeys[kid] = key
+	}
+
+	if ks.privateKey == nil {
+		return nil, errors.Errorf("private key of active key %q not found", active)
+	}
+
+	return &ks, nil
+}
+
+// ParsePrivateKey parses PEM encoded PKCS1, SEC1 or PKCS8 private key.
+func ParsePrivateKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("key must be PEM encoded")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse private key")
+	}
+
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		return key, nil
+	case *ecdsa.PrivateKey:
+		return key, nil
+	case ed25519.PrivateKey:
+		return key, nil
+	default:
+		return nil, errors.Errorf("unsupported private key type %T", key)
+	}
+}
+
+// ParsePublicKey parses PEM encoded PKIX or PKCS1 public key.
+func ParsePublicKey(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("key must be PEM encoded")
+	}
+
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse public key")
+	}
+
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		return key, nil
+	case *ecdsa.PublicKey:
+		return key, nil
+	case ed25519.PublicKey:
+		return key, nil
+	default:
+		return nil, errors.Errorf("unsupported public key type %T", key)
+	}
+}
+
+// SigningKey returns the id and the private key of the active key.
+func (ks *KeySet) SigningKey() (string, crypto.Signer) {
+	return ks.active, ks.privateKey
+}
+
+// PublicKey maps a JWT key id (kid) to the corresponding public key.
+func (ks *KeySet) PublicKey(kid string) (crypto.PublicKey, error) {
+	key, ok := ks.publicKeys[kid]
+	if !ok {
+		return nil, errors.Errorf("unrecognized kid %q", kid)
+	}
+	return key, nil
+}
+
+// JWK is a public key in JSON Web Key format.
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Crv string `json:"crv,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKS is a set of public keys in JSON Web Key format.
+//easyjson:json
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns public keys of the set. RSA keys are published
+// with given algorithm when it is the RSA one.
+func (ks *KeySet) JWKS(algorithm string) JWKS {
+	kids := make([]string, 0, len(ks.publicKeys))
+	for kid := range ks.publicKeys {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	set := JWKS{
+		Keys: make([]JWK, 0, len(kids)),
+	}
+
+	for _, kid := range kids {
+		jwk := JWK{
+			Use: "sig",
+			Kid: kid,
+		}
+
+		switch key := ks.publicKeys[kid].(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.Alg = "RS256"
+			if strings.HasPrefix(algorithm, "RS") || strings.HasPrefix(algorithm, "PS") {
+				jwk.Alg = algorithm
+			}
+			jwk.N = encode(key.N.Bytes())
+			jwk.E = encode(big.NewInt(int64(key.E)).Bytes())
+		case *ecdsa.PublicKey:
+			size := (key.Curve.Params().BitSize + 7) / 8
+			jwk.Kty = "EC"
+			jwk.Crv = key.Curve.Params().Name
+			jwk.Alg = curveAlgorithms[jwk.Crv]
+			jwk.X = encode(key.X.FillBytes(make([]byte, size)))
+			jwk.Y = encode(key.Y.FillBytes(make([]byte, size)))
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.Alg = "EdDSA"
+			jwk.X = encode(key)
+		}
+
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	return set
+}
+
+// PublicKey decodes the public key of the JWK, e.g. the
+// key of the identity provider which signs ID tokens.
+func (k JWK) PublicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decode(k.N)
+		if err != nil {
+			return nil, errors.Wrap(err, "decode modulus")
+		}
+		e, err := decode(k.E)
+		if err != nil {
+			return nil, errors.Wrap(err, "decode exponent")
+		}
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, errors.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decode(k.X)
+		if err != nil {
+			return nil, errors.Wrap(err, "decode x")
+		}
+		y, err := decode(k.Y)
+		if err != nil {
+			return nil, errors.Wrap(err, "decode y")
+		}
+		return &ecdsa.PublicKey{
+			Curve: curve,
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, errors.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decode(k.X)
+		if err != nil {
+			return nil, errors.Wrap(err, "decode x")
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid ed25519 key size")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, errors.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+// curveAlgorithms maps elliptic curves to ECDSA algorithms.
+var curveAlgorithms = map[string]string{
+	"P-256": "ES256",
+	"P-384": "ES384",
+	"P-521": "ES512",
+}
+
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decode(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(s)
+}
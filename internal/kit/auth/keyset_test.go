@@ -0,0 +1,307 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dipress/crmifc/internal/user"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadKeySet(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keys")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	old, active, retired := newKey(t), newKey(t), newKey(t)
+	writeKey(t, dir, "old", "PUBLIC KEY", &old.PublicKey)
+	writeKey(t, dir, "active", "RSA PRIVATE KEY", active)
+	writeKey(t, dir, "retired", "RSA PRIVATE KEY", retired)
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	writeKey(t, dir, "ed", "PRIVATE KEY", edKey)
+
+	tests := []struct {
+		name    string
+		active  string
+		retired []string
+		kids    []string
+		wantErr bool
+	}{
+		{
+			name:    "ok",
+			active:  "active",
+			retired: []string{"retired"},
+			kids:    []string{"active", "ed", "old"},
+		},
+		{
+			name:   "without retired",
+			active: "active",
+			kids:   []string{"active", "ed", "old", "retired"},
+		},
+		{
+			name:    "public active key",
+			active:  "old",
+			wantErr: true,
+		},
+		{
+			name:    "retired active key",
+			active:  "retired",
+			retired: []string{"retired"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown active key",
+			active:  "unknown",
+			wantErr: true,
+		},
+	}
+
+	// Subtests aren't parallel, the key directory
+	// is removed when the test returns.
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ks, err := LoadKeySet(dir, tc.active, tc.retired...)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.Nil(t, err)
+
+			kid, key := ks.SigningKey()
+			assert.Equal(t, tc.active, kid)
+			assert.Equal(t, active, key)
+
+			var kids []string
+			for _, k := range ks.JWKS("RS256").Keys {
+				kids = append(kids, k.Kid)
+			}
+			assert.Equal(t, tc.kids, kids)
+		})
+	}
+}
+
+func TestAuthenticatorRotation(t *testing.T) {
+	old, active := newKey(t), newKey(t)
+
+	before, err := NewKeySet("old", old)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	after := KeySet{
+		active:     "active",
+		privateKey: active,
+		publicKeys: map[string]crypto.PublicKey{
+			"old":    &old.PublicKey,
+			"active": &active.PublicKey,
+		},
+	}
+
+	retired, err := NewKeySet("active", active)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx := context.Background()
+	claims := NewClaims(&user.User{ID: 1, Email: "username@example.com", TokenVersion: 1}, time.Now(), time.Hour)
+
+	tknStr := generateToken(ctx, t, before, claims)
+
+	if _, err := newAuthenticator(t, &after).ParseClaims(ctx, tknStr); err != nil {
+		t.Errorf("expected the token of the old key to be valid: %v", err)
+	}
+
+	if _, err := newAuthenticator(t, retired).ParseClaims(ctx, tknStr); err == nil {
+		t.Error("expected the token of the retired key to be invalid")
+	}
+
+	tknStr = generateToken(ctx, t, &after, claims)
+	if _, err := newAuthenticator(t, &after).ParseClaims(ctx, tknStr); err != nil {
+		t.Errorf("expected the token of the active key to be valid: %v", err)
+	}
+}
+
+func TestAuthenticatorAlgorithms(t *testing.T) {
+	rsaKey := newKey(t)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		algorithm string
+		key       crypto.Signer
+		kty       string
+		wantErr   bool
+	}{
+		{
+			name:      "RS256",
+			algorithm: "RS256",
+			key:       rsaKey,
+			kty:       "RSA",
+		},
+		{
+			name:      "ES256",
+			algorithm: "ES256",
+			key:       ecKey,
+			kty:       "EC",
+		},
+		{
+			name:      "EdDSA",
+			algorithm: "EdDSA",
+			key:       edKey,
+			kty:       "OKP",
+		},
+		{
+			name:      "key mismatch",
+			algorithm: "ES256",
+			key:       rsaKey,
+			wantErr:   true,
+		},
+		{
+			name:      "curve mismatch",
+			algorithm: "ES384",
+			key:       ecKey,
+			wantErr:   true,
+		},
+		{
+			name:      "symmetric algorithm",
+			algorithm: "HS256",
+			key:       rsaKey,
+			wantErr:   true,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ks, err := NewKeySet("12345", tc.key)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			a, err := NewAuthenticator(ks, tc.algorithm, versionFunc(func(ctx context.Context, userID int) (int, error) {
+				return 1, nil
+			}), revocationListFunc(func(ctx context.Context, jti string) (bool, error) {
+				return false, nil
+			}))
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.Nil(t, err)
+
+			ctx := context.Background()
+			claims := NewClaims(&user.User{ID: 1, Email: "username@example.com", TokenVersion: 1}, time.Now(), time.Hour)
+
+			tknStr, err := a.GenerateToken(ctx, claims)
+			assert.Nil(t, err)
+
+			got, err := a.ParseClaims(ctx, tknStr)
+			assert.Nil(t, err)
+			assert.Equal(t, claims.Id, got.Id)
+
+			jwks := a.JWKS()
+			assert.Equal(t, tc.kty, jwks.Keys[0].Kty)
+			assert.Equal(t, tc.algorithm, jwks.Keys[0].Alg)
+
+			pub, err := jwks.Keys[0].PublicKey()
+			assert.Nil(t, err)
+			assert.Equal(t, tc.key.Public(), pub)
+		})
+	}
+}
+
+func newKey(t *testing.T) *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	return key
+}
+
+func writeKey(t *testing.T, dir, kid, typ string, key interface{}) {
+	var (
+		der []byte
+		err error
+	)
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		der = x509.MarshalPKCS1PrivateKey(k)
+	case *rsa.PublicKey:
+		der, err = x509.MarshalPKIXPublicKey(k)
+	default:
+		der, err = x509.MarshalPKCS8PrivateKey(k)
+	}
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+
+	data := pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der})
+	if err := ioutil.WriteFile(filepath.Join(dir, kid+".pem"), data, 0600); err != nil {
+		t.Fatalf("write key: %v", err)
+	}
+}
+
+func newAuthenticator(t *testing.T, ks *KeySet) *Authenticator {
+	a, err := NewAuthenticator(ks, "RS256", versionFunc(func(ctx context.Context, userID int) (int, error) {
+		return 1, nil
+	}), revocationListFunc(func(ctx context.Context, jti string) (bool, error) {
+		return false, nil
+	}))
+	if err != nil {
+		t.Fatalf("new authenticator: %v", err)
+	}
+	return a
+}
+
+func generateToken(ctx context.Context, t *testing.T, ks *KeySet, claims Claims) string {
+	tknStr, err := newAuthenticator(t, ks).GenerateToken(ctx, claims)
+	if err != nil {
+		t.Fatalf("generate token: %v", err)
+	}
+	return tknStr
+}
+
+type versionFunc func(ctx context.Context, userID int) (int, error)
+
+func (v versionFunc) TokenVersion(ctx context.Context, userID int) (int, error) {
+	return v(ctx, userID)
+}
+
+type revocationListFunc func(ctx context.Context, jti string) (bool, error)
+
+func (r revocationListFunc) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	return r(ctx, jti)
+}
+
+func (r revocationListFunc) IsSessionRevoked(ctx context.Context, id int) (bool, error) {
+	return false, nil
+}
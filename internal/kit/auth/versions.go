@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// versionTTL is how long a token version of the user
+	// is trusted without asking the repository.
+	versionTTL = 10 * time.Second
+	// versionLimit is the number of cached versions after
+	// which expired ones are dropped.
+	versionLimit = 10000
+)
+
+type cachedVersion struct {
+	version   int
+	expiresAt time.Time
+}
+
+// versionCache keeps token versions of users in memory, so
+// the repository is asked at most once per ttl for each user.
+type versionCache struct {
+	mu       sync.Mutex
+	ttl      time.Duration
+	now      func() time.Time
+	versions map[int]cachedVersion
+}
+
+func newVersionCache(ttl time.Duration) *versionCache {
+	c := versionCache{
+		ttl:      ttl,
+		now:      time.Now,
+		versions: make(map[int]cachedVersion),
+	}
+
+	return &c
+}
+
+// get returns the token version of the user. The cached version is
+// reloaded when it's expired or older than the seen one, the latter
+// means the version was changed since it was cached.
+func (c *versionCache) get(ctx context.Context, userID, seen int, load func(ctx context.Context, userID int) (int, error)) (int, error) {
+	c.mu.Lock()
+	cached, ok := c.versions[userID]
+	c.mu.Unlock()
+
+	now := c.now()
+	if ok && now.Before(cached.expiresAt) && cached.version >= seen {
+		return cached.version, nil
+	}
+
+	version, err := load(ctx, userID)
+	if err != nil {
+		return 0, errors.Wrap(err, "load token version")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.versions) >= versionLimit {
+		for id, v := range c.versions {
+			if !now.Before(v.expiresAt) {
+				delete(c.versions, id)
+			}
+		}
+	}
+
+	c.versions[userID] = cachedVersion{
+		version:   version,
+		expiresAt: now.Add(c.ttl),
+	}
+
+	return version, nil
+}
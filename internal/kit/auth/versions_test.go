@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dipress/crmifc/internal/user"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersionCache(t *testing.T) {
+	now := time.Now()
+	c := newVersionCache(time.Minute)
+	c.now = func() time.Time { return now }
+
+	var loads int
+	version := 1
+	load := func(ctx context.Context, userID int) (int, error) {
+		loads++
+		return version, nil
+	}
+
+	ctx := context.Background()
+
+	t.Log("\ttest:0\tshould load the version once per ttl")
+	{
+		for i := 0; i < 3; i++ {
+			v, err := c.get(ctx, 1, 1, load)
+			assert.Nil(t, err)
+			assert.Equal(t, 1, v)
+		}
+		assert.Equal(t, 1, loads)
+	}
+
+	t.Log("\ttest:1\tshould keep the cached version until it expires")
+	{
+		version = 2
+
+		v, err := c.get(ctx, 1, 1, load)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, v)
+
+		now = now.Add(time.Minute)
+
+		v, err = c.get(ctx, 1, 1, load)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, v)
+		assert.Equal(t, 2, loads)
+	}
+
+	t.Log("\ttest:2\tshould reload the version older than the seen one")
+	{
+		version = 3
+
+		v, err := c.get(ctx, 1, 3, load)
+		assert.Nil(t, err)
+		assert.Equal(t, 3, v)
+		assert.Equal(t, 3, loads)
+	}
+}
+
+func TestParseClaimsOutdated(t *testing.T) {
+	ks, err := NewKeySet("12345", newKey(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	a, err := NewAuthenticator(ks, "RS256", versionFunc(func(ctx context.Context, userID int) (int, error) {
+		return 2, nil
+	}), revocationListFunc(func(ctx context.Context, jti string) (bool, error) {
+		return false, nil
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx := context.Background()
+	claims := NewClaims(&user.User{ID: 1, Email: "username@example.com", TokenVersion: 1}, time.Now(), time.Hour)
+
+	tknStr, err := a.GenerateToken(ctx, claims)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := a.ParseClaims(ctx, tknStr); err != ErrOutdated {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,118 @@
+package diff
+
+import (
+	"errors"
+	"strings"
+)
+
+// MaxLines limits the changed lines of each text, the diff keeps
+// a table of their product in memory.
+const MaxLines = 2000
+
+// ErrTooLarge is returned when the texts change too many lines to compare.
+var ErrTooLarge = errors.New("texts are too large to compare")
+
+// Op is a kind of the line change.
+type Op string
+
+const (
+	// Equal marks a line which is present in both texts.
+	Equal Op = "equal"
+	// Insert marks a line which is present only in the new text.
+	Insert Op = "insert"
+	// Delete marks a line which is present only in the old text.
+	Delete Op = "delete"
+)
+
+// Line is a single line of the diff.
+type Line struct {
+	Op   Op     `json:"op"`
+	Text string `json:"text"`
+}
+
+// Lines returns line-level diff between a and b based on
+// the longest common subsequence of their lines. The common
+// leading and trailing lines aren't limited by MaxLines.
+func Lines(a, b string) ([]Line, error) {
+	x, y := split(a), split(b)
+
+	var head, tail []Line
+	for len(x) > 0 && len(y) > 0 && x[0] == y[0] {
+		head = append(head, Line{Op: Equal, Text: x[0]})
+		x, y = x[1:], y[1:]
+	}
+
+	for len(x) > 0 && len(y) > 0 && x[len(x)-1] == y[len(y)-1] {
+		tail = append([]Line{{Op: Equal, Text: x[len(x)-1]}}, tail...)
+		x, y = x[:len(x)-1], y[:len(y)-1]
+	}
+
+	if len(x) > MaxLines || len(y) > MaxLines {
+		return nil, ErrTooLarge
+	}
+
+	// lcs[i][j] holds the length of the longest common
+	// subsequence of x[i:] and y[j:].
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			switch {
+			case x[i] == y[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	lines := make([]Line, 0, len(head)+len(x)+len(y)+len(tail))
+	lines = append(lines, head...)
+	i, j := 0, 0
+	for i < len(x) && j < len(y) {
+		switch {
+		case x[i] == y[j]:
+			lines = append(lines, Line{Op: Equal, Text: x[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, Line{Op: Delete, Text: x[i]})
+			i++
+		default:
+			lines = append(lines, Line{Op: Insert, Text: y[j]})
+			j++
+		}
+	}
+
+	for ; i < len(x); i++ {
+		lines = append(lines, Line{Op: Delete, Text: x[i]})
+	}
+
+	for ; j < len(y); j++ {
+		lines = append(lines, Line{Op: Insert, Text: y[j]})
+	}
+
+	return append(lines, tail...), nil
+}
+
+// Changed checks that lines contain at least one change.
+func Changed(lines []Line) bool {
+	for _, l := range lines {
+		if l.Op != Equal {
+			return true
+		}
+	}
+	return false
+}
+
+func split(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
@@ -0,0 +1,92 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		name   string
+		a      string
+		b      string
+		expect []Line
+	}{
+		{
+			name:   "both empty",
+			expect: []Line{},
+		},
+		{
+			name: "equal",
+			a:    "one\ntwo",
+			b:    "one\ntwo",
+			expect: []Line{
+				{Op: Equal, Text: "one"},
+				{Op: Equal, Text: "two"},
+			},
+		},
+		{
+			name: "insert",
+			a:    "one\nthree",
+			b:    "one\ntwo\nthree",
+			expect: []Line{
+				{Op: Equal, Text: "one"},
+				{Op: Insert, Text: "two"},
+				{Op: Equal, Text: "three"},
+			},
+		},
+		{
+			name: "delete",
+			a:    "one\ntwo\nthree",
+			b:    "one\nthree",
+			expect: []Line{
+				{Op: Equal, Text: "one"},
+				{Op: Delete, Text: "two"},
+				{Op: Equal, Text: "three"},
+			},
+		},
+		{
+			name: "replace",
+			a:    "one\ntwo",
+			b:    "one\n2",
+			expect: []Line{
+				{Op: Equal, Text: "one"},
+				{Op: Delete, Text: "two"},
+				{Op: Insert, Text: "2"},
+			},
+		},
+		{
+			name: "from empty",
+			b:    "one",
+			expect: []Line{
+				{Op: Insert, Text: "one"},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			lines, err := Lines(tc.a, tc.b)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expect, lines)
+			assert.Equal(t, tc.a != tc.b, Changed(lines))
+		})
+	}
+}
+
+func TestLinesTooLarge(t *testing.T) {
+	a := strings.Repeat("a\n", MaxLines+1)
+	b := strings.Repeat("b\n", MaxLines+1)
+
+	_, err := Lines("same\n"+a+"same", "same\n"+b+"same")
+	assert.Equal(t, ErrTooLarge, err)
+
+	lines, err := Lines(a+a, a+a)
+	assert.Nil(t, err)
+	assert.False(t, Changed(lines))
+}
@@ -0,0 +1,61 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// FileMailer drops messages into the directory as .eml files,
+// it allows to read them during the local development.
+type FileMailer struct {
+	dir  string
+	from string
+}
+
+// NewFileMailer factory prepares the mailer to work.
+func NewFileMailer(dir, from string) *FileMailer {
+	m := FileMailer{
+		dir:  dir,
+		from: from,
+	}
+
+	return &m
+}
+
+// Send implements Mailer interface.
+func (f *FileMailer) Send(ctx context.Context, m *Message) error {
+	now := time.Now()
+
+	data, err := m.bytes(f.from, now)
+	if err != nil {
+		return errors.Wrap(err, "format message")
+	}
+
+	if err := os.MkdirAll(f.dir, 0755); err != nil {
+		return errors.Wrap(err, "make dir")
+	}
+
+	name := fmt.Sprintf("%d-%s.eml", now.UnixNano(), strings.Map(safeRune, m.To))
+	if err := ioutil.WriteFile(filepath.Join(f.dir, name), data, 0644); err != nil {
+		return errors.Wrap(err, "write file")
+	}
+
+	return nil
+}
+
+// safeRune keeps only the characters which are safe in file names.
+func safeRune(r rune) rune {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '@', r == '.', r == '-', r == '_':
+		return r
+	default:
+		return '_'
+	}
+}
@@ -0,0 +1,44 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Message is an email message.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends email messages.
+type Mailer interface {
+	Send(ctx context.Context, m *Message) error
+}
+
+// bytes formats the message as a plain text email from given sender.
+func (m *Message) bytes(from string, now time.Time) ([]byte, error) {
+	for _, header := range []string{from, m.To, m.Subject} {
+		if strings.ContainsAny(header, "\r\n") {
+			return nil, errors.New("header contains line break")
+		}
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", m.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", m.Subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.Replace(m.Body, "\n", "\r\n", -1))
+
+	return b.Bytes(), nil
+}
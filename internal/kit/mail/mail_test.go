@@ -0,0 +1,54 @@
+package mail
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileMailer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mail")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	m := NewFileMailer(dir, "noreply@example.com")
+
+	msg := Message{
+		To:      "username@example.com",
+		Subject: "Hello",
+		Body:    "line one\nline two",
+	}
+
+	assert.Nil(t, m.Send(context.Background(), &msg))
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if assert.Len(t, files, 1) {
+		assert.True(t, strings.HasSuffix(files[0].Name(), "-username@example.com.eml"))
+
+		data, err := ioutil.ReadFile(dir + "/" + files[0].Name())
+		assert.Nil(t, err)
+		assert.Contains(t, string(data), "To: username@example.com\r\n")
+		assert.Contains(t, string(data), "\r\n\r\nline one\r\nline two")
+	}
+}
+
+func TestMessageHeaderInjection(t *testing.T) {
+	msg := Message{
+		To:      "username@example.com\r\nBcc: other@example.com",
+		Subject: "Hello",
+	}
+
+	_, err := msg.bytes("noreply@example.com", time.Now())
+	assert.Error(t, err)
+}
@@ -0,0 +1,36 @@
+package mail
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryMailer keeps messages in memory, it is used in tests.
+type MemoryMailer struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+// NewMemoryMailer factory prepares the mailer to work.
+func NewMemoryMailer() *MemoryMailer {
+	return &MemoryMailer{}
+}
+
+// Send implements Mailer interface.
+func (mm *MemoryMailer) Send(ctx context.Context, m *Message) error {
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+
+	mm.messages = append(mm.messages, *m)
+	return nil
+}
+
+// Messages returns sent messages.
+func (mm *MemoryMailer) Messages() []Message {
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+
+	messages := make([]Message, len(mm.messages))
+	copy(messages, mm.messages)
+	return messages
+}
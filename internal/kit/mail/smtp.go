@@ -0,0 +1,51 @@
+package mail
+
+import (
+	"context"
+	"net"
+	"net/smtp"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// SMTPMailer sends messages through the SMTP server.
+type SMTPMailer struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+// NewSMTPMailer factory prepares the mailer to work. The plain
+// authentication is used when username is given.
+func NewSMTPMailer(addr, from, username, password string) (*SMTPMailer, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, errors.Wrap(err, "split host port")
+	}
+
+	m := SMTPMailer{
+		addr: addr,
+		from: from,
+	}
+
+	if username != "" {
+		m.auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return &m, nil
+}
+
+// Send implements Mailer interface.
+func (s *SMTPMailer) Send(ctx context.Context, m *Message) error {
+	data, err := m.bytes(s.from, time.Now())
+	if err != nil {
+		return errors.Wrap(err, "format message")
+	}
+
+	if err := smtp.SendMail(s.addr, s.auth, s.from, []string{m.To}, data); err != nil {
+		return errors.Wrap(err, "send mail")
+	}
+
+	return nil
+}
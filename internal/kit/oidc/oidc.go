@@ -0,0 +1,360 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/dipress/crmifc/internal/kit/auth"
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrInvalidToken returns when the ID token is malformed, isn't
+	// signed by the provider, expired or was issued for another client.
+	ErrInvalidToken = errors.New("invalid id token")
+	// ErrExchange returns when the provider refuses to exchange
+	// the authorization code for the tokens.
+	ErrExchange = errors.New("code exchange failed")
+)
+
+const (
+	// Leeway tolerates the clock drift of the provider.
+	Leeway = time.Minute
+
+	// keysRefreshAfter limits how often the keys are fetched again
+	// on an unknown key id, so forged tokens can't flood the provider.
+	keysRefreshAfter = time.Minute
+	// maxResponseSize limits the responses of the provider.
+	maxResponseSize = 1 << 20
+)
+
+// supportedAlgorithms are the asymmetric algorithms which are
+// accepted for ID tokens. Symmetric ones and "none" are refused.
+var supportedAlgorithms = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+// Config holds the client registration at the provider.
+type Config struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	// Scopes are requested in addition to "openid".
+	Scopes []string
+}
+
+// Claims are the claims of the ID token which are used to
+// find the user. The groups claim isn't standard, though
+// it is supported by the most providers.
+type Claims struct {
+	Issuer            string   `json:"iss"`
+	Subject           string   `json:"sub"`
+	Audience          Audience `json:"aud"`
+	AuthorizedParty   string   `json:"azp"`
+	ExpiresAt         int64    `json:"exp"`
+	IssuedAt          int64    `json:"iat"`
+	Nonce             string   `json:"nonce"`
+	Email             string   `json:"email"`
+	EmailVerified     *bool    `json:"email_verified"`
+	Name              string   `json:"name"`
+	PreferredUsername string   `json:"preferred_username"`
+	Groups            []string `json:"groups"`
+}
+
+// Valid is called during the parsing of a token. The claims are
+// checked by Verify, since the checks need the provider settings.
+func (c *Claims) Valid() error {
+	return nil
+}
+
+// Audience is either a single audience or the list of them.
+type Audience []string
+
+// UnmarshalJSON implements json.Unmarshaler interface.
+func (a *Audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = Audience{single}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return errors.Wrap(err, "unmarshal audience")
+	}
+	*a = list
+	return nil
+}
+
+// Contains checks that the audience contains aud.
+func (a Audience) Contains(aud string) bool {
+	for _, v := range a {
+		if v == aud {
+			return true
+		}
+	}
+	return false
+}
+
+// discovery is the provider metadata document.
+type discovery struct {
+	Issuer                string   `json:"issuer"`
+	AuthorizationEndpoint string   `json:"authorization_endpoint"`
+	TokenEndpoint         string   `json:"token_endpoint"`
+	JWKSURI               string   `json:"jwks_uri"`
+	Algorithms            []string `json:"id_token_signing_alg_values_supported"`
+}
+
+// Provider is the client of the OpenID Connect provider.
+type Provider struct {
+	config    Config
+	endpoints discovery
+	parser    *jwt.Parser
+	client    *http.Client
+
+	mu        sync.Mutex
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+}
+
+// NewProvider factory prepares the provider to work. The endpoints are
+// discovered from the provider metadata of the issuer.
+func NewProvider(ctx context.Context, config Config, client *http.Client) (*Provider, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	p := Provider{
+		config: config,
+		client: client,
+	}
+
+	wellKnown := strings.TrimSuffix(config.Issuer, "/") + "/.well-known/openid-configuration"
+	if err := p.get(ctx, wellKnown, &p.endpoints); err != nil {
+		return nil, errors.Wrap(err, "discovery")
+	}
+
+	// The issuer of the metadata must be exactly the configured
+	// one, the ID tokens are checked against it.
+	if p.endpoints.Issuer != config.Issuer {
+		return nil, errors.Errorf("issuer %q doesn't match %q", p.endpoints.Issuer, config.Issuer)
+	}
+
+	algorithms := p.endpoints.Algorithms
+	if len(algorithms) == 0 {
+		algorithms = []string{"RS256"}
+	}
+
+	var methods []string
+	for _, alg := range algorithms {
+		for _, supported := range supportedAlgorithms {
+			if alg == supported {
+				methods = append(methods, alg)
+			}
+		}
+	}
+
+	if len(methods) == 0 {
+		return nil, errors.Errorf("unsupported algorithms %v", algorithms)
+	}
+
+	p.parser = &jwt.Parser{
+		ValidMethods: methods,
+	}
+
+	return &p, nil
+}
+
+// AuthCodeURL returns the provider URL which the user is redirected
+// to for the sign in. The state and the nonce tie the callback to
+// this request, the challenge is the PKCE code challenge.
+func (p *Provider) AuthCodeURL(state, nonce, challenge string) string {
+	v := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.config.ClientID},
+		"redirect_uri":          {p.config.RedirectURL},
+		"scope":                 {strings.Join(append([]string{"openid"}, p.config.Scopes...), " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {challenge},
+		"code_challenge_method": {"S256"},
+	}
+
+	sep := "?"
+	if strings.Contains(p.endpoints.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+
+	return p.endpoints.AuthorizationEndpoint + sep + v.Encode()
+}
+
+// tokenResponse is the response of the token endpoint.
+type tokenResponse struct {
+	IDToken string `json:"id_token"`
+}
+
+// Exchange exchanges the authorization code for the tokens and
+// returns the verified claims of the ID token.
+func (p *Provider) Exchange(ctx context.Context, code, verifier, nonce string) (*Claims, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.config.RedirectURL},
+		"code_verifier": {verifier},
+	}
+
+	req, err := http.NewRequest(http.MethodPost, p.endpoints.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, errors.Wrap(err, "new request")
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+
+	resp, err := p.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, errors.Wrap(err, "do request")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Wrapf(ErrExchange, "status %d", resp.StatusCode)
+	}
+
+	var tr tokenResponse
+	if err := decode(resp.Body, &tr); err != nil {
+		return nil, errors.Wrap(err, "decode token response")
+	}
+
+	if tr.IDToken == "" {
+		return nil, errors.Wrap(ErrExchange, "missing id token")
+	}
+
+	return p.Verify(ctx, tr.IDToken, nonce)
+}
+
+// Verify verifies the signature of the ID token by the provider
+// keys and checks that it was issued for the client with the nonce.
+func (p *Provider) Verify(ctx context.Context, rawIDToken, nonce string) (*Claims, error) {
+	var claims Claims
+	tkn, err := p.parser.ParseWithClaims(rawIDToken, &claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return p.key(ctx, kid)
+	})
+	if err != nil || !tkn.Valid {
+		return nil, errors.Wrapf(ErrInvalidToken, "parse: %v", err)
+	}
+
+	now := time.Now()
+
+	switch {
+	case claims.Issuer != p.config.Issuer:
+		return nil, errors.Wrap(ErrInvalidToken, "issuer mismatch")
+	case !claims.Audience.Contains(p.config.ClientID):
+		return nil, errors.Wrap(ErrInvalidToken, "audience mismatch")
+	case len(claims.Audience) > 1 && claims.AuthorizedParty != p.config.ClientID:
+		return nil, errors.Wrap(ErrInvalidToken, "authorized party mismatch")
+	case now.After(time.Unix(claims.ExpiresAt, 0).Add(Leeway)):
+		return nil, errors.Wrap(ErrInvalidToken, "expired")
+	case now.Add(Leeway).Before(time.Unix(claims.IssuedAt, 0)):
+		return nil, errors.Wrap(ErrInvalidToken, "issued in the future")
+	case claims.Nonce != nonce:
+		return nil, errors.Wrap(ErrInvalidToken, "nonce mismatch")
+	}
+
+	return &claims, nil
+}
+
+// key returns the provider key by id. The keys are fetched again when
+// the id is unknown, since the provider may have rotated them.
+func (p *Provider) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := p.lookup(kid); ok {
+		return key, nil
+	}
+
+	if time.Since(p.fetchedAt) < keysRefreshAfter {
+		return nil, errors.Errorf("unknown key id %q", kid)
+	}
+
+	var set auth.JWKS
+	if err := p.get(ctx, p.endpoints.JWKSURI, &set); err != nil {
+		return nil, errors.Wrap(err, "fetch keys")
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.PublicKey()
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+
+	p.keys = keys
+	p.fetchedAt = time.Now()
+
+	if key, ok := p.lookup(kid); ok {
+		return key, nil
+	}
+	return nil, errors.Errorf("unknown key id %q", kid)
+}
+
+// lookup finds the key by id. Tokens without the id are
+// accepted only when the provider has a single key.
+func (p *Provider) lookup(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+
+	key, ok := p.keys[kid]
+	return key, ok
+}
+
+// get fetches the JSON document from the provider into v.
+func (p *Provider) get(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return errors.Wrap(err, "new request")
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return errors.Wrap(err, "do request")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return decode(resp.Body, v)
+}
+
+func decode(r io.Reader, v interface{}) error {
+	data, err := ioutil.ReadAll(io.LimitReader(r, maxResponseSize))
+	if err != nil {
+		return errors.Wrap(err, "read body")
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return errors.Wrap(err, "unmarshal json")
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+package oidc_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/dipress/crmifc/internal/kit/oidc"
+	"github.com/dipress/crmifc/internal/kit/oidc/oidctest"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExchange(t *testing.T) {
+	idp, err := oidctest.NewServer("client", "secret")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer idp.Close()
+
+	ctx := context.Background()
+	p, err := oidc.NewProvider(ctx, oidc.Config{
+		Issuer:       idp.Issuer(),
+		ClientID:     "client",
+		ClientSecret: "secret",
+		RedirectURL:  "http://example.com/callback",
+		Scopes:       []string{"email"},
+	}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	idp.SignIn(map[string]interface{}{
+		"sub":    "1",
+		"email":  "username@example.com",
+		"groups": []string{"Manager"},
+	})
+
+	// Follow the authorization request up to the
+	// redirect of the provider to the client.
+	client := http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	verifier := "verifier"
+	resp, err := client.Get(p.AuthCodeURL("state", "nonce", oidc.Challenge(verifier)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert.Equal(t, "state", location.Query().Get("state"))
+	code := location.Query().Get("code")
+
+	t.Run("wrong verifier", func(t *testing.T) {
+		_, err := p.Exchange(ctx, code, "wrong", "nonce")
+		assert.Equal(t, oidc.ErrExchange, errors.Cause(err))
+	})
+
+	resp, err = client.Get(p.AuthCodeURL("state", "nonce", oidc.Challenge(verifier)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	location, _ = url.Parse(resp.Header.Get("Location"))
+	code = location.Query().Get("code")
+
+	t.Run("wrong nonce", func(t *testing.T) {
+		_, err := p.Exchange(ctx, code, verifier, "other")
+		assert.Equal(t, oidc.ErrInvalidToken, errors.Cause(err))
+	})
+
+	resp, err = client.Get(p.AuthCodeURL("state", "nonce", oidc.Challenge(verifier)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	location, _ = url.Parse(resp.Header.Get("Location"))
+	code = location.Query().Get("code")
+
+	t.Run("ok", func(t *testing.T) {
+		claims, err := p.Exchange(ctx, code, verifier, "nonce")
+		assert.Nil(t, err)
+		assert.Equal(t, "username@example.com", claims.Email)
+		assert.Equal(t, []string{"Manager"}, claims.Groups)
+	})
+}
+
+func TestVerify(t *testing.T) {
+	idp, err := oidctest.NewServer("client", "secret")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer idp.Close()
+
+	p, err := oidc.NewProvider(context.Background(), oidc.Config{
+		Issuer:   idp.Issuer(),
+		ClientID: "client",
+	}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		claims  map[string]interface{}
+		wantErr bool
+	}{
+		{
+			name:   "ok",
+			claims: map[string]interface{}{"sub": "1"},
+		},
+		{
+			name:   "audience list",
+			claims: map[string]interface{}{"aud": []string{"client", "other"}, "azp": "client"},
+		},
+		{
+			name:    "other audience",
+			claims:  map[string]interface{}{"aud": "other"},
+			wantErr: true,
+		},
+		{
+			name:    "other authorized party",
+			claims:  map[string]interface{}{"aud": []string{"client", "other"}, "azp": "other"},
+			wantErr: true,
+		},
+		{
+			name:    "other issuer",
+			claims:  map[string]interface{}{"iss": "http://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "expired",
+			claims:  map[string]interface{}{"exp": time.Now().Add(-time.Hour).Unix()},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			tkn, err := idp.IDToken("nonce", tc.claims)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			_, err = p.Verify(context.Background(), tkn, "nonce")
+			if tc.wantErr {
+				assert.Equal(t, oidc.ErrInvalidToken, errors.Cause(err))
+				return
+			}
+			assert.Nil(t, err)
+		})
+	}
+
+	t.Run("forged signature", func(t *testing.T) {
+		other, err := oidctest.NewServer("client", "secret")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer other.Close()
+
+		tkn, err := other.IDToken("nonce", map[string]interface{}{"iss": idp.Issuer()})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		_, err = p.Verify(context.Background(), tkn, "nonce")
+		assert.Equal(t, oidc.ErrInvalidToken, errors.Cause(err))
+	})
+}
+
+func TestChallenge(t *testing.T) {
+	// base64url(sha256(verifier)) without padding.
+	assert.Equal(t, "xLvLH77JnWW_WdhcjLYu4tuWPw_hBvSD2a-nO9Tjmoo", oidc.Challenge("correct horse battery staple"))
+}
@@ -0,0 +1,88 @@
+package password
+
+import "strings"
+
+// commonList holds the most frequent passwords of the public breach
+// dumps, lowercased and separated by spaces and newlines. It's bundled
+// so the check works offline.
+const commonList = `
+123456 password 12345678 qwerty 123456789 12345 1234 111111 1234567
+dragon 123123 baseball abc123 football monkey letmein 696969 shadow
+master 666666 qwertyuiop 123321 mustang 1234567890 michael 654321
+superman 1qaz2wsx 7777777 121212 000000 qazwsx 123qwe killer trustno1
+jordan jennifer zxcvbnm asdfgh hunter buster soccer harley batman
+andrew tigger sunshine iloveyou 2000 charlie robert thomas hockey
+ranger daniel starwars klaster 112233 george computer michelle
+jessica pepper 1111 zxcvbn 555555 11111111 131313 freedom 777777 pass
+maggie 159753 aaaaaa ginger princess joshua cheese amanda summer love
+ashley nicole chelsea matthew access yankees 987654321 dallas
+austin thunder taylor matrix william corvette hello martin heather
+secret merlin diamond 1234qwer gfhjkm hammer silver 222222 88888888
+anthony justin test bailey q1w2e3r4t5 patrick internet scooter orange
+11111 golfer cookie richard samantha bigdog guitar jackson whatever
+mickey chicken sparky snoopy maverick phoenix camaro peanut morgan
+welcome falcon cowboy ferrari samsung andrea smokey steelers joseph
+mercedes dakota arsenal eagles melissa boomer booboo spider nascar
+monster tigers yellow xxxxxx 123123123 gateway marina diablo bulldog
+qwer1234 compaq purple banana junior hannah 123654 porsche
+lakers iceman money cowboys 987654 london tennis 999999 ncc1701
+coffee scooby 0000 miller boston q1w2e3r4 brandon yamaha chester
+mother forever johnny edward 333333 oliver redsox player nikita
+knight fender barney midnight please brandy chicago badboy slayer
+rangers charles angel flower bigdaddy rabbit wizard jasper
+enter rachel chris steven winner adidas victoria natasha 1q2w3e4r
+jasmine winter prince marine ghbdtn fishing cocacola casper
+james 232323 raiders 888888 marlboro gandalf asdfasdf crystal
+87654321 12344321 golden 8675309 panther lauren angela spanky
+thx1138 angels madison winston shannon mike toyota jordan23 canada
+sophie apples tiger123 hunter2 qwerty123 qwerty1 password1 password12
+password123 passw0rd p@ssw0rd p@ssword pa55word pa55w0rd admin
+admin123 administrator root toor changeme default guest login
+welcome1 letmein1 iloveyou1 abc12345 abcd1234 abcdef abcdefg abcdefgh
+qwertyu qwertyui asdf asdfg asdfghjk asdfghjkl zxcv zxcvb 1q2w3e
+1q2w3e4r5t 1qazxsw2 zaq12wsx zaq1zaq1 qazwsxedc 123abc a1b2c3
+a1b2c3d4 aa123456 1password mypassword mypass pass123 pass1234
+passpass secret123 test123 test1234 testing demo user user123 temp
+temp123 qwe123 qweasd qweasdzxc monkey123 dragon123 football1
+baseball1 soccer1 princess1 sunshine1 superman1 batman123 starwars1
+master123 shadow123 loveme lovely iloveu babygirl baby beautiful
+butterfly cheyenne chocolate daniela friends jessica1 jesus
+liverpool manchester michael1 pokemon samsung1 snickers sweety
+tinkerbell vanessa zxcvbnm1 111222 112233445566 121314 123 1234512345
+123456a 123456789a 12345a 12345qwert 123qweasd 147258 147258369
+159357 1a2b3c 202020 246810 252525 369369 420420 456789 5201314
+654321a 741852963 789456 789456123 a123456 a12345 aaaaa aaaaaaaa
+abc123456 access14 alexander alexis amber andrew1 angel1 anthony1
+apple arsenal1 asd123 asdf1234 ashley1 austin1 banana1 barcelona
+baseball123 basketball bear benjamin blink182 blue bonjour boomer1
+brandon1 buster1 butter calvin carlos cameron captain chance cherry
+chocolate1 christian christopher claudia cookie1 cooper corona
+dallas1 danielle david dennis destiny dolphin donald eagle elephant
+elizabeth eminem family fernando flowers football123 formula1 frank
+freddy friend gabriel garfield genius george1 godzilla golf goodluck
+google green gregory hallo happy hello1 hello123 helpme hotdog house
+iloveyou2 jack jackie jaguar james1 jeremy jessie jonathan joseph1
+junior1 justin1 karina kevin kimberly kitten lakers1 legend lemon
+liberty linkin lucky maddog madonna marley martin1 maxwell melissa1
+metallica mexico michelle1 millie minecraft monkey1 morgan1 mustang1
+naruto nathan nicholas nirvana november ocean oliver1 orange1 packers
+paris password2 password3 peaches pepper1 peter phoenix1 picture
+pineapple pookie popcorn power qazwsx123 qwertz rainbow redskins
+robert1 rocket rockyou rosebud samuel sandra sarah scorpion secret1
+shadow1 simpsons skippy soccer123 sparky1 spiderman spongebob
+startrek stella steelers1 success sunflower sunshine123 superstar
+sydney teacher thomas1 thunder1 tigger1 tomcat toyota1 trinity turtle
+tweety united universal valentina victor victory viper warrior
+william1 willow wolf xbox360 yankees1 yellow1 zachary zxc123
+zxcasdqwe
+`
+
+// common is the set of the common passwords.
+var common = func() map[string]struct{} {
+	fields := strings.Fields(commonList)
+	m := make(map[string]struct{}, len(fields))
+	for _, f := range fields {
+		m[f] = struct{}{}
+	}
+	return m
+}()
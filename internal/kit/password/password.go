@@ -0,0 +1,139 @@
+package password
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+// minPersonalLength is the length from which the username and the
+// email are looked for in the password, so short names don't forbid
+// too many passwords.
+const minPersonalLength = 3
+
+var (
+	// ErrLower returns when the password has no lowercase letter.
+	ErrLower = errors.New("must contain a lowercase letter")
+	// ErrUpper returns when the password has no uppercase letter.
+	ErrUpper = errors.New("must contain an uppercase letter")
+	// ErrDigit returns when the password has no digit.
+	ErrDigit = errors.New("must contain a digit")
+	// ErrSymbol returns when the password has no symbol.
+	ErrSymbol = errors.New("must contain a symbol")
+	// ErrPersonal returns when the password contains
+	// the username or the email.
+	ErrPersonal = errors.New("must not contain the username or email")
+	// ErrCommon returns when the password is found
+	// in the list of common passwords.
+	ErrCommon = errors.New("is too common")
+)
+
+// Policy describes the passwords which are accepted.
+// The zero value accepts any password.
+type Policy struct {
+	MinLength     int
+	RequireLower  bool
+	RequireUpper  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	// Personal forbids the username and the email in the password.
+	Personal bool
+	// Common forbids the passwords of the bundled list.
+	Common bool
+}
+
+// DefaultPolicy asks for a long password which isn't guessed
+// easily instead of forcing character classes.
+var DefaultPolicy = Policy{
+	MinLength: 8,
+	Personal:  true,
+	Common:    true,
+}
+
+// Check returns the first rule the password breaks. Personal holds
+// the username, the email and so on of the password owner.
+func (p Policy) Check(password string, personal ...string) error {
+	if p.MinLength > 0 && len([]rune(password)) < p.MinLength {
+		return errors.Errorf("the length must be no less than %d", p.MinLength)
+	}
+
+	var lower, upper, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		case !unicode.IsLetter(r):
+			symbol = true
+		}
+	}
+
+	switch {
+	case p.RequireLower && !lower:
+		return ErrLower
+	case p.RequireUpper && !upper:
+		return ErrUpper
+	case p.RequireDigit && !digit:
+		return ErrDigit
+	case p.RequireSymbol && !symbol:
+		return ErrSymbol
+	}
+
+	folded := strings.ToLower(password)
+
+	if p.Personal {
+		for _, s := range personalParts(personal) {
+			if strings.Contains(folded, s) {
+				return ErrPersonal
+			}
+		}
+	}
+
+	if p.Common && IsCommon(folded) {
+		return ErrCommon
+	}
+
+	return nil
+}
+
+// IsCommon tells whether the password is in the list of common
+// passwords. The case and the digits and symbols appended to
+// a listed password don't make it less common.
+func IsCommon(password string) bool {
+	folded := strings.ToLower(password)
+	if _, ok := common[folded]; ok {
+		return true
+	}
+
+	trimmed := strings.TrimRightFunc(folded, func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	_, ok := common[trimmed]
+	return ok
+}
+
+// personalParts returns the lowercased values and the local parts
+// of the emails which are long enough to be looked for.
+func personalParts(values []string) []string {
+	var parts []string
+	for _, v := range values {
+		v = strings.ToLower(v)
+		if i := strings.LastIndex(v, "@"); i >= 0 {
+			parts = append(parts, v[:i])
+		}
+		parts = append(parts, v)
+	}
+
+	n := 0
+	for _, p := range parts {
+		if len([]rune(p)) >= minPersonalLength {
+			parts[n] = p
+			n++
+		}
+	}
+	return parts[:n]
+}
@@ -0,0 +1,62 @@
+package password
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicyCheck(t *testing.T) {
+	strict := Policy{
+		MinLength:     10,
+		RequireLower:  true,
+		RequireUpper:  true,
+		RequireDigit:  true,
+		RequireSymbol: true,
+		Personal:      true,
+		Common:        true,
+	}
+
+	tests := []struct {
+		name     string
+		policy   Policy
+		password string
+		expect   string
+	}{
+		{name: "zero policy", policy: Policy{}, password: "a"},
+		{name: "default", policy: DefaultPolicy, password: "correct horse battery"},
+		{name: "strict", policy: strict, password: "Tr0ub4dor&3x"},
+		{name: "short", policy: strict, password: "Tr0ub4d&", expect: "the length must be no less than 10"},
+		{name: "runes length", policy: Policy{MinLength: 4}, password: "пароль"},
+		{name: "no lower", policy: strict, password: "TR0UB4DOR&3X", expect: ErrLower.Error()},
+		{name: "no upper", policy: strict, password: "tr0ub4dor&3x", expect: ErrUpper.Error()},
+		{name: "no digit", policy: strict, password: "Troubador&xx", expect: ErrDigit.Error()},
+		{name: "no symbol", policy: strict, password: "Tr0ub4dor3xx", expect: ErrSymbol.Error()},
+		{name: "username", policy: DefaultPolicy, password: "my-Username-rocks", expect: ErrPersonal.Error()},
+		{name: "email local part", policy: DefaultPolicy, password: "mail.box.2019", expect: ErrPersonal.Error()},
+		{name: "common", policy: DefaultPolicy, password: "Password", expect: ErrCommon.Error()},
+		{name: "common with suffix", policy: strict, password: "Starwars123!", expect: ErrCommon.Error()},
+		{name: "common allowed", policy: Policy{MinLength: 8}, password: "password"},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			err := tc.policy.Check(tc.password, "username", "mail.box@example.com")
+			if tc.expect == "" {
+				assert.Nil(t, err)
+				return
+			}
+
+			if assert.Error(t, err) {
+				assert.Equal(t, tc.expect, err.Error())
+			}
+		})
+	}
+}
+
+func TestPersonalParts(t *testing.T) {
+	parts := personalParts([]string{"Al", "John@Example.com", ""})
+	assert.Equal(t, []string{"john", "john@example.com"}, parts)
+}
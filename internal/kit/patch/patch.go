@@ -0,0 +1,73 @@
+package patch
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+// ErrInvalid returns when the patch isn't a JSON object
+// or the patched document doesn't fit the form.
+var ErrInvalid = errors.New("invalid merge patch")
+
+// Patch is a JSON merge patch document described by RFC 7396.
+type Patch []byte
+
+// Apply merges the patch into v which has to be a pointer to a form.
+// The fields the patch doesn't mention keep their values, the ones
+// set to null are reset to zero values.
+func (p Patch) Apply(v interface{}) error {
+	var changes map[string]interface{}
+	if err := json.Unmarshal(p, &changes); err != nil || changes == nil {
+		return ErrInvalid
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return errors.Wrap(err, "marshal document")
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return errors.Wrap(err, "unmarshal document")
+	}
+
+	data, err = json.Marshal(merge(doc, changes))
+	if err != nil {
+		return errors.Wrap(err, "marshal patched document")
+	}
+
+	// The removed members have to be zero, so
+	// the patched document fills the blank form.
+	rv := reflect.ValueOf(v).Elem()
+	rv.Set(reflect.Zero(rv.Type()))
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return ErrInvalid
+	}
+	return nil
+}
+
+// merge implements MergePatch function of RFC 7396.
+func merge(target, patch interface{}) interface{} {
+	changes, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	doc, ok := target.(map[string]interface{})
+	if !ok {
+		doc = make(map[string]interface{})
+	}
+
+	for name, value := range changes {
+		if value == nil {
+			delete(doc, name)
+			continue
+		}
+		doc[name] = merge(doc[name], value)
+	}
+
+	return doc
+}
@@ -0,0 +1,78 @@
+package patch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type form struct {
+	Name     string            `json:"name"`
+	Password *string           `json:"password,omitempty"`
+	Count    int               `json:"count"`
+	Labels   map[string]string `json:"labels"`
+}
+
+func TestPatchApply(t *testing.T) {
+	secret := "secret"
+
+	tests := []struct {
+		name    string
+		patch   string
+		expect  form
+		wantErr bool
+	}{
+		{
+			name:   "empty",
+			patch:  `{}`,
+			expect: form{Name: "name", Count: 1, Labels: map[string]string{"a": "1", "b": "2"}},
+		},
+		{
+			name:   "replace",
+			patch:  `{"name":"other","password":"secret"}`,
+			expect: form{Name: "other", Password: &secret, Count: 1, Labels: map[string]string{"a": "1", "b": "2"}},
+		},
+		{
+			name:   "null resets",
+			patch:  `{"count":null,"password":null}`,
+			expect: form{Name: "name", Labels: map[string]string{"a": "1", "b": "2"}},
+		},
+		{
+			name:   "nested",
+			patch:  `{"labels":{"a":null,"c":"3"}}`,
+			expect: form{Name: "name", Count: 1, Labels: map[string]string{"b": "2", "c": "3"}},
+		},
+		{
+			name:    "not an object",
+			patch:   `["name"]`,
+			wantErr: true,
+		},
+		{
+			name:    "null",
+			patch:   `null`,
+			wantErr: true,
+		},
+		{
+			name:    "wrong type",
+			patch:   `{"count":"one"}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			f := form{Name: "name", Count: 1, Labels: map[string]string{"a": "1", "b": "2"}}
+
+			err := Patch(tc.patch).Apply(&f)
+			if tc.wantErr {
+				assert.Equal(t, ErrInvalid, err)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expect, f)
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package query
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+const (
+	// DefaultLimit is a page size used when limit isn't given.
+	DefaultLimit = 20
+	// MaxLimit is a largest allowed page size.
+	MaxLimit = 100
+	// DefaultSort is a field used for sorting when sort isn't given.
+	DefaultSort = "id"
+)
+
+var (
+	// ErrInvalidLimit returns when given limit is out of range.
+	ErrInvalidLimit = errors.New("invalid limit")
+	// ErrInvalidCursor returns when given cursor can't be decoded
+	// or doesn't belong to the requested sorting.
+	ErrInvalidCursor = errors.New("invalid cursor")
+	// ErrInvalidSort returns when given field can't be used for sorting.
+	ErrInvalidSort = errors.New("invalid sort field")
+	// ErrInvalidDirection returns when given sort direction is unknown.
+	ErrInvalidDirection = errors.New("invalid sort direction")
+	// ErrInvalidFilter returns when given field can't be used for filtering.
+	ErrInvalidFilter = errors.New("invalid filter field")
+)
+
+// Direction is a sort direction.
+type Direction string
+
+const (
+	// Asc sorts from the smallest value to the largest.
+	Asc Direction = "asc"
+	// Desc sorts from the largest value to the smallest.
+	Desc Direction = "desc"
+)
+
+// Query holds limit, cursor, sorting and filters
+// for all list actions.
+type Query struct {
+	Limit     int
+	Cursor    string
+	Sort      string
+	Direction Direction
+	Filters   map[string]string
+}
+
+// New returns a query with default values.
+func New() *Query {
+	q := Query{
+		Limit:     DefaultLimit,
+		Sort:      DefaultSort,
+		Direction: Asc,
+		Filters:   make(map[string]string),
+	}
+
+	return &q
+}
+
+// Normalize sets default values for blank fields.
+func (q *Query) Normalize() {
+	if q.Limit == 0 {
+		q.Limit = DefaultLimit
+	}
+	if q.Sort == "" {
+		q.Sort = DefaultSort
+	}
+	if q.Direction == "" {
+		q.Direction = Asc
+	}
+}
+
+// Validate checks limit and direction values.
+func (q *Query) Validate() error {
+	if q.Limit < 1 || q.Limit > MaxLimit {
+		return ErrInvalidLimit
+	}
+
+	if q.Direction != Asc && q.Direction != Desc {
+		return ErrInvalidDirection
+	}
+
+	return nil
+}
+
+// IsInvalid checks that err is caused by a wrong query.
+func IsInvalid(err error) bool {
+	switch err {
+	case ErrInvalidLimit, ErrInvalidCursor, ErrInvalidSort, ErrInvalidDirection, ErrInvalidFilter:
+		return true
+	}
+	return false
+}
+
+// Cursor points to the last item of a page.
+type Cursor struct {
+	Sort      string    `json:"s"`
+	Direction Direction `json:"d"`
+	Value     string    `json:"v"`
+	ID        int       `json:"id"`
+}
+
+// Encode returns opaque cursor string.
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses cursor string and checks that it
+// was issued for the sorting of the query.
+func DecodeCursor(q *Query) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(q.Cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	if c.Sort != q.Sort || c.Direction != q.Direction {
+		return nil, ErrInvalidCursor
+	}
+
+	return &c, nil
+}
@@ -0,0 +1,96 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  Query
+		expect error
+	}{
+		{
+			name:  "ok",
+			query: Query{Limit: DefaultLimit, Direction: Asc},
+		},
+		{
+			name:   "zero limit",
+			query:  Query{Direction: Asc},
+			expect: ErrInvalidLimit,
+		},
+		{
+			name:   "large limit",
+			query:  Query{Limit: MaxLimit + 1, Direction: Asc},
+			expect: ErrInvalidLimit,
+		},
+		{
+			name:   "unknown direction",
+			query:  Query{Limit: DefaultLimit, Direction: "up"},
+			expect: ErrInvalidDirection,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tc.query.Validate()
+			assert.Equal(t, tc.expect, err)
+		})
+	}
+}
+
+func TestDecodeCursor(t *testing.T) {
+	c := Cursor{
+		Sort:      "created_at",
+		Direction: Desc,
+		Value:     "2019-10-15 10:00:00",
+		ID:        42,
+	}
+
+	tests := []struct {
+		name    string
+		query   Query
+		wantErr bool
+	}{
+		{
+			name:  "ok",
+			query: Query{Cursor: c.Encode(), Sort: "created_at", Direction: Desc},
+		},
+		{
+			name:    "malformed",
+			query:   Query{Cursor: "!!!", Sort: "created_at", Direction: Desc},
+			wantErr: true,
+		},
+		{
+			name:    "another sort",
+			query:   Query{Cursor: c.Encode(), Sort: "id", Direction: Desc},
+			wantErr: true,
+		},
+		{
+			name:    "another direction",
+			query:   Query{Cursor: c.Encode(), Sort: "created_at", Direction: Asc},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := DecodeCursor(&tc.query)
+			if tc.wantErr {
+				assert.Equal(t, ErrInvalidCursor, err)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, c, *got)
+		})
+	}
+}
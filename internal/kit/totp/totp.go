@@ -0,0 +1,115 @@
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// Digits is the length of the code.
+	Digits = 6
+	// Period is the time step of the code.
+	Period = 30 * time.Second
+	// Skew is the number of steps before and after the current one
+	// which codes are accepted too, so the clock drift is tolerated.
+	Skew = 1
+
+	secretSize = 20
+)
+
+// ErrInvalidSecret returns when the secret isn't base32 encoded.
+var ErrInvalidSecret = errors.New("invalid secret")
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// NewSecret returns a random base32 encoded secret.
+func NewSecret() (string, error) {
+	b := make([]byte, secretSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Wrap(err, "read random bytes")
+	}
+	return encoding.EncodeToString(b), nil
+}
+
+// Code returns the code of the secret at the time t.
+func Code(secret string, t time.Time) (string, error) {
+	key, err := decode(secret)
+	if err != nil {
+		return "", err
+	}
+	return code(key, counter(t)), nil
+}
+
+// Match checks the code against the secret at the time t and the
+// steps around it. The counter of the matched step is returned, so
+// the caller can refuse to accept the same code twice.
+func Match(secret, c string, t time.Time) (int64, bool) {
+	key, err := decode(secret)
+	if err != nil || len(c) != Digits {
+		return 0, false
+	}
+
+	now := counter(t)
+	for i := -Skew; i <= Skew; i++ {
+		if subtle.ConstantTimeCompare([]byte(code(key, now+int64(i))), []byte(c)) == 1 {
+			return now + int64(i), true
+		}
+	}
+	return 0, false
+}
+
+// URI returns the otpauth URI which is understood by
+// authenticator apps, usually it is shown as a QR code.
+func URI(issuer, account, secret string) string {
+	v := url.Values{}
+	v.Set("secret", secret)
+	v.Set("issuer", issuer)
+	v.Set("algorithm", "SHA1")
+	v.Set("digits", fmt.Sprint(Digits))
+	v.Set("period", fmt.Sprint(int(Period.Seconds())))
+
+	u := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + issuer + ":" + account,
+		RawQuery: v.Encode(),
+	}
+	return u.String()
+}
+
+func decode(secret string) ([]byte, error) {
+	key, err := encoding.DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+	if err != nil || len(key) == 0 {
+		return nil, ErrInvalidSecret
+	}
+	return key, nil
+}
+
+func counter(t time.Time) int64 {
+	return t.Unix() / int64(Period.Seconds())
+}
+
+// code implements HOTP from RFC 4226 with the dynamic truncation.
+func code(key []byte, counter int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(counter))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:]) & 0x7fffffff
+
+	return fmt.Sprintf("%0*d", Digits, value%1000000)
+}
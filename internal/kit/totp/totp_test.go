@@ -0,0 +1,74 @@
+package totp
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// secret is the key of the RFC 6238 test vectors.
+const secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCode(t *testing.T) {
+	tests := []struct {
+		name   string
+		time   int64
+		expect string
+	}{
+		{name: "59", time: 59, expect: "287082"},
+		{name: "1111111109", time: 1111111109, expect: "081804"},
+		{name: "1234567890", time: 1234567890, expect: "005924"},
+		{name: "20000000000", time: 20000000000, expect: "353130"},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			c, err := Code(secret, time.Unix(tc.time, 0))
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expect, c)
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+
+	counter, ok := Match(secret, "005924", now)
+	assert.True(t, ok)
+	assert.Equal(t, int64(1234567890/30), counter)
+
+	previous, err := Code(secret, now.Add(-Period))
+	assert.Nil(t, err)
+	counter, ok = Match(secret, previous, now)
+	assert.True(t, ok)
+	assert.Equal(t, int64(1234567890/30-1), counter)
+
+	stale, err := Code(secret, now.Add(-3*Period))
+	assert.Nil(t, err)
+	_, ok = Match(secret, stale, now)
+	assert.False(t, ok)
+
+	_, ok = Match("not base32!", "005924", now)
+	assert.False(t, ok)
+}
+
+func TestNewSecret(t *testing.T) {
+	s, err := NewSecret()
+	assert.Nil(t, err)
+	assert.Len(t, s, 32)
+
+	_, err = Code(s, time.Now())
+	assert.Nil(t, err)
+}
+
+func TestURI(t *testing.T) {
+	uri := URI("crmifc", "shepard@normandy.com", secret)
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/crmifc:shepard@normandy.com?"), uri)
+	assert.Contains(t, uri, "secret="+secret)
+	assert.Contains(t, uri, "issuer=crmifc")
+}
@@ -0,0 +1,18 @@
+package tx
+
+import "context"
+
+// Transactor runs fn in a single transaction, the repositories
+// called with the context given to fn take part in it.
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// Nop runs functions without a transaction, it is
+// the default of the services until one is given.
+type Nop struct{}
+
+// WithinTx implements Transactor interface.
+func (Nop) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
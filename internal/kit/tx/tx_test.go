@@ -0,0 +1,21 @@
+package tx
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNopWithinTx(t *testing.T) {
+	ctx := context.Background()
+	mockErr := errors.New("mock error")
+
+	err := Nop{}.WithinTx(ctx, func(fnCtx context.Context) error {
+		assert.Equal(t, ctx, fnCtx)
+		return mockErr
+	})
+
+	assert.Equal(t, mockErr, err)
+}
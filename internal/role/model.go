@@ -7,28 +7,51 @@ import (
 
 // easyjson -all model.go
 
-// ErrNotFound raises when role isn't found in the database.
-var ErrNotFound = errors.New("role not found")
+var (
+	// ErrNotFound raises when role isn't found in the database.
+	ErrNotFound = errors.New("role not found")
+	// ErrUnknownPermission raises when permission isn't one of the known permissions.
+	ErrUnknownPermission = errors.New("unknown permission")
+	// ErrNameExists raises when given name is already present in database.
+	ErrNameExists = errors.New("name already exists")
+	// ErrInUse raises when role can't be deleted because users have it.
+	ErrInUse = errors.New("role is in use")
+	// ErrVersionMismatch raises when the role was changed
+	// since the version the request is based on.
+	ErrVersionMismatch = errors.New("role version mismatch")
+)
 
 // Role constains all role fields.
 type Role struct {
-	ID        int       `json:"id"`
-	Name      string    `json:"name"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID          int          `json:"id"`
+	Name        string       `json:"name"`
+	Permissions []Permission `json:"permissions"`
+	MFARequired bool         `json:"mfa_required"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+	Version     int          `json:"version"`
 }
 
 // NewRole contains the information which needs to create a new Role.
 type NewRole struct {
-	Name string `json:"name"`
+	Name        string `json:"name"`
+	MFARequired bool   `json:"mfa_required"`
 }
 
 // Form is a role form.
 type Form struct {
 	Name string `json:"name"`
+	// MFARequired enforces the two-factor sign in for the role users.
+	MFARequired bool `json:"mfa_required"`
 }
 
 // Roles contains slice of roles.
 type Roles struct {
-	Roles []Role `json:"roles"`
+	Roles      []Role `json:"roles"`
+	NextCursor string `json:"next_cursor"`
+}
+
+// PermissionList contains slice of permissions.
+type PermissionList struct {
+	Permissions []Permission `json:"permissions"`
 }
@@ -59,6 +59,8 @@ func easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalRole(in *jlexer.Lexer,
 				}
 				in.Delim(']')
 			}
+		case "next_cursor":
+			out.NextCursor = string(in.String())
 		default:
 			in.SkipRecursive()
 		}
@@ -94,6 +96,16 @@ func easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalRole(out *jwriter.Write
 			out.RawByte(']')
 		}
 	}
+	{
+		const prefix string = ",\"next_cursor\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.NextCursor))
+	}
 	out.RawByte('}')
 }
 
@@ -143,6 +155,31 @@ func easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalRole1(in *jlexer.Lexer,
 			out.ID = int(in.Int())
 		case "name":
 			out.Name = string(in.String())
+		case "permissions":
+			if in.IsNull() {
+				in.Skip()
+				out.Permissions = nil
+			} else {
+				in.Delim('[')
+				if out.Permissions == nil {
+					if !in.IsDelim(']') {
+						out.Permissions = make([]Permission, 0, 4)
+					} else {
+						out.Permissions = []Permission{}
+					}
+				} else {
+					out.Permissions = (out.Permissions)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v4 Permission
+					v4 = Permission(in.String())
+					out.Permissions = append(out.Permissions, v4)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "mfa_required":
+			out.MFARequired = bool(in.Bool())
 		case "created_at":
 			if data := in.Raw(); in.Ok() {
 				in.AddError((out.CreatedAt).UnmarshalJSON(data))
@@ -151,6 +188,8 @@ func easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalRole1(in *jlexer.Lexer,
 			if data := in.Raw(); in.Ok() {
 				in.AddError((out.UpdatedAt).UnmarshalJSON(data))
 			}
+		case "version":
+			out.Version = int(in.Int())
 		default:
 			in.SkipRecursive()
 		}
@@ -185,6 +224,37 @@ func easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalRole1(out *jwriter.Writ
 		}
 		out.String(string(in.Name))
 	}
+	{
+		const prefix string = ",\"permissions\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		if in.Permissions == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v5, v6 := range in.Permissions {
+				if v5 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v6))
+			}
+			out.RawByte(']')
+		}
+	}
+	{
+		const prefix string = ",\"mfa_required\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Bool(bool(in.MFARequired))
+	}
 	{
 		const prefix string = ",\"created_at\":"
 		if first {
@@ -205,6 +275,16 @@ func easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalRole1(out *jwriter.Writ
 		}
 		out.Raw((in.UpdatedAt).MarshalJSON())
 	}
+	{
+		const prefix string = ",\"version\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int(int(in.Version))
+	}
 	out.RawByte('}')
 }
 
@@ -231,7 +311,110 @@ func (v *Role) UnmarshalJSON(data []byte) error {
 func (v *Role) UnmarshalEasyJSON(l *jlexer.Lexer) {
 	easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalRole1(l, v)
 }
-func easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalRole2(in *jlexer.Lexer, out *NewRole) {
+func easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalRole2(in *jlexer.Lexer, out *PermissionList) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "permissions":
+			if in.IsNull() {
+				in.Skip()
+				out.Permissions = nil
+			} else {
+				in.Delim('[')
+				if out.Permissions == nil {
+					if !in.IsDelim(']') {
+						out.Permissions = make([]Permission, 0, 4)
+					} else {
+						out.Permissions = []Permission{}
+					}
+				} else {
+					out.Permissions = (out.Permissions)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v7 Permission
+					v7 = Permission(in.String())
+					out.Permissions = append(out.Permissions, v7)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalRole2(out *jwriter.Writer, in PermissionList) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"permissions\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		if in.Permissions == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v8, v9 := range in.Permissions {
+				if v8 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v9))
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v PermissionList) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalRole2(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v PermissionList) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalRole2(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *PermissionList) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalRole2(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *PermissionList) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalRole2(l, v)
+}
+func easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalRole3(in *jlexer.Lexer, out *NewRole) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -252,6 +435,8 @@ func easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalRole2(in *jlexer.Lexer,
 		switch key {
 		case "name":
 			out.Name = string(in.String())
+		case "mfa_required":
+			out.MFARequired = bool(in.Bool())
 		default:
 			in.SkipRecursive()
 		}
@@ -262,7 +447,7 @@ func easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalRole2(in *jlexer.Lexer,
 		in.Consumed()
 	}
 }
-func easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalRole2(out *jwriter.Writer, in NewRole) {
+func easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalRole3(out *jwriter.Writer, in NewRole) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -276,33 +461,43 @@ func easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalRole2(out *jwriter.Writ
 		}
 		out.String(string(in.Name))
 	}
+	{
+		const prefix string = ",\"mfa_required\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Bool(bool(in.MFARequired))
+	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
 func (v NewRole) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalRole2(&w, v)
+	easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalRole3(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v NewRole) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalRole2(w, v)
+	easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalRole3(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *NewRole) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalRole2(&r, v)
+	easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalRole3(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *NewRole) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalRole2(l, v)
+	easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalRole3(l, v)
 }
-func easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalRole3(in *jlexer.Lexer, out *Form) {
+func easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalRole4(in *jlexer.Lexer, out *Form) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -323,6 +518,8 @@ func easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalRole3(in *jlexer.Lexer,
 		switch key {
 		case "name":
 			out.Name = string(in.String())
+		case "mfa_required":
+			out.MFARequired = bool(in.Bool())
 		default:
 			in.SkipRecursive()
 		}
@@ -333,7 +530,7 @@ func easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalRole3(in *jlexer.Lexer,
 		in.Consumed()
 	}
 }
-func easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalRole3(out *jwriter.Writer, in Form) {
+func easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalRole4(out *jwriter.Writer, in Form) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -347,29 +544,39 @@ func easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalRole3(out *jwriter.Writ
 		}
 		out.String(string(in.Name))
 	}
+	{
+		const prefix string = ",\"mfa_required\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Bool(bool(in.MFARequired))
+	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
 func (v Form) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalRole3(&w, v)
+	easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalRole4(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v Form) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalRole3(w, v)
+	easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalRole4(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *Form) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalRole3(&r, v)
+	easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalRole4(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *Form) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalRole3(l, v)
+	easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalRole4(l, v)
 }
@@ -0,0 +1,78 @@
+package role
+
+// Permission names an action which can be granted to a role.
+// Permissions ending with ":own" allow the action only for
+// the items created by the user.
+type Permission string
+
+const (
+	// ArticlesView allows to read articles and their revisions.
+	ArticlesView Permission = "articles:view"
+	// ArticlesCreate allows to create articles.
+	ArticlesCreate Permission = "articles:create"
+	// ArticlesUpdate allows to update any article.
+	ArticlesUpdate Permission = "articles:update"
+	// ArticlesUpdateOwn allows to update articles of the user.
+	ArticlesUpdateOwn Permission = "articles:update:own"
+	// ArticlesDelete allows to delete any article.
+	ArticlesDelete Permission = "articles:delete"
+	// ArticlesDeleteOwn allows to delete articles of the user.
+	ArticlesDeleteOwn Permission = "articles:delete:own"
+
+	// CategoriesView allows to read categories.
+	CategoriesView Permission = "categories:view"
+	// CategoriesCreate allows to create categories.
+	CategoriesCreate Permission = "categories:create"
+	// CategoriesUpdate allows to update categories.
+	CategoriesUpdate Permission = "categories:update"
+	// CategoriesDelete allows to delete categories.
+	CategoriesDelete Permission = "categories:delete"
+
+	// RolesView allows to read roles and their permissions.
+	RolesView Permission = "roles:view"
+	// RolesManage allows to change roles and grant permissions.
+	RolesManage Permission = "roles:manage"
+
+	// UsersView allows to read users.
+	UsersView Permission = "users:view"
+	// UsersManage allows to create, change and delete users.
+	UsersManage Permission = "users:manage"
+)
+
+// Permissions contains all known permissions.
+var Permissions = []Permission{
+	ArticlesView,
+	ArticlesCreate,
+	ArticlesUpdate,
+	ArticlesUpdateOwn,
+	ArticlesDelete,
+	ArticlesDeleteOwn,
+	CategoriesView,
+	CategoriesCreate,
+	CategoriesUpdate,
+	CategoriesDelete,
+	RolesView,
+	RolesManage,
+	UsersView,
+	UsersManage,
+}
+
+// Known checks that p is one of the known permissions.
+func (p Permission) Known() bool {
+	for _, known := range Permissions {
+		if p == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Can checks that the role is granted with p.
+func (r *Role) Can(p Permission) bool {
+	for _, granted := range r.Permissions {
+		if granted == p {
+			return true
+		}
+	}
+	return false
+}
@@ -3,6 +3,9 @@ package role
 import (
 	"context"
 
+	"github.com/dipress/crmifc/internal/kit/patch"
+	"github.com/dipress/crmifc/internal/kit/query"
+	"github.com/dipress/crmifc/internal/kit/tx"
 	"github.com/pkg/errors"
 )
 
@@ -14,7 +17,9 @@ type Repository interface {
 	Find(ctx context.Context, id int) (*Role, error)
 	Update(ctx context.Context, id int, rl *Role) error
 	Delete(ctx context.Context, id int) error
-	List(ctx context.Context, roles *Roles) error
+	List(ctx context.Context, q *query.Query, roles *Roles) error
+	Grant(ctx context.Context, id int, p Permission) error
+	Revoke(ctx context.Context, id int, p Permission) error
 }
 
 // Validater validates role fields.
@@ -26,6 +31,9 @@ type Validater interface {
 type Service struct {
 	Repository
 	Validater
+	// Transactor runs the actions of several
+	// repository calls in a single transaction.
+	Transactor tx.Transactor
 }
 
 // NewService factory prepares service for all futher operations.
@@ -33,6 +41,7 @@ func NewService(r Repository, v Validater) *Service {
 	s := Service{
 		Repository: r,
 		Validater:  v,
+		Transactor: tx.Nop{},
 	}
 	return &s
 }
@@ -45,6 +54,7 @@ func (s *Service) Create(ctx context.Context, f *Form) (*Role, error) {
 
 	var nr NewRole
 	nr.Name = f.Name
+	nr.MFARequired = f.MFARequired
 
 	var rol Role
 	if err := s.Repository.Create(ctx, &nr, &rol); err != nil {
@@ -62,8 +72,8 @@ func (s *Service) Find(ctx context.Context, id int) (*Role, error) {
 	return r, nil
 }
 
-// Update updates a role.
-func (s *Service) Update(ctx context.Context, id int, f *Form) (*Role, error) {
+// Update updates a role of the given version.
+func (s *Service) Update(ctx context.Context, id, version int, f *Form) (*Role, error) {
 	if err := s.Validater.Validate(ctx, f); err != nil {
 		return nil, errors.Wrap(err, "validater validate")
 	}
@@ -73,7 +83,12 @@ func (s *Service) Update(ctx context.Context, id int, f *Form) (*Role, error) {
 		return nil, errors.Wrap(err, "repository find role")
 	}
 
+	if rl.Version != version {
+		return nil, ErrVersionMismatch
+	}
+
 	rl.Name = f.Name
+	rl.MFARequired = f.MFARequired
 
 	if err := s.Repository.Update(ctx, id, rl); err != nil {
 		return nil, errors.Wrap(err, "update role")
@@ -81,24 +96,100 @@ func (s *Service) Update(ctx context.Context, id int, f *Form) (*Role, error) {
 	return rl, nil
 }
 
-// Delete deletes a role.
-func (s *Service) Delete(ctx context.Context, id int) error {
+// Patch updates only the fields of the role of the
+// given version which are given by the JSON merge patch.
+func (s *Service) Patch(ctx context.Context, id, version int, p patch.Patch) (*Role, error) {
 	rl, err := s.Repository.Find(ctx, id)
 	if err != nil {
-		return errors.Wrap(err, "find role")
+		return nil, errors.Wrap(err, "repository find role")
+	}
+
+	f := Form{
+		Name:        rl.Name,
+		MFARequired: rl.MFARequired,
 	}
 
-	if err := s.Repository.Delete(ctx, rl.ID); err != nil {
-		return errors.Wrap(err, "delete role")
+	if err := p.Apply(&f); err != nil {
+		return nil, errors.Wrap(err, "apply patch")
 	}
-	return nil
+
+	return s.Update(ctx, id, version, &f)
+}
+
+// Delete deletes a role of the given version.
+func (s *Service) Delete(ctx context.Context, id, version int) error {
+	return s.Transactor.WithinTx(ctx, func(ctx context.Context) error {
+		rl, err := s.Repository.Find(ctx, id)
+		if err != nil {
+			return errors.Wrap(err, "find role")
+		}
+
+		if rl.Version != version {
+			return ErrVersionMismatch
+		}
+
+		if err := s.Repository.Delete(ctx, rl.ID); err != nil {
+			return errors.Wrap(err, "delete role")
+		}
+		return nil
+	})
 }
 
-// List shows all roles.
-func (s *Service) List(ctx context.Context) (*Roles, error) {
+// List shows roles page by given query.
+func (s *Service) List(ctx context.Context, q *query.Query) (*Roles, error) {
 	var roles Roles
-	if err := s.Repository.List(ctx, &roles); err != nil {
+	if err := s.Repository.List(ctx, q, &roles); err != nil {
 		return nil, errors.Wrap(err, "list of roles")
 	}
 	return &roles, nil
 }
+
+// Permissions shows all known permissions.
+func (s *Service) Permissions(ctx context.Context) *PermissionList {
+	list := PermissionList{
+		Permissions: Permissions,
+	}
+	return &list
+}
+
+// Grant grants the permission to a role.
+func (s *Service) Grant(ctx context.Context, id int, p Permission) (*Role, error) {
+	if !p.Known() {
+		return nil, ErrUnknownPermission
+	}
+
+	if _, err := s.Repository.Find(ctx, id); err != nil {
+		return nil, errors.Wrap(err, "repository find role")
+	}
+
+	if err := s.Repository.Grant(ctx, id, p); err != nil {
+		return nil, errors.Wrap(err, "grant permission")
+	}
+
+	rl, err := s.Repository.Find(ctx, id)
+	if err != nil {
+		return nil, errors.Wrap(err, "repository find role")
+	}
+	return rl, nil
+}
+
+// Revoke revokes the permission from a role.
+func (s *Service) Revoke(ctx context.Context, id int, p Permission) (*Role, error) {
+	if !p.Known() {
+		return nil, ErrUnknownPermission
+	}
+
+	if _, err := s.Repository.Find(ctx, id); err != nil {
+		return nil, errors.Wrap(err, "repository find role")
+	}
+
+	if err := s.Repository.Revoke(ctx, id, p); err != nil {
+		return nil, errors.Wrap(err, "revoke permission")
+	}
+
+	rl, err := s.Repository.Find(ctx, id)
+	if err != nil {
+		return nil, errors.Wrap(err, "repository find role")
+	}
+	return rl, nil
+}
@@ -6,6 +6,7 @@ package role
 
 import (
 	context "context"
+	query "github.com/dipress/crmifc/internal/kit/query"
 	gomock "github.com/golang/mock/gomock"
 	reflect "reflect"
 )
@@ -91,17 +92,45 @@ func (mr *MockRepositoryMockRecorder) Delete(ctx, id interface{}) *gomock.Call {
 }
 
 // List mocks base method
-func (m *MockRepository) List(ctx context.Context, roles *Roles) error {
+func (m *MockRepository) List(ctx context.Context, q *query.Query, roles *Roles) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "List", ctx, roles)
+	ret := m.ctrl.Call(m, "List", ctx, q, roles)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // List indicates an expected call of List
-func (mr *MockRepositoryMockRecorder) List(ctx, roles interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) List(ctx, q, roles interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockRepository)(nil).List), ctx, roles)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockRepository)(nil).List), ctx, q, roles)
+}
+
+// Grant mocks base method
+func (m *MockRepository) Grant(ctx context.Context, id int, p Permission) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Grant", ctx, id, p)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Grant indicates an expected call of Grant
+func (mr *MockRepositoryMockRecorder) Grant(ctx, id, p interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Grant", reflect.TypeOf((*MockRepository)(nil).Grant), ctx, id, p)
+}
+
+// Revoke mocks base method
+func (m *MockRepository) Revoke(ctx context.Context, id int, p Permission) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", ctx, id, p)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Revoke indicates an expected call of Revoke
+func (mr *MockRepositoryMockRecorder) Revoke(ctx, id, p interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockRepository)(nil).Revoke), ctx, id, p)
 }
 
 // MockValidater is a mock of Validater interface
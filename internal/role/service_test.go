@@ -5,6 +5,8 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/dipress/crmifc/internal/kit/patch"
+	"github.com/dipress/crmifc/internal/kit/query"
 	gomock "github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 )
@@ -135,7 +137,7 @@ func Test_Service_Update(t *testing.T) {
 		{
 			name: "ok",
 			repositoryFunc: func(m *MockRepository) {
-				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&Role{}, nil)
+				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&Role{Version: 1}, nil)
 				m.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
 			},
 			validaterFunc: func(m *MockValidater) {
@@ -153,7 +155,7 @@ func Test_Service_Update(t *testing.T) {
 		{
 			name: "find role error",
 			repositoryFunc: func(m *MockRepository) {
-				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&Role{}, errors.New("mock error"))
+				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&Role{Version: 1}, errors.New("mock error"))
 			},
 			validaterFunc: func(m *MockValidater) {
 				m.EXPECT().Validate(gomock.Any(), gomock.Any()).Return(nil)
@@ -163,7 +165,7 @@ func Test_Service_Update(t *testing.T) {
 		{
 			name: "update role error",
 			repositoryFunc: func(m *MockRepository) {
-				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&Role{}, nil)
+				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&Role{Version: 1}, nil)
 				m.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("mock error"))
 			},
 			validaterFunc: func(m *MockValidater) {
@@ -196,7 +198,7 @@ func Test_Service_Update(t *testing.T) {
 				Name: "Manager",
 			}
 
-			_, err := s.Update(ctx, 1, &form)
+			_, err := s.Update(ctx, 1, 1, &form)
 			if tc.wantErr {
 				assert.Error(t, err)
 				return
@@ -206,6 +208,25 @@ func Test_Service_Update(t *testing.T) {
 	}
 }
 
+func Test_Service_Patch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := NewMockRepository(ctrl)
+	validater := NewMockValidater(ctrl)
+
+	validater.EXPECT().Validate(gomock.Any(), &Form{Name: "Editors", MFARequired: true}).Return(nil)
+	repo.EXPECT().Find(gomock.Any(), 1).Return(&Role{Version: 1, ID: 1, Name: "Writers", MFARequired: true}, nil).Times(2)
+	repo.EXPECT().Update(gomock.Any(), 1, gomock.Any()).Return(nil)
+
+	s := NewService(repo, validater)
+
+	rl, err := s.Patch(context.Background(), 1, 1, patch.Patch(`{"name":"Editors"}`))
+	assert.Nil(t, err)
+	assert.Equal(t, "Editors", rl.Name)
+	assert.True(t, rl.MFARequired)
+}
+
 func Test_Service_Delete(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -215,21 +236,21 @@ func Test_Service_Delete(t *testing.T) {
 		{
 			name: "ok",
 			repositoryFunc: func(m *MockRepository) {
-				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&Role{}, nil)
+				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&Role{Version: 1}, nil)
 				m.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(nil)
 			},
 		},
 		{
 			name: "find role error",
 			repositoryFunc: func(m *MockRepository) {
-				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&Role{}, errors.New("mock error"))
+				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&Role{Version: 1}, errors.New("mock error"))
 			},
 			wantErr: true,
 		},
 		{
 			name: "delete role error",
 			repositoryFunc: func(m *MockRepository) {
-				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&Role{}, nil)
+				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&Role{Version: 1}, nil)
 				m.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(errors.New("mock error"))
 			},
 			wantErr: true,
@@ -253,7 +274,7 @@ func Test_Service_Delete(t *testing.T) {
 			ctx, cancel := context.WithCancel(context.Background())
 			defer cancel()
 
-			err := s.Delete(ctx, 1)
+			err := s.Delete(ctx, 1, 1)
 			if tc.wantErr {
 				assert.Error(t, err)
 				return
@@ -272,13 +293,80 @@ func Test_List_Service(t *testing.T) {
 		{
 			name: "ok",
 			repositoryFunc: func(m *MockRepository) {
-				m.EXPECT().List(gomock.Any(), gomock.Any()).Return(nil)
+				m.EXPECT().List(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
 			},
 		},
 		{
 			name: "internal error",
 			repositoryFunc: func(m *MockRepository) {
-				m.EXPECT().List(gomock.Any(), gomock.Any()).Return(errors.New("mock error"))
+				m.EXPECT().List(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("mock error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := NewMockRepository(ctrl)
+
+			tc.repositoryFunc(repo)
+
+			s := NewService(repo, nil)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			_, err := s.List(ctx, query.New())
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.Nil(t, err)
+		})
+	}
+}
+
+func Test_Grant_Service(t *testing.T) {
+	tests := []struct {
+		name           string
+		permission     Permission
+		repositoryFunc func(mock *MockRepository)
+		wantErr        bool
+	}{
+		{
+			name:       "ok",
+			permission: ArticlesCreate,
+			repositoryFunc: func(m *MockRepository) {
+				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&Role{}, nil).Times(2)
+				m.EXPECT().Grant(gomock.Any(), 1, ArticlesCreate).Return(nil)
+			},
+		},
+		{
+			name:           "unknown permission",
+			permission:     "articles:fly",
+			repositoryFunc: func(m *MockRepository) {},
+			wantErr:        true,
+		},
+		{
+			name:       "find role error",
+			permission: ArticlesCreate,
+			repositoryFunc: func(m *MockRepository) {
+				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(nil, ErrNotFound)
+			},
+			wantErr: true,
+		},
+		{
+			name:       "grant error",
+			permission: ArticlesCreate,
+			repositoryFunc: func(m *MockRepository) {
+				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&Role{}, nil)
+				m.EXPECT().Grant(gomock.Any(), 1, ArticlesCreate).Return(errors.New("mock error"))
 			},
 			wantErr: true,
 		},
@@ -293,7 +381,64 @@ func Test_List_Service(t *testing.T) {
 			defer ctrl.Finish()
 
 			repo := NewMockRepository(ctrl)
+			tc.repositoryFunc(repo)
+
+			s := NewService(repo, nil)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
 
+			_, err := s.Grant(ctx, 1, tc.permission)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.Nil(t, err)
+		})
+	}
+}
+
+func Test_Revoke_Service(t *testing.T) {
+	tests := []struct {
+		name           string
+		permission     Permission
+		repositoryFunc func(mock *MockRepository)
+		wantErr        bool
+	}{
+		{
+			name:       "ok",
+			permission: ArticlesDelete,
+			repositoryFunc: func(m *MockRepository) {
+				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&Role{}, nil).Times(2)
+				m.EXPECT().Revoke(gomock.Any(), 1, ArticlesDelete).Return(nil)
+			},
+		},
+		{
+			name:           "unknown permission",
+			permission:     "everything",
+			repositoryFunc: func(m *MockRepository) {},
+			wantErr:        true,
+		},
+		{
+			name:       "revoke error",
+			permission: ArticlesDelete,
+			repositoryFunc: func(m *MockRepository) {
+				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&Role{}, nil)
+				m.EXPECT().Revoke(gomock.Any(), 1, ArticlesDelete).Return(errors.New("mock error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := NewMockRepository(ctrl)
 			tc.repositoryFunc(repo)
 
 			s := NewService(repo, nil)
@@ -301,7 +446,7 @@ func Test_List_Service(t *testing.T) {
 			ctx, cancel := context.WithCancel(context.Background())
 			defer cancel()
 
-			_, err := s.List(ctx)
+			_, err := s.Revoke(ctx, 1, tc.permission)
 			if tc.wantErr {
 				assert.Error(t, err)
 				return
@@ -310,3 +455,12 @@ func Test_List_Service(t *testing.T) {
 		})
 	}
 }
+
+func TestRoleCan(t *testing.T) {
+	r := Role{Permissions: []Permission{ArticlesView, ArticlesUpdateOwn}}
+
+	assert.True(t, r.Can(ArticlesView))
+	assert.True(t, r.Can(ArticlesUpdateOwn))
+	assert.False(t, r.Can(ArticlesUpdate))
+	assert.False(t, Permission("articles:fly").Known())
+}
@@ -0,0 +1,71 @@
+package session
+
+import (
+	"errors"
+	"time"
+)
+
+// easyjson -all model.go
+
+// ErrNotFound raises when session isn't found in the database
+// or is revoked or expired already.
+var ErrNotFound = errors.New("session not found")
+
+// Session is a sign in of the user on a client. The tokens issued
+// by the sign in and their refreshes belong to the session.
+type Session struct {
+	ID         int        `json:"id"`
+	UserID     int        `json:"user_id"`
+	Username   string     `json:"username"`
+	IP         string     `json:"ip"`
+	UserAgent  string     `json:"user_agent"`
+	Current    bool       `json:"current"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt time.Time  `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// NewSession contains the information which needs to create a new Session.
+type NewSession struct {
+	UserID    int
+	IP        string
+	UserAgent string
+	ExpiresAt time.Time
+}
+
+// Sessions contains slice of sessions.
+type Sessions struct {
+	Sessions   []Session `json:"sessions"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+}
+
+// LoginEvent is a sign in attempt. The user is
+// unknown when the email isn't registered.
+type LoginEvent struct {
+	ID        int       `json:"id"`
+	UserID    *int      `json:"user_id"`
+	Email     string    `json:"email"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"user_agent"`
+	Success   bool      `json:"success"`
+	Reason    string    `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewLoginEvent contains the information which needs to create
+// a new LoginEvent. Zero user id means the user is unknown.
+type NewLoginEvent struct {
+	UserID    int
+	Email     string
+	IP        string
+	UserAgent string
+	Success   bool
+	Reason    string
+}
+
+// LoginEvents contains slice of login events.
+type LoginEvents struct {
+	LoginEvents []LoginEvent `json:"login_events"`
+	NextCursor  string       `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,84 @@
+package session
+
+import (
+	"context"
+
+	"github.com/dipress/crmifc/internal/kit/auth"
+	"github.com/dipress/crmifc/internal/kit/query"
+	"github.com/pkg/errors"
+)
+
+// go:generate mockgen -source=service.go -package=session -destination=service.mock.go
+
+// Repository allows to work with the database.
+type Repository interface {
+	ListUserSessions(ctx context.Context, userID int, sessions *Sessions) error
+	ListSessions(ctx context.Context, q *query.Query, sessions *Sessions) error
+	RevokeSession(ctx context.Context, id int) error
+	RevokeUserSession(ctx context.Context, userID, id int) error
+	ListLoginEvents(ctx context.Context, q *query.Query, events *LoginEvents) error
+}
+
+// Service is a use case for sessions and sign in history.
+type Service struct {
+	Repository
+}
+
+// NewService factory prepares service for all futher operations.
+func NewService(r Repository) *Service {
+	s := Service{
+		Repository: r,
+	}
+
+	return &s
+}
+
+// List lists the active sessions of the user with the claims.
+// The session of the claims is marked as the current one.
+func (s *Service) List(ctx context.Context, c *auth.Claims) (*Sessions, error) {
+	var sessions Sessions
+	if err := s.Repository.ListUserSessions(ctx, c.User.ID, &sessions); err != nil {
+		return nil, errors.Wrap(err, "repository list user sessions")
+	}
+
+	for i := range sessions.Sessions {
+		sessions.Sessions[i].Current = sessions.Sessions[i].ID == c.SessionID
+	}
+
+	return &sessions, nil
+}
+
+// Delete revokes the session of the user with the claims. The tokens
+// of the session stop working, the current one too.
+func (s *Service) Delete(ctx context.Context, c *auth.Claims, id int) error {
+	if err := s.Repository.RevokeUserSession(ctx, c.User.ID, id); err != nil {
+		return errors.Wrap(err, "repository revoke user session")
+	}
+	return nil
+}
+
+// ListAll returns active sessions of all users by given query.
+func (s *Service) ListAll(ctx context.Context, q *query.Query) (*Sessions, error) {
+	var sessions Sessions
+	if err := s.Repository.ListSessions(ctx, q, &sessions); err != nil {
+		return nil, errors.Wrap(err, "repository list sessions")
+	}
+	return &sessions, nil
+}
+
+// Revoke revokes the session of any user.
+func (s *Service) Revoke(ctx context.Context, id int) error {
+	if err := s.Repository.RevokeSession(ctx, id); err != nil {
+		return errors.Wrap(err, "repository revoke session")
+	}
+	return nil
+}
+
+// LoginEvents returns sign in attempts by given query.
+func (s *Service) LoginEvents(ctx context.Context, q *query.Query) (*LoginEvents, error) {
+	var events LoginEvents
+	if err := s.Repository.ListLoginEvents(ctx, q, &events); err != nil {
+		return nil, errors.Wrap(err, "repository list login events")
+	}
+	return &events, nil
+}
@@ -0,0 +1,105 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: service.go
+
+// Package session is a generated GoMock package.
+package session
+
+import (
+	context "context"
+	query "github.com/dipress/crmifc/internal/kit/query"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockRepository is a mock of Repository interface
+type MockRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepositoryMockRecorder
+}
+
+// MockRepositoryMockRecorder is the mock recorder for MockRepository
+type MockRepositoryMockRecorder struct {
+	mock *MockRepository
+}
+
+// NewMockRepository creates a new mock instance
+func NewMockRepository(ctrl *gomock.Controller) *MockRepository {
+	mock := &MockRepository{ctrl: ctrl}
+	mock.recorder = &MockRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ListUserSessions mocks base method
+func (m *MockRepository) ListUserSessions(ctx context.Context, userID int, sessions *Sessions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserSessions", ctx, userID, sessions)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ListUserSessions indicates an expected call of ListUserSessions
+func (mr *MockRepositoryMockRecorder) ListUserSessions(ctx, userID, sessions interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserSessions", reflect.TypeOf((*MockRepository)(nil).ListUserSessions), ctx, userID, sessions)
+}
+
+// ListSessions mocks base method
+func (m *MockRepository) ListSessions(ctx context.Context, q *query.Query, sessions *Sessions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSessions", ctx, q, sessions)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ListSessions indicates an expected call of ListSessions
+func (mr *MockRepositoryMockRecorder) ListSessions(ctx, q, sessions interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSessions", reflect.TypeOf((*MockRepository)(nil).ListSessions), ctx, q, sessions)
+}
+
+// RevokeSession mocks base method
+func (m *MockRepository) RevokeSession(ctx context.Context, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSession", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeSession indicates an expected call of RevokeSession
+func (mr *MockRepositoryMockRecorder) RevokeSession(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSession", reflect.TypeOf((*MockRepository)(nil).RevokeSession), ctx, id)
+}
+
+// RevokeUserSession mocks base method
+func (m *MockRepository) RevokeUserSession(ctx context.Context, userID, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeUserSession", ctx, userID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeUserSession indicates an expected call of RevokeUserSession
+func (mr *MockRepositoryMockRecorder) RevokeUserSession(ctx, userID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeUserSession", reflect.TypeOf((*MockRepository)(nil).RevokeUserSession), ctx, userID, id)
+}
+
+// ListLoginEvents mocks base method
+func (m *MockRepository) ListLoginEvents(ctx context.Context, q *query.Query, events *LoginEvents) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListLoginEvents", ctx, q, events)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ListLoginEvents indicates an expected call of ListLoginEvents
+func (mr *MockRepositoryMockRecorder) ListLoginEvents(ctx, q, events interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListLoginEvents", reflect.TypeOf((*MockRepository)(nil).ListLoginEvents), ctx, q, events)
+}
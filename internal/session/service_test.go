@@ -0,0 +1,72 @@
+package session
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dipress/crmifc/internal/kit/auth"
+	"github.com/dipress/crmifc/internal/user"
+	gomock "github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_List_Service(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := NewMockRepository(ctrl)
+	repo.EXPECT().ListUserSessions(gomock.Any(), 1, gomock.Any()).
+		DoAndReturn(func(ctx context.Context, userID int, sessions *Sessions) error {
+			sessions.Sessions = []Session{{ID: 2}, {ID: 3}}
+			return nil
+		})
+
+	s := NewService(repo)
+
+	claims := auth.Claims{User: user.User{ID: 1}, SessionID: 3}
+	sessions, err := s.List(context.Background(), &claims)
+	if assert.Nil(t, err) {
+		assert.False(t, sessions.Sessions[0].Current)
+		assert.True(t, sessions.Sessions[1].Current)
+	}
+}
+
+func Test_Delete_Service(t *testing.T) {
+	tests := []struct {
+		name           string
+		repositoryFunc func(mock *MockRepository)
+		wantErr        error
+	}{
+		{
+			name: "ok",
+			repositoryFunc: func(m *MockRepository) {
+				m.EXPECT().RevokeUserSession(gomock.Any(), 1, 2).Return(nil)
+			},
+		},
+		{
+			name: "not found",
+			repositoryFunc: func(m *MockRepository) {
+				m.EXPECT().RevokeUserSession(gomock.Any(), 1, 2).Return(ErrNotFound)
+			},
+			wantErr: ErrNotFound,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := NewMockRepository(ctrl)
+			tc.repositoryFunc(repo)
+			s := NewService(repo)
+
+			claims := auth.Claims{User: user.User{ID: 1}}
+			err := s.Delete(context.Background(), &claims, 2)
+			assert.Equal(t, tc.wantErr, errors.Cause(err))
+		})
+	}
+}
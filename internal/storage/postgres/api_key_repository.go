@@ -0,0 +1,131 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/dipress/crmifc/internal/apikey"
+	"github.com/dipress/crmifc/internal/role"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+// APIKeyRepository holds api keys of the users.
+type APIKeyRepository struct {
+	db *database
+}
+
+// NewAPIKeyRepository factory prepares the repository to work.
+func NewAPIKeyRepository(db *sql.DB) *APIKeyRepository {
+	r := APIKeyRepository{
+		db: newDatabase(db),
+	}
+
+	return &r
+}
+
+// apiKeyColumns are the columns which are scanned by scanAPIKey.
+const apiKeyColumns = `id, user_id, name, prefix, scopes, expires_at, last_used_at, created_at`
+
+const createAPIKeyQuery = `
+	INSERT INTO api_keys (user_id, name, prefix, key_hash, scopes, expires_at)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	RETURNING ` + apiKeyColumns
+
+// Create inserts the hash of a new api key into the database.
+// Expiration times are stored in UTC.
+func (r *APIKeyRepository) Create(ctx context.Context, nk *apikey.NewAPIKey, k *apikey.APIKey) error {
+	row := r.db.QueryRowContext(ctx, createAPIKeyQuery,
+		nk.UserID, nk.Name, nk.Prefix, nk.Hash, pq.Array(scopeNames(nk.Scopes)), nk.ExpiresAt.UTC())
+
+	if err := scanAPIKey(row, k); err != nil {
+		return errors.Wrap(err, "query row scan")
+	}
+	return nil
+}
+
+const listAPIKeysQuery = `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE user_id = $1 ORDER BY id`
+
+// List lists the api keys of the user.
+func (r *APIKeyRepository) List(ctx context.Context, userID int, keys *apikey.APIKeys) error {
+	rows, err := r.db.QueryContext(ctx, listAPIKeysQuery, userID)
+	if err != nil {
+		return errors.Wrap(err, "query context")
+	}
+	defer rows.Close()
+
+	keys.APIKeys = make([]apikey.APIKey, 0)
+	for rows.Next() {
+		var k apikey.APIKey
+		if err := scanAPIKey(rows, &k); err != nil {
+			return errors.Wrap(err, "rows scan")
+		}
+		keys.APIKeys = append(keys.APIKeys, k)
+	}
+
+	if err := rows.Err(); err != nil {
+		return errors.Wrap(err, "rows err")
+	}
+	return nil
+}
+
+const deleteAPIKeyQuery = `DELETE FROM api_keys WHERE user_id = $1 AND id = $2`
+
+// Delete deletes the api key of the user.
+func (r *APIKeyRepository) Delete(ctx context.Context, userID, id int) error {
+	res, err := r.db.ExecContext(ctx, deleteAPIKeyQuery, userID, id)
+	if err != nil {
+		return errors.Wrap(err, "exec context")
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "rows affected")
+	}
+
+	if affected == 0 {
+		return apikey.ErrNotFound
+	}
+	return nil
+}
+
+const useAPIKeyQuery = `
+	UPDATE api_keys SET last_used_at = now()
+	WHERE key_hash = $1 AND expires_at > now() AT TIME ZONE 'UTC'
+	RETURNING ` + apiKeyColumns
+
+// Use finds the api key which isn't expired by the
+// hash and saves the time it was used.
+func (r *APIKeyRepository) Use(ctx context.Context, hash string) (*apikey.APIKey, error) {
+	var k apikey.APIKey
+	if err := scanAPIKey(r.db.QueryRowContext(ctx, useAPIKeyQuery, hash), &k); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apikey.ErrNotFound
+		}
+		return nil, errors.Wrap(err, "query row scan")
+	}
+	return &k, nil
+}
+
+// scanner is implemented by both sql.Row and sql.Rows.
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanAPIKey scans the api key columns into k.
+func scanAPIKey(s scanner, k *apikey.APIKey) error {
+	var scopes []string
+	if err := s.Scan(&k.ID, &k.UserID, &k.Name, &k.Prefix, pq.Array(&scopes), &k.ExpiresAt, &k.LastUsedAt, &k.CreatedAt); err != nil {
+		return err
+	}
+	k.Scopes = toPermissions(scopes)
+	return nil
+}
+
+func scopeNames(scopes []role.Permission) []string {
+	names := make([]string, 0, len(scopes))
+	for _, p := range scopes {
+		names = append(names, string(p))
+	}
+	return names
+}
@@ -0,0 +1,106 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dipress/crmifc/internal/apikey"
+	"github.com/dipress/crmifc/internal/role"
+	"github.com/dipress/crmifc/internal/user"
+)
+
+func TestAPIKey(t *testing.T) {
+	t.Log("with initialized repository")
+	{
+		db, teardown := postgresDB(t)
+		defer teardown()
+
+		r := NewAPIKeyRepository(db)
+		userRepo := NewUserRepository(db)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		nu := user.NewUser{
+			RoleID:       createRole(t, db, "Developer"),
+			Username:     "username_api_key",
+			Email:        "username_api_key@example.com",
+			PasswordHash: "hash",
+		}
+
+		var u user.User
+		if err := userRepo.Create(ctx, &nu, &u); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		nk := apikey.NewAPIKey{
+			UserID:    u.ID,
+			Name:      "ci",
+			Prefix:    "crm_abcdefgh",
+			Hash:      "2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae",
+			Scopes:    []role.Permission{role.ArticlesView},
+			ExpiresAt: time.Now().Add(time.Hour),
+		}
+
+		var k apikey.APIKey
+		t.Log("\ttest:0\tshould create and list the api key")
+		{
+			if err := r.Create(ctx, &nk, &k); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var keys apikey.APIKeys
+			if err := r.List(ctx, u.ID, &keys); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(keys.APIKeys) != 1 || keys.APIKeys[0].Name != "ci" || keys.APIKeys[0].Scopes[0] != role.ArticlesView {
+				t.Errorf("unexpected keys: %+v", keys)
+			}
+		}
+
+		t.Log("\ttest:1\tshould use the api key by the hash")
+		{
+			used, err := r.Use(ctx, nk.Hash)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if used.ID != k.ID || used.UserID != u.ID || used.LastUsedAt == nil {
+				t.Errorf("unexpected key: %+v", used)
+			}
+		}
+
+		t.Log("\ttest:2\tshould not use the expired api key")
+		{
+			expired := nk
+			expired.Hash = "fcde2b2edba56bf408601fb721fe9b5c338d10ee429ea04fae5511b68fbf8fb9"
+			expired.ExpiresAt = time.Now().Add(-time.Minute)
+
+			var ek apikey.APIKey
+			if err := r.Create(ctx, &expired, &ek); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if _, err := r.Use(ctx, expired.Hash); err != apikey.ErrNotFound {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}
+
+		t.Log("\ttest:3\tshould delete the api key of the user only")
+		{
+			if err := r.Delete(ctx, u.ID+1, k.ID); err != apikey.ErrNotFound {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if err := r.Delete(ctx, u.ID, k.ID); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if _, err := r.Use(ctx, nk.Hash); err != apikey.ErrNotFound {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}
+	}
+}
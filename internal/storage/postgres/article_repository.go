@@ -5,61 +5,112 @@ import (
 	"database/sql"
 
 	"github.com/dipress/crmifc/internal/article"
-	"github.com/jmoiron/sqlx"
+	"github.com/dipress/crmifc/internal/kit/query"
+	"github.com/lib/pq"
 	"github.com/pkg/errors"
 )
 
 // ArticleRepository holds CRUD actions for article.
 type ArticleRepository struct {
-	db *sqlx.DB
+	db *database
 }
 
 //NewArticleRepository factory prepares the repository to work.
 func NewArticleRepository(db *sql.DB) *ArticleRepository {
 	r := ArticleRepository{
-		db: sqlx.NewDb(db, driverName),
+		db: newDatabase(db),
 	}
 
 	return &r
 }
 
+var articleViolations = violations{
+	"articles_category_id_fkey": article.ErrCategoryNotFound,
+}
+
 const createArticleQuery = `INSERT INTO 
-	articles (user_id, category_id, title, body) 
-	VALUES ($1, $2, $3, $4)
-	RETURNING id, user_id, category_id, title, body, created_at, updated_at`
+	articles (author_id, updated_by_id, category_id, title, body) 
+	VALUES ($1, $1, $2, $3, $4)
+	RETURNING id`
 
-// Create inserts a new category into the database.
+// Create inserts a new category into the database
+// and saves its first revision.
 func (r *ArticleRepository) Create(ctx context.Context, f *article.NewArticle, art *article.Article) error {
-	if err := r.db.QueryRowContext(ctx, createArticleQuery, f.UserID, f.CategoryID, f.Title, f.Body).
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "begin tx")
+	}
+	defer tx.Rollback()
+
+	var id int
+	if err := tx.QueryRowContext(ctx, createArticleQuery, f.AuthorID, f.CategoryID, f.Title, f.Body).Scan(&id); err != nil {
+		return errors.Wrap(articleViolations.translate(err), "query context scan")
+	}
+
+	if err := saveRevision(ctx, tx, id); err != nil {
+		return errors.Wrap(err, "save revision")
+	}
+
+	if err := tx.QueryRowContext(ctx, findArticleQuery, id).
 		Scan(
 			&art.ID,
-			&art.UserID,
+			&art.AuthorID,
+			&art.AuthorUsername,
+			&art.UpdatedByID,
+			&art.UpdatedByUsername,
 			&art.CategoryID,
 			&art.Title,
 			&art.Body,
 			&art.CreatedAt,
 			&art.UpdatedAt,
+			&art.Version,
 		); err != nil {
-		return errors.Wrap(err, "query context scan")
+		return errors.Wrap(err, "query row scan")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "commit tx")
 	}
 
 	return nil
 }
 
-const findArticleQuery = `SELECT id, user_id, category_id, title, body, created_at, updated_at FROM articles WHERE id = $1`
+const findArticleQuery = `
+	SELECT
+		articles.id,
+		articles.author_id,
+		COALESCE(authors.username, ''),
+		articles.updated_by_id,
+		COALESCE(editors.username, ''),
+		articles.category_id,
+		articles.title,
+		articles.body,
+		articles.created_at,
+		articles.updated_at,
+		articles.version
+	FROM
+		articles
+		LEFT JOIN users authors ON articles.author_id = authors.id
+		LEFT JOIN users editors ON articles.updated_by_id = editors.id
+	WHERE
+		articles.id = $1 AND articles.deleted_at IS NULL`
 
-// Find finds a article by id.
+// Find finds a article by id, deleted articles aren't found.
 func (r *ArticleRepository) Find(ctx context.Context, id int) (*article.Article, error) {
 	var a article.Article
 	if err := r.db.QueryRowContext(ctx, findArticleQuery, id).
 		Scan(
 			&a.ID,
-			&a.UserID,
+			&a.AuthorID,
+			&a.AuthorUsername,
+			&a.UpdatedByID,
+			&a.UpdatedByUsername,
 			&a.CategoryID,
 			&a.Title,
 			&a.Body,
 			&a.CreatedAt,
 			&a.UpdatedAt,
+			&a.Version,
 		); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, article.ErrNotFound
@@ -71,79 +122,334 @@ func (r *ArticleRepository) Find(ctx context.Context, id int) (*article.Article,
 	return &a, nil
 }
 
-const updateArticleQuery = `UPDATE articles SET user_id=:user_id, category_id=:category_id, title=:title, body=:body, updated_at=now() WHERE id=:id`
+const (
+	updateArticleQuery = `
+	UPDATE articles SET updated_by_id=:updated_by_id, category_id=:category_id, title=:title, body=:body, version=version+1, updated_at=now()
+	WHERE id=:id AND version=:version AND deleted_at IS NULL
+	RETURNING version, updated_at`
+	articleExistsQuery = `SELECT EXISTS(SELECT 1 FROM articles WHERE id=$1 AND deleted_at IS NULL)`
+)
 
-// Update updates article by id.
+// Update updates article by id when its version is still the version
+// of the article and saves the new state as the next revision.
+// The version is incremented then.
 func (r *ArticleRepository) Update(ctx context.Context, id int, a *article.Article) error {
-	stmt, err := r.db.PrepareNamed(updateArticleQuery)
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "begin tx")
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareNamedContext(ctx, updateArticleQuery)
 	if err != nil {
 		return errors.Wrap(err, "prepare named")
 	}
 	defer stmt.Close()
 
-	if _, err := stmt.ExecContext(ctx, map[string]interface{}{
-		"id":          id,
-		"user_id":     a.UserID,
-		"category_id": a.CategoryID,
-		"title":       a.Title,
-		"body":        a.Body,
-	}); err != nil {
+	if err := stmt.QueryRowxContext(ctx, map[string]interface{}{
+		"id":            id,
+		"updated_by_id": a.UpdatedByID,
+		"category_id":   a.CategoryID,
+		"title":         a.Title,
+		"body":          a.Body,
+		"version":       a.Version,
+	}).Scan(&a.Version, &a.UpdatedAt); err != nil {
 		if err == sql.ErrNoRows {
-			return article.ErrNotFound
+			return staleOrNotFound(ctx, tx, article.ErrNotFound, article.ErrVersionMismatch, articleExistsQuery, id)
 		}
-		return errors.Wrap(err, "exec context")
+		return errors.Wrap(articleViolations.translate(err), "query row scan")
+	}
+
+	if err := saveRevision(ctx, tx, id); err != nil {
+		return errors.Wrap(err, "save revision")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "commit tx")
 	}
+
 	return nil
 }
 
-const deleteArticleQuery = `DELETE FROM articles WHERE id=:id`
+const deleteArticleQuery = `UPDATE articles SET deleted_at=now() WHERE id=$1 AND deleted_at IS NULL`
 
-// Delete deletes article by id.
+// Delete moves article to the trash, the revisions are kept.
 func (r *ArticleRepository) Delete(ctx context.Context, id int) error {
-	stmt, err := r.db.PrepareNamed(deleteArticleQuery)
-	if err != nil {
-		return errors.Wrap(err, "prepare named")
-	}
-	defer stmt.Close()
+	return execOne(ctx, r.db, article.ErrNotFound, deleteArticleQuery, id)
+}
 
-	if _, err := stmt.ExecContext(ctx, map[string]interface{}{
-		"id": id,
-	}); err != nil {
-		if err == sql.ErrNoRows {
-			return article.ErrNotFound
-		}
-		return errors.Wrap(err, "exec context")
-	}
+const undeleteArticleQuery = `UPDATE articles SET deleted_at=NULL WHERE id=$1 AND deleted_at IS NOT NULL`
 
-	return nil
+// Undelete restores article from the trash.
+func (r *ArticleRepository) Undelete(ctx context.Context, id int) error {
+	return execOne(ctx, r.db, article.ErrNotFound, undeleteArticleQuery, id)
+}
+
+const purgeArticleQuery = `DELETE FROM articles WHERE id=$1 AND deleted_at IS NOT NULL`
+
+// Purge deletes article from the trash permanently,
+// the revisions are deleted by the cascade.
+func (r *ArticleRepository) Purge(ctx context.Context, id int) error {
+	return execOne(ctx, r.db, article.ErrNotFound, purgeArticleQuery, id)
+}
+
+var listArticles = listing{
+	columns: `
+		articles.id,
+		articles.author_id,
+		COALESCE(authors.username, ''),
+		articles.updated_by_id,
+		COALESCE(editors.username, ''),
+		articles.category_id,
+		articles.title,
+		articles.body,
+		articles.created_at,
+		articles.updated_at,
+		articles.deleted_at,
+		articles.version`,
+	from: `
+		articles
+		LEFT JOIN users authors ON articles.author_id = authors.id
+		LEFT JOIN users editors ON articles.updated_by_id = editors.id`,
+	id:    "articles.id",
+	scope: "articles.deleted_at IS NULL",
+	sort: map[string]string{
+		"id":         "articles.id",
+		"title":      "articles.title",
+		"created_at": "articles.created_at",
+		"updated_at": "articles.updated_at",
+	},
+	filter: map[string]column{
+		"user_id":       {name: "articles.author_id", typ: intColumn},
+		"author_id":     {name: "articles.author_id", typ: intColumn},
+		"updated_by_id": {name: "articles.updated_by_id", typ: intColumn},
+		"category_id":   {name: "articles.category_id", typ: intColumn},
+	},
+}
+
+var listDeletedArticles = listArticles.withScope("articles.deleted_at IS NOT NULL")
+
+// List shows articles page by given query.
+func (r *ArticleRepository) List(ctx context.Context, q *query.Query, articles *article.Articles) error {
+	return r.list(ctx, listArticles, q, articles)
 }
 
-const listArticleQuery = `SELECT * FROM articles`
+// ListDeleted shows page of the articles in the trash by given query.
+func (r *ArticleRepository) ListDeleted(ctx context.Context, q *query.Query, articles *article.Articles) error {
+	return r.list(ctx, listDeletedArticles, q, articles)
+}
+
+func (r *ArticleRepository) list(ctx context.Context, l listing, q *query.Query, articles *article.Articles) error {
+	listQuery, args, err := l.build(q)
+	if err != nil {
+		return errors.Wrap(err, "build list query")
+	}
 
-// List shows all articles.
-func (r *ArticleRepository) List(ctx context.Context, articles *article.Articles) error {
-	rows, err := r.db.QueryxContext(ctx, listArticleQuery)
+	rows, err := r.db.QueryxContext(ctx, listQuery, args...)
 	if err != nil {
 		return errors.Wrap(err, "query rows")
 	}
 	defer rows.Close()
 
+	var values []string
 	for rows.Next() {
-		var a article.Article
+		var (
+			a     article.Article
+			value string
+		)
 		if err := rows.Scan(
 			&a.ID,
-			&a.UserID,
+			&a.AuthorID,
+			&a.AuthorUsername,
+			&a.UpdatedByID,
+			&a.UpdatedByUsername,
+			&a.CategoryID,
 			&a.Title,
 			&a.Body,
-			&a.CategoryID,
 			&a.CreatedAt,
 			&a.UpdatedAt,
+			&a.DeletedAt,
+			&a.Version,
+			&value,
 		); err != nil {
 			return errors.Wrap(err, "articles query row scan on loop")
 		}
-
 		articles.Articles = append(articles.Articles, a)
+		values = append(values, value)
+	}
+
+	if err := rows.Err(); err != nil {
+		return errors.Wrap(err, "articles rows")
+	}
+
+	if len(values) > q.Limit {
+		last := q.Limit - 1
+		articles.Articles = articles.Articles[:q.Limit]
+		articles.NextCursor = nextCursor(q, values[last], articles.Articles[last].ID)
+	}
+
+	return nil
+}
+
+const searchArticleQuery = `
+	SELECT
+		articles.id,
+		articles.author_id,
+		COALESCE(authors.username, ''),
+		articles.updated_by_id,
+		COALESCE(editors.username, ''),
+		articles.category_id,
+		articles.title,
+		articles.body,
+		articles.created_at,
+		articles.updated_at,
+		ts_rank_cd(articles.search, q) AS rank,
+		ts_headline('english', articles.title, q, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=TRUE'),
+		ts_headline('english', articles.body, q, 'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MinWords=15, MaxWords=35'),
+		count(*) OVER ()
+	FROM
+		articles
+		LEFT JOIN users authors ON articles.author_id = authors.id
+		LEFT JOIN users editors ON articles.updated_by_id = editors.id,
+		websearch_to_tsquery('english', $1) q
+	WHERE
+		articles.search @@ q
+		AND articles.deleted_at IS NULL
+		AND ($2::int[] IS NULL OR articles.category_id = ANY($2))
+	ORDER BY
+		rank DESC,
+		articles.id DESC
+	LIMIT $3
+	OFFSET $4`
+
+// Search finds articles by full-text query ordered by rank.
+func (r *ArticleRepository) Search(ctx context.Context, sq *article.SearchQuery, results *article.SearchResults) error {
+	var categories []int64
+	for _, id := range sq.CategoryIDs {
+		categories = append(categories, int64(id))
+	}
+
+	rows, err := r.db.QueryxContext(ctx, searchArticleQuery, sq.Query, pq.Array(categories), sq.Limit, sq.Offset)
+	if err != nil {
+		return errors.Wrap(err, "query rows")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var res article.SearchResult
+		if err := rows.Scan(
+			&res.Article.ID,
+			&res.Article.AuthorID,
+			&res.Article.AuthorUsername,
+			&res.Article.UpdatedByID,
+			&res.Article.UpdatedByUsername,
+			&res.Article.CategoryID,
+			&res.Article.Title,
+			&res.Article.Body,
+			&res.Article.CreatedAt,
+			&res.Article.UpdatedAt,
+			&res.Rank,
+			&res.Title,
+			&res.Snippet,
+			&results.Total,
+		); err != nil {
+			return errors.Wrap(err, "search query row scan on loop")
+		}
+		results.Results = append(results.Results, res)
+	}
+
+	if err := rows.Err(); err != nil {
+		return errors.Wrap(err, "search rows")
+	}
+
+	return nil
+}
+
+const saveRevisionQuery = `
+	INSERT INTO article_revisions (article_id, version, user_id, category_id, title, body)
+	SELECT
+		id,
+		(SELECT COALESCE(MAX(version), 0) + 1 FROM article_revisions WHERE article_id = $1),
+		updated_by_id,
+		category_id,
+		title,
+		body
+	FROM articles
+	WHERE id = $1`
+
+// saveRevision copies the current state of the article
+// into the next revision.
+func saveRevision(ctx context.Context, tx executor, id int) error {
+	if _, err := tx.ExecContext(ctx, saveRevisionQuery, id); err != nil {
+		return errors.Wrap(err, "exec context")
+	}
+
+	return nil
+}
+
+const listRevisionsQuery = `
+	SELECT id, article_id, version, user_id, category_id, title, body, created_at
+	FROM article_revisions
+	WHERE article_id = $1
+	ORDER BY version DESC`
+
+// Revisions shows all revisions of the article, the latest first.
+func (r *ArticleRepository) Revisions(ctx context.Context, articleID int, revisions *article.Revisions) error {
+	rows, err := r.db.QueryxContext(ctx, listRevisionsQuery, articleID)
+	if err != nil {
+		return errors.Wrap(err, "query rows")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var rev article.Revision
+		if err := rows.Scan(
+			&rev.ID,
+			&rev.ArticleID,
+			&rev.Version,
+			&rev.UserID,
+			&rev.CategoryID,
+			&rev.Title,
+			&rev.Body,
+			&rev.CreatedAt,
+		); err != nil {
+			return errors.Wrap(err, "revisions query row scan on loop")
+		}
+		revisions.Revisions = append(revisions.Revisions, rev)
+	}
+
+	if err := rows.Err(); err != nil {
+		return errors.Wrap(err, "revisions rows")
 	}
 
 	return nil
 }
+
+const findRevisionQuery = `
+	SELECT id, article_id, version, user_id, category_id, title, body, created_at
+	FROM article_revisions
+	WHERE article_id = $1 AND version = $2`
+
+// FindRevision finds a revision of the article by version.
+func (r *ArticleRepository) FindRevision(ctx context.Context, articleID, version int) (*article.Revision, error) {
+	var rev article.Revision
+	if err := r.db.QueryRowContext(ctx, findRevisionQuery, articleID, version).
+		Scan(
+			&rev.ID,
+			&rev.ArticleID,
+			&rev.Version,
+			&rev.UserID,
+			&rev.CategoryID,
+			&rev.Title,
+			&rev.Body,
+			&rev.CreatedAt,
+		); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, article.ErrRevisionNotFound
+		}
+
+		return nil, errors.Wrap(err, "query row scan")
+	}
+
+	return &rev, nil
+}
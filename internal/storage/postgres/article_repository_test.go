@@ -2,9 +2,14 @@ package postgres
 
 import (
 	"context"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/dipress/crmifc/internal/article"
+	"github.com/dipress/crmifc/internal/kit/query"
+	"github.com/dipress/crmifc/internal/user"
+	"github.com/pkg/errors"
 )
 
 func TestCreateArticle(t *testing.T) {
@@ -21,8 +26,8 @@ func TestCreateArticle(t *testing.T) {
 		t.Log("\ttest:0\tshould create the article into the database")
 		{
 			na := article.NewArticle{
-				UserID:     1,
-				CategoryID: 1,
+				AuthorID:   createUser(t, db, "article_creator"),
+				CategoryID: createCategory(t, db, "Created"),
 				Title:      "article title",
 				Body:       "article body",
 			}
@@ -38,6 +43,22 @@ func TestCreateArticle(t *testing.T) {
 			}
 
 		}
+
+		t.Log("\ttest:1\tshould return error on unknown category")
+		{
+			na := article.NewArticle{
+				AuthorID:   createUser(t, db, "article_orphan"),
+				CategoryID: createCategory(t, db, "Missing") + 100,
+				Title:      "article title",
+				Body:       "article body",
+			}
+
+			var art article.Article
+			err := r.Create(ctx, &na, &art)
+			if errors.Cause(err) != article.ErrCategoryNotFound {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}
 	}
 }
 
@@ -53,8 +74,8 @@ func TestFindArticle(t *testing.T) {
 		r := NewArticleRepository(db)
 
 		na := article.NewArticle{
-			UserID:     2,
-			CategoryID: 3,
+			AuthorID:   createUser(t, db, "article_finder"),
+			CategoryID: createCategory(t, db, "Found"),
 			Title:      "my new title",
 			Body:       "my new body",
 		}
@@ -86,9 +107,11 @@ func TestUpdateArticle(t *testing.T) {
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
 
+		author := createUser(t, db, "article_author")
+
 		na := article.NewArticle{
-			UserID:     2,
-			CategoryID: 12,
+			AuthorID:   author,
+			CategoryID: createCategory(t, db, "Original"),
 			Title:      "my new title",
 			Body:       "my new body",
 		}
@@ -103,13 +126,32 @@ func TestUpdateArticle(t *testing.T) {
 		{
 			art.Title = "my update title"
 			art.Body = "my update body"
-			art.CategoryID = 13
+			art.CategoryID = createCategory(t, db, "Updated")
 
-			err := r.Update(ctx, 1, &art)
+			err := r.Update(ctx, art.ID, &art)
 			if err != nil {
 				t.Errorf("unexpected error: %v", err)
 			}
 		}
+
+		t.Log("\ttest:1\tshould keep the author and save the last editor")
+		{
+			editor := createUser(t, db, "article_editor")
+			art.UpdatedByID = editor
+
+			if err := r.Update(ctx, art.ID, &art); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			a, err := r.Find(ctx, art.ID)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if a.AuthorID != author || a.UpdatedByID != editor {
+				t.Errorf("unexpected author %d and editor %d", a.AuthorID, a.UpdatedByID)
+			}
+		}
 	}
 }
 
@@ -125,8 +167,8 @@ func TestArticleDelete(t *testing.T) {
 		defer cancel()
 
 		na := article.NewArticle{
-			UserID:     5,
-			CategoryID: 32,
+			AuthorID:   createUser(t, db, "article_deleter"),
+			CategoryID: createCategory(t, db, "Deleted"),
 			Title:      "my new title",
 			Body:       "my new body",
 		}
@@ -144,6 +186,70 @@ func TestArticleDelete(t *testing.T) {
 				t.Errorf("unexpected error: %v", err)
 			}
 		}
+
+		t.Log("\ttest:1\tshould move the deleted article to the trash")
+		{
+			if _, err := r.Find(ctx, art.ID); err != article.ErrNotFound {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			var trash article.Articles
+			if err := r.ListDeleted(ctx, &query.Query{}, &trash); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(trash.Articles) != 1 || trash.Articles[0].ID != art.ID || trash.Articles[0].DeletedAt == nil {
+				t.Errorf("unexpected trash: %+v", trash)
+			}
+
+			var articles article.Articles
+			if err := r.List(ctx, &query.Query{}, &articles); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(articles.Articles) != 0 {
+				t.Errorf("unexpected articles: %+v", articles)
+			}
+		}
+
+		t.Log("\ttest:2\tshould restore the article from the trash")
+		{
+			if err := r.Undelete(ctx, art.ID); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if _, err := r.Find(ctx, art.ID); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if err := r.Purge(ctx, art.ID); err != article.ErrNotFound {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}
+
+		t.Log("\ttest:3\tshould purge the deleted article with the revisions")
+		{
+			if err := r.Delete(ctx, art.ID); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if err := r.Purge(ctx, art.ID); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			var revisions article.Revisions
+			if err := r.Revisions(ctx, art.ID, &revisions); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(revisions.Revisions) != 0 {
+				t.Errorf("unexpected revisions: %+v", revisions)
+			}
+
+			if err := r.Undelete(ctx, art.ID); err != article.ErrNotFound {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}
 	}
 }
 
@@ -155,9 +261,12 @@ func TestListArticles(t *testing.T) {
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
 
+		author := createUser(t, db, "article_lister")
+		cat := createCategory(t, db, "Listed")
+
 		na1 := article.NewArticle{
-			UserID:     6,
-			CategoryID: 9,
+			AuthorID:   author,
+			CategoryID: cat,
 			Title:      "my new title1",
 			Body:       "my new body1",
 		}
@@ -169,8 +278,8 @@ func TestListArticles(t *testing.T) {
 		}
 
 		na2 := article.NewArticle{
-			UserID:     6,
-			CategoryID: 9,
+			AuthorID:   author,
+			CategoryID: cat,
 			Title:      "my new title2",
 			Body:       "my new body2",
 		}
@@ -184,7 +293,7 @@ func TestListArticles(t *testing.T) {
 		t.Log("\ttest:0\tshould show list of the articles")
 		{
 			var articles article.Articles
-			err := repo.List(ctx, &articles)
+			err := repo.List(ctx, query.New(), &articles)
 
 			if err != nil {
 				t.Errorf("unexpected error: %v", err)
@@ -196,3 +305,295 @@ func TestListArticles(t *testing.T) {
 		}
 	}
 }
+
+func TestListArticlesPagination(t *testing.T) {
+	t.Log("with initialized repository")
+	{
+		db, teardown := postgresDB(t)
+		defer teardown()
+
+		repo := NewArticleRepository(db)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		author := createUser(t, db, "article_paginator")
+		cat := createCategory(t, db, "Paginated")
+
+		for i := 0; i < 3; i++ {
+			na := article.NewArticle{
+				AuthorID:   author,
+				CategoryID: cat,
+				Title:      "paginated title",
+				Body:       "paginated body",
+			}
+
+			var art article.Article
+			if err := repo.Create(ctx, &na, &art); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}
+
+		q := query.New()
+		q.Limit = 2
+		q.Sort = "created_at"
+		q.Direction = query.Desc
+		q.Filters["user_id"] = strconv.Itoa(author)
+
+		t.Log("\ttest:0\tshould show the first page with next cursor")
+		{
+			var articles article.Articles
+			err := repo.List(ctx, q, &articles)
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if len(articles.Articles) != 2 {
+				t.Error("expected to slice of two articles")
+			}
+
+			if articles.NextCursor == "" {
+				t.Error("expected to next cursor")
+			}
+
+			q.Cursor = articles.NextCursor
+		}
+
+		t.Log("\ttest:1\tshould show the last page without next cursor")
+		{
+			var articles article.Articles
+			err := repo.List(ctx, q, &articles)
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if len(articles.Articles) != 1 {
+				t.Error("expected to slice of one article")
+			}
+
+			if articles.NextCursor != "" {
+				t.Error("expected to blank next cursor")
+			}
+		}
+
+		t.Log("\ttest:2\tshould return error on unknown sort field")
+		{
+			var articles article.Articles
+			err := repo.List(ctx, &query.Query{Sort: "body"}, &articles)
+			if errors.Cause(err) != query.ErrInvalidSort {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}
+	}
+}
+
+func TestSearchArticles(t *testing.T) {
+	t.Log("with initialized repository")
+	{
+		db, teardown := postgresDB(t)
+		defer teardown()
+
+		repo := NewArticleRepository(db)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		author := createUser(t, db, "article_searcher")
+		services := createCategory(t, db, "Services")
+		billing := createCategory(t, db, "Billing")
+
+		articles := []article.NewArticle{
+			{AuthorID: author, CategoryID: services, Title: "Router configuration", Body: "How to configure the router for a new subscriber."},
+			{AuthorID: author, CategoryID: billing, Title: "Payments", Body: "The router is returned after the contract is closed."},
+			{AuthorID: author, CategoryID: services, Title: "Television", Body: "Channels list of the basic package."},
+		}
+
+		for i := range articles {
+			var art article.Article
+			if err := repo.Create(ctx, &articles[i], &art); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}
+
+		t.Log("\ttest:0\tshould find articles ranked by relevance")
+		{
+			sq := article.SearchQuery{
+				Query: "router",
+				Limit: 10,
+			}
+
+			var results article.SearchResults
+			if err := repo.Search(ctx, &sq, &results); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if results.Total != 2 || len(results.Results) != 2 {
+				t.Fatalf("expected to find two articles, got %d", len(results.Results))
+			}
+
+			if results.Results[0].Article.Title != "Router configuration" {
+				t.Errorf("expected title match to rank first, got %q", results.Results[0].Article.Title)
+			}
+
+			if !strings.Contains(results.Results[0].Title, "<mark>Router</mark>") {
+				t.Errorf("expected highlighted title, got %q", results.Results[0].Title)
+			}
+		}
+
+		t.Log("\ttest:1\tshould filter found articles by category")
+		{
+			sq := article.SearchQuery{
+				Query:       "router",
+				CategoryIDs: []int{billing},
+				Limit:       10,
+			}
+
+			var results article.SearchResults
+			if err := repo.Search(ctx, &sq, &results); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if len(results.Results) != 1 {
+				t.Errorf("expected to find one article, got %d", len(results.Results))
+			}
+		}
+	}
+}
+
+func TestArticleRevisions(t *testing.T) {
+	t.Log("with initialized repository")
+	{
+		db, teardown := postgresDB(t)
+		defer teardown()
+
+		repo := NewArticleRepository(db)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		editor := createUser(t, db, "revision_editor")
+
+		na := article.NewArticle{
+			AuthorID:   createUser(t, db, "revision_author"),
+			CategoryID: createCategory(t, db, "Revised"),
+			Title:      "revision title",
+			Body:       "revision body",
+		}
+
+		var art article.Article
+		if err := repo.Create(ctx, &na, &art); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		art.UpdatedByID = editor
+		art.Body = "revision body\nsecond line"
+		if err := repo.Update(ctx, art.ID, &art); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		t.Log("\ttest:0\tshould show revisions of the article, the latest first")
+		{
+			var revisions article.Revisions
+			if err := repo.Revisions(ctx, art.ID, &revisions); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if len(revisions.Revisions) != 2 {
+				t.Fatalf("expected to two revisions, got %d", len(revisions.Revisions))
+			}
+
+			if revisions.Revisions[0].Version != 2 || revisions.Revisions[0].UserID != editor {
+				t.Errorf("unexpected latest revision: %+v", revisions.Revisions[0])
+			}
+		}
+
+		t.Log("\ttest:1\tshould find the first revision")
+		{
+			rev, err := repo.FindRevision(ctx, art.ID, 1)
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if rev.Body != "revision body" {
+				t.Errorf("unexpected revision body: %q", rev.Body)
+			}
+		}
+
+		t.Log("\ttest:2\tshould return error on unknown revision")
+		{
+			_, err := repo.FindRevision(ctx, art.ID, 3)
+			if err != article.ErrRevisionNotFound {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}
+	}
+}
+
+func TestArticleUsernames(t *testing.T) {
+	t.Log("with initialized repository")
+	{
+		db, teardown := postgresDB(t)
+		defer teardown()
+
+		userRepo := NewUserRepository(db)
+		repo := NewArticleRepository(db)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var author, editor user.User
+		for _, nu := range []struct {
+			u        *user.User
+			username string
+		}{
+			{u: &author, username: "article_author"},
+			{u: &editor, username: "article_editor"},
+		} {
+			newUser := user.NewUser{
+				Username:     nu.username,
+				Email:        nu.username + "@example.com",
+				PasswordHash: "$2y$12$e4.VBLqKAanAZs10dRL65O8.b0kHBC34pcGCN1HdJIchCi9im40Ei",
+				RoleID:       createRole(t, db, nu.username+"_role"),
+			}
+			if err := userRepo.Create(ctx, &newUser, nu.u); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}
+
+		na := article.NewArticle{
+			AuthorID:   author.ID,
+			CategoryID: createCategory(t, db, "Authored"),
+			Title:      "authored title",
+			Body:       "authored body",
+		}
+
+		var art article.Article
+		if err := repo.Create(ctx, &na, &art); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		t.Log("\ttest:0\tshould show the author as the first editor")
+		{
+			if art.AuthorUsername != "article_author" || art.UpdatedByUsername != "article_author" {
+				t.Errorf("unexpected usernames: %q, %q", art.AuthorUsername, art.UpdatedByUsername)
+			}
+		}
+
+		art.UpdatedByID = editor.ID
+		if err := repo.Update(ctx, art.ID, &art); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		t.Log("\ttest:1\tshould show usernames of the author and the last editor")
+		{
+			a, err := repo.Find(ctx, art.ID)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if a.AuthorUsername != "article_author" || a.UpdatedByUsername != "article_editor" {
+				t.Errorf("unexpected usernames: %q, %q", a.AuthorUsername, a.UpdatedByUsername)
+			}
+		}
+	}
+}
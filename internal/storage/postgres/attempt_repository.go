@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/dipress/crmifc/internal/auth"
+	"github.com/pkg/errors"
+)
+
+// AttemptRepository holds failed sign in attempts.
+type AttemptRepository struct {
+	db *database
+}
+
+// NewAttemptRepository factory prepares the repository to work.
+func NewAttemptRepository(db *sql.DB) *AttemptRepository {
+	r := AttemptRepository{
+		db: newDatabase(db),
+	}
+
+	return &r
+}
+
+const findAttemptsQuery = `SELECT key, failures, locked_until FROM login_attempts WHERE key = $1`
+
+// FindAttempts finds attempts by key. Unknown key has no attempts.
+func (r *AttemptRepository) FindAttempts(ctx context.Context, key string) (*auth.Attempts, error) {
+	a := auth.Attempts{
+		Key: key,
+	}
+
+	if err := r.db.QueryRowContext(ctx, findAttemptsQuery, key).
+		Scan(
+			&a.Key,
+			&a.Failures,
+			&a.LockedUntil,
+		); err != nil {
+		if err == sql.ErrNoRows {
+			return &a, nil
+		}
+		return nil, errors.Wrap(err, "query row scan")
+	}
+
+	return &a, nil
+}
+
+const addFailureQuery = `
+	INSERT INTO login_attempts (key, failures, updated_at)
+	VALUES ($1, 1, now() AT TIME ZONE 'UTC')
+	ON CONFLICT (key) DO UPDATE SET
+		failures = CASE
+			WHEN login_attempts.updated_at < $2 THEN 1
+			ELSE login_attempts.failures + 1
+		END,
+		updated_at = EXCLUDED.updated_at
+	RETURNING failures`
+
+// AddFailure increments failures of the key and returns their number.
+// The failures older than window are forgotten.
+func (r *AttemptRepository) AddFailure(ctx context.Context, key string, window time.Duration) (int, error) {
+	var failures int
+	if err := r.db.QueryRowContext(ctx, addFailureQuery, key, time.Now().Add(-window).UTC()).Scan(&failures); err != nil {
+		return 0, errors.Wrap(err, "query row scan")
+	}
+	return failures, nil
+}
+
+const lockQuery = `UPDATE login_attempts SET locked_until = $2 WHERE key = $1`
+
+// Lock locks the key until given time.
+func (r *AttemptRepository) Lock(ctx context.Context, key string, until time.Time) error {
+	if _, err := r.db.ExecContext(ctx, lockQuery, key, until.UTC()); err != nil {
+		return errors.Wrap(err, "exec context")
+	}
+	return nil
+}
+
+const resetAttemptsQuery = `DELETE FROM login_attempts WHERE key = $1`
+
+// ResetAttempts forgets failures and the lock of the key.
+func (r *AttemptRepository) ResetAttempts(ctx context.Context, key string) error {
+	if _, err := r.db.ExecContext(ctx, resetAttemptsQuery, key); err != nil {
+		return errors.Wrap(err, "exec context")
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestAttempts(t *testing.T) {
+	t.Log("with initialized repository")
+	{
+		db, teardown := postgresDB(t)
+		defer teardown()
+
+		r := NewAttemptRepository(db)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		key := "email:username@example.com"
+
+		t.Log("\ttest:0\tshould count failures of the key")
+		{
+			for i := 1; i <= 3; i++ {
+				failures, err := r.AddFailure(ctx, key, time.Hour)
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+
+				if failures != i {
+					t.Errorf("unexpected failures: %d expected: %d", failures, i)
+				}
+			}
+		}
+
+		t.Log("\ttest:1\tshould lock the key")
+		{
+			until := time.Now().Add(time.Minute)
+			if err := r.Lock(ctx, key, until); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			a, err := r.FindAttempts(ctx, key)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if a.Failures != 3 || a.LockedUntil == nil || !a.LockedUntil.After(time.Now()) {
+				t.Errorf("unexpected attempts: %+v", a)
+			}
+		}
+
+		t.Log("\ttest:2\tshould forget failures out of the window")
+		{
+			failures, err := r.AddFailure(ctx, key, -time.Minute)
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if failures != 1 {
+				t.Errorf("unexpected failures: %d expected: %d", failures, 1)
+			}
+		}
+
+		t.Log("\ttest:3\tshould reset attempts of the key")
+		{
+			if err := r.ResetAttempts(ctx, key); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			a, err := r.FindAttempts(ctx, key)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if a.Failures != 0 || a.LockedUntil != nil {
+				t.Errorf("unexpected attempts: %+v", a)
+			}
+		}
+	}
+}
@@ -5,47 +5,56 @@ import (
 	"database/sql"
 
 	"github.com/dipress/crmifc/internal/category"
-	"github.com/jmoiron/sqlx"
+	"github.com/dipress/crmifc/internal/kit/query"
 	"github.com/pkg/errors"
 )
 
 // CategoryRepository holds CRUD actions.
 type CategoryRepository struct {
-	db *sqlx.DB
+	db *database
 }
 
 //NewCategoryRepository factory prepares the repository to work.
 func NewCategoryRepository(db *sql.DB) *CategoryRepository {
 	r := CategoryRepository{
-		db: sqlx.NewDb(db, driverName),
+		db: newDatabase(db),
 	}
 
 	return &r
 }
 
+var (
+	categoryViolations = violations{
+		"categories_name_key": category.ErrNameExists,
+	}
+	purgeCategoryViolations = violations{
+		"articles_category_id_fkey": category.ErrInUse,
+	}
+)
+
 const createCategoryQuery = `INSERT INTO 
 	categories (name) 
 	VALUES ($1) 
-	RETURNING id, name, created_at, updated_at`
+	RETURNING id, name, created_at, updated_at, version`
 
 // Create inserts a new category into the database.
 func (r *CategoryRepository) Create(ctx context.Context, f *category.NewCategory, cat *category.Category) error {
 	if err := r.db.QueryRowContext(ctx, createCategoryQuery, f.Name).
-		Scan(&cat.ID, &cat.Name, &cat.CreatedAt, &cat.UpdatedAt); err != nil {
-		return errors.Wrap(err, "query context scan")
+		Scan(&cat.ID, &cat.Name, &cat.CreatedAt, &cat.UpdatedAt, &cat.Version); err != nil {
+		return errors.Wrap(categoryViolations.translate(err), "query context scan")
 	}
 
 	return nil
 }
 
-const findCategoryQuery = `SELECT id, name, created_at, updated_at FROM categories WHERE id = $1`
+const findCategoryQuery = `SELECT id, name, created_at, updated_at, version FROM categories WHERE id = $1 AND deleted_at IS NULL`
 
-// Find finds a category by id.
+// Find finds a category by id, deleted categories aren't found.
 func (r *CategoryRepository) Find(ctx context.Context, id int) (*category.Category, error) {
 	var cat category.Category
 
 	if err := r.db.QueryRowContext(ctx, findCategoryQuery, id).
-		Scan(&cat.ID, &cat.Name, &cat.CreatedAt, &cat.UpdatedAt); err != nil {
+		Scan(&cat.ID, &cat.Name, &cat.CreatedAt, &cat.UpdatedAt, &cat.Version); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, category.ErrNotFound
 		}
@@ -55,70 +64,122 @@ func (r *CategoryRepository) Find(ctx context.Context, id int) (*category.Catego
 	return &cat, nil
 }
 
-const updateCategoryQuery = `UPDATE categories SET name=:name, updated_at=now() WHERE id=:id`
+const (
+	updateCategoryQuery = `
+	UPDATE categories SET name=:name, version=version+1, updated_at=now()
+	WHERE id=:id AND version=:version AND deleted_at IS NULL
+	RETURNING version, updated_at`
+	categoryExistsQuery = `SELECT EXISTS(SELECT 1 FROM categories WHERE id=$1 AND deleted_at IS NULL)`
+)
 
-// Update updates a category by id.
+// Update updates a category by id when its version is still the
+// version of the category, the version is incremented then.
 func (r *CategoryRepository) Update(ctx context.Context, id int, cat *category.Category) error {
-	stmt, err := r.db.PrepareNamed(updateCategoryQuery)
+	stmt, err := r.db.PrepareNamedContext(ctx, updateCategoryQuery)
 	if err != nil {
 		return errors.Wrap(err, "prepare named")
 	}
 	defer stmt.Close()
 
-	if _, err := stmt.ExecContext(ctx, map[string]interface{}{
-		"id":   id,
-		"name": cat.Name,
-	}); err != nil {
+	if err := stmt.QueryRowxContext(ctx, map[string]interface{}{
+		"id":      id,
+		"name":    cat.Name,
+		"version": cat.Version,
+	}).Scan(&cat.Version, &cat.UpdatedAt); err != nil {
 		if err == sql.ErrNoRows {
-			return category.ErrNotFound
+			return staleOrNotFound(ctx, r.db, category.ErrNotFound, category.ErrVersionMismatch, categoryExistsQuery, id)
 		}
 
-		return errors.Wrap(err, "exec context")
+		return errors.Wrap(categoryViolations.translate(err), "query row scan")
 	}
 
 	return nil
 }
 
-const deleteCategoryQuery = `DELETE FROM categories WHERE id=:id`
+const deleteCategoryQuery = `UPDATE categories SET deleted_at=now() WHERE id=$1 AND deleted_at IS NULL`
 
-// Delete deletes category by id.
+// Delete moves category to the trash.
 func (r *CategoryRepository) Delete(ctx context.Context, id int) error {
-	stmt, err := r.db.PrepareNamed(deleteCategoryQuery)
-	if err != nil {
-		return errors.Wrap(err, "prepare named")
-	}
-	defer stmt.Close()
+	return execOne(ctx, r.db, category.ErrNotFound, deleteCategoryQuery, id)
+}
 
-	if _, err := stmt.ExecContext(ctx, map[string]interface{}{
-		"id": id,
-	}); err != nil {
-		if err == sql.ErrNoRows {
-			return category.ErrNotFound
-		}
+const undeleteCategoryQuery = `UPDATE categories SET deleted_at=NULL WHERE id=$1 AND deleted_at IS NOT NULL`
 
-		return errors.Wrap(err, "exec context")
-	}
+// Undelete restores category from the trash.
+func (r *CategoryRepository) Undelete(ctx context.Context, id int) error {
+	return execOne(ctx, r.db, category.ErrNotFound, undeleteCategoryQuery, id)
+}
 
-	return nil
+const purgeCategoryQuery = `DELETE FROM categories WHERE id=$1 AND deleted_at IS NOT NULL`
+
+// Purge deletes category from the trash permanently,
+// the category of articles isn't purged.
+func (r *CategoryRepository) Purge(ctx context.Context, id int) error {
+	return purgeCategoryViolations.translate(execOne(ctx, r.db, category.ErrNotFound, purgeCategoryQuery, id))
+}
+
+var listCategories = listing{
+	columns: "id, name, created_at, updated_at, deleted_at, version",
+	from:    "categories",
+	id:      "id",
+	scope:   "deleted_at IS NULL",
+	sort: map[string]string{
+		"id":         "id",
+		"name":       "name",
+		"created_at": "created_at",
+		"updated_at": "updated_at",
+	},
+	filter: map[string]column{
+		"name": {name: "name", typ: textColumn},
+	},
+}
+
+var listDeletedCategories = listCategories.withScope("deleted_at IS NOT NULL")
+
+// List shows categories page by given query.
+func (r *CategoryRepository) List(ctx context.Context, q *query.Query, cat *category.Categories) error {
+	return r.list(ctx, listCategories, q, cat)
+}
+
+// ListDeleted shows page of the categories in the trash by given query.
+func (r *CategoryRepository) ListDeleted(ctx context.Context, q *query.Query, cat *category.Categories) error {
+	return r.list(ctx, listDeletedCategories, q, cat)
 }
 
-const listCategoryQuery = `SELECT * FROM categories`
+func (r *CategoryRepository) list(ctx context.Context, l listing, q *query.Query, cat *category.Categories) error {
+	listQuery, args, err := l.build(q)
+	if err != nil {
+		return errors.Wrap(err, "build list query")
+	}
 
-// List shows all categories.
-func (r *CategoryRepository) List(ctx context.Context, cat *category.Categories) error {
-	rows, err := r.db.QueryxContext(ctx, listCategoryQuery)
+	rows, err := r.db.QueryxContext(ctx, listQuery, args...)
 	if err != nil {
 		return errors.Wrap(err, "query rows")
 	}
 	defer rows.Close()
 
+	var values []string
 	for rows.Next() {
-		var c category.Category
-		if err := rows.Scan(&c.ID, &c.Name, &c.CreatedAt, &c.UpdatedAt); err != nil {
+		var (
+			c     category.Category
+			value string
+		)
+		if err := rows.Scan(&c.ID, &c.Name, &c.CreatedAt, &c.UpdatedAt, &c.DeletedAt, &c.Version, &value); err != nil {
 			return errors.Wrap(err, "categories query row scan on loop")
 		}
 
 		cat.Categories = append(cat.Categories, c)
+		values = append(values, value)
+	}
+
+	if err := rows.Err(); err != nil {
+		return errors.Wrap(err, "categories rows")
+	}
+
+	if len(values) > q.Limit {
+		last := q.Limit - 1
+		cat.Categories = cat.Categories[:q.Limit]
+		cat.NextCursor = nextCursor(q, values[last], cat.Categories[last].ID)
 	}
 
 	return nil
@@ -4,7 +4,10 @@ import (
 	"context"
 	"testing"
 
+	"github.com/dipress/crmifc/internal/article"
 	"github.com/dipress/crmifc/internal/category"
+	"github.com/dipress/crmifc/internal/kit/query"
+	"github.com/pkg/errors"
 )
 
 func TestCreateCategory(t *testing.T) {
@@ -131,6 +134,49 @@ func TestCategoryDelete(t *testing.T) {
 				t.Errorf("unexpected error: %v", err)
 			}
 		}
+
+		t.Log("\ttest:1\tshould move the deleted category to the trash")
+		{
+			if _, err := r.Find(ctx, cat.ID); err != category.ErrNotFound {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if err := r.Delete(ctx, cat.ID); err != category.ErrNotFound {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			var trash category.Categories
+			if err := r.ListDeleted(ctx, &query.Query{}, &trash); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(trash.Categories) != 1 || trash.Categories[0].ID != cat.ID {
+				t.Errorf("unexpected trash: %+v", trash)
+			}
+		}
+
+		t.Log("\ttest:2\tshould restore and purge the category")
+		{
+			if err := r.Undelete(ctx, cat.ID); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if _, err := r.Find(ctx, cat.ID); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if err := r.Delete(ctx, cat.ID); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if err := r.Purge(ctx, cat.ID); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if err := r.Undelete(ctx, cat.ID); err != category.ErrNotFound {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}
 	}
 }
 
@@ -168,7 +214,7 @@ func TestListCategories(t *testing.T) {
 		t.Log("\ttest:0\tshould show list of categories")
 		{
 			var categories category.Categories
-			err := r.List(ctx, &categories)
+			err := r.List(ctx, query.New(), &categories)
 			if err != nil {
 				t.Errorf("unexpected error: %v", err)
 			}
@@ -176,6 +222,75 @@ func TestListCategories(t *testing.T) {
 			if len(categories.Categories) != 2 {
 				t.Error("expected to slice of two categories")
 			}
+
+			for _, c := range categories.Categories {
+				if c.Version != 1 {
+					t.Errorf("unexpected version of %q: %d", c.Name, c.Version)
+				}
+			}
+		}
+	}
+}
+
+func TestCategoryNameExists(t *testing.T) {
+	t.Log("with initialized repository")
+	{
+		db, teardown := postgresDB(t)
+		defer teardown()
+
+		r := NewCategoryRepository(db)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var cat category.Category
+		if err := r.Create(ctx, &category.NewCategory{Name: "Internet"}, &cat); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		t.Log("\ttest:0\tshould return error on duplicate name")
+		{
+			var dup category.Category
+			err := r.Create(ctx, &category.NewCategory{Name: "Internet"}, &dup)
+			if errors.Cause(err) != category.ErrNameExists {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}
+	}
+}
+
+func TestCategoryPurgeInUse(t *testing.T) {
+	t.Log("with initialized repository")
+	{
+		db, teardown := postgresDB(t)
+		defer teardown()
+
+		r := NewCategoryRepository(db)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		na := article.NewArticle{
+			AuthorID:   createUser(t, db, "category_author"),
+			CategoryID: createCategory(t, db, "Internet"),
+			Title:      "my title",
+			Body:       "my body",
+		}
+
+		var art article.Article
+		if err := NewArticleRepository(db).Create(ctx, &na, &art); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		t.Log("\ttest:0\tshould not purge the category of articles")
+		{
+			if err := r.Delete(ctx, na.CategoryID); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if err := r.Purge(ctx, na.CategoryID); errors.Cause(err) != category.ErrInUse {
+				t.Errorf("unexpected error: %v", err)
+			}
 		}
 	}
 }
@@ -0,0 +1,171 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/dipress/crmifc/internal/invitation"
+	"github.com/dipress/crmifc/internal/user"
+	"github.com/pkg/errors"
+)
+
+// InvitationRepository holds invitations of the users.
+type InvitationRepository struct {
+	db *database
+}
+
+// NewInvitationRepository factory prepares the repository to work.
+func NewInvitationRepository(db *sql.DB) *InvitationRepository {
+	r := InvitationRepository{
+		db: newDatabase(db),
+	}
+
+	return &r
+}
+
+// invitationColumns are the columns which are scanned by scanInvitation.
+const invitationColumns = `id, email, role_id, COALESCE(invited_by, 0), expires_at, created_at`
+
+const createInvitationQuery = `
+	INSERT INTO invitations (email, role_id, invited_by, token_hash, expires_at)
+	VALUES ($1, $2, $3, $4, $5)
+	ON CONFLICT (email) WHERE accepted_at IS NULL DO NOTHING
+	RETURNING ` + invitationColumns
+
+// Create inserts the hash of a new invitation token into the database.
+// Expiration times are stored in UTC.
+func (r *InvitationRepository) Create(ctx context.Context, ni *invitation.NewInvitation, inv *invitation.Invitation) error {
+	row := r.db.QueryRowContext(ctx, createInvitationQuery,
+		ni.Email, ni.RoleID, ni.InvitedBy, ni.Hash, ni.ExpiresAt.UTC())
+
+	if err := scanInvitation(row, inv); err != nil {
+		if err == sql.ErrNoRows {
+			return invitation.ErrExists
+		}
+		return errors.Wrap(err, "query row scan")
+	}
+	return nil
+}
+
+const listInvitationsQuery = `SELECT ` + invitationColumns + ` FROM invitations WHERE accepted_at IS NULL ORDER BY id`
+
+// List lists the pending invitations, the expired ones included.
+func (r *InvitationRepository) List(ctx context.Context, invs *invitation.Invitations) error {
+	rows, err := r.db.QueryContext(ctx, listInvitationsQuery)
+	if err != nil {
+		return errors.Wrap(err, "query context")
+	}
+	defer rows.Close()
+
+	invs.Invitations = make([]invitation.Invitation, 0)
+	for rows.Next() {
+		var inv invitation.Invitation
+		if err := scanInvitation(rows, &inv); err != nil {
+			return errors.Wrap(err, "rows scan")
+		}
+		invs.Invitations = append(invs.Invitations, inv)
+	}
+
+	if err := rows.Err(); err != nil {
+		return errors.Wrap(err, "rows err")
+	}
+	return nil
+}
+
+const findInvitationByTokenQuery = `
+	SELECT ` + invitationColumns + ` FROM invitations
+	WHERE token_hash = $1 AND accepted_at IS NULL AND expires_at > now() AT TIME ZONE 'UTC'`
+
+// FindByToken finds the pending invitation which isn't expired by the token hash.
+func (r *InvitationRepository) FindByToken(ctx context.Context, hash string) (*invitation.Invitation, error) {
+	var inv invitation.Invitation
+	if err := scanInvitation(r.db.QueryRowContext(ctx, findInvitationByTokenQuery, hash), &inv); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, invitation.ErrInvalidToken
+		}
+		return nil, errors.Wrap(err, "query row scan")
+	}
+	return &inv, nil
+}
+
+const renewInvitationQuery = `
+	UPDATE invitations SET token_hash = $2, expires_at = $3
+	WHERE id = $1 AND accepted_at IS NULL
+	RETURNING ` + invitationColumns
+
+// Renew replaces the token hash and the expiration time of the pending invitation.
+func (r *InvitationRepository) Renew(ctx context.Context, id int, hash string, expiresAt time.Time, inv *invitation.Invitation) error {
+	row := r.db.QueryRowContext(ctx, renewInvitationQuery, id, hash, expiresAt.UTC())
+	if err := scanInvitation(row, inv); err != nil {
+		if err == sql.ErrNoRows {
+			return invitation.ErrNotFound
+		}
+		return errors.Wrap(err, "query row scan")
+	}
+	return nil
+}
+
+const deleteInvitationQuery = `DELETE FROM invitations WHERE id = $1 AND accepted_at IS NULL`
+
+// Delete deletes the pending invitation.
+func (r *InvitationRepository) Delete(ctx context.Context, id int) error {
+	res, err := r.db.ExecContext(ctx, deleteInvitationQuery, id)
+	if err != nil {
+		return errors.Wrap(err, "exec context")
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "rows affected")
+	}
+
+	if affected == 0 {
+		return invitation.ErrNotFound
+	}
+	return nil
+}
+
+const acceptInvitationQuery = `
+	UPDATE invitations SET accepted_at = now()
+	WHERE id = $1 AND accepted_at IS NULL AND expires_at > now() AT TIME ZONE 'UTC'`
+
+// Accept marks the invitation accepted and creates the user in one
+// transaction, so the invitation is accepted only once.
+func (r *InvitationRepository) Accept(ctx context.Context, id int, nu *user.NewUser, usr *user.User) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "begin tx")
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, acceptInvitationQuery, id)
+	if err != nil {
+		return errors.Wrap(err, "accept invitation")
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "rows affected")
+	}
+
+	if affected == 0 {
+		return invitation.ErrInvalidToken
+	}
+
+	if err := tx.QueryRowContext(ctx, createUserQuery, nu.Username, nu.Email, nu.PasswordHash, nu.RoleID).
+		Scan(&usr.ID, &usr.Role.ID, &usr.Username, &usr.Email, &usr.CreatedAt, &usr.UpdatedAt); err != nil {
+		return errors.Wrap(userViolations.translate(err), "create user")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "commit")
+	}
+
+	return nil
+}
+
+// scanInvitation scans the invitation columns into inv.
+func scanInvitation(s scanner, inv *invitation.Invitation) error {
+	return s.Scan(&inv.ID, &inv.Email, &inv.RoleID, &inv.InvitedBy, &inv.ExpiresAt, &inv.CreatedAt)
+}
@@ -0,0 +1,151 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dipress/crmifc/internal/invitation"
+	"github.com/dipress/crmifc/internal/role"
+	"github.com/dipress/crmifc/internal/user"
+)
+
+func TestInvitation(t *testing.T) {
+	t.Log("with initialized repository")
+	{
+		db, teardown := postgresDB(t)
+		defer teardown()
+
+		r := NewInvitationRepository(db)
+		userRepo := NewUserRepository(db)
+		roleRepo := NewRoleRepository(db)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var rl role.Role
+		if err := roleRepo.Create(ctx, &role.NewRole{Name: "Invited"}, &rl); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		nu := user.NewUser{
+			RoleID:       createRole(t, db, "Inviter"),
+			Username:     "username_inviter",
+			Email:        "username_inviter@example.com",
+			PasswordHash: "hash",
+		}
+
+		var u user.User
+		if err := userRepo.Create(ctx, &nu, &u); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		ni := invitation.NewInvitation{
+			Email:     "username_invited@example.com",
+			RoleID:    rl.ID,
+			InvitedBy: u.ID,
+			Hash:      "2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae",
+			ExpiresAt: time.Now().Add(time.Hour),
+		}
+
+		var inv invitation.Invitation
+		t.Log("\ttest:0\tshould create and list the invitation")
+		{
+			if err := r.Create(ctx, &ni, &inv); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var invs invitation.Invitations
+			if err := r.List(ctx, &invs); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(invs.Invitations) != 1 || invs.Invitations[0].Email != ni.Email || invs.Invitations[0].InvitedBy != u.ID {
+				t.Errorf("unexpected invitations: %+v", invs)
+			}
+		}
+
+		t.Log("\ttest:1\tshould not invite the email twice")
+		{
+			other := ni
+			other.Hash = "fcde2b2edba56bf408601fb721fe9b5c338d10ee429ea04fae5511b68fbf8fb9"
+			if err := r.Create(ctx, &other, &invitation.Invitation{}); err != invitation.ErrExists {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}
+
+		t.Log("\ttest:2\tshould renew the token of the invitation")
+		{
+			renewed := "baa5a0964d3320fbc0c6a922140453c8513ea24ab8fd0577034804a967248096"
+			if err := r.Renew(ctx, inv.ID, renewed, time.Now().Add(time.Hour), &inv); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if _, err := r.FindByToken(ctx, ni.Hash); err != invitation.ErrInvalidToken {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			found, err := r.FindByToken(ctx, renewed)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if found.ID != inv.ID {
+				t.Errorf("unexpected invitation: %+v", found)
+			}
+		}
+
+		t.Log("\ttest:3\tshould accept the invitation once")
+		{
+			accepted := user.NewUser{
+				RoleID:       inv.RoleID,
+				Username:     "username_invited",
+				Email:        inv.Email,
+				PasswordHash: "hash",
+			}
+
+			var created user.User
+			if err := r.Accept(ctx, inv.ID, &accepted, &created); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if created.ID == 0 || created.Email != inv.Email {
+				t.Errorf("unexpected user: %+v", created)
+			}
+
+			if err := r.Accept(ctx, inv.ID, &accepted, &user.User{}); err != invitation.ErrInvalidToken {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if err := r.Delete(ctx, inv.ID); err != invitation.ErrNotFound {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}
+
+		t.Log("\ttest:4\tshould revoke the pending invitation")
+		{
+			if err := r.Create(ctx, &invitation.NewInvitation{
+				Email:     "username_revoked@example.com",
+				RoleID:    rl.ID,
+				InvitedBy: u.ID,
+				Hash:      "fcde2b2edba56bf408601fb721fe9b5c338d10ee429ea04fae5511b68fbf8fb9",
+				ExpiresAt: time.Now().Add(time.Hour),
+			}, &inv); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if err := r.Delete(ctx, inv.ID); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			var invs invitation.Invitations
+			if err := r.List(ctx, &invs); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(invs.Invitations) != 0 {
+				t.Errorf("unexpected invitations: %+v", invs)
+			}
+		}
+	}
+}
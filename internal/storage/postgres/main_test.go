@@ -1,6 +1,7 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 	"flag"
 	"fmt"
@@ -10,8 +11,11 @@ import (
 	"time"
 
 	txdb "github.com/DATA-DOG/go-txdb"
+	"github.com/dipress/crmifc/internal/category"
 	"github.com/dipress/crmifc/internal/kit/docker"
+	"github.com/dipress/crmifc/internal/role"
 	"github.com/dipress/crmifc/internal/storage/postgres/schema"
+	"github.com/dipress/crmifc/internal/user"
 	"github.com/ory/dockertest"
 )
 
@@ -63,3 +67,40 @@ func postgresDB(t *testing.T) (db *sql.DB, teardown func() error) {
 
 	return db, db.Close
 }
+
+// createRole inserts a role the rows of the test refer to.
+func createRole(t *testing.T, db *sql.DB, name string) int {
+	var rl role.Role
+	if err := NewRoleRepository(db).Create(context.Background(), &role.NewRole{Name: name}, &rl); err != nil {
+		t.Fatalf("create role: %v", err)
+	}
+
+	return rl.ID
+}
+
+// createUser inserts a user with a role of its own.
+func createUser(t *testing.T, db *sql.DB, username string) int {
+	nu := user.NewUser{
+		RoleID:       createRole(t, db, username+"_role"),
+		Username:     username,
+		Email:        username + "@example.com",
+		PasswordHash: "hash",
+	}
+
+	var u user.User
+	if err := NewUserRepository(db).Create(context.Background(), &nu, &u); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	return u.ID
+}
+
+// createCategory inserts a category the articles of the test refer to.
+func createCategory(t *testing.T, db *sql.DB, name string) int {
+	var cat category.Category
+	if err := NewCategoryRepository(db).Create(context.Background(), &category.NewCategory{Name: name}, &cat); err != nil {
+		t.Fatalf("create category: %v", err)
+	}
+
+	return cat.ID
+}
@@ -0,0 +1,164 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/dipress/crmifc/internal/auth"
+	"github.com/pkg/errors"
+)
+
+// MFARepository holds totp secrets and recovery codes.
+type MFARepository struct {
+	db *database
+}
+
+// NewMFARepository factory prepares the repository to work.
+func NewMFARepository(db *sql.DB) *MFARepository {
+	r := MFARepository{
+		db: newDatabase(db),
+	}
+
+	return &r
+}
+
+const findMFAQuery = `SELECT user_id, secret, enabled, last_counter FROM mfa WHERE user_id = $1`
+
+// FindMFA finds the mfa of the user.
+func (r *MFARepository) FindMFA(ctx context.Context, userID int) (*auth.MFA, error) {
+	var m auth.MFA
+	if err := r.db.QueryRowContext(ctx, findMFAQuery, userID).
+		Scan(&m.UserID, &m.Secret, &m.Enabled, &m.LastCounter); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, auth.ErrMFANotFound
+		}
+		return nil, errors.Wrap(err, "query row scan")
+	}
+	return &m, nil
+}
+
+const saveMFASecretQuery = `
+	INSERT INTO mfa (user_id, secret) VALUES ($1, $2)
+	ON CONFLICT (user_id) DO UPDATE SET secret = EXCLUDED.secret, last_counter = 0, created_at = now()
+	WHERE mfa.enabled = false`
+
+// SaveMFASecret saves the secret of the user which isn't enabled
+// yet, the previous one is replaced unless it is enabled.
+func (r *MFARepository) SaveMFASecret(ctx context.Context, userID int, secret string) error {
+	res, err := r.db.ExecContext(ctx, saveMFASecretQuery, userID, secret)
+	if err != nil {
+		return errors.Wrap(err, "exec context")
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "rows affected")
+	}
+
+	if affected == 0 {
+		return auth.ErrMFAEnabled
+	}
+	return nil
+}
+
+const (
+	enableMFAQuery           = `UPDATE mfa SET enabled = true, last_counter = $2 WHERE user_id = $1 AND enabled = false`
+	deleteRecoveryCodesQuery = `DELETE FROM mfa_recovery_codes WHERE user_id = $1`
+	createRecoveryCodeQuery  = `INSERT INTO mfa_recovery_codes (user_id, code_hash) VALUES ($1, $2)`
+	disableMFAQuery          = `DELETE FROM mfa WHERE user_id = $1`
+	useMFACounterQuery       = `UPDATE mfa SET last_counter = $2 WHERE user_id = $1 AND enabled AND last_counter < $2`
+	useRecoveryCodeQuery     = `UPDATE mfa_recovery_codes SET used_at = now() WHERE id = (SELECT id FROM mfa_recovery_codes WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL LIMIT 1)`
+)
+
+// EnableMFA enables the secret of the user with the counter of the
+// first code and replaces the recovery codes by given hashes.
+func (r *MFARepository) EnableMFA(ctx context.Context, userID int, counter int64, codeHashes []string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "begin tx")
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, enableMFAQuery, userID, counter)
+	if err != nil {
+		return errors.Wrap(err, "enable mfa")
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "rows affected")
+	}
+
+	if affected == 0 {
+		return auth.ErrMFAEnabled
+	}
+
+	if _, err := tx.ExecContext(ctx, deleteRecoveryCodesQuery, userID); err != nil {
+		return errors.Wrap(err, "delete recovery codes")
+	}
+
+	for _, hash := range codeHashes {
+		if _, err := tx.ExecContext(ctx, createRecoveryCodeQuery, userID, hash); err != nil {
+			return errors.Wrap(err, "create recovery code")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "commit")
+	}
+
+	return nil
+}
+
+// UseMFACounter saves the counter of the accepted code. The counter
+// which isn't newer than the saved one means the code is replayed.
+func (r *MFARepository) UseMFACounter(ctx context.Context, userID int, counter int64) error {
+	return r.useOnce(ctx, useMFACounterQuery, userID, counter)
+}
+
+// UseRecoveryCode marks the recovery code of the user as used.
+func (r *MFARepository) UseRecoveryCode(ctx context.Context, userID int, hash string) error {
+	return r.useOnce(ctx, useRecoveryCodeQuery, userID, hash)
+}
+
+// useOnce executes the query which updates a single row
+// and returns ErrInvalidCode when nothing is updated.
+func (r *MFARepository) useOnce(ctx context.Context, query string, args ...interface{}) error {
+	res, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return errors.Wrap(err, "exec context")
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "rows affected")
+	}
+
+	if affected == 0 {
+		return auth.ErrInvalidCode
+	}
+	return nil
+}
+
+// DisableMFA deletes the secret and the recovery codes of the user.
+func (r *MFARepository) DisableMFA(ctx context.Context, userID int) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "begin tx")
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, deleteRecoveryCodesQuery, userID); err != nil {
+		return errors.Wrap(err, "delete recovery codes")
+	}
+
+	if _, err := tx.ExecContext(ctx, disableMFAQuery, userID); err != nil {
+		return errors.Wrap(err, "delete mfa")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "commit")
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dipress/crmifc/internal/auth"
+	"github.com/dipress/crmifc/internal/user"
+)
+
+func TestMFA(t *testing.T) {
+	t.Log("with initialized repository")
+	{
+		db, teardown := postgresDB(t)
+		defer teardown()
+
+		r := NewMFARepository(db)
+		userRepo := NewUserRepository(db)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		nu := user.NewUser{
+			RoleID:       createRole(t, db, "Member"),
+			Username:     "username_mfa",
+			Email:        "username_mfa@example.com",
+			PasswordHash: "hash",
+		}
+
+		var u user.User
+		if err := userRepo.Create(ctx, &nu, &u); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		hash := "2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"
+
+		t.Log("\ttest:0\tshould not find the mfa of the user without secret")
+		{
+			if _, err := r.FindMFA(ctx, u.ID); err != auth.ErrMFANotFound {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}
+
+		t.Log("\ttest:1\tshould replace the secret until it is enabled")
+		{
+			if err := r.SaveMFASecret(ctx, u.ID, "FIRST"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if err := r.SaveMFASecret(ctx, u.ID, "SECOND"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if err := r.EnableMFA(ctx, u.ID, 10, []string{hash}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			m, err := r.FindMFA(ctx, u.ID)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if m.Secret != "SECOND" || !m.Enabled || m.LastCounter != 10 {
+				t.Errorf("unexpected mfa: %+v", m)
+			}
+
+			if err := r.SaveMFASecret(ctx, u.ID, "THIRD"); err != auth.ErrMFAEnabled {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}
+
+		t.Log("\ttest:2\tshould use the counter and the recovery code once")
+		{
+			if err := r.UseMFACounter(ctx, u.ID, 10); err != auth.ErrInvalidCode {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if err := r.UseMFACounter(ctx, u.ID, 11); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if err := r.UseRecoveryCode(ctx, u.ID, hash); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if err := r.UseRecoveryCode(ctx, u.ID, hash); err != auth.ErrInvalidCode {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}
+
+		t.Log("\ttest:3\tshould disable the mfa")
+		{
+			if err := r.DisableMFA(ctx, u.ID); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if _, err := r.FindMFA(ctx, u.ID); err != auth.ErrMFANotFound {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/dipress/crmifc/internal/auth"
+	"github.com/pkg/errors"
+)
+
+// OIDCStateRepository holds the single sign-on
+// logins which wait for the callback.
+type OIDCStateRepository struct {
+	db *database
+}
+
+// NewOIDCStateRepository factory prepares the repository to work.
+func NewOIDCStateRepository(db *sql.DB) *OIDCStateRepository {
+	r := OIDCStateRepository{
+		db: newDatabase(db),
+	}
+
+	return &r
+}
+
+const (
+	saveOIDCStateQuery           = `INSERT INTO oidc_states (state_hash, nonce, verifier, expires_at) VALUES ($1, $2, $3, $4)`
+	deleteExpiredOIDCStatesQuery = `DELETE FROM oidc_states WHERE expires_at < now() AT TIME ZONE 'UTC'`
+)
+
+// SaveOIDCState inserts the state by its hash. The expired
+// states which were never used are deleted on the way.
+// Expiration times are stored in UTC.
+func (r *OIDCStateRepository) SaveOIDCState(ctx context.Context, hash string, s *auth.OIDCState) error {
+	if _, err := r.db.ExecContext(ctx, deleteExpiredOIDCStatesQuery); err != nil {
+		return errors.Wrap(err, "delete expired")
+	}
+
+	if _, err := r.db.ExecContext(ctx, saveOIDCStateQuery, hash, s.Nonce, s.Verifier, s.ExpiresAt.UTC()); err != nil {
+		return errors.Wrap(err, "exec context")
+	}
+	return nil
+}
+
+const useOIDCStateQuery = `DELETE FROM oidc_states WHERE state_hash = $1 RETURNING nonce, verifier, expires_at`
+
+// UseOIDCState deletes the state by the hash and returns
+// it, so each state works only once.
+func (r *OIDCStateRepository) UseOIDCState(ctx context.Context, hash string) (*auth.OIDCState, error) {
+	var s auth.OIDCState
+	if err := r.db.QueryRowContext(ctx, useOIDCStateQuery, hash).Scan(&s.Nonce, &s.Verifier, &s.ExpiresAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, auth.ErrInvalidOIDCState
+		}
+		return nil, errors.Wrap(err, "query row scan")
+	}
+	return &s, nil
+}
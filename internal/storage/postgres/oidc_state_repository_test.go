@@ -0,0 +1,50 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dipress/crmifc/internal/auth"
+)
+
+func TestOIDCState(t *testing.T) {
+	t.Log("with initialized repository")
+	{
+		db, teardown := postgresDB(t)
+		defer teardown()
+
+		r := NewOIDCStateRepository(db)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		hash := "2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"
+
+		t.Log("\ttest:0\tshould use the state once")
+		{
+			s := auth.OIDCState{
+				Nonce:     "nonce",
+				Verifier:  "verifier",
+				ExpiresAt: time.Now().Add(time.Minute),
+			}
+
+			if err := r.SaveOIDCState(ctx, hash, &s); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			used, err := r.UseOIDCState(ctx, hash)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if used.Nonce != s.Nonce || used.Verifier != s.Verifier || !used.ExpiresAt.After(time.Now()) {
+				t.Errorf("unexpected state: %+v", used)
+			}
+
+			if _, err := r.UseOIDCState(ctx, hash); err != auth.ErrInvalidOIDCState {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}
+	}
+}
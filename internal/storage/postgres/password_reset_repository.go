@@ -0,0 +1,121 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/dipress/crmifc/internal/user"
+	"github.com/pkg/errors"
+)
+
+// PasswordResetRepository holds password reset tokens.
+type PasswordResetRepository struct {
+	db *database
+}
+
+// NewPasswordResetRepository factory prepares the repository to work.
+func NewPasswordResetRepository(db *sql.DB) *PasswordResetRepository {
+	r := PasswordResetRepository{
+		db: newDatabase(db),
+	}
+
+	return &r
+}
+
+const createPasswordResetQuery = `
+	INSERT INTO password_resets (user_id, token_hash, expires_at)
+	SELECT id, $2, $3 FROM users WHERE lower(email) = lower($1) AND deleted_at IS NULL AND disabled_at IS NULL`
+
+// CreatePasswordReset inserts the hash of a new reset token of the user
+// with given email. Deleted and deactivated users aren't found.
+func (r *PasswordResetRepository) CreatePasswordReset(ctx context.Context, email, hash string, expiresAt time.Time) error {
+	res, err := r.db.ExecContext(ctx, createPasswordResetQuery, email, hash, expiresAt.UTC())
+	if err != nil {
+		return errors.Wrap(err, "exec context")
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "rows affected")
+	}
+
+	if affected == 0 {
+		return user.ErrNotFound
+	}
+	return nil
+}
+
+const findResetUserQuery = `
+	SELECT users.id, users.username, users.email
+	FROM password_resets JOIN users ON users.id = password_resets.user_id
+	WHERE password_resets.token_hash = $1 AND password_resets.used_at IS NULL
+	AND password_resets.expires_at > now() AT TIME ZONE 'UTC'`
+
+// FindResetUser finds the user of the reset token hash which
+// isn't used or expired yet.
+func (r *PasswordResetRepository) FindResetUser(ctx context.Context, hash string) (*user.User, error) {
+	var u user.User
+	if err := r.db.QueryRowContext(ctx, findResetUserQuery, hash).Scan(&u.ID, &u.Username, &u.Email); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, user.ErrInvalidResetToken
+		}
+		return nil, errors.Wrap(err, "query row scan")
+	}
+
+	return &u, nil
+}
+
+const (
+	usePasswordResetQuery = `
+		UPDATE password_resets SET used_at = now()
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > now() AT TIME ZONE 'UTC'
+		RETURNING user_id`
+	resetPasswordQuery = `
+		UPDATE users SET password_hash = $2, token_version = token_version + 1, version = version + 1, updated_at = now()
+		WHERE id = $1`
+	useUserPasswordResetsQuery    = `UPDATE password_resets SET used_at = now() WHERE user_id = $1 AND used_at IS NULL`
+	revokeResetRefreshTokensQuery = `UPDATE refresh_tokens SET revoked_at = now() WHERE user_id = $1 AND revoked_at IS NULL`
+	revokeResetSessionsQuery      = `UPDATE sessions SET revoked_at = now() WHERE user_id = $1 AND revoked_at IS NULL`
+)
+
+// ResetPassword sets the password of the user by the reset token hash.
+// The token is used up with other tokens of the user, the refresh tokens
+// and the sessions are revoked and the access tokens become outdated.
+func (r *PasswordResetRepository) ResetPassword(ctx context.Context, hash, passwordHash string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "begin tx")
+	}
+	defer tx.Rollback()
+
+	var userID int
+	if err := tx.QueryRowContext(ctx, usePasswordResetQuery, hash).Scan(&userID); err != nil {
+		if err == sql.ErrNoRows {
+			return user.ErrInvalidResetToken
+		}
+		return errors.Wrap(err, "use password reset")
+	}
+
+	if _, err := tx.ExecContext(ctx, resetPasswordQuery, userID, passwordHash); err != nil {
+		return errors.Wrap(err, "reset password")
+	}
+
+	if _, err := tx.ExecContext(ctx, useUserPasswordResetsQuery, userID); err != nil {
+		return errors.Wrap(err, "use password resets")
+	}
+
+	if _, err := tx.ExecContext(ctx, revokeResetRefreshTokensQuery, userID); err != nil {
+		return errors.Wrap(err, "revoke refresh tokens")
+	}
+
+	if _, err := tx.ExecContext(ctx, revokeResetSessionsQuery, userID); err != nil {
+		return errors.Wrap(err, "revoke sessions")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "commit")
+	}
+
+	return nil
+}
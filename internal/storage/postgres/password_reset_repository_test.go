@@ -0,0 +1,113 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dipress/crmifc/internal/session"
+	"github.com/dipress/crmifc/internal/user"
+)
+
+func TestPasswordReset(t *testing.T) {
+	t.Log("with initialized repository")
+	{
+		db, teardown := postgresDB(t)
+		defer teardown()
+
+		r := NewPasswordResetRepository(db)
+		userRepo := NewUserRepository(db)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		nu := user.NewUser{
+			RoleID:       createRole(t, db, "Member"),
+			Username:     "username_reset",
+			Email:        "username_reset@example.com",
+			PasswordHash: "old hash",
+		}
+
+		var u user.User
+		if err := userRepo.Create(ctx, &nu, &u); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		hash := "2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"
+
+		t.Log("\ttest:0\tshould not create the reset of unknown email")
+		{
+			if err := r.CreatePasswordReset(ctx, "unknown@example.com", hash, time.Now().Add(time.Hour)); err != user.ErrNotFound {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}
+
+		t.Log("\ttest:1\tshould reset the password by the token once")
+		{
+			if err := r.CreatePasswordReset(ctx, nu.Email, hash, time.Now().Add(time.Hour)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			sessions := NewSessionRepository(db)
+			ns := session.NewSession{UserID: u.ID, ExpiresAt: time.Now().Add(time.Hour)}
+			if err := sessions.CreateSession(ctx, &ns, &session.Session{}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			reset, err := r.FindResetUser(ctx, hash)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if reset.ID != u.ID || reset.Username != nu.Username || reset.Email != nu.Email {
+				t.Errorf("unexpected user: %+v", reset)
+			}
+
+			if err := r.ResetPassword(ctx, hash, "new hash"); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			found, err := userRepo.FindByEmail(ctx, nu.Email)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if found.PasswordHash != "new hash" || found.TokenVersion != 2 {
+				t.Errorf("unexpected user: %+v", found)
+			}
+
+			var active int
+			if err := db.QueryRow(`SELECT COUNT(*) FROM sessions WHERE user_id = $1 AND revoked_at IS NULL`, u.ID).Scan(&active); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if active != 0 {
+				t.Errorf("unexpected active sessions: %d", active)
+			}
+
+			if err := r.ResetPassword(ctx, hash, "other hash"); err != user.ErrInvalidResetToken {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if _, err := r.FindResetUser(ctx, hash); err != user.ErrInvalidResetToken {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}
+
+		t.Log("\ttest:2\tshould not reset the password by the expired token")
+		{
+			expired := "fcde2b2edba56bf408601fb721fe9b5c338d10ee429ea04fae5511b68fbf8fb9"
+			if err := r.CreatePasswordReset(ctx, nu.Email, expired, time.Now().Add(-time.Minute)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if _, err := r.FindResetUser(ctx, expired); err != user.ErrInvalidResetToken {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if err := r.ResetPassword(ctx, expired, "other hash"); err != user.ErrInvalidResetToken {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}
+	}
+}
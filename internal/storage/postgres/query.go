@@ -0,0 +1,178 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dipress/crmifc/internal/kit/query"
+	"github.com/pkg/errors"
+)
+
+// listing describes a list query of the table and
+// the fields which are allowed to sort and filter by.
+// The scope is the condition all listed rows match.
+type listing struct {
+	columns string
+	from    string
+	id      string
+	scope   string
+	sort    map[string]string
+	filter  map[string]column
+}
+
+// columnType is the type the filter value is parsed into.
+type columnType int
+
+const (
+	textColumn columnType = iota
+	intColumn
+	boolColumn
+)
+
+// column is the filtered column of the given type.
+type column struct {
+	name string
+	typ  columnType
+}
+
+// parse converts the filter value to the type of the column,
+// query.ErrInvalidFilter is returned when it doesn't fit.
+func (c column) parse(value string) (interface{}, error) {
+	switch c.typ {
+	case intColumn:
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, query.ErrInvalidFilter
+		}
+		return v, nil
+	case boolColumn:
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, query.ErrInvalidFilter
+		}
+		return v, nil
+	default:
+		return value, nil
+	}
+}
+
+// withScope returns the copy of the listing with given scope.
+func (l listing) withScope(scope string) listing {
+	l.scope = scope
+	return l
+}
+
+// build turns q into a parameterized keyset query. The sort column is
+// selected as the last text column so the cursor of the next page can be
+// built from the last row. One extra row is requested to find out whether
+// the next page exists.
+func (l listing) build(q *query.Query) (string, []interface{}, error) {
+	q.Normalize()
+	if err := q.Validate(); err != nil {
+		return "", nil, err
+	}
+
+	sortCol, ok := l.sort[q.Sort]
+	if !ok {
+		return "", nil, query.ErrInvalidSort
+	}
+
+	var (
+		where []string
+		args  []interface{}
+	)
+
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if l.scope != "" {
+		where = append(where, l.scope)
+	}
+
+	for field, value := range q.Filters {
+		col, ok := l.filter[field]
+		if !ok {
+			return "", nil, query.ErrInvalidFilter
+		}
+
+		v, err := col.parse(value)
+		if err != nil {
+			return "", nil, err
+		}
+		where = append(where, fmt.Sprintf("%s = %s", col.name, arg(v)))
+	}
+
+	op := ">"
+	if q.Direction == query.Desc {
+		op = "<"
+	}
+
+	if q.Cursor != "" {
+		c, err := query.DecodeCursor(q)
+		if err != nil {
+			return "", nil, err
+		}
+		where = append(where, fmt.Sprintf("(%s, %s) %s (%s, %s)", sortCol, l.id, op, arg(c.Value), arg(c.ID)))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "SELECT %s, %s::text FROM %s", l.columns, sortCol, l.from)
+	if len(where) > 0 {
+		fmt.Fprintf(&b, " WHERE %s", strings.Join(where, " AND "))
+	}
+	fmt.Fprintf(&b, " ORDER BY %s %s, %s %s", sortCol, q.Direction, l.id, q.Direction)
+	fmt.Fprintf(&b, " LIMIT %s", arg(q.Limit+1))
+
+	return b.String(), args, nil
+}
+
+// nextCursor returns the cursor pointing to the item
+// with given sort value and id.
+func nextCursor(q *query.Query, value string, id int) string {
+	c := query.Cursor{
+		Sort:      q.Sort,
+		Direction: q.Direction,
+		Value:     value,
+		ID:        id,
+	}
+
+	return c.Encode()
+}
+
+// execOne executes the statement which has to affect a row,
+// notFound is returned when no rows are affected.
+func execOne(ctx context.Context, db executor, notFound error, stmt string, args ...interface{}) error {
+	res, err := db.ExecContext(ctx, stmt, args...)
+	if err != nil {
+		return errors.Wrap(err, "exec context")
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "rows affected")
+	}
+
+	if n == 0 {
+		return notFound
+	}
+	return nil
+}
+
+// staleOrNotFound tells why the update of the version affected no
+// rows by the exists statement: notFound is returned when the row
+// doesn't exist, mismatch when its version is another one.
+func staleOrNotFound(ctx context.Context, db executor, notFound, mismatch error, exists string, id int) error {
+	var ok bool
+	if err := db.QueryRowContext(ctx, exists, id).Scan(&ok); err != nil {
+		return errors.Wrap(err, "query row scan")
+	}
+
+	if !ok {
+		return notFound
+	}
+	return mismatch
+}
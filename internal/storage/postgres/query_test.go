@@ -0,0 +1,128 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/dipress/crmifc/internal/kit/query"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListingBuild(t *testing.T) {
+	l := listing{
+		columns: "id, name",
+		from:    "things",
+		id:      "id",
+		sort: map[string]string{
+			"id":   "id",
+			"name": "name",
+		},
+		filter: map[string]column{
+			"name":    {name: "name", typ: textColumn},
+			"user_id": {name: "user_id", typ: intColumn},
+			"active":  {name: "active", typ: boolColumn},
+		},
+	}
+
+	cursor := query.Cursor{Sort: "name", Direction: query.Desc, Value: "b", ID: 3}
+
+	tests := []struct {
+		name       string
+		query      query.Query
+		wantErr    error
+		expect     string
+		expectArgs []interface{}
+	}{
+		{
+			name:       "defaults",
+			query:      query.Query{},
+			expect:     "SELECT id, name, id::text FROM things ORDER BY id asc, id asc LIMIT $1",
+			expectArgs: []interface{}{query.DefaultLimit + 1},
+		},
+		{
+			name: "filter and cursor",
+			query: query.Query{
+				Limit:     10,
+				Cursor:    cursor.Encode(),
+				Sort:      "name",
+				Direction: query.Desc,
+				Filters:   map[string]string{"name": "a"},
+			},
+			expect:     "SELECT id, name, name::text FROM things WHERE name = $1 AND (name, id) < ($2, $3) ORDER BY name desc, id desc LIMIT $4",
+			expectArgs: []interface{}{"a", "b", 3, 11},
+		},
+		{
+			name:    "unknown sort",
+			query:   query.Query{Sort: "password"},
+			wantErr: query.ErrInvalidSort,
+		},
+		{
+			name:    "unknown filter",
+			query:   query.Query{Filters: map[string]string{"password": "secret"}},
+			wantErr: query.ErrInvalidFilter,
+		},
+		{
+			name:       "typed filters",
+			query:      query.Query{Filters: map[string]string{"user_id": "7"}},
+			expect:     "SELECT id, name, id::text FROM things WHERE user_id = $1 ORDER BY id asc, id asc LIMIT $2",
+			expectArgs: []interface{}{7, query.DefaultLimit + 1},
+		},
+		{
+			name:    "not numeric filter",
+			query:   query.Query{Filters: map[string]string{"user_id": "abc"}},
+			wantErr: query.ErrInvalidFilter,
+		},
+		{
+			name:    "not boolean filter",
+			query:   query.Query{Filters: map[string]string{"active": "abc"}},
+			wantErr: query.ErrInvalidFilter,
+		},
+		{
+			name:    "cursor of another sort",
+			query:   query.Query{Cursor: cursor.Encode(), Sort: "id", Direction: query.Desc},
+			wantErr: query.ErrInvalidCursor,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, args, err := l.build(&tc.query)
+			if tc.wantErr != nil {
+				assert.Equal(t, tc.wantErr, err)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expect, got)
+			assert.Equal(t, tc.expectArgs, args)
+		})
+	}
+}
+
+func TestListingScope(t *testing.T) {
+	l := listing{
+		columns: "id, name",
+		from:    "things",
+		id:      "id",
+		scope:   "deleted_at IS NULL",
+		sort: map[string]string{
+			"id": "id",
+		},
+		filter: map[string]column{
+			"name": {name: "name", typ: textColumn},
+		},
+	}
+
+	got, args, err := l.build(&query.Query{Filters: map[string]string{"name": "a"}})
+	assert.Nil(t, err)
+	assert.Equal(t, "SELECT id, name, id::text FROM things WHERE deleted_at IS NULL AND name = $1 ORDER BY id asc, id asc LIMIT $2", got)
+	assert.Equal(t, []interface{}{"a", query.DefaultLimit + 1}, args)
+
+	deleted := l.withScope("deleted_at IS NOT NULL")
+	got, _, err = deleted.build(&query.Query{})
+	assert.Nil(t, err)
+	assert.Equal(t, "SELECT id, name, id::text FROM things WHERE deleted_at IS NOT NULL ORDER BY id asc, id asc LIMIT $1", got)
+	assert.Equal(t, "deleted_at IS NULL", l.scope)
+}
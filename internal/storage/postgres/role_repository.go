@@ -4,113 +4,233 @@ import (
 	"context"
 	"database/sql"
 
+	"github.com/dipress/crmifc/internal/kit/query"
 	"github.com/dipress/crmifc/internal/role"
-	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/pkg/errors"
 )
 
 // RoleRepository holds CRUD actions.
 type RoleRepository struct {
-	db *sqlx.DB
+	db *database
 }
 
 //NewRoleRepository factory prepares the repository to work.
 func NewRoleRepository(db *sql.DB) *RoleRepository {
 	r := RoleRepository{
-		db: sqlx.NewDb(db, driverName),
+		db: newDatabase(db),
 	}
 
 	return &r
 }
 
-const createRoleQuery = `INSERT INTO roles (name) VALUES ($1) RETURNING id, name, created_at, updated_at`
+var (
+	roleViolations = violations{
+		"roles_name_key": role.ErrNameExists,
+	}
+	deleteRoleViolations = violations{
+		"users_role_id_fkey": role.ErrInUse,
+	}
+)
+
+const createRoleQuery = `INSERT INTO roles (name, mfa_required) VALUES ($1, $2) RETURNING id, name, mfa_required, created_at, updated_at, version`
 
 // Create insert a new role into the database.
 func (r *RoleRepository) Create(ctx context.Context, f *role.NewRole, rol *role.Role) error {
-	if err := r.db.QueryRowContext(ctx, createRoleQuery, f.Name).
-		Scan(&rol.ID, &rol.Name, &rol.CreatedAt, &rol.UpdatedAt); err != nil {
-		return errors.Wrap(err, "query context scan")
+	if err := r.db.QueryRowContext(ctx, createRoleQuery, f.Name, f.MFARequired).
+		Scan(&rol.ID, &rol.Name, &rol.MFARequired, &rol.CreatedAt, &rol.UpdatedAt, &rol.Version); err != nil {
+		return errors.Wrap(roleViolations.translate(err), "query context scan")
 	}
+	rol.Permissions = toPermissions(nil)
 	return nil
 }
 
-const findRoleQuery = `SELECT id, name, created_at, updated_at FROM roles where id = $1`
+// rolePermissionsColumn selects permissions granted to the role.
+const rolePermissionsColumn = `ARRAY(SELECT permission FROM role_permissions WHERE role_permissions.role_id = roles.id ORDER BY permission)`
+
+const findRoleQuery = `SELECT id, name, ` + rolePermissionsColumn + `, mfa_required, created_at, updated_at, version FROM roles where id = $1`
 
 // Find finds a role by id.
 func (r *RoleRepository) Find(ctx context.Context, id int) (*role.Role, error) {
-	var rol role.Role
+	var (
+		rol         role.Role
+		permissions []string
+	)
 	if err := r.db.QueryRowContext(ctx, findRoleQuery, id).
-		Scan(&rol.ID, &rol.Name, &rol.CreatedAt, &rol.UpdatedAt); err != nil {
+		Scan(&rol.ID, &rol.Name, pq.Array(&permissions), &rol.MFARequired, &rol.CreatedAt, &rol.UpdatedAt, &rol.Version); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, role.ErrNotFound
 		}
 		return nil, errors.Wrap(err, "query row scan")
 	}
+	rol.Permissions = toPermissions(permissions)
 	return &rol, nil
 }
 
-const updateRoleQuery = `UPDATE roles SET name=:name, updated_at=now() WHERE id=:id`
-
-// Update updates role by id.
-func (r *RoleRepository) Update(ctx context.Context, id int, rl *role.Role) error {
-
-	stmt, err := r.db.PrepareNamed(updateRoleQuery)
-	if err != nil {
-		return errors.Wrap(err, "prepare named")
-	}
-	defer stmt.Close()
+const findRoleByNameQuery = `SELECT id, name, ` + rolePermissionsColumn + `, mfa_required, created_at, updated_at, version FROM roles where name = $1`
 
-	if _, err := stmt.ExecContext(ctx, map[string]interface{}{
-		"id":   id,
-		"name": rl.Name,
-	}); err != nil {
+// FindByName finds a role by name.
+func (r *RoleRepository) FindByName(ctx context.Context, name string) (*role.Role, error) {
+	var (
+		rol         role.Role
+		permissions []string
+	)
+	if err := r.db.QueryRowContext(ctx, findRoleByNameQuery, name).
+		Scan(&rol.ID, &rol.Name, pq.Array(&permissions), &rol.MFARequired, &rol.CreatedAt, &rol.UpdatedAt, &rol.Version); err != nil {
 		if err == sql.ErrNoRows {
-			return role.ErrNotFound
+			return nil, role.ErrNotFound
 		}
-		return errors.Wrap(err, "exec context")
+		return nil, errors.Wrap(err, "query row scan")
 	}
-	return nil
+	rol.Permissions = toPermissions(permissions)
+	return &rol, nil
 }
 
-const deleteRoleQuery = `DELETE FROM roles WHERE id=:id`
+const (
+	updateRoleQuery = `
+	WITH updated AS (
+		UPDATE roles SET name=:name, mfa_required=:mfa_required, version=version+1, updated_at=now()
+		WHERE id=:id AND version=:version
+		RETURNING id, version, updated_at
+	), outdated AS (
+		UPDATE users SET token_version = token_version + 1
+		WHERE role_id IN (SELECT id FROM updated)
+	)
+	SELECT version, updated_at FROM updated`
+	roleExistsQuery = `SELECT EXISTS(SELECT 1 FROM roles WHERE id=$1)`
+)
 
-// Delete deletes role by id.
-func (r *RoleRepository) Delete(ctx context.Context, id int) error {
-	stmt, err := r.db.PrepareNamed(deleteRoleQuery)
+// Update updates role by id when its version is still the version
+// of the role, the version is incremented then. Tokens of the role
+// users become outdated.
+func (r *RoleRepository) Update(ctx context.Context, id int, rl *role.Role) error {
+	stmt, err := r.db.PrepareNamedContext(ctx, updateRoleQuery)
 	if err != nil {
 		return errors.Wrap(err, "prepare named")
 	}
 	defer stmt.Close()
 
-	if _, err := stmt.ExecContext(ctx, map[string]interface{}{
-		"id": id,
-	}); err != nil {
+	if err := stmt.QueryRowxContext(ctx, map[string]interface{}{
+		"id":           id,
+		"name":         rl.Name,
+		"mfa_required": rl.MFARequired,
+		"version":      rl.Version,
+	}).Scan(&rl.Version, &rl.UpdatedAt); err != nil {
 		if err == sql.ErrNoRows {
-			return role.ErrNotFound
+			return staleOrNotFound(ctx, r.db, role.ErrNotFound, role.ErrVersionMismatch, roleExistsQuery, id)
 		}
-		return errors.Wrap(err, "exec context")
+		return errors.Wrap(roleViolations.translate(err), "query row scan")
 	}
 	return nil
 }
 
-const listRoleQuery = `SELECT * FROM roles`
+const deleteRoleQuery = `DELETE FROM roles WHERE id=$1`
+
+// Delete deletes role by id. The role which
+// users have, deleted ones included, isn't deleted.
+func (r *RoleRepository) Delete(ctx context.Context, id int) error {
+	return deleteRoleViolations.translate(execOne(ctx, r.db, role.ErrNotFound, deleteRoleQuery, id))
+}
+
+var listRoles = listing{
+	columns: "roles.id, roles.name, " + rolePermissionsColumn + ", roles.mfa_required, roles.created_at, roles.updated_at, roles.version",
+	from:    "roles",
+	id:      "roles.id",
+	sort: map[string]string{
+		"id":         "roles.id",
+		"name":       "roles.name",
+		"created_at": "roles.created_at",
+		"updated_at": "roles.updated_at",
+	},
+	filter: map[string]column{
+		"name": {name: "roles.name", typ: textColumn},
+	},
+}
+
+// List shows roles page by given query.
+func (r *RoleRepository) List(ctx context.Context, q *query.Query, roles *role.Roles) error {
+	listQuery, args, err := listRoles.build(q)
+	if err != nil {
+		return errors.Wrap(err, "build list query")
+	}
 
-// List shows all roles.
-func (r *RoleRepository) List(ctx context.Context, roles *role.Roles) error {
-	rows, err := r.db.QueryxContext(ctx, listRoleQuery)
+	rows, err := r.db.QueryxContext(ctx, listQuery, args...)
 	if err != nil {
 		return errors.Wrap(err, "query rows")
 	}
 	defer rows.Close()
 
+	var values []string
 	for rows.Next() {
-		var rl role.Role
-		if err := rows.Scan(&rl.ID, &rl.Name, &rl.CreatedAt, &rl.UpdatedAt); err != nil {
+		var (
+			rl          role.Role
+			permissions []string
+			value       string
+		)
+		if err := rows.Scan(&rl.ID, &rl.Name, pq.Array(&permissions), &rl.MFARequired, &rl.CreatedAt, &rl.UpdatedAt, &rl.Version, &value); err != nil {
 			return errors.Wrap(err, "roles query row scan on loop")
 		}
+		rl.Permissions = toPermissions(permissions)
 
 		roles.Roles = append(roles.Roles, rl)
+		values = append(values, value)
+	}
+
+	if err := rows.Err(); err != nil {
+		return errors.Wrap(err, "roles rows")
+	}
+
+	if len(values) > q.Limit {
+		last := q.Limit - 1
+		roles.Roles = roles.Roles[:q.Limit]
+		roles.NextCursor = nextCursor(q, values[last], roles.Roles[last].ID)
 	}
 
 	return nil
 }
+
+const grantPermissionQuery = `
+	WITH granted AS (
+		INSERT INTO role_permissions (role_id, permission) VALUES ($1, $2)
+		ON CONFLICT DO NOTHING RETURNING role_id
+	), bumped AS (
+		UPDATE roles SET version = version + 1 WHERE id IN (SELECT role_id FROM granted)
+	)
+	UPDATE users SET token_version = token_version + 1
+	WHERE role_id IN (SELECT role_id FROM granted)`
+
+// Grant grants the permission to the role, increments its version
+// and makes tokens of the role users outdated. Granting of the already granted permission
+// does nothing.
+func (r *RoleRepository) Grant(ctx context.Context, id int, p role.Permission) error {
+	if _, err := r.db.ExecContext(ctx, grantPermissionQuery, id, string(p)); err != nil {
+		return errors.Wrap(err, "exec context")
+	}
+	return nil
+}
+
+const revokePermissionQuery = `
+	WITH revoked AS (
+		DELETE FROM role_permissions WHERE role_id = $1 AND permission = $2 RETURNING role_id
+	), bumped AS (
+		UPDATE roles SET version = version + 1 WHERE id IN (SELECT role_id FROM revoked)
+	)
+	UPDATE users SET token_version = token_version + 1
+	WHERE role_id IN (SELECT role_id FROM revoked)`
+
+// Revoke revokes the permission from the role, increments
+// its version and makes tokens of the role users outdated.
+func (r *RoleRepository) Revoke(ctx context.Context, id int, p role.Permission) error {
+	if _, err := r.db.ExecContext(ctx, revokePermissionQuery, id, string(p)); err != nil {
+		return errors.Wrap(err, "exec context")
+	}
+	return nil
+}
+
+func toPermissions(names []string) []role.Permission {
+	permissions := make([]role.Permission, 0, len(names))
+	for _, name := range names {
+		permissions = append(permissions, role.Permission(name))
+	}
+	return permissions
+}
@@ -4,7 +4,10 @@ import (
 	"context"
 	"testing"
 
+	"github.com/dipress/crmifc/internal/kit/query"
 	"github.com/dipress/crmifc/internal/role"
+	"github.com/dipress/crmifc/internal/user"
+	"github.com/pkg/errors"
 )
 
 func TestRoleCreate(t *testing.T) {
@@ -35,6 +38,15 @@ func TestRoleCreate(t *testing.T) {
 				t.Error("expected to parse returned id")
 			}
 		}
+
+		t.Log("\ttest:1\tshould return error on duplicate name")
+		{
+			var dup role.Role
+			err := r.Create(ctx, &role.NewRole{Name: "Admin"}, &dup)
+			if errors.Cause(err) != role.ErrNameExists {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}
 	}
 
 }
@@ -67,6 +79,20 @@ func TestRoleFind(t *testing.T) {
 				t.Errorf("unexpected error: %v", err)
 			}
 		}
+
+		t.Log("\ttest:1\tshould find the role by name")
+		{
+			found, err := r.FindByName(ctx, "Manager")
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			} else if found.ID != rol.ID {
+				t.Errorf("unexpected role: %+v", found)
+			}
+
+			if _, err := r.FindByName(ctx, "Unknown"); err != role.ErrNotFound {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}
 	}
 }
 
@@ -124,10 +150,33 @@ func TestRoleDelete(t *testing.T) {
 		}
 		t.Log("\ttest:0\tshould delete the role into the database")
 		{
-			err := r.Delete(ctx, 1)
+			err := r.Delete(ctx, rol.ID)
 			if err != nil {
 				t.Errorf("unexpected error: %v", err)
 			}
+
+			if err := r.Delete(ctx, rol.ID); err != role.ErrNotFound {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}
+
+		t.Log("\ttest:1\tshould not delete the role of users")
+		{
+			nu := user.NewUser{
+				RoleID:       createRole(t, db, "Member"),
+				Username:     "username_role",
+				Email:        "username_role@example.com",
+				PasswordHash: "hash",
+			}
+
+			var u user.User
+			if err := NewUserRepository(db).Create(ctx, &nu, &u); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if err := r.Delete(ctx, nu.RoleID); errors.Cause(err) != role.ErrInUse {
+				t.Errorf("unexpected error: %v", err)
+			}
 		}
 	}
 }
@@ -166,7 +215,7 @@ func TestListRoles(t *testing.T) {
 		t.Log("\ttest:0\tshould show list of roles")
 		{
 			var roles role.Roles
-			err := r.List(ctx, &roles)
+			err := r.List(ctx, query.New(), &roles)
 			if err != nil {
 				t.Errorf("unexpected error: %v", err)
 			}
@@ -177,3 +226,59 @@ func TestListRoles(t *testing.T) {
 		}
 	}
 }
+
+func TestRolePermissions(t *testing.T) {
+	t.Log("with initialized repository")
+	{
+		db, teardown := postgresDB(t)
+		defer teardown()
+
+		r := NewRoleRepository(db)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		nr := role.NewRole{
+			Name: "Editor",
+		}
+
+		var rol role.Role
+		if err := r.Create(ctx, &nr, &rol); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		t.Log("\ttest:0\tshould grant permissions to the role")
+		{
+			for _, p := range []role.Permission{role.ArticlesView, role.ArticlesCreate, role.ArticlesView} {
+				if err := r.Grant(ctx, rol.ID, p); err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+			}
+
+			found, err := r.Find(ctx, rol.ID)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(found.Permissions) != 2 || !found.Can(role.ArticlesCreate) {
+				t.Errorf("unexpected permissions: %v", found.Permissions)
+			}
+		}
+
+		t.Log("\ttest:1\tshould revoke the permission from the role")
+		{
+			if err := r.Revoke(ctx, rol.ID, role.ArticlesCreate); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			found, err := r.Find(ctx, rol.ID)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if found.Can(role.ArticlesCreate) {
+				t.Errorf("unexpected permissions: %v", found.Permissions)
+			}
+		}
+	}
+}
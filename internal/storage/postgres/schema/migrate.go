@@ -2,6 +2,8 @@ package schema
 
 import (
 	"database/sql"
+	"strconv"
+	"strings"
 
 	"github.com/mattes/migrate"
 	"github.com/mattes/migrate/database/postgres"
@@ -15,6 +17,9 @@ const (
 	migrationsTable = "versions"
 )
 
+// ErrOutdated is returned when the schema isn't at the latest version.
+var ErrOutdated = errors.New("schema is outdated")
+
 // go:generate go-bindata -prefix migrations/ -pkg schema -o migrations.bindata.go migrations/
 
 // Migrate migrates schema to given database connection.
@@ -31,6 +36,106 @@ func Migrate(db *sql.DB) error {
 	return nil
 }
 
+// Rollback rolls back the given number of the last migrations.
+func Rollback(db *sql.DB, steps int) error {
+	m, err := newMigration(db)
+	if err != nil {
+		return errors.Wrap(err, "new migration")
+	}
+
+	if err := m.Steps(-steps); err != nil {
+		return errors.Wrap(err, "rollback schema")
+	}
+
+	return nil
+}
+
+// Goto migrates schema up or down to the given version.
+func Goto(db *sql.DB, version uint) error {
+	m, err := newMigration(db)
+	if err != nil {
+		return errors.Wrap(err, "new migration")
+	}
+
+	if err := m.Migrate(version); err != nil {
+		return errors.Wrap(err, "migrate schema to version")
+	}
+
+	return nil
+}
+
+// Force sets the version of the schema without running migrations.
+// It clears the dirty state left by the failed migration.
+func Force(db *sql.DB, version int) error {
+	m, err := newMigration(db)
+	if err != nil {
+		return errors.Wrap(err, "new migration")
+	}
+
+	if err := m.Force(version); err != nil {
+		return errors.Wrap(err, "force schema version")
+	}
+
+	return nil
+}
+
+// Status returns the current version of the schema and whether
+// the last migration failed. The version is 0 for the empty schema.
+func Status(db *sql.DB) (version uint, dirty bool, err error) {
+	m, err := newMigration(db)
+	if err != nil {
+		return 0, false, errors.Wrap(err, "new migration")
+	}
+
+	version, dirty, err = m.Version()
+	if err != nil {
+		if err == migrate.ErrNilVersion {
+			return 0, false, nil
+		}
+		return 0, false, errors.Wrap(err, "schema version")
+	}
+
+	return version, dirty, nil
+}
+
+// Latest returns the version of the last bundled migration.
+func Latest() (uint, error) {
+	var latest uint
+	for _, name := range AssetNames() {
+		prefix := strings.SplitN(name, "_", 2)[0]
+		version, err := strconv.ParseUint(prefix, 10, 64)
+		if err != nil {
+			return 0, errors.Wrapf(err, "parse version of %s", name)
+		}
+
+		if uint(version) > latest {
+			latest = uint(version)
+		}
+	}
+
+	return latest, nil
+}
+
+// Check returns ErrOutdated unless the schema is cleanly
+// migrated to the latest version.
+func Check(db *sql.DB) error {
+	latest, err := Latest()
+	if err != nil {
+		return errors.Wrap(err, "latest version")
+	}
+
+	version, dirty, err := Status(db)
+	if err != nil {
+		return errors.Wrap(err, "status")
+	}
+
+	if dirty || version != latest {
+		return ErrOutdated
+	}
+
+	return nil
+}
+
 func newMigration(db *sql.DB) (*migrate.Migrate, error) {
 	r := bindata.Resource(AssetNames(), Asset)
 	s, err := bindata.WithInstance(r)
@@ -22,10 +22,80 @@ func Test_Migrate(t *testing.T) {
 			assert.Nil(t, err)
 		}
 
-		t.Log("\ttest:1\tshould down schema.")
+		t.Log("\ttest:1\tshould report the latest version.")
+		{
+			latest, err := Latest()
+			assert.Nil(t, err)
+
+			version, dirty, err := Status(db)
+			assert.Nil(t, err)
+			assert.False(t, dirty)
+			assert.Equal(t, latest, version)
+			assert.Nil(t, Check(db))
+		}
+
+		t.Log("\ttest:2\tshould rollback the last migration.")
+		{
+			err := Rollback(db, 1)
+			assert.Nil(t, err)
+			assert.Equal(t, ErrOutdated, Check(db))
+		}
+
+		t.Log("\ttest:3\tshould down schema.")
 		{
 			err := m.Down()
 			assert.Nil(t, err)
 		}
 	}
 }
+
+func Test_Migrate_CaseDuplicates(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test in short mode")
+	}
+
+	t.Log("with users which differ only by the case.")
+	{
+		assert.Nil(t, Goto(db, 1572955200))
+
+		_, err := db.Exec(caseDuplicatesQuery)
+		assert.Nil(t, err)
+
+		t.Log("\ttest:0\tshould refuse to make the users unique.")
+		{
+			err := Goto(db, 1573041600)
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), "username Duplicate, duplicate")
+			}
+		}
+
+		t.Log("\ttest:1\tshould migrate once the users are renamed.")
+		{
+			assert.Nil(t, Force(db, 1572955200))
+
+			_, err := db.Exec(`UPDATE users SET username = 'renamed', email = 'renamed@example.com' WHERE username = 'duplicate'`)
+			assert.Nil(t, err)
+
+			assert.Nil(t, Goto(db, 1573041600))
+		}
+
+		t.Log("\ttest:2\tshould down schema.")
+		{
+			m, err := newMigration(db)
+			assert.Nil(t, err)
+			assert.Nil(t, m.Down())
+		}
+	}
+}
+
+const caseDuplicatesQuery = `
+	INSERT INTO roles (name) VALUES ('Member');
+	INSERT INTO users (role_id, username, email, password_hash) VALUES
+	((SELECT id FROM roles WHERE name = 'Member'), 'Duplicate', 'Duplicate@example.com', 'hash'),
+	((SELECT id FROM roles WHERE name = 'Member'), 'duplicate', 'duplicate@example.com', 'hash')`
+
+func TestLatest(t *testing.T) {
+	latest, err := Latest()
+	assert.Nil(t, err)
+	assert.True(t, latest >= 1573128000)
+}
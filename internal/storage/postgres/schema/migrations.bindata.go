@@ -8,6 +8,42 @@
 // migrations/1570866542_articles.up.sql
 // migrations/1571140600_categories.down.sql
 // migrations/1571140600_categories.up.sql
+// migrations/1571745600_articles_search.down.sql
+// migrations/1571745600_articles_search.up.sql
+// migrations/1571832000_article_revisions.down.sql
+// migrations/1571832000_article_revisions.up.sql
+// migrations/1571918400_articles_authorship.down.sql
+// migrations/1571918400_articles_authorship.up.sql
+// migrations/1572004800_role_permissions.down.sql
+// migrations/1572004800_role_permissions.up.sql
+// migrations/1572091200_tokens.down.sql
+// migrations/1572091200_tokens.up.sql
+// migrations/1572177600_users_token_version.down.sql
+// migrations/1572177600_users_token_version.up.sql
+// migrations/1572264000_login_attempts.down.sql
+// migrations/1572264000_login_attempts.up.sql
+// migrations/1572350400_password_resets.down.sql
+// migrations/1572350400_password_resets.up.sql
+// migrations/1572436800_mfa.down.sql
+// migrations/1572436800_mfa.up.sql
+// migrations/1572523200_api_keys.down.sql
+// migrations/1572523200_api_keys.up.sql
+// migrations/1572609600_oidc_states.down.sql
+// migrations/1572609600_oidc_states.up.sql
+// migrations/1572696000_invitations.down.sql
+// migrations/1572696000_invitations.up.sql
+// migrations/1572782400_soft_delete.down.sql
+// migrations/1572782400_soft_delete.up.sql
+// migrations/1572868800_sessions.down.sql
+// migrations/1572868800_sessions.up.sql
+// migrations/1572955200_foreign_keys.down.sql
+// migrations/1572955200_foreign_keys.up.sql
+// migrations/1573041600_case_insensitive_users.down.sql
+// migrations/1573041600_case_insensitive_users.up.sql
+// migrations/1573128000_versions.down.sql
+// migrations/1573128000_versions.up.sql
+// migrations/1573214400_login_events_email.down.sql
+// migrations/1573214400_login_events_email.up.sql
 // DO NOT EDIT!
 
 package schema
@@ -235,6 +271,726 @@ func _1571140600_categoriesUpSql() (*asset, error) {
 	return a, nil
 }
 
+var __1571745600_articles_searchDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x7c\xcc\xb1\x0a\xc3\x20\x14\x85\xe1\xdd\xa7\xb8\x63\xfb\x0c\x4e\x69\x62\x82\x60\xb5\x18\x03\xd9\x44\xf4\xd2\x08\x85\x14\xb5\xa5\x7d\xfb\x42\x09\x21\x93\xf3\xf9\xce\xdf\x69\x75\x03\x2e\x3b\x36\x03\xef\x81\xcd\x7c\x34\x23\xb8\x54\xa2\x7f\x60\xb6\xde\x15\xbc\xaf\xe9\x6b\x63\xb0\x31\x7c\x28\xa9\xf3\x8c\x2e\xf9\xe5\x20\x8d\xe6\xc3\xc0\x74\xc5\xbe\x9e\xc1\x15\x04\x25\xf7\x65\xbb\xf6\x93\x6c\x0d\x57\xb2\xf2\x7d\xa3\x2f\x6b\x3a\x9d\x29\x69\x84\x61\x1a\x4c\x73\x11\x6c\x57\xf0\xcf\xb4\x4a\x4c\xd7\x63\x24\xa3\x4b\x7e\xa1\xe4\x37\x00\xa1\x66\xf5\xa2\xf9\x00\x00\x00")
+
+func _1571745600_articles_searchDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1571745600_articles_searchDownSql,
+		"1571745600_articles_search.down.sql",
+	)
+}
+
+func _1571745600_articles_searchDownSql() (*asset, error) {
+	bytes, err := _1571745600_articles_searchDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1571745600_articles_search.down.sql", size: 249, mode: os.FileMode(420), modTime: time.Unix(1792300219, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __1571745600_articles_searchUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x94\x92\xcd\x8a\xa3\x40\x14\x85\xd7\xd6\x53\x9c\x45\x40\x85\x30\x0f\x30\xd2\x8b\x8a\x5e\x9d\x02\xa7\x2a\x94\x55\x93\xec\x82\x63\x8a\x44\x90\x31\x13\x6b\x7e\x1a\xfa\xe1\x9b\x10\x0d\x92\xf4\xa2\xb3\xd6\xf3\xd5\x3d\xdf\xbd\xbc\x34\xa4\x61\xf8\xaa\x24\xd4\x67\xdf\x36\x9d\x1b\xc0\xb3\x0c\xa9\x2a\xed\x77\x09\x91\x43\x2a\x03\xda\x8a\xca\x54\x18\x5c\x7d\x6e\x8e\x30\xd5\x0f\x4a\x8d\xd2\x09\x63\xa9\x26\x6e\x08\x4a\x43\xd3\xba\xe4\x29\x21\xb7\x32\x35\x42\xc9\x1b\x6e\x77\x4d\xed\xfe\xba\xc6\xf7\xe7\x28\x86\x26\x63\xb5\xac\x60\xb4\x28\x0a\xd2\xe0\x15\x16\x0b\xb6\xa2\x42\x48\x16\x48\xda\x7c\x19\x9f\xf9\xfa\xc2\x82\x60\x70\xfe\x9f\x6b\x0f\x47\x1f\xf9\x7e\xe7\x87\x11\x12\xba\x5f\x87\xae\x1d\x8e\xe1\x12\x4d\x5f\x77\x6e\x68\x5c\x74\x49\xfa\xd6\x77\x6e\x89\x30\x8c\xe3\x25\x42\x1e\xc6\x78\x7b\x7b\x1a\xf2\xb3\xdf\xbf\xde\x18\xab\x30\x4e\x58\x70\x1d\x19\x92\x36\x09\x23\x99\xb1\xc5\x02\x25\x97\x85\xe5\x05\xe1\xd4\x9d\x0e\xc3\xef\x2e\x61\x2c\xd3\x6a\x7d\x6b\x25\xf2\xc9\xda\xbd\x88\x3f\xa7\x7d\xed\x1d\x66\x8a\x92\xc9\xe3\x14\xfe\x38\xc2\x82\x15\xe5\x4a\x13\x84\xac\x48\x9b\x8b\x75\xbb\xce\x2e\x39\x95\x63\xac\x7e\x19\x7e\x8e\x66\x41\xae\x34\x88\xa7\xdf\xa0\xd5\x06\xb4\xa5\xd4\x1a\xc2\x5a\xab\x94\x32\xab\xe9\xe1\xa9\x51\x4e\x9c\x30\x36\xc2\xa7\x3f\x50\x91\x99\x4e\xe0\x85\x3d\x21\xf5\xe3\xad\x7c\x3e\xff\xb8\x90\xc9\x97\x90\x19\x6d\xef\x8e\xf4\xbe\x50\xbb\xff\x3f\x17\x02\x5b\x09\x59\xa0\x10\x12\xd1\xb5\x4c\x9c\x7c\x0a\xd7\xd4\xde\x1d\xfa\xf3\xeb\xae\xdd\x3f\x30\xa3\xd9\xc7\x38\x61\xef\x03\x00\xd3\x11\xed\xa1\x56\x03\x00\x00")
+
+func _1571745600_articles_searchUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1571745600_articles_searchUpSql,
+		"1571745600_articles_search.up.sql",
+	)
+}
+
+func _1571745600_articles_searchUpSql() (*asset, error) {
+	bytes, err := _1571745600_articles_searchUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1571745600_articles_search.up.sql", size: 854, mode: os.FileMode(420), modTime: time.Unix(1792300219, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __1571832000_article_revisionsDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x28\x00\xd7\xff\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x49\x46\x20\x45\x58\x49\x53\x54\x53\x20\x61\x72\x74\x69\x63\x6c\x65\x5f\x72\x65\x76\x69\x73\x69\x6f\x6e\x73\x3b\x0a\x03\x00\x04\xfd\x5c\x1e\x28\x00\x00\x00")
+
+func _1571832000_article_revisionsDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1571832000_article_revisionsDownSql,
+		"1571832000_article_revisions.down.sql",
+	)
+}
+
+func _1571832000_article_revisionsDownSql() (*asset, error) {
+	bytes, err := _1571832000_article_revisionsDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1571832000_article_revisions.down.sql", size: 40, mode: os.FileMode(420), modTime: time.Unix(1792300309, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __1571832000_article_revisionsUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x8c\x91\xcb\xce\xda\x30\x14\x84\xd7\xf6\x53\xcc\x92\x20\xab\xa8\x95\x58\xb1\x72\x83\x53\xac\x26\x0e\x75\x9c\x0a\x56\x28\x4d\x5c\x6a\x89\x9b\x6c\x83\xca\xdb\x57\x41\xdc\x54\x65\xf1\x6f\xcf\x99\x19\x7b\xbe\x93\x6a\xc1\x8d\x80\xe1\x5f\x73\x01\x99\x41\x95\x06\x62\x25\x2b\x53\xa1\xf1\xd1\xb5\x3b\xbb\xf1\xf6\xe2\x82\x3b\x1e\x02\x46\x94\xb8\x8e\x54\x42\x4b\x9e\x63\xa9\x65\xc1\xf5\x1a\xdf\xc5\x9a\x51\xf2\x10\xbb\x8e\x48\x65\x6e\x31\xaa\xce\x73\x46\xc9\xc5\xfa\xde\xfd\xff\xf8\x1c\xac\x1f\x50\xb7\x4d\xb4\xdb\xa3\xbf\x0e\xac\xa2\x8b\x3b\x4b\x7e\x72\x9d\x2e\xb8\xc6\xe8\xcb\x74\x9a\xbc\xaf\x7f\x1d\xbb\x2b\x31\x62\xf5\xee\xa1\x64\x32\x46\x74\x7b\x1b\x62\xb3\x3f\x61\x3c\xa1\xa4\xf5\xb6\x89\xb6\xdb\x34\x91\x18\x59\x88\xca\xf0\x62\xf9\x74\x60\x2e\x32\x5e\xe7\x06\x69\xad\xb5\x50\x66\xf3\x94\xf4\x59\xb5\x92\x3f\x6a\x81\xd1\xab\x2b\xc3\xbd\x5d\x42\x93\x19\xa5\x93\x31\xc4\x5f\x17\xa2\x3b\x6c\x1f\xf4\x02\xb6\x36\x22\xfe\xb1\xce\xa3\x3d\x7b\x6f\x0f\x11\x21\x36\xd1\xa2\x09\xfd\x18\xbf\x9d\x0f\x11\x0f\xc6\x9f\xfa\x3f\x4a\x55\x09\x6d\x20\x95\x29\x87\x8e\x30\xf0\x3c\xc3\x1d\x27\xc3\x1b\x40\x86\x1b\x32\x86\x1e\x0d\xc3\xab\x79\x42\x2b\x91\x8b\xd4\xa0\x8f\xf8\xfc\x21\xf3\xf9\xd4\xdd\xcd\xc8\x74\x59\x3c\xeb\xd1\x52\x21\x2d\x55\x96\xcb\xd4\x60\x5e\x42\x95\x66\x21\xd5\xb7\x19\xfd\x37\x00\xc0\x79\x6d\xea\x59\x02\x00\x00")
+
+func _1571832000_article_revisionsUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1571832000_article_revisionsUpSql,
+		"1571832000_article_revisions.up.sql",
+	)
+}
+
+func _1571832000_article_revisionsUpSql() (*asset, error) {
+	bytes, err := _1571832000_article_revisionsUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1571832000_article_revisions.up.sql", size: 601, mode: os.FileMode(420), modTime: time.Unix(1792300309, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __1571918400_articles_authorshipDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x6c\xcd\x41\x0a\xc2\x30\x10\x85\xe1\x7d\x4e\x31\xf7\x28\x2e\xa2\x1d\xa1\xd0\x26\x25\x99\x42\x77\x43\x74\x02\x06\x04\x25\x4d\x40\x6f\x2f\x08\xd6\x22\xae\xdf\xfb\xf8\x5b\x67\x47\xe8\x4c\x8b\x33\x74\x47\xc0\xb9\xf3\xe4\x21\xe4\x92\xce\xd7\xb8\x70\xa8\xe5\x72\xcb\x9c\x84\x93\x3c\x1a\xa5\xa6\xb1\xd5\x84\xeb\x0e\x1e\x09\xd6\x0f\xec\xa0\xde\x25\x94\x28\x7c\x7a\x72\x92\x46\x29\xdd\x13\x3a\x20\xbd\xef\x37\xe8\x9d\x3c\xd8\x7e\x1a\xcc\xa6\xf9\x43\xff\x4a\x87\x46\x0f\xf8\xb1\xdf\x30\x59\xa8\x4b\xcc\x9c\xa4\x51\xaf\x01\x00\xf5\x24\xf4\x25\xd1\x00\x00\x00")
+
+func _1571918400_articles_authorshipDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1571918400_articles_authorshipDownSql,
+		"1571918400_articles_authorship.down.sql",
+	)
+}
+
+func _1571918400_articles_authorshipDownSql() (*asset, error) {
+	bytes, err := _1571918400_articles_authorshipDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1571918400_articles_authorship.down.sql", size: 209, mode: os.FileMode(420), modTime: time.Unix(1792300519, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __1571918400_articles_authorshipUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x74\x52\xc1\x8e\x9b\x30\x14\xbc\xfb\x2b\xe6\xd8\x46\x11\x51\xcf\x28\x07\xba\x78\xd5\x48\x04\x2a\x42\xd4\xbd\x21\x16\x3f\x84\x55\xca\x8b\x6c\x13\x9a\xbf\xaf\x4c\x03\x9b\xcd\x66\x8f\x7e\xcf\x33\xf3\x66\x34\x51\x52\xc8\x1c\x45\xf4\x3d\x91\xa8\x8c\xd3\x75\x47\x16\xb9\x4c\xa3\xbd\xc4\x53\x96\x1c\xf7\x29\x06\x4b\xa6\xd4\x0a\x45\x86\x6a\x70\x2d\xfb\x47\x28\x1e\x02\xa3\x38\x9e\x51\xbb\x67\xa4\x59\x01\xf9\xb2\x3b\x14\x07\x0c\x27\x55\x39\x52\xe5\xeb\xc5\x33\xed\xd2\x22\x14\x62\xb3\xc2\xb1\x77\xba\x43\xcf\xe3\x22\x32\x56\x16\x7c\x26\x33\x1a\xed\x1c\xf5\xe0\x1e\x74\x26\x73\xb9\x32\xac\x61\x19\xda\xa1\xe5\x4e\x59\xb8\x96\xd0\x55\xd6\x81\x94\x76\x6c\x02\xac\x36\xe2\xf8\x33\x8e\x8a\x9b\x93\x0e\xb2\xb8\x53\xdf\xde\xda\x10\x9b\x95\xc0\x0a\x45\x4b\x68\xb4\xb1\x0e\x86\xce\xda\x6a\xee\xf1\x9b\xe8\xf4\x5f\xc2\xdf\x86\xb1\x65\xd4\x86\x3c\xcf\x34\xbc\x0a\xac\xf1\x3a\x38\x70\xdf\x5d\xd0\xb0\xf1\x1b\x4f\xb7\xa8\xcf\x88\xaa\x71\x34\x6d\x17\x7e\x8b\x91\x0c\x41\xf7\xce\xb0\x1a\x6a\x52\xc1\x83\x23\x2c\xb8\xf1\x7c\x1e\xc8\x9d\x22\xf3\xc6\x3c\xa1\x6b\x3e\x69\x52\x68\x0c\xff\x99\x13\x9c\x12\x72\x2d\x5d\xa6\x8c\xee\x23\x82\x63\x5e\x7b\xc6\xaa\x9f\x76\xfe\x02\x8b\x9e\xc1\xfe\x01\x43\x35\x1b\x05\x6e\xee\xec\xb2\x5d\x0c\xdb\x40\x7c\x16\xf3\x12\x2b\xb6\xf3\xa2\x5c\x9c\x04\xd7\xfb\xc4\x73\x9e\xed\x3f\xae\xc5\xaf\x1f\x32\x97\x1f\xe7\xc1\x3c\xb9\x65\xb5\x81\x56\x88\xd2\xf8\xc1\xf7\x33\x19\x8f\xc3\x16\xdf\x42\xf1\x49\x49\xa7\xe1\x5c\xee\x77\xd5\xf0\x2e\x7c\x6b\xd3\x63\x92\x84\x42\x3c\xe5\xd2\x77\x69\x97\xc6\xf2\xe5\xae\xd0\x33\x5b\xb9\x98\x2e\xb5\xfa\x8b\x2c\x7d\xd3\xf9\xb2\xac\xbe\x86\xe2\xdf\x00\xda\xe2\x33\x04\x68\x03\x00\x00")
+
+func _1571918400_articles_authorshipUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1571918400_articles_authorshipUpSql,
+		"1571918400_articles_authorship.up.sql",
+	)
+}
+
+func _1571918400_articles_authorshipUpSql() (*asset, error) {
+	bytes, err := _1571918400_articles_authorshipUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1571918400_articles_authorship.up.sql", size: 872, mode: os.FileMode(420), modTime: time.Unix(1792307194, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __1572004800_role_permissionsDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x27\x00\xd8\xff\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x49\x46\x20\x45\x58\x49\x53\x54\x53\x20\x72\x6f\x6c\x65\x5f\x70\x65\x72\x6d\x69\x73\x73\x69\x6f\x6e\x73\x3b\x0a\x03\x00\x4f\x05\x16\xf2\x27\x00\x00\x00")
+
+func _1572004800_role_permissionsDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1572004800_role_permissionsDownSql,
+		"1572004800_role_permissions.down.sql",
+	)
+}
+
+func _1572004800_role_permissionsDownSql() (*asset, error) {
+	bytes, err := _1572004800_role_permissionsDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1572004800_role_permissions.down.sql", size: 39, mode: os.FileMode(420), modTime: time.Unix(1792300671, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __1572004800_role_permissionsUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xd4\x92\x4f\x8f\xda\x30\x10\xc5\xcf\xf1\xa7\x98\x5b\x60\x15\x2d\xdb\xeb\xf6\x8f\xe4\x06\x53\xa2\x86\x64\xe5\x98\xb6\xa8\xaa\x90\x37\x99\x82\x45\x62\x23\xdb\x6c\xb4\xdf\xbe\x82\xb0\x24\x5b\x21\x6d\xaf\x3d\xfa\x37\x2f\xf3\x26\x6f\x26\xe6\x8c\x0a\x06\x82\x7e\x4e\x19\x24\x33\xc8\x72\x01\xec\x47\x52\x88\x02\xac\xa9\x71\xbd\x47\xdb\x28\xe7\x94\xd1\x0e\x46\x24\x38\x31\x55\x05\x49\x26\x4e\xd2\x6c\x99\xa6\xc0\xd9\x8c\x71\x96\xc5\xac\xfb\xc6\xc1\x48\x55\x63\xc8\x33\x98\xb2\x94\x09\x06\x31\x2d\x62\x3a\x65\x11\x09\xfa\x6e\xc1\x37\xca\xe3\x39\xe5\x30\x7a\x77\x77\x37\xbe\xf4\x8a\x08\x09\x26\x37\xe0\x55\x83\xce\xcb\x66\x0f\x37\x13\x12\x94\x16\xa5\xc7\x6a\x2d\x7d\x20\x92\x05\x2b\x04\x5d\x3c\xf4\xee\x53\x36\xa3\xcb\x54\x40\xbc\xe4\x9c\x65\x62\x7d\x91\x1c\x7b\x3d\xf0\x64\x41\xf9\x0a\xbe\xb2\x15\x8c\xce\xc3\x47\xd0\x8f\x31\x26\xe3\xf7\x84\x4c\x6e\x80\x56\x8d\xd2\xb0\x43\xdc\x3b\x90\x65\x89\xce\x81\x37\x80\x4f\x68\x9f\xfd\x56\xe9\x0d\x28\x0f\xa5\x39\xd4\x15\x54\x06\x1e\xf1\xb7\xb1\x78\x7b\x1c\x2e\xc9\x0a\xc6\x05\x24\x99\xc8\xaf\x04\x76\xd5\xb1\x60\x29\x8b\xc5\x49\xed\x6e\x5f\x17\xc9\x8c\xe7\x8b\xae\x12\xc1\x41\x6b\x74\x7e\x44\x39\xa7\xab\x9f\x24\x08\xa5\xf5\xaa\xac\xd1\xdd\x3f\x29\x6c\xc3\x08\x7a\xd0\x05\xf4\x0a\x1d\xf6\xd5\x75\x74\x6f\x5a\x1d\x46\xc3\x76\x15\xd6\xf8\x97\xb4\x43\x17\x69\x29\x3d\x6e\x8c\x55\x03\xef\x01\xea\xdd\x07\xb0\xf7\x1f\xc0\x17\x27\x12\x84\xd6\x0c\xff\xa4\x7b\x35\x52\xcb\xcd\x69\x90\x83\x43\xdb\x57\xbb\xd7\xb9\x4a\x7e\x8d\x87\x81\x7d\x9f\x33\xce\xce\x59\x6a\xd9\x20\x7c\x84\xf0\xb4\xcb\x90\xe4\x19\xc4\x79\x36\x4b\x93\x58\xc0\x34\x3f\x1e\xcc\x3c\xc9\xbe\x74\xeb\xce\xfd\x16\xed\xf9\x5a\x5b\x63\x77\xd0\x2a\xbf\x05\xbf\x45\xd8\x69\xd3\xd6\x58\x6d\x10\x1e\xa5\x43\x90\xba\x82\x72\x2b\xf5\x06\xc1\xe8\xfa\xf9\x28\x51\x16\x4c\xab\xe1\x25\xac\xff\xe0\x0c\xba\x45\xfe\xfb\x7e\xdf\xca\xf8\xc3\xa7\x37\x43\xfe\x33\x00\x2d\x2b\x13\x60\x58\x04\x00\x00")
+
+func _1572004800_role_permissionsUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1572004800_role_permissionsUpSql,
+		"1572004800_role_permissions.up.sql",
+	)
+}
+
+func _1572004800_role_permissionsUpSql() (*asset, error) {
+	bytes, err := _1572004800_role_permissionsUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1572004800_role_permissions.up.sql", size: 1112, mode: os.FileMode(420), modTime: time.Unix(1792300671, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __1572091200_tokensDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x4a\x00\xb5\xff\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x49\x46\x20\x45\x58\x49\x53\x54\x53\x20\x72\x65\x76\x6f\x6b\x65\x64\x5f\x74\x6f\x6b\x65\x6e\x73\x3b\x0a\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x49\x46\x20\x45\x58\x49\x53\x54\x53\x20\x72\x65\x66\x72\x65\x73\x68\x5f\x74\x6f\x6b\x65\x6e\x73\x3b\x0a\x03\x00\x28\x07\xd1\xf1\x4a\x00\x00\x00")
+
+func _1572091200_tokensDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1572091200_tokensDownSql,
+		"1572091200_tokens.down.sql",
+	)
+}
+
+func _1572091200_tokensDownSql() (*asset, error) {
+	bytes, err := _1572091200_tokensDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1572091200_tokens.down.sql", size: 74, mode: os.FileMode(420), modTime: time.Unix(1792300882, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __1572091200_tokensUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xbc\xd0\xc1\x4b\xc3\x30\x14\x06\xf0\x73\xf2\x57\xbc\xe3\x36\x0a\xbb\x88\x97\x9d\x62\xf7\x86\xc1\x36\x9b\x69\x2a\xdb\x29\x14\xfb\xa4\x71\xcc\x8d\x24\xca\xfe\x7c\x61\x16\xd3\xe2\xd0\x9b\xe7\xf7\xe5\x23\xdf\x2f\xd7\x28\x0c\x82\x11\x77\x05\x82\x5c\x81\x5a\x1b\xc0\xad\xac\x4c\x05\x9e\x5e\x3c\x85\xce\xc6\xe3\x9e\xde\x02\x4c\x38\x73\x2d\xab\x50\x4b\x51\xc0\x46\xcb\x52\xe8\x1d\x3c\xe0\x2e\xe3\xec\x3d\x90\xb7\xae\x65\x52\x99\x4b\x81\xaa\x8b\x22\xe3\xec\xf2\xd0\x76\x4d\xe8\x58\x7e\x2f\x34\x4c\x6e\x6f\xa6\x50\x2b\xf9\x58\xe3\x30\x46\xe7\x93\xf3\x14\x6c\x13\x99\x91\x25\x56\x46\x94\x9b\xe1\xdd\xd3\xc7\x71\x4f\xed\xe8\x9e\x71\xce\xe6\x33\x88\xee\x40\x21\x36\x87\x13\xcc\xe6\x9c\x3d\x7b\x6a\x22\xb5\xd7\x8b\x60\x89\x2b\x51\x17\x06\xf2\x5a\x6b\x54\xc6\x7e\x47\xf8\x74\xc1\x79\xef\x20\xd5\x12\xb7\xbf\x3a\xd8\x7e\xac\x75\xed\x19\xd6\xea\x87\x52\x7f\x1e\x74\x5e\xb7\xfd\xda\x94\x6c\x5f\xa3\x63\x4f\x42\x27\xa8\x31\xf1\x1f\x48\xff\xa9\x31\xfc\xb9\x4d\xff\x4a\x20\xe3\x69\x29\x31\x5d\xf0\xcf\x01\x00\x95\x25\x47\x3d\x6f\x02\x00\x00")
+
+func _1572091200_tokensUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1572091200_tokensUpSql,
+		"1572091200_tokens.up.sql",
+	)
+}
+
+func _1572091200_tokensUpSql() (*asset, error) {
+	bytes, err := _1572091200_tokensUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1572091200_tokens.up.sql", size: 623, mode: os.FileMode(420), modTime: time.Unix(1792300882, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __1572177600_users_token_versionDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x37\x00\xc8\xff\x41\x4c\x54\x45\x52\x20\x54\x41\x42\x4c\x45\x20\x75\x73\x65\x72\x73\x20\x44\x52\x4f\x50\x20\x43\x4f\x4c\x55\x4d\x4e\x20\x49\x46\x20\x45\x58\x49\x53\x54\x53\x20\x74\x6f\x6b\x65\x6e\x5f\x76\x65\x72\x73\x69\x6f\x6e\x3b\x0a\x03\x00\xd0\x49\x67\x06\x37\x00\x00\x00")
+
+func _1572177600_users_token_versionDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1572177600_users_token_versionDownSql,
+		"1572177600_users_token_version.down.sql",
+	)
+}
+
+func _1572177600_users_token_versionDownSql() (*asset, error) {
+	bytes, err := _1572177600_users_token_versionDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1572177600_users_token_version.down.sql", size: 55, mode: os.FileMode(420), modTime: time.Unix(1792301341, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __1572177600_users_token_versionUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x51\x00\xae\xff\x41\x4c\x54\x45\x52\x20\x54\x41\x42\x4c\x45\x20\x75\x73\x65\x72\x73\x20\x41\x44\x44\x20\x43\x4f\x4c\x55\x4d\x4e\x20\x49\x46\x20\x4e\x4f\x54\x20\x45\x58\x49\x53\x54\x53\x20\x74\x6f\x6b\x65\x6e\x5f\x76\x65\x72\x73\x69\x6f\x6e\x20\x49\x4e\x54\x20\x4e\x4f\x54\x20\x4e\x55\x4c\x4c\x20\x44\x45\x46\x41\x55\x4c\x54\x20\x31\x3b\x0a\x03\x00\xa5\x9f\xd8\xc5\x51\x00\x00\x00")
+
+func _1572177600_users_token_versionUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1572177600_users_token_versionUpSql,
+		"1572177600_users_token_version.up.sql",
+	)
+}
+
+func _1572177600_users_token_versionUpSql() (*asset, error) {
+	bytes, err := _1572177600_users_token_versionUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1572177600_users_token_version.up.sql", size: 81, mode: os.FileMode(420), modTime: time.Unix(1792301341, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __1572264000_login_attemptsDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x25\x00\xda\xff\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x49\x46\x20\x45\x58\x49\x53\x54\x53\x20\x6c\x6f\x67\x69\x6e\x5f\x61\x74\x74\x65\x6d\x70\x74\x73\x3b\x0a\x03\x00\xee\xdf\x7c\xa1\x25\x00\x00\x00")
+
+func _1572264000_login_attemptsDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1572264000_login_attemptsDownSql,
+		"1572264000_login_attempts.down.sql",
+	)
+}
+
+func _1572264000_login_attemptsDownSql() (*asset, error) {
+	bytes, err := _1572264000_login_attemptsDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1572264000_login_attempts.down.sql", size: 37, mode: os.FileMode(420), modTime: time.Unix(1792301471, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __1572264000_login_attemptsUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x64\xcc\xb1\x4e\xc3\x30\x10\x00\xd0\xf9\xee\x2b\x6e\x6c\xab\x48\xad\x60\x64\x32\xe1\x2a\x2c\x12\x53\x39\x17\x44\x27\xcb\x22\x06\x59\x75\x9a\x88\x5c\x06\xfe\x1e\x89\x01\x06\xf6\xa7\x57\x7b\x36\xc2\x24\xe6\xbe\x61\xb2\x47\x72\xcf\x42\xfc\x6a\x3b\xe9\xa8\x4c\x1f\xf9\x1a\xa2\x6a\x1a\x67\x5d\x68\x83\x70\x49\x5f\xf0\x62\x7c\xfd\x68\x3c\x6d\x6e\x6f\x0e\x5b\x3a\x79\xdb\x1a\x7f\xa6\x27\x3e\x57\x08\xef\x31\x97\xf5\x33\x2d\x60\x9d\xfc\x4c\xae\x6f\x1a\x7a\xe0\xa3\xe9\x1b\xa1\x43\x85\x50\xa6\xb7\x4b\x1a\xc2\x7a\xd5\x5c\x40\x6c\xcb\x9d\x98\xf6\x54\x21\xc2\x7e\x47\x9a\xc7\xb4\x68\x1c\x67\xda\xed\x11\xd6\x79\x88\x9a\x86\x10\xf5\x0f\xfe\x4f\xeb\xde\x7b\x76\x12\x7e\x09\x6e\xef\xf0\x7b\x00\x73\xd4\x1f\xbb\xd5\x00\x00\x00")
+
+func _1572264000_login_attemptsUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1572264000_login_attemptsUpSql,
+		"1572264000_login_attempts.up.sql",
+	)
+}
+
+func _1572264000_login_attemptsUpSql() (*asset, error) {
+	bytes, err := _1572264000_login_attemptsUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1572264000_login_attempts.up.sql", size: 213, mode: os.FileMode(420), modTime: time.Unix(1792301471, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __1572350400_password_resetsDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x26\x00\xd9\xff\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x49\x46\x20\x45\x58\x49\x53\x54\x53\x20\x70\x61\x73\x73\x77\x6f\x72\x64\x5f\x72\x65\x73\x65\x74\x73\x3b\x0a\x03\x00\xbf\x52\xb7\x21\x26\x00\x00\x00")
+
+func _1572350400_password_resetsDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1572350400_password_resetsDownSql,
+		"1572350400_password_resets.down.sql",
+	)
+}
+
+func _1572350400_password_resetsDownSql() (*asset, error) {
+	bytes, err := _1572350400_password_resetsDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1572350400_password_resets.down.sql", size: 38, mode: os.FileMode(420), modTime: time.Unix(1792301622, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __1572350400_password_resetsUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x7c\x90\xc1\x6a\x32\x31\x14\x46\xd7\xc9\x53\xdc\xa5\x23\x03\x6e\x7e\xfe\x8d\xab\x34\x73\xa5\xa1\x99\x68\x93\x0c\xe8\x2a\x0c\x4d\xc0\x50\xac\x43\x6e\x86\xfa\xf8\xc5\x22\xd2\xd2\xd2\xf5\xf9\x38\xf0\x1d\x69\x51\x78\x04\x2f\x1e\x34\x82\xda\x80\xd9\x7a\xc0\xbd\x72\xde\xc1\x34\x12\xbd\x9f\x4b\x0c\x25\x51\xaa\x04\x0b\xce\x72\x64\x0e\xad\x12\x1a\x76\x56\xf5\xc2\x1e\xe0\x09\x0f\x2d\x67\x33\xa5\x12\x72\x64\xca\xf8\x4f\x83\x19\xb4\x06\x8b\x1b\xb4\x68\x24\x3a\xb8\x72\x82\x45\x8e\x0d\x6c\x0d\x74\xa8\xd1\x23\x48\xe1\xa4\xe8\xb0\xe5\xac\x9e\x5f\xd3\x5b\x38\x8e\x74\x64\xf2\x51\x58\x58\xfc\xff\xd7\xc0\x60\xd4\xf3\x80\x77\x5d\xcb\x59\xba\x4c\xb9\x24\x0a\x63\x65\x5e\xf5\xe8\xbc\xe8\x77\x5f\xf9\x4c\x29\x7e\x83\x2d\xe7\x6c\xb5\x84\x9a\x4f\x89\xea\x78\x9a\x60\xb9\xe2\xec\xa5\xa4\xb1\xa6\xf8\xbb\x05\x3a\xdc\x88\x41\x7b\x90\x83\xb5\x68\x7c\xb8\x4f\x78\xb3\xe6\xfc\x56\x4b\x99\x0e\xf7\x7f\xd7\x0a\xb7\x24\x21\xc7\xcb\xf5\xf3\x8f\x98\x33\xa5\x12\x72\x6c\xd6\xfc\x63\x00\xcf\xe8\xa7\xbf\x83\x01\x00\x00")
+
+func _1572350400_password_resetsUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1572350400_password_resetsUpSql,
+		"1572350400_password_resets.up.sql",
+	)
+}
+
+func _1572350400_password_resetsUpSql() (*asset, error) {
+	bytes, err := _1572350400_password_resetsUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1572350400_password_resets.up.sql", size: 387, mode: os.FileMode(420), modTime: time.Unix(1792301622, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __1572436800_mfaDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x79\x00\x86\xff\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x49\x46\x20\x45\x58\x49\x53\x54\x53\x20\x6d\x66\x61\x5f\x72\x65\x63\x6f\x76\x65\x72\x79\x5f\x63\x6f\x64\x65\x73\x3b\x0a\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x49\x46\x20\x45\x58\x49\x53\x54\x53\x20\x6d\x66\x61\x3b\x0a\x41\x4c\x54\x45\x52\x20\x54\x41\x42\x4c\x45\x20\x72\x6f\x6c\x65\x73\x20\x44\x52\x4f\x50\x20\x43\x4f\x4c\x55\x4d\x4e\x20\x49\x46\x20\x45\x58\x49\x53\x54\x53\x20\x6d\x66\x61\x5f\x72\x65\x71\x75\x69\x72\x65\x64\x3b\x0a\x03\x00\x7c\xfb\xe8\x41\x79\x00\x00\x00")
+
+func _1572436800_mfaDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1572436800_mfaDownSql,
+		"1572436800_mfa.down.sql",
+	)
+}
+
+func _1572436800_mfaDownSql() (*asset, error) {
+	bytes, err := _1572436800_mfaDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1572436800_mfa.down.sql", size: 121, mode: os.FileMode(420), modTime: time.Unix(1792302238, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __1572436800_mfaUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xb4\xd2\xc1\x8e\xd3\x30\x10\x06\xe0\xb3\xfd\x14\x73\x6c\x56\x91\x96\x03\xe2\xb2\x27\x37\x99\x82\x85\xe3\x54\x8e\x83\xda\x93\x65\xe2\xa9\x1a\x29\x6d\xc0\x4e\x10\xbc\x3d\x0a\xaa\x2a\xa0\x15\xe5\xb2\x67\x8f\x7f\xff\xf3\xc9\x42\x59\x34\x60\xc5\x5a\x21\xc4\x71\xa0\x04\xa2\x2c\xa1\xa8\x55\x5b\x69\x90\x1b\xd0\xb5\x05\xdc\xc9\xc6\x36\x70\x3a\x78\x17\xe9\xeb\xdc\x47\x0a\xb0\xae\x6b\x85\x42\xff\x3a\xd7\xad\x52\x50\xe2\x46\xb4\xca\xc2\xc1\x0f\x89\x5e\x38\x2f\x0c\x0a\x8b\x97\xe4\x9b\x20\x58\x71\x36\x27\x8a\xae\x0f\x4c\x6a\x0b\x5b\x23\x2b\x61\xf6\xf0\x11\xf7\x60\x70\x83\x06\x75\x81\x0d\x2c\x23\x09\x56\x7d\xc8\xa0\xd6\x50\xa2\x42\x8b\x50\x88\xa6\x10\x25\xe6\x9c\x25\xea\x22\x4d\xec\x93\x30\xc5\x07\x61\x60\xf5\xee\x6d\x76\x2d\x94\x73\x46\x67\xff\x79\xa0\xc0\xfe\xdd\x35\xe7\x6c\xf0\x69\x72\xdd\x38\x9f\x27\x8a\x6c\x2d\xdf\x2f\x8d\x6e\x86\xdf\xe4\x9c\xb3\xe7\x27\x98\xfa\x13\xa5\xc9\x9f\xbe\xc0\xd3\x33\x67\x5d\x24\x3f\x51\x70\x7e\x62\x56\x56\xd8\x58\x51\x6d\x6f\xef\x16\xad\x31\xa8\xad\xbb\x8e\xf0\xec\x21\x91\x8b\xd4\x8d\xdf\x28\xfe\x70\xdd\x18\x28\x2d\x62\x7d\x60\x0d\x1a\x29\xd4\xef\x5e\xf9\x9f\x92\xd7\xb7\xff\x9f\x71\xc9\x77\x47\x9f\x8e\xec\x2e\xe3\x9c\xfe\xda\xef\xb5\x20\xa4\x2e\x71\xf7\x10\xc2\x5d\xb6\x75\x7d\xf8\xbe\xfc\x8a\x7b\x54\x73\xa2\xe8\xfa\x90\xbd\xf0\x9f\x03\x00\xb5\x0c\xa2\x29\xe0\x02\x00\x00")
+
+func _1572436800_mfaUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1572436800_mfaUpSql,
+		"1572436800_mfa.up.sql",
+	)
+}
+
+func _1572436800_mfaUpSql() (*asset, error) {
+	bytes, err := _1572436800_mfaUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1572436800_mfa.up.sql", size: 736, mode: os.FileMode(420), modTime: time.Unix(1792302238, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __1572523200_api_keysDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x1f\x00\xe0\xff\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x49\x46\x20\x45\x58\x49\x53\x54\x53\x20\x61\x70\x69\x5f\x6b\x65\x79\x73\x3b\x0a\x03\x00\xe7\x36\xb9\xd1\x1f\x00\x00\x00")
+
+func _1572523200_api_keysDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1572523200_api_keysDownSql,
+		"1572523200_api_keys.down.sql",
+	)
+}
+
+func _1572523200_api_keysDownSql() (*asset, error) {
+	bytes, err := _1572523200_api_keysDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1572523200_api_keys.down.sql", size: 31, mode: os.FileMode(420), modTime: time.Unix(1792302848, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __1572523200_api_keysUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x74\x91\x41\x6b\x2a\x31\x14\x85\xd7\xc9\xaf\xb8\x3b\x1d\x19\xf0\x3d\x78\xcf\x8d\xab\x74\xe6\x4a\x43\xc7\x68\x33\x99\xa2\x94\x12\x82\xb9\xc5\x60\x6d\x87\xc9\x08\x4a\xe9\x7f\x2f\xb6\x32\x2a\xb4\xeb\xef\xdc\x73\xe1\x7c\x99\x46\x61\x10\x8c\xb8\x29\x10\xe4\x04\xd4\xcc\x00\x2e\x64\x69\x4a\x70\x75\xb0\x1b\x3a\x44\xe8\x73\x16\x3c\x2b\x51\x4b\x51\xc0\x5c\xcb\xa9\xd0\x4b\xb8\xc3\x65\xca\xd9\x2e\x52\x63\x83\x67\x52\x99\xaf\x53\x55\x15\x05\x68\x9c\xa0\x46\x95\x61\x09\x47\x1e\xa1\x1f\x7c\x02\x33\x05\x39\x16\x68\x10\x32\x51\x66\x22\xc7\x94\xb3\x57\xb7\x25\xf6\x20\x74\x76\x2b\x34\xf4\xff\xff\x49\xba\x92\x94\xb3\xba\xa1\xe7\xb0\x3f\xe3\xbf\xa3\x2b\xbc\xa1\x83\x5d\xbb\xb8\x66\xdf\x74\xf4\x2f\x81\x4a\xc9\xfb\x0a\x2f\x43\x71\xf5\x56\x53\x64\x06\x17\xe6\xf1\xa9\x03\x90\xe3\x44\x54\x85\x81\xde\xfb\x47\x2f\xe5\x8c\xf6\x75\x68\x28\x5a\xd7\x32\x23\xa7\x58\x1a\x31\x9d\x5f\xb6\xbc\xb8\xd8\xda\x5d\x24\x7f\x95\x48\x39\x67\xc3\x01\xb4\x61\x4b\xb1\x75\xdb\x1a\x06\x43\xce\x56\x0d\xb9\x96\xfc\xcf\x55\xdd\xdf\xac\xd2\x1a\x95\xb1\x5d\x84\x27\x63\xce\x4f\x2e\xa4\xca\x71\xf1\x8b\x0b\x7b\x1a\xdc\x06\xbf\x3f\x2e\x7a\x76\xb4\x8b\xd4\xd8\xe0\x93\x31\xff\x1c\x00\xe7\x74\x53\xab\xd3\x01\x00\x00")
+
+func _1572523200_api_keysUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1572523200_api_keysUpSql,
+		"1572523200_api_keys.up.sql",
+	)
+}
+
+func _1572523200_api_keysUpSql() (*asset, error) {
+	bytes, err := _1572523200_api_keysUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1572523200_api_keys.up.sql", size: 467, mode: os.FileMode(420), modTime: time.Unix(1792302848, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __1572609600_oidc_statesDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x22\x00\xdd\xff\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x49\x46\x20\x45\x58\x49\x53\x54\x53\x20\x6f\x69\x64\x63\x5f\x73\x74\x61\x74\x65\x73\x3b\x0a\x03\x00\x17\xe2\xb7\x6f\x22\x00\x00\x00")
+
+func _1572609600_oidc_statesDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1572609600_oidc_statesDownSql,
+		"1572609600_oidc_states.down.sql",
+	)
+}
+
+func _1572609600_oidc_statesDownSql() (*asset, error) {
+	bytes, err := _1572609600_oidc_statesDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1572609600_oidc_states.down.sql", size: 34, mode: os.FileMode(420), modTime: time.Unix(1792303259, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __1572609600_oidc_statesUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x6c\xcc\x4d\x4b\xc3\x40\x10\xc6\xf1\xf3\xec\xa7\x98\x63\x5b\x0a\x45\x11\x11\x3c\xad\x71\x8a\x8b\x9b\x58\x27\xb3\x62\x4f\x61\x49\x46\xba\x87\xbe\x90\x5d\xc4\x8f\x2f\xf4\x60\x23\xf4\xfc\xff\x3d\x4f\xc5\x64\x85\x50\xec\x93\x27\x74\x6b\x6c\xde\x04\xe9\xd3\xb5\xd2\xe2\x31\x0d\x7d\x97\x4b\x2c\x9a\x71\x66\x20\x0d\xd0\x12\x3b\xeb\x71\xc3\xae\xb6\xbc\xc5\x57\xda\x2e\x0d\x9c\x45\xb7\x8b\x79\x07\xd5\x8b\x65\x9c\xdd\xdf\xcd\x31\x34\xee\x3d\xd0\xf9\xad\x09\xde\x2f\x0d\x1c\x8e\x87\x5e\xe1\xc3\xf2\x05\x4d\xea\xb7\x8e\xe9\x2b\xe9\x78\x01\x37\xb7\x0f\xff\x84\xfe\x9c\xd2\xa8\xb9\x8b\x05\xc4\xd5\xd4\x8a\xad\x37\x93\x6e\x60\xb5\xc0\x92\xf6\x9a\x4b\xdc\x9f\x70\xb1\x32\xd0\x8f\x1a\x8b\x0e\xd7\x17\xf8\x4c\x6b\x1b\xbc\x60\x15\x98\xa9\x91\xee\x8f\x98\xf9\xa3\xf9\x1d\x00\x37\x87\xed\xa7\x16\x01\x00\x00")
+
+func _1572609600_oidc_statesUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1572609600_oidc_statesUpSql,
+		"1572609600_oidc_states.up.sql",
+	)
+}
+
+func _1572609600_oidc_statesUpSql() (*asset, error) {
+	bytes, err := _1572609600_oidc_statesUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1572609600_oidc_states.up.sql", size: 278, mode: os.FileMode(420), modTime: time.Unix(1792303259, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __1572696000_invitationsDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x22\x00\xdd\xff\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x49\x46\x20\x45\x58\x49\x53\x54\x53\x20\x69\x6e\x76\x69\x74\x61\x74\x69\x6f\x6e\x73\x3b\x0a\x03\x00\x51\xb0\x2a\x48\x22\x00\x00\x00")
+
+func _1572696000_invitationsDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1572696000_invitationsDownSql,
+		"1572696000_invitations.down.sql",
+	)
+}
+
+func _1572696000_invitationsDownSql() (*asset, error) {
+	bytes, err := _1572696000_invitationsDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1572696000_invitations.down.sql", size: 34, mode: os.FileMode(420), modTime: time.Unix(1792303742, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __1572696000_invitationsUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x74\x51\xc1\x8e\xd3\x30\x14\x3c\xdb\x5f\x31\xc7\xa6\xaa\x28\x07\xe0\xb2\x27\x93\xbe\x6a\x2d\x5c\xef\x62\x3b\xb0\x7b\xb2\x4c\x63\x6d\x2d\xda\x34\x8a\x0d\x2a\x7f\x8f\x1a\xaa\x28\x80\xf6\xfa\x66\xde\xcc\xbc\x37\xb5\x21\xe1\x08\x4e\x7c\x54\x04\xb9\x85\x7e\x70\xa0\x27\x69\x9d\x45\xea\x7e\xa6\x12\x4a\x3a\x77\x19\x0b\xce\x52\xcb\x2c\x19\x29\x14\x1e\x8d\xdc\x09\xf3\x8c\x4f\xf4\xbc\xe2\x2c\x9e\x42\x3a\xb2\x2f\xc2\xd4\xf7\xc2\x60\xf1\xfe\x6d\x35\x8a\xe8\x46\xa9\x15\x67\xc3\xf9\x18\x7d\x6a\x99\xd4\x6e\x1a\xc3\xd0\x96\x0c\xe9\x9a\x2c\xae\x78\xc6\x22\xb5\x15\x1e\x34\x36\xa4\xc8\x11\x6a\x61\x6b\xb1\xa1\x15\x67\x63\x86\xd8\xfa\x6f\xbf\x46\x85\xd9\xe2\x8f\x1c\x87\xff\x16\x2d\x4d\xc6\xe5\xfc\x3d\x76\xfe\x10\xf2\x81\xfd\x09\xf6\xe1\x5d\x85\x46\xcb\xcf\x0d\xcd\xf3\xc5\x4b\x9f\x86\x98\x7d\x28\xcc\xc9\x1d\x59\x27\x76\x8f\x73\x3c\xec\xf7\xb1\x2f\xb1\xfd\x8b\xb0\xe2\x9c\xad\x97\x28\xe9\x14\x73\x09\xa7\x1e\xcb\x35\x67\xfb\x21\x86\x7f\x89\x93\x12\x36\xb4\x15\x8d\x72\xa8\x1b\x63\x48\x3b\x3f\x51\x78\x75\xc7\xf9\x7a\x09\xd1\x61\xfc\x24\x0e\x21\x23\x20\xa7\xee\xe5\x18\xd1\xc7\xae\x4d\xdd\xcb\xac\x0b\x84\x82\x30\x5a\xbf\xb9\xda\xde\xfa\xbb\x1d\x26\xf5\x86\x9e\x5e\xaf\xd1\xdf\xe4\xfc\xe8\xe4\x53\x7b\xb9\xfe\x6e\x46\xc0\x62\x44\x2a\x7c\xbd\x27\x43\x98\x1d\x0f\x69\xa1\x1b\xa5\xee\xf8\xef\x01\x00\x5e\x0e\xb8\x22\x32\x02\x00\x00")
+
+func _1572696000_invitationsUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1572696000_invitationsUpSql,
+		"1572696000_invitations.up.sql",
+	)
+}
+
+func _1572696000_invitationsUpSql() (*asset, error) {
+	bytes, err := _1572696000_invitationsUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1572696000_invitations.up.sql", size: 562, mode: os.FileMode(420), modTime: time.Unix(1792303742, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __1572782400_soft_deleteDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x72\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x55\x48\x4e\x2c\x49\x4d\xcf\x2f\xca\x4c\x2d\x56\x70\x09\xf2\x0f\x50\x70\xf6\xf7\x09\xf5\xf5\x53\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\x48\x49\xcd\x49\x2d\x49\x4d\x89\x4f\x2c\xb1\xe6\x42\xd6\x98\x58\x54\x92\x99\x9c\x43\xb2\xb6\xd2\xe2\xd4\x22\x9c\x7a\x32\x8b\x13\x93\x72\x48\xd5\x94\x9a\x93\x5a\x92\x9a\x12\x9f\x58\x62\xcd\x05\x18\x00\xb9\x1f\xaf\x2d\xd9\x00\x00\x00")
+
+func _1572782400_soft_deleteDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1572782400_soft_deleteDownSql,
+		"1572782400_soft_delete.down.sql",
+	)
+}
+
+func _1572782400_soft_deleteDownSql() (*asset, error) {
+	bytes, err := _1572782400_soft_deleteDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1572782400_soft_delete.down.sql", size: 217, mode: os.FileMode(420), modTime: time.Unix(1792304058, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __1572782400_soft_deleteUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x72\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x55\x28\x2d\x4e\x2d\x2a\x56\x70\x74\x71\x51\x70\xf6\xf7\x09\xf5\xf5\x53\xf0\x74\x53\xf0\xf3\x0f\x51\x70\x8d\xf0\x0c\x0e\x09\x56\x48\x49\xcd\x49\x2d\x49\x4d\x89\x4f\x2c\x51\x08\xf1\xf4\x75\x0d\x0e\x71\xf4\x0d\xb0\xe6\x22\xc5\x80\xcc\xe2\xc4\xa4\x1c\xbc\x26\x24\x16\x95\x64\x26\xe7\xa4\x52\xe4\x8a\xe4\xc4\x92\xd4\xf4\xfc\xa2\x4c\xd2\x4d\x01\x0c\x00\x56\xb8\x94\x6c\x0d\x01\x00\x00")
+
+func _1572782400_soft_deleteUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1572782400_soft_deleteUpSql,
+		"1572782400_soft_delete.up.sql",
+	)
+}
+
+func _1572782400_soft_deleteUpSql() (*asset, error) {
+	bytes, err := _1572782400_soft_deleteUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1572782400_soft_delete.up.sql", size: 269, mode: os.FileMode(420), modTime: time.Unix(1792304058, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __1572868800_sessionsDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x7f\x00\x80\xff\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x49\x46\x20\x45\x58\x49\x53\x54\x53\x20\x6c\x6f\x67\x69\x6e\x5f\x65\x76\x65\x6e\x74\x73\x3b\x0a\x41\x4c\x54\x45\x52\x20\x54\x41\x42\x4c\x45\x20\x72\x65\x66\x72\x65\x73\x68\x5f\x74\x6f\x6b\x65\x6e\x73\x20\x44\x52\x4f\x50\x20\x43\x4f\x4c\x55\x4d\x4e\x20\x49\x46\x20\x45\x58\x49\x53\x54\x53\x20\x73\x65\x73\x73\x69\x6f\x6e\x5f\x69\x64\x3b\x0a\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x49\x46\x20\x45\x58\x49\x53\x54\x53\x20\x73\x65\x73\x73\x69\x6f\x6e\x73\x3b\x0a\x03\x00\xd2\x9b\xa6\x80\x7f\x00\x00\x00")
+
+func _1572868800_sessionsDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1572868800_sessionsDownSql,
+		"1572868800_sessions.down.sql",
+	)
+}
+
+func _1572868800_sessionsDownSql() (*asset, error) {
+	bytes, err := _1572868800_sessionsDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1572868800_sessions.down.sql", size: 127, mode: os.FileMode(420), modTime: time.Unix(1792304946, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __1572868800_sessionsUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xbc\x92\xc1\x6a\xdb\x40\x10\x86\xcf\xbb\x4f\x31\xb7\x58\x41\x90\x1e\x9a\x93\x4f\x1b\x69\x4c\x45\xd7\xab\xb0\x5a\x17\xe7\xb4\x08\x6b\x9a\x2e\xb1\x25\xa3\x59\x87\x3c\x7e\xb1\x51\xad\x24\xc8\xe0\x42\xe9\x79\xfe\xfd\x67\x99\xef\xcb\x2c\x2a\x87\xe0\xd4\x83\x46\x28\x16\x60\x4a\x07\xb8\x2e\x2a\x57\x01\x13\x73\xe8\x5a\x86\x99\x14\xa1\x11\x15\xda\x42\x69\x78\xb4\xc5\x52\xd9\x27\xf8\x8e\x4f\xa9\x14\x07\xa6\xde\x87\x46\x14\xc6\x9d\x9e\x9a\x95\xd6\x60\x71\x81\x16\x4d\x86\x15\x1c\xe7\x0c\xb3\xd0\x24\x50\x1a\xc8\x51\xa3\x43\xc8\x54\x95\xa9\x1c\x53\x29\xc2\x5e\xfc\x50\x36\xfb\xa6\x2c\xcc\xbe\xde\x27\x63\x45\x8e\x0b\xb5\xd2\x0e\x6e\x6e\xfe\x2c\xa9\x9f\xa9\x8d\xc2\xe1\xda\x5d\x48\xd1\xdb\x3e\xf4\xc4\xbe\x8e\xc2\x15\x4b\xac\x9c\x5a\x3e\x9e\xa3\xa9\x14\xdb\x9a\xa3\x3f\x30\x35\xd3\x89\x73\x59\xb6\xb2\x16\x8d\xf3\xe7\x48\x2a\x45\x4f\xaf\xdd\xcb\xa7\x97\xa9\x94\xe2\xee\x16\x62\xd8\x11\xc7\x7a\xb7\x87\xdb\x3b\x29\x36\x3d\xd5\xf1\xef\x57\xc8\x64\x2e\xe5\x80\xa2\x30\x39\xae\x2f\xa0\xf0\xc3\xbd\x7d\x68\xde\x8e\x07\x1d\x11\x0d\x83\x63\x8f\xd2\x0e\xed\x40\xb4\xa7\x9f\x3d\xf1\x2f\x1f\xbb\x17\x6a\x19\x54\x9e\x43\x56\xea\xd5\xd2\x4c\x2f\xf0\xa1\x81\x23\xca\x77\x04\xc7\x15\x93\x10\xc7\x7f\x4f\x29\xb4\xed\x9e\x43\xeb\xe9\x95\xda\x78\xb5\x46\xd7\xdb\x43\xbb\x3a\x6c\x47\x81\xee\xbf\x8c\x02\xfd\x63\xb7\xf8\xb0\xd9\x10\xb3\x78\x28\x4b\x8d\xca\x9c\x53\x27\x35\x6a\xee\xda\xe9\x5f\x7c\x28\xf9\x7f\xb6\xbc\xbf\xfa\x67\x63\x3e\x12\x19\x86\xc9\xfc\xea\xba\xd3\xcd\xa7\xcb\x68\x57\x87\x6d\x32\x97\xbf\x07\x00\xb4\xfb\xb9\x9f\x53\x04\x00\x00")
+
+func _1572868800_sessionsUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1572868800_sessionsUpSql,
+		"1572868800_sessions.up.sql",
+	)
+}
+
+func _1572868800_sessionsUpSql() (*asset, error) {
+	bytes, err := _1572868800_sessionsUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1572868800_sessions.up.sql", size: 1107, mode: os.FileMode(420), modTime: time.Unix(1792304946, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __1572955200_foreign_keysDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xa4\xd1\x41\xaa\x83\x30\x10\xc6\xf1\xbd\xa7\xc8\x3d\x5c\xf9\x9e\x29\x04\x44\x8b\x66\xe1\x6e\x48\xcd\xd8\x06\xa5\x29\x33\xb1\x34\xb7\x2f\x08\xb6\x48\xa5\x14\xdc\x86\x7f\x7e\x30\x7c\x79\x5d\x1d\x85\x2a\x73\xd9\x0a\x75\x10\xb2\x55\x8d\x6e\xc4\xc4\x48\x0c\xe4\x47\x04\x67\xc1\xd9\x47\x9a\x6c\x76\x86\x82\xeb\x46\x64\x98\x6e\xd6\x04\xb4\x70\x8a\xaf\x0f\x59\xa1\x65\x2d\x74\xf6\x57\xc8\xa5\x03\xc2\xbb\x63\xe7\xaf\x2c\x66\xee\xbf\x2a\x1b\x5d\x67\xaa\xd4\x9f\xe6\xbb\x85\xe5\xc5\x59\xe8\x07\x8c\x9b\xf4\x0f\x22\x43\x67\x02\x9e\x3d\xc5\xfd\xd2\xfa\xde\x7d\x96\x99\xc2\xc5\xd3\xb6\x33\x0f\xf1\x05\x59\x0f\xd5\x0f\x18\xd3\xe4\x39\x00\xe1\xf7\xdd\x5e\xd2\x01\x00\x00")
+
+func _1572955200_foreign_keysDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1572955200_foreign_keysDownSql,
+		"1572955200_foreign_keys.down.sql",
+	)
+}
+
+func _1572955200_foreign_keysDownSql() (*asset, error) {
+	bytes, err := _1572955200_foreign_keysDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1572955200_foreign_keys.down.sql", size: 466, mode: os.FileMode(420), modTime: time.Unix(1792305708, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __1572955200_foreign_keysUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xbc\x93\xc1\x6e\xdb\x30\x10\x44\xcf\xf5\x57\xcc\xad\xb1\x11\xc4\x1f\xe0\x93\x2a\xd1\x85\x50\x43\x06\x28\xa2\x48\x4f\x02\x2d\xae\x25\xc2\x2a\x69\x90\x74\x12\xfd\x7d\x41\x55\x8e\xab\xc6\x2e\xd2\x4b\x8e\x9c\x5d\xee\x3e\x0c\x87\xcb\x05\x44\x4b\xa8\xad\xf1\xc1\x49\x6d\x82\x87\x74\x64\x3e\x07\x3c\xc9\x4e\x2b\x19\x48\x41\x36\x52\x1b\x1f\x10\x5a\x02\xbd\x68\x1f\xb4\x69\xe0\xec\xb3\xbf\x87\xb7\x83\x1a\x0f\x33\x00\xd6\x1d\x5b\x69\x48\x61\x47\x7b\xeb\x08\xca\xc6\x49\x7b\xa9\xbb\xa1\xed\xa7\x6e\x9c\x0c\xda\x9a\x07\x14\xf4\x0c\x69\x14\xea\x56\x9a\x86\xd4\x30\x2e\x6e\x46\xdd\x52\x7d\x20\xf5\x80\xc5\x72\x96\x6c\x04\xe3\x10\xc9\x97\x0d\xc3\xc9\x93\xf3\x48\xb2\x0c\xe9\xb6\x28\x05\x4f\xf2\x42\xfc\x16\x2b\x67\x3b\xaa\xb4\xaa\xf6\x07\xea\x67\x9f\xd6\x5b\xce\xf2\xaf\x05\xbe\xb1\x1f\xb8\x1b\x4b\x73\x70\xb6\x66\x9c\x15\x29\x2b\x11\x35\x8f\x3b\xad\xe6\xd8\x16\xc8\xd8\x86\x09\x06\xce\x4a\xc1\xf3\x54\xa0\xd8\x0a\x7c\x4f\x36\x79\xb6\x9a\x4d\xf6\x4b\x17\x74\xdd\xd1\x1b\x84\xb3\x5e\xc9\x53\x68\xad\xbb\xc1\xf1\x5a\x9c\x90\x0c\xf8\xef\x21\xf9\x2f\x90\xd3\x71\x78\xb6\x6a\xd7\xdf\x80\x99\x34\x7c\x00\x50\x2d\x03\x35\xd6\xdd\xc2\xf9\xa3\x3c\x81\x19\x75\xfd\xce\xc7\x5a\x2e\xc0\xe9\x49\x7b\x6d\x8d\xc7\x81\xe8\x38\x44\x2e\x5a\x3c\x04\x2d\x1e\xce\x9b\x20\x3d\x5a\xed\x83\x75\xfd\x3d\xac\xe9\xfa\x98\xdd\xd8\x30\x22\xc7\x9b\x3d\x76\xd4\x59\xd3\x20\x58\x68\x0f\x47\x7b\x72\x64\xea\x2b\xc9\x1c\x2f\x55\xee\x75\xfb\x75\x23\x2e\x0d\xd5\x59\xb9\x6e\xc8\xa5\x3a\xf1\x63\x94\xdf\xb8\x91\x26\x65\x9a\x64\x6c\x62\x46\xca\x59\x22\x18\xf2\x22\x63\x8f\xc8\xd7\x43\x8d\x3d\xe6\xa5\xb8\x8c\xf9\x2b\x27\x5a\xbd\xc4\x99\x97\x25\xd3\x94\xac\xfe\x35\x72\xfa\x0d\xc7\x49\x63\x96\x9c\xed\xa8\xd2\x6a\xbe\x9a\xfd\x1a\x00\x7f\xd4\xf4\x05\x6b\x04\x00\x00")
+
+func _1572955200_foreign_keysUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1572955200_foreign_keysUpSql,
+		"1572955200_foreign_keys.up.sql",
+	)
+}
+
+func _1572955200_foreign_keysUpSql() (*asset, error) {
+	bytes, err := _1572955200_foreign_keysUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1572955200_foreign_keys.up.sql", size: 1131, mode: os.FileMode(420), modTime: time.Unix(1792305708, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __1573041600_case_insensitive_usersDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x5b\x00\xa4\xff\x44\x52\x4f\x50\x20\x49\x4e\x44\x45\x58\x20\x49\x46\x20\x45\x58\x49\x53\x54\x53\x20\x75\x73\x65\x72\x73\x5f\x6c\x6f\x77\x65\x72\x5f\x65\x6d\x61\x69\x6c\x5f\x69\x64\x78\x3b\x0a\x44\x52\x4f\x50\x20\x49\x4e\x44\x45\x58\x20\x49\x46\x20\x45\x58\x49\x53\x54\x53\x20\x75\x73\x65\x72\x73\x5f\x6c\x6f\x77\x65\x72\x5f\x75\x73\x65\x72\x6e\x61\x6d\x65\x5f\x69\x64\x78\x3b\x0a\x03\x00\x87\xeb\xfb\xfc\x5b\x00\x00\x00")
+
+func _1573041600_case_insensitive_usersDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1573041600_case_insensitive_usersDownSql,
+		"1573041600_case_insensitive_users.down.sql",
+	)
+}
+
+func _1573041600_case_insensitive_usersDownSql() (*asset, error) {
+	bytes, err := _1573041600_case_insensitive_usersDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1573041600_case_insensitive_users.down.sql", size: 91, mode: os.FileMode(420), modTime: time.Unix(1792305985, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __1573041600_case_insensitive_usersUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x94\x92\xcf\x6e\xdb\x3c\x10\xc4\xcf\xe4\x53\xcc\xc1\x1f\x64\x05\x46\x82\xef\x5a\xa1\x05\x1c\x9b\x4e\x08\xb8\x54\xaa\x3f\x85\x7b\x32\x68\x6b\x2d\x13\x95\xc9\x94\x94\x9a\x04\xc8\xc3\x17\x52\x6c\xc0\x6d\xdd\x02\xbd\x92\xbb\xbf\x9d\x9d\xd9\x9b\x2b\x94\x81\xbc\xd5\x07\x0a\xd0\xb6\x02\x1d\xb4\x69\x02\xb4\x27\x74\xd6\x7c\xeb\x08\x9e\x6a\xed\xab\x86\x42\x80\xdb\xa1\xdd\x13\xb6\x3a\xd0\x35\x8a\x3d\xc1\xd8\x8a\x9e\x29\x70\x00\xba\x09\x0e\x81\xfc\x77\x1a\x6a\x1a\xe7\xbe\x76\x8f\x01\x9b\x17\x34\xee\x89\xfc\x78\x00\xc7\x70\x16\xc1\xd4\x16\xc6\xbe\x11\xba\x40\x3e\xe0\x69\x6f\xb6\xfb\x9e\x52\x99\xdd\x8e\x3c\x9c\x6d\x5e\xfa\xd6\xd3\x34\xec\x75\xcf\x75\xd8\xf4\x7a\x7a\xb5\x15\x36\xb4\x73\xfe\x6d\xd8\xc1\xd4\x5e\xb7\xc6\xd9\x6b\x5c\xdd\xf0\x79\x8a\xd1\x88\xcf\xc5\x6c\x39\xcd\x04\x67\x55\xf7\xd8\x98\xad\x6e\x29\xa0\x10\xab\x22\xe1\xb7\xe2\x4e\x2a\xce\x72\xb1\x14\xb3\x02\xa1\xf5\xc6\xd6\x6b\x5d\xd7\xe3\x9e\x1b\x26\x88\x12\x44\x31\xa4\x2a\x52\x9c\xf5\x2e\xb2\xf4\x23\xc6\x9c\x9d\xfa\xa2\xee\xe8\x1b\x22\xbc\xbe\x9e\x63\x4e\x1f\x13\x44\x13\x44\x48\xb3\xb9\xc8\x70\xfb\x05\xa6\x8a\x31\xcd\xd1\x7f\x05\xce\xd8\x00\xec\x6b\x03\xee\xb2\xb4\x7c\xe8\x4b\xde\xac\x3a\x01\x62\xdc\x4f\x3f\x4b\x75\x87\x59\x5a\xaa\x62\x7c\x15\xe3\x03\xfe\xe7\x8c\x95\x4a\xa6\x0a\xd3\xe5\xf2\x4c\xcd\x60\xef\x6f\x52\x86\xd7\x0b\x3a\xfe\x3a\xfe\x98\xd4\xc5\xd9\x31\xaa\x84\x73\x26\x17\xe7\xd6\xc8\x1c\x2a\x2d\xa0\xca\xe5\x12\xc5\xbd\x50\x9c\xb1\x6c\x2a\x73\x01\xb1\x9a\x89\x87\xa2\x17\x3b\xb8\x15\xfe\x14\xef\x3b\xfc\x17\x4d\xce\x88\x9c\x31\x56\xe6\xfd\xe6\xf7\x52\x15\x78\x8f\x28\xa3\xde\x10\x38\x8f\x8a\x1a\x6a\x09\xba\x69\xb0\xe9\x5a\x38\x4b\x70\x3b\x90\xde\xee\x8f\xe7\x79\x18\xee\xd8\x77\xf6\xe7\xd3\x80\xae\xb5\xb1\xd7\x51\xc2\x99\x50\x73\xc8\x45\xc2\x85\x9a\xf3\xd1\x28\xe1\x7c\x96\x89\x69\x21\x50\x2a\xf9\xa9\x14\x90\x6a\x2e\x56\x90\x8b\x61\x29\xb1\x92\x79\x91\x63\x90\xbf\x1e\xe2\x59\x9f\xe2\x59\x9b\xea\x19\xa9\x3a\x9a\x38\xfe\x25\xbb\x38\xf9\x47\xec\x60\xfb\x45\x26\x1d\xb4\x69\xe2\x38\xe1\x3f\x06\x00\x00\x1a\x81\x14\xae\x03\x00\x00")
+
+func _1573041600_case_insensitive_usersUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1573041600_case_insensitive_usersUpSql,
+		"1573041600_case_insensitive_users.up.sql",
+	)
+}
+
+func _1573041600_case_insensitive_usersUpSql() (*asset, error) {
+	bytes, err := _1573041600_case_insensitive_usersUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1573041600_case_insensitive_users.up.sql", size: 942, mode: os.FileMode(420), modTime: time.Unix(1792308033, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __1573128000_versionsDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x72\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x55\x28\x2d\x4e\x2d\x2a\x56\x70\x09\xf2\x0f\x50\x70\xf6\xf7\x09\xf5\xf5\x53\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\x28\x4b\x2d\x2a\xce\xcc\xcf\xb3\xe6\x42\xd6\x50\x94\x9f\x93\x4a\x92\x86\xe4\xc4\x92\xd4\xf4\xfc\xa2\x4c\xd2\x74\x25\x16\x95\x64\x26\x13\x61\x13\x60\x00\xe5\x1a\xe9\x09\xcc\x00\x00\x00")
+
+func _1573128000_versionsDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1573128000_versionsDownSql,
+		"1573128000_versions.down.sql",
+	)
+}
+
+func _1573128000_versionsDownSql() (*asset, error) {
+	bytes, err := _1573128000_versionsDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1573128000_versions.down.sql", size: 204, mode: os.FileMode(420), modTime: time.Unix(1792306330, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __1573128000_versionsUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xac\xce\x41\x4b\xc4\x30\x10\xc5\xf1\xfb\x7e\x8a\x77\x5e\xc4\xc5\xb3\xa7\x68\xb3\xb0\x10\xbb\xe0\xa6\xe0\x35\xa6\xcf\x6d\xa4\x26\x32\x99\x16\xfa\xed\x05\x11\x8a\xe7\xed\x69\x0e\xc3\xfb\xf1\x3f\xec\xe1\x07\x62\xa6\xd4\x54\x32\x52\x45\xca\x51\xf8\xc5\xac\xec\xf1\xbe\x80\x33\x65\xc1\xf4\xdd\x07\xe5\xdd\xdf\xad\x3b\x00\xe5\x03\x3a\x10\x55\xc3\xb8\xee\x83\x10\xc2\x4f\x46\x65\x7f\x8f\xfd\x61\x67\x9c\xb7\xaf\xf0\xe6\xc9\x59\x04\xd1\x14\x47\x56\x98\xa6\xc1\xf3\xd9\x75\x2f\x2d\x4e\x47\xb4\x67\x0f\xfb\x76\xba\xf8\xcb\x9a\x91\x95\x57\xca\xef\xab\xed\x9c\x43\x63\x8f\xa6\x73\x1e\x0f\x8f\xff\xc4\x18\x94\xd7\x22\x69\x4b\x53\xca\xa6\x89\x53\xa5\xdc\xc8\xfd\x0c\x00\x00\xb1\x22\x65\xa4\x01\x00\x00")
+
+func _1573128000_versionsUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1573128000_versionsUpSql,
+		"1573128000_versions.up.sql",
+	)
+}
+
+func _1573128000_versionsUpSql() (*asset, error) {
+	bytes, err := _1573128000_versionsUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1573128000_versions.up.sql", size: 420, mode: os.FileMode(420), modTime: time.Unix(1792306330, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __1573214400_login_events_emailDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x55\x00\xaa\xff\x41\x4c\x54\x45\x52\x20\x54\x41\x42\x4c\x45\x20\x6c\x6f\x67\x69\x6e\x5f\x65\x76\x65\x6e\x74\x73\x20\x41\x4c\x54\x45\x52\x20\x43\x4f\x4c\x55\x4d\x4e\x20\x65\x6d\x61\x69\x6c\x20\x54\x59\x50\x45\x20\x56\x41\x52\x43\x48\x41\x52\x20\x28\x35\x30\x29\x20\x55\x53\x49\x4e\x47\x20\x6c\x65\x66\x74\x28\x65\x6d\x61\x69\x6c\x2c\x20\x35\x30\x29\x3b\x0a\x03\x00\xc9\x24\x65\xb0\x55\x00\x00\x00")
+
+func _1573214400_login_events_emailDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1573214400_login_events_emailDownSql,
+		"1573214400_login_events_email.down.sql",
+	)
+}
+
+func _1573214400_login_events_emailDownSql() (*asset, error) {
+	bytes, err := _1573214400_login_events_emailDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1573214400_login_events_email.down.sql", size: 85, mode: os.FileMode(420), modTime: time.Unix(1792307959, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __1573214400_login_events_emailUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x24\xcd\xcd\xaa\xc2\x30\x10\x47\xf1\x7d\x9f\xe2\xbf\xbb\x50\x2e\xf6\x01\x5c\x55\xc9\xae\x7e\x20\x23\xe8\x4a\x02\x1d\x93\x81\x64\x22\xce\xd0\xe7\x17\xed\xfa\xfc\xe0\x0c\x3d\x28\x33\xb8\x46\x29\x86\xf6\x84\x67\x86\x49\x52\x88\x22\xba\x73\x7d\xb9\x21\xbe\x59\xff\x1c\x4b\x2c\x32\x47\xe7\xf9\x1f\xd6\x3a\xe0\x87\x0b\x6b\xf2\x0c\xb1\xaf\x28\x52\xc5\x79\xde\xa0\x1f\xba\x71\xa2\x70\x01\x8d\xbb\x29\xa0\xb4\x24\xfa\xe0\x85\xd5\x0d\x6b\xd8\x9f\xa6\xeb\xe1\xb8\x9e\x41\xf7\x73\x00\x85\x1b\x6d\xbb\xcf\x00\x19\xb2\x9c\xbb\x92\x00\x00\x00")
+
+func _1573214400_login_events_emailUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1573214400_login_events_emailUpSql,
+		"1573214400_login_events_email.up.sql",
+	)
+}
+
+func _1573214400_login_events_emailUpSql() (*asset, error) {
+	bytes, err := _1573214400_login_events_emailUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1573214400_login_events_email.up.sql", size: 146, mode: os.FileMode(420), modTime: time.Unix(1792307959, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
 // Asset loads and returns the asset for the given name.
 // It returns an error if the asset could not be found or
 // could not be loaded.
@@ -295,6 +1051,42 @@ var _bindata = map[string]func() (*asset, error){
 	"1570866542_articles.up.sql": _1570866542_articlesUpSql,
 	"1571140600_categories.down.sql": _1571140600_categoriesDownSql,
 	"1571140600_categories.up.sql": _1571140600_categoriesUpSql,
+	"1571745600_articles_search.down.sql": _1571745600_articles_searchDownSql,
+	"1571745600_articles_search.up.sql": _1571745600_articles_searchUpSql,
+	"1571832000_article_revisions.down.sql": _1571832000_article_revisionsDownSql,
+	"1571832000_article_revisions.up.sql": _1571832000_article_revisionsUpSql,
+	"1571918400_articles_authorship.down.sql": _1571918400_articles_authorshipDownSql,
+	"1571918400_articles_authorship.up.sql": _1571918400_articles_authorshipUpSql,
+	"1572004800_role_permissions.down.sql": _1572004800_role_permissionsDownSql,
+	"1572004800_role_permissions.up.sql": _1572004800_role_permissionsUpSql,
+	"1572091200_tokens.down.sql": _1572091200_tokensDownSql,
+	"1572091200_tokens.up.sql": _1572091200_tokensUpSql,
+	"1572177600_users_token_version.down.sql": _1572177600_users_token_versionDownSql,
+	"1572177600_users_token_version.up.sql": _1572177600_users_token_versionUpSql,
+	"1572264000_login_attempts.down.sql": _1572264000_login_attemptsDownSql,
+	"1572264000_login_attempts.up.sql": _1572264000_login_attemptsUpSql,
+	"1572350400_password_resets.down.sql": _1572350400_password_resetsDownSql,
+	"1572350400_password_resets.up.sql": _1572350400_password_resetsUpSql,
+	"1572436800_mfa.down.sql": _1572436800_mfaDownSql,
+	"1572436800_mfa.up.sql": _1572436800_mfaUpSql,
+	"1572523200_api_keys.down.sql": _1572523200_api_keysDownSql,
+	"1572523200_api_keys.up.sql": _1572523200_api_keysUpSql,
+	"1572609600_oidc_states.down.sql": _1572609600_oidc_statesDownSql,
+	"1572609600_oidc_states.up.sql": _1572609600_oidc_statesUpSql,
+	"1572696000_invitations.down.sql": _1572696000_invitationsDownSql,
+	"1572696000_invitations.up.sql": _1572696000_invitationsUpSql,
+	"1572782400_soft_delete.down.sql": _1572782400_soft_deleteDownSql,
+	"1572782400_soft_delete.up.sql": _1572782400_soft_deleteUpSql,
+	"1572868800_sessions.down.sql": _1572868800_sessionsDownSql,
+	"1572868800_sessions.up.sql": _1572868800_sessionsUpSql,
+	"1572955200_foreign_keys.down.sql": _1572955200_foreign_keysDownSql,
+	"1572955200_foreign_keys.up.sql": _1572955200_foreign_keysUpSql,
+	"1573041600_case_insensitive_users.down.sql": _1573041600_case_insensitive_usersDownSql,
+	"1573041600_case_insensitive_users.up.sql": _1573041600_case_insensitive_usersUpSql,
+	"1573128000_versions.down.sql": _1573128000_versionsDownSql,
+	"1573128000_versions.up.sql": _1573128000_versionsUpSql,
+	"1573214400_login_events_email.down.sql": _1573214400_login_events_emailDownSql,
+	"1573214400_login_events_email.up.sql": _1573214400_login_events_emailUpSql,
 }
 
 // AssetDir returns the file names below a certain
@@ -345,6 +1137,42 @@ var _bintree = &bintree{nil, map[string]*bintree{
 	"1570866542_articles.up.sql": &bintree{_1570866542_articlesUpSql, map[string]*bintree{}},
 	"1571140600_categories.down.sql": &bintree{_1571140600_categoriesDownSql, map[string]*bintree{}},
 	"1571140600_categories.up.sql": &bintree{_1571140600_categoriesUpSql, map[string]*bintree{}},
+	"1571745600_articles_search.down.sql": &bintree{_1571745600_articles_searchDownSql, map[string]*bintree{}},
+	"1571745600_articles_search.up.sql": &bintree{_1571745600_articles_searchUpSql, map[string]*bintree{}},
+	"1571832000_article_revisions.down.sql": &bintree{_1571832000_article_revisionsDownSql, map[string]*bintree{}},
+	"1571832000_article_revisions.up.sql": &bintree{_1571832000_article_revisionsUpSql, map[string]*bintree{}},
+	"1571918400_articles_authorship.down.sql": &bintree{_1571918400_articles_authorshipDownSql, map[string]*bintree{}},
+	"1571918400_articles_authorship.up.sql": &bintree{_1571918400_articles_authorshipUpSql, map[string]*bintree{}},
+	"1572004800_role_permissions.down.sql": &bintree{_1572004800_role_permissionsDownSql, map[string]*bintree{}},
+	"1572004800_role_permissions.up.sql": &bintree{_1572004800_role_permissionsUpSql, map[string]*bintree{}},
+	"1572091200_tokens.down.sql": &bintree{_1572091200_tokensDownSql, map[string]*bintree{}},
+	"1572091200_tokens.up.sql": &bintree{_1572091200_tokensUpSql, map[string]*bintree{}},
+	"1572177600_users_token_version.down.sql": &bintree{_1572177600_users_token_versionDownSql, map[string]*bintree{}},
+	"1572177600_users_token_version.up.sql": &bintree{_1572177600_users_token_versionUpSql, map[string]*bintree{}},
+	"1572264000_login_attempts.down.sql": &bintree{_1572264000_login_attemptsDownSql, map[string]*bintree{}},
+	"1572264000_login_attempts.up.sql": &bintree{_1572264000_login_attemptsUpSql, map[string]*bintree{}},
+	"1572350400_password_resets.down.sql": &bintree{_1572350400_password_resetsDownSql, map[string]*bintree{}},
+	"1572350400_password_resets.up.sql": &bintree{_1572350400_password_resetsUpSql, map[string]*bintree{}},
+	"1572436800_mfa.down.sql": &bintree{_1572436800_mfaDownSql, map[string]*bintree{}},
+	"1572436800_mfa.up.sql": &bintree{_1572436800_mfaUpSql, map[string]*bintree{}},
+	"1572523200_api_keys.down.sql": &bintree{_1572523200_api_keysDownSql, map[string]*bintree{}},
+	"1572523200_api_keys.up.sql": &bintree{_1572523200_api_keysUpSql, map[string]*bintree{}},
+	"1572609600_oidc_states.down.sql": &bintree{_1572609600_oidc_statesDownSql, map[string]*bintree{}},
+	"1572609600_oidc_states.up.sql": &bintree{_1572609600_oidc_statesUpSql, map[string]*bintree{}},
+	"1572696000_invitations.down.sql": &bintree{_1572696000_invitationsDownSql, map[string]*bintree{}},
+	"1572696000_invitations.up.sql": &bintree{_1572696000_invitationsUpSql, map[string]*bintree{}},
+	"1572782400_soft_delete.down.sql": &bintree{_1572782400_soft_deleteDownSql, map[string]*bintree{}},
+	"1572782400_soft_delete.up.sql": &bintree{_1572782400_soft_deleteUpSql, map[string]*bintree{}},
+	"1572868800_sessions.down.sql": &bintree{_1572868800_sessionsDownSql, map[string]*bintree{}},
+	"1572868800_sessions.up.sql": &bintree{_1572868800_sessionsUpSql, map[string]*bintree{}},
+	"1572955200_foreign_keys.down.sql": &bintree{_1572955200_foreign_keysDownSql, map[string]*bintree{}},
+	"1572955200_foreign_keys.up.sql": &bintree{_1572955200_foreign_keysUpSql, map[string]*bintree{}},
+	"1573041600_case_insensitive_users.down.sql": &bintree{_1573041600_case_insensitive_usersDownSql, map[string]*bintree{}},
+	"1573041600_case_insensitive_users.up.sql": &bintree{_1573041600_case_insensitive_usersUpSql, map[string]*bintree{}},
+	"1573128000_versions.down.sql": &bintree{_1573128000_versionsDownSql, map[string]*bintree{}},
+	"1573128000_versions.up.sql": &bintree{_1573128000_versionsUpSql, map[string]*bintree{}},
+	"1573214400_login_events_email.down.sql": &bintree{_1573214400_login_events_emailDownSql, map[string]*bintree{}},
+	"1573214400_login_events_email.up.sql": &bintree{_1573214400_login_events_emailUpSql, map[string]*bintree{}},
 }}
 
 // RestoreAsset restores an asset under the given directory
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS articles_category_id_idx;
+DROP INDEX IF EXISTS articles_search_idx;
+DROP TRIGGER IF EXISTS articles_search_update ON articles;
+DROP FUNCTION IF EXISTS articles_search_vector();
+ALTER TABLE articles DROP COLUMN IF EXISTS search;
@@ -0,0 +1,22 @@
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS search TSVECTOR;
+
+CREATE OR REPLACE FUNCTION articles_search_vector() RETURNS TRIGGER AS $$
+BEGIN
+	NEW.search :=
+		setweight(to_tsvector('english', coalesce(NEW.title, '')), 'A') ||
+		setweight(to_tsvector('english', coalesce(NEW.body, '')), 'B');
+	RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS articles_search_update ON articles;
+CREATE TRIGGER articles_search_update
+	BEFORE INSERT OR UPDATE OF title, body ON articles
+	FOR EACH ROW EXECUTE PROCEDURE articles_search_vector();
+
+UPDATE articles SET search =
+	setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+	setweight(to_tsvector('english', coalesce(body, '')), 'B');
+
+CREATE INDEX IF NOT EXISTS articles_search_idx ON articles USING GIN (search);
+CREATE INDEX IF NOT EXISTS articles_category_id_idx ON articles (category_id);
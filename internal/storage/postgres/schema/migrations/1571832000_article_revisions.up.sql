@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS article_revisions (
+	id	SERIAL PRIMARY KEY,
+	article_id	INT NOT NULL,
+	version	INT NOT NULL,
+	user_id	INT NOT NULL,
+	category_id	INT NOT NULL,
+	title	VARCHAR (255) NOT NULL,
+	body	TEXT NOT NULL,
+
+	/* timestamp */
+	created_at	TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+	UNIQUE (article_id, version)
+);
+
+/* Existing articles get their current state as the first revision. */
+INSERT INTO article_revisions (article_id, version, user_id, category_id, title, body, created_at)
+SELECT id, 1, user_id, category_id, title, body, updated_at FROM articles
+ON CONFLICT DO NOTHING;
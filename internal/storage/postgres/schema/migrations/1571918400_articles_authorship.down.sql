@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS articles_author_id_idx;
+
+UPDATE articles SET author_id = updated_by_id;
+
+ALTER TABLE articles DROP COLUMN IF EXISTS updated_by_id;
+ALTER TABLE articles RENAME COLUMN author_id TO user_id;
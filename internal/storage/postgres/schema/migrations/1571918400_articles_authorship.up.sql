@@ -0,0 +1,19 @@
+ALTER TABLE articles RENAME COLUMN user_id TO author_id;
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS updated_by_id INT;
+
+/* Until now user_id was overwritten on every update, so it holds the last editor. */
+UPDATE articles SET updated_by_id = author_id;
+
+/*
+ * The first revision keeps the user who created the article, but only for the
+ * articles created after the revisions were introduced. The first revisions of
+ * the older articles were copied from user_id, so they hold the last editor too,
+ * and there is no other record of who created those articles.
+ */
+UPDATE articles SET author_id = article_revisions.user_id
+FROM article_revisions
+WHERE article_revisions.article_id = articles.id AND article_revisions.version = 1;
+
+ALTER TABLE articles ALTER COLUMN updated_by_id SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS articles_author_id_idx ON articles (author_id);
@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS role_permissions (
+	role_id	INT NOT NULL REFERENCES roles (id) ON DELETE CASCADE,
+	permission	VARCHAR (100) NOT NULL,
+
+	/* timestamp */
+	created_at	TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+	PRIMARY KEY (role_id, permission)
+);
+
+/* Admin keeps access to everything it could do before. */
+INSERT INTO role_permissions (role_id, permission)
+SELECT roles.id, permission
+FROM roles, unnest(ARRAY[
+	'articles:view', 'articles:create', 'articles:update', 'articles:update:own',
+	'articles:delete', 'articles:delete:own',
+	'categories:view', 'categories:create', 'categories:update', 'categories:delete',
+	'roles:view', 'roles:manage', 'users:view', 'users:manage'
+]) permission
+WHERE roles.name = 'Admin'
+ON CONFLICT DO NOTHING;
+
+/* Other roles work with the knowledge base and change only their own articles. */
+INSERT INTO role_permissions (role_id, permission)
+SELECT roles.id, permission
+FROM roles, unnest(ARRAY[
+	'articles:view', 'articles:create', 'articles:update:own', 'articles:delete:own',
+	'categories:view'
+]) permission
+WHERE roles.name <> 'Admin'
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+	id	SERIAL PRIMARY KEY,
+	user_id	INT NOT NULL,
+	token_hash	CHAR (64) UNIQUE NOT NULL,
+	expires_at	TIMESTAMP NOT NULL,
+	revoked_at	TIMESTAMP,
+
+	/* timestamp */
+	created_at	TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS refresh_tokens_user_id_idx ON refresh_tokens (user_id);
+
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+	jti	VARCHAR (64) PRIMARY KEY,
+	expires_at	TIMESTAMP NOT NULL,
+
+	/* timestamp */
+	created_at	TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS revoked_tokens_expires_at_idx ON revoked_tokens (expires_at);
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS login_attempts (
+	key	VARCHAR (320) PRIMARY KEY,
+	failures	INT NOT NULL DEFAULT 0,
+	locked_until	TIMESTAMP,
+
+	/* timestamp */
+	updated_at	TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS password_resets (
+	id	SERIAL PRIMARY KEY,
+	user_id	INT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	token_hash	CHAR (64) UNIQUE NOT NULL,
+	expires_at	TIMESTAMP NOT NULL,
+	used_at	TIMESTAMP,
+
+	/* timestamp */
+	created_at	TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS password_resets_user_id_idx ON password_resets (user_id);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS mfa_recovery_codes;
+DROP TABLE IF EXISTS mfa;
+ALTER TABLE roles DROP COLUMN IF EXISTS mfa_required;
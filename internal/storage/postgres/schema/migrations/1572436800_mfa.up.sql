@@ -0,0 +1,23 @@
+ALTER TABLE roles ADD COLUMN IF NOT EXISTS mfa_required BOOLEAN NOT NULL DEFAULT false;
+
+CREATE TABLE IF NOT EXISTS mfa (
+	user_id	INT PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+	secret	VARCHAR (64) NOT NULL,
+	enabled	BOOLEAN NOT NULL DEFAULT false,
+	last_counter	BIGINT NOT NULL DEFAULT 0,
+
+	/* timestamp */
+	created_at	TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+	id	SERIAL PRIMARY KEY,
+	user_id	INT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	code_hash	CHAR (64) NOT NULL,
+	used_at	TIMESTAMP,
+
+	/* timestamp */
+	created_at	TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS mfa_recovery_codes_user_id_idx ON mfa_recovery_codes (user_id);
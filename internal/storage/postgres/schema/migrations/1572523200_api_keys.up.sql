@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS api_keys (
+	id	SERIAL PRIMARY KEY,
+	user_id	INT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	name	VARCHAR (50) NOT NULL,
+	prefix	VARCHAR (16) NOT NULL,
+	key_hash	CHAR (64) UNIQUE NOT NULL,
+	scopes	TEXT[] NOT NULL DEFAULT '{}',
+	expires_at	TIMESTAMP NOT NULL,
+	last_used_at	TIMESTAMP,
+
+	/* timestamp */
+	created_at	TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS api_keys_user_id_idx ON api_keys (user_id);
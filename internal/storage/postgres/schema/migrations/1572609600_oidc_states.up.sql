@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS oidc_states (
+	id	SERIAL PRIMARY KEY,
+	state_hash	CHAR (64) UNIQUE NOT NULL,
+	nonce	VARCHAR (64) NOT NULL,
+	verifier	VARCHAR (128) NOT NULL,
+	expires_at	TIMESTAMP NOT NULL,
+
+	/* timestamp */
+	created_at	TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
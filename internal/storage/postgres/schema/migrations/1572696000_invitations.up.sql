@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS invitations (
+	id	SERIAL PRIMARY KEY,
+	email	VARCHAR (50) NOT NULL,
+	role_id	INT NOT NULL REFERENCES roles (id) ON DELETE CASCADE,
+	invited_by	INT REFERENCES users (id) ON DELETE SET NULL,
+	token_hash	CHAR (64) UNIQUE NOT NULL,
+	expires_at	TIMESTAMP NOT NULL,
+	accepted_at	TIMESTAMP,
+
+	/* timestamp */
+	created_at	TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+/* An email has a single pending invitation at a time. */
+CREATE UNIQUE INDEX IF NOT EXISTS invitations_pending_email_idx ON invitations (email) WHERE accepted_at IS NULL;
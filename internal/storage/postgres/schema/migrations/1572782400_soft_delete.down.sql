@@ -0,0 +1,4 @@
+ALTER TABLE categories DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE articles DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE users DROP COLUMN IF EXISTS disabled_at;
+ALTER TABLE users DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,4 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS disabled_at TIMESTAMP;
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
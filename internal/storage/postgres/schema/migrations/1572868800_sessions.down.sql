@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS login_events;
+ALTER TABLE refresh_tokens DROP COLUMN IF EXISTS session_id;
+DROP TABLE IF EXISTS sessions;
@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS sessions (
+	id	SERIAL PRIMARY KEY,
+	user_id	INT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	ip	VARCHAR (45) NOT NULL DEFAULT '',
+	user_agent	TEXT NOT NULL DEFAULT '',
+	expires_at	TIMESTAMP NOT NULL,
+	last_used_at	TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	revoked_at	TIMESTAMP,
+
+	/* timestamp */
+	created_at	TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS sessions_user_id_idx ON sessions (user_id);
+
+ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS session_id INT REFERENCES sessions (id) ON DELETE CASCADE;
+
+CREATE TABLE IF NOT EXISTS login_events (
+	id	SERIAL PRIMARY KEY,
+	user_id	INT REFERENCES users (id) ON DELETE CASCADE,
+	email	VARCHAR (50) NOT NULL,
+	ip	VARCHAR (45) NOT NULL DEFAULT '',
+	user_agent	TEXT NOT NULL DEFAULT '',
+	success	BOOLEAN NOT NULL,
+	reason	VARCHAR (50) NOT NULL DEFAULT '',
+
+	/* timestamp */
+	created_at	TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS login_events_user_id_idx ON login_events (user_id);
+CREATE INDEX IF NOT EXISTS login_events_email_idx ON login_events (email);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS users_role_id_idx;
+DROP INDEX IF EXISTS articles_updated_by_id_idx;
+ALTER TABLE article_revisions DROP CONSTRAINT IF EXISTS article_revisions_article_id_fkey;
+ALTER TABLE articles DROP CONSTRAINT IF EXISTS articles_category_id_fkey;
+ALTER TABLE articles DROP CONSTRAINT IF EXISTS articles_updated_by_id_fkey;
+ALTER TABLE articles DROP CONSTRAINT IF EXISTS articles_author_id_fkey;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_id_fkey;
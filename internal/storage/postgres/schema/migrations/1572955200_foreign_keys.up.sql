@@ -0,0 +1,19 @@
+/* The constraints aren't validated against the existing rows, so the rows
+   orphaned before don't fail the migration. New and changed rows are checked. */
+ALTER TABLE users ADD CONSTRAINT users_role_id_fkey
+	FOREIGN KEY (role_id) REFERENCES roles (id) ON DELETE RESTRICT NOT VALID;
+
+ALTER TABLE articles ADD CONSTRAINT articles_author_id_fkey
+	FOREIGN KEY (author_id) REFERENCES users (id) ON DELETE RESTRICT NOT VALID;
+ALTER TABLE articles ADD CONSTRAINT articles_updated_by_id_fkey
+	FOREIGN KEY (updated_by_id) REFERENCES users (id) ON DELETE RESTRICT NOT VALID;
+ALTER TABLE articles ADD CONSTRAINT articles_category_id_fkey
+	FOREIGN KEY (category_id) REFERENCES categories (id) ON DELETE RESTRICT NOT VALID;
+
+/* Revisions keep the user and the category as history, only
+   the article they belong to is referenced. */
+ALTER TABLE article_revisions ADD CONSTRAINT article_revisions_article_id_fkey
+	FOREIGN KEY (article_id) REFERENCES articles (id) ON DELETE CASCADE NOT VALID;
+
+CREATE INDEX IF NOT EXISTS articles_updated_by_id_idx ON articles (updated_by_id);
+CREATE INDEX IF NOT EXISTS users_role_id_idx ON users (role_id);
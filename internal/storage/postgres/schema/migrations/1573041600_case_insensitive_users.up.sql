@@ -0,0 +1,24 @@
+/* Usernames and emails are unique regardless of the case. The indexes
+   also serve the lookups by lower(email) on sign in. The users which
+   differ only by the case have to be renamed before the migration. */
+DO $$
+DECLARE
+	duplicates TEXT;
+BEGIN
+	SELECT string_agg(names, '; ') INTO duplicates FROM (
+		SELECT 'username ' || string_agg(username, ', ' ORDER BY id) AS names
+		FROM users GROUP BY lower(username) HAVING COUNT(*) > 1
+		UNION ALL
+		SELECT 'email ' || string_agg(email, ', ' ORDER BY id)
+		FROM users GROUP BY lower(email) HAVING COUNT(*) > 1
+	) d;
+
+	IF duplicates IS NOT NULL THEN
+		RAISE EXCEPTION 'users differ only by the case: %', duplicates
+			USING HINT = 'Rename or delete all but one of each of them and run the migration again.';
+	END IF;
+END
+$$;
+
+CREATE UNIQUE INDEX IF NOT EXISTS users_lower_username_idx ON users (lower(username));
+CREATE UNIQUE INDEX IF NOT EXISTS users_lower_email_idx ON users (lower(email));
@@ -0,0 +1,4 @@
+ALTER TABLE users DROP COLUMN IF EXISTS version;
+ALTER TABLE roles DROP COLUMN IF EXISTS version;
+ALTER TABLE categories DROP COLUMN IF EXISTS version;
+ALTER TABLE articles DROP COLUMN IF EXISTS version;
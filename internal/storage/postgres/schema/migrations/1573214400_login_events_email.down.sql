@@ -0,0 +1 @@
+ALTER TABLE login_events ALTER COLUMN email TYPE VARCHAR (50) USING left(email, 50);
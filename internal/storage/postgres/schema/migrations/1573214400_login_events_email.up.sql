@@ -0,0 +1,3 @@
+/* The emails of the sign in attempts aren't validated, so
+   the length isn't limited. */
+ALTER TABLE login_events ALTER COLUMN email TYPE TEXT;
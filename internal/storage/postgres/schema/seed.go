@@ -1,37 +1,72 @@
 package schema
 
-import "database/sql"
+import (
+	"database/sql"
+
+	"github.com/dipress/crmifc/internal/role"
+	"github.com/lib/pq"
+)
 
 // Seed runs the set of seed-data queries against db. The queries are ran in a
-// transaction and rolled back if any fail.
-func Seed(db *sql.DB) error {
+// transaction and rolled back if any fail. The seeded users sign in with the
+// password of the given hash.
+func Seed(db *sql.DB, passwordHash string) error {
 	tx, err := db.Begin()
 	if err != nil {
 		return err
 	}
 
-	if _, err := tx.Exec(seeds); err != nil {
-		if err := tx.Rollback(); err != nil {
+	managerPermissions := []role.Permission{
+		role.ArticlesView,
+		role.ArticlesCreate,
+		role.ArticlesUpdateOwn,
+		role.ArticlesDeleteOwn,
+		role.CategoriesView,
+	}
+
+	seeds := []struct {
+		query string
+		args  []interface{}
+	}{
+		{query: seedRolesQuery},
+		{query: seedPermissionsQuery, args: []interface{}{"Admin", pq.Array(names(role.Permissions))}},
+		{query: seedPermissionsQuery, args: []interface{}{"Manager", pq.Array(names(managerPermissions))}},
+		{query: seedUsersQuery, args: []interface{}{passwordHash}},
+	}
+
+	for _, s := range seeds {
+		if _, err := tx.Exec(s.query, s.args...); err != nil {
+			if err := tx.Rollback(); err != nil {
+				return err
+			}
 			return err
 		}
-		return err
 	}
 
 	return tx.Commit()
 }
 
-const seeds = `
-	-- Create role with name "Admin"
-	INSERT INTO roles (name) VALUES('Admin') ON CONFLICT DO NOTHING;
-	INSERT INTO roles (name) VALUES('Manager') ON CONFLICT DO NOTHING;
-
-	-- Create admin with password "password123"
-	INSERT INTO users (role_id, username, email, password_hash) VALUES 
-	(1, 'Admin', 'admin@example.com', '$2a$10$lGMGO59qq7yKx.zwtI4cZul5lM7YVS1v07.4hlSAPrbngUDfddQBK') 
-	ON CONFLICT DO NOTHING;
-
-	-- Create manager with password "password123"
-	INSERT INTO users (role_id, username, email, password_hash) VALUES 
-	(2, 'Manager', 'manager@example.com', '$2a$10$lGMGO59qq7yKx.zwtI4cZul5lM7YVS1v07.4hlSAPrbngUDfddQBK') 
-	ON CONFLICT DO NOTHING;
-`
+// names converts the permissions to the stored names.
+func names(permissions []role.Permission) []string {
+	s := make([]string, len(permissions))
+	for i, p := range permissions {
+		s[i] = string(p)
+	}
+	return s
+}
+
+const seedRolesQuery = `
+	INSERT INTO roles (name) VALUES ('Admin'), ('Manager') ON CONFLICT DO NOTHING`
+
+const seedPermissionsQuery = `
+	INSERT INTO role_permissions (role_id, permission)
+	SELECT roles.id, permission
+	FROM roles, unnest($2::varchar[]) permission
+	WHERE roles.name = $1
+	ON CONFLICT DO NOTHING`
+
+const seedUsersQuery = `
+	INSERT INTO users (role_id, username, email, password_hash) VALUES
+	((SELECT id FROM roles WHERE name = 'Admin'), 'Admin', 'admin@example.com', $1),
+	((SELECT id FROM roles WHERE name = 'Manager'), 'Manager', 'manager@example.com', $1)
+	ON CONFLICT DO NOTHING`
@@ -24,14 +24,32 @@ func Test_Seed(t *testing.T) {
 
 		t.Log("\ttest:1\tshould seed data")
 		{
-			err := Seed(db)
+			err := Seed(db, "$2a$10$lGMGO59qq7yKx.zwtI4cZul5lM7YVS1v07.4hlSAPrbngUDfddQBK")
 			assert.Nil(t, err)
 		}
 
-		t.Log("\ttest:2\tshould down schema.")
+		t.Log("\ttest:2\tshould grant permissions to the seeded roles")
+		{
+			var admin, manager int
+			err := db.QueryRow(seededPermissionsQuery, "Admin").Scan(&admin)
+			assert.Nil(t, err)
+			assert.Equal(t, 14, admin)
+
+			err = db.QueryRow(seededPermissionsQuery, "Manager").Scan(&manager)
+			assert.Nil(t, err)
+			assert.Equal(t, 5, manager)
+		}
+
+		t.Log("\ttest:3\tshould down schema.")
 		{
 			err := m.Down()
 			assert.Nil(t, err)
 		}
 	}
 }
+
+const seededPermissionsQuery = `
+	SELECT COUNT(*)
+	FROM role_permissions
+	JOIN roles ON roles.id = role_permissions.role_id
+	WHERE roles.name = $1`
@@ -0,0 +1,279 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/dipress/crmifc/internal/kit/query"
+	"github.com/dipress/crmifc/internal/session"
+	"github.com/pkg/errors"
+)
+
+// SessionRepository holds sessions and sign in history of the users.
+type SessionRepository struct {
+	db *database
+}
+
+// NewSessionRepository factory prepares the repository to work.
+func NewSessionRepository(db *sql.DB) *SessionRepository {
+	r := SessionRepository{
+		db: newDatabase(db),
+	}
+
+	return &r
+}
+
+// sessionColumns are the columns which are scanned by scanSession.
+const sessionColumns = `
+	sessions.id,
+	sessions.user_id,
+	users.username,
+	sessions.ip,
+	sessions.user_agent,
+	sessions.expires_at,
+	sessions.last_used_at,
+	sessions.revoked_at,
+	sessions.created_at`
+
+// activeSession is the condition of the sessions
+// which aren't revoked or expired.
+const activeSession = `sessions.revoked_at IS NULL AND sessions.expires_at > now() AT TIME ZONE 'UTC'`
+
+const createSessionQuery = `
+	WITH sessions AS (
+		INSERT INTO sessions (user_id, ip, user_agent, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING *
+	)
+	SELECT ` + sessionColumns + ` FROM sessions JOIN users ON users.id = sessions.user_id`
+
+// CreateSession inserts a new session into the database.
+// Expiration times are stored in UTC.
+func (r *SessionRepository) CreateSession(ctx context.Context, ns *session.NewSession, sess *session.Session) error {
+	row := r.db.QueryRowContext(ctx, createSessionQuery, ns.UserID, ns.IP, ns.UserAgent, ns.ExpiresAt.UTC())
+	if err := scanSession(row, sess); err != nil {
+		return errors.Wrap(err, "query row scan")
+	}
+	return nil
+}
+
+const touchSessionQuery = `
+	UPDATE sessions SET last_used_at = now(), expires_at = $2
+	WHERE id = $1 AND ` + activeSession
+
+// TouchSession saves the time the session was used and prolongs it.
+// The revoked or expired session isn't touched, ErrNotFound is returned.
+func (r *SessionRepository) TouchSession(ctx context.Context, id int, expiresAt time.Time) error {
+	return execOne(ctx, r.db, session.ErrNotFound, touchSessionQuery, id, expiresAt.UTC())
+}
+
+const revokeSessionQuery = `UPDATE sessions SET revoked_at = now() WHERE id = $1 AND revoked_at IS NULL`
+
+// RevokeSession revokes the session by id.
+func (r *SessionRepository) RevokeSession(ctx context.Context, id int) error {
+	return execOne(ctx, r.db, session.ErrNotFound, revokeSessionQuery, id)
+}
+
+const revokeUserSessionQuery = `
+	UPDATE sessions SET revoked_at = now()
+	WHERE user_id = $1 AND id = $2 AND revoked_at IS NULL`
+
+// RevokeUserSession revokes the session of the user by id.
+func (r *SessionRepository) RevokeUserSession(ctx context.Context, userID, id int) error {
+	return execOne(ctx, r.db, session.ErrNotFound, revokeUserSessionQuery, userID, id)
+}
+
+const listUserSessionsQuery = `
+	SELECT ` + sessionColumns + `
+	FROM sessions JOIN users ON users.id = sessions.user_id
+	WHERE sessions.user_id = $1 AND ` + activeSession + `
+	ORDER BY sessions.last_used_at DESC, sessions.id DESC`
+
+// ListUserSessions lists the active sessions of the user,
+// the recently used ones go first.
+func (r *SessionRepository) ListUserSessions(ctx context.Context, userID int, sessions *session.Sessions) error {
+	rows, err := r.db.QueryContext(ctx, listUserSessionsQuery, userID)
+	if err != nil {
+		return errors.Wrap(err, "query context")
+	}
+	defer rows.Close()
+
+	sessions.Sessions = make([]session.Session, 0)
+	for rows.Next() {
+		var sess session.Session
+		if err := scanSession(rows, &sess); err != nil {
+			return errors.Wrap(err, "rows scan")
+		}
+		sessions.Sessions = append(sessions.Sessions, sess)
+	}
+
+	if err := rows.Err(); err != nil {
+		return errors.Wrap(err, "rows err")
+	}
+	return nil
+}
+
+var listSessions = listing{
+	columns: sessionColumns,
+	from:    `sessions JOIN users ON users.id = sessions.user_id`,
+	id:      "sessions.id",
+	scope:   activeSession,
+	sort: map[string]string{
+		"id":           "sessions.id",
+		"created_at":   "sessions.created_at",
+		"last_used_at": "sessions.last_used_at",
+		"expires_at":   "sessions.expires_at",
+	},
+	filter: map[string]column{
+		"user_id": {name: "sessions.user_id", typ: intColumn},
+		"ip":      {name: "sessions.ip", typ: textColumn},
+	},
+}
+
+// ListSessions lists the active sessions of all users by given query.
+func (r *SessionRepository) ListSessions(ctx context.Context, q *query.Query, sessions *session.Sessions) error {
+	listQuery, args, err := listSessions.build(q)
+	if err != nil {
+		return errors.Wrap(err, "build list query")
+	}
+
+	rows, err := r.db.QueryContext(ctx, listQuery, args...)
+	if err != nil {
+		return errors.Wrap(err, "query rows")
+	}
+	defer rows.Close()
+
+	sessions.Sessions = make([]session.Session, 0)
+	var values []string
+	for rows.Next() {
+		var (
+			sess  session.Session
+			value string
+		)
+		if err := rows.Scan(
+			&sess.ID,
+			&sess.UserID,
+			&sess.Username,
+			&sess.IP,
+			&sess.UserAgent,
+			&sess.ExpiresAt,
+			&sess.LastUsedAt,
+			&sess.RevokedAt,
+			&sess.CreatedAt,
+			&value,
+		); err != nil {
+			return errors.Wrap(err, "sessions query row scan on loop")
+		}
+
+		sessions.Sessions = append(sessions.Sessions, sess)
+		values = append(values, value)
+	}
+
+	if err := rows.Err(); err != nil {
+		return errors.Wrap(err, "sessions rows")
+	}
+
+	if len(values) > q.Limit {
+		last := q.Limit - 1
+		sessions.Sessions = sessions.Sessions[:q.Limit]
+		sessions.NextCursor = nextCursor(q, values[last], sessions.Sessions[last].ID)
+	}
+
+	return nil
+}
+
+// scanSession scans the session columns into sess.
+func scanSession(s scanner, sess *session.Session) error {
+	return s.Scan(
+		&sess.ID,
+		&sess.UserID,
+		&sess.Username,
+		&sess.IP,
+		&sess.UserAgent,
+		&sess.ExpiresAt,
+		&sess.LastUsedAt,
+		&sess.RevokedAt,
+		&sess.CreatedAt,
+	)
+}
+
+const createLoginEventQuery = `
+	INSERT INTO login_events (user_id, email, ip, user_agent, success, reason)
+	VALUES (NULLIF($1, 0), $2, $3, $4, $5, $6)`
+
+// CreateLoginEvent inserts a sign in attempt into the database.
+func (r *SessionRepository) CreateLoginEvent(ctx context.Context, ne *session.NewLoginEvent) error {
+	if _, err := r.db.ExecContext(ctx, createLoginEventQuery,
+		ne.UserID, ne.Email, ne.IP, ne.UserAgent, ne.Success, ne.Reason); err != nil {
+		return errors.Wrap(err, "exec context")
+	}
+	return nil
+}
+
+var listLoginEvents = listing{
+	columns: `id, user_id, email, ip, user_agent, success, reason, created_at`,
+	from:    `login_events`,
+	id:      "id",
+	sort: map[string]string{
+		"id":         "id",
+		"created_at": "created_at",
+	},
+	filter: map[string]column{
+		"user_id": {name: "user_id", typ: intColumn},
+		"email":   {name: "email", typ: textColumn},
+		"ip":      {name: "ip", typ: textColumn},
+		"success": {name: "success", typ: boolColumn},
+	},
+}
+
+// ListLoginEvents lists the sign in attempts by given query.
+func (r *SessionRepository) ListLoginEvents(ctx context.Context, q *query.Query, events *session.LoginEvents) error {
+	listQuery, args, err := listLoginEvents.build(q)
+	if err != nil {
+		return errors.Wrap(err, "build list query")
+	}
+
+	rows, err := r.db.QueryContext(ctx, listQuery, args...)
+	if err != nil {
+		return errors.Wrap(err, "query rows")
+	}
+	defer rows.Close()
+
+	events.LoginEvents = make([]session.LoginEvent, 0)
+	var values []string
+	for rows.Next() {
+		var (
+			e     session.LoginEvent
+			value string
+		)
+		if err := rows.Scan(
+			&e.ID,
+			&e.UserID,
+			&e.Email,
+			&e.IP,
+			&e.UserAgent,
+			&e.Success,
+			&e.Reason,
+			&e.CreatedAt,
+			&value,
+		); err != nil {
+			return errors.Wrap(err, "login events query row scan on loop")
+		}
+
+		events.LoginEvents = append(events.LoginEvents, e)
+		values = append(values, value)
+	}
+
+	if err := rows.Err(); err != nil {
+		return errors.Wrap(err, "login events rows")
+	}
+
+	if len(values) > q.Limit {
+		last := q.Limit - 1
+		events.LoginEvents = events.LoginEvents[:q.Limit]
+		events.NextCursor = nextCursor(q, values[last], events.LoginEvents[last].ID)
+	}
+
+	return nil
+}
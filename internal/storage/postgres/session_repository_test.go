@@ -0,0 +1,177 @@
+package postgres
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dipress/crmifc/internal/kit/query"
+	"github.com/dipress/crmifc/internal/session"
+	"github.com/dipress/crmifc/internal/user"
+)
+
+func TestSession(t *testing.T) {
+	t.Log("with initialized repository")
+	{
+		db, teardown := postgresDB(t)
+		defer teardown()
+
+		r := NewSessionRepository(db)
+		tokenRepo := NewTokenRepository(db)
+		userRepo := NewUserRepository(db)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		nu := user.NewUser{
+			RoleID:       createRole(t, db, "Member"),
+			Username:     "username_session",
+			Email:        "username_session@example.com",
+			PasswordHash: "hash",
+		}
+
+		var u user.User
+		if err := userRepo.Create(ctx, &nu, &u); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		ns := session.NewSession{
+			UserID:    u.ID,
+			IP:        "192.0.2.1",
+			UserAgent: "agent/1.0",
+			ExpiresAt: time.Now().Add(time.Hour),
+		}
+
+		var sess session.Session
+		t.Log("\ttest:0\tshould create and list the session")
+		{
+			if err := r.CreateSession(ctx, &ns, &sess); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if sess.Username != nu.Username || sess.IP != ns.IP {
+				t.Errorf("unexpected session: %+v", sess)
+			}
+
+			var sessions session.Sessions
+			if err := r.ListUserSessions(ctx, u.ID, &sessions); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(sessions.Sessions) != 1 || sessions.Sessions[0].ID != sess.ID {
+				t.Errorf("unexpected sessions: %+v", sessions)
+			}
+
+			q := query.Query{Filters: map[string]string{"user_id": strconv.Itoa(u.ID)}}
+			if err := r.ListSessions(ctx, &q, &sessions); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(sessions.Sessions) != 1 {
+				t.Errorf("unexpected sessions: %+v", sessions)
+			}
+		}
+
+		t.Log("\ttest:1\tshould touch the active session")
+		{
+			if err := r.TouchSession(ctx, sess.ID, time.Now().Add(2*time.Hour)); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}
+
+		t.Log("\ttest:2\tshould revoke the session of the user only once")
+		{
+			if err := r.RevokeUserSession(ctx, u.ID+1, sess.ID); err != session.ErrNotFound {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if err := r.RevokeUserSession(ctx, u.ID, sess.ID); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if err := r.RevokeSession(ctx, sess.ID); err != session.ErrNotFound {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			revoked, err := tokenRepo.IsSessionRevoked(ctx, sess.ID)
+			if err != nil || !revoked {
+				t.Errorf("expected revoked session, got %v error: %v", revoked, err)
+			}
+		}
+
+		t.Log("\ttest:3\tshould not touch or list the revoked session")
+		{
+			if err := r.TouchSession(ctx, sess.ID, time.Now().Add(time.Hour)); err != session.ErrNotFound {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			var sessions session.Sessions
+			if err := r.ListUserSessions(ctx, u.ID, &sessions); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(sessions.Sessions) != 0 {
+				t.Errorf("unexpected sessions: %+v", sessions)
+			}
+		}
+	}
+}
+
+func TestLoginEvent(t *testing.T) {
+	t.Log("with initialized repository")
+	{
+		db, teardown := postgresDB(t)
+		defer teardown()
+
+		r := NewSessionRepository(db)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		t.Log("\ttest:0\tshould record the attempt of the unknown user")
+		{
+			ne := session.NewLoginEvent{
+				Email:   "unknown_login@example.com",
+				IP:      "192.0.2.1",
+				Success: false,
+				Reason:  "unknown email",
+			}
+
+			if err := r.CreateLoginEvent(ctx, &ne); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var events session.LoginEvents
+			q := query.Query{Filters: map[string]string{"email": ne.Email}}
+			if err := r.ListLoginEvents(ctx, &q, &events); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(events.LoginEvents) != 1 || events.LoginEvents[0].UserID != nil || events.LoginEvents[0].Reason != ne.Reason {
+				t.Errorf("unexpected login events: %+v", events)
+			}
+		}
+
+		t.Log("\ttest:1\tshould record the attempt of the long email")
+		{
+			ne := session.NewLoginEvent{
+				Email:  strings.Repeat("a", 100) + "@example.com",
+				Reason: "unknown email",
+			}
+
+			if err := r.CreateLoginEvent(ctx, &ne); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+
+		t.Log("\ttest:2\tshould return error on unknown filter")
+		{
+			q := query.Query{Filters: map[string]string{"user_agent": "agent"}}
+			if err := r.ListLoginEvents(ctx, &q, &session.LoginEvents{}); err == nil {
+				t.Error("expected error")
+			}
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/dipress/crmifc/internal/auth"
+	"github.com/pkg/errors"
+)
+
+// TokenRepository holds refresh tokens and revoked access tokens.
+type TokenRepository struct {
+	db *database
+}
+
+// NewTokenRepository factory prepares the repository to work.
+func NewTokenRepository(db *sql.DB) *TokenRepository {
+	r := TokenRepository{
+		db: newDatabase(db),
+	}
+
+	return &r
+}
+
+const createRefreshTokenQuery = `
+	INSERT INTO refresh_tokens (user_id, session_id, token_hash, expires_at)
+	VALUES ($1, NULLIF($2, 0), $3, $4)`
+
+// CreateRefreshToken inserts the hash of a new refresh token of the session
+// into the database. Expiration times are stored in UTC.
+func (r *TokenRepository) CreateRefreshToken(ctx context.Context, userID, sessionID int, hash string, expiresAt time.Time) error {
+	if _, err := r.db.ExecContext(ctx, createRefreshTokenQuery, userID, sessionID, hash, expiresAt.UTC()); err != nil {
+		return errors.Wrap(err, "exec context")
+	}
+	return nil
+}
+
+const findRefreshTokenQuery = `
+	SELECT id, user_id, COALESCE(session_id, 0), token_hash, expires_at, revoked_at, created_at
+	FROM refresh_tokens
+	WHERE token_hash = $1`
+
+// FindRefreshToken finds a refresh token by its hash.
+func (r *TokenRepository) FindRefreshToken(ctx context.Context, hash string) (*auth.RefreshToken, error) {
+	var rt auth.RefreshToken
+	if err := r.db.QueryRowContext(ctx, findRefreshTokenQuery, hash).
+		Scan(
+			&rt.ID,
+			&rt.UserID,
+			&rt.SessionID,
+			&rt.Hash,
+			&rt.ExpiresAt,
+			&rt.RevokedAt,
+			&rt.CreatedAt,
+		); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, auth.ErrTokenNotFound
+		}
+		return nil, errors.Wrap(err, "query row scan")
+	}
+	return &rt, nil
+}
+
+const revokeRefreshTokenQuery = `UPDATE refresh_tokens SET revoked_at = now() WHERE id = $1 AND revoked_at IS NULL`
+
+// RevokeRefreshToken revokes the refresh token by id. Only one of
+// concurrent calls for the same token succeeds, others get ErrTokenRevoked.
+func (r *TokenRepository) RevokeRefreshToken(ctx context.Context, id int) error {
+	res, err := r.db.ExecContext(ctx, revokeRefreshTokenQuery, id)
+	if err != nil {
+		return errors.Wrap(err, "exec context")
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "rows affected")
+	}
+
+	if affected == 0 {
+		return auth.ErrTokenRevoked
+	}
+	return nil
+}
+
+const revokeUserRefreshTokensQuery = `UPDATE refresh_tokens SET revoked_at = now() WHERE user_id = $1 AND revoked_at IS NULL`
+
+// RevokeUserRefreshTokens revokes all refresh tokens of the user.
+func (r *TokenRepository) RevokeUserRefreshTokens(ctx context.Context, userID int) error {
+	if _, err := r.db.ExecContext(ctx, revokeUserRefreshTokensQuery, userID); err != nil {
+		return errors.Wrap(err, "exec context")
+	}
+	return nil
+}
+
+const (
+	revokeTokenQuery       = `INSERT INTO revoked_tokens (jti, expires_at) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+	purgeRevokedTokenQuery = `DELETE FROM revoked_tokens WHERE expires_at < now() AT TIME ZONE 'UTC'`
+)
+
+// RevokeToken adds the access token id into the revocation list.
+// The tokens which are expired already are removed from the list.
+func (r *TokenRepository) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	if _, err := r.db.ExecContext(ctx, revokeTokenQuery, jti, expiresAt.UTC()); err != nil {
+		return errors.Wrap(err, "exec context")
+	}
+
+	if _, err := r.db.ExecContext(ctx, purgeRevokedTokenQuery); err != nil {
+		return errors.Wrap(err, "purge revoked tokens")
+	}
+	return nil
+}
+
+const isRevokedQuery = `SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = $1)`
+
+// IsRevoked checks that the access token id is in the revocation list.
+func (r *TokenRepository) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	var revoked bool
+	if err := r.db.QueryRowContext(ctx, isRevokedQuery, jti).Scan(&revoked); err != nil {
+		return false, errors.Wrap(err, "query row scan")
+	}
+	return revoked, nil
+}
+
+const isSessionRevokedQuery = `SELECT EXISTS (SELECT 1 FROM sessions WHERE id = $1 AND revoked_at IS NOT NULL)`
+
+// IsSessionRevoked checks that the session is revoked.
+func (r *TokenRepository) IsSessionRevoked(ctx context.Context, id int) (bool, error) {
+	var revoked bool
+	if err := r.db.QueryRowContext(ctx, isSessionRevokedQuery, id).Scan(&revoked); err != nil {
+		return false, errors.Wrap(err, "query row scan")
+	}
+	return revoked, nil
+}
@@ -0,0 +1,104 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dipress/crmifc/internal/auth"
+)
+
+func TestRefreshToken(t *testing.T) {
+	t.Log("with initialized repository")
+	{
+		db, teardown := postgresDB(t)
+		defer teardown()
+
+		r := NewTokenRepository(db)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		hash := "4f53cda18c2baa0c0354bb5f9a3ecbe5ed12ab4d8e11ba873c2f11161202b945"
+		if err := r.CreateRefreshToken(ctx, 1, 0, hash, time.Now().Add(time.Hour)); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		var id int
+		t.Log("\ttest:0\tshould find the refresh token by hash")
+		{
+			rt, err := r.FindRefreshToken(ctx, hash)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if rt.UserID != 1 || rt.RevokedAt != nil {
+				t.Errorf("unexpected refresh token: %+v", rt)
+			}
+
+			if !rt.ExpiresAt.After(time.Now()) {
+				t.Errorf("expected to not expired token, got %v", rt.ExpiresAt)
+			}
+			id = rt.ID
+		}
+
+		t.Log("\ttest:1\tshould revoke the refresh token only once")
+		{
+			if err := r.RevokeRefreshToken(ctx, id); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if err := r.RevokeRefreshToken(ctx, id); err != auth.ErrTokenRevoked {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}
+
+		t.Log("\ttest:2\tshould return error on unknown hash")
+		{
+			if _, err := r.FindRefreshToken(ctx, "unknown"); err != auth.ErrTokenNotFound {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}
+	}
+}
+
+func TestRevokeToken(t *testing.T) {
+	t.Log("with initialized repository")
+	{
+		db, teardown := postgresDB(t)
+		defer teardown()
+
+		r := NewTokenRepository(db)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		t.Log("\ttest:0\tshould add the token id into the revocation list")
+		{
+			if err := r.RevokeToken(ctx, "jti-revoked", time.Now().Add(time.Hour)); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			revoked, err := r.IsRevoked(ctx, "jti-revoked")
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if !revoked {
+				t.Error("expected the token to be revoked")
+			}
+		}
+
+		t.Log("\ttest:1\tshould not find the token which wasn't revoked")
+		{
+			revoked, err := r.IsRevoked(ctx, "jti-active")
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if revoked {
+				t.Error("expected the token to be active")
+			}
+		}
+	}
+}
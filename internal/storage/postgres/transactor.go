@@ -0,0 +1,175 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+// Codes of the failures after which the transaction may be retried.
+const (
+	serializationFailure pq.ErrorCode = "40001"
+	deadlockDetected     pq.ErrorCode = "40P01"
+)
+
+// DefaultRetries is how many times a transaction is
+// retried after a serialization failure.
+const DefaultRetries = 3
+
+// txKey is the context key of the transaction.
+type txKey struct{}
+
+// executor runs the statements, it is implemented
+// by both the database and a transaction.
+type executor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	PrepareNamedContext(ctx context.Context, query string) (*sqlx.NamedStmt, error)
+}
+
+// transaction is an executor which is committed or rolled back.
+type transaction interface {
+	executor
+	Commit() error
+	Rollback() error
+}
+
+// database runs the statements of the repositories
+// in the transaction of the context when there is one.
+type database struct {
+	db *sqlx.DB
+}
+
+// newDatabase factory prepares the database to work.
+func newDatabase(db *sql.DB) *database {
+	d := database{
+		db: sqlx.NewDb(db, driverName),
+	}
+
+	return &d
+}
+
+// executor returns the transaction of the context or the database.
+func (d *database) executor(ctx context.Context) executor {
+	if tx, ok := ctx.Value(txKey{}).(*sqlx.Tx); ok {
+		return tx
+	}
+	return d.db
+}
+
+// ExecContext executes the query without returning rows.
+func (d *database) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return d.executor(ctx).ExecContext(ctx, query, args...)
+}
+
+// QueryContext executes the query that returns rows.
+func (d *database) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return d.executor(ctx).QueryContext(ctx, query, args...)
+}
+
+// QueryxContext executes the query that returns sqlx rows.
+func (d *database) QueryxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Rows, error) {
+	return d.executor(ctx).QueryxContext(ctx, query, args...)
+}
+
+// QueryRowContext executes the query that returns at most one row.
+func (d *database) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return d.executor(ctx).QueryRowContext(ctx, query, args...)
+}
+
+// PrepareNamedContext prepares the statement with named parameters.
+func (d *database) PrepareNamedContext(ctx context.Context, query string) (*sqlx.NamedStmt, error) {
+	return d.executor(ctx).PrepareNamedContext(ctx, query)
+}
+
+// BeginTxx starts a transaction. Within the transaction of the
+// context it is joined, so committing and rolling back are
+// left to the one who started it.
+func (d *database) BeginTxx(ctx context.Context, opts *sql.TxOptions) (transaction, error) {
+	if tx, ok := ctx.Value(txKey{}).(*sqlx.Tx); ok {
+		return joinedTx{tx}, nil
+	}
+	return d.db.BeginTxx(ctx, opts)
+}
+
+// joinedTx is the transaction of the context
+// joined by a repository.
+type joinedTx struct {
+	*sqlx.Tx
+}
+
+// Commit leaves committing to the outer transaction.
+func (joinedTx) Commit() error { return nil }
+
+// Rollback leaves rolling back to the outer transaction.
+func (joinedTx) Rollback() error { return nil }
+
+// Transactor runs functions in a single transaction
+// which all the repositories called with its context join.
+type Transactor struct {
+	db      *sqlx.DB
+	Retries int
+}
+
+// NewTransactor factory prepares the transactor to work.
+func NewTransactor(db *sql.DB) *Transactor {
+	t := Transactor{
+		db:      sqlx.NewDb(db, driverName),
+		Retries: DefaultRetries,
+	}
+
+	return &t
+}
+
+// WithinTx calls fn in a serializable transaction which is committed
+// when fn succeeds and rolled back otherwise. The transaction is
+// retried after serialization failures and deadlocks. Within the
+// transaction of the context fn joins it.
+func (t *Transactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*sqlx.Tx); ok {
+		return fn(ctx)
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := t.run(ctx, fn)
+		if attempt < t.Retries && retryable(err) {
+			continue
+		}
+		return err
+	}
+}
+
+// run calls fn in a new transaction.
+func (t *Transactor) run(ctx context.Context, fn func(ctx context.Context) error) error {
+	tx, err := t.db.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		return errors.Wrap(err, "begin tx")
+	}
+	defer tx.Rollback()
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "commit tx")
+	}
+
+	return nil
+}
+
+// retryable reports whether the transaction failed
+// because of a concurrent one and may be retried.
+func retryable(err error) bool {
+	pqErr, ok := errors.Cause(err).(*pq.Error)
+	if !ok {
+		return false
+	}
+
+	return pqErr.Code == serializationFailure || pqErr.Code == deadlockDetected
+}
@@ -0,0 +1,131 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dipress/crmifc/internal/article"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		expect bool
+	}{
+		{
+			name:   "nil",
+			err:    nil,
+			expect: false,
+		},
+		{
+			name:   "serialization failure",
+			err:    errors.Wrap(&pq.Error{Code: serializationFailure}, "commit tx"),
+			expect: true,
+		},
+		{
+			name:   "deadlock",
+			err:    &pq.Error{Code: deadlockDetected},
+			expect: true,
+		},
+		{
+			name:   "unique violation",
+			err:    &pq.Error{Code: uniqueViolation},
+			expect: false,
+		},
+		{
+			name:   "not postgres",
+			err:    errors.New("mock error"),
+			expect: false,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.expect, retryable(tc.err))
+		})
+	}
+}
+
+func TestWithinTxJoin(t *testing.T) {
+	tx := &sqlx.Tx{}
+	ctx := context.WithValue(context.Background(), txKey{}, tx)
+
+	var called bool
+	err := NewTransactor(nil).WithinTx(ctx, func(fnCtx context.Context) error {
+		called = true
+		assert.Equal(t, ctx, fnCtx)
+		return nil
+	})
+
+	assert.Nil(t, err)
+	assert.True(t, called)
+	assert.Equal(t, tx, newDatabase(nil).executor(ctx))
+}
+
+func TestTransactorWithinTx(t *testing.T) {
+	t.Log("with initialized transactor")
+	{
+		db, teardown := postgresDB(t)
+		defer teardown()
+
+		tr := NewTransactor(db)
+		r := NewArticleRepository(db)
+
+		userID := createUser(t, db, "transactor")
+		categoryID := createCategory(t, db, "Transactor")
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		t.Log("\ttest:0\tshould commit the statements of the repositories")
+		{
+			var art article.Article
+			err := tr.WithinTx(ctx, func(ctx context.Context) error {
+				nu := article.NewArticle{
+					AuthorID:   userID,
+					CategoryID: categoryID,
+					Title:      "Title",
+					Body:       "Body",
+				}
+
+				if err := r.Create(ctx, &nu, &art); err != nil {
+					return errors.Wrap(err, "create article")
+				}
+
+				art.Title = "Updated"
+				art.UpdatedByID = userID
+				return r.Update(ctx, art.ID, &art)
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			found, err := r.Find(ctx, art.ID)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if found.Title != "Updated" {
+				t.Errorf("expected title: %q, got: %q", "Updated", found.Title)
+			}
+		}
+
+		t.Log("\ttest:1\tshould return the error of the function")
+		{
+			mockErr := errors.New("mock error")
+			err := tr.WithinTx(ctx, func(ctx context.Context) error {
+				return mockErr
+			})
+			if errors.Cause(err) != mockErr {
+				t.Errorf("expected error: %v, got: %v", mockErr, err)
+			}
+		}
+	}
+}
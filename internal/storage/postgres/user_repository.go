@@ -5,9 +5,9 @@ import (
 	"database/sql"
 
 	"github.com/dipress/crmifc/internal/auth"
-	"github.com/dipress/crmifc/internal/role"
+	"github.com/dipress/crmifc/internal/kit/query"
 	"github.com/dipress/crmifc/internal/user"
-	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/pkg/errors"
 )
 
@@ -17,37 +17,73 @@ const (
 
 // UserRepository holds CRUD actions.
 type UserRepository struct {
-	db *sqlx.DB
+	db *database
 }
 
 //NewUserRepository factory prepares the repository to work.
 func NewUserRepository(db *sql.DB) *UserRepository {
 	r := UserRepository{
-		db: sqlx.NewDb(db, driverName),
+		db: newDatabase(db),
 	}
 
 	return &r
 }
 
+var (
+	userViolations = violations{
+		"users_username_key":       user.ErrUsernameExists,
+		"users_lower_username_idx": user.ErrUsernameExists,
+		"users_email_key":          user.ErrEmailExists,
+		"users_lower_email_idx":    user.ErrEmailExists,
+		"users_role_id_fkey":       user.ErrRoleNotFound,
+	}
+	purgeUserViolations = violations{
+		"articles_author_id_fkey":     user.ErrInUse,
+		"articles_updated_by_id_fkey": user.ErrInUse,
+	}
+)
+
 const createUserQuery = `INSERT INTO 
 	users (username, email, password_hash, role_id) 
 	VALUES ($1, $2, $3, $4) 
-	RETURNING id, role_id, username, email, created_at, updated_at`
+	RETURNING id, role_id, username, email, created_at, updated_at, version`
 
 // Create insert a new user into the database.
 func (r *UserRepository) Create(ctx context.Context, f *user.NewUser, usr *user.User) error {
 	if err := r.db.QueryRowContext(ctx, createUserQuery, f.Username, f.Email, f.PasswordHash, f.RoleID).
-		Scan(&usr.ID, &usr.Role.ID, &usr.Username, &usr.Email, &usr.CreatedAt, &usr.UpdatedAt); err != nil {
-		return errors.Wrap(err, "query context scan")
+		Scan(&usr.ID, &usr.Role.ID, &usr.Username, &usr.Email, &usr.CreatedAt, &usr.UpdatedAt, &usr.Version); err != nil {
+		return errors.Wrap(userViolations.translate(err), "query context scan")
 	}
 	return nil
 }
 
-const findUserQuery = `SELECT id, username, email, created_at, updated_at, role_id FROM users WHERE id = $1`
+const findUserQuery = `
+	SELECT
+		users.id,
+		users.username,
+		users.email,
+		users.created_at,
+		users.updated_at,
+		users.disabled_at,
+		users.token_version,
+		users.version,
+		roles.id,
+		roles.name,
+		roles.mfa_required,
+		` + rolePermissionsColumn + `
+	FROM
+		users
+		LEFT JOIN roles ON users.role_id = roles.id
+	WHERE
+		users.id = $1 AND users.deleted_at IS NULL`
 
-// Find finds a user by id.
+// Find finds a user by id. Deleted users aren't found,
+// deactivated ones are found with DisabledAt set.
 func (r *UserRepository) Find(ctx context.Context, id int) (*user.User, error) {
-	var u user.User
+	var (
+		u           user.User
+		permissions []string
+	)
 	if err := r.db.QueryRowContext(ctx, findUserQuery, id).
 		Scan(
 			&u.ID,
@@ -55,17 +91,48 @@ func (r *UserRepository) Find(ctx context.Context, id int) (*user.User, error) {
 			&u.Email,
 			&u.CreatedAt,
 			&u.UpdatedAt,
+			&u.DisabledAt,
+			&u.TokenVersion,
+			&u.Version,
 			&u.Role.ID,
+			&u.Role.Name,
+			&u.Role.MFARequired,
+			pq.Array(&permissions),
 		); err != nil {
 		if err == sql.ErrNoRows {
-			return nil, role.ErrNotFound
+			return nil, user.ErrNotFound
 		}
 		return nil, errors.Wrap(err, "query row scan")
 	}
+	u.Role.Permissions = toPermissions(permissions)
+
 	return &u, nil
 }
 
-const updateUserQuery = `
+const tokenVersionQuery = `SELECT token_version, disabled_at IS NOT NULL FROM users WHERE id = $1 AND deleted_at IS NULL`
+
+// TokenVersion returns the current token version of the user.
+// ErrDeactivated is returned for the deactivated user.
+func (r *UserRepository) TokenVersion(ctx context.Context, id int) (int, error) {
+	var (
+		version  int
+		disabled bool
+	)
+	if err := r.db.QueryRowContext(ctx, tokenVersionQuery, id).Scan(&version, &disabled); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, user.ErrNotFound
+		}
+		return 0, errors.Wrap(err, "query row scan")
+	}
+
+	if disabled {
+		return 0, user.ErrDeactivated
+	}
+	return version, nil
+}
+
+const (
+	updateUserQuery = `
 	UPDATE 
 		users 
 	SET 
@@ -73,57 +140,241 @@ const updateUserQuery = `
 		email=:email, 
 		password_hash=:password_hash, 
 		role_id=:role_id, 
+		token_version=CASE
+			WHEN role_id <> :role_id OR password_hash <> :password_hash THEN token_version + 1
+			ELSE token_version
+		END,
+		version=version + 1,
 		updated_at=now() 
 	WHERE 
-		id=:id`
+		id=:id AND version=:version AND deleted_at IS NULL
+	RETURNING version, updated_at`
+	userExistsQuery = `SELECT EXISTS(SELECT 1 FROM users WHERE id=$1 AND deleted_at IS NULL)`
+)
 
-// Update updates user by id.
+// Update updates user by id when its version is still the version
+// of the user, the version is incremented then. Change of the role
+// or the password increments the token version, so issued tokens
+// become outdated.
 func (r *UserRepository) Update(ctx context.Context, id int, u *user.User) error {
-	stmt, err := r.db.PrepareNamed(updateUserQuery)
+	stmt, err := r.db.PrepareNamedContext(ctx, updateUserQuery)
 	if err != nil {
 		return errors.Wrap(err, "prepare named")
 	}
 	defer stmt.Close()
 
-	if _, err := stmt.ExecContext(ctx, map[string]interface{}{
+	if err := stmt.QueryRowxContext(ctx, map[string]interface{}{
 		"id":            id,
 		"username":      u.Username,
 		"email":         u.Email,
 		"password_hash": u.PasswordHash,
 		"role_id":       u.Role.ID,
-	}); err != nil {
+		"version":       u.Version,
+	}).Scan(&u.Version, &u.UpdatedAt); err != nil {
 		if err == sql.ErrNoRows {
-			return user.ErrNotFound
+			return staleOrNotFound(ctx, r.db, user.ErrNotFound, user.ErrVersionMismatch, userExistsQuery, id)
 		}
-		return errors.Wrap(err, "exec context")
+		return errors.Wrap(userViolations.translate(err), "query row scan")
 	}
 	return nil
 }
 
-const deleteUserQuery = `DELETE FROM users WHERE id=:id`
+const updateProfileQuery = `UPDATE users SET username=$2, email=$3, version=version + 1, updated_at=now() WHERE id=$1 AND deleted_at IS NULL`
 
-// Delete deletes user by id.
-func (r *UserRepository) Delete(ctx context.Context, id int) error {
-	stmt, err := r.db.PrepareNamed(deleteUserQuery)
+// UpdateProfile updates username and email of the user.
+func (r *UserRepository) UpdateProfile(ctx context.Context, id int, username, email string) error {
+	res, err := r.db.ExecContext(ctx, updateProfileQuery, id, username, email)
 	if err != nil {
-		return errors.Wrap(err, "prepare named")
+		return errors.Wrap(userViolations.translate(err), "exec context")
 	}
-	defer stmt.Close()
 
-	if _, err := stmt.ExecContext(ctx, map[string]interface{}{
-		"id": id,
-	}); err != nil {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "rows affected")
+	}
+
+	if n == 0 {
+		return user.ErrNotFound
+	}
+	return nil
+}
+
+const passwordHashQuery = `SELECT password_hash FROM users WHERE id = $1 AND deleted_at IS NULL`
+
+// PasswordHash returns the password hash of the user.
+func (r *UserRepository) PasswordHash(ctx context.Context, id int) (string, error) {
+	var hash string
+	if err := r.db.QueryRowContext(ctx, passwordHashQuery, id).Scan(&hash); err != nil {
 		if err == sql.ErrNoRows {
-			return user.ErrNotFound
+			return "", user.ErrNotFound
 		}
+		return "", errors.Wrap(err, "query row scan")
+	}
+	return hash, nil
+}
+
+const updatePasswordQuery = `
+	UPDATE
+		users
+	SET
+		password_hash=$2,
+		token_version=token_version + 1,
+		version=version + 1,
+		updated_at=now()
+	WHERE
+		id=$1 AND deleted_at IS NULL`
+
+// UpdatePassword sets the password hash of the user.
+// Issued tokens of the user become outdated.
+func (r *UserRepository) UpdatePassword(ctx context.Context, id int, passwordHash string) error {
+	res, err := r.db.ExecContext(ctx, updatePasswordQuery, id, passwordHash)
+	if err != nil {
 		return errors.Wrap(err, "exec context")
 	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "rows affected")
+	}
+
+	if n == 0 {
+		return user.ErrNotFound
+	}
 	return nil
 }
 
-const uniqueUsernameQuery = `SELECT COUNT(*) FROM users WHERE username = $1`
+const (
+	revokeOtherRefreshTokensQuery = `
+		UPDATE refresh_tokens SET revoked_at = now()
+		WHERE user_id = $1 AND ($2 = 0 OR session_id IS DISTINCT FROM $2) AND revoked_at IS NULL`
+	revokeOtherSessionsQuery = `
+		UPDATE sessions SET revoked_at = now()
+		WHERE user_id = $1 AND id <> $2 AND revoked_at IS NULL`
+)
+
+// RevokeSessions revokes the refresh tokens and the sessions of the
+// user except the kept session. Nothing is kept when it's 0.
+func (r *UserRepository) RevokeSessions(ctx context.Context, id, keepSessionID int) error {
+	if _, err := r.db.ExecContext(ctx, revokeOtherRefreshTokensQuery, id, keepSessionID); err != nil {
+		return errors.Wrap(err, "revoke refresh tokens")
+	}
+
+	if _, err := r.db.ExecContext(ctx, revokeOtherSessionsQuery, id, keepSessionID); err != nil {
+		return errors.Wrap(err, "revoke sessions")
+	}
+	return nil
+}
 
-// UniqueUsername checks that username is unique.
+const upgradePasswordHashQuery = `
+	UPDATE
+		users
+	SET
+		password_hash=$3
+	WHERE
+		id=$1 AND password_hash=$2 AND deleted_at IS NULL`
+
+// UpgradePasswordHash replaces the hash of the same password, so
+// issued tokens stay valid. Nothing is changed when the password
+// was changed meanwhile.
+func (r *UserRepository) UpgradePasswordHash(ctx context.Context, id int, oldHash, newHash string) error {
+	if _, err := r.db.ExecContext(ctx, upgradePasswordHashQuery, id, oldHash, newHash); err != nil {
+		return errors.Wrap(err, "exec context")
+	}
+	return nil
+}
+
+const updateUserRoleQuery = `
+	UPDATE
+		users
+	SET
+		role_id=$2,
+		token_version=token_version + 1,
+		version=version + 1,
+		updated_at=now()
+	WHERE
+		id=$1 AND deleted_at IS NULL`
+
+// UpdateRole sets the role of the user.
+// Issued tokens of the user become outdated.
+func (r *UserRepository) UpdateRole(ctx context.Context, id, roleID int) error {
+	res, err := r.db.ExecContext(ctx, updateUserRoleQuery, id, roleID)
+	if err != nil {
+		return errors.Wrap(userViolations.translate(err), "exec context")
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "rows affected")
+	}
+
+	if n == 0 {
+		return user.ErrNotFound
+	}
+	return nil
+}
+
+const deleteUserQuery = `
+	UPDATE
+		users
+	SET
+		deleted_at=now(),
+		token_version=token_version + 1
+	WHERE
+		id=$1 AND deleted_at IS NULL`
+
+// Delete moves the user to the trash.
+// Issued tokens of the user become outdated.
+func (r *UserRepository) Delete(ctx context.Context, id int) error {
+	return execOne(ctx, r.db, user.ErrNotFound, deleteUserQuery, id)
+}
+
+const undeleteUserQuery = `UPDATE users SET deleted_at=NULL, updated_at=now() WHERE id=$1 AND deleted_at IS NOT NULL`
+
+// Undelete restores the user from the trash.
+func (r *UserRepository) Undelete(ctx context.Context, id int) error {
+	return execOne(ctx, r.db, user.ErrNotFound, undeleteUserQuery, id)
+}
+
+const purgeUserQuery = `DELETE FROM users WHERE id=$1 AND deleted_at IS NOT NULL`
+
+// Purge deletes the user from the trash permanently,
+// the author or the editor of articles isn't purged.
+func (r *UserRepository) Purge(ctx context.Context, id int) error {
+	return purgeUserViolations.translate(execOne(ctx, r.db, user.ErrNotFound, purgeUserQuery, id))
+}
+
+const (
+	deactivateUserQuery = `
+		UPDATE
+			users
+		SET
+			disabled_at=COALESCE(disabled_at, now()),
+			token_version=token_version + 1,
+			version=version + 1,
+			updated_at=now()
+		WHERE
+			id=$1 AND deleted_at IS NULL`
+	activateUserQuery = `UPDATE users SET disabled_at=NULL, version=version + 1, updated_at=now() WHERE id=$1 AND deleted_at IS NULL`
+)
+
+// Deactivate forbids the user to sign in.
+// Issued tokens of the user become outdated.
+func (r *UserRepository) Deactivate(ctx context.Context, id int) error {
+	return execOne(ctx, r.db, user.ErrNotFound, deactivateUserQuery, id)
+}
+
+// Activate allows the deactivated user to sign in again.
+func (r *UserRepository) Activate(ctx context.Context, id int) error {
+	return execOne(ctx, r.db, user.ErrNotFound, activateUserQuery, id)
+}
+
+// The deleted users are counted by the unique checks,
+// so the user can be restored from the trash. Usernames and
+// emails are unique regardless of the case.
+const uniqueUsernameQuery = `SELECT COUNT(*) FROM users WHERE lower(username) = lower($1)`
+
+// UniqueUsername checks that username is unique. It's a fast path
+// for the forms, the unique indexes are checked on insert anyway.
 func (r *UserRepository) UniqueUsername(ctx context.Context, username string) error {
 	var c int
 	if err := r.db.QueryRowContext(ctx, uniqueUsernameQuery, username).Scan(&c); err != nil {
@@ -137,7 +388,7 @@ func (r *UserRepository) UniqueUsername(ctx context.Context, username string) er
 	return nil
 }
 
-const uniqueEmailQuery = `SELECT COUNT(*) FROM users WHERE email = $1`
+const uniqueEmailQuery = `SELECT COUNT(*) FROM users WHERE lower(email) = lower($1)`
 
 // UniqueEmail checks that email address is unique.
 func (r *UserRepository) UniqueEmail(ctx context.Context, email string) error {
@@ -159,27 +410,39 @@ const emailFindQuery = `
 		users.username,
 		users.email,
 		users.password_hash,
+		users.disabled_at,
+		users.token_version,
 		roles.id,
 		roles.name,
+		roles.mfa_required,
+		` + rolePermissionsColumn + `,
 		users.created_at,
 		users.updated_at
 	FROM
 		users
 		LEFT JOIN roles ON users.role_id = roles.id
 	WHERE 
-		email = $1`
+		lower(users.email) = lower($1) AND users.deleted_at IS NULL`
 
-// FindByEmail finds users by e-mail.
+// FindByEmail finds users by e-mail regardless of the case. Deleted
+// users aren't found, deactivated ones are found with DisabledAt set.
 func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*user.User, error) {
-	var usr user.User
+	var (
+		usr         user.User
+		permissions []string
+	)
 	err := r.db.QueryRowContext(ctx, emailFindQuery, email).
 		Scan(
 			&usr.ID,
 			&usr.Username,
 			&usr.Email,
 			&usr.PasswordHash,
+			&usr.DisabledAt,
+			&usr.TokenVersion,
 			&usr.Role.ID,
 			&usr.Role.Name,
+			&usr.Role.MFARequired,
+			pq.Array(&permissions),
 			&usr.CreatedAt,
 			&usr.UpdatedAt,
 		)
@@ -191,46 +454,100 @@ func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*user.U
 	if err != nil {
 		return nil, errors.Wrap(err, "scan error")
 	}
+	usr.Role.Permissions = toPermissions(permissions)
 
 	return &usr, nil
 }
 
-const listUsersQuery = `
-	SELECT 
-		users.id, 
-		users.username, 
-		users.email, 
-		users.created_at, 
-		users.updated_at, 
-		roles.id, 
-		roles.name 
-	FROM 
+var listUsers = listing{
+	columns: `
+		users.id,
+		users.username,
+		users.email,
+		users.created_at,
+		users.updated_at,
+		users.disabled_at,
+		users.deleted_at,
+		users.version,
+		roles.id,
+		roles.name`,
+	from: `
 		users
-		LEFT JOIN roles ON users.role_id = roles.id`
+		LEFT JOIN roles ON users.role_id = roles.id`,
+	id:    "users.id",
+	scope: "users.deleted_at IS NULL",
+	sort: map[string]string{
+		"id":         "users.id",
+		"username":   "users.username",
+		"email":      "users.email",
+		"created_at": "users.created_at",
+		"updated_at": "users.updated_at",
+	},
+	filter: map[string]column{
+		"role_id":  {name: "users.role_id", typ: intColumn},
+		"username": {name: "users.username", typ: textColumn},
+		"email":    {name: "users.email", typ: textColumn},
+	},
+}
 
-// List returns all users.
-func (r *UserRepository) List(ctx context.Context, usr *user.Users) error {
-	rows, err := r.db.QueryxContext(ctx, listUsersQuery)
+var listDeletedUsers = listUsers.withScope("users.deleted_at IS NOT NULL")
+
+// List returns users page by given query.
+func (r *UserRepository) List(ctx context.Context, q *query.Query, usr *user.Users) error {
+	return r.list(ctx, listUsers, q, usr)
+}
+
+// ListDeleted returns page of the users in the trash by given query.
+func (r *UserRepository) ListDeleted(ctx context.Context, q *query.Query, usr *user.Users) error {
+	return r.list(ctx, listDeletedUsers, q, usr)
+}
+
+func (r *UserRepository) list(ctx context.Context, l listing, q *query.Query, usr *user.Users) error {
+	listQuery, args, err := l.build(q)
+	if err != nil {
+		return errors.Wrap(err, "build list query")
+	}
+
+	rows, err := r.db.QueryxContext(ctx, listQuery, args...)
 	if err != nil {
 		return errors.Wrap(err, "query rows")
 	}
 	defer rows.Close()
 
+	var values []string
 	for rows.Next() {
-		var user user.User
+		var (
+			user  user.User
+			value string
+		)
 		if err := rows.Scan(
 			&user.ID,
 			&user.Username,
 			&user.Email,
 			&user.CreatedAt,
 			&user.UpdatedAt,
+			&user.DisabledAt,
+			&user.DeletedAt,
+			&user.Version,
 			&user.Role.ID,
 			&user.Role.Name,
+			&value,
 		); err != nil {
 			return errors.Wrap(err, "users query row scan on loop")
 		}
 
 		usr.Users = append(usr.Users, user)
+		values = append(values, value)
+	}
+
+	if err := rows.Err(); err != nil {
+		return errors.Wrap(err, "users rows")
+	}
+
+	if len(values) > q.Limit {
+		last := q.Limit - 1
+		usr.Users = usr.Users[:q.Limit]
+		usr.NextCursor = nextCursor(q, values[last], usr.Users[last].ID)
 	}
 
 	return nil
@@ -4,9 +4,12 @@ import (
 	"context"
 	"testing"
 
+	"github.com/dipress/crmifc/internal/article"
+	"github.com/dipress/crmifc/internal/kit/query"
 	"github.com/dipress/crmifc/internal/role"
 
 	"github.com/dipress/crmifc/internal/user"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -109,7 +112,7 @@ func TestUniqueUsername(t *testing.T) {
 			Username:     "username1",
 			Email:        "username1@example.com",
 			PasswordHash: "$2y$12$gwoUXq7kCxNcucd.eFxOp.vJYYmo6917fSGuuEowfyNf3E8KySrWC",
-			RoleID:       1,
+			RoleID:       createRole(t, db, "Member"),
 		}
 
 		ctx, cancel := context.WithCancel(context.Background())
@@ -126,11 +129,16 @@ func TestUniqueUsername(t *testing.T) {
 			err := userRepo.UniqueUsername(ctx, "username1")
 			assert.Error(t, err, "username already exists")
 		}
-		t.Log("\ttest:0\tshould return nil")
+		t.Log("\ttest:1\tshould return nil")
 		{
 			err := userRepo.UniqueUsername(ctx, "username2")
 			assert.Nil(t, err)
 		}
+		t.Log("\ttest:2\tshould ignore the case")
+		{
+			err := userRepo.UniqueUsername(ctx, "UserName1")
+			assert.Error(t, err, "username already exists")
+		}
 	}
 }
 
@@ -174,7 +182,7 @@ func TestUpdateUser(t *testing.T) {
 
 			user.Username = "Hacket"
 			user.Email = "hacket@example.com"
-			user.Role.ID = 3
+			user.Role.ID = rl.ID
 
 			err := userRepo.Update(ctx, 1, &user)
 			if err != nil {
@@ -195,7 +203,7 @@ func TestUniqueEmail(t *testing.T) {
 			Username:     "username5",
 			Email:        "username5@example.com",
 			PasswordHash: "$2y$12$gwoUXq7kCxNcucd.eFxOp.vJYYmo6917fSGuuEowfyNf3E8KySrWC",
-			RoleID:       1,
+			RoleID:       createRole(t, db, "Member"),
 		}
 
 		ctx, cancel := context.WithCancel(context.Background())
@@ -212,11 +220,16 @@ func TestUniqueEmail(t *testing.T) {
 			err := userRepo.UniqueEmail(ctx, "username5@example.com")
 			assert.Error(t, err, "email already exists")
 		}
-		t.Log("\ttest:0\tshould return nil")
+		t.Log("\ttest:1\tshould return nil")
 		{
 			err := userRepo.UniqueEmail(ctx, "username6@example.com")
 			assert.Nil(t, err)
 		}
+		t.Log("\ttest:2\tshould ignore the case")
+		{
+			err := userRepo.UniqueEmail(ctx, "UserName5@Example.com")
+			assert.Error(t, err, "email already exists")
+		}
 	}
 }
 
@@ -270,7 +283,7 @@ func TestListUsers(t *testing.T) {
 		t.Log("\ttest:0\tshould show list of users")
 		{
 			var users user.Users
-			err := userRepo.List(ctx, &users)
+			err := userRepo.List(ctx, query.New(), &users)
 			if err != nil {
 				t.Errorf("unexpected error: %v", err)
 			}
@@ -323,3 +336,337 @@ func TestFindUserByEmail(t *testing.T) {
 		}
 	}
 }
+
+func TestUserTokenVersion(t *testing.T) {
+	t.Log("with initialized repository")
+	{
+		db, teardown := postgresDB(t)
+		defer teardown()
+
+		r := NewUserRepository(db)
+		roleRepo := NewRoleRepository(db)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var rol role.Role
+		if err := roleRepo.Create(ctx, &role.NewRole{Name: "Versioned"}, &rol); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		nu := user.NewUser{
+			RoleID:       rol.ID,
+			Username:     "username_version",
+			Email:        "username_version@example.com",
+			PasswordHash: "hash",
+		}
+
+		var u user.User
+		if err := r.Create(ctx, &nu, &u); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		version := func() int {
+			v, err := r.TokenVersion(ctx, u.ID)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			return v
+		}
+
+		t.Log("\ttest:0\tshould start with the first version")
+		{
+			assert.Equal(t, 1, version())
+		}
+
+		t.Log("\ttest:1\tshould keep the version when the role and the password are the same")
+		{
+			u.PasswordHash = "hash"
+			u.Username = "username_version2"
+			if err := r.Update(ctx, u.ID, &u); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			assert.Equal(t, 1, version())
+		}
+
+		t.Log("\ttest:2\tshould increment the version on the password change")
+		{
+			u.PasswordHash = "new hash"
+			if err := r.Update(ctx, u.ID, &u); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			assert.Equal(t, 2, version())
+		}
+
+		t.Log("\ttest:3\tshould increment the version on the permission change")
+		{
+			if err := roleRepo.Grant(ctx, rol.ID, role.ArticlesView); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if err := roleRepo.Grant(ctx, rol.ID, role.ArticlesView); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			assert.Equal(t, 3, version())
+
+			found, err := r.Find(ctx, u.ID)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			assert.Equal(t, 3, found.TokenVersion)
+			assert.True(t, found.Role.Can(role.ArticlesView))
+		}
+
+		t.Log("\ttest:4\tshould keep the version and the password on the profile update")
+		{
+			if err := r.UpdateProfile(ctx, u.ID, "username_version3", "username_version3@example.com"); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			assert.Equal(t, 3, version())
+
+			hash, err := r.PasswordHash(ctx, u.ID)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			assert.Equal(t, "new hash", hash)
+		}
+
+		t.Log("\ttest:5\tshould increment the version on the password update")
+		{
+			if err := r.UpdatePassword(ctx, u.ID, "newer hash"); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			assert.Equal(t, 4, version())
+		}
+
+		t.Log("\ttest:6\tshould increment the version on the role update")
+		{
+			other := createRole(t, db, "Reassigned")
+			if err := r.UpdateRole(ctx, u.ID, other); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			assert.Equal(t, 5, version())
+
+			found, err := r.Find(ctx, u.ID)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			assert.Equal(t, other, found.Role.ID)
+		}
+
+		t.Log("\ttest:7\tshould keep the version on the password hash upgrade")
+		{
+			if err := r.UpgradePasswordHash(ctx, u.ID, "newer hash", "upgraded hash"); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if err := r.UpgradePasswordHash(ctx, u.ID, "newer hash", "stale hash"); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			assert.Equal(t, 5, version())
+
+			hash, err := r.PasswordHash(ctx, u.ID)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			assert.Equal(t, "upgraded hash", hash)
+		}
+	}
+}
+
+func TestUserDeactivateAndTrash(t *testing.T) {
+	t.Log("with initialized repository")
+	{
+		db, teardown := postgresDB(t)
+		defer teardown()
+
+		r := NewUserRepository(db)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		nu := user.NewUser{
+			RoleID:       createRole(t, db, "Member"),
+			Username:     "username_trash",
+			Email:        "username_trash@example.com",
+			PasswordHash: "hash",
+		}
+
+		var u user.User
+		if err := r.Create(ctx, &nu, &u); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		t.Log("\ttest:0\tshould deactivate and activate the user")
+		{
+			if err := r.Deactivate(ctx, u.ID); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			_, err := r.TokenVersion(ctx, u.ID)
+			assert.Equal(t, user.ErrDeactivated, err)
+
+			found, err := r.FindByEmail(ctx, nu.Email)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			assert.NotNil(t, found.DisabledAt)
+
+			if err := r.Activate(ctx, u.ID); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			version, err := r.TokenVersion(ctx, u.ID)
+			assert.Nil(t, err)
+			assert.Equal(t, 2, version)
+		}
+
+		t.Log("\ttest:1\tshould move the deleted user to the trash")
+		{
+			if err := r.Delete(ctx, u.ID); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			_, err := r.Find(ctx, u.ID)
+			assert.Equal(t, user.ErrNotFound, err)
+
+			_, err = r.TokenVersion(ctx, u.ID)
+			assert.Equal(t, user.ErrNotFound, err)
+
+			assert.Equal(t, user.ErrUsernameExists, r.UniqueUsername(ctx, nu.Username))
+
+			var trash user.Users
+			if err := r.ListDeleted(ctx, &query.Query{}, &trash); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if assert.Len(t, trash.Users, 1) {
+				assert.Equal(t, u.ID, trash.Users[0].ID)
+				assert.NotNil(t, trash.Users[0].DeletedAt)
+			}
+		}
+
+		t.Log("\ttest:2\tshould restore the user from the trash")
+		{
+			if err := r.Undelete(ctx, u.ID); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			_, err := r.Find(ctx, u.ID)
+			assert.Nil(t, err)
+
+			assert.Equal(t, user.ErrNotFound, r.Purge(ctx, u.ID))
+		}
+
+		t.Log("\ttest:3\tshould purge the deleted user")
+		{
+			if err := r.Delete(ctx, u.ID); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			assert.Nil(t, r.Purge(ctx, u.ID))
+			assert.Nil(t, r.UniqueUsername(ctx, nu.Username))
+		}
+	}
+}
+
+func TestUserUniqueIndexes(t *testing.T) {
+	t.Log("with initialized repository")
+	{
+		db, teardown := postgresDB(t)
+		defer teardown()
+
+		r := NewUserRepository(db)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		nu := user.NewUser{
+			RoleID:       createRole(t, db, "Member"),
+			Username:     "username_unique",
+			Email:        "username_unique@example.com",
+			PasswordHash: "hash",
+		}
+
+		var u user.User
+		if err := r.Create(ctx, &nu, &u); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		t.Log("\ttest:0\tshould find the user by email regardless of the case")
+		{
+			found, err := r.FindByEmail(ctx, "UserName_Unique@Example.com")
+			if assert.Nil(t, err) {
+				assert.Equal(t, u.ID, found.ID)
+			}
+		}
+
+		t.Log("\ttest:1\tshould return error on email which differs by case")
+		{
+			dup := nu
+			dup.Username = "username_other"
+			dup.Email = "UserName_Unique@Example.com"
+
+			var created user.User
+			assert.Equal(t, user.ErrEmailExists, errors.Cause(r.Create(ctx, &dup, &created)))
+		}
+	}
+}
+
+func TestUserUnknownRole(t *testing.T) {
+	t.Log("with initialized repository")
+	{
+		db, teardown := postgresDB(t)
+		defer teardown()
+
+		r := NewUserRepository(db)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		t.Log("\ttest:0\tshould return error on unknown role")
+		{
+			nu := user.NewUser{
+				RoleID:       createRole(t, db, "Member") + 100,
+				Username:     "username_unknown_role",
+				Email:        "username_unknown_role@example.com",
+				PasswordHash: "hash",
+			}
+
+			var u user.User
+			assert.Equal(t, user.ErrRoleNotFound, errors.Cause(r.Create(ctx, &nu, &u)))
+		}
+	}
+}
+
+func TestUserPurgeInUse(t *testing.T) {
+	t.Log("with initialized repository")
+	{
+		db, teardown := postgresDB(t)
+		defer teardown()
+
+		r := NewUserRepository(db)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		na := article.NewArticle{
+			AuthorID:   createUser(t, db, "username_author"),
+			CategoryID: createCategory(t, db, "Authored"),
+			Title:      "my title",
+			Body:       "my body",
+		}
+
+		var art article.Article
+		if err := NewArticleRepository(db).Create(ctx, &na, &art); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		t.Log("\ttest:0\tshould not purge the author of articles")
+		{
+			if err := r.Delete(ctx, na.AuthorID); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			assert.Equal(t, user.ErrInUse, errors.Cause(r.Purge(ctx, na.AuthorID)))
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package postgres
+
+import (
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+// Codes of the integrity constraint violations.
+const (
+	foreignKeyViolation pq.ErrorCode = "23503"
+	uniqueViolation     pq.ErrorCode = "23505"
+)
+
+// violations maps names of the constraints to the domain
+// errors returned when the statement violates them.
+type violations map[string]error
+
+// translate returns the domain error of the constraint violated by err.
+// Other errors are returned as is.
+func (v violations) translate(err error) error {
+	pqErr, ok := errors.Cause(err).(*pq.Error)
+	if !ok {
+		return err
+	}
+
+	if pqErr.Code != foreignKeyViolation && pqErr.Code != uniqueViolation {
+		return err
+	}
+
+	if e, ok := v[pqErr.Constraint]; ok {
+		return e
+	}
+	return err
+}
@@ -0,0 +1,64 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/dipress/crmifc/internal/category"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestViolationsTranslate(t *testing.T) {
+	v := violations{
+		"articles_category_id_fkey": category.ErrInUse,
+		"categories_name_key":       category.ErrNameExists,
+	}
+
+	mockErr := errors.New("mock error")
+
+	tests := []struct {
+		name   string
+		err    error
+		expect error
+	}{
+		{
+			name:   "nil",
+			err:    nil,
+			expect: nil,
+		},
+		{
+			name:   "foreign key",
+			err:    errors.Wrap(&pq.Error{Code: foreignKeyViolation, Constraint: "articles_category_id_fkey"}, "exec context"),
+			expect: category.ErrInUse,
+		},
+		{
+			name:   "unique",
+			err:    &pq.Error{Code: uniqueViolation, Constraint: "categories_name_key"},
+			expect: category.ErrNameExists,
+		},
+		{
+			name:   "unknown constraint",
+			err:    &pq.Error{Code: uniqueViolation, Constraint: "categories_pkey"},
+			expect: &pq.Error{Code: uniqueViolation, Constraint: "categories_pkey"},
+		},
+		{
+			name:   "other code",
+			err:    &pq.Error{Code: "23502", Constraint: "categories_name_key"},
+			expect: &pq.Error{Code: "23502", Constraint: "categories_name_key"},
+		},
+		{
+			name:   "not postgres",
+			err:    mockErr,
+			expect: mockErr,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.expect, v.translate(tc.err))
+		})
+	}
+}
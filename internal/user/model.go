@@ -18,17 +18,39 @@ var (
 	// ErrEmailExists returns when given email is already
 	// present in database.
 	ErrEmailExists = errors.New("email already exists")
+	// ErrInvalidResetToken returns when password reset token
+	// is unknown, expired or used already.
+	ErrInvalidResetToken = errors.New("invalid reset token")
+	// ErrWrongPassword returns when given current password
+	// doesn't match the password of the user.
+	ErrWrongPassword = errors.New("wrong password")
+	// ErrDeactivated returns when the user is deactivated
+	// and isn't allowed to sign in.
+	ErrDeactivated = errors.New("user is deactivated")
+	// ErrRoleNotFound returns when the role given
+	// to the user doesn't exist.
+	ErrRoleNotFound = errors.New("user role not found")
+	// ErrInUse returns when the user can't be purged
+	// because articles refer to it.
+	ErrInUse = errors.New("user is in use")
+	// ErrVersionMismatch returns when the user was changed
+	// since the version the request is based on.
+	ErrVersionMismatch = errors.New("user version mismatch")
 )
 
 // User contains all user field.
 type User struct {
-	ID           int       `json:"id"`
-	Username     string    `json:"username"`
-	Email        string    `json:"email"`
-	PasswordHash string    `json:"password_hash"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
-	Role         role.Role `json:"role"`
+	ID           int        `json:"id"`
+	Username     string     `json:"username"`
+	Email        string     `json:"email"`
+	PasswordHash string     `json:"password_hash"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	DisabledAt   *time.Time `json:"disabled_at"`
+	DeletedAt    *time.Time `json:"deleted_at,omitempty"`
+	Role         role.Role  `json:"role"`
+	TokenVersion int        `json:"-"`
+	Version      int        `json:"version"`
 }
 
 // Form is a user form.
@@ -39,6 +61,38 @@ type Form struct {
 	RoleID   int    `json:"role_id"`
 }
 
+// PatchForm is a form of the partial update of the user.
+// The password is nil when the patch doesn't supply it.
+type PatchForm struct {
+	Username string  `json:"username"`
+	Email    string  `json:"email"`
+	Password *string `json:"password,omitempty"`
+	RoleID   int     `json:"role_id"`
+}
+
+// ForgotForm is a form to request the password reset.
+type ForgotForm struct {
+	Email string `json:"email"`
+}
+
+// ResetForm is a form to reset the password.
+type ResetForm struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+// ProfileForm is a form to change own account of the user.
+type ProfileForm struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+}
+
+// PasswordForm is a form to change own password of the user.
+type PasswordForm struct {
+	OldPassword string `json:"old_password"`
+	NewPassword string `json:"new_password"`
+}
+
 // NewUser contains the information which needs to create a new User.
 type NewUser struct {
 	RoleID       int
@@ -49,5 +103,6 @@ type NewUser struct {
 
 // Users contains slice of users.
 type Users struct {
-	Users []User `json:"users"`
+	Users      []User `json:"users"`
+	NextCursor string `json:"next_cursor"`
 }
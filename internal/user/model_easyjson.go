@@ -7,6 +7,7 @@ import (
 	easyjson "github.com/mailru/easyjson"
 	jlexer "github.com/mailru/easyjson/jlexer"
 	jwriter "github.com/mailru/easyjson/jwriter"
+	time "time"
 )
 
 // suppress unused package warning
@@ -59,6 +60,8 @@ func easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalUser(in *jlexer.Lexer,
 				}
 				in.Delim(']')
 			}
+		case "next_cursor":
+			out.NextCursor = string(in.String())
 		default:
 			in.SkipRecursive()
 		}
@@ -94,6 +97,16 @@ func easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalUser(out *jwriter.Write
 			out.RawByte(']')
 		}
 	}
+	{
+		const prefix string = ",\"next_cursor\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.NextCursor))
+	}
 	out.RawByte('}')
 }
 
@@ -155,8 +168,34 @@ func easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalUser1(in *jlexer.Lexer,
 			if data := in.Raw(); in.Ok() {
 				in.AddError((out.UpdatedAt).UnmarshalJSON(data))
 			}
+		case "disabled_at":
+			if in.IsNull() {
+				in.Skip()
+				out.DisabledAt = nil
+			} else {
+				if out.DisabledAt == nil {
+					out.DisabledAt = new(time.Time)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.DisabledAt).UnmarshalJSON(data))
+				}
+			}
+		case "deleted_at":
+			if in.IsNull() {
+				in.Skip()
+				out.DeletedAt = nil
+			} else {
+				if out.DeletedAt == nil {
+					out.DeletedAt = new(time.Time)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.DeletedAt).UnmarshalJSON(data))
+				}
+			}
 		case "role":
 			(out.Role).UnmarshalEasyJSON(in)
+		case "version":
+			out.Version = int(in.Int())
 		default:
 			in.SkipRecursive()
 		}
@@ -231,6 +270,30 @@ func easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalUser1(out *jwriter.Writ
 		}
 		out.Raw((in.UpdatedAt).MarshalJSON())
 	}
+	{
+		const prefix string = ",\"disabled_at\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		if in.DisabledAt == nil {
+			out.RawString("null")
+		} else {
+			out.Raw((*in.DisabledAt).MarshalJSON())
+		}
+	}
+	if in.DeletedAt != nil {
+		const prefix string = ",\"deleted_at\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Raw((*in.DeletedAt).MarshalJSON())
+	}
 	{
 		const prefix string = ",\"role\":"
 		if first {
@@ -241,6 +304,16 @@ func easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalUser1(out *jwriter.Writ
 		}
 		(in.Role).MarshalEasyJSON(out)
 	}
+	{
+		const prefix string = ",\"version\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int(int(in.Version))
+	}
 	out.RawByte('}')
 }
 
@@ -267,7 +340,371 @@ func (v *User) UnmarshalJSON(data []byte) error {
 func (v *User) UnmarshalEasyJSON(l *jlexer.Lexer) {
 	easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalUser1(l, v)
 }
-func easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalUser2(in *jlexer.Lexer, out *NewUser) {
+func easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalUser2(in *jlexer.Lexer, out *ResetForm) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "token":
+			out.Token = string(in.String())
+		case "password":
+			out.Password = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalUser2(out *jwriter.Writer, in ResetForm) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"token\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Token))
+	}
+	{
+		const prefix string = ",\"password\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Password))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v ResetForm) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalUser2(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ResetForm) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalUser2(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ResetForm) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalUser2(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ResetForm) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalUser2(l, v)
+}
+func easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalUser3(in *jlexer.Lexer, out *ProfileForm) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "username":
+			out.Username = string(in.String())
+		case "email":
+			out.Email = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalUser3(out *jwriter.Writer, in ProfileForm) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"username\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Username))
+	}
+	{
+		const prefix string = ",\"email\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Email))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v ProfileForm) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalUser3(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ProfileForm) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalUser3(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ProfileForm) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalUser3(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ProfileForm) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalUser3(l, v)
+}
+func easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalUser4(in *jlexer.Lexer, out *PatchForm) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "username":
+			out.Username = string(in.String())
+		case "email":
+			out.Email = string(in.String())
+		case "password":
+			if in.IsNull() {
+				in.Skip()
+				out.Password = nil
+			} else {
+				if out.Password == nil {
+					out.Password = new(string)
+				}
+				*out.Password = string(in.String())
+			}
+		case "role_id":
+			out.RoleID = int(in.Int())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalUser4(out *jwriter.Writer, in PatchForm) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"username\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Username))
+	}
+	{
+		const prefix string = ",\"email\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Email))
+	}
+	if in.Password != nil {
+		const prefix string = ",\"password\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(*in.Password))
+	}
+	{
+		const prefix string = ",\"role_id\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int(int(in.RoleID))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v PatchForm) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalUser4(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v PatchForm) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalUser4(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *PatchForm) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalUser4(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *PatchForm) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalUser4(l, v)
+}
+func easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalUser5(in *jlexer.Lexer, out *PasswordForm) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "old_password":
+			out.OldPassword = string(in.String())
+		case "new_password":
+			out.NewPassword = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalUser5(out *jwriter.Writer, in PasswordForm) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"old_password\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.OldPassword))
+	}
+	{
+		const prefix string = ",\"new_password\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.NewPassword))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v PasswordForm) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalUser5(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v PasswordForm) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalUser5(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *PasswordForm) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalUser5(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *PasswordForm) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalUser5(l, v)
+}
+func easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalUser6(in *jlexer.Lexer, out *NewUser) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -304,7 +741,7 @@ func easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalUser2(in *jlexer.Lexer,
 		in.Consumed()
 	}
 }
-func easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalUser2(out *jwriter.Writer, in NewUser) {
+func easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalUser6(out *jwriter.Writer, in NewUser) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -354,27 +791,27 @@ func easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalUser2(out *jwriter.Writ
 // MarshalJSON supports json.Marshaler interface
 func (v NewUser) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalUser2(&w, v)
+	easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalUser6(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v NewUser) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalUser2(w, v)
+	easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalUser6(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *NewUser) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalUser2(&r, v)
+	easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalUser6(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *NewUser) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalUser2(l, v)
+	easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalUser6(l, v)
 }
-func easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalUser3(in *jlexer.Lexer, out *Form) {
+func easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalUser7(in *jlexer.Lexer, out *Form) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -411,7 +848,7 @@ func easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalUser3(in *jlexer.Lexer,
 		in.Consumed()
 	}
 }
-func easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalUser3(out *jwriter.Writer, in Form) {
+func easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalUser7(out *jwriter.Writer, in Form) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -461,23 +898,94 @@ func easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalUser3(out *jwriter.Writ
 // MarshalJSON supports json.Marshaler interface
 func (v Form) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalUser3(&w, v)
+	easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalUser7(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v Form) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalUser3(w, v)
+	easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalUser7(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *Form) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalUser3(&r, v)
+	easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalUser7(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *Form) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalUser3(l, v)
+	easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalUser7(l, v)
+}
+func easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalUser8(in *jlexer.Lexer, out *ForgotForm) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "email":
+			out.Email = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalUser8(out *jwriter.Writer, in ForgotForm) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"email\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Email))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v ForgotForm) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalUser8(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ForgotForm) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC80ae7adEncodeGithubComDipressCrmifcInternalUser8(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ForgotForm) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalUser8(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ForgotForm) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC80ae7adDecodeGithubComDipressCrmifcInternalUser8(l, v)
 }
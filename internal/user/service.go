@@ -2,7 +2,19 @@ package user
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
 
+	"github.com/dipress/crmifc/internal/kit/mail"
+	"github.com/dipress/crmifc/internal/kit/patch"
+	"github.com/dipress/crmifc/internal/kit/query"
+	"github.com/dipress/crmifc/internal/kit/tx"
 	"github.com/pkg/errors"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -16,26 +28,70 @@ type Repository interface {
 	UniqueEmail(ctx context.Context, email string) error
 	Find(ctx context.Context, id int) (*User, error)
 	Update(ctx context.Context, id int, u *User) error
+	UpdateProfile(ctx context.Context, id int, username, email string) error
+	PasswordHash(ctx context.Context, id int) (string, error)
+	UpdatePassword(ctx context.Context, id int, passwordHash string) error
+	RevokeSessions(ctx context.Context, id, keepSessionID int) error
 	Delete(ctx context.Context, id int) error
-	List(ctx context.Context, users *Users) error
+	List(ctx context.Context, q *query.Query, users *Users) error
+	Deactivate(ctx context.Context, id int) error
+	Activate(ctx context.Context, id int) error
+	ListDeleted(ctx context.Context, q *query.Query, users *Users) error
+	Undelete(ctx context.Context, id int) error
+	Purge(ctx context.Context, id int) error
+}
+
+// ResetRepository stores password reset tokens.
+type ResetRepository interface {
+	CreatePasswordReset(ctx context.Context, email, hash string, expiresAt time.Time) error
+	FindResetUser(ctx context.Context, hash string) (*User, error)
+	ResetPassword(ctx context.Context, hash, passwordHash string) error
 }
 
 // Validater validates user fields.
 type Validater interface {
 	Validate(ctx context.Context, form *Form) error
+	ValidatePatch(ctx context.Context, form *PatchForm) error
+	ValidateForgot(ctx context.Context, form *ForgotForm) error
+	ValidateReset(ctx context.Context, form *ResetForm, u *User) error
+	ValidateProfile(ctx context.Context, form *ProfileForm) error
+	ValidatePassword(ctx context.Context, form *PasswordForm) error
 }
 
+const (
+	// DefaultResetURL is the page which resets the password
+	// by the token from the query string.
+	DefaultResetURL = "http://localhost:3000/password/reset"
+	// DefaultResetExpireAfter is the lifetime of the reset token.
+	DefaultResetExpireAfter = time.Hour
+)
+
 // Service is a use case for user creation.
 type Service struct {
 	Repository
 	Validater
+	ResetRepository
+	Mailer           mail.Mailer
+	ResetURL         string
+	ResetExpireAfter time.Duration
+	// Cost is the bcrypt cost of the password hashes.
+	Cost int
+	// Transactor runs the actions of several
+	// repository calls in a single transaction.
+	Transactor tx.Transactor
 }
 
 // NewService factory prepares service for all futher operations.
-func NewService(r Repository, v Validater) *Service {
+func NewService(r Repository, v Validater, rr ResetRepository, m mail.Mailer) *Service {
 	s := Service{
-		Repository: r,
-		Validater:  v,
+		Repository:       r,
+		Validater:        v,
+		ResetRepository:  rr,
+		Mailer:           m,
+		ResetURL:         DefaultResetURL,
+		ResetExpireAfter: DefaultResetExpireAfter,
+		Cost:             bcrypt.DefaultCost,
+		Transactor:       tx.Nop{},
 	}
 
 	return &s
@@ -47,15 +103,7 @@ func (s *Service) Create(ctx context.Context, f *Form, u *User) error {
 		return errors.Wrap(err, "validater validate")
 	}
 
-	if err := s.Repository.UniqueUsername(ctx, f.Username); err != nil {
-		return errors.Wrap(err, "unique username")
-	}
-
-	if err := s.Repository.UniqueEmail(ctx, f.Email); err != nil {
-		return errors.Wrap(err, "unique email")
-	}
-
-	pw, err := bcrypt.GenerateFromPassword([]byte(f.Password), bcrypt.DefaultCost)
+	pw, err := bcrypt.GenerateFromPassword([]byte(f.Password), s.Cost)
 	if err != nil {
 		return errors.Wrap(err, "generating password hash")
 	}
@@ -67,11 +115,21 @@ func (s *Service) Create(ctx context.Context, f *Form, u *User) error {
 		RoleID:       f.RoleID,
 	}
 
-	if err := s.Repository.Create(ctx, &nu, u); err != nil {
-		return errors.Wrap(err, "create user")
-	}
+	return s.Transactor.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.Repository.UniqueUsername(ctx, f.Username); err != nil {
+			return errors.Wrap(err, "unique username")
+		}
 
-	return nil
+		if err := s.Repository.UniqueEmail(ctx, f.Email); err != nil {
+			return errors.Wrap(err, "unique email")
+		}
+
+		if err := s.Repository.Create(ctx, &nu, u); err != nil {
+			return errors.Wrap(err, "create user")
+		}
+
+		return nil
+	})
 }
 
 // Find finds a user by id.
@@ -83,8 +141,8 @@ func (s *Service) Find(ctx context.Context, id int) (*User, error) {
 	return u, nil
 }
 
-// Update updates a user by id.
-func (s *Service) Update(ctx context.Context, id int, f *Form) (*User, error) {
+// Update updates a user of the given version by id.
+func (s *Service) Update(ctx context.Context, id, version int, f *Form) (*User, error) {
 	if err := s.Validater.Validate(ctx, f); err != nil {
 		return nil, errors.Wrap(err, "validate user")
 	}
@@ -94,7 +152,11 @@ func (s *Service) Update(ctx context.Context, id int, f *Form) (*User, error) {
 		return nil, errors.Wrap(err, "find user")
 	}
 
-	pw, err := bcrypt.GenerateFromPassword([]byte(f.Password), bcrypt.DefaultCost)
+	if u.Version != version {
+		return nil, ErrVersionMismatch
+	}
+
+	pw, err := bcrypt.GenerateFromPassword([]byte(f.Password), s.Cost)
 	if err != nil {
 		return nil, errors.Wrap(err, "generating password hash")
 	}
@@ -111,26 +173,277 @@ func (s *Service) Update(ctx context.Context, id int, f *Form) (*User, error) {
 	return u, nil
 }
 
-// Delete deletes a user.
-func (s *Service) Delete(ctx context.Context, id int) error {
+// Patch updates only the fields of the user of the given version which
+// are given by the JSON merge patch. The password is rehashed only when
+// it is supplied.
+func (s *Service) Patch(ctx context.Context, id, version int, p patch.Patch) (*User, error) {
 	u, err := s.Repository.Find(ctx, id)
 	if err != nil {
-		return errors.Wrap(err, "find user")
+		return nil, errors.Wrap(err, "find user")
 	}
 
-	if err := s.Repository.Delete(ctx, u.ID); err != nil {
-		return errors.Wrap(err, "delete user")
+	if u.Version != version {
+		return nil, ErrVersionMismatch
 	}
 
-	return nil
+	f := PatchForm{
+		Username: u.Username,
+		Email:    u.Email,
+		RoleID:   u.Role.ID,
+	}
+
+	if err := p.Apply(&f); err != nil {
+		return nil, errors.Wrap(err, "apply patch")
+	}
+
+	if err := s.Validater.ValidatePatch(ctx, &f); err != nil {
+		return nil, errors.Wrap(err, "validate user")
+	}
+
+	if !strings.EqualFold(f.Username, u.Username) {
+		if err := s.Repository.UniqueUsername(ctx, f.Username); err != nil {
+			return nil, errors.Wrap(err, "unique username")
+		}
+	}
+
+	if !strings.EqualFold(f.Email, u.Email) {
+		if err := s.Repository.UniqueEmail(ctx, f.Email); err != nil {
+			return nil, errors.Wrap(err, "unique email")
+		}
+	}
+
+	if f.Password != nil {
+		pw, err := bcrypt.GenerateFromPassword([]byte(*f.Password), s.Cost)
+		if err != nil {
+			return nil, errors.Wrap(err, "generating password hash")
+		}
+		u.PasswordHash = string(pw)
+	} else {
+		hash, err := s.Repository.PasswordHash(ctx, id)
+		if err != nil {
+			return nil, errors.Wrap(err, "find password hash")
+		}
+		u.PasswordHash = hash
+	}
+
+	u.Username = f.Username
+	u.Email = f.Email
+	u.Role.ID = f.RoleID
+
+	if err := s.Repository.Update(ctx, id, u); err != nil {
+		return nil, errors.Wrap(err, "update user")
+	}
+
+	return u, nil
+}
+
+// Delete moves a user of the given version to the trash.
+func (s *Service) Delete(ctx context.Context, id, version int) error {
+	return s.Transactor.WithinTx(ctx, func(ctx context.Context) error {
+		u, err := s.Repository.Find(ctx, id)
+		if err != nil {
+			return errors.Wrap(err, "find user")
+		}
+
+		if u.Version != version {
+			return ErrVersionMismatch
+		}
+
+		if err := s.Repository.Delete(ctx, u.ID); err != nil {
+			return errors.Wrap(err, "delete user")
+		}
+
+		return nil
+	})
 }
 
-// List shows all users.
-func (s *Service) List(ctx context.Context) (*Users, error) {
+// List shows users page by given query.
+func (s *Service) List(ctx context.Context, q *query.Query) (*Users, error) {
 	var users Users
-	if err := s.Repository.List(ctx, &users); err != nil {
+	if err := s.Repository.List(ctx, q, &users); err != nil {
 		return nil, errors.Wrap(err, "list of users")
 	}
 
 	return &users, nil
 }
+
+// Deactivate forbids the user to sign in, the issued tokens
+// of the user stop working.
+func (s *Service) Deactivate(ctx context.Context, id int) error {
+	if err := s.Repository.Deactivate(ctx, id); err != nil {
+		return errors.Wrap(err, "deactivate user")
+	}
+	return nil
+}
+
+// Activate allows the deactivated user to sign in again.
+func (s *Service) Activate(ctx context.Context, id int) error {
+	if err := s.Repository.Activate(ctx, id); err != nil {
+		return errors.Wrap(err, "activate user")
+	}
+	return nil
+}
+
+// Trash shows page of the deleted users by given query.
+func (s *Service) Trash(ctx context.Context, q *query.Query) (*Users, error) {
+	var users Users
+	if err := s.Repository.ListDeleted(ctx, q, &users); err != nil {
+		return nil, errors.Wrap(err, "list of deleted users")
+	}
+
+	return &users, nil
+}
+
+// Undelete restores the deleted user.
+func (s *Service) Undelete(ctx context.Context, id int) error {
+	if err := s.Repository.Undelete(ctx, id); err != nil {
+		return errors.Wrap(err, "undelete user")
+	}
+	return nil
+}
+
+// Purge deletes the deleted user permanently.
+func (s *Service) Purge(ctx context.Context, id int) error {
+	if err := s.Repository.Purge(ctx, id); err != nil {
+		return errors.Wrap(err, "purge user")
+	}
+	return nil
+}
+
+// UpdateProfile changes username and email of the user.
+// The role of the user is kept as is.
+func (s *Service) UpdateProfile(ctx context.Context, id int, f *ProfileForm) (*User, error) {
+	if err := s.Validater.ValidateProfile(ctx, f); err != nil {
+		return nil, errors.Wrap(err, "validate profile")
+	}
+
+	u, err := s.Repository.Find(ctx, id)
+	if err != nil {
+		return nil, errors.Wrap(err, "find user")
+	}
+
+	if !strings.EqualFold(f.Username, u.Username) {
+		if err := s.Repository.UniqueUsername(ctx, f.Username); err != nil {
+			return nil, errors.Wrap(err, "unique username")
+		}
+	}
+
+	if !strings.EqualFold(f.Email, u.Email) {
+		if err := s.Repository.UniqueEmail(ctx, f.Email); err != nil {
+			return nil, errors.Wrap(err, "unique email")
+		}
+	}
+
+	if err := s.Repository.UpdateProfile(ctx, id, f.Username, f.Email); err != nil {
+		return nil, errors.Wrap(err, "update profile")
+	}
+
+	u.Username = f.Username
+	u.Email = f.Email
+
+	return u, nil
+}
+
+// ChangePassword sets the new password of the user when the old one
+// matches. Issued tokens of the user become invalid, the refresh tokens
+// and the sessions are revoked except the given session of the request.
+func (s *Service) ChangePassword(ctx context.Context, id, sessionID int, f *PasswordForm) error {
+	if err := s.Validater.ValidatePassword(ctx, f); err != nil {
+		return errors.Wrap(err, "validate password")
+	}
+
+	hash, err := s.Repository.PasswordHash(ctx, id)
+	if err != nil {
+		return errors.Wrap(err, "find password hash")
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(f.OldPassword)); err != nil {
+		return ErrWrongPassword
+	}
+
+	pw, err := bcrypt.GenerateFromPassword([]byte(f.NewPassword), s.Cost)
+	if err != nil {
+		return errors.Wrap(err, "generating password hash")
+	}
+
+	return s.Transactor.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.Repository.UpdatePassword(ctx, id, string(pw)); err != nil {
+			return errors.Wrap(err, "update password")
+		}
+
+		if err := s.Repository.RevokeSessions(ctx, id, sessionID); err != nil {
+			return errors.Wrap(err, "revoke sessions")
+		}
+
+		return nil
+	})
+}
+
+// ForgotPassword sends the link with the password reset token to the
+// email. Unknown email is ignored, so it doesn't tell which emails
+// are registered.
+func (s *Service) ForgotPassword(ctx context.Context, f *ForgotForm) error {
+	if err := s.Validater.ValidateForgot(ctx, f); err != nil {
+		return errors.Wrap(err, "validate forgot form")
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return errors.Wrap(err, "read random bytes")
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	expiresAt := time.Now().Add(s.ResetExpireAfter)
+	if err := s.ResetRepository.CreatePasswordReset(ctx, f.Email, hashResetToken(token), expiresAt); err != nil {
+		if errors.Cause(err) == ErrNotFound {
+			return nil
+		}
+		return errors.Wrap(err, "create password reset")
+	}
+
+	m := mail.Message{
+		To:      f.Email,
+		Subject: "Password reset",
+		Body: fmt.Sprintf("Follow the link to reset your password:\n\n%s?token=%s\n\nThe link expires in %s.\n",
+			s.ResetURL, url.QueryEscape(token), s.ResetExpireAfter),
+	}
+
+	if err := s.Mailer.Send(ctx, &m); err != nil {
+		return errors.Wrap(err, "send mail")
+	}
+
+	return nil
+}
+
+// ResetPassword sets the new password by the reset token. The token
+// works only once, tokens and sessions of the user become invalid.
+func (s *Service) ResetPassword(ctx context.Context, f *ResetForm) error {
+	// The user of the token is found first, so the password
+	// is compared with its username and email.
+	u, err := s.ResetRepository.FindResetUser(ctx, hashResetToken(f.Token))
+	if err != nil {
+		return errors.Wrap(err, "find reset user")
+	}
+
+	if err := s.Validater.ValidateReset(ctx, f, u); err != nil {
+		return errors.Wrap(err, "validate reset form")
+	}
+
+	pw, err := bcrypt.GenerateFromPassword([]byte(f.Password), s.Cost)
+	if err != nil {
+		return errors.Wrap(err, "generating password hash")
+	}
+
+	if err := s.ResetRepository.ResetPassword(ctx, hashResetToken(f.Token), string(pw)); err != nil {
+		return errors.Wrap(err, "reset password")
+	}
+
+	return nil
+}
+
+// hashResetToken returns the hash of the reset token which is stored
+// instead of the token itself.
+func hashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -6,8 +6,10 @@ package user
 
 import (
 	context "context"
+	query "github.com/dipress/crmifc/internal/kit/query"
 	gomock "github.com/golang/mock/gomock"
 	reflect "reflect"
+	time "time"
 )
 
 // MockRepository is a mock of Repository interface
@@ -104,6 +106,63 @@ func (mr *MockRepositoryMockRecorder) Update(ctx, id, u interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockRepository)(nil).Update), ctx, id, u)
 }
 
+// UpdateProfile mocks base method
+func (m *MockRepository) UpdateProfile(ctx context.Context, id int, username, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProfile", ctx, id, username, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateProfile indicates an expected call of UpdateProfile
+func (mr *MockRepositoryMockRecorder) UpdateProfile(ctx, id, username, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProfile", reflect.TypeOf((*MockRepository)(nil).UpdateProfile), ctx, id, username, email)
+}
+
+// PasswordHash mocks base method
+func (m *MockRepository) PasswordHash(ctx context.Context, id int) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PasswordHash", ctx, id)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PasswordHash indicates an expected call of PasswordHash
+func (mr *MockRepositoryMockRecorder) PasswordHash(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PasswordHash", reflect.TypeOf((*MockRepository)(nil).PasswordHash), ctx, id)
+}
+
+// UpdatePassword mocks base method
+func (m *MockRepository) UpdatePassword(ctx context.Context, id int, passwordHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePassword", ctx, id, passwordHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePassword indicates an expected call of UpdatePassword
+func (mr *MockRepositoryMockRecorder) UpdatePassword(ctx, id, passwordHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePassword", reflect.TypeOf((*MockRepository)(nil).UpdatePassword), ctx, id, passwordHash)
+}
+
+// RevokeSessions mocks base method
+func (m *MockRepository) RevokeSessions(ctx context.Context, id, keepSessionID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSessions", ctx, id, keepSessionID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeSessions indicates an expected call of RevokeSessions
+func (mr *MockRepositoryMockRecorder) RevokeSessions(ctx, id, keepSessionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSessions", reflect.TypeOf((*MockRepository)(nil).RevokeSessions), ctx, id, keepSessionID)
+}
+
 // Delete mocks base method
 func (m *MockRepository) Delete(ctx context.Context, id int) error {
 	m.ctrl.T.Helper()
@@ -119,17 +178,153 @@ func (mr *MockRepositoryMockRecorder) Delete(ctx, id interface{}) *gomock.Call {
 }
 
 // List mocks base method
-func (m *MockRepository) List(ctx context.Context, users *Users) error {
+func (m *MockRepository) List(ctx context.Context, q *query.Query, users *Users) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "List", ctx, users)
+	ret := m.ctrl.Call(m, "List", ctx, q, users)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // List indicates an expected call of List
-func (mr *MockRepositoryMockRecorder) List(ctx, users interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) List(ctx, q, users interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockRepository)(nil).List), ctx, users)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockRepository)(nil).List), ctx, q, users)
+}
+
+// Deactivate mocks base method
+func (m *MockRepository) Deactivate(ctx context.Context, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Deactivate", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Deactivate indicates an expected call of Deactivate
+func (mr *MockRepositoryMockRecorder) Deactivate(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deactivate", reflect.TypeOf((*MockRepository)(nil).Deactivate), ctx, id)
+}
+
+// Activate mocks base method
+func (m *MockRepository) Activate(ctx context.Context, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Activate", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Activate indicates an expected call of Activate
+func (mr *MockRepositoryMockRecorder) Activate(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Activate", reflect.TypeOf((*MockRepository)(nil).Activate), ctx, id)
+}
+
+// ListDeleted mocks base method
+func (m *MockRepository) ListDeleted(ctx context.Context, q *query.Query, users *Users) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeleted", ctx, q, users)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ListDeleted indicates an expected call of ListDeleted
+func (mr *MockRepositoryMockRecorder) ListDeleted(ctx, q, users interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeleted", reflect.TypeOf((*MockRepository)(nil).ListDeleted), ctx, q, users)
+}
+
+// Undelete mocks base method
+func (m *MockRepository) Undelete(ctx context.Context, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Undelete", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Undelete indicates an expected call of Undelete
+func (mr *MockRepositoryMockRecorder) Undelete(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Undelete", reflect.TypeOf((*MockRepository)(nil).Undelete), ctx, id)
+}
+
+// Purge mocks base method
+func (m *MockRepository) Purge(ctx context.Context, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Purge indicates an expected call of Purge
+func (mr *MockRepositoryMockRecorder) Purge(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockRepository)(nil).Purge), ctx, id)
+}
+
+// MockResetRepository is a mock of ResetRepository interface
+type MockResetRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockResetRepositoryMockRecorder
+}
+
+// MockResetRepositoryMockRecorder is the mock recorder for MockResetRepository
+type MockResetRepositoryMockRecorder struct {
+	mock *MockResetRepository
+}
+
+// NewMockResetRepository creates a new mock instance
+func NewMockResetRepository(ctrl *gomock.Controller) *MockResetRepository {
+	mock := &MockResetRepository{ctrl: ctrl}
+	mock.recorder = &MockResetRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockResetRepository) EXPECT() *MockResetRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreatePasswordReset mocks base method
+func (m *MockResetRepository) CreatePasswordReset(ctx context.Context, email, hash string, expiresAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePasswordReset", ctx, email, hash, expiresAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreatePasswordReset indicates an expected call of CreatePasswordReset
+func (mr *MockResetRepositoryMockRecorder) CreatePasswordReset(ctx, email, hash, expiresAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePasswordReset", reflect.TypeOf((*MockResetRepository)(nil).CreatePasswordReset), ctx, email, hash, expiresAt)
+}
+
+// FindResetUser mocks base method
+func (m *MockResetRepository) FindResetUser(ctx context.Context, hash string) (*User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindResetUser", ctx, hash)
+	ret0, _ := ret[0].(*User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindResetUser indicates an expected call of FindResetUser
+func (mr *MockResetRepositoryMockRecorder) FindResetUser(ctx, hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindResetUser", reflect.TypeOf((*MockResetRepository)(nil).FindResetUser), ctx, hash)
+}
+
+// ResetPassword mocks base method
+func (m *MockResetRepository) ResetPassword(ctx context.Context, hash, passwordHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPassword", ctx, hash, passwordHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetPassword indicates an expected call of ResetPassword
+func (mr *MockResetRepositoryMockRecorder) ResetPassword(ctx, hash, passwordHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockResetRepository)(nil).ResetPassword), ctx, hash, passwordHash)
 }
 
 // MockValidater is a mock of Validater interface
@@ -168,3 +363,73 @@ func (mr *MockValidaterMockRecorder) Validate(ctx, form interface{}) *gomock.Cal
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Validate", reflect.TypeOf((*MockValidater)(nil).Validate), ctx, form)
 }
+
+// ValidatePatch mocks base method
+func (m *MockValidater) ValidatePatch(ctx context.Context, form *PatchForm) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ValidatePatch", ctx, form)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ValidatePatch indicates an expected call of ValidatePatch
+func (mr *MockValidaterMockRecorder) ValidatePatch(ctx, form interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidatePatch", reflect.TypeOf((*MockValidater)(nil).ValidatePatch), ctx, form)
+}
+
+// ValidateForgot mocks base method
+func (m *MockValidater) ValidateForgot(ctx context.Context, form *ForgotForm) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ValidateForgot", ctx, form)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ValidateForgot indicates an expected call of ValidateForgot
+func (mr *MockValidaterMockRecorder) ValidateForgot(ctx, form interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateForgot", reflect.TypeOf((*MockValidater)(nil).ValidateForgot), ctx, form)
+}
+
+// ValidateReset mocks base method
+func (m *MockValidater) ValidateReset(ctx context.Context, form *ResetForm, u *User) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ValidateReset", ctx, form, u)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ValidateReset indicates an expected call of ValidateReset
+func (mr *MockValidaterMockRecorder) ValidateReset(ctx, form, u interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateReset", reflect.TypeOf((*MockValidater)(nil).ValidateReset), ctx, form, u)
+}
+
+// ValidateProfile mocks base method
+func (m *MockValidater) ValidateProfile(ctx context.Context, form *ProfileForm) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ValidateProfile", ctx, form)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ValidateProfile indicates an expected call of ValidateProfile
+func (mr *MockValidaterMockRecorder) ValidateProfile(ctx, form interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateProfile", reflect.TypeOf((*MockValidater)(nil).ValidateProfile), ctx, form)
+}
+
+// ValidatePassword mocks base method
+func (m *MockValidater) ValidatePassword(ctx context.Context, form *PasswordForm) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ValidatePassword", ctx, form)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ValidatePassword indicates an expected call of ValidatePassword
+func (mr *MockValidaterMockRecorder) ValidatePassword(ctx, form interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidatePassword", reflect.TypeOf((*MockValidater)(nil).ValidatePassword), ctx, form)
+}
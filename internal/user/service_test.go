@@ -2,11 +2,16 @@ package user
 
 import (
 	"context"
-	"errors"
 	"testing"
 
+	"github.com/dipress/crmifc/internal/kit/mail"
+	"github.com/dipress/crmifc/internal/kit/patch"
+	"github.com/dipress/crmifc/internal/kit/query"
+	"github.com/dipress/crmifc/internal/role"
 	gomock "github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
 )
 
 func Test_Create_Service(t *testing.T) {
@@ -84,7 +89,7 @@ func Test_Create_Service(t *testing.T) {
 			tc.repositoryFunc(repo)
 			tc.validaterFunc(validater)
 
-			s := NewService(repo, validater)
+			s := NewService(repo, validater, nil, nil)
 
 			ctx, cancel := context.WithCancel(context.Background())
 			defer cancel()
@@ -138,7 +143,7 @@ func Test_Find_Service(t *testing.T) {
 			repo := NewMockRepository(ctrl)
 			tc.repositoryFunc(repo)
 
-			s := NewService(repo, nil)
+			s := NewService(repo, nil, nil, nil)
 
 			ctx, cancel := context.WithCancel(context.Background())
 			defer cancel()
@@ -164,7 +169,7 @@ func Test_Update_Service(t *testing.T) {
 		{
 			name: "ok",
 			repositoryFunc: func(m *MockRepository) {
-				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&User{}, nil)
+				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&User{Version: 1}, nil)
 				m.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
 			},
 			validaterFunc: func(m *MockValidater) {
@@ -182,7 +187,7 @@ func Test_Update_Service(t *testing.T) {
 		{
 			name: "find user error",
 			repositoryFunc: func(m *MockRepository) {
-				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&User{}, errors.New("mock error"))
+				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&User{Version: 1}, errors.New("mock error"))
 			},
 			validaterFunc: func(m *MockValidater) {
 				m.EXPECT().Validate(gomock.Any(), gomock.Any()).Return(nil)
@@ -192,7 +197,7 @@ func Test_Update_Service(t *testing.T) {
 		{
 			name: "update user error",
 			repositoryFunc: func(m *MockRepository) {
-				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&User{}, nil)
+				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&User{Version: 1}, nil)
 				m.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("mock error"))
 			},
 			validaterFunc: func(m *MockValidater) {
@@ -216,7 +221,7 @@ func Test_Update_Service(t *testing.T) {
 			tc.repositoryFunc(repo)
 			tc.validaterFunc(validater)
 
-			s := NewService(repo, validater)
+			s := NewService(repo, validater, nil, nil)
 
 			ctx, cancel := context.WithCancel(context.Background())
 			defer cancel()
@@ -228,7 +233,7 @@ func Test_Update_Service(t *testing.T) {
 				RoleID:   1,
 			}
 
-			_, err := s.Update(ctx, 1, &form)
+			_, err := s.Update(ctx, 1, 1, &form)
 			if tc.wantErr {
 				assert.Error(t, err)
 				return
@@ -238,6 +243,136 @@ func Test_Update_Service(t *testing.T) {
 	}
 }
 
+func Test_Patch_Service(t *testing.T) {
+	found := func() *User {
+		return &User{ID: 1, Version: 1, Username: "username123", Email: "username@example.com", Role: role.Role{ID: 2}}
+	}
+
+	tests := []struct {
+		name           string
+		patch          string
+		repositoryFunc func(mock *MockRepository)
+		validaterFunc  func(mock *MockValidater)
+		wantErr        error
+	}{
+		{
+			name:  "email only",
+			patch: `{"email":"changed@example.com"}`,
+			repositoryFunc: func(m *MockRepository) {
+				m.EXPECT().Find(gomock.Any(), 1).Return(found(), nil)
+				m.EXPECT().UniqueEmail(gomock.Any(), "changed@example.com").Return(nil)
+				m.EXPECT().PasswordHash(gomock.Any(), 1).Return("hash", nil)
+				m.EXPECT().Update(gomock.Any(), 1, gomock.Any()).
+					DoAndReturn(func(ctx context.Context, id int, u *User) error {
+						if u.Username != "username123" || u.Email != "changed@example.com" || u.PasswordHash != "hash" || u.Role.ID != 2 {
+							t.Errorf("unexpected user: %+v", u)
+						}
+						return nil
+					})
+			},
+			validaterFunc: func(m *MockValidater) {
+				m.EXPECT().ValidatePatch(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, f *PatchForm) error {
+						if f.Password != nil {
+							t.Errorf("unexpected password: %q", *f.Password)
+						}
+						return nil
+					})
+			},
+		},
+		{
+			name:  "case of own email",
+			patch: `{"email":"UserName@Example.com"}`,
+			repositoryFunc: func(m *MockRepository) {
+				m.EXPECT().Find(gomock.Any(), 1).Return(found(), nil)
+				m.EXPECT().PasswordHash(gomock.Any(), 1).Return("hash", nil)
+				m.EXPECT().Update(gomock.Any(), 1, gomock.Any()).
+					DoAndReturn(func(ctx context.Context, id int, u *User) error {
+						if u.Email != "UserName@Example.com" {
+							t.Errorf("unexpected user: %+v", u)
+						}
+						return nil
+					})
+			},
+			validaterFunc: func(m *MockValidater) {
+				m.EXPECT().ValidatePatch(gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:  "password supplied",
+			patch: `{"password":"password321"}`,
+			repositoryFunc: func(m *MockRepository) {
+				m.EXPECT().Find(gomock.Any(), 1).Return(found(), nil)
+				m.EXPECT().Update(gomock.Any(), 1, gomock.Any()).
+					DoAndReturn(func(ctx context.Context, id int, u *User) error {
+						if err := bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte("password321")); err != nil {
+							t.Errorf("unexpected password hash: %v", err)
+						}
+						return nil
+					})
+			},
+			validaterFunc: func(m *MockValidater) {
+				m.EXPECT().ValidatePatch(gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:  "invalid patch",
+			patch: `["email"]`,
+			repositoryFunc: func(m *MockRepository) {
+				m.EXPECT().Find(gomock.Any(), 1).Return(found(), nil)
+			},
+			validaterFunc: func(m *MockValidater) {},
+			wantErr:       patch.ErrInvalid,
+		},
+		{
+			name:  "email exists",
+			patch: `{"email":"changed@example.com"}`,
+			repositoryFunc: func(m *MockRepository) {
+				m.EXPECT().Find(gomock.Any(), 1).Return(found(), nil)
+				m.EXPECT().UniqueEmail(gomock.Any(), "changed@example.com").Return(ErrEmailExists)
+			},
+			validaterFunc: func(m *MockValidater) {
+				m.EXPECT().ValidatePatch(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			wantErr: ErrEmailExists,
+		},
+		{
+			name:  "not found",
+			patch: `{}`,
+			repositoryFunc: func(m *MockRepository) {
+				m.EXPECT().Find(gomock.Any(), 1).Return(nil, ErrNotFound)
+			},
+			validaterFunc: func(m *MockValidater) {},
+			wantErr:       ErrNotFound,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := NewMockRepository(ctrl)
+			validater := NewMockValidater(ctrl)
+
+			tc.repositoryFunc(repo)
+			tc.validaterFunc(validater)
+
+			s := NewService(repo, validater, nil, nil)
+			s.Cost = bcrypt.MinCost
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			_, err := s.Patch(ctx, 1, 1, patch.Patch(tc.patch))
+			assert.Equal(t, tc.wantErr, errors.Cause(err))
+		})
+	}
+}
+
 func Test_Delete_Service(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -247,21 +382,21 @@ func Test_Delete_Service(t *testing.T) {
 		{
 			name: "ok",
 			repositoryFunc: func(m *MockRepository) {
-				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&User{}, nil)
+				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&User{Version: 1}, nil)
 				m.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(nil)
 			},
 		},
 		{
 			name: "find user error",
 			repositoryFunc: func(m *MockRepository) {
-				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&User{}, errors.New("mock error"))
+				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&User{Version: 1}, errors.New("mock error"))
 			},
 			wantErr: true,
 		},
 		{
 			name: "delete user error",
 			repositoryFunc: func(m *MockRepository) {
-				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&User{}, nil)
+				m.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&User{Version: 1}, nil)
 				m.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(errors.New("mock error"))
 			},
 			wantErr: true,
@@ -278,12 +413,12 @@ func Test_Delete_Service(t *testing.T) {
 			repo := NewMockRepository(ctrl)
 			tc.repositoryFunc(repo)
 
-			s := NewService(repo, nil)
+			s := NewService(repo, nil, nil, nil)
 
 			ctx, cancel := context.WithCancel(context.Background())
 			defer cancel()
 
-			err := s.Delete(ctx, 1)
+			err := s.Delete(ctx, 1, 1)
 			if tc.wantErr {
 				assert.Error(t, err)
 				return
@@ -302,13 +437,13 @@ func Test_Service_List(t *testing.T) {
 		{
 			name: "ok",
 			repositoryFunc: func(m *MockRepository) {
-				m.EXPECT().List(gomock.Any(), gomock.Any()).Return(nil)
+				m.EXPECT().List(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
 			},
 		},
 		{
 			name: "internal error",
 			repositoryFunc: func(m *MockRepository) {
-				m.EXPECT().List(gomock.Any(), gomock.Any()).Return(errors.New("mock error"))
+				m.EXPECT().List(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("mock error"))
 			},
 			wantErr: true,
 		},
@@ -325,11 +460,354 @@ func Test_Service_List(t *testing.T) {
 			repo := NewMockRepository(ctrl)
 			tc.repositoryFunc(repo)
 
-			s := NewService(repo, nil)
+			s := NewService(repo, nil, nil, nil)
 			ctx, cancel := context.WithCancel(context.Background())
 			defer cancel()
 
-			_, err := s.List(ctx)
+			_, err := s.List(ctx, query.New())
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.Nil(t, err)
+		})
+	}
+}
+
+func Test_Service_ForgotPassword(t *testing.T) {
+	tests := []struct {
+		name          string
+		resetFunc     func(mock *MockResetRepository)
+		validaterFunc func(mock *MockValidater)
+		sent          int
+		wantErr       bool
+	}{
+		{
+			name: "ok",
+			resetFunc: func(m *MockResetRepository) {
+				m.EXPECT().CreatePasswordReset(gomock.Any(), "username@example.com", gomock.Any(), gomock.Any()).Return(nil)
+			},
+			validaterFunc: func(m *MockValidater) {
+				m.EXPECT().ValidateForgot(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			sent: 1,
+		},
+		{
+			name: "unknown email",
+			resetFunc: func(m *MockResetRepository) {
+				m.EXPECT().CreatePasswordReset(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(ErrNotFound)
+			},
+			validaterFunc: func(m *MockValidater) {
+				m.EXPECT().ValidateForgot(gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:      "validation error",
+			resetFunc: func(m *MockResetRepository) {},
+			validaterFunc: func(m *MockValidater) {
+				m.EXPECT().ValidateForgot(gomock.Any(), gomock.Any()).Return(errors.New("mock error"))
+			},
+			wantErr: true,
+		},
+		{
+			name: "repository error",
+			resetFunc: func(m *MockResetRepository) {
+				m.EXPECT().CreatePasswordReset(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("mock error"))
+			},
+			validaterFunc: func(m *MockValidater) {
+				m.EXPECT().ValidateForgot(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			resets := NewMockResetRepository(ctrl)
+			tc.resetFunc(resets)
+
+			validater := NewMockValidater(ctrl)
+			tc.validaterFunc(validater)
+
+			mailer := mail.NewMemoryMailer()
+			s := NewService(nil, validater, resets, mailer)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			err := s.ForgotPassword(ctx, &ForgotForm{Email: "username@example.com"})
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.Nil(t, err)
+			messages := mailer.Messages()
+			if assert.Len(t, messages, tc.sent) && tc.sent > 0 {
+				assert.Equal(t, "username@example.com", messages[0].To)
+				assert.Contains(t, messages[0].Body, DefaultResetURL+"?token=")
+			}
+		})
+	}
+}
+
+func Test_Service_ResetPassword(t *testing.T) {
+	found := &User{ID: 1, Username: "username", Email: "username@example.com"}
+
+	tests := []struct {
+		name          string
+		resetFunc     func(mock *MockResetRepository)
+		validaterFunc func(mock *MockValidater)
+		wantErr       bool
+	}{
+		{
+			name: "ok",
+			resetFunc: func(m *MockResetRepository) {
+				m.EXPECT().FindResetUser(gomock.Any(), hashResetToken("token")).Return(found, nil)
+				m.EXPECT().ResetPassword(gomock.Any(), hashResetToken("token"), gomock.Any()).Return(nil)
+			},
+			validaterFunc: func(m *MockValidater) {
+				m.EXPECT().ValidateReset(gomock.Any(), gomock.Any(), found).Return(nil)
+			},
+		},
+		{
+			name: "validation error",
+			resetFunc: func(m *MockResetRepository) {
+				m.EXPECT().FindResetUser(gomock.Any(), gomock.Any()).Return(found, nil)
+			},
+			validaterFunc: func(m *MockValidater) {
+				m.EXPECT().ValidateReset(gomock.Any(), gomock.Any(), found).Return(errors.New("mock error"))
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid token",
+			resetFunc: func(m *MockResetRepository) {
+				m.EXPECT().FindResetUser(gomock.Any(), gomock.Any()).Return(nil, ErrInvalidResetToken)
+			},
+			validaterFunc: func(m *MockValidater) {},
+			wantErr:       true,
+		},
+		{
+			name: "token used meanwhile",
+			resetFunc: func(m *MockResetRepository) {
+				m.EXPECT().FindResetUser(gomock.Any(), gomock.Any()).Return(found, nil)
+				m.EXPECT().ResetPassword(gomock.Any(), gomock.Any(), gomock.Any()).Return(ErrInvalidResetToken)
+			},
+			validaterFunc: func(m *MockValidater) {
+				m.EXPECT().ValidateReset(gomock.Any(), gomock.Any(), found).Return(nil)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			resets := NewMockResetRepository(ctrl)
+			tc.resetFunc(resets)
+
+			validater := NewMockValidater(ctrl)
+			tc.validaterFunc(validater)
+
+			s := NewService(nil, validater, resets, nil)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			err := s.ResetPassword(ctx, &ResetForm{Token: "token", Password: "password123"})
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.Nil(t, err)
+		})
+	}
+}
+
+func Test_Service_UpdateProfile(t *testing.T) {
+	tests := []struct {
+		name           string
+		repositoryFunc func(mock *MockRepository)
+		validaterFunc  func(mock *MockValidater)
+		wantErr        bool
+	}{
+		{
+			name: "ok",
+			repositoryFunc: func(m *MockRepository) {
+				m.EXPECT().Find(gomock.Any(), 1).Return(&User{ID: 1, Username: "username123", Email: "old@example.com", Role: role.Role{ID: 2}}, nil)
+				m.EXPECT().UniqueEmail(gomock.Any(), "username@example.com").Return(nil)
+				m.EXPECT().UpdateProfile(gomock.Any(), 1, "username123", "username@example.com").Return(nil)
+			},
+			validaterFunc: func(m *MockValidater) {
+				m.EXPECT().ValidateProfile(gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name: "case of own username and email",
+			repositoryFunc: func(m *MockRepository) {
+				m.EXPECT().Find(gomock.Any(), 1).Return(&User{ID: 1, Username: "UserName123", Email: "UserName@Example.com", Role: role.Role{ID: 2}}, nil)
+				m.EXPECT().UpdateProfile(gomock.Any(), 1, "username123", "username@example.com").Return(nil)
+			},
+			validaterFunc: func(m *MockValidater) {
+				m.EXPECT().ValidateProfile(gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:           "validation error",
+			repositoryFunc: func(m *MockRepository) {},
+			validaterFunc: func(m *MockValidater) {
+				m.EXPECT().ValidateProfile(gomock.Any(), gomock.Any()).Return(errors.New("mock error"))
+			},
+			wantErr: true,
+		},
+		{
+			name: "username exists",
+			repositoryFunc: func(m *MockRepository) {
+				m.EXPECT().Find(gomock.Any(), 1).Return(&User{ID: 1, Username: "other", Email: "username@example.com"}, nil)
+				m.EXPECT().UniqueUsername(gomock.Any(), "username123").Return(ErrUsernameExists)
+			},
+			validaterFunc: func(m *MockValidater) {
+				m.EXPECT().ValidateProfile(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := NewMockRepository(ctrl)
+			validater := NewMockValidater(ctrl)
+
+			tc.repositoryFunc(repo)
+			tc.validaterFunc(validater)
+
+			s := NewService(repo, validater, nil, nil)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			form := ProfileForm{
+				Username: "username123",
+				Email:    "username@example.com",
+			}
+
+			_, err := s.UpdateProfile(ctx, 1, &form)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.Nil(t, err)
+		})
+	}
+}
+
+func Test_Service_ChangePassword(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("generate password hash: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		oldPassword    string
+		repositoryFunc func(mock *MockRepository)
+		wantErr        error
+	}{
+		{
+			name:        "ok",
+			oldPassword: "password123",
+			repositoryFunc: func(m *MockRepository) {
+				m.EXPECT().PasswordHash(gomock.Any(), 1).Return(string(hash), nil)
+				m.EXPECT().UpdatePassword(gomock.Any(), 1, gomock.Any()).Return(nil)
+				m.EXPECT().RevokeSessions(gomock.Any(), 1, 7).Return(nil)
+			},
+		},
+		{
+			name:        "wrong password",
+			oldPassword: "wrong",
+			repositoryFunc: func(m *MockRepository) {
+				m.EXPECT().PasswordHash(gomock.Any(), 1).Return(string(hash), nil)
+			},
+			wantErr: ErrWrongPassword,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := NewMockRepository(ctrl)
+			tc.repositoryFunc(repo)
+
+			validater := NewMockValidater(ctrl)
+			validater.EXPECT().ValidatePassword(gomock.Any(), gomock.Any()).Return(nil)
+
+			s := NewService(repo, validater, nil, nil)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			err := s.ChangePassword(ctx, 1, 7, &PasswordForm{OldPassword: tc.oldPassword, NewPassword: "newpassword"})
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func Test_Service_Deactivate(t *testing.T) {
+	tests := []struct {
+		name           string
+		repositoryFunc func(mock *MockRepository)
+		wantErr        bool
+	}{
+		{
+			name: "ok",
+			repositoryFunc: func(m *MockRepository) {
+				m.EXPECT().Deactivate(gomock.Any(), 1).Return(nil)
+			},
+		},
+		{
+			name: "not found",
+			repositoryFunc: func(m *MockRepository) {
+				m.EXPECT().Deactivate(gomock.Any(), 1).Return(ErrNotFound)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := NewMockRepository(ctrl)
+			tc.repositoryFunc(repo)
+
+			s := NewService(repo, nil, nil, nil)
+
+			err := s.Deactivate(context.Background(), 1)
 			if tc.wantErr {
 				assert.Error(t, err)
 				return
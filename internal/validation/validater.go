@@ -5,8 +5,12 @@ import (
 
 	"github.com/go-ozzo/ozzo-validation/is"
 
+	"github.com/dipress/crmifc/internal/apikey"
 	"github.com/dipress/crmifc/internal/article"
 	"github.com/dipress/crmifc/internal/category"
+	"github.com/dipress/crmifc/internal/invitation"
+	"github.com/dipress/crmifc/internal/kit/auth"
+	"github.com/dipress/crmifc/internal/kit/password"
 	"github.com/dipress/crmifc/internal/role"
 	"github.com/dipress/crmifc/internal/user"
 	validation "github.com/go-ozzo/ozzo-validation"
@@ -15,6 +19,9 @@ import (
 const (
 	mismatchMsg   = "mismatch"
 	validationMsg = "you have validation errors"
+
+	// maxAPIKeyDays limits the lifetime of api keys.
+	maxAPIKeyDays = 365
 )
 
 // Errors holds validation errors.
@@ -46,7 +53,10 @@ func (r *Role) Validate(ctx context.Context, form *role.Form) error {
 }
 
 // User holds form validations.
-type User struct{}
+type User struct {
+	// Policy is checked against the new passwords.
+	Policy password.Policy
+}
 
 // Validate validates user form.
 func (u *User) Validate(ctx context.Context, form *user.Form) error {
@@ -69,6 +79,47 @@ func (u *User) Validate(ctx context.Context, form *user.Form) error {
 		validation.Required,
 		validation.Length(1, 72)); err != nil {
 		ves["password"] = err.Error()
+	} else if err := u.Policy.Check(form.Password, form.Username, form.Email); err != nil {
+		ves["password"] = err.Error()
+	}
+
+	if err := validation.Validate(form.RoleID,
+		validation.Required); err != nil {
+		ves["role_id"] = err.Error()
+	}
+
+	if len(ves) > 0 {
+		return ves
+	}
+	return nil
+}
+
+// ValidatePatch validates the user form merged with the patch.
+// The password is validated only when it is supplied.
+func (u *User) ValidatePatch(ctx context.Context, form *user.PatchForm) error {
+	ves := make(Errors)
+
+	if err := validation.Validate(form.Username,
+		validation.Required,
+		validation.Length(1, 50)); err != nil {
+		ves["username"] = err.Error()
+	}
+
+	if err := validation.Validate(form.Email,
+		validation.Required,
+		is.Email,
+		validation.Length(1, 50)); err != nil {
+		ves["email"] = err.Error()
+	}
+
+	if form.Password != nil {
+		if err := validation.Validate(*form.Password,
+			validation.Required,
+			validation.Length(1, 72)); err != nil {
+			ves["password"] = err.Error()
+		} else if err := u.Policy.Check(*form.Password, form.Username, form.Email); err != nil {
+			ves["password"] = err.Error()
+		}
 	}
 
 	if err := validation.Validate(form.RoleID,
@@ -82,6 +133,109 @@ func (u *User) Validate(ctx context.Context, form *user.Form) error {
 	return nil
 }
 
+// ValidateForgot validates forgot password form.
+func (u *User) ValidateForgot(ctx context.Context, form *user.ForgotForm) error {
+	ves := make(Errors)
+
+	if err := validation.Validate(form.Email,
+		validation.Required,
+		is.Email,
+		validation.Length(1, 50)); err != nil {
+		ves["email"] = err.Error()
+	}
+
+	if len(ves) > 0 {
+		return ves
+	}
+	return nil
+}
+
+// ValidateReset validates reset password form. The password
+// is compared with the username and the email of the user
+// of the reset token.
+func (u *User) ValidateReset(ctx context.Context, form *user.ResetForm, usr *user.User) error {
+	ves := make(Errors)
+
+	if err := validation.Validate(form.Token,
+		validation.Required); err != nil {
+		ves["token"] = err.Error()
+	}
+
+	if err := validation.Validate(form.Password,
+		validation.Required,
+		validation.Length(1, 72)); err != nil {
+		ves["password"] = err.Error()
+	} else {
+		var personal []string
+		if usr != nil {
+			personal = append(personal, usr.Username, usr.Email)
+		}
+
+		if err := u.Policy.Check(form.Password, personal...); err != nil {
+			ves["password"] = err.Error()
+		}
+	}
+
+	if len(ves) > 0 {
+		return ves
+	}
+	return nil
+}
+
+// ValidateProfile validates profile form.
+func (u *User) ValidateProfile(ctx context.Context, form *user.ProfileForm) error {
+	ves := make(Errors)
+
+	if err := validation.Validate(form.Username,
+		validation.Required,
+		validation.Length(1, 50)); err != nil {
+		ves["username"] = err.Error()
+	}
+
+	if err := validation.Validate(form.Email,
+		validation.Required,
+		is.Email,
+		validation.Length(1, 50)); err != nil {
+		ves["email"] = err.Error()
+	}
+
+	if len(ves) > 0 {
+		return ves
+	}
+	return nil
+}
+
+// ValidatePassword validates change password form. The password
+// is compared with the username and the email of the signed in user.
+func (u *User) ValidatePassword(ctx context.Context, form *user.PasswordForm) error {
+	ves := make(Errors)
+
+	if err := validation.Validate(form.OldPassword,
+		validation.Required); err != nil {
+		ves["old_password"] = err.Error()
+	}
+
+	if err := validation.Validate(form.NewPassword,
+		validation.Required,
+		validation.Length(1, 72)); err != nil {
+		ves["new_password"] = err.Error()
+	} else {
+		var personal []string
+		if claims, ok := auth.FromContext(ctx); ok {
+			personal = append(personal, claims.User.Username, claims.User.Email)
+		}
+
+		if err := u.Policy.Check(form.NewPassword, personal...); err != nil {
+			ves["new_password"] = err.Error()
+		}
+	}
+
+	if len(ves) > 0 {
+		return ves
+	}
+	return nil
+}
+
 // Category holds form validations.
 type Category struct{}
 
@@ -129,3 +283,93 @@ func (a *Article) Validate(ctx context.Context, form *article.Form) error {
 	}
 	return nil
 }
+
+// APIKey holds form validations.
+type APIKey struct{}
+
+// Validate validates api key form.
+func (a *APIKey) Validate(ctx context.Context, form *apikey.Form) error {
+	ves := make(Errors)
+
+	if err := validation.Validate(form.Name,
+		validation.Required,
+		validation.Length(1, 50)); err != nil {
+		ves["name"] = err.Error()
+	}
+
+	if err := validation.Validate(form.Scopes,
+		validation.Required); err != nil {
+		ves["scopes"] = err.Error()
+	}
+
+	for _, p := range form.Scopes {
+		if !p.Known() {
+			ves["scopes"] = role.ErrUnknownPermission.Error()
+			break
+		}
+	}
+
+	if err := validation.Validate(form.ExpiresIn,
+		validation.Required,
+		validation.Min(1),
+		validation.Max(maxAPIKeyDays)); err != nil {
+		ves["expires_in"] = err.Error()
+	}
+
+	if len(ves) > 0 {
+		return ves
+	}
+	return nil
+}
+
+// Invitation holds form validations.
+type Invitation struct {
+	// Policy is checked against the passwords of the invitees.
+	Policy password.Policy
+}
+
+// Validate validates invitation form.
+func (i *Invitation) Validate(ctx context.Context, form *invitation.Form) error {
+	ves := make(Errors)
+
+	if err := validation.Validate(form.Email,
+		validation.Required,
+		is.Email,
+		validation.Length(1, 50)); err != nil {
+		ves["email"] = err.Error()
+	}
+
+	if err := validation.Validate(form.RoleID,
+		validation.Required); err != nil {
+		ves["role_id"] = err.Error()
+	}
+
+	if len(ves) > 0 {
+		return ves
+	}
+	return nil
+}
+
+// ValidateAccept validates accept invitation form.
+func (i *Invitation) ValidateAccept(ctx context.Context, form *invitation.AcceptForm) error {
+	ves := make(Errors)
+
+	if err := validation.Validate(form.Username,
+		validation.Required,
+		validation.Length(1, 50)); err != nil {
+		ves["username"] = err.Error()
+	}
+
+	if err := validation.Validate(form.Password,
+		validation.Required,
+		validation.Length(1, 72)); err != nil {
+		ves["password"] = err.Error()
+	} else if err := i.Policy.Check(form.Password, form.Username, form.Email); err != nil {
+		ves["password"] = err.Error()
+	}
+
+	if len(ves) > 0 {
+		return ves
+	}
+	return nil
+}
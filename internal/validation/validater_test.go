@@ -5,8 +5,12 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/dipress/crmifc/internal/apikey"
 	"github.com/dipress/crmifc/internal/article"
 	"github.com/dipress/crmifc/internal/category"
+	"github.com/dipress/crmifc/internal/invitation"
+	"github.com/dipress/crmifc/internal/kit/auth"
+	"github.com/dipress/crmifc/internal/kit/password"
 	"github.com/dipress/crmifc/internal/role"
 	"github.com/dipress/crmifc/internal/user"
 )
@@ -185,6 +189,182 @@ func TestUserValidate(t *testing.T) {
 	}
 }
 
+func TestUserValidateReset(t *testing.T) {
+	tests := []struct {
+		name   string
+		form   user.ResetForm
+		expect Errors
+	}{
+		{
+			name: "ok",
+			form: user.ResetForm{
+				Token:    "token",
+				Password: "mypassword",
+			},
+		},
+		{
+			name: "blank fields",
+			expect: Errors{
+				"token":    "cannot be blank",
+				"password": "cannot be blank",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			var u User
+			err := u.ValidateReset(ctx, &tc.form, nil)
+			if tc.expect == nil {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			if !reflect.DeepEqual(tc.expect, err) {
+				t.Errorf("expected: %+#v got: %+#v", tc.expect, err)
+			}
+		})
+	}
+}
+
+func TestUserValidateForgot(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var u User
+	if err := u.ValidateForgot(ctx, &user.ForgotForm{Email: "shepard@normandy.com"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	expect := Errors{"email": "must be a valid email address"}
+	if err := u.ValidateForgot(ctx, &user.ForgotForm{Email: "shepard"}); !reflect.DeepEqual(expect, err) {
+		t.Errorf("expected: %+#v got: %+#v", expect, err)
+	}
+}
+
+func TestUserValidateProfile(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var u User
+	if err := u.ValidateProfile(ctx, &user.ProfileForm{Username: "shepard", Email: "shepard@normandy.com"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	expect := Errors{
+		"username": "cannot be blank",
+		"email":    "must be a valid email address",
+	}
+	if err := u.ValidateProfile(ctx, &user.ProfileForm{Email: "shepard"}); !reflect.DeepEqual(expect, err) {
+		t.Errorf("expected: %+#v got: %+#v", expect, err)
+	}
+}
+
+func TestUserValidatePassword(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var u User
+	if err := u.ValidatePassword(ctx, &user.PasswordForm{OldPassword: "old", NewPassword: "mypassword"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	expect := Errors{
+		"old_password": "cannot be blank",
+		"new_password": "cannot be blank",
+	}
+	if err := u.ValidatePassword(ctx, &user.PasswordForm{}); !reflect.DeepEqual(expect, err) {
+		t.Errorf("expected: %+#v got: %+#v", expect, err)
+	}
+}
+
+func TestUserValidatePatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	u := User{Policy: password.DefaultPolicy}
+	form := user.PatchForm{Username: "shepard", Email: "commander@normandy.com", RoleID: 1}
+	if err := u.ValidatePatch(ctx, &form); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	blank := ""
+	form.Password = &blank
+	expect := Errors{"password": "cannot be blank"}
+	if err := u.ValidatePatch(ctx, &form); !reflect.DeepEqual(expect, err) {
+		t.Errorf("expected: %+#v got: %+#v", expect, err)
+	}
+
+	personal := "commander-2183"
+	form.Password = &personal
+	expect = Errors{"password": password.ErrPersonal.Error()}
+	if err := u.ValidatePatch(ctx, &form); !reflect.DeepEqual(expect, err) {
+		t.Errorf("expected: %+#v got: %+#v", expect, err)
+	}
+
+	expect = Errors{
+		"username": "cannot be blank",
+		"email":    "cannot be blank",
+		"role_id":  "cannot be blank",
+	}
+	if err := u.ValidatePatch(ctx, &user.PatchForm{}); !reflect.DeepEqual(expect, err) {
+		t.Errorf("expected: %+#v got: %+#v", expect, err)
+	}
+}
+
+func TestUserValidatePolicy(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	u := User{Policy: password.DefaultPolicy}
+	form := user.Form{Username: "shepard", Email: "commander@normandy.com", Password: "mypassword", RoleID: 1}
+
+	expect := Errors{"password": password.ErrCommon.Error()}
+	if err := u.Validate(ctx, &form); !reflect.DeepEqual(expect, err) {
+		t.Errorf("expected: %+#v got: %+#v", expect, err)
+	}
+
+	form.Password = "commander-2183"
+	expect = Errors{"password": password.ErrPersonal.Error()}
+	if err := u.Validate(ctx, &form); !reflect.DeepEqual(expect, err) {
+		t.Errorf("expected: %+#v got: %+#v", expect, err)
+	}
+
+	expect = Errors{"password": "the length must be no less than 8"}
+	if err := u.ValidateReset(ctx, &user.ResetForm{Token: "token", Password: "n7x!"}, nil); !reflect.DeepEqual(expect, err) {
+		t.Errorf("expected: %+#v got: %+#v", expect, err)
+	}
+
+	usr := user.User{Username: "shepard", Email: "commander@normandy.com"}
+	expect = Errors{"password": password.ErrPersonal.Error()}
+	if err := u.ValidateReset(ctx, &user.ResetForm{Token: "token", Password: "Shepard-N7"}, &usr); !reflect.DeepEqual(expect, err) {
+		t.Errorf("expected: %+#v got: %+#v", expect, err)
+	}
+
+	claims := auth.Claims{User: user.User{Username: "shepard", Email: "commander@normandy.com"}}
+	expect = Errors{"new_password": password.ErrPersonal.Error()}
+	if err := u.ValidatePassword(auth.ToContext(ctx, &claims), &user.PasswordForm{OldPassword: "old", NewPassword: "Shepard-N7"}); !reflect.DeepEqual(expect, err) {
+		t.Errorf("expected: %+#v got: %+#v", expect, err)
+	}
+
+	if err := u.ValidatePassword(auth.ToContext(ctx, &claims), &user.PasswordForm{OldPassword: "old", NewPassword: "reapers are coming"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	i := Invitation{Policy: password.DefaultPolicy}
+	expect = Errors{"password": password.ErrPersonal.Error()}
+	if err := i.ValidateAccept(ctx, &invitation.AcceptForm{Username: "garrus", Password: "commander-2183", Email: "commander@normandy.com"}); !reflect.DeepEqual(expect, err) {
+		t.Errorf("expected: %+#v got: %+#v", expect, err)
+	}
+}
+
 func TestCategoryValidate(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -327,3 +507,62 @@ func TestArticleValidate(t *testing.T) {
 		})
 	}
 }
+
+func TestAPIKeyValidate(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var a APIKey
+	form := apikey.Form{Name: "ci", Scopes: []role.Permission{role.ArticlesView}, ExpiresIn: 30}
+	if err := a.Validate(ctx, &form); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	expect := Errors{
+		"name":       "cannot be blank",
+		"scopes":     "cannot be blank",
+		"expires_in": "cannot be blank",
+	}
+	if err := a.Validate(ctx, &apikey.Form{}); !reflect.DeepEqual(expect, err) {
+		t.Errorf("expected: %+#v got: %+#v", expect, err)
+	}
+
+	expect = Errors{
+		"scopes":     "unknown permission",
+		"expires_in": "must be no greater than 365",
+	}
+	form = apikey.Form{Name: "ci", Scopes: []role.Permission{"articles:burn"}, ExpiresIn: 400}
+	if err := a.Validate(ctx, &form); !reflect.DeepEqual(expect, err) {
+		t.Errorf("expected: %+#v got: %+#v", expect, err)
+	}
+}
+
+func TestInvitationValidate(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var i Invitation
+	if err := i.Validate(ctx, &invitation.Form{Email: "username@example.com", RoleID: 1}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	expect := Errors{
+		"email":   "must be a valid email address",
+		"role_id": "cannot be blank",
+	}
+	if err := i.Validate(ctx, &invitation.Form{Email: "username"}); !reflect.DeepEqual(expect, err) {
+		t.Errorf("expected: %+#v got: %+#v", expect, err)
+	}
+
+	if err := i.ValidateAccept(ctx, &invitation.AcceptForm{Username: "username", Password: "password123"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	expect = Errors{
+		"username": "cannot be blank",
+		"password": "cannot be blank",
+	}
+	if err := i.ValidateAccept(ctx, &invitation.AcceptForm{}); !reflect.DeepEqual(expect, err) {
+		t.Errorf("expected: %+#v got: %+#v", expect, err)
+	}
+}